@@ -2,9 +2,15 @@
 package accounts
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/google/uuid"
+	"github.com/onflow/flow-go-sdk"
 	"gorm.io/gorm"
 )
 
@@ -18,7 +24,144 @@ type Account struct {
 	Address   string          `json:"address" gorm:"primaryKey"`
 	Keys      []keys.Storable `json:"keys" gorm:"foreignKey:AccountAddress;references:Address;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	Type      AccountType     `json:"type" gorm:"default:custodial"`
-	CreatedAt time.Time       `json:"createdAt" `
+	Label     string          `json:"label,omitempty" gorm:"index"`
+	CreatedAt time.Time       `json:"createdAt" gorm:"index"`
 	UpdatedAt time.Time       `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt  `json:"-" gorm:"index"`
+	TenantID  string          `json:"-" gorm:"index;uniqueIndex:idx_accounts_tenant_alias"`
+	// Alias is unique per tenant and can be used in place of the address in
+	// URLs as "alias:{name}"
+	Alias *string `json:"alias,omitempty" gorm:"size:64;uniqueIndex:idx_accounts_tenant_alias"`
+	// Frozen accounts can not send any transactions, e.g. during incident response
+	Frozen       bool   `json:"frozen" gorm:"not null;default:false"`
+	FrozenReason string `json:"frozenReason,omitempty"`
+	// Storage usage as of the last check, see CheckStorage
+	StorageUsed      uint64     `json:"storageUsed,omitempty"`
+	StorageCapacity  uint64     `json:"storageCapacity,omitempty"`
+	StorageCheckedAt *time.Time `json:"storageCheckedAt,omitempty"`
+	// The latest storage top-up job, only one is run at a time
+	StorageTopUpJobID *uuid.UUID `json:"-" gorm:"type:uuid"`
+	// OnChain is only populated when live data is requested from the access node
+	OnChain *OnChainDetails `json:"onChain,omitempty" gorm:"-"`
+}
+
+// OnChainDetails holds live account data read from the chain.
+type OnChainDetails struct {
+	Balance         string       `json:"balance"`
+	StorageUsed     uint64       `json:"storageUsed"`
+	StorageCapacity uint64       `json:"storageCapacity"`
+	Keys            []OnChainKey `json:"keys"`
+	Contracts       []string     `json:"contracts"`
+}
+
+// OnChainKey is an account key as it is stored on chain.
+type OnChainKey struct {
+	Index          int    `json:"index"`
+	PublicKey      string `json:"publicKey"`
+	SignAlgo       string `json:"signAlgo"`
+	HashAlgo       string `json:"hashAlgo"`
+	Weight         int    `json:"weight"`
+	SequenceNumber uint64 `json:"sequenceNumber"`
+	Revoked        bool   `json:"revoked"`
+}
+
+// CreateRequest holds optional parameters for account creation.
+type CreateRequest struct {
+	// Amount of FLOW transferred from the admin account to the new account
+	InitialFundingAmount string `json:"initialFundingAmount"`
+	// Names of fungible tokens whose vaults are set up in the creation transaction
+	Tokens []string `json:"tokens"`
+	// MultiSig creates an account requiring multiple internal signatures
+	MultiSig *MultiSig `json:"multiSig"`
+	// Key is a client-held public key the account is created with instead of
+	// a generated key. The account is stored as non-custodial.
+	Key *NewAccountKey `json:"key"`
+}
+
+// MultiSig describes a multi-signature account where the service holds all
+// keys, one per key type (backend), and Threshold of them are required to
+// authorize a transaction.
+type MultiSig struct {
+	Threshold int      `json:"threshold"`
+	KeyTypes  []string `json:"keyTypes"`
+}
+
+// Validate checks that the threshold and key types are usable.
+func (m MultiSig) Validate() error {
+	if len(m.KeyTypes) < 2 || m.Threshold < 2 || m.Threshold > len(m.KeyTypes) {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid multiSig: threshold must be between 2 and the number of key types (%d)", len(m.KeyTypes)),
+		}
+	}
+
+	for _, t := range m.KeyTypes {
+		switch t {
+		case keys.AccountKeyTypeLocal, keys.AccountKeyTypeGoogleKMS, keys.AccountKeyTypeAWSKMS:
+		default:
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid multiSig: unknown key type %q", t),
+			}
+		}
+	}
+
+	return nil
+}
+
+// KeyWeight returns the weight of each key so that Threshold keys together,
+// but not one less, reach full weight.
+func (m MultiSig) KeyWeight() int {
+	return (flow.AccountKeyWeightThreshold + m.Threshold - 1) / m.Threshold
+}
+
+// NewAccountKey describes a key to add to an account. If PublicKey is
+// empty, a new custodial key is generated and stored.
+type NewAccountKey struct {
+	PublicKey string `json:"publicKey"`
+	SignAlgo  string `json:"signAlgo"`
+	HashAlgo  string `json:"hashAlgo"`
+	// Weight defaults to full weight (1000)
+	Weight *int `json:"weight"`
+}
+
+// ListFilter restricts and orders an accounts listing.
+type ListFilter struct {
+	Type          AccountType
+	Label         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// TenantID restricts the listing to accounts of a tenant, if set
+	TenantID string
+	// Sort is one of "createdAt", "address" or "label", prefixed with
+	// "-" for descending order. Defaults to "-createdAt".
+	Sort string
+}
+
+var sortColumns = map[string]string{
+	"createdAt": "created_at",
+	"address":   "address",
+	"label":     "label",
+}
+
+// order returns the SQL order clause for the filter or an error
+// if the sort field is unknown.
+func (f ListFilter) order() (string, error) {
+	sort := f.Sort
+	if sort == "" {
+		sort = "-createdAt"
+	}
+
+	direction := "asc"
+	if strings.HasPrefix(sort, "-") {
+		direction = "desc"
+		sort = strings.TrimPrefix(sort, "-")
+	}
+
+	column, ok := sortColumns[sort]
+	if !ok {
+		return "", fmt.Errorf("invalid sort field: %q", sort)
+	}
+
+	return fmt.Sprintf("%s %s", column, direction), nil
 }
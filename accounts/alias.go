@@ -0,0 +1,69 @@
+package accounts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	log "github.com/sirupsen/logrus"
+)
+
+// AliasPrefix marks an account alias used in place of an address, e.g.
+// in URLs: /accounts/alias:user-1234
+const AliasPrefix = "alias:"
+
+var aliasPattern = regexp.MustCompile(`^[a-zA-Z0-9_.@-]{1,64}$`)
+
+// UpdateAlias sets the alias of an account, an empty alias removes it.
+// Aliases are unique among the accounts of a tenant.
+func (s *ServiceImpl) UpdateAlias(address, alias string) (Account, error) {
+	log.WithFields(log.Fields{"address": address, "alias": alias}).Trace("Update account alias")
+
+	alias = strings.TrimPrefix(alias, AliasPrefix)
+
+	if alias != "" && !aliasPattern.MatchString(alias) {
+		return Account{}, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid alias %q, expected 1 to 64 letters, digits or any of _.@-", alias),
+		}
+	}
+
+	account, err := s.Details(address)
+	if err != nil {
+		return Account{}, err
+	}
+
+	var value *string
+	if alias != "" {
+		if other, err := s.store.AccountByAlias(account.TenantID, alias); err == nil && other.Address != account.Address {
+			return Account{}, &errors.RequestError{
+				StatusCode: http.StatusConflict,
+				Err:        fmt.Errorf("alias %q is already in use", alias),
+			}
+		}
+		value = &alias
+	}
+
+	if err := s.store.UpdateAccountAlias(account.Address, value); err != nil {
+		return Account{}, err
+	}
+
+	return s.Details(account.Address)
+}
+
+// ResolveAlias returns the address of the account with the alias, among the
+// accounts of the tenant in ctx. The alias may be prefixed with AliasPrefix.
+func (s *ServiceImpl) ResolveAlias(ctx context.Context, alias string) (string, error) {
+	alias = strings.TrimPrefix(alias, AliasPrefix)
+
+	account, err := s.store.AccountByAlias(tenants.FromContext(ctx), alias)
+	if err != nil {
+		return "", err
+	}
+
+	return account.Address, nil
+}
@@ -0,0 +1,129 @@
+package accounts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	log "github.com/sirupsen/logrus"
+)
+
+// creationBatcher coalesces concurrent account creations into batch
+// creation transactions. Creations of the same tenant requested within an
+// interval (roughly a block) are created with a single transaction, instead
+// of each one racing its own transaction against the admin proposal keys.
+type creationBatcher struct {
+	interval time.Duration
+	maxSize  int
+	create   func(ctx context.Context, n int) ([]Account, string, error)
+
+	mu      sync.Mutex
+	pending map[string]*creationBatch // by tenant ID
+}
+
+type creationBatch struct {
+	waiters []creationWaiter
+}
+
+type creationWaiter struct {
+	ctx    context.Context
+	result chan creationResult
+}
+
+type creationResult struct {
+	account *Account
+	txID    string
+	err     error
+}
+
+func newCreationBatcher(interval time.Duration, maxSize int, create func(ctx context.Context, n int) ([]Account, string, error)) *creationBatcher {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+
+	return &creationBatcher{
+		interval: interval,
+		maxSize:  maxSize,
+		create:   create,
+		pending:  make(map[string]*creationBatch),
+	}
+}
+
+// Create adds an account creation to the pending batch of the tenant in ctx
+// and waits for the batch to be created. A batch is sent when the interval
+// has passed since its first creation or when it is full.
+//
+// Returns the created account and the flow transaction ID of the batch.
+func (b *creationBatcher) Create(ctx context.Context) (*Account, string, error) {
+	tenantID := tenants.FromContext(ctx)
+	result := make(chan creationResult, 1)
+
+	b.mu.Lock()
+	batch, ok := b.pending[tenantID]
+	if !ok {
+		batch = &creationBatch{}
+		b.pending[tenantID] = batch
+		time.AfterFunc(b.interval, func() { b.flush(tenantID, batch) })
+	}
+	batch.waiters = append(batch.waiters, creationWaiter{ctx: ctx, result: result})
+	if len(batch.waiters) >= b.maxSize {
+		delete(b.pending, tenantID)
+		go b.send(tenantID, batch)
+	}
+	b.mu.Unlock()
+
+	// Once the batch is being sent the account is created even if the
+	// caller stops waiting
+	select {
+	case r := <-result:
+		return r.account, r.txID, r.err
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+}
+
+// flush sends the batch unless it was already sent for being full.
+func (b *creationBatcher) flush(tenantID string, batch *creationBatch) {
+	b.mu.Lock()
+	if b.pending[tenantID] != batch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, tenantID)
+	b.mu.Unlock()
+
+	b.send(tenantID, batch)
+}
+
+func (b *creationBatcher) send(tenantID string, batch *creationBatch) {
+	// Callers which stopped waiting get no account, e.g. a job retrying the
+	// creation would otherwise leave an extra account behind
+	waiters := make([]creationWaiter, 0, len(batch.waiters))
+	for _, w := range batch.waiters {
+		if w.ctx.Err() == nil {
+			waiters = append(waiters, w)
+		}
+	}
+
+	n := len(waiters)
+	if n == 0 {
+		return
+	}
+
+	log.WithFields(log.Fields{"count": n, "tenant": tenantID}).Trace("Sending account creation batch")
+
+	// Not bound to the context of any single creation
+	ctx := tenants.NewContext(context.Background(), tenantID)
+
+	accounts, txID, err := b.create(ctx, n)
+
+	for i, w := range waiters {
+		if err != nil {
+			// The transaction ID tells whether the batch was sent
+			w.result <- creationResult{txID: txID, err: err}
+			continue
+		}
+		w.result <- creationResult{account: &accounts[i], txID: txID}
+	}
+}
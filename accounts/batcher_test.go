@@ -0,0 +1,55 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCreationBatcherCancelled(t *testing.T) {
+	created := make(chan int, 1)
+
+	b := newCreationBatcher(50*time.Millisecond, 10, func(ctx context.Context, n int) ([]Account, string, error) {
+		created <- n
+		accounts := make([]Account, n)
+		for i := range accounts {
+			accounts[i].Address = fmt.Sprintf("0x%016x", i+1)
+		}
+		return accounts, "tx", nil
+	})
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := b.Create(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the creation to be cancelled, got %v", err)
+	}
+
+	account, txID, err := b.Create(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if account == nil || txID != "tx" {
+		t.Fatalf("expected an account, got %v", account)
+	}
+
+	if n := <-created; n != 1 {
+		t.Fatalf("expected the cancelled creation to be left out of the batch, got %d accounts", n)
+	}
+
+	// A batch of cancelled creations is not sent at all
+	if _, _, err := b.Create(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the creation to be cancelled, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	select {
+	case n := <-created:
+		t.Fatalf("expected no batch to be sent, got %d accounts", n)
+	default:
+	}
+}
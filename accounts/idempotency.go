@@ -0,0 +1,144 @@
+package accounts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// IdempotencyKey records an account creation request made with an
+// Idempotency-Key header and its outcome, either the job (async) or the
+// address of the created account (sync). A sync request failing after
+// sending its creation transaction records the transaction instead.
+type IdempotencyKey struct {
+	Key           string `gorm:"primaryKey"`
+	RequestHash   string
+	JobID         *uuid.UUID `gorm:"type:uuid"`
+	Job           *jobs.Job  `gorm:"foreignKey:JobID"`
+	Address       string
+	TransactionID string
+	CreatedAt     time.Time `gorm:"index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "account_idempotency_keys"
+}
+
+// CreateIdempotent creates a new account like CreateWithRequest, unless a
+// request with the same idempotency key has already been made. In that case
+// the original job or account is returned instead of creating another account.
+// Reusing a key for a different request fails.
+func (s *ServiceImpl) CreateIdempotent(ctx context.Context, sync bool, key string, req CreateRequest) (*jobs.Job, *Account, error) {
+	log.WithFields(log.Fields{"sync": sync, "idempotencyKey": key}).Trace("Create account idempotently")
+
+	hash, err := createRequestHash(sync, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Keys are only unique per tenant
+	if tenantID := tenants.FromContext(ctx); tenantID != "" {
+		key = fmt.Sprintf("%s:%s", tenantID, key)
+	}
+
+	if existing, err := s.store.IdempotencyKey(key); err == nil {
+		return s.idempotentResult(existing, hash)
+	} else if !strings.Contains(err.Error(), "record not found") {
+		return nil, nil, err
+	}
+
+	k := &IdempotencyKey{Key: key, RequestHash: hash}
+	if err := s.store.InsertIdempotencyKey(k); err != nil {
+		// A concurrent request may have inserted the key first
+		if existing, getErr := s.store.IdempotencyKey(key); getErr == nil {
+			return s.idempotentResult(existing, hash)
+		}
+		return nil, nil, err
+	}
+
+	job, account, txID, err := s.createWithRequest(ctx, sync, req)
+	if err != nil && account == nil && !creationSent(err, txID) {
+		// Nothing was created, allow retrying with the same key
+		if deleteErr := s.store.DeleteIdempotencyKey(key); deleteErr != nil {
+			log.WithFields(log.Fields{"error": deleteErr, "idempotencyKey": key}).Warn("Unable to delete idempotency key")
+		}
+		return nil, nil, err
+	}
+
+	if job != nil {
+		k.JobID = &job.ID
+	}
+	if account != nil {
+		k.Address = account.Address
+	}
+	k.TransactionID = txID
+
+	// Once the creation transaction was sent it may create the account
+	// regardless of the error, retrying with the key must not create another
+	if err != nil {
+		if saveErr := s.store.SaveIdempotencyKey(k); saveErr != nil {
+			log.WithFields(log.Fields{"error": saveErr, "idempotencyKey": key, "transactionId": txID}).Warn("Unable to save idempotency key")
+		}
+		return nil, nil, err
+	}
+
+	if err := s.store.SaveIdempotencyKey(k); err != nil {
+		return nil, nil, err
+	}
+
+	return job, account, nil
+}
+
+func (s *ServiceImpl) idempotentResult(k IdempotencyKey, hash string) (*jobs.Job, *Account, error) {
+	if k.RequestHash != hash {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusUnprocessableEntity,
+			Err:        fmt.Errorf("idempotency key %s was already used for a different request", k.Key),
+		}
+	}
+
+	switch {
+	case k.Job != nil:
+		return k.Job, nil, nil
+	case k.Address != "":
+		account, err := s.store.Account(k.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, &account, nil
+	case k.TransactionID != "":
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("the account creation transaction %s of idempotency key %s was sent but failed or timed out, check its result before creating the account again with another key", k.TransactionID, k.Key),
+		}
+	}
+
+	return nil, nil, &errors.RequestError{
+		StatusCode: http.StatusConflict,
+		Err:        fmt.Errorf("a request with idempotency key %s is still in progress", k.Key),
+	}
+}
+
+func createRequestHash(sync bool, req CreateRequest) (string, error) {
+	b, err := json.Marshal(struct {
+		Sync    bool          `json:"sync"`
+		Request CreateRequest `json:"request"`
+	}{sync, req})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,88 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+)
+
+type dummyTemplates struct {
+	templates.Service
+	createAccount *templates.CreateAccountTemplate
+}
+
+func (t dummyTemplates) CreateAccountTemplate() *templates.CreateAccountTemplate {
+	return t.createAccount
+}
+
+func TestCreateIdempotent(t *testing.T) {
+	db, _ := newTestDatabases(t)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name string
+		txID string
+		err  error
+		// Status of retrying with the same key, 0 if it creates an account
+		retryStatus int
+	}{
+		{name: "not sent", err: fmt.Errorf("no connection")},
+		{name: "expired", txID: "tx", err: flow_helpers.ErrTransactionExpired},
+		{name: "timed out", txID: "tx", err: fmt.Errorf("timeout"), retryStatus: http.StatusConflict},
+		{name: "reverted", txID: "tx", err: fmt.Errorf("reverted"), retryStatus: http.StatusConflict},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			created := 0
+
+			svc := &ServiceImpl{
+				cfg:   &configs.Config{},
+				store: NewGormStore(db),
+				temps: dummyTemplates{},
+			}
+			svc.batcher = newCreationBatcher(time.Millisecond, 1, func(ctx context.Context, n int) ([]Account, string, error) {
+				created++
+				if created == 1 {
+					return nil, tc.txID, tc.err
+				}
+				return []Account{{Address: fmt.Sprintf("0x%016x", i+1)}}, "tx2", nil
+			})
+
+			key := fmt.Sprintf("key-%d", i)
+
+			if _, _, err := svc.CreateIdempotent(ctx, true, key, CreateRequest{}); err == nil {
+				t.Fatal("expected the first creation to fail")
+			}
+
+			_, account, err := svc.CreateIdempotent(ctx, true, key, CreateRequest{})
+
+			if tc.retryStatus != 0 {
+				var reqErr *wallet_errors.RequestError
+				if !errors.As(err, &reqErr) || reqErr.StatusCode != tc.retryStatus {
+					t.Fatalf("expected status %d, got %v", tc.retryStatus, err)
+				}
+				if created != 1 {
+					t.Fatalf("expected no other account to be created, got %d creations", created)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if account == nil || created != 2 {
+				t.Fatalf("expected the account to be created on retry, got %d creations", created)
+			}
+		})
+	}
+}
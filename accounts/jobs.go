@@ -4,14 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/google/uuid"
 	"github.com/onflow/flow-go-sdk"
 	log "github.com/sirupsen/logrus"
 )
 
 const AccountCreateJobType = "account_create"
 
+type accountCreateJobAttributes struct {
+	InitialFundingAmount string         `json:"initialFundingAmount,omitempty"`
+	Tokens               []string       `json:"tokens,omitempty"`
+	MultiSig             *MultiSig      `json:"multiSig,omitempty"`
+	Key                  *NewAccountKey `json:"key,omitempty"`
+}
+
 func (s *ServiceImpl) executeAccountCreateJob(ctx context.Context, j *jobs.Job) error {
 	if j.Type != AccountCreateJobType {
 		return jobs.ErrInvalidJobType
@@ -19,14 +28,211 @@ func (s *ServiceImpl) executeAccountCreateJob(ctx context.Context, j *jobs.Job)
 
 	j.ShouldSendNotification = true
 
-	a, txID, err := s.createAccount(ctx)
+	var attrs accountCreateJobAttributes
+	if len(j.Attributes) > 0 {
+		if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+			return err
+		}
+	}
+
+	var (
+		a    *Account
+		txID string
+	)
+
+	err := s.retryAccountCreation(ctx, func() (string, error) {
+		var err error
+		a, txID, err = s.createAccount(ctx, attrs.Tokens, attrs.MultiSig, attrs.Key)
+		return txID, err
+	})
 	if err != nil {
-		return err
+		return creationJobError(err, txID)
 	}
 
 	j.TransactionID = txID
 	j.Result = a.Address
 
+	if attrs.InitialFundingAmount != "" {
+		// The account exists at this point, retrying would create another one
+		if _, err := s.fundAccount(ctx, a.Address, attrs.InitialFundingAmount); err != nil {
+			return jobs.PermanentFailure(fmt.Errorf("account %s created but initial funding failed: %w", a.Address, err))
+		}
+	}
+
+	return nil
+}
+
+const AccountCreateBatchJobType = "account_create_batch"
+
+type accountCreateBatchJobAttributes struct {
+	Count int `json:"count"`
+}
+
+func (s *ServiceImpl) executeAccountCreateBatchJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != AccountCreateBatchJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var attrs accountCreateBatchJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	var (
+		accounts []Account
+		txID     string
+	)
+
+	err := s.retryAccountCreation(ctx, func() (string, error) {
+		var err error
+		accounts, txID, err = s.createAccounts(ctx, attrs.Count)
+		return txID, err
+	})
+	if err != nil {
+		return creationJobError(err, txID)
+	}
+
+	addresses := make([]string, len(accounts))
+	for i, a := range accounts {
+		addresses[i] = a.Address
+	}
+
+	j.TransactionID = txID
+	j.Result = strings.Join(addresses, ",")
+
+	return nil
+}
+
+const AccountAddKeyJobType = "account_add_key"
+const AccountRevokeKeyJobType = "account_revoke_key"
+
+type accountKeyJobAttributes struct {
+	Address string         `json:"address"`
+	Key     *NewAccountKey `json:"key,omitempty"`
+	Index   int            `json:"index"`
+}
+
+func (s *ServiceImpl) executeAccountAddKeyJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != AccountAddKeyJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var attrs accountKeyJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	if attrs.Key == nil {
+		return fmt.Errorf("missing key in job attributes")
+	}
+
+	index, txID, err := s.addKey(ctx, attrs.Address, *attrs.Key)
+	if err != nil {
+		return err
+	}
+
+	j.TransactionID = txID
+	j.Result = fmt.Sprintf("%s:%d", attrs.Address, index)
+
+	return nil
+}
+
+func (s *ServiceImpl) executeAccountRevokeKeyJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != AccountRevokeKeyJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var attrs accountKeyJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	txID, err := s.revokeKey(ctx, attrs.Address, attrs.Index)
+	if err != nil {
+		return err
+	}
+
+	j.TransactionID = txID
+	j.Result = fmt.Sprintf("%s:%d", attrs.Address, attrs.Index)
+
+	return nil
+}
+
+const AccountStorageTopUpJobType = "account_storage_top_up"
+
+type accountStorageTopUpJobAttributes struct {
+	Address string `json:"address"`
+}
+
+func (s *ServiceImpl) executeAccountStorageTopUpJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != AccountStorageTopUpJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var attrs accountStorageTopUpJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	txID, err := s.topUpStorage(ctx, attrs.Address)
+	if err != nil {
+		return err
+	}
+
+	j.TransactionID = txID
+	if txID == "" {
+		j.Result = fmt.Sprintf("%s:not needed", attrs.Address)
+	} else {
+		j.Result = fmt.Sprintf("%s:%s", attrs.Address, s.cfg.StorageTopUpAmount)
+	}
+
+	return nil
+}
+
+const AccountKeyRecoveryJobType = "account_key_recovery"
+
+type accountKeyRecoveryJobAttributes struct {
+	Address string    `json:"address"`
+	ID      uuid.UUID `json:"id"`
+}
+
+func (s *ServiceImpl) executeAccountKeyRecoveryJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != AccountKeyRecoveryJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	var attrs accountKeyRecoveryJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	r, err := s.store.KeyRecovery(attrs.Address, attrs.ID)
+	if err != nil {
+		return err
+	}
+
+	if r.Status != KeyRecoveryExecuting {
+		return jobs.PermanentFailure(fmt.Errorf("key recovery %s is %s", r.ID, r.Status))
+	}
+
+	// A failed recovery is released, it has to be executed again explicitly
+	if err := s.recoverKey(ctx, &r); err != nil {
+		return jobs.PermanentFailure(err)
+	}
+
+	j.TransactionID = r.TransactionID
+	j.Result = fmt.Sprintf("%s:%d", r.AccountAddress, *r.NewKeyIndex)
+
 	return nil
 }
 
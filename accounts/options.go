@@ -1,6 +1,9 @@
 package accounts
 
-import "go.uber.org/ratelimit"
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"go.uber.org/ratelimit"
+)
 
 type ServiceOption func(*ServiceImpl)
 
@@ -9,3 +12,10 @@ func WithTxRatelimiter(limiter ratelimit.Limiter) ServiceOption {
 		svc.txRateLimiter = limiter
 	}
 }
+
+// WithWebhooks enables webhook notifications of account lifecycle events.
+func WithWebhooks(webhookService webhooks.Service) ServiceOption {
+	return func(svc *ServiceImpl) {
+		svc.webhooks = webhookService
+	}
+}
@@ -0,0 +1,438 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"github.com/google/uuid"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type KeyRecoveryStatus string
+
+const (
+	KeyRecoveryPending   KeyRecoveryStatus = "pending"
+	KeyRecoveryApproved  KeyRecoveryStatus = "approved"
+	KeyRecoveryExecuting KeyRecoveryStatus = "executing"
+	KeyRecoveryCompleted KeyRecoveryStatus = "completed"
+	KeyRecoveryCancelled KeyRecoveryStatus = "cancelled"
+)
+
+// KeyRecovery replaces a lost client-held key of a shared-custody account
+// with a new client-provided key. A recovery has to be approved and can only
+// be executed once the configured delay has passed since the approval.
+type KeyRecovery struct {
+	ID             uuid.UUID         `json:"id" gorm:"column:id;primary_key;type:uuid;"`
+	AccountAddress string            `json:"address" gorm:"index"`
+	LostKeyIndex   int               `json:"lostKeyIndex"`
+	PublicKey      string            `json:"publicKey"`
+	SignAlgo       string            `json:"signAlgo"`
+	HashAlgo       string            `json:"hashAlgo"`
+	Weight         int               `json:"weight"`
+	Status         KeyRecoveryStatus `json:"status" gorm:"index"`
+	ApprovedBy     string            `json:"approvedBy,omitempty"`
+	ApprovedAt     *time.Time        `json:"approvedAt,omitempty"`
+	ExecutableAt   *time.Time        `json:"executableAt,omitempty"`
+	NewKeyIndex    *int              `json:"newKeyIndex,omitempty"`
+	TransactionID  string            `json:"transactionId,omitempty"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+func (KeyRecovery) TableName() string {
+	return "account_key_recoveries"
+}
+
+func (r *KeyRecovery) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.New()
+	return nil
+}
+
+// KeyRecoveryRequest describes the lost key and the key replacing it.
+type KeyRecoveryRequest struct {
+	LostKeyIndex int           `json:"lostKeyIndex"`
+	NewKey       NewAccountKey `json:"newKey"`
+}
+
+// RequestKeyRecovery starts the recovery of a lost client-held key of a
+// custodial account. The keys held by the service must be able to authorize
+// the replacement on their own.
+func (s *ServiceImpl) RequestKeyRecovery(ctx context.Context, address string, req KeyRecoveryRequest) (KeyRecovery, error) {
+	log.WithFields(log.Fields{"address": address, "lostKeyIndex": req.LostKeyIndex}).Trace("Request key recovery")
+
+	address, err := s.validateCustodialAccount(address)
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return KeyRecovery{}, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account keys can not be managed"),
+		}
+	}
+
+	if req.NewKey.PublicKey == "" {
+		return KeyRecovery{}, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("a publicKey is required for the new key"),
+		}
+	}
+
+	dbAccount, err := s.store.Account(address)
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	if err := checkRecoverable(dbAccount, flowAccount, req.LostKeyIndex); err != nil {
+		return KeyRecovery{}, err
+	}
+
+	// The new key takes over the weight of the lost key unless given
+	if req.NewKey.Weight == nil {
+		weight := flowAccount.Keys[req.LostKeyIndex].Weight
+		req.NewKey.Weight = &weight
+	}
+
+	flowKey, err := s.parseNewAccountKey(req.NewKey)
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	open, err := s.store.OpenKeyRecoveries(address)
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	if len(open) > 0 {
+		return KeyRecovery{}, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("key recovery %s is already in progress for account %s", open[0].ID, address),
+		}
+	}
+
+	r := KeyRecovery{
+		AccountAddress: address,
+		LostKeyIndex:   req.LostKeyIndex,
+		PublicKey:      strings.TrimPrefix(flowKey.PublicKey.String(), "0x"),
+		SignAlgo:       flowKey.SigAlgo.String(),
+		HashAlgo:       flowKey.HashAlgo.String(),
+		Weight:         flowKey.Weight,
+		Status:         KeyRecoveryPending,
+	}
+
+	if err := s.store.InsertKeyRecovery(&r); err != nil {
+		return KeyRecovery{}, err
+	}
+
+	return r, nil
+}
+
+// ListKeyRecoveries returns the key recoveries of an account, newest first.
+func (s *ServiceImpl) ListKeyRecoveries(address string) ([]KeyRecovery, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.KeyRecoveries(address)
+}
+
+// ApproveKeyRecovery records the out-of-band approval of a pending key
+// recovery by the admin of ctx. The recovery becomes executable after the
+// configured delay.
+func (s *ServiceImpl) ApproveKeyRecovery(ctx context.Context, address, id string) (KeyRecovery, error) {
+	approvedBy := tenants.AdminFromContext(ctx)
+
+	log.WithFields(log.Fields{"address": address, "id": id, "approvedBy": approvedBy}).Trace("Approve key recovery")
+
+	if approvedBy == "" {
+		return KeyRecovery{}, &errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("key recoveries can only be approved with an admin API key"),
+		}
+	}
+
+	r, err := s.keyRecovery(address, id)
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	now := time.Now()
+	executableAt := now.Add(s.cfg.KeyRecoveryDelay)
+
+	r.ApprovedBy = approvedBy
+	r.ApprovedAt = &now
+	r.ExecutableAt = &executableAt
+
+	if err := s.transitionKeyRecovery(&r, KeyRecoveryPending, KeyRecoveryApproved); err != nil {
+		return KeyRecovery{}, err
+	}
+
+	return r, nil
+}
+
+// CancelKeyRecovery cancels a key recovery which has not been executed yet.
+func (s *ServiceImpl) CancelKeyRecovery(address, id string) (KeyRecovery, error) {
+	log.WithFields(log.Fields{"address": address, "id": id}).Trace("Cancel key recovery")
+
+	r, err := s.keyRecovery(address, id)
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	if r.Status != KeyRecoveryPending && r.Status != KeyRecoveryApproved {
+		return KeyRecovery{}, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("key recovery %s is %s and can not be cancelled", r.ID, r.Status),
+		}
+	}
+
+	if err := s.transitionKeyRecovery(&r, r.Status, KeyRecoveryCancelled); err != nil {
+		return KeyRecovery{}, err
+	}
+
+	return r, nil
+}
+
+// ExecuteKeyRecovery sends the transaction replacing the lost key with the
+// new key of an approved key recovery, once its delay has passed.
+// It returns a job, the updated recovery and a possible error.
+func (s *ServiceImpl) ExecuteKeyRecovery(ctx context.Context, sync bool, address, id string) (*jobs.Job, *KeyRecovery, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address, "id": id}).Trace("Execute key recovery")
+
+	r, err := s.keyRecovery(address, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.Status == KeyRecoveryApproved && r.ExecutableAt != nil && time.Now().Before(*r.ExecutableAt) {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("key recovery %s can not be executed before %s", r.ID, r.ExecutableAt.Format(time.RFC3339)),
+		}
+	}
+
+	// Claiming the recovery makes sure it is only executed once
+	if err := s.transitionKeyRecovery(&r, KeyRecoveryApproved, KeyRecoveryExecuting); err != nil {
+		return nil, nil, err
+	}
+
+	if !sync {
+		attrBytes, err := json.Marshal(accountKeyRecoveryJobAttributes{Address: r.AccountAddress, ID: r.ID})
+		if err != nil {
+			return nil, nil, s.releaseKeyRecovery(&r, err)
+		}
+
+		job, err := s.wp.CreateJob(AccountKeyRecoveryJobType, "", jobs.WithAttributes(attrBytes), jobs.WithTenantID(tenants.FromContext(ctx)))
+		if err != nil {
+			return nil, nil, s.releaseKeyRecovery(&r, err)
+		}
+
+		// The job does not execute a released recovery
+		if err := s.wp.Schedule(job); err != nil {
+			return nil, nil, s.releaseKeyRecovery(&r, err)
+		}
+
+		return job, nil, nil
+	}
+
+	if err := s.recoverKey(ctx, &r); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, &r, nil
+}
+
+// recoverKey sends the key replacement transaction of a claimed (executing)
+// recovery and records the outcome. The recovery is released for another
+// execution attempt if the replacement fails.
+func (s *ServiceImpl) recoverKey(ctx context.Context, r *KeyRecovery) error {
+	entry := log.WithFields(log.Fields{"address": r.AccountAddress, "id": r.ID, "function": "ServiceImpl.recoverKey"})
+
+	index, txID, err := s.replaceKey(ctx, r)
+	if err != nil {
+		entry.WithFields(log.Fields{"err": err}).Warn("Key recovery failed")
+		return s.releaseKeyRecovery(r, err)
+	}
+
+	r.NewKeyIndex = &index
+	r.TransactionID = txID
+
+	if err := s.transitionKeyRecovery(r, KeyRecoveryExecuting, KeyRecoveryCompleted); err != nil {
+		return err
+	}
+
+	entry.WithFields(log.Fields{"index": index}).Info("Account key recovered")
+
+	s.notify(webhooks.EventAccountKeyRotated, webhooks.AccountData{Address: r.AccountAddress, TransactionID: txID, KeyIndex: &index, KeyChange: "recovered"})
+
+	return nil
+}
+
+// replaceKey sends a transaction revoking the lost key and adding the new
+// key. Returns the on-chain index of the new key and the flow transaction ID.
+func (s *ServiceImpl) replaceKey(ctx context.Context, r *KeyRecovery) (int, string, error) {
+	dbAccount, err := s.store.Account(r.AccountAddress)
+	if err != nil {
+		return 0, "", err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(r.AccountAddress))
+	if err != nil {
+		return 0, "", err
+	}
+
+	// The account may have changed since the recovery was requested
+	if err := checkRecoverable(dbAccount, flowAccount, r.LostKeyIndex); err != nil {
+		return 0, "", err
+	}
+
+	weight := r.Weight
+	flowKey, err := s.parseNewAccountKey(NewAccountKey{PublicKey: r.PublicKey, SignAlgo: r.SignAlgo, HashAlgo: r.HashAlgo, Weight: &weight})
+	if err != nil {
+		return 0, "", err
+	}
+
+	pbk, err := cadence.NewString(r.PublicKey)
+	if err != nil {
+		return 0, "", err
+	}
+
+	ufixWeight, err := cadence.NewUFix64(fmt.Sprintf("%d.0", flowKey.Weight))
+	if err != nil {
+		return 0, "", err
+	}
+
+	args := []transactions.Argument{
+		cadence.NewInt(r.LostKeyIndex),
+		pbk,
+		cadence.NewUInt8(flow_helpers.CadenceSignatureAlgorithm(flowKey.SigAlgo)),
+		cadence.NewUInt8(flow_helpers.CadenceHashAlgorithm(flowKey.HashAlgo)),
+		ufixWeight,
+	}
+
+	// NOTE: sync, so will wait for transaction to be sent & sealed
+	_, tx, err := s.txs.Create(ctx, true, r.AccountAddress, template_strings.ReplaceAccountKeyTransaction, args, transactions.General)
+	if err != nil {
+		return 0, "", err
+	}
+
+	flowAccount, err = s.fc.GetAccount(ctx, flow.HexToAddress(r.AccountAddress))
+	if err != nil {
+		return 0, tx.TransactionId, err
+	}
+
+	index := -1
+	for _, k := range flowAccount.Keys {
+		if !k.Revoked && k.PublicKey.Equals(flowKey.PublicKey) {
+			index = k.Index
+		}
+	}
+
+	if index < 0 {
+		return 0, tx.TransactionId, fmt.Errorf("recovered key not found on account %s", r.AccountAddress)
+	}
+
+	return index, tx.TransactionId, nil
+}
+
+// keyRecovery returns a key recovery of the account.
+func (s *ServiceImpl) keyRecovery(address, id string) (KeyRecovery, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return KeyRecovery{}, err
+	}
+
+	recoveryID, err := uuid.Parse(id)
+	if err != nil {
+		return KeyRecovery{}, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid key recovery id %q", id),
+		}
+	}
+
+	return s.store.KeyRecovery(address, recoveryID)
+}
+
+// transitionKeyRecovery saves the recovery with the new status, provided
+// its status in the datastore still is from.
+func (s *ServiceImpl) transitionKeyRecovery(r *KeyRecovery, from, to KeyRecoveryStatus) error {
+	r.Status = to
+
+	ok, err := s.store.UpdateKeyRecovery(r, from)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("key recovery %s is not %s", r.ID, from),
+		}
+	}
+
+	return nil
+}
+
+// releaseKeyRecovery returns a claimed recovery to the approved status after
+// a failed execution. Returns the execution error.
+func (s *ServiceImpl) releaseKeyRecovery(r *KeyRecovery, cause error) error {
+	if err := s.transitionKeyRecovery(r, KeyRecoveryExecuting, KeyRecoveryApproved); err != nil {
+		log.WithFields(log.Fields{"error": err, "id": r.ID}).Warn("Unable to release key recovery")
+	}
+
+	return cause
+}
+
+// checkRecoverable makes sure the key at index is a valid client-held key
+// and the keys held by the service have enough weight to replace it.
+func checkRecoverable(a Account, fa *flow.Account, index int) error {
+	if index < 0 || index >= len(fa.Keys) || fa.Keys[index].Revoked {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("key %d not found or already revoked", index),
+		}
+	}
+
+	weight := 0
+	for _, k := range a.Keys {
+		if k.Index == index {
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("key %d is held by the service and can not be recovered", index),
+			}
+		}
+		if k.Index < len(fa.Keys) && !fa.Keys[k.Index].Revoked {
+			weight += fa.Keys[k.Index].Weight
+		}
+	}
+
+	if weight < flow.AccountKeyWeightThreshold {
+		return &errors.RequestError{
+			StatusCode: http.StatusUnprocessableEntity,
+			Err:        fmt.Errorf("keys held by the service do not have enough weight to recover key %d", index),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/jpillora/backoff"
+	log "github.com/sirupsen/logrus"
+)
+
+// isTransientCreationError tells whether an account creation attempt can be
+// safely retried with a rebuilt transaction. txID is the ID of the creation
+// transaction if it was sent.
+func isTransientCreationError(err error, txID string) bool {
+	// An expired transaction was never executed
+	if errors.Is(err, flow_helpers.ErrTransactionExpired) {
+		return true
+	}
+
+	// Once sent, the transaction may still get executed
+	return txID == "" && wallet_errors.IsChainConnectionError(err)
+}
+
+// isRetryableCreationError tells whether an account creation job which failed
+// with err may be retried. Jobs whose creation transaction was sent fail for
+// good with a permanent failure, see creationJobError.
+func isRetryableCreationError(err error) bool {
+	return isTransientCreationError(err, "")
+}
+
+// creationJobError returns the error of an account creation job which failed
+// with err on an attempt which sent the transaction txID, if any. A sent
+// transaction which did not expire may still create the account, retrying
+// the job could create another one.
+func creationJobError(err error, txID string) error {
+	if creationSent(err, txID) {
+		return jobs.PermanentFailure(err)
+	}
+	return err
+}
+
+// creationSent tells whether an account creation attempt which failed with
+// err may still create the account, txID is the ID of the creation
+// transaction if it was sent. An expired transaction was never executed.
+func creationSent(err error, txID string) bool {
+	return txID != "" && !errors.Is(err, flow_helpers.ErrTransactionExpired)
+}
+
+// retryAccountCreation runs create and retries it with backoff as long as it
+// fails with a transient error, at most cfg.AccountCreationMaxRetries times.
+// The last error is returned once the retries are exhausted.
+func (s *ServiceImpl) retryAccountCreation(ctx context.Context, create func() (string, error)) error {
+	b := &backoff.Backoff{
+		Min:    time.Second,
+		Max:    30 * time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	for {
+		txID, err := create()
+		if err == nil || !isTransientCreationError(err, txID) || b.Attempt() >= float64(s.cfg.AccountCreationMaxRetries) {
+			return err
+		}
+
+		d := b.Duration()
+
+		log.
+			WithFields(log.Fields{"error": err, "transactionId": txID, "attempt": b.Attempt(), "retryIn": d}).
+			Warn("Account creation failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(d):
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package accounts
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+)
+
+func TestCreationJobError(t *testing.T) {
+	connErr := &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}
+
+	testCases := []struct {
+		name      string
+		err       error
+		txID      string
+		retryable bool
+	}{
+		{name: "connection error before sending", err: connErr, retryable: true},
+		{name: "connection error after sending", err: connErr, txID: "tx"},
+		{name: "expired transaction", err: flow_helpers.ErrTransactionExpired, txID: "tx", retryable: true},
+		{name: "reverted transaction", err: fmt.Errorf("reverted"), txID: "tx"},
+		{name: "invalid request", err: fmt.Errorf("invalid key")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := creationJobError(tc.err, tc.txID)
+
+			retryable := !errors.Is(err, jobs.ErrPermanentFailure) && isRetryableCreationError(err)
+			if retryable != tc.retryable {
+				t.Fatalf("expected retryable %t, got %t", tc.retryable, retryable)
+			}
+		})
+	}
+}
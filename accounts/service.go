@@ -4,18 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
 	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
 	"github.com/onflow/cadence"
 	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go-sdk"
@@ -25,15 +30,55 @@ import (
 	"go.uber.org/ratelimit"
 )
 
-const maxGasLimit = 9999
+var (
+	errBatchWithCustomTemplate = &errors.RequestError{
+		StatusCode: http.StatusBadRequest,
+		Err:        fmt.Errorf("accounts can not be created in batches with a custom account creation template"),
+	}
+	errMultiSigWithSingleKeyTemplate = &errors.RequestError{
+		StatusCode: http.StatusBadRequest,
+		Err:        fmt.Errorf("multiSig can not be used with an account creation template adding a single key"),
+	}
+)
 
 type Service interface {
-	List(limit, offset int) (result []Account, err error)
+	List(limit, offset int, filter ListFilter) (result []Account, err error)
+	Export(filter ListFilter, fn func(ExportedAccount) error) error
 	Create(ctx context.Context, sync bool) (*jobs.Job, *Account, error)
-	AddNonCustodialAccount(address string) (*Account, error)
+	CreateWithRequest(ctx context.Context, sync bool, req CreateRequest) (*jobs.Job, *Account, error)
+	CreateIdempotent(ctx context.Context, sync bool, key string, req CreateRequest) (*jobs.Job, *Account, error)
+	CreateBatch(ctx context.Context, sync bool, n int) (*jobs.Job, []Account, error)
+	AddNonCustodialAccount(ctx context.Context, address string) (*Account, error)
 	DeleteNonCustodialAccount(address string) error
 	SyncAccountKeyCount(ctx context.Context, address flow.Address) (*jobs.Job, error)
 	Details(address string) (Account, error)
+	BulkDetails(ctx context.Context, addresses []string) ([]Account, error)
+	Tenant(address string) (string, error)
+	DetailsWithOnChain(ctx context.Context, address string) (Account, error)
+	UpdateLabel(address, label string) (Account, error)
+	UpdateAlias(address, alias string) (Account, error)
+	ResolveAlias(ctx context.Context, alias string) (string, error)
+	Disable(address string) error
+	OnChainKeys(ctx context.Context, address string) ([]OnChainKey, error)
+	AddKey(ctx context.Context, sync bool, address string, key NewAccountKey) (*jobs.Job, *Account, error)
+	RevokeKey(ctx context.Context, sync bool, address string, index int) (*jobs.Job, *Account, error)
+	DeployContract(ctx context.Context, sync bool, address, name, code string) (*jobs.Job, *transactions.Transaction, error)
+	RemoveContract(ctx context.Context, sync bool, address, name string) (*jobs.Job, *transactions.Transaction, error)
+	Sync(ctx context.Context, address string) (*SyncReport, error)
+	SignAccountProof(ctx context.Context, address, appIdentifier, nonce string) (*AccountProof, error)
+	SignMessage(ctx context.Context, address, message string) (*SignedMessage, error)
+	Import(ctx context.Context, entries []ImportEntry) []ImportResult
+	Enable(address string) (Account, error)
+	Freeze(address, reason string) (Account, error)
+	Unfreeze(address string) (Account, error)
+	IsFrozen(address string) (bool, error)
+	CheckStorage(ctx context.Context, address string) (Account, error)
+	CheckAllStorage(ctx context.Context) error
+	RequestKeyRecovery(ctx context.Context, address string, req KeyRecoveryRequest) (KeyRecovery, error)
+	ListKeyRecoveries(address string) ([]KeyRecovery, error)
+	ApproveKeyRecovery(ctx context.Context, address, id string) (KeyRecovery, error)
+	CancelKeyRecovery(address, id string) (KeyRecovery, error)
+	ExecuteKeyRecovery(ctx context.Context, sync bool, address, id string) (*jobs.Job, *KeyRecovery, error)
 	InitAdminAccount(ctx context.Context) error
 }
 
@@ -47,6 +92,11 @@ type ServiceImpl struct {
 	txs           transactions.Service
 	temps         templates.Service
 	txRateLimiter ratelimit.Limiter
+	webhooks      webhooks.Service
+	// Coalesces account creations into batches, nil if disabled
+	batcher *creationBatcher
+	// Limits the number of account creation transactions in flight, nil if unlimited
+	creationSlots chan struct{}
 }
 
 // NewService initiates a new account service.
@@ -63,27 +113,72 @@ func NewService(
 	var defaultTxRatelimiter = ratelimit.NewUnlimited()
 
 	// TODO(latenssi): safeguard against nil config?
-	svc := &ServiceImpl{cfg, store, km, fc, wp, txs, temps, defaultTxRatelimiter}
+	svc := &ServiceImpl{cfg, store, km, fc, wp, txs, temps, defaultTxRatelimiter, nil, nil, nil}
 
 	for _, opt := range opts {
 		opt(svc)
 	}
 
+	if cfg.AccountCreationBatchInterval > 0 {
+		svc.batcher = newCreationBatcher(cfg.AccountCreationBatchInterval, int(cfg.MaxAccountBatchSize), svc.createAccounts)
+	}
+
+	if cfg.MaxInFlightAccountCreations > 0 {
+		svc.creationSlots = make(chan struct{}, cfg.MaxInFlightAccountCreations)
+	}
+
 	if wp == nil {
 		panic("workerpool nil")
 	}
 
 	// Register asynchronous job executors
 	wp.RegisterExecutor(AccountCreateJobType, svc.executeAccountCreateJob)
+	wp.RegisterExecutor(AccountCreateBatchJobType, svc.executeAccountCreateBatchJob)
+	wp.RegisterRetryPolicy(AccountCreateJobType, jobs.RetryPolicy{Retryable: isRetryableCreationError})
+	wp.RegisterRetryPolicy(AccountCreateBatchJobType, jobs.RetryPolicy{Retryable: isRetryableCreationError})
+	wp.RegisterExecutor(AccountAddKeyJobType, svc.executeAccountAddKeyJob)
+	wp.RegisterExecutor(AccountRevokeKeyJobType, svc.executeAccountRevokeKeyJob)
 	wp.RegisterExecutor(SyncAccountKeyCountJobType, svc.executeSyncAccountKeyCountJob)
+	wp.RegisterExecutor(AccountStorageTopUpJobType, svc.executeAccountStorageTopUpJob)
+	wp.RegisterExecutor(AccountKeyRecoveryJobType, svc.executeAccountKeyRecoveryJob)
 
 	return svc
 }
 
 // List returns all accounts in the datastore.
-func (s *ServiceImpl) List(limit, offset int) (result []Account, err error) {
+func (s *ServiceImpl) List(limit, offset int, filter ListFilter) (result []Account, err error) {
+	if _, err := filter.order(); err != nil {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
 	o := datastore.ParseListOptions(limit, offset)
-	return s.store.Accounts(o)
+	return s.store.Accounts(o, filter)
+}
+
+// ExportedAccount is an account in an export, without keys.
+type ExportedAccount struct {
+	Address   string      `json:"address"`
+	Type      AccountType `json:"type"`
+	Label     string      `json:"label"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// Export calls fn for each account matching the filter, oldest first.
+// Accounts are read from the datastore one at a time so exports of any size
+// can be streamed. The sort order of the filter is ignored.
+func (s *ServiceImpl) Export(filter ListFilter, fn func(ExportedAccount) error) error {
+	log.WithFields(log.Fields{"filter": filter}).Trace("Export accounts")
+
+	return s.store.EachAccount(filter, func(a Account) error {
+		return fn(ExportedAccount{
+			Address:   a.Address,
+			Type:      a.Type,
+			Label:     a.Label,
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt,
+		})
+	})
 }
 
 // Create calls account.New to generate a new account.
@@ -91,10 +186,143 @@ func (s *ServiceImpl) List(limit, offset int) (result []Account, err error) {
 // and stores both in datastore.
 // It returns a job, the new account and a possible error.
 func (s *ServiceImpl) Create(ctx context.Context, sync bool) (*jobs.Job, *Account, error) {
-	log.WithFields(log.Fields{"sync": sync}).Trace("Create account")
+	return s.CreateWithRequest(ctx, sync, CreateRequest{})
+}
+
+// CreateWithRequest creates a new account like Create and optionally funds
+// it with FLOW from the admin account as part of the same job.
+func (s *ServiceImpl) CreateWithRequest(ctx context.Context, sync bool, req CreateRequest) (*jobs.Job, *Account, error) {
+	job, account, _, err := s.createWithRequest(ctx, sync, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, account, nil
+}
+
+// createWithRequest is CreateWithRequest returning the ID of the account
+// creation transaction if it was sent, along with the account if it was
+// created, also when failing.
+func (s *ServiceImpl) createWithRequest(ctx context.Context, sync bool, req CreateRequest) (*jobs.Job, *Account, string, error) {
+	log.WithFields(log.Fields{"sync": sync, "initialFundingAmount": req.InitialFundingAmount}).Trace("Create account")
+
+	if req.InitialFundingAmount != "" {
+		if _, err := decimal.ParseAmount(req.InitialFundingAmount); err != nil {
+			return nil, nil, "", &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid initialFundingAmount: %w", err),
+			}
+		}
+	}
+
+	if _, err := s.vaultTokens(req.Tokens); err != nil {
+		return nil, nil, "", err
+	}
+
+	if req.MultiSig != nil {
+		if err := req.MultiSig.Validate(); err != nil {
+			return nil, nil, "", err
+		}
+
+		if t := s.temps.CreateAccountTemplate(); t != nil && t.SingleKey {
+			return nil, nil, "", errMultiSigWithSingleKeyTemplate
+		}
+	}
+
+	if req.Key != nil {
+		if err := s.validateClientKey(*req.Key, req.MultiSig); err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	if !sync {
+		attrBytes, err := json.Marshal(accountCreateJobAttributes{
+			InitialFundingAmount: req.InitialFundingAmount,
+			Tokens:               req.Tokens,
+			MultiSig:             req.MultiSig,
+			Key:                  req.Key,
+		})
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		job, err := s.wp.CreateJob(AccountCreateJobType, "", jobs.WithAttributes(attrBytes), jobs.WithTenantID(tenants.FromContext(ctx)))
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		err = s.wp.Schedule(job)
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		return job, nil, "", nil
+	}
+
+	account, txID, err := s.createAccount(ctx, req.Tokens, req.MultiSig, req.Key)
+	if err != nil {
+		return nil, nil, txID, err
+	}
+
+	if req.InitialFundingAmount != "" {
+		if _, err := s.fundAccount(ctx, account.Address, req.InitialFundingAmount); err != nil {
+			return nil, account, txID, fmt.Errorf("account %s created but initial funding failed: %w", account.Address, err)
+		}
+	}
+
+	return nil, account, txID, nil
+}
+
+// fundAccount transfers the given amount of FLOW from the admin account to
+// the account. Returns the flow transaction ID.
+func (s *ServiceImpl) fundAccount(ctx context.Context, address, amount string) (string, error) {
+	token, err := s.temps.GetTokenByName("FlowToken")
+	if err != nil {
+		return "", err
+	}
+
+	cadenceAmount, err := decimal.ParseAmount(amount)
+	if err != nil {
+		return "", err
+	}
+
+	args := []transactions.Argument{cadenceAmount, cadence.NewAddress(flow.HexToAddress(address))}
+
+	// NOTE: sync, so will wait for transaction to be sent & sealed
+	_, tx, err := s.txs.Create(ctx, true, s.cfg.AdminAddress, token.Transfer, args, transactions.FtTransfer, transactions.WithTokenName(token.Name))
+	if err != nil {
+		return "", err
+	}
+
+	log.WithFields(log.Fields{"address": address, "amount": amount, "transactionId": tx.TransactionId}).Info("Account funded")
+
+	s.notify(webhooks.EventAccountFunded, webhooks.AccountData{Address: address, TransactionID: tx.TransactionId, Amount: amount})
+
+	return tx.TransactionId, nil
+}
+
+// CreateBatch creates n new accounts in a single flow transaction.
+// It returns a job, the new accounts and a possible error.
+func (s *ServiceImpl) CreateBatch(ctx context.Context, sync bool, n int) (*jobs.Job, []Account, error) {
+	log.WithFields(log.Fields{"sync": sync, "count": n}).Trace("Create account batch")
+
+	if n < 1 || n > int(s.cfg.MaxAccountBatchSize) {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid account count %d, expected 1 to %d", n, s.cfg.MaxAccountBatchSize),
+		}
+	}
+
+	if s.temps.CreateAccountTemplate() != nil {
+		return nil, nil, errBatchWithCustomTemplate
+	}
 
 	if !sync {
-		job, err := s.wp.CreateJob(AccountCreateJobType, "")
+		attrBytes, err := json.Marshal(accountCreateBatchJobAttributes{Count: n})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		job, err := s.wp.CreateJob(AccountCreateBatchJobType, "", jobs.WithAttributes(attrBytes), jobs.WithTenantID(tenants.FromContext(ctx)))
 		if err != nil {
 			return nil, nil, err
 		}
@@ -107,20 +335,21 @@ func (s *ServiceImpl) Create(ctx context.Context, sync bool) (*jobs.Job, *Accoun
 		return job, nil, err
 	}
 
-	account, _, err := s.createAccount(ctx)
+	accounts, _, err := s.createAccounts(ctx, n)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	return nil, account, nil
+	return nil, accounts, nil
 }
 
-func (s *ServiceImpl) AddNonCustodialAccount(address string) (*Account, error) {
+func (s *ServiceImpl) AddNonCustodialAccount(ctx context.Context, address string) (*Account, error) {
 	log.WithFields(log.Fields{"address": address}).Trace("Add non-custodial account")
 
 	a := &Account{
-		Address: flow_helpers.HexString(address),
-		Type:    AccountTypeNonCustodial,
+		Address:  flow_helpers.HexString(address),
+		Type:     AccountTypeNonCustodial,
+		TenantID: tenants.FromContext(ctx),
 	}
 
 	err := s.store.InsertAccount(a)
@@ -128,6 +357,11 @@ func (s *ServiceImpl) AddNonCustodialAccount(address string) (*Account, error) {
 		return nil, err
 	}
 
+	// Track FlowToken for the account like for custodial accounts
+	AccountAdded.Trigger(AccountAddedPayload{
+		Address: flow.HexToAddress(a.Address),
+	})
+
 	return a, nil
 }
 
@@ -174,6 +408,228 @@ func (s *ServiceImpl) Details(address string) (Account, error) {
 	return account, nil
 }
 
+// BulkDetails returns the accounts with the given addresses in the order they
+// were given, restricted to the accounts of the tenant in ctx. Unknown
+// addresses are left out. Does not include private keys.
+func (s *ServiceImpl) BulkDetails(ctx context.Context, addresses []string) ([]Account, error) {
+	log.WithFields(log.Fields{"count": len(addresses)}).Trace("Bulk account details")
+
+	if len(addresses) < 1 || len(addresses) > int(s.cfg.MaxBulkAccountDetails) {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid address count %d, expected 1 to %d", len(addresses), s.cfg.MaxBulkAccountDetails),
+		}
+	}
+
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		a, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		formatted[i] = a
+	}
+
+	found, err := s.store.AccountsByAddress(formatted, tenants.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	byAddress := make(map[string]Account, len(found))
+	for _, a := range found {
+		// Strip the private keys
+		for i := range a.Keys {
+			a.Keys[i].Value = make([]byte, 0)
+		}
+		byAddress[a.Address] = a
+	}
+
+	result := make([]Account, 0, len(found))
+	for _, address := range formatted {
+		if a, ok := byAddress[address]; ok {
+			result = append(result, a)
+			// Repeated addresses are only returned once
+			delete(byAddress, address)
+		}
+	}
+
+	return result, nil
+}
+
+// Tenant returns the ID of the tenant an account belongs to, disabled
+// accounts included.
+func (s *ServiceImpl) Tenant(address string) (string, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.store.AccountTenant(address)
+}
+
+// DetailsWithOnChain returns the stored account merged with live data from
+// the access node: FLOW balance, storage usage, keys and contracts.
+func (s *ServiceImpl) DetailsWithOnChain(ctx context.Context, address string) (Account, error) {
+	account, err := s.Details(address)
+	if err != nil {
+		return Account{}, err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(account.Address))
+	if err != nil {
+		return Account{}, err
+	}
+
+	storageUsed, storageCapacity, err := s.onChainStorage(ctx, flowAccount.Address)
+	if err != nil {
+		return Account{}, err
+	}
+
+	// Keep the tracked storage usage up to date while at it
+	if err := s.store.UpdateAccountStorage(account.Address, storageUsed, storageCapacity, time.Now()); err != nil {
+		log.WithFields(log.Fields{"error": err, "address": account.Address}).Warn("Unable to update account storage usage")
+	}
+
+	onChain := OnChainDetails{
+		Balance:         cadence.UFix64(flowAccount.Balance).String(),
+		StorageUsed:     storageUsed,
+		StorageCapacity: storageCapacity,
+		Keys:            onChainKeys(flowAccount),
+		Contracts:       make([]string, 0, len(flowAccount.Contracts)),
+	}
+
+	for name := range flowAccount.Contracts {
+		onChain.Contracts = append(onChain.Contracts, name)
+	}
+	sort.Strings(onChain.Contracts)
+
+	account.OnChain = &onChain
+
+	return account, nil
+}
+
+// UpdateLabel sets the label of an account.
+func (s *ServiceImpl) UpdateLabel(address, label string) (Account, error) {
+	log.WithFields(log.Fields{"address": address, "label": label}).Trace("Update account label")
+
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	if err := s.store.UpdateAccountLabel(address, label); err != nil {
+		return Account{}, err
+	}
+
+	return s.Details(address)
+}
+
+// Disable soft deletes an account. Transactions can no longer be signed
+// for a disabled account, its keys are kept for auditing.
+func (s *ServiceImpl) Disable(address string) error {
+	log.WithFields(log.Fields{"address": address}).Trace("Disable account")
+
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account can not be disabled"),
+		}
+	}
+
+	if err := s.store.DisableAccount(address); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{"address": address}).Info("Account disabled")
+
+	s.notify(webhooks.EventAccountDisabled, webhooks.AccountData{Address: address})
+
+	return nil
+}
+
+// Enable restores a disabled account.
+func (s *ServiceImpl) Enable(address string) (Account, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Enable account")
+
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	if err := s.store.EnableAccount(address); err != nil {
+		return Account{}, err
+	}
+
+	log.WithFields(log.Fields{"address": address}).Info("Account enabled")
+
+	s.notify(webhooks.EventAccountEnabled, webhooks.AccountData{Address: address})
+
+	return s.Details(address)
+}
+
+// Freeze puts an account on hold, any transaction or withdrawal for it
+// fails with transactions.ErrAccountFrozen until it is unfrozen.
+func (s *ServiceImpl) Freeze(address, reason string) (Account, error) {
+	log.WithFields(log.Fields{"address": address, "reason": reason}).Trace("Freeze account")
+
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return Account{}, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account can not be frozen"),
+		}
+	}
+
+	if err := s.store.UpdateAccountFrozen(address, true, reason); err != nil {
+		return Account{}, err
+	}
+
+	log.WithFields(log.Fields{"address": address, "reason": reason}).Info("Account frozen")
+
+	s.notify(webhooks.EventAccountFrozen, webhooks.AccountData{Address: address})
+
+	return s.Details(address)
+}
+
+// Unfreeze lifts the hold of a frozen account.
+func (s *ServiceImpl) Unfreeze(address string) (Account, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Unfreeze account")
+
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	if err := s.store.UpdateAccountFrozen(address, false, ""); err != nil {
+		return Account{}, err
+	}
+
+	log.WithFields(log.Fields{"address": address}).Info("Account unfrozen")
+
+	s.notify(webhooks.EventAccountUnfrozen, webhooks.AccountData{Address: address})
+
+	return s.Details(address)
+}
+
+// IsFrozen reports whether an account is frozen.
+func (s *ServiceImpl) IsFrozen(address string) (bool, error) {
+	return s.store.IsFrozen(address)
+}
+
 // SyncKeyCount syncs number of keys for given account
 func (s *ServiceImpl) SyncAccountKeyCount(ctx context.Context, address flow.Address) (*jobs.Job, error) {
 	// Validate address, they might be legit addresses but for the wrong chain
@@ -189,7 +645,7 @@ func (s *ServiceImpl) SyncAccountKeyCount(ctx context.Context, address flow.Addr
 	}
 
 	// Create & schedule the "sync key count" job
-	job, err := s.wp.CreateJob(SyncAccountKeyCountJobType, "", jobs.WithAttributes(attrBytes))
+	job, err := s.wp.CreateJob(SyncAccountKeyCountJobType, "", jobs.WithAttributes(attrBytes), jobs.WithTenantID(tenants.FromContext(ctx)))
 	if err != nil {
 		return nil, err
 	}
@@ -226,6 +682,11 @@ func (s *ServiceImpl) syncAccountKeyCount(ctx context.Context, address flow.Addr
 
 	// Pick a source key that will be used to create the new keys & decode public key
 	sourceKey := dbAccount.Keys[0] // NOTE: Only valid (not revoked) keys should be stored in the database
+
+	// Cloning a partial weight key would not add signing capacity
+	if sourceKey.Index < len(flowAccount.Keys) && flowAccount.Keys[sourceKey.Index].Weight < flow.AccountKeyWeightThreshold {
+		return 0, "", fmt.Errorf("key count sync is not supported for multi-signature account %s", address)
+	}
 	sourceKeyPbkString := strings.TrimPrefix(sourceKey.PublicKey, "0x")
 	sourcePbk, err := flow_crypto.DecodePublicKeyHex(flow_crypto.StringToSignatureAlgorithm(sourceKey.SignAlgo), sourceKeyPbkString)
 	if err != nil {
@@ -320,11 +781,104 @@ func (s *ServiceImpl) syncAccountKeyCount(ctx context.Context, address flow.Addr
 
 // createAccount creates a new account on the flow blockchain. It generates a
 // fresh key pair and constructs a flow transaction to create the account with
-// generated key. Admin account is used to pay for the transaction.
+// generated key. For multi-signature accounts a partial weight key is generated
+// per configured key type instead. If a client key is given, the account is
+// created with it and stored as non-custodial. Admin account is used to pay
+// for the transaction.
 //
 // Returns created account and the flow transaction ID of the account creation.
-func (s *ServiceImpl) createAccount(ctx context.Context) (*Account, string, error) {
-	account := &Account{Type: AccountTypeCustodial}
+func (s *ServiceImpl) createAccount(ctx context.Context, tokenNames []string, multiSig *MultiSig, clientKey *NewAccountKey) (*Account, string, error) {
+	customTemplate := s.temps.CreateAccountTemplate()
+
+	// Plain accounts can be created in batches, the batch transaction
+	// sets up the same vaults as a single account creation transaction
+	if s.batcher != nil && len(tokenNames) == 0 && multiSig == nil && clientKey == nil && customTemplate == nil {
+		return s.batcher.Create(ctx)
+	}
+
+	if customTemplate != nil && customTemplate.SingleKey && multiSig != nil {
+		return nil, "", errMultiSigWithSingleKeyTemplate
+	}
+
+	// Custom templates handle any setup of the account themselves
+	if customTemplate != nil && len(tokenNames) > 0 {
+		return nil, "", &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("token vaults can not be set up with a custom account creation template"),
+		}
+	}
+
+	initVaults := customTemplate == nil && (s.cfg.InitFungibleTokenVaultsOnAccountCreation || len(tokenNames) > 0)
+
+	var vaultTokens []templates.Token
+	if initVaults {
+		var err error
+		if vaultTokens, err = s.vaultTokens(tokenNames); err != nil {
+			return nil, "", err
+		}
+	}
+
+	n := newAccount{account: Account{Type: AccountTypeCustodial, TenantID: tenants.FromContext(ctx)}}
+
+	if clientKey != nil {
+		// The service pays for the account but never holds its key
+		publicKey, err := s.parseNewAccountKey(*clientKey)
+		if err != nil {
+			return nil, "", err
+		}
+		n.publicKeys = []*flow.AccountKey{publicKey}
+		n.account.Type = AccountTypeNonCustodial
+	} else {
+		// Generate new key pair(s), public keys for creating the account and
+		// the corresponding storable (encrypted) keys
+		var err error
+		n.publicKeys, n.account.Keys, err = s.generateAccountKeys(ctx, multiSig)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	accounts, txID, err := s.sendAccountCreation(ctx, []newAccount{n}, func(payer flow.Address) (*flow.Transaction, []templates.Token, error) {
+		switch {
+		case customTemplate != nil:
+			flowTx, err := customTemplate.Transaction(n.publicKeys, payer)
+			return flowTx, nil, err
+		case initVaults:
+			return s.generateCreateAccountTransactionWithFungibleTokenVaults(n.publicKeys, payer, vaultTokens)
+		default:
+			flowTx, err := flow_templates.CreateAccount(n.publicKeys, nil, payer)
+			return flowTx, nil, err
+		}
+	})
+	if err != nil {
+		return nil, txID, err
+	}
+
+	return &accounts[0], txID, nil
+}
+
+// newAccount is an account to be created by an account creation transaction
+// with the given public keys. The account is stored as is once created.
+type newAccount struct {
+	account    Account
+	publicKeys []*flow.AccountKey
+}
+
+// sendAccountCreation sends the transaction returned by build, which creates
+// the accounts in the given order, and waits for it to be sealed. The admin
+// account pays for the transaction, build gets its address for the
+// authorizer and returns the fungible tokens whose vaults the transaction
+// sets up. The created accounts are stored with their addresses, announced
+// with AccountAdded and notified of.
+//
+// Returns the created accounts and the flow transaction ID of the account
+// creation, also along with errors once the transaction was sent.
+func (s *ServiceImpl) sendAccountCreation(ctx context.Context, nn []newAccount, build func(payer flow.Address) (*flow.Transaction, []templates.Token, error)) ([]Account, string, error) {
+	release, err := s.acquireCreationSlot(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer release()
 
 	// Important to ratelimit all the way up here so the keys and reference blocks
 	// are "fresh" when the transaction is actually sent
@@ -346,63 +900,16 @@ func (s *ServiceImpl) createAccount(ctx context.Context) (*Account, string, erro
 		return nil, "", err
 	}
 
-	// Generate a new key pair
-	accountKey, newPrivateKey, err := s.km.GenerateDefault(ctx)
+	flowTx, initializedFungibleTokens, err := build(payer.Address)
 	if err != nil {
 		return nil, "", err
 	}
 
-	// Public keys for creating the account
-	publicKeys := []*flow.AccountKey{}
-
-	// Create copies based on the configured key count, changing just the index
-	for i := 0; i < int(s.cfg.DefaultAccountKeyCount); i++ {
-		clonedAccountKey := *accountKey
-		clonedAccountKey.Index = i
-
-		publicKeys = append(publicKeys, &clonedAccountKey)
-	}
-
-	var flowTx *flow.Transaction
-	var initializedFungibleTokens []templates.Token
-	if s.cfg.InitFungibleTokenVaultsOnAccountCreation {
-
-		flowTx, initializedFungibleTokens, err = s.generateCreateAccountTransactionWithEnabledFungibleTokenVaults(
-			publicKeys,
-			payer.Address,
-		)
-		if err != nil {
-			return nil, "", err
-		}
-
-	} else {
-
-		flowTx, err = flow_templates.CreateAccount(
-			publicKeys,
-			nil,
-			payer.Address,
-		)
-		if err != nil {
-			return nil, "", err
-		}
-
-	}
-
 	flowTx.
 		SetReferenceBlockID(*referenceBlockID).
 		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
 		SetPayer(payer.Address).
-		SetGasLimit(maxGasLimit)
-
-	// Check if we want to use a custom account create script
-	if s.cfg.ScriptPathCreateAccount != "" {
-		bytes, err := os.ReadFile(s.cfg.ScriptPathCreateAccount)
-		if err != nil {
-			return nil, "", err
-		}
-		// Overwrite the existing script
-		flowTx.SetScript(bytes)
-	}
+		SetGasLimit(s.cfg.TransactionGasLimit)
 
 	// Proposer signs the payload (unless proposer == payer).
 	if !proposer.Equals(payer) {
@@ -416,85 +923,313 @@ func (s *ServiceImpl) createAccount(ctx context.Context) (*Account, string, erro
 		return nil, "", err
 	}
 
-	// Send and wait for the transaction to be sealed
-	result, err := flow_helpers.SendAndWait(ctx, s.fc, *flowTx, s.cfg.TransactionTimeout)
-	if err != nil {
+	if err := s.fc.SendTransaction(ctx, *flowTx); err != nil {
 		return nil, "", err
 	}
 
-	// Grab the new address from transaction events
-	var newAddress flow.Address
+	// The account may be created from here on, errors carry the
+	// transaction ID so the creation is not retried blindly
+	txID := flowTx.ID().String()
+
+	// Wait for the transaction to be sealed
+	result, err := flow_helpers.WaitForSeal(ctx, s.fc, flowTx.ID(), s.cfg.TransactionTimeout)
+	if err != nil {
+		return nil, txID, err
+	}
+
+	// Grab the new addresses from transaction events, accounts are created
+	// in the same order as their keys were given
+	newAddresses := []flow.Address{}
 	for _, event := range result.Events {
 		if event.Type == flow.EventAccountCreated {
 			accountCreatedEvent := flow.AccountCreatedEvent(event)
-			newAddress = accountCreatedEvent.Address()
-			break
+			newAddresses = append(newAddresses, accountCreatedEvent.Address())
+		}
+	}
+
+	// Check that we actually got all the new addresses
+	if len(newAddresses) != len(nn) {
+		return nil, txID, fmt.Errorf("expected %d created accounts, got %d", len(nn), len(newAddresses))
+	}
+
+	accounts := make([]Account, len(nn))
+	addresses := make([]string, len(nn))
+	for i, newAddress := range newAddresses {
+		account := nn[i].account
+		account.Address = flow_helpers.FormatAddress(newAddress)
+
+		// Store account and key(s)
+		if err := s.store.InsertAccount(&account); err != nil {
+			return nil, txID, err
 		}
+
+		AccountAdded.Trigger(AccountAddedPayload{
+			Address:                   newAddress,
+			InitializedFungibleTokens: initializedFungibleTokens,
+		})
+
+		accounts[i] = account
+		addresses[i] = account.Address
+	}
+
+	log.
+		WithFields(log.Fields{"addresses": addresses, "transactionId": txID, "initialized-fungible-tokens": initializedFungibleTokens}).
+		Info("Accounts created")
+
+	for _, a := range accounts {
+		s.notify(webhooks.EventAccountCreated, webhooks.AccountData{Address: a.Address, TransactionID: txID})
+	}
+
+	return accounts, txID, nil
+}
+
+// validateClientKey checks that a client key for account creation is given
+// and can be used on its own.
+func (s *ServiceImpl) validateClientKey(key NewAccountKey, multiSig *MultiSig) error {
+	if key.PublicKey == "" {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("key.publicKey is required when a key is given"),
+		}
+	}
+
+	if multiSig != nil {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("multiSig can not be used with a client key"),
+		}
+	}
+
+	_, err := s.parseNewAccountKey(key)
+	return err
+}
+
+// acquireCreationSlot waits until the number of account creation
+// transactions in flight is below the configured limit. The returned
+// function releases the slot.
+func (s *ServiceImpl) acquireCreationSlot(ctx context.Context) (func(), error) {
+	if s.creationSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case s.creationSlots <- struct{}{}:
+		return func() { <-s.creationSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
+}
+
+// notify sends a webhook notification of an account lifecycle event, if webhooks are configured.
+func (s *ServiceImpl) notify(event webhooks.Event, data webhooks.AccountData) {
+	if s.webhooks != nil {
+		s.webhooks.Notify(event, data)
+	}
+}
+
+// generateAccountKeys generates the keys of a new account. By default a single
+// key pair is generated and cloned based on the configured key count, for
+// multi-signature accounts each key is generated separately with partial weight.
+//
+// Returns the public keys for creating the account and the keys to store.
+func (s *ServiceImpl) generateAccountKeys(ctx context.Context, multiSig *MultiSig) ([]*flow.AccountKey, []keys.Storable, error) {
+	publicKeys := []*flow.AccountKey{}
+	storableKeys := []keys.Storable{}
+
+	if multiSig != nil {
+		weight := multiSig.KeyWeight()
+		for i, keyType := range multiSig.KeyTypes {
+			accountKey, newPrivateKey, err := s.km.GenerateWithType(ctx, keyType, i, weight)
+			if err != nil {
+				return nil, nil, err
+			}
+			accountKey.Index = i
+			newPrivateKey.Index = i
+
+			// Convert the key to storable form (encrypt it)
+			encryptedAccountKey, err := s.km.Save(*newPrivateKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			encryptedAccountKey.PublicKey = accountKey.PublicKey.String()
 
-	// Check that we actually got a new address
-	if newAddress == flow.EmptyAddress {
-		return nil, "", fmt.Errorf("something went wrong when waiting for address")
+			publicKeys = append(publicKeys, accountKey)
+			storableKeys = append(storableKeys, encryptedAccountKey)
+		}
+
+		return publicKeys, storableKeys, nil
 	}
 
-	account.Address = flow_helpers.FormatAddress(newAddress)
+	// Generate a new key pair
+	accountKey, newPrivateKey, err := s.km.GenerateDefault(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Convert the key to storable form (encrypt it)
 	encryptedAccountKey, err := s.km.Save(*newPrivateKey)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 	encryptedAccountKey.PublicKey = accountKey.PublicKey.String()
 
-	// Store account and key(s)
-	// Looping through accountKeys to get the correct Index values
-	storableKeys := []keys.Storable{}
-	for _, pbk := range publicKeys {
+	// Create copies based on the configured key count, changing just the index
+	for i := 0; i < int(s.cfg.DefaultAccountKeyCount); i++ {
+		clonedAccountKey := *accountKey
+		clonedAccountKey.Index = i
+		publicKeys = append(publicKeys, &clonedAccountKey)
+
 		clonedEncryptedAccountKey := encryptedAccountKey
-		clonedEncryptedAccountKey.Index = pbk.Index
+		clonedEncryptedAccountKey.Index = i
 		storableKeys = append(storableKeys, clonedEncryptedAccountKey)
 	}
 
-	account.Keys = storableKeys
-	if err := s.store.InsertAccount(account); err != nil {
-		return nil, "", err
+	return publicKeys, storableKeys, nil
+}
+
+// createAccounts creates n new accounts on the flow blockchain using a single
+// transaction. Each account gets a fresh key pair (duplicated based on the
+// configured key count). Admin account is used to pay for the transaction.
+//
+// Returns created accounts and the flow transaction ID of the account creation.
+func (s *ServiceImpl) createAccounts(ctx context.Context, n int) ([]Account, string, error) {
+	// Batches use the default transaction, jobs may predate the template
+	if s.temps.CreateAccountTemplate() != nil {
+		return nil, "", errBatchWithCustomTemplate
 	}
 
-	AccountAdded.Trigger(AccountAddedPayload{
-		Address:                   flow.HexToAddress(account.Address),
-		InitializedFungibleTokens: initializedFungibleTokens,
-	})
+	nn := make([]newAccount, n)
+	publicKeys := make([][]*flow.AccountKey, n)
 
-	log.
-		WithFields(log.Fields{"address": account.Address, "initialized-fungible-tokens": initializedFungibleTokens}).
-		Info("Account created")
+	for i := range nn {
+		// Generate a new key pair per account
+		accountKeys, storableKeys, err := s.generateAccountKeys(ctx, nil)
+		if err != nil {
+			return nil, "", err
+		}
+
+		nn[i] = newAccount{
+			account:    Account{Type: AccountTypeCustodial, TenantID: tenants.FromContext(ctx), Keys: storableKeys},
+			publicKeys: accountKeys,
+		}
+		publicKeys[i] = accountKeys
+	}
 
-	return account, flowTx.ID().String(), nil
+	return s.sendAccountCreation(ctx, nn, func(payer flow.Address) (*flow.Transaction, []templates.Token, error) {
+		return s.generateCreateAccountsTransaction(publicKeys, payer)
+	})
 }
 
-// generateCreateAccountTransactionWithEnabledFungibleTokenVaults is a helper function that generates a templated
-// account creation transaction that initializes all enabled fungible tokens.
-func (s *ServiceImpl) generateCreateAccountTransactionWithEnabledFungibleTokenVaults(
-	publicKeys []*flow.AccountKey,
+// generateCreateAccountsTransaction is a helper function that generates a templated
+// transaction creating one account per public key list. Enabled fungible token vaults
+// are initialized if configured to do so.
+func (s *ServiceImpl) generateCreateAccountsTransaction(
+	publicKeys [][]*flow.AccountKey,
 	payerAddress flow.Address,
 ) (
 	*flow.Transaction,
 	[]templates.Token,
 	error,
 ) {
-	// Create custom cadence script to create account and init enabled fungible tokens vaults
-	tokens, err := s.temps.ListTokensFull(templates.FT)
+	var initializedTokens []templates.Token
+	tokensInfo := []template_strings.FungibleTokenInfo{}
+
+	if s.cfg.InitFungibleTokenVaultsOnAccountCreation {
+		tokens, err := s.temps.ListTokensFull(templates.FT)
+		if err != nil {
+			return nil, []templates.Token{}, err
+		}
+
+		for _, t := range tokens {
+			if t.Name != "FlowToken" {
+				tokensInfo = append(tokensInfo, templates.NewFungibleTokenInfo(t))
+				initializedTokens = append(initializedTokens, t)
+			}
+		}
+	}
+
+	txScript, err := templates.CreateAccountsAndInitFungibleTokenVaultsCode(s.cfg.ChainID, tokensInfo)
 	if err != nil {
-		return nil, []templates.Token{}, nil
+		return nil, []templates.Token{}, err
 	}
 
-	var initializedTokens []templates.Token
+	// Encode public key lists
+	keyLists := make([]cadence.Value, len(publicKeys))
+	for i, accountKeys := range publicKeys {
+		keyList := make([]cadence.Value, len(accountKeys))
+		for j, key := range accountKeys {
+			keyList[j], err = flow_templates.AccountKeyToCadenceCryptoKey(key)
+			if err != nil {
+				return nil, []templates.Token{}, err
+			}
+		}
+		keyLists[i] = cadence.NewArray(keyList)
+	}
+	cadencePublicKeys := cadence.NewArray(keyLists)
+
+	flowTx := flow.NewTransaction().
+		SetScript([]byte(txScript)).
+		AddAuthorizer(payerAddress).
+		AddRawArgument(jsoncdc.MustEncode(cadencePublicKeys))
+
+	return flowTx, initializedTokens, nil
+}
+
+// vaultTokens returns the fungible tokens whose vaults should be initialized
+// when creating an account: all enabled fungible tokens if configured so, and
+// the requested tokens. FlowToken is left out as every account has a vault.
+func (s *ServiceImpl) vaultTokens(tokenNames []string) ([]templates.Token, error) {
+	result := []templates.Token{}
+	included := map[string]bool{"flowtoken": true}
+
+	if s.cfg.InitFungibleTokenVaultsOnAccountCreation {
+		tokens, err := s.temps.ListTokensFull(templates.FT)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tokens {
+			if !included[strings.ToLower(t.Name)] {
+				included[strings.ToLower(t.Name)] = true
+				result = append(result, t)
+			}
+		}
+	}
+
+	for _, name := range tokenNames {
+		if included[strings.ToLower(name)] {
+			continue
+		}
+
+		t, err := s.temps.GetTokenByName(name)
+		if err != nil || t.Type != templates.FT {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("unknown fungible token: %q", name),
+			}
+		}
+
+		included[strings.ToLower(t.Name)] = true
+		result = append(result, *t)
+	}
+
+	return result, nil
+}
+
+// generateCreateAccountTransactionWithFungibleTokenVaults is a helper function that generates a templated
+// account creation transaction that initializes the vaults of the given fungible tokens.
+func (s *ServiceImpl) generateCreateAccountTransactionWithFungibleTokenVaults(
+	publicKeys []*flow.AccountKey,
+	payerAddress flow.Address,
+	tokens []templates.Token,
+) (
+	*flow.Transaction,
+	[]templates.Token,
+	error,
+) {
 	tokensInfo := []template_strings.FungibleTokenInfo{}
 	for _, t := range tokens {
-		if t.Name != "FlowToken" {
-			tokensInfo = append(tokensInfo, templates.NewFungibleTokenInfo(t))
-			initializedTokens = append(initializedTokens, t)
-		}
+		tokensInfo = append(tokensInfo, templates.NewFungibleTokenInfo(t))
 	}
 
 	txScript, err := templates.CreateAccountAndInitFungibleTokenVaultsCode(s.cfg.ChainID, tokensInfo)
@@ -517,5 +1252,5 @@ func (s *ServiceImpl) generateCreateAccountTransactionWithEnabledFungibleTokenVa
 		AddAuthorizer(payerAddress).
 		AddRawArgument(jsoncdc.MustEncode(cadencePublicKeys))
 
-	return flowTx, initializedTokens, nil
+	return flowTx, tokens, nil
 }
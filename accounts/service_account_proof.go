@@ -0,0 +1,103 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// AccountProof is the data of an FCL account-proof service.
+type AccountProof struct {
+	Address    string               `json:"address"`
+	Nonce      string               `json:"nonce"`
+	Signatures []CompositeSignature `json:"signatures"`
+}
+
+// CompositeSignature is a signature in the format used by FCL.
+type CompositeSignature struct {
+	FType     string `json:"f_type"`
+	FVsn      string `json:"f_vsn"`
+	Address   string `json:"addr"`
+	KeyID     int    `json:"keyId"`
+	Signature string `json:"signature"`
+}
+
+func newCompositeSignature(address flow.Address, keyID int, signature []byte) CompositeSignature {
+	return CompositeSignature{
+		FType:     "CompositeSignature",
+		FVsn:      "1.0.0",
+		Address:   flow_helpers.FormatAddress(address),
+		KeyID:     keyID,
+		Signature: fmt.Sprintf("%x", signature),
+	}
+}
+
+// SignAccountProof signs an FCL account-proof message for the given app
+// identifier and nonce, proving to a dApp that the user controls the account.
+// Multi-signature accounts return a signature per key needed to reach the
+// weight threshold.
+func (s *ServiceImpl) SignAccountProof(ctx context.Context, address, appIdentifier, nonce string) (*AccountProof, error) {
+	log.WithFields(log.Fields{"address": address, "appIdentifier": appIdentifier}).Trace("Sign account proof")
+
+	address, err := s.validateCustodialAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("can not sign account proofs for the admin account"),
+		}
+	}
+
+	flowAddress := flow.HexToAddress(address)
+
+	message, err := flow.EncodeAccountProofMessage(flowAddress, appIdentifier, nonce)
+	if err != nil {
+		if errors.Is(err, flow.ErrInvalidNonce) || errors.Is(err, flow.ErrInvalidAppID) {
+			return nil, &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+		}
+		return nil, err
+	}
+
+	signatures, err := s.signUserMessage(ctx, flowAddress, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountProof{Address: address, Nonce: nonce, Signatures: signatures}, nil
+}
+
+// signUserMessage signs a message in the user domain with the keys of a
+// custodial account, a signature per key needed to reach the weight
+// threshold.
+func (s *ServiceImpl) signUserMessage(ctx context.Context, address flow.Address, message []byte) ([]CompositeSignature, error) {
+	authorizer, err := s.km.UserAuthorizer(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := flow.SignUserMessage(authorizer.Signer, message)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := []CompositeSignature{newCompositeSignature(address, authorizer.Key.Index, signature)}
+
+	for _, c := range authorizer.CoSigners {
+		signature, err := flow.SignUserMessage(c.Signer, message)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, newCompositeSignature(address, c.Key.Index, signature))
+	}
+
+	return signatures, nil
+}
@@ -0,0 +1,112 @@
+package accounts
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+var contractNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DeployContract deploys a contract to a custodial account, or updates
+// it if a contract with the same name already exists on the account.
+// It returns a job, the transaction and a possible error.
+func (s *ServiceImpl) DeployContract(ctx context.Context, sync bool, address, name, code string) (*jobs.Job, *transactions.Transaction, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address, "name": name}).Trace("Deploy contract")
+
+	address, err := s.validateCustodialAccount(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateContractName(name); err != nil {
+		return nil, nil, err
+	}
+
+	if code == "" {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("contract code can not be empty"),
+		}
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txCode := template_strings.DeployContractTransaction
+	if _, exists := flowAccount.Contracts[name]; exists {
+		txCode = template_strings.UpdateContractTransaction
+	}
+
+	cadenceName, err := cadence.NewString(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cadenceCode, err := cadence.NewString(hex.EncodeToString([]byte(code)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := []transactions.Argument{cadenceName, cadenceCode}
+
+	return s.txs.Create(ctx, sync, address, txCode, args, transactions.General)
+}
+
+// RemoveContract removes a contract from a custodial account.
+// It returns a job, the transaction and a possible error.
+func (s *ServiceImpl) RemoveContract(ctx context.Context, sync bool, address, name string) (*jobs.Job, *transactions.Transaction, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address, "name": name}).Trace("Remove contract")
+
+	address, err := s.validateCustodialAccount(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateContractName(name); err != nil {
+		return nil, nil, err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, exists := flowAccount.Contracts[name]; !exists {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusNotFound,
+			Err:        fmt.Errorf("contract %s not found on account %s", name, address),
+		}
+	}
+
+	cadenceName, err := cadence.NewString(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := []transactions.Argument{cadenceName}
+
+	return s.txs.Create(ctx, sync, address, template_strings.RemoveContractTransaction, args, transactions.General)
+}
+
+func validateContractName(name string) error {
+	if !contractNameRegexp.MatchString(name) {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("not a valid contract name: %q", name),
+		}
+	}
+	return nil
+}
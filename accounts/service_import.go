@@ -0,0 +1,216 @@
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/onflow/flow-go-sdk"
+	flow_crypto "github.com/onflow/flow-go-sdk/crypto"
+	log "github.com/sirupsen/logrus"
+)
+
+// ImportEntry is an externally created account and its private key.
+// If KeyIndex is not set, the first on-chain key matching the private key is used.
+// SignAlgo and HashAlgo default to the configured defaults.
+type ImportEntry struct {
+	Address    string `json:"address"`
+	PrivateKey string `json:"privateKey"`
+	KeyIndex   *int   `json:"keyIndex,omitempty"`
+	SignAlgo   string `json:"signAlgo,omitempty"`
+	HashAlgo   string `json:"hashAlgo,omitempty"`
+}
+
+// ImportResult is the outcome of importing a single account.
+type ImportResult struct {
+	Address  string `json:"address"`
+	Imported bool   `json:"imported"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ParseImportEntries parses a JSON array of ImportEntry objects or CSV
+// with rows of "address,privateKey[,keyIndex[,signAlgo,hashAlgo]]".
+// A CSV header row starting with "address" is skipped.
+func ParseImportEntries(data []byte) ([]ImportEntry, error) {
+	data = bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		entries := []ImportEntry{}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("invalid JSON import file: %w", err)
+		}
+		return entries, nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	entries := []ImportEntry{}
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV import file: %w", err)
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "address") {
+			continue
+		}
+
+		if len(record) != 2 && len(record) != 3 && len(record) != 5 {
+			return nil, fmt.Errorf("invalid CSV import file: line %d: unexpected number of fields %d", line, len(record))
+		}
+
+		entry := ImportEntry{Address: record[0], PrivateKey: record[1]}
+
+		if len(record) > 2 && record[2] != "" {
+			index, err := strconv.Atoi(record[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid CSV import file: line %d: invalid key index: %w", line, err)
+			}
+			entry.KeyIndex = &index
+		}
+
+		if len(record) == 5 {
+			entry.SignAlgo, entry.HashAlgo = record[3], record[4]
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Import persists externally created accounts as custodial accounts. Each
+// private key is validated against the on-chain account before it is stored.
+// Accounts are imported independently, failures are reported in the results.
+func (s *ServiceImpl) Import(ctx context.Context, entries []ImportEntry) []ImportResult {
+	results := make([]ImportResult, len(entries))
+
+	for i, entry := range entries {
+		results[i].Address = entry.Address
+
+		address, err := s.importAccount(ctx, entry)
+		if err != nil {
+			log.WithFields(log.Fields{"address": entry.Address, "error": err}).Warn("Account import failed")
+			results[i].Error = err.Error()
+			continue
+		}
+
+		results[i].Address = address
+		results[i].Imported = true
+	}
+
+	return results
+}
+
+func (s *ServiceImpl) importAccount(ctx context.Context, entry ImportEntry) (string, error) {
+	address, err := flow_helpers.ValidateAddress(entry.Address, s.cfg.ChainID)
+	if err != nil {
+		return "", err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return "", fmt.Errorf("can not import the admin account")
+	}
+
+	if _, err := s.store.Account(address); err == nil {
+		return "", fmt.Errorf("account already exists")
+	}
+
+	signAlgo := flow_crypto.StringToSignatureAlgorithm(entry.SignAlgo)
+	if entry.SignAlgo == "" {
+		signAlgo = flow_crypto.StringToSignatureAlgorithm(s.cfg.DefaultSignAlgo)
+	}
+
+	hashAlgo := flow_crypto.StringToHashAlgorithm(entry.HashAlgo)
+	if entry.HashAlgo == "" {
+		hashAlgo = flow_crypto.StringToHashAlgorithm(s.cfg.DefaultHashAlgo)
+	}
+
+	privateKey, err := flow_crypto.DecodePrivateKeyHex(signAlgo, strings.TrimPrefix(entry.PrivateKey, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %w", err)
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+
+	onChain, err := matchingAccountKey(flowAccount, privateKey.PublicKey(), hashAlgo, entry.KeyIndex)
+	if err != nil {
+		return "", err
+	}
+
+	storable, err := s.km.Save(keys.Private{
+		Index:    onChain.Index,
+		Type:     keys.AccountKeyTypeLocal,
+		Value:    strings.TrimPrefix(privateKey.String(), "0x"),
+		SignAlgo: signAlgo,
+		HashAlgo: hashAlgo,
+	})
+	if err != nil {
+		return "", err
+	}
+	storable.PublicKey = onChain.PublicKey.String()
+
+	account := &Account{
+		Address:  address,
+		Type:     AccountTypeCustodial,
+		Keys:     []keys.Storable{storable},
+		TenantID: tenants.FromContext(ctx),
+	}
+
+	if err := s.store.InsertAccount(account); err != nil {
+		return "", err
+	}
+
+	AccountAdded.Trigger(AccountAddedPayload{
+		Address: flowAccount.Address,
+	})
+
+	log.WithFields(log.Fields{"address": address, "keyIndex": onChain.Index}).Info("Account imported")
+
+	return address, nil
+}
+
+// matchingAccountKey returns the on-chain key for the public key. The key has
+// to be usable for signing on its own.
+func matchingAccountKey(flowAccount *flow.Account, publicKey flow_crypto.PublicKey, hashAlgo flow_crypto.HashAlgorithm, index *int) (*flow.AccountKey, error) {
+	err := fmt.Errorf("private key does not match any key of the account")
+
+	for _, k := range flowAccount.Keys {
+		if index != nil && k.Index != *index {
+			continue
+		}
+
+		if !k.PublicKey.Equals(publicKey) {
+			continue
+		}
+
+		switch {
+		case k.Revoked:
+			err = fmt.Errorf("key %d is revoked", k.Index)
+		case k.HashAlgo != hashAlgo:
+			err = fmt.Errorf("key %d uses hash algorithm %s, not %s", k.Index, k.HashAlgo, hashAlgo)
+		case k.Weight < flow.AccountKeyWeightThreshold:
+			err = fmt.Errorf("key %d does not have full weight", k.Index)
+		default:
+			return k, nil
+		}
+	}
+
+	return nil, err
+}
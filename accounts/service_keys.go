@@ -0,0 +1,411 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	flow_crypto "github.com/onflow/flow-go-sdk/crypto"
+	log "github.com/sirupsen/logrus"
+)
+
+// OnChainKeys returns the keys of an account as they are stored on chain,
+// including revoked keys.
+func (s *ServiceImpl) OnChainKeys(ctx context.Context, address string) ([]OnChainKey, error) {
+	account, err := s.Details(address)
+	if err != nil {
+		return nil, err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(account.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	return onChainKeys(flowAccount), nil
+}
+
+// AddKey adds a key to an account on chain. Generated custodial keys are
+// stored once the transaction has been sealed.
+// It returns a job, the updated account and a possible error.
+func (s *ServiceImpl) AddKey(ctx context.Context, sync bool, address string, key NewAccountKey) (*jobs.Job, *Account, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address}).Trace("Add account key")
+
+	address, err := s.validateCustodialAccount(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account keys can not be managed"),
+		}
+	}
+
+	if _, err := s.parseNewAccountKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	if !sync {
+		attrBytes, err := json.Marshal(accountKeyJobAttributes{Address: address, Key: &key})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		job, err := s.wp.CreateJob(AccountAddKeyJobType, "", jobs.WithAttributes(attrBytes), jobs.WithTenantID(tenants.FromContext(ctx)))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = s.wp.Schedule(job)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return job, nil, err
+	}
+
+	if _, _, err := s.addKey(ctx, address, key); err != nil {
+		return nil, nil, err
+	}
+
+	account, err := s.Details(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, &account, nil
+}
+
+// RevokeKey revokes a key of an account on chain and removes it from the
+// local key store once the transaction has been sealed.
+// It returns a job, the updated account and a possible error.
+func (s *ServiceImpl) RevokeKey(ctx context.Context, sync bool, address string, index int) (*jobs.Job, *Account, error) {
+	log.WithFields(log.Fields{"sync": sync, "address": address, "index": index}).Trace("Revoke account key")
+
+	address, err := s.validateCustodialAccount(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("admin account keys can not be managed"),
+		}
+	}
+
+	dbAccount, err := s.store.Account(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkRevocable(dbAccount, index); err != nil {
+		return nil, nil, err
+	}
+
+	if !sync {
+		attrBytes, err := json.Marshal(accountKeyJobAttributes{Address: address, Index: index})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		job, err := s.wp.CreateJob(AccountRevokeKeyJobType, "", jobs.WithAttributes(attrBytes), jobs.WithTenantID(tenants.FromContext(ctx)))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = s.wp.Schedule(job)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return job, nil, err
+	}
+
+	if _, err := s.revokeKey(ctx, address, index); err != nil {
+		return nil, nil, err
+	}
+
+	account, err := s.Details(address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nil, &account, nil
+}
+
+// addKey sends a transaction adding the key to the account and stores the
+// key if it was generated. Returns the on-chain index of the new key and
+// the flow transaction ID.
+func (s *ServiceImpl) addKey(ctx context.Context, address string, key NewAccountKey) (int, string, error) {
+	entry := log.WithFields(log.Fields{"address": address, "function": "ServiceImpl.addKey"})
+
+	flowKey, err := s.parseNewAccountKey(key)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var storable *keys.Storable
+
+	// Generate a new custodial key if no public key was given
+	if flowKey == nil {
+		var newPrivateKey *keys.Private
+		flowKey, newPrivateKey, err = s.km.Generate(ctx, 0, flow.AccountKeyWeightThreshold)
+		if err != nil {
+			return 0, "", err
+		}
+
+		encryptedKey, err := s.km.Save(*newPrivateKey)
+		if err != nil {
+			return 0, "", err
+		}
+		encryptedKey.PublicKey = flowKey.PublicKey.String()
+
+		storable = &encryptedKey
+	}
+
+	pbk, err := cadence.NewString(strings.TrimPrefix(flowKey.PublicKey.String(), "0x"))
+	if err != nil {
+		return 0, "", err
+	}
+
+	weight, err := cadence.NewUFix64(fmt.Sprintf("%d.0", flowKey.Weight))
+	if err != nil {
+		return 0, "", err
+	}
+
+	args := []transactions.Argument{
+		pbk,
+		cadence.NewUInt8(flow_helpers.CadenceSignatureAlgorithm(flowKey.SigAlgo)),
+		cadence.NewUInt8(flow_helpers.CadenceHashAlgorithm(flowKey.HashAlgo)),
+		weight,
+	}
+
+	// NOTE: sync, so will wait for transaction to be sent & sealed
+	_, tx, err := s.txs.Create(ctx, true, address, template_strings.AddAccountKeyTransaction, args, transactions.General)
+	if err != nil {
+		entry.WithFields(log.Fields{"err": err}).Error("failed to create transaction")
+		return 0, "", err
+	}
+
+	// Keys are appended, so the new key is the last one matching the public key
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return 0, tx.TransactionId, err
+	}
+
+	index := -1
+	for _, k := range flowAccount.Keys {
+		if !k.Revoked && k.PublicKey.Equals(flowKey.PublicKey) {
+			index = k.Index
+		}
+	}
+
+	if index < 0 {
+		return 0, tx.TransactionId, fmt.Errorf("added key not found on account %s", address)
+	}
+
+	if storable != nil {
+		dbAccount, err := s.store.Account(address)
+		if err != nil {
+			return 0, tx.TransactionId, err
+		}
+
+		storable.Index = index
+		dbAccount.Keys = append(dbAccount.Keys, *storable)
+
+		// TODO: if update fails, should sync keys from chain later
+		if err := s.store.SaveAccount(&dbAccount); err != nil {
+			entry.WithFields(log.Fields{"err": err}).Error("failed to update account in database")
+			return 0, tx.TransactionId, err
+		}
+	}
+
+	entry.WithFields(log.Fields{"index": index, "custodial": storable != nil}).Info("Account key added")
+
+	s.notify(webhooks.EventAccountKeyRotated, webhooks.AccountData{Address: address, TransactionID: tx.TransactionId, KeyIndex: &index, KeyChange: "added"})
+
+	return index, tx.TransactionId, nil
+}
+
+// revokeKey sends a transaction revoking the key and removes it from the
+// local key store. Returns the flow transaction ID.
+func (s *ServiceImpl) revokeKey(ctx context.Context, address string, index int) (string, error) {
+	entry := log.WithFields(log.Fields{"address": address, "index": index, "function": "ServiceImpl.revokeKey"})
+
+	dbAccount, err := s.store.Account(address)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkRevocable(dbAccount, index); err != nil {
+		return "", err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+
+	if index < 0 || index >= len(flowAccount.Keys) || flowAccount.Keys[index].Revoked {
+		return "", &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("key %d not found or already revoked", index),
+		}
+	}
+
+	args := []transactions.Argument{cadence.NewInt(index)}
+
+	// NOTE: sync, so will wait for transaction to be sent & sealed
+	_, tx, err := s.txs.Create(ctx, true, address, template_strings.RevokeAccountKeyTransaction, args, transactions.General)
+	if err != nil {
+		entry.WithFields(log.Fields{"err": err}).Error("failed to create transaction")
+		return "", err
+	}
+
+	// Revoked keys can not be used for signing anymore
+	if err := s.store.RevokeAccountKey(address, index); err != nil {
+		entry.WithFields(log.Fields{"err": err}).Error("failed to delete key from database")
+		return tx.TransactionId, err
+	}
+
+	entry.Info("Account key revoked")
+
+	s.notify(webhooks.EventAccountKeyRotated, webhooks.AccountData{Address: address, TransactionID: tx.TransactionId, KeyIndex: &index, KeyChange: "revoked"})
+
+	return tx.TransactionId, nil
+}
+
+// validateCustodialAccount checks that the address is valid and belongs to a
+// custodial account. Returns the formatted address.
+func (s *ServiceImpl) validateCustodialAccount(address string) (string, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return "", err
+	}
+
+	account, err := s.store.Account(address)
+	if err != nil {
+		return "", err
+	}
+
+	if account.Type != AccountTypeCustodial {
+		return "", fmt.Errorf("%w %s: no keys held for account", keys.ErrNonCustodialAccount, address)
+	}
+
+	return address, nil
+}
+
+// parseNewAccountKey validates the given key. Returns nil if a new key
+// should be generated.
+func (s *ServiceImpl) parseNewAccountKey(key NewAccountKey) (*flow.AccountKey, error) {
+	weight := keyWeight(key)
+	if weight < 0 || weight > flow.AccountKeyWeightThreshold {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid key weight %d, expected 0 to %d", weight, flow.AccountKeyWeightThreshold),
+		}
+	}
+
+	if key.PublicKey == "" {
+		// Generated keys are used for signing on their own
+		if key.SignAlgo != "" || key.HashAlgo != "" || weight != flow.AccountKeyWeightThreshold {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("signAlgo, hashAlgo and weight can only be set with a publicKey"),
+			}
+		}
+		return nil, nil
+	}
+
+	signAlgo := flow_crypto.StringToSignatureAlgorithm(key.SignAlgo)
+	if key.SignAlgo == "" {
+		signAlgo = flow_crypto.StringToSignatureAlgorithm(s.cfg.DefaultSignAlgo)
+	}
+
+	hashAlgo := flow_crypto.StringToHashAlgorithm(key.HashAlgo)
+	if key.HashAlgo == "" {
+		hashAlgo = flow_crypto.StringToHashAlgorithm(s.cfg.DefaultHashAlgo)
+	}
+
+	if flow_helpers.CadenceSignatureAlgorithm(signAlgo) == 0 || flow_helpers.CadenceHashAlgorithm(hashAlgo) == 0 {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("unsupported signature or hash algorithm: %q, %q", key.SignAlgo, key.HashAlgo),
+		}
+	}
+
+	pbk, err := flow_crypto.DecodePublicKeyHex(signAlgo, strings.TrimPrefix(key.PublicKey, "0x"))
+	if err != nil {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid public key: %w", err),
+		}
+	}
+
+	return &flow.AccountKey{
+		PublicKey: pbk,
+		SigAlgo:   signAlgo,
+		HashAlgo:  hashAlgo,
+		Weight:    weight,
+	}, nil
+}
+
+// onChainKeys converts the keys of a flow account, including revoked keys.
+func onChainKeys(flowAccount *flow.Account) []OnChainKey {
+	result := make([]OnChainKey, len(flowAccount.Keys))
+	for i, k := range flowAccount.Keys {
+		result[i] = OnChainKey{
+			Index:          k.Index,
+			PublicKey:      k.PublicKey.String(),
+			SignAlgo:       k.SigAlgo.String(),
+			HashAlgo:       k.HashAlgo.String(),
+			Weight:         k.Weight,
+			SequenceNumber: k.SequenceNumber,
+			Revoked:        k.Revoked,
+		}
+	}
+	return result
+}
+
+// keyWeight returns the requested key weight, full weight by default.
+func keyWeight(key NewAccountKey) int {
+	if key.Weight == nil {
+		return flow.AccountKeyWeightThreshold
+	}
+	return *key.Weight
+}
+
+// checkRevocable makes sure the account is left with at least one stored
+// key, otherwise the service could no longer sign for it.
+func checkRevocable(a Account, index int) error {
+	stored := false
+	for _, k := range a.Keys {
+		if k.Index == index {
+			stored = true
+		}
+	}
+
+	if stored && len(a.Keys) == 1 {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("can not revoke the last custodial key of the account"),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package accounts
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// SignedMessage is a user message signed by an account, in the format of
+// FCL user signatures.
+type SignedMessage struct {
+	Address    string               `json:"address"`
+	Message    string               `json:"message"`
+	Signatures []CompositeSignature `json:"signatures"`
+}
+
+// SignMessage signs an arbitrary hex encoded message with the keys of a
+// custodial account. The message is prefixed with the Flow user domain tag
+// before signing, as expected by FCL verifyUserSignatures and
+// AccountKey.verify in Cadence.
+func (s *ServiceImpl) SignMessage(ctx context.Context, address, message string) (*SignedMessage, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Sign user message")
+
+	address, err := s.validateCustodialAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("can not sign messages for the admin account"),
+		}
+	}
+
+	b, err := hex.DecodeString(strings.TrimPrefix(message, "0x"))
+	if err != nil || len(b) == 0 {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid message, expected a non-empty hex string"),
+		}
+	}
+
+	flowAddress := flow.HexToAddress(address)
+
+	signatures, err := s.signUserMessage(ctx, flowAddress, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedMessage{Address: address, Message: hex.EncodeToString(b), Signatures: signatures}, nil
+}
@@ -0,0 +1,172 @@
+package accounts
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// Number of accounts read from the datastore at a time when checking storage.
+const storageCheckPageSize = 100
+
+// CheckStorage updates the tracked storage usage of a custodial account from
+// the chain and schedules a top-up job if automatic top-ups are enabled and
+// the remaining capacity is below the threshold.
+func (s *ServiceImpl) CheckStorage(ctx context.Context, address string) (Account, error) {
+	log.WithFields(log.Fields{"address": address}).Trace("Check account storage")
+
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return Account{}, err
+	}
+
+	account, err := s.Details(address)
+	if err != nil {
+		return Account{}, err
+	}
+
+	if err := s.checkStorage(ctx, &account); err != nil {
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// CheckAllStorage checks the storage of all custodial accounts, see CheckStorage.
+// Failed checks are logged and do not stop checking the rest of the accounts.
+func (s *ServiceImpl) CheckAllStorage(ctx context.Context) error {
+	checked, failed := 0, 0
+
+	filter := ListFilter{Type: AccountTypeCustodial, Sort: "address"}
+
+	for offset := 0; ; offset += storageCheckPageSize {
+		aa, err := s.store.Accounts(datastore.ListOptions{Limit: storageCheckPageSize, Offset: offset}, filter)
+		if err != nil {
+			return err
+		}
+
+		for i := range aa {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := s.checkStorage(ctx, &aa[i]); err != nil {
+				failed++
+				log.
+					WithFields(log.Fields{"error": err, "address": aa[i].Address}).
+					Warn("Account storage check failed")
+				continue
+			}
+			checked++
+		}
+
+		if len(aa) < storageCheckPageSize {
+			break
+		}
+	}
+
+	log.WithFields(log.Fields{"checked": checked, "failed": failed}).Info("Checked account storage")
+
+	return nil
+}
+
+func (s *ServiceImpl) checkStorage(ctx context.Context, account *Account) error {
+	used, capacity, err := s.onChainStorage(ctx, flow.HexToAddress(account.Address))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.store.UpdateAccountStorage(account.Address, used, capacity, now); err != nil {
+		return err
+	}
+
+	account.StorageUsed = used
+	account.StorageCapacity = capacity
+	account.StorageCheckedAt = &now
+
+	if !s.needsStorageTopUp(account.Address, used, capacity) {
+		return nil
+	}
+
+	attrBytes, err := json.Marshal(accountStorageTopUpJobAttributes{Address: account.Address})
+	if err != nil {
+		return err
+	}
+
+	job := &jobs.Job{
+		State:      jobs.Init,
+		Type:       AccountStorageTopUpJobType,
+		Attributes: attrBytes,
+		TenantID:   account.TenantID,
+	}
+
+	// The previous top-up may not have been sent yet
+	if inserted, err := s.store.InsertStorageTopUpJob(account.Address, job); err != nil || !inserted {
+		return err
+	}
+
+	log.
+		WithFields(log.Fields{"address": account.Address, "storageUsed": used, "storageCapacity": capacity}).
+		Info("Account storage running low, scheduling top-up")
+
+	return s.wp.Schedule(job)
+}
+
+// topUpStorage funds the account with the configured amount of FLOW if its
+// storage is still running low. Returns the flow transaction ID of the
+// funding, empty if no top-up was needed.
+func (s *ServiceImpl) topUpStorage(ctx context.Context, address string) (string, error) {
+	// Storage may have been topped up since the check
+	used, capacity, err := s.onChainStorage(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+
+	if !s.needsStorageTopUp(address, used, capacity) {
+		return "", nil
+	}
+
+	return s.fundAccount(ctx, address, s.cfg.StorageTopUpAmount)
+}
+
+// needsStorageTopUp returns true if automatic top-ups are enabled and the
+// remaining storage capacity of the account is below the threshold.
+func (s *ServiceImpl) needsStorageTopUp(address string, used, capacity uint64) bool {
+	if s.cfg.StorageTopUpThreshold == 0 || address == flow_helpers.FormatAddress(flow.HexToAddress(s.cfg.AdminAddress)) {
+		return false
+	}
+
+	return capacity < used || capacity-used < s.cfg.StorageTopUpThreshold
+}
+
+// onChainStorage returns the storage used and the storage capacity of an
+// account in bytes.
+func (s *ServiceImpl) onChainStorage(ctx context.Context, address flow.Address) (uint64, uint64, error) {
+	storage, err := s.txs.ExecuteScript(
+		ctx,
+		template_strings.AccountStorageScript,
+		[]transactions.Argument{cadence.NewAddress(address)},
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var used, capacity cadence.UInt64
+	if values, ok := storage.(cadence.Array); ok && len(values.Values) == 2 {
+		used, _ = values.Values[0].(cadence.UInt64)
+		capacity, _ = values.Values[1].(cadence.UInt64)
+	}
+
+	return uint64(used), uint64(capacity), nil
+}
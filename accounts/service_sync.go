@@ -0,0 +1,165 @@
+package accounts
+
+import (
+	"context"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	flow_crypto "github.com/onflow/flow-go-sdk/crypto"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	KeySyncStatusOK        = "ok"
+	KeySyncStatusMissing   = "missing"   // Stored key index does not exist on chain
+	KeySyncStatusRevoked   = "revoked"   // Stored key has been revoked on chain
+	KeySyncStatusMismatch  = "mismatch"  // Public key differs from the one on chain
+	KeySyncStatusUntracked = "untracked" // On-chain key is not stored by the service
+)
+
+// SyncReport describes the differences found between the stored account and
+// the account on chain, and what was repaired.
+type SyncReport struct {
+	Address string            `json:"address"`
+	Keys    []KeySyncResult   `json:"keys"`
+	Vaults  []VaultSyncResult `json:"vaults"`
+}
+
+// KeySyncResult is the sync state of a single account key.
+type KeySyncResult struct {
+	Index          int     `json:"index"`
+	Status         string  `json:"status"`
+	SequenceNumber *uint64 `json:"sequenceNumber,omitempty"`
+	// Removed is true if the stored key was removed as it is revoked or missing on chain
+	Removed bool `json:"removed"`
+}
+
+// VaultSyncResult is the on-chain setup state of a fungible token vault.
+type VaultSyncResult struct {
+	TokenName string `json:"tokenName"`
+	SetUp     bool   `json:"setUp"`
+}
+
+// Sync compares the stored keys and the fungible token vaults of an account
+// against the chain. Stored keys that are missing or revoked on chain are soft
+// deleted, keys that do not match the on-chain key are only reported. Vaults
+// set up on chain are registered for token tracking.
+func (s *ServiceImpl) Sync(ctx context.Context, address string) (*SyncReport, error) {
+	entry := log.WithFields(log.Fields{"address": address, "function": "ServiceImpl.Sync"})
+
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbAccount, err := s.store.Account(address)
+	if err != nil {
+		return nil, err
+	}
+
+	flowAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(address))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SyncReport{Address: address, Keys: []KeySyncResult{}, Vaults: []VaultSyncResult{}}
+
+	stored := map[int]bool{}
+	for _, k := range dbAccount.Keys {
+		stored[k.Index] = true
+
+		result := KeySyncResult{Index: k.Index, Status: keySyncStatus(k.Index, k.PublicKey, k.SignAlgo, flowAccount)}
+		switch result.Status {
+		case KeySyncStatusOK:
+			result.SequenceNumber = &flowAccount.Keys[k.Index].SequenceNumber
+		case KeySyncStatusMissing, KeySyncStatusRevoked:
+			entry.WithFields(log.Fields{"index": k.Index, "status": result.Status}).Warn("Removing out of sync account key")
+			if err := s.store.RevokeAccountKey(address, k.Index); err != nil {
+				return nil, err
+			}
+			result.Removed = true
+		default:
+			// The stored public key may be missing, e.g. for keys stored
+			// before public keys were, so the private key is kept
+			entry.WithFields(log.Fields{"index": k.Index, "status": result.Status}).Warn("Account key does not match the on-chain key")
+		}
+
+		report.Keys = append(report.Keys, result)
+	}
+
+	for _, k := range flowAccount.Keys {
+		if !stored[k.Index] && !k.Revoked {
+			seq := k.SequenceNumber
+			report.Keys = append(report.Keys, KeySyncResult{Index: k.Index, Status: KeySyncStatusUntracked, SequenceNumber: &seq})
+		}
+	}
+
+	tokens, err := s.temps.ListTokensFull(templates.FT)
+	if err != nil {
+		return nil, err
+	}
+
+	setUp := []templates.Token{}
+	for _, t := range tokens {
+		ok, err := s.vaultSetUp(ctx, t, address)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok && t.Name != "FlowToken" {
+			setUp = append(setUp, t)
+		}
+
+		report.Vaults = append(report.Vaults, VaultSyncResult{TokenName: t.Name, SetUp: ok})
+	}
+
+	// Re-announce the account so vaults set up outside the service get tracked
+	AccountAdded.Trigger(AccountAddedPayload{
+		Address:                   flowAccount.Address,
+		InitializedFungibleTokens: setUp,
+	})
+
+	entry.Info("Account synced")
+
+	return report, nil
+}
+
+// keySyncStatus compares a stored key against the key at the same index on chain.
+func keySyncStatus(index int, publicKey, signAlgo string, flowAccount *flow.Account) string {
+	if index < 0 || index >= len(flowAccount.Keys) {
+		return KeySyncStatusMissing
+	}
+
+	onChain := flowAccount.Keys[index]
+	if onChain.Revoked {
+		return KeySyncStatusRevoked
+	}
+
+	pbk, err := flow_crypto.DecodePublicKeyHex(flow_crypto.StringToSignatureAlgorithm(signAlgo), strings.TrimPrefix(publicKey, "0x"))
+	if err != nil || !pbk.Equals(onChain.PublicKey) {
+		return KeySyncStatusMismatch
+	}
+
+	return KeySyncStatusOK
+}
+
+// vaultSetUp checks whether the fungible token vault of the account is set up.
+func (s *ServiceImpl) vaultSetUp(ctx context.Context, token templates.Token, address string) (bool, error) {
+	code, err := templates.FungibleVaultCheckCode(s.cfg.ChainID, &token)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.txs.ExecuteScript(ctx, code, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(address))})
+	if err != nil {
+		return false, err
+	}
+
+	ok, _ := res.(cadence.Bool)
+
+	return bool(ok), nil
+}
@@ -0,0 +1,59 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+)
+
+func TestCustomTemplateRestrictions(t *testing.T) {
+	ctx := context.Background()
+	multiSig := &MultiSig{Threshold: 2, KeyTypes: []string{keys.AccountKeyTypeLocal, keys.AccountKeyTypeLocal}}
+
+	singleKey := &templates.CreateAccountTemplate{Code: "transaction {}", SingleKey: true}
+	svc := &ServiceImpl{
+		cfg:   &configs.Config{MaxAccountBatchSize: 10},
+		temps: dummyTemplates{createAccount: singleKey},
+	}
+
+	testCases := []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "batch sync", fn: func() error {
+			_, _, err := svc.CreateBatch(ctx, true, 2)
+			return err
+		}},
+		{name: "batch async", fn: func() error {
+			_, _, err := svc.CreateBatch(ctx, false, 2)
+			return err
+		}},
+		{name: "batch job", fn: func() error {
+			_, _, err := svc.createAccounts(ctx, 2)
+			return err
+		}},
+		{name: "multiSig", fn: func() error {
+			_, _, err := svc.CreateWithRequest(ctx, false, CreateRequest{MultiSig: multiSig})
+			return err
+		}},
+		{name: "multiSig job", fn: func() error {
+			_, _, err := svc.createAccount(ctx, nil, multiSig, nil)
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var reqErr *wallet_errors.RequestError
+			if err := tc.fn(); !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %v", http.StatusBadRequest, err)
+			}
+		})
+	}
+}
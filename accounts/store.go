@@ -1,23 +1,98 @@
 package accounts
 
 import (
+	"time"
+
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/google/uuid"
 )
 
 // Store manages data regarding accounts.
 type Store interface {
-	// List all accounts.
-	Accounts(datastore.ListOptions) ([]Account, error)
+	// List accounts matching the filter.
+	Accounts(datastore.ListOptions, ListFilter) ([]Account, error)
+
+	// Iterate over all accounts matching the filter using a database cursor,
+	// ordered by creation time. Keys are not loaded.
+	EachAccount(f ListFilter, fn func(Account) error) error
 
 	// Get account details.
 	Account(address string) (Account, error)
 
+	// Get the details of the accounts with the given addresses, restricted to
+	// a tenant if tenantID is not empty. Unknown addresses are skipped.
+	AccountsByAddress(addresses []string, tenantID string) ([]Account, error)
+
+	// Get the tenant of an account, including disabled accounts.
+	AccountTenant(address string) (string, error)
+
 	// Insert a new account.
 	InsertAccount(a *Account) error
 
 	// Update an existing account.
 	SaveAccount(a *Account) error
 
+	// Update the label of an existing account.
+	UpdateAccountLabel(address, label string) error
+
+	// Set or clear (nil) the alias of an existing account.
+	UpdateAccountAlias(address string, alias *string) error
+
+	// Get an account of a tenant by its alias, including disabled accounts.
+	AccountByAlias(tenantID, alias string) (Account, error)
+
+	// Freeze or unfreeze an existing account.
+	UpdateAccountFrozen(address string, frozen bool, reason string) error
+
+	// Check whether an account is frozen, unknown accounts are not.
+	IsFrozen(address string) (bool, error)
+
+	// Update the tracked storage usage of an existing account.
+	UpdateAccountStorage(address string, used, capacity uint64, checkedAt time.Time) error
+
+	// Insert a storage top-up job of an account unless a previous top-up job
+	// of the account is not finished yet. Returns whether it was inserted.
+	InsertStorageTopUpJob(address string, job *jobs.Job) (bool, error)
+
+	// Soft delete an account key which has been revoked or is missing on
+	// chain. The key is kept for auditing but no longer used for signing.
+	RevokeAccountKey(address string, index int) error
+
+	// Soft delete an account and its keys. Keys are kept for auditing.
+	DisableAccount(address string) error
+
+	// Restore a soft deleted account and its keys.
+	EnableAccount(address string) error
+
 	// Permanently delete an account, despite of `DeletedAt` field.
 	HardDeleteAccount(a *Account) error
+
+	// Get an account creation idempotency key along with its job.
+	IdempotencyKey(key string) (IdempotencyKey, error)
+
+	// Insert a new account creation idempotency key, fails if it exists.
+	InsertIdempotencyKey(k *IdempotencyKey) error
+
+	// Update the outcome of an account creation idempotency key.
+	SaveIdempotencyKey(k *IdempotencyKey) error
+
+	// Delete an account creation idempotency key.
+	DeleteIdempotencyKey(key string) error
+
+	// List key recoveries of an account, newest first.
+	KeyRecoveries(address string) ([]KeyRecovery, error)
+
+	// List key recoveries of an account which are pending, approved or executing.
+	OpenKeyRecoveries(address string) ([]KeyRecovery, error)
+
+	// Get a key recovery of an account.
+	KeyRecovery(address string, id uuid.UUID) (KeyRecovery, error)
+
+	// Insert a new key recovery.
+	InsertKeyRecovery(r *KeyRecovery) error
+
+	// Update a key recovery if its stored status still equals fromStatus.
+	// Returns false if the status had changed.
+	UpdateKeyRecovery(r *KeyRecovery, fromStatus KeyRecoveryStatus) (bool, error)
 }
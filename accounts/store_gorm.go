@@ -1,40 +1,447 @@
 package accounts
 
 import (
+	"errors"
+	"time"
+
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type GormStore struct {
 	db *gorm.DB
+	// keysDB holds account keys, equals db unless a separate
+	// database is used for key material
+	keysDB *gorm.DB
 }
 
 func NewGormStore(db *gorm.DB) Store {
-	return &GormStore{db}
+	return &GormStore{db, db}
 }
 
-func (s *GormStore) Accounts(o datastore.ListOptions) (aa []Account, err error) {
-	err = s.db.
-		Order("created_at desc").
+// NewGormStoreWithKeysDB creates a store which keeps account keys
+// in a separate database.
+func NewGormStoreWithKeysDB(db, keysDB *gorm.DB) Store {
+	if keysDB == nil {
+		keysDB = db
+	}
+	return &GormStore{db, keysDB}
+}
+
+func (s *GormStore) separateKeysDB() bool {
+	return s.keysDB != s.db
+}
+
+func (s *GormStore) Accounts(o datastore.ListOptions, f ListFilter) (aa []Account, err error) {
+	order, err := f.order()
+	if err != nil {
+		return nil, err
+	}
+
+	q := s.db.Where(&Account{Type: f.Type, Label: f.Label, TenantID: f.TenantID})
+
+	if f.CreatedAfter != nil {
+		q = q.Where("created_at >= ?", *f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != nil {
+		q = q.Where("created_at < ?", *f.CreatedBefore)
+	}
+
+	err = q.
+		// Address as a tiebreaker keeps pagination stable
+		Order(order).
+		Order("address asc").
 		Limit(o.Limit).
 		Offset(o.Offset).
 		Find(&aa).Error
 	return
 }
 
+func (s *GormStore) EachAccount(f ListFilter, fn func(Account) error) error {
+	q := s.db.Model(&Account{}).Where(&Account{Type: f.Type, Label: f.Label, TenantID: f.TenantID})
+
+	if f.CreatedAfter != nil {
+		q = q.Where("created_at >= ?", *f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != nil {
+		q = q.Where("created_at < ?", *f.CreatedBefore)
+	}
+
+	rows, err := q.Order("created_at asc").Order("address asc").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Account
+		if err := s.db.ScanRows(rows, &a); err != nil {
+			return err
+		}
+
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (s *GormStore) Account(address string) (a Account, err error) {
-	err = s.db.Preload("Keys").First(&a, "address = ?", address).Error
+	if !s.separateKeysDB() {
+		err = s.db.Preload("Keys").First(&a, "address = ?", address).Error
+		return
+	}
+
+	if err = s.db.First(&a, "address = ?", address).Error; err != nil {
+		return
+	}
+
+	err = s.keysDB.Where(&keys.Storable{AccountAddress: a.Address}).Find(&a.Keys).Error
+	return
+}
+
+func (s *GormStore) AccountsByAddress(addresses []string, tenantID string) (aa []Account, err error) {
+	q := s.db.Where(&Account{TenantID: tenantID}).Where("address IN ?", addresses)
+
+	if !s.separateKeysDB() {
+		err = q.Preload("Keys").Find(&aa).Error
+		return
+	}
+
+	if err = q.Find(&aa).Error; err != nil || len(aa) == 0 {
+		return
+	}
+
+	found := make([]string, len(aa))
+	byAddress := make(map[string]*Account, len(aa))
+	for i := range aa {
+		found[i] = aa[i].Address
+		byAddress[aa[i].Address] = &aa[i]
+	}
+
+	var kk []keys.Storable
+	if err = s.keysDB.Where("account_address IN ?", found).Find(&kk).Error; err != nil {
+		return
+	}
+
+	for _, k := range kk {
+		a := byAddress[k.AccountAddress]
+		a.Keys = append(a.Keys, k)
+	}
+
 	return
 }
 
 func (s *GormStore) InsertAccount(a *Account) error {
-	return s.db.Create(a).Error
+	if !s.separateKeysDB() {
+		return s.db.Create(a).Error
+	}
+
+	// Keys are stored first so an account is never left without its keys
+	if err := s.saveKeys(a); err != nil {
+		return err
+	}
+
+	return s.db.Omit("Keys").Create(a).Error
 }
 
 func (s *GormStore) SaveAccount(a *Account) error {
-	return s.db.Save(&a).Error
+	// The top-up job claims the account, see InsertStorageTopUpJob
+	if !s.separateKeysDB() {
+		return s.db.Omit("StorageTopUpJobID").Save(&a).Error
+	}
+
+	if err := s.saveKeys(a); err != nil {
+		return err
+	}
+
+	return s.db.Omit("Keys", "StorageTopUpJobID").Save(a).Error
+}
+
+func (s *GormStore) UpdateAccountLabel(address, label string) error {
+	res := s.db.Model(&Account{}).Where("address = ?", address).Update("label", label)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *GormStore) RevokeAccountKey(address string, index int) error {
+	now := time.Now()
+	return s.keysDB.
+		Unscoped().
+		Model(&keys.Storable{}).
+		// Map conditions so the reserved "index" column name gets quoted
+		Where(map[string]interface{}{"account_address": address, "index": index}).
+		Updates(map[string]interface{}{"revoked_at": now, "deleted_at": now}).Error
+}
+
+func (s *GormStore) UpdateAccountAlias(address string, alias *string) error {
+	res := s.db.Model(&Account{}).Where("address = ?", address).Update("alias", alias)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *GormStore) AccountByAlias(tenantID, alias string) (a Account, err error) {
+	err = s.db.Unscoped().
+		// Map conditions so the empty tenant ID is not ignored
+		Where(map[string]interface{}{"tenant_id": tenantID, "alias": alias}).
+		First(&a).Error
+	return
+}
+
+func (s *GormStore) UpdateAccountFrozen(address string, frozen bool, reason string) error {
+	res := s.db.Model(&Account{}).
+		Where("address = ?", address).
+		Updates(map[string]interface{}{"frozen": frozen, "frozen_reason": reason})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *GormStore) IsFrozen(address string) (bool, error) {
+	var count int64
+	err := s.db.Model(&Account{}).Where("address = ? AND frozen = ?", address, true).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *GormStore) UpdateAccountStorage(address string, used, capacity uint64, checkedAt time.Time) error {
+	return s.db.Model(&Account{}).
+		Where("address = ?", address).
+		// Not an update of the account itself, UpdatedAt is left as is
+		UpdateColumns(map[string]interface{}{
+			"storage_used":       used,
+			"storage_capacity":   capacity,
+			"storage_checked_at": checkedAt,
+		}).Error
+}
+
+// errStorageTopUpPending rolls back the insertion of a storage top-up job.
+var errStorageTopUpPending = errors.New("storage top-up pending")
+
+func (s *GormStore) InsertStorageTopUpJob(address string, job *jobs.Job) (bool, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+
+		unfinished := tx.Model(&jobs.Job{}).
+			Select("1").
+			Where("jobs.id = accounts.storage_top_up_job_id AND jobs.state IN ?", []string{
+				string(jobs.Init), string(jobs.Accepted), string(jobs.NoAvailableWorkers), string(jobs.Error),
+			})
+
+		// Claims the account, concurrent checks can not both get it
+		res := tx.Model(&Account{}).
+			Where("address = ?", address).
+			Where("storage_top_up_job_id IS NULL OR NOT EXISTS (?)", unfinished).
+			UpdateColumn("storage_top_up_job_id", job.ID)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return errStorageTopUpPending
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errStorageTopUpPending) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (s *GormStore) DisableAccount(address string) error {
+	if !s.separateKeysDB() {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			if err := disableKeys(tx, address); err != nil {
+				return err
+			}
+			return disableAccount(tx, address)
+		})
+	}
+
+	// Keys are disabled first so signing is blocked even if
+	// disabling the account record fails
+	if err := disableKeys(s.keysDB, address); err != nil {
+		return err
+	}
+
+	return disableAccount(s.db, address)
+}
+
+func (s *GormStore) EnableAccount(address string) error {
+	if !s.separateKeysDB() {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			if err := enableAccount(tx, address); err != nil {
+				return err
+			}
+			return enableKeys(tx, address)
+		})
+	}
+
+	if err := enableAccount(s.db, address); err != nil {
+		return err
+	}
+
+	return enableKeys(s.keysDB, address)
+}
+
+func disableAccount(db *gorm.DB, address string) error {
+	res := db.Where("address = ?", address).Delete(&Account{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func enableAccount(db *gorm.DB, address string) error {
+	res := db.Unscoped().
+		Model(&Account{}).
+		Where("address = ? AND deleted_at IS NOT NULL", address).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func disableKeys(db *gorm.DB, address string) error {
+	return db.Where("account_address = ?", address).Delete(&keys.Storable{}).Error
+}
+
+func enableKeys(db *gorm.DB, address string) error {
+	return db.Unscoped().
+		Model(&keys.Storable{}).
+		Where("account_address = ? AND deleted_at IS NOT NULL AND revoked_at IS NULL", address).
+		Update("deleted_at", nil).Error
+}
+
+func (s *GormStore) AccountTenant(address string) (string, error) {
+	a := Account{}
+	err := s.db.Unscoped().Select("address", "tenant_id").First(&a, "address = ?", address).Error
+	return a.TenantID, err
 }
 
 func (s *GormStore) HardDeleteAccount(a *Account) error {
+	if !s.separateKeysDB() {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			if err := hardDeleteKeys(tx, a.Address); err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(a).Error
+		})
+	}
+
+	// Keys are deleted first so no key material is left behind
+	// for an account which no longer exists
+	if err := hardDeleteKeys(s.keysDB, a.Address); err != nil {
+		return err
+	}
+
 	return s.db.Unscoped().Delete(a).Error
 }
+
+func hardDeleteKeys(db *gorm.DB, address string) error {
+	return db.Unscoped().Where("account_address = ?", address).Delete(&keys.Storable{}).Error
+}
+
+func (s *GormStore) saveKeys(a *Account) error {
+	if len(a.Keys) == 0 {
+		return nil
+	}
+
+	for i := range a.Keys {
+		a.Keys[i].AccountAddress = a.Address
+	}
+
+	return s.keysDB.Save(&a.Keys).Error
+}
+
+func (s *GormStore) IdempotencyKey(key string) (k IdempotencyKey, err error) {
+	err = s.db.Preload("Job").Where(&IdempotencyKey{Key: key}).First(&k).Error
+	return
+}
+
+func (s *GormStore) InsertIdempotencyKey(k *IdempotencyKey) error {
+	return s.db.Omit("Job").Create(k).Error
+}
+
+func (s *GormStore) SaveIdempotencyKey(k *IdempotencyKey) error {
+	return s.db.Omit("Job").Save(k).Error
+}
+
+func (s *GormStore) DeleteIdempotencyKey(key string) error {
+	return s.db.Where(&IdempotencyKey{Key: key}).Delete(&IdempotencyKey{}).Error
+}
+
+func (s *GormStore) KeyRecoveries(address string) (rr []KeyRecovery, err error) {
+	err = s.db.
+		Where(&KeyRecovery{AccountAddress: address}).
+		Order("created_at desc").
+		Find(&rr).Error
+	return
+}
+
+func (s *GormStore) OpenKeyRecoveries(address string) (rr []KeyRecovery, err error) {
+	err = s.db.
+		Where(&KeyRecovery{AccountAddress: address}).
+		Where("status IN ?", []KeyRecoveryStatus{KeyRecoveryPending, KeyRecoveryApproved, KeyRecoveryExecuting}).
+		Find(&rr).Error
+	return
+}
+
+func (s *GormStore) KeyRecovery(address string, id uuid.UUID) (r KeyRecovery, err error) {
+	err = s.db.Where(&KeyRecovery{ID: id, AccountAddress: address}).First(&r).Error
+	return
+}
+
+func (s *GormStore) InsertKeyRecovery(r *KeyRecovery) error {
+	return s.db.Create(r).Error
+}
+
+func (s *GormStore) UpdateKeyRecovery(r *KeyRecovery, fromStatus KeyRecoveryStatus) (bool, error) {
+	res := s.db.Model(r).Where("status = ?", fromStatus).Select("*").Updates(r)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
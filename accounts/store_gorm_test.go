@@ -0,0 +1,208 @@
+package accounts
+
+import (
+	"path"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	gorm_lib "gorm.io/gorm"
+)
+
+func newTestDatabases(t *testing.T) (db, keysDB *gorm_lib.DB) {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := &configs.Config{
+		DatabaseType:    "sqlite",
+		DatabaseDSN:     path.Join(dir, "test.db"),
+		KeysDatabaseDSN: path.Join(dir, "keys.db"),
+	}
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gorm.Close(db) })
+
+	keysDB, err = gorm.NewKeys(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gorm.Close(keysDB) })
+
+	return db, keysDB
+}
+
+func countKeys(t *testing.T, db *gorm_lib.DB, address string) int64 {
+	t.Helper()
+
+	var count int64
+	if err := db.Unscoped().Model(&keys.Storable{}).Where("account_address = ?", address).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func TestGormStoreWithKeysDB(t *testing.T) {
+	db, keysDB := newTestDatabases(t)
+	store := NewGormStoreWithKeysDB(db, keysDB)
+
+	address := "0x01cf0e2f2f715450"
+
+	a := &Account{
+		Address: address,
+		Keys:    []keys.Storable{{Index: 0, Type: keys.AccountKeyTypeLocal, Value: []byte("secret"), PublicKey: "0x01"}},
+	}
+
+	if err := store.InsertAccount(a); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countKeys(t, keysDB, address); n != 1 {
+		t.Fatalf("expected the key in the keys database, got %d", n)
+	}
+
+	if n := countKeys(t, db, address); n != 0 {
+		t.Fatalf("expected no keys in the main database, got %d", n)
+	}
+
+	stored, err := store.Account(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored.Keys) != 1 || string(stored.Keys[0].Value) != "secret" {
+		t.Fatalf("expected the account with its key, got %+v", stored)
+	}
+
+	aa, err := store.AccountsByAddress([]string{address}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(aa) != 1 || len(aa[0].Keys) != 1 {
+		t.Fatalf("expected the account with its key, got %+v", aa)
+	}
+
+	// Disabled accounts can not sign
+	if err := store.DisableAccount(address); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Account(address); err == nil {
+		t.Fatal("expected a disabled account not to be found")
+	}
+
+	if err := keysDB.First(&keys.Storable{}, "account_address = ?", address).Error; err == nil {
+		t.Fatal("expected the key of a disabled account to be disabled")
+	}
+
+	if err := store.EnableAccount(address); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err = store.Account(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored.Keys) != 1 {
+		t.Fatalf("expected the key to be enabled, got %+v", stored)
+	}
+
+	if err := store.HardDeleteAccount(&stored); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Account(address); err == nil {
+		t.Fatal("expected the account to be deleted")
+	}
+
+	if n := countKeys(t, keysDB, address); n != 0 {
+		t.Fatalf("expected the keys to be deleted, got %d", n)
+	}
+}
+
+func TestHardDeleteAccount(t *testing.T) {
+	db, _ := newTestDatabases(t)
+
+	// Without a keys database the main database holds the keys
+	store := NewGormStoreWithKeysDB(db, nil)
+
+	address := "0x01cf0e2f2f715450"
+
+	a := &Account{
+		Address: address,
+		Keys:    []keys.Storable{{Index: 0, Type: keys.AccountKeyTypeLocal, Value: []byte("secret")}},
+	}
+
+	if err := store.InsertAccount(a); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := countKeys(t, db, address); n != 1 {
+		t.Fatalf("expected the key in the main database, got %d", n)
+	}
+
+	if err := store.HardDeleteAccount(a); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Unscoped().First(&Account{}, "address = ?", address).Error; err == nil {
+		t.Fatal("expected the account to be deleted")
+	}
+
+	if n := countKeys(t, db, address); n != 0 {
+		t.Fatalf("expected the keys to be deleted, got %d", n)
+	}
+}
+
+func TestInsertStorageTopUpJob(t *testing.T) {
+	db, _ := newTestDatabases(t)
+	store := NewGormStore(db)
+
+	address := "0x01cf0e2f2f715450"
+
+	if err := store.InsertAccount(&Account{Address: address}); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := func(expected bool) *jobs.Job {
+		t.Helper()
+
+		job := &jobs.Job{State: jobs.Init, Type: AccountStorageTopUpJobType}
+		inserted, err := store.InsertStorageTopUpJob(address, job)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if inserted != expected {
+			t.Fatalf("expected inserted %t, got %t", expected, inserted)
+		}
+
+		return job
+	}
+
+	job := insert(true)
+
+	// The account is claimed until its job is finished
+	insert(false)
+
+	var count int64
+	if err := db.Model(&jobs.Job{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the second job not to be inserted, got %d jobs", count)
+	}
+
+	if err := db.Model(job).Update("state", jobs.Complete).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	insert(true)
+}
@@ -13,6 +13,8 @@ import (
 	flow_templates "github.com/onflow/flow-go-sdk/templates"
 )
 
+const maxGasLimit = 9999
+
 // AddContract is used only in tests
 func AddContract(
 	ctx context.Context,
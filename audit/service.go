@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// Service lists all functionality provided by the audit service.
+type Service interface {
+	Record(e *Entry) error
+	List(limit, offset int, f ListFilter) ([]Entry, error)
+	Details(id, tenantID string) (*Entry, error)
+}
+
+// ServiceImpl implements the audit Service.
+type ServiceImpl struct {
+	store Store
+}
+
+// NewService initiates a new audit service.
+func NewService(store Store) Service {
+	return &ServiceImpl{store}
+}
+
+// Record stores an audit log entry.
+func (s *ServiceImpl) Record(e *Entry) error {
+	return s.store.InsertEntry(e)
+}
+
+// List returns audit log entries, newest first.
+func (s *ServiceImpl) List(limit, offset int, f ListFilter) ([]Entry, error) {
+	log.WithFields(log.Fields{"limit": limit, "offset": offset, "tenantID": f.TenantID}).Trace("List audit log")
+
+	return s.store.Entries(datastore.ParseListOptions(limit, offset), f)
+}
+
+// Details returns an audit log entry, entries of other tenants are not found.
+func (s *ServiceImpl) Details(id, tenantID string) (*Entry, error) {
+	entryID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid audit log entry id"),
+		}
+	}
+
+	e, err := s.store.Entry(entryID)
+	if err == nil && tenantID != "" && tenantID != e.TenantID {
+		err = fmt.Errorf("record not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
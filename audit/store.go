@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/google/uuid"
+)
+
+// Store manages the audit log.
+type Store interface {
+	InsertEntry(e *Entry) error
+	Entries(o datastore.ListOptions, f ListFilter) ([]Entry, error)
+	Entry(id uuid.UUID) (Entry, error)
+}
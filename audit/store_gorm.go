@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) Store {
+	return &GormStore{db}
+}
+
+func (s *GormStore) InsertEntry(e *Entry) error {
+	return s.db.Create(e).Error
+}
+
+func (s *GormStore) Entries(o datastore.ListOptions, f ListFilter) (ee []Entry, err error) {
+	q := s.db.Where(&Entry{TenantID: f.TenantID, Address: f.Address})
+
+	if f.CreatedAfter != nil {
+		q = q.Where("created_at >= ?", *f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != nil {
+		q = q.Where("created_at < ?", *f.CreatedBefore)
+	}
+
+	err = q.
+		Order("created_at desc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&ee).Error
+	return
+}
+
+func (s *GormStore) Entry(id uuid.UUID) (e Entry, err error) {
+	err = s.db.First(&e, "id = ?", id).Error
+	return
+}
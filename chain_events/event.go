@@ -17,6 +17,21 @@ type chainEvent struct {
 
 var ChainEvent chainEvent // singleton of type event
 
+type blockHeightKey struct{}
+
+// WithBlockHeight returns a copy of ctx carrying the height of the block of
+// the event being handled.
+func WithBlockHeight(ctx context.Context, height uint64) context.Context {
+	return context.WithValue(ctx, blockHeightKey{}, height)
+}
+
+// BlockHeight returns the height of the block of the event being handled,
+// 0 if unknown.
+func BlockHeight(ctx context.Context) uint64 {
+	height, _ := ctx.Value(blockHeightKey{}).(uint64)
+	return height
+}
+
 // Register adds an event handler for this event
 func (e *chainEvent) Register(handler chainEventHandler) {
 	log.Debug("Registering Flow event handler")
@@ -76,7 +76,7 @@ func NewListener(
 }
 
 func (l *ListenerImpl) run(ctx context.Context, start, end uint64) error {
-	events := make([]flow.Event, 0)
+	events := make([]flow.BlockEvents, 0)
 
 	eventTypes, err := l.getTypes()
 	if err != nil {
@@ -91,8 +91,8 @@ func (l *ListenerImpl) run(ctx context.Context, start, end uint64) error {
 		count := 0
 		for _, b := range r {
 			count += len(b.Events)
-			events = append(events, b.Events...)
 		}
+		events = append(events, r...)
 		log.
 			WithFields(log.Fields{
 				"type":        t,
@@ -103,8 +103,11 @@ func (l *ListenerImpl) run(ctx context.Context, start, end uint64) error {
 			Debug("Fetching events")
 	}
 
-	for _, event := range events {
-		ChainEvent.Trigger(ctx, event)
+	for _, b := range events {
+		blockCtx := WithBlockHeight(ctx, b.Height)
+		for _, event := range b.Events {
+			ChainEvent.Trigger(blockCtx, event)
+		}
 	}
 
 	return nil
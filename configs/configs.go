@@ -23,6 +23,13 @@ type Config struct {
 	DisableFungibleTokens    bool `env:"DISABLE_FT"`
 	DisableNonFungibleTokens bool `env:"DISABLE_NFT"`
 	DisableChainEvents       bool `env:"DISABLE_CHAIN_EVENTS"`
+	// Only execute scripts from clients which are in the script allowlist,
+	// managed with the /system/allowlist/scripts endpoints.
+	EnforceScriptAllowlist bool `env:"ENFORCE_SCRIPT_ALLOWLIST"`
+	// Only sign transactions from clients whose code is in the transaction
+	// allowlist, managed with the /system/allowlist/transactions endpoints.
+	// Transaction templates are allowed regardless.
+	EnforceTransactionAllowlist bool `env:"ENFORCE_TRANSACTION_ALLOWLIST"`
 
 	// -- Admin account --
 
@@ -34,6 +41,14 @@ type Config struct {
 	// You can increase transaction throughput by using multiple proposal keys for
 	// parallel transaction execution.
 	AdminProposalKeyCount uint16 `env:"ADMIN_PROPOSAL_KEY_COUNT" envDefault:"1"`
+	// Interval at which the admin key is automatically rotated, 0 disables rotation.
+	// Only supported for "local" admin keys. The rotated key is stored (encrypted)
+	// in the database and overrides AdminPrivateKey & AdminKeyIndex.
+	AdminKeyRotationInterval time.Duration `env:"ADMIN_KEY_ROTATION_INTERVAL" envDefault:"0"`
+	// When set, admin key rotations are only logged, nothing is sent to chain.
+	AdminKeyRotationDryRun bool `env:"ADMIN_KEY_ROTATION_DRY_RUN" envDefault:"false"`
+	// Webhook endpoint to receive alerts about failed admin key rotations
+	AdminKeyRotationAlertWebhookUrl string `env:"ADMIN_KEY_ROTATION_ALERT_WEBHOOK" envDefault:""`
 
 	// -- Keys --
 
@@ -54,17 +69,64 @@ type Config struct {
 	// - local: 32 bytes long encryption key
 	// - aws_kms: key ARN, e.g. arn:aws:kms:us-west-1:123456789000:key/00000000-1111-2222-3333-444444444444
 	// - google_kms: key resource name (without version info), e.g. projects/my-project/locations/europe-north1/keyRings/my-keyring/cryptoKeys/my-encryption-key
+	// - tpm: TPM device path, e.g. /dev/tpmrm0
 	EncryptionKey string `env:"ENCRYPTION_KEY,notEmpty"`
-	// Encryption key type, one of: local, aws_kms, google_kms
+	// Encryption key type, one of: local, aws_kms, google_kms, tpm
 	EncryptionKeyType string `env:"ENCRYPTION_KEY_TYPE,notEmpty" envDefault:"local"`
+	// Local AES key used to decrypt account import files (see the -import-accounts flag).
+	ImportEncryptionKey string `env:"IMPORT_ENCRYPTION_KEY" envDefault:""`
 	// DefaultAccountKeyCount specifies how many times the account key will be duplicated upon account creation, does not affect existing accounts
 	DefaultAccountKeyCount uint `env:"DEFAULT_ACCOUNT_KEY_COUNT" envDefault:"1"`
+	// Maximum number of accounts that can be created in a single batch account creation transaction
+	MaxAccountBatchSize uint `env:"MAX_ACCOUNT_BATCH_SIZE" envDefault:"50"`
+	// Maximum number of addresses in a single bulk account details lookup
+	MaxBulkAccountDetails uint `env:"MAX_BULK_ACCOUNT_DETAILS" envDefault:"100"`
+	// Interval at which queued account creations are coalesced into batch
+	// creation transactions (at most MaxAccountBatchSize accounts each),
+	// roughly the block time. 0 disables coalescing.
+	AccountCreationBatchInterval time.Duration `env:"ACCOUNT_CREATION_BATCH_INTERVAL" envDefault:"0"`
+	// Interval at which the storage usage of custodial accounts is checked, 0 disables checks.
+	StorageCheckInterval time.Duration `env:"STORAGE_CHECK_INTERVAL" envDefault:"0"`
+	// Accounts with less remaining storage capacity (in bytes) are topped up
+	// with StorageTopUpAmount of FLOW from the admin account, 0 disables top-ups.
+	StorageTopUpThreshold uint64 `env:"STORAGE_TOP_UP_THRESHOLD" envDefault:"0"`
+	// Amount of FLOW transferred per top-up, 1 FLOW buys 100 MB of storage
+	StorageTopUpAmount string `env:"STORAGE_TOP_UP_AMOUNT" envDefault:"0.01"`
+	// Maximum number of account creation transactions in flight at once, 0 means no limit
+	MaxInFlightAccountCreations uint `env:"MAX_IN_FLIGHT_ACCOUNT_CREATIONS" envDefault:"0"`
+	// Maximum number of signatures a single account key (including admin keys)
+	// may produce per second and per minute, 0 disables the limit.
+	// Signing over the limit fails with a retryable error.
+	SigningRateLimitPerSecond uint `env:"SIGNING_RATE_LIMIT_PER_SECOND" envDefault:"0"`
+	SigningRateLimitPerMinute uint `env:"SIGNING_RATE_LIMIT_PER_MINUTE" envDefault:"0"`
+	// Time between the approval of a key recovery and the earliest moment
+	// it can be executed, giving the account owner time to cancel it.
+	KeyRecoveryDelay time.Duration `env:"KEY_RECOVERY_DELAY" envDefault:"48h"`
+
+	// -- Tenants --
+
+	// API keys of tenants as "tenantID:apiKey" pairs, separated by commas.
+	// When set, requests need an "Authorization: Bearer <api key>" header and
+	// accounts, transactions and jobs are scoped to the tenant of the key.
+	TenantAPIKeys []string `env:"TENANT_API_KEYS" envSeparator:","`
+	// API keys of admins as "adminID:apiKey" pairs, separated by commas.
+	// Admin requests are not scoped to a tenant and only they can use the
+	// /system and /ops endpoints and change token templates once tenant API
+	// keys are set. Withdrawals pending approval are approved by an admin
+	// other than the one who requested them, the admin ID is recorded as the
+	// approver of key recoveries.
+	AdminAPIKeys []string `env:"ADMIN_API_KEYS" envSeparator:","`
 
 	// -- Database --
 
 	DatabaseDSN     string `env:"DATABASE_DSN" envDefault:"wallet.db"`
 	DatabaseType    string `env:"DATABASE_TYPE" envDefault:"sqlite"`
 	DatabaseVersion string `env:"DATABASE_VERSION" envDefault:""`
+	// Optional separate database for key material (account keys, admin
+	// proposal keys and rotated admin keys). When empty, the main database is used.
+	// KeysDatabaseType defaults to DatabaseType.
+	KeysDatabaseDSN  string `env:"KEYS_DATABASE_DSN" envDefault:""`
+	KeysDatabaseType string `env:"KEYS_DATABASE_TYPE" envDefault:""`
 
 	// -- Host and chain access --
 
@@ -76,9 +138,46 @@ type Config struct {
 
 	// -- Templates --
 
-	EnabledTokens                            []string `env:"ENABLED_TOKENS" envSeparator:","`
-	ScriptPathCreateAccount                  string   `env:"SCRIPT_PATH_CREATE_ACCOUNT" envDefault:""`
-	InitFungibleTokenVaultsOnAccountCreation bool     `env:"INIT_FUNGIBLE_TOKEN_VAULTS_ON_ACCOUNT_CREATION" envDefault:"false"`
+	EnabledTokens []string `env:"ENABLED_TOKENS" envSeparator:","`
+	// JSON file of fungible tokens with their contract address per chain,
+	// see README for the format.
+	TokenRegistryFile string `env:"TOKEN_REGISTRY_FILE" envDefault:""`
+	// Address of the NFTStorefrontV2 contract used for NFT sale listings,
+	// defaults to its address on testnet and mainnet.
+	NFTStorefrontAddress string `env:"NFT_STOREFRONT_ADDRESS" envDefault:""`
+	// Custom account creation transaction, either from a file or inline.
+	// Validated at startup, see README for the supported placeholders.
+	ScriptPathCreateAccount                  string `env:"SCRIPT_PATH_CREATE_ACCOUNT" envDefault:""`
+	CreateAccountTemplate                    string `env:"CREATE_ACCOUNT_TEMPLATE" envDefault:""`
+	InitFungibleTokenVaultsOnAccountCreation bool   `env:"INIT_FUNGIBLE_TOKEN_VAULTS_ON_ACCOUNT_CREATION" envDefault:"false"`
+	// Set up the missing fungible token vault of a custodial recipient of a
+	// transfer before sending it, instead of the transfer failing.
+	SetupRecipientVaults bool `env:"SETUP_RECIPIENT_VAULTS" envDefault:"false"`
+
+	// -- Sweeping --
+
+	// Interval at which token balances of custodial accounts above their
+	// threshold are swept to the treasury account, 0 disables sweeping.
+	SweepInterval time.Duration `env:"SWEEP_INTERVAL" envDefault:"0"`
+	// Account the balances are swept to, defaults to the admin account. The
+	// treasury is shared by all tenants.
+	SweepTreasuryAddress string `env:"SWEEP_TREASURY_ADDRESS" envDefault:""`
+	// Tokens to sweep as "tokenName:amount" pairs, separated by commas. The
+	// amount is the balance left in each account.
+	SweepThresholds []string `env:"SWEEP_THRESHOLDS" envSeparator:","`
+
+	// -- Internal ledger --
+
+	// Interval at which ledger transfers between managed accounts are settled
+	// on-chain, 0 disables periodic settlement.
+	LedgerSettlementInterval time.Duration `env:"LEDGER_SETTLEMENT_INTERVAL" envDefault:"0"`
+
+	// -- Balance snapshots --
+
+	// Interval at which the balances of fungible tokens of managed accounts
+	// are snapshotted, once per day (UTC), if they have not been yet. 0
+	// disables snapshots.
+	BalanceSnapshotInterval time.Duration `env:"BALANCE_SNAPSHOT_INTERVAL" envDefault:"0"`
 
 	// -- Workerpool --
 
@@ -96,6 +195,23 @@ type Config struct {
 	// For more info: https://pkg.go.dev/time#ParseDuration
 	JobStatusWebhookTimeout time.Duration `env:"JOB_STATUS_WEBHOOK_TIMEOUT" envDefault:"30s"`
 
+	// -- Webhooks --
+
+	// Endpoints to receive account lifecycle events (created, funded, key
+	// rotated, disabled), separated by commas.
+	WebhookEndpoints []string `env:"WEBHOOK_ENDPOINTS" envSeparator:","`
+	// Secret used to sign webhook payloads with HMAC-SHA256.
+	WebhookSecret string `env:"WEBHOOK_SECRET" envDefault:""`
+	// Duration for which to wait for a webhook response. Default: 30s.
+	WebhookTimeout time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"30s"`
+	// Whether the endpoints also receive transaction result events (sealed,
+	// failed) of all transactions, callback URLs of requests receive them regardless.
+	WebhookTransactionEvents bool `env:"WEBHOOK_TRANSACTION_EVENTS" envDefault:"false"`
+	// Hosts the callback URLs of requests may point to, separated by commas.
+	// Defaults to the hosts of the webhook endpoints. Callback URLs resolving
+	// to private or loopback addresses are refused regardless.
+	WebhookCallbackHosts []string `env:"WEBHOOK_CALLBACK_HOSTS" envSeparator:","`
+
 	// -- Google KMS --
 
 	GoogleKMSProjectID  string `env:"GOOGLE_KMS_PROJECT_ID"`
@@ -109,6 +225,74 @@ type Config struct {
 	// For more info: https://pkg.go.dev/time#ParseDuration
 	TransactionTimeout time.Duration `env:"TRANSACTION_TIMEOUT" envDefault:"0"`
 
+	// Duration for which sync transaction requests wait for the seal, if 0
+	// wait as long as TransactionTimeout. Transactions which are not sealed
+	// in time are followed by a job, returned with a 202 response.
+	SyncTransactionTimeout time.Duration `env:"SYNC_TRANSACTION_TIMEOUT" envDefault:"30s"`
+
+	// Polling of transaction results while waiting for a seal: the delay
+	// before the first poll and the interval between polls, growing by the
+	// backoff factor from the min to the max interval.
+	TransactionWaitInitialDelay  time.Duration `env:"TRANSACTION_WAIT_INITIAL_DELAY" envDefault:"0"`
+	TransactionWaitMinInterval   time.Duration `env:"TRANSACTION_WAIT_MIN_INTERVAL" envDefault:"100ms"`
+	TransactionWaitMaxInterval   time.Duration `env:"TRANSACTION_WAIT_MAX_INTERVAL" envDefault:"1s"`
+	TransactionWaitBackoffFactor float64       `env:"TRANSACTION_WAIT_BACKOFF_FACTOR" envDefault:"5"`
+
+	// Number of times a transaction is rebuilt and sent again when the access
+	// node rejects it for an invalid proposal key sequence number. The key
+	// state of the proposer is fetched from chain for each retry. 0 disables
+	// the retries.
+	SequenceNumberMaxRetries uint `env:"SEQUENCE_NUMBER_MAX_RETRIES" envDefault:"3"`
+
+	// Default gas (computation) limit of transactions sent by the service.
+	TransactionGasLimit uint64 `env:"TRANSACTION_GAS_LIMIT" envDefault:"9999"`
+	// Maximum gas limit a transaction request can ask for.
+	MaxTransactionGasLimit uint64 `env:"MAX_TRANSACTION_GAS_LIMIT" envDefault:"9999"`
+	// Maximum gas limit of transactions sponsored by the admin account.
+	MaxSponsoredGasLimit uint64 `env:"MAX_SPONSORED_GAS_LIMIT" envDefault:"1000"`
+	// Maximum number of transactions in a single batch submission.
+	MaxTransactionBatchSize uint `env:"MAX_TRANSACTION_BATCH_SIZE" envDefault:"1000"`
+	// Maximum number of recipients of a batch withdrawal, all sent in a
+	// single transaction.
+	MaxWithdrawalBatchSize uint `env:"MAX_WITHDRAWAL_BATCH_SIZE" envDefault:"100"`
+	// Withdrawals of more than the amount of a token need the approval of an
+	// admin, given as "tokenName:amount" pairs separated by commas.
+	WithdrawalApprovalThresholds []string `env:"WITHDRAWAL_APPROVAL_THRESHOLDS" envSeparator:","`
+	// Withdrawals which are not approved in time are rejected, 0 means never.
+	WithdrawalApprovalTimeout time.Duration `env:"WITHDRAWAL_APPROVAL_TIMEOUT" envDefault:"24h"`
+	// How often withdrawals pending approval are checked for the timeout.
+	WithdrawalApprovalExpiryInterval time.Duration `env:"WITHDRAWAL_APPROVAL_EXPIRY_INTERVAL" envDefault:"1m"`
+	// Require an Idempotency-Key header for withdrawal requests.
+	RequireWithdrawalIdempotencyKeys bool `env:"REQUIRE_WITHDRAWAL_IDEMPOTENCY_KEYS" envDefault:"false"`
+	// Withdrawals of less than the amount of a token are refused, given as
+	// "tokenName:amount" pairs separated by commas.
+	WithdrawalMinimums []string `env:"WITHDRAWAL_MINIMUMS" envSeparator:","`
+	// Withdrawals leaving the sender with a balance of a token above zero but
+	// below the amount are refused, given as "tokenName:amount" pairs
+	// separated by commas.
+	WithdrawalDustThresholds []string `env:"WITHDRAWAL_DUST_THRESHOLDS" envSeparator:","`
+	// Execution effort of a token transfer, as reported by FlowFees, used for
+	// fee estimates until withdrawals of the token have been sealed.
+	FeeEstimateExecutionEffort string `env:"FEE_ESTIMATE_EXECUTION_EFFORT" envDefault:"0.00000030"`
+	// Token balances of accounts are cached for the duration, per instance,
+	// 0 disables caching.
+	BalanceCacheTTL time.Duration `env:"BALANCE_CACHE_TTL" envDefault:"0"`
+
+	// Interval at which the on-chain results (status, events, block and error)
+	// of sent transactions are fetched and stored, 0 disables fetching.
+	TransactionResultFetchInterval time.Duration `env:"TRANSACTION_RESULT_FETCH_INTERVAL" envDefault:"10s"`
+
+	// Interval at which transaction schedules are checked for due runs,
+	// 0 disables scheduled transactions. Schedules have a resolution of a
+	// minute.
+	ScheduleCheckInterval time.Duration `env:"SCHEDULE_CHECK_INTERVAL" envDefault:"30s"`
+
+	// Interval at which key backends are checked for the readiness endpoint,
+	// 0 disables the checks and the instance is always reported ready.
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" envDefault:"30s"`
+	// Maximum duration of a single health check.
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"10s"`
+
 	// Idempotency middleware configuration
 	DisableIdempotencyMiddleware bool `env:"DISABLE_IDEMPOTENCY_MIDDLEWARE" envDefault:"false"`
 	// Idempotency middleware database type;
@@ -128,6 +312,9 @@ type Config struct {
 	// Valid time units are "ns", "us" (or "µs"), "ms", "s", "m", "h".
 	// For more info: https://pkg.go.dev/time#ParseDuration
 	ChainListenerInterval time.Duration `env:"EVENTS_INTERVAL" envDefault:"10s"`
+	// Number of blocks on top of the block of a deposit for it to be confirmed,
+	// checked every EVENTS_INTERVAL. 0 confirms deposits once detected.
+	DepositConfirmations uint64 `env:"DEPOSIT_CONFIRMATIONS" envDefault:"0"`
 
 	// Max transactions per second, rate at which the service can submit transactions to Flow (excluding ops)
 	TransactionMaxSendRate int `env:"MAX_TPS" envDefault:"10"`
@@ -136,6 +323,18 @@ type Config struct {
 	// execute before considering it completely failed.
 	MaxJobErrorCount int `env:"MAX_JOB_ERROR_COUNT" envDefault:"10"`
 
+	// Delay before the first retry of an errored job, doubling with each
+	// retry up to the max backoff. Retries are picked up every
+	// DB_JOB_POLL_INTERVAL. A min backoff of 0 retries errored jobs after
+	// RESCHEDULABLE_GRACE_PERIOD instead.
+	JobRetryMinBackoff time.Duration `env:"JOB_RETRY_MIN_BACKOFF" envDefault:"30s"`
+	JobRetryMaxBackoff time.Duration `env:"JOB_RETRY_MAX_BACKOFF" envDefault:"15m"`
+
+	// Number of times account creation is retried within a job when the
+	// transaction expires or can not be sent to the access node. Each retry
+	// rebuilds the transaction. 0 disables the retries.
+	AccountCreationMaxRetries uint `env:"ACCOUNT_CREATION_MAX_RETRIES" envDefault:"3"`
+
 	// Poll DB for new schedulable jobs every 30s.
 	DBJobPollInterval time.Duration `env:"DB_JOB_POLL_INTERVAL" envDefault:"30s"`
 
@@ -154,6 +353,14 @@ type Config struct {
 
 	GrpcMaxCallRecvMsgSize int `env:"GRPC_MAX_CALL_RECV_MSG_SIZE" envDefault:"16777216"`
 
+	// Retries of sending transactions and fetching transaction results on
+	// transient access node errors (gRPC Unavailable, DeadlineExceeded or
+	// ResourceExhausted), 0 disables them. The jittered delay before each
+	// retry doubles from the min to the max backoff.
+	AccessAPIMaxRetries      uint          `env:"ACCESS_API_MAX_RETRIES" envDefault:"3"`
+	AccessAPIRetryMinBackoff time.Duration `env:"ACCESS_API_RETRY_MIN_BACKOFF" envDefault:"100ms"`
+	AccessAPIRetryMaxBackoff time.Duration `env:"ACCESS_API_RETRY_MAX_BACKOFF" envDefault:"2s"`
+
 	// -- ops ---
 	// WorkerCount for system jobs, max number of in-flight transactions
 	OpsWorkerCount uint `env:"OPS_WORKER_COUNT" envDefault:"200"`
@@ -21,17 +21,35 @@ const (
 
 func New(cfg *configs.Config) (*gorm.DB, error) {
 	// TODO(latenssi): safeguard against nil config?
+	return open(cfg.DatabaseType, cfg.DatabaseDSN, cfg.DatabaseVersion, migrations.List())
+}
+
+// NewKeys opens the database used for key material. If no separate keys
+// database is configured, nil is returned and the main database should be used.
+func NewKeys(cfg *configs.Config) (*gorm.DB, error) {
+	if cfg.KeysDatabaseDSN == "" {
+		return nil, nil
+	}
+
+	dbType := cfg.KeysDatabaseType
+	if dbType == "" {
+		dbType = cfg.DatabaseType
+	}
+
+	return open(dbType, cfg.KeysDatabaseDSN, "", migrations.KeysList())
+}
 
+func open(dbType, dsn, version string, ms []*gormigrate.Migration) (*gorm.DB, error) {
 	var dialector gorm.Dialector
-	switch cfg.DatabaseType {
+	switch dbType {
 	default:
-		panic(fmt.Sprintf("database type '%s' not supported", cfg.DatabaseType))
+		panic(fmt.Sprintf("database type '%s' not supported", dbType))
 	case dbTypePostgresql:
-		dialector = postgres.Open(cfg.DatabaseDSN)
+		dialector = postgres.Open(dsn)
 	case dbTypeMysql:
-		dialector = mysql.Open(cfg.DatabaseDSN)
+		dialector = mysql.Open(dsn)
 	case dbTypeSqlite:
-		dialector = sqlite.Open(cfg.DatabaseDSN)
+		dialector = sqlite.Open(dsn)
 	}
 
 	options := &gorm.Config{
@@ -43,16 +61,16 @@ func New(cfg *configs.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	m := gormigrate.New(db, gormigrate.DefaultOptions, migrations.List())
-	if cfg.DatabaseVersion == "" {
+	m := gormigrate.New(db, gormigrate.DefaultOptions, ms)
+	if version == "" {
 		err = m.Migrate()
 	} else {
-		err = m.MigrateTo(cfg.DatabaseVersion)
+		err = m.MigrateTo(version)
 		if err != nil {
 			return nil, err
 		}
 
-		err = m.RollbackTo(cfg.DatabaseVersion)
+		err = m.RollbackTo(version)
 	}
 	if err != nil {
 		return nil, err
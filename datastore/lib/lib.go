@@ -24,7 +24,7 @@ func GormTransaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
 	}
 
 	if !isSqlite {
-		tx.Commit()
+		return tx.Commit().Error
 	}
 
 	return nil
@@ -0,0 +1,106 @@
+// Package decimal provides strict parsing of token amounts, UFix64 decimals
+// with up to 8 decimal places. Amounts are never handled as floats and
+// amounts with more decimal places than UFix64 holds are rejected rather
+// than truncated.
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// Scale is the number of decimal places of UFix64.
+const Scale = 8
+
+var (
+	amountPattern = regexp.MustCompile(`^([0-9]+)(?:\.([0-9]+))?$`)
+	maxUFix64     = new(big.Int).SetUint64(math.MaxUint64)
+)
+
+// ParseUFix64 parses a non-negative decimal number with at most 8 decimal
+// places, e.g. "10", "10.5" or "0.00000001".
+func ParseUFix64(s string) (cadence.UFix64, error) {
+	m := amountPattern.FindStringSubmatch(s)
+	if m == nil {
+		if strings.HasPrefix(s, "-") {
+			return 0, fmt.Errorf("invalid amount %q, can not be negative", s)
+		}
+		return 0, fmt.Errorf("invalid amount %q, expected a decimal number such as \"1.5\"", s)
+	}
+
+	integer, fraction := m[1], m[2]
+
+	if len(fraction) > Scale {
+		return 0, fmt.Errorf("invalid amount %q, more than %d decimal places", s, Scale)
+	}
+
+	v, _ := new(big.Int).SetString(integer+fraction+strings.Repeat("0", Scale-len(fraction)), 10)
+	if v.Cmp(maxUFix64) > 0 {
+		return 0, fmt.Errorf("invalid amount %q, larger than the maximum of %s", s, cadence.UFix64(math.MaxUint64))
+	}
+
+	return cadence.UFix64(v.Uint64()), nil
+}
+
+// ParseAmount parses a positive decimal number with at most 8 decimal
+// places, the amount of a transfer.
+func ParseAmount(s string) (cadence.UFix64, error) {
+	amount, err := ParseUFix64(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if amount == 0 {
+		return 0, fmt.Errorf("invalid amount %q, has to be positive", s)
+	}
+
+	return amount, nil
+}
+
+// Normalize returns an amount with all 8 decimal places, e.g. "1.50000000"
+// for "1.5".
+func Normalize(s string) (string, error) {
+	amount, err := ParseUFix64(s)
+	if err != nil {
+		return "", err
+	}
+
+	return amount.String(), nil
+}
+
+// Sum adds up amounts, which may be larger than the maximum of UFix64 in
+// total, as a fixed point number with 8 decimal places.
+func Sum(amounts []string) (*big.Int, error) {
+	sum := new(big.Int)
+
+	for _, s := range amounts {
+		amount, err := ParseUFix64(s)
+		if err != nil {
+			return nil, err
+		}
+		sum.Add(sum, new(big.Int).SetUint64(uint64(amount)))
+	}
+
+	return sum, nil
+}
+
+// Format formats a fixed point number with 8 decimal places, e.g. a sum or a
+// difference of amounts, "-1.50000000" for -150000000.
+func Format(v *big.Int) string {
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+	}
+
+	digits := new(big.Int).Abs(v).String()
+	if len(digits) <= Scale {
+		digits = strings.Repeat("0", Scale-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-Scale] + "." + digits[len(digits)-Scale:]
+}
@@ -0,0 +1,101 @@
+package decimal
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseUFix64(t *testing.T) {
+	valid := map[string]string{
+		"0":                     "0.00000000",
+		"10":                    "10.00000000",
+		"1.5":                   "1.50000000",
+		"0.00000001":            "0.00000001",
+		"1.50000000":            "1.50000000",
+		"184467440737.09551615": "184467440737.09551615",
+	}
+
+	for s, expected := range valid {
+		amount, err := ParseUFix64(s)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", s, err)
+			continue
+		}
+		if amount.String() != expected {
+			t.Errorf("%q: expected %s, got %s", s, expected, amount)
+		}
+	}
+
+	invalid := map[string]string{
+		"1.123456789":           "more than 8 decimal places",
+		"0.000000001":           "more than 8 decimal places",
+		"-1.0":                  "can not be negative",
+		"184467440737.09551616": "larger than the maximum",
+		"":                      "expected a decimal number",
+		"1.":                    "expected a decimal number",
+		".5":                    "expected a decimal number",
+		"1e3":                   "expected a decimal number",
+		" 1.0":                  "expected a decimal number",
+		"1,5":                   "expected a decimal number",
+	}
+
+	for s, expected := range invalid {
+		_, err := ParseUFix64(s)
+		if err == nil {
+			t.Errorf("%q: expected an error", s)
+			continue
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected an error containing %q, got %q", s, expected, err)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	if _, err := ParseAmount("0.0"); err == nil || !strings.Contains(err.Error(), "has to be positive") {
+		t.Errorf("expected a zero amount to be rejected, got %v", err)
+	}
+
+	if amount, err := ParseAmount("0.00000001"); err != nil || amount != 1 {
+		t.Errorf("expected the smallest amount, got %s, %v", amount, err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	s, err := Normalize("42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "42.00000000" {
+		t.Errorf("expected 42.00000000, got %s", s)
+	}
+}
+
+func TestSumAndFormat(t *testing.T) {
+	sum, err := Sum([]string{"184467440737.09551615", "1.5", "0.00000001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := Format(sum); s != "184467440738.59551616" {
+		t.Errorf("expected a sum larger than UFix64, got %s", s)
+	}
+
+	if _, err := Sum([]string{"1.0", "-1.0"}); err == nil {
+		t.Error("expected an invalid amount to be rejected")
+	}
+
+	formatted := map[int64]string{
+		0:          "0.00000000",
+		1:          "0.00000001",
+		-1:         "-0.00000001",
+		150000000:  "1.50000000",
+		-150000000: "-1.50000000",
+	}
+
+	for v, expected := range formatted {
+		if s := Format(big.NewInt(v)); s != expected {
+			t.Errorf("%d: expected %s, got %s", v, expected, s)
+		}
+	}
+}
@@ -13,21 +13,59 @@ import (
 	"github.com/jpillora/backoff"
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
 )
 
 type FlowClient interface {
 	ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments []cadence.Value) (cadence.Value, error)
+	ExecuteScriptAtBlockHeight(ctx context.Context, height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error)
+	ExecuteScriptAtBlockID(ctx context.Context, blockID flow.Identifier, script []byte, arguments []cadence.Value) (cadence.Value, error)
 	GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetAccountAtLatestBlock(ctx context.Context, address flow.Address) (*flow.Account, error)
 	GetTransaction(ctx context.Context, txID flow.Identifier) (*flow.Transaction, error)
 	GetTransactionResult(ctx context.Context, txID flow.Identifier) (*flow.TransactionResult, error)
 	GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.BlockHeader, error)
+	GetBlockHeaderByID(ctx context.Context, blockID flow.Identifier) (*flow.BlockHeader, error)
 	GetEventsForHeightRange(ctx context.Context, eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error)
 	SendTransaction(ctx context.Context, tx flow.Transaction) error
 }
 
 const hexPrefix = "0x"
 
+// ErrTransactionExpired is returned by WaitForSeal when a transaction expires
+// before being sealed. An expired transaction was never executed.
+var ErrTransactionExpired = fmt.Errorf("transaction expired")
+
+// ErrSealTimeout is returned by WaitForSeal when the transaction is not sealed
+// within the timeout. The transaction may still get sealed later on.
+var ErrSealTimeout = fmt.Errorf("timed out waiting for transaction seal")
+
+// WaitOption configures how WaitForSeal polls for the transaction result.
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	initialDelay time.Duration
+	backoff      backoff.Backoff
+}
+
+// WithInitialDelay delays the first poll for the transaction result, as a
+// transaction takes at least a few blocks to get sealed.
+func WithInitialDelay(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.initialDelay = d
+	}
+}
+
+// WithBackoff sets the interval between polls, growing exponentially by
+// factor from min up to max. Default: 100ms to 1s with a factor of 5.
+func WithBackoff(min, max time.Duration, factor float64) WaitOption {
+	return func(o *waitOptions) {
+		o.backoff.Min = min
+		o.backoff.Max = max
+		o.backoff.Factor = factor
+	}
+}
+
 // LatestBlockId retuns the flow.Identifier for the latest block in the chain.
 func LatestBlockId(ctx context.Context, flowClient FlowClient) (*flow.Identifier, error) {
 	block, err := flowClient.GetLatestBlockHeader(ctx, false)
@@ -41,18 +79,24 @@ func LatestBlockId(ctx context.Context, flowClient FlowClient) (*flow.Identifier
 // - an error occurs while fetching the transaction result
 // - the transaction gets an error status
 // - the transaction gets a "TransactionStatusSealed" or "TransactionStatusExpired" status
-// - timeout is reached
-func WaitForSeal(ctx context.Context, flowClient FlowClient, id flow.Identifier, timeout time.Duration) (*flow.TransactionResult, error) {
+// - timeout is reached, ErrSealTimeout is returned then
+func WaitForSeal(ctx context.Context, flowClient FlowClient, id flow.Identifier, timeout time.Duration, opts ...WaitOption) (*flow.TransactionResult, error) {
 	var (
 		result *flow.TransactionResult
 		err    error
 	)
 
-	b := &backoff.Backoff{
-		Min:    100 * time.Millisecond,
-		Max:    time.Second,
-		Factor: 5,
-		Jitter: true,
+	o := waitOptions{
+		backoff: backoff.Backoff{
+			Min:    100 * time.Millisecond,
+			Max:    time.Second,
+			Factor: 5,
+			Jitter: true,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
 	}
 
 	if timeout > 0 {
@@ -61,9 +105,16 @@ func WaitForSeal(ctx context.Context, flowClient FlowClient, id flow.Identifier,
 		defer cancel()
 	}
 
+	if err := sleep(ctx, o.initialDelay); err != nil {
+		return nil, err
+	}
+
 	for {
 		result, err = flowClient.GetTransactionResult(ctx, id)
 		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, ErrSealTimeout
+			}
 			return nil, err
 		}
 
@@ -76,22 +127,45 @@ func WaitForSeal(ctx context.Context, flowClient FlowClient, id flow.Identifier,
 			// Not an interesting state, exit switch and continue loop
 		case flow.TransactionStatusExpired:
 			// Expired, handle as an error
-			return result, fmt.Errorf("transaction expired")
+			return result, ErrTransactionExpired
 		case flow.TransactionStatusSealed:
 			// Sealed, all good
 			return result, nil
 		}
 
-		time.Sleep(b.Duration())
+		if err := sleep(ctx, o.backoff.Duration()); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done, returning ErrSealTimeout if the
+// deadline of ctx is exceeded.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrSealTimeout
+		}
+		return ctx.Err()
 	}
 }
 
 // SendAndWait sends the transaction and waits for the transaction to be sealed
-func SendAndWait(ctx context.Context, flowClient FlowClient, tx flow.Transaction, timeout time.Duration) (*flow.TransactionResult, error) {
+func SendAndWait(ctx context.Context, flowClient FlowClient, tx flow.Transaction, timeout time.Duration, opts ...WaitOption) (*flow.TransactionResult, error) {
 	if err := flowClient.SendTransaction(ctx, tx); err != nil {
 		return nil, err
 	}
-	return WaitForSeal(ctx, flowClient, tx.ID(), timeout)
+	return WaitForSeal(ctx, flowClient, tx.ID(), timeout, opts...)
 }
 
 func HexString(str string) string {
@@ -130,3 +204,33 @@ func ValidateTransactionId(id string) error {
 	}
 	return nil
 }
+
+// CadenceSignatureAlgorithm maps a signature algorithm to the raw value of
+// Cadence's SignatureAlgorithm enum.
+func CadenceSignatureAlgorithm(a crypto.SignatureAlgorithm) uint8 {
+	switch a {
+	case crypto.ECDSA_P256:
+		return 1
+	case crypto.ECDSA_secp256k1:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// CadenceHashAlgorithm maps a hash algorithm to the raw value of
+// Cadence's HashAlgorithm enum.
+func CadenceHashAlgorithm(a crypto.HashAlgorithm) uint8 {
+	switch a {
+	case crypto.SHA2_256:
+		return 1
+	case crypto.SHA2_384:
+		return 2
+	case crypto.SHA3_256:
+		return 3
+	case crypto.SHA3_384:
+		return 4
+	default:
+		return 0
+	}
+}
@@ -55,4 +55,33 @@ func TestWaitForSeal(t *testing.T) {
 			t.Fatal("expected wait to take longer")
 		}
 	})
+
+	t.Run("options", func(t *testing.T) {
+		flowClient := new(internal.MockFlowClient)
+		ctx := context.Background()
+		start := time.Now()
+
+		opts := []WaitOption{
+			WithInitialDelay(200 * time.Millisecond),
+			WithBackoff(10*time.Millisecond, 20*time.Millisecond, 2),
+		}
+
+		if _, err := WaitForSeal(ctx, flowClient, flow.EmptyID, 0, opts...); err != nil {
+			t.Fatalf("did not expect an error, got: %s", err)
+		}
+
+		if d := time.Since(start); d < 200*time.Millisecond || d >= 500*time.Millisecond {
+			t.Fatalf("expected wait to take 200ms to 500ms, took %s", d)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		flowClient := new(internal.MockFlowClient)
+		ctx := context.Background()
+
+		_, err := WaitForSeal(ctx, flowClient, flow.EmptyID, 50*time.Millisecond, WithInitialDelay(time.Second))
+		if err != ErrSealTimeout {
+			t.Fatalf("expected %q, got: %v", ErrSealTimeout, err)
+		}
+	})
 }
@@ -0,0 +1,116 @@
+package flow_helpers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryClient is a FlowClient which retries sending transactions and
+// fetching transaction results on transient access node errors, with a
+// jittered exponential backoff.
+type RetryClient struct {
+	FlowClient
+	maxRetries uint
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+type RetryOption func(*RetryClient)
+
+// WithMaxRetries sets the number of retries after the first attempt, 0
+// disables retrying. Default: 3.
+func WithMaxRetries(n uint) RetryOption {
+	return func(c *RetryClient) {
+		c.maxRetries = n
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry, doubling with each
+// retry up to max. Default: 100ms to 2s.
+func WithRetryBackoff(min, max time.Duration) RetryOption {
+	return func(c *RetryClient) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+func NewRetryClient(fc FlowClient, opts ...RetryOption) *RetryClient {
+	c := &RetryClient{
+		FlowClient: fc,
+		maxRetries: 3,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 2 * time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *RetryClient) SendTransaction(ctx context.Context, tx flow.Transaction) error {
+	return c.retry(ctx, "SendTransaction", func() error {
+		return c.FlowClient.SendTransaction(ctx, tx)
+	})
+}
+
+func (c *RetryClient) GetTransactionResult(ctx context.Context, txID flow.Identifier) (*flow.TransactionResult, error) {
+	var result *flow.TransactionResult
+	err := c.retry(ctx, "GetTransactionResult", func() (err error) {
+		result, err = c.FlowClient.GetTransactionResult(ctx, txID)
+		return err
+	})
+	return result, err
+}
+
+func (c *RetryClient) retry(ctx context.Context, method string, f func() error) error {
+	b := &backoff.Backoff{
+		Min:    c.minBackoff,
+		Max:    c.maxBackoff,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	for attempt := uint(1); ; attempt++ {
+		err := f()
+		if err == nil || attempt > c.maxRetries || !IsTransientError(err) || ctx.Err() != nil {
+			return err
+		}
+
+		log.
+			WithFields(log.Fields{"error": err, "method": method, "attempt": attempt}).
+			Warn("Transient access node error, retrying")
+
+		t := time.NewTimer(b.Duration())
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		}
+	}
+}
+
+// IsTransientError reports whether err is a gRPC error which is likely to go
+// away on retry: Unavailable, DeadlineExceeded or ResourceExhausted.
+func IsTransientError(err error) bool {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return false
+	}
+
+	switch grpcErr.GRPCStatus().Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+package flow_helpers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers/internal"
+	"github.com/onflow/flow-go-sdk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failingFlowClient fails to send transactions with err the first failures
+// times.
+type failingFlowClient struct {
+	internal.MockFlowClient
+	err      error
+	failures int
+	calls    int
+}
+
+func (c *failingFlowClient) SendTransaction(ctx context.Context, tx flow.Transaction) error {
+	c.calls++
+	if c.calls <= c.failures {
+		return c.err
+	}
+	return nil
+}
+
+func TestRetryClient(t *testing.T) {
+	opts := []RetryOption{WithMaxRetries(2), WithRetryBackoff(time.Millisecond, 2*time.Millisecond)}
+
+	t.Run("transient error", func(t *testing.T) {
+		fc := &failingFlowClient{err: status.Error(codes.Unavailable, "unavailable"), failures: 2}
+		if err := NewRetryClient(fc, opts...).SendTransaction(context.Background(), flow.Transaction{}); err != nil {
+			t.Fatalf("did not expect an error, got: %s", err)
+		}
+		if fc.calls != 3 {
+			t.Fatalf("expected 3 calls, got %d", fc.calls)
+		}
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		fc := &failingFlowClient{err: status.Error(codes.ResourceExhausted, "rate limited"), failures: 3}
+		if err := NewRetryClient(fc, opts...).SendTransaction(context.Background(), flow.Transaction{}); err == nil {
+			t.Fatal("expected an error")
+		}
+		if fc.calls != 3 {
+			t.Fatalf("expected 3 calls, got %d", fc.calls)
+		}
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		fc := &failingFlowClient{err: status.Error(codes.InvalidArgument, "invalid"), failures: 1}
+		if err := NewRetryClient(fc, opts...).SendTransaction(context.Background(), flow.Transaction{}); err == nil {
+			t.Fatal("expected an error")
+		}
+		if fc.calls != 1 {
+			t.Fatalf("expected 1 call, got %d", fc.calls)
+		}
+	})
+}
+
+func TestIsTransientError(t *testing.T) {
+	cases := map[error]bool{
+		status.Error(codes.Unavailable, ""):                            true,
+		status.Error(codes.DeadlineExceeded, ""):                       true,
+		fmt.Errorf("wrapped: %w", status.Error(codes.Unavailable, "")): true,
+		status.Error(codes.NotFound, ""):                               false,
+		fmt.Errorf("not a gRPC error"):                                 false,
+	}
+
+	for err, expected := range cases {
+		if IsTransientError(err) != expected {
+			t.Errorf("expected IsTransientError(%q) to be %t", err, expected)
+		}
+	}
+}
@@ -19,6 +19,45 @@ type SyncKeyCountRequest struct {
 	Address flow.Address `json:"address"`
 }
 
+// CreateBatchRequest represents a JSON payload for a batch account creation HTTP request
+type CreateBatchRequest struct {
+	Count int `json:"count"`
+}
+
+// BulkDetailsRequest represents a JSON payload for a bulk account details HTTP request
+type BulkDetailsRequest struct {
+	Addresses []string `json:"addresses"`
+}
+
+// UpdateAccountRequest represents a JSON payload for an account update HTTP request
+// Omitted fields are left as is.
+type UpdateAccountRequest struct {
+	Label *string `json:"label"`
+	Alias *string `json:"alias"`
+}
+
+// FreezeAccountRequest represents an optional JSON payload for an account freeze HTTP request
+type FreezeAccountRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SignAccountProofRequest represents a JSON payload for an FCL account-proof signing HTTP request
+type SignAccountProofRequest struct {
+	AppIdentifier string `json:"appIdentifier"`
+	Nonce         string `json:"nonce"`
+}
+
+// SignMessageRequest represents a JSON payload for a user message signing HTTP request
+type SignMessageRequest struct {
+	// Message is hex encoded
+	Message string `json:"message"`
+}
+
+// DeployContractRequest represents a JSON payload for a contract deployment HTTP request
+type DeployContractRequest struct {
+	Code string `json:"code"`
+}
+
 // NewAccounts initiates a new accounts server.
 func NewAccounts(service accounts.Service) *Accounts {
 	return &Accounts{service}
@@ -28,10 +67,18 @@ func (s *Accounts) List() http.Handler {
 	return http.HandlerFunc(s.ListFunc)
 }
 
+func (s *Accounts) Export() http.Handler {
+	return http.HandlerFunc(s.ExportFunc)
+}
+
 func (s *Accounts) Create() http.Handler {
 	return http.HandlerFunc(s.CreateFunc)
 }
 
+func (s *Accounts) CreateBatch() http.Handler {
+	return http.HandlerFunc(s.CreateBatchFunc)
+}
+
 func (s *Accounts) AddNonCustodialAccount() http.Handler {
 	return http.HandlerFunc(s.AddNonCustodialAccountFunc)
 }
@@ -44,6 +91,82 @@ func (s *Accounts) SyncAccountKeyCount() http.Handler {
 	return http.HandlerFunc(s.SyncAccountKeyCountFunc)
 }
 
+func (s *Accounts) Update() http.Handler {
+	return http.HandlerFunc(s.UpdateFunc)
+}
+
+func (s *Accounts) Disable() http.Handler {
+	return http.HandlerFunc(s.DisableFunc)
+}
+
+func (s *Accounts) Enable() http.Handler {
+	return http.HandlerFunc(s.EnableFunc)
+}
+
+func (s *Accounts) Freeze() http.Handler {
+	return http.HandlerFunc(s.FreezeFunc)
+}
+
+func (s *Accounts) Unfreeze() http.Handler {
+	return http.HandlerFunc(s.UnfreezeFunc)
+}
+
+func (s *Accounts) OnChainKeys() http.Handler {
+	return http.HandlerFunc(s.OnChainKeysFunc)
+}
+
+func (s *Accounts) AddKey() http.Handler {
+	return http.HandlerFunc(s.AddKeyFunc)
+}
+
+func (s *Accounts) RevokeKey() http.Handler {
+	return http.HandlerFunc(s.RevokeKeyFunc)
+}
+
+func (s *Accounts) RequestKeyRecovery() http.Handler {
+	return http.HandlerFunc(s.RequestKeyRecoveryFunc)
+}
+
+func (s *Accounts) ListKeyRecoveries() http.Handler {
+	return http.HandlerFunc(s.ListKeyRecoveriesFunc)
+}
+
+func (s *Accounts) ApproveKeyRecovery() http.Handler {
+	return http.HandlerFunc(s.ApproveKeyRecoveryFunc)
+}
+
+func (s *Accounts) CancelKeyRecovery() http.Handler {
+	return http.HandlerFunc(s.CancelKeyRecoveryFunc)
+}
+
+func (s *Accounts) ExecuteKeyRecovery() http.Handler {
+	return http.HandlerFunc(s.ExecuteKeyRecoveryFunc)
+}
+
+func (s *Accounts) DeployContract() http.Handler {
+	return http.HandlerFunc(s.DeployContractFunc)
+}
+
+func (s *Accounts) RemoveContract() http.Handler {
+	return http.HandlerFunc(s.RemoveContractFunc)
+}
+
+func (s *Accounts) Sync() http.Handler {
+	return http.HandlerFunc(s.SyncFunc)
+}
+
+func (s *Accounts) SignAccountProof() http.Handler {
+	return http.HandlerFunc(s.SignAccountProofFunc)
+}
+
+func (s *Accounts) SignMessage() http.Handler {
+	return http.HandlerFunc(s.SignMessageFunc)
+}
+
 func (s *Accounts) Details() http.Handler {
 	return http.HandlerFunc(s.DetailsFunc)
 }
+
+func (s *Accounts) BulkDetails() http.Handler {
+	return http.HandlerFunc(s.BulkDetailsFunc)
+}
@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
 	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
 )
 
 // List returns all accounts.
@@ -23,7 +28,13 @@ func (s *Accounts) ListFunc(rw http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	res, err := s.service.List(limit, offset)
+	filter, err := parseAccountListFilter(r)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := s.service.List(limit, offset, filter)
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -33,13 +44,167 @@ func (s *Accounts) ListFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
+// Export formats of the accounts export.
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+)
+
+// Export streams all accounts matching the list filters as newline
+// delimited JSON (default) or CSV, oldest first.
+func (s *Accounts) ExportFunc(rw http.ResponseWriter, r *http.Request) {
+	filter, err := parseAccountListFilter(r)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = ExportFormatNDJSON
+	}
+
+	var (
+		write func(accounts.ExportedAccount) error
+		flush func() error
+	)
+
+	switch format {
+	case ExportFormatNDJSON:
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(rw)
+		write = func(a accounts.ExportedAccount) error { return enc.Encode(a) }
+		flush = func() error { return nil }
+	case ExportFormatCSV:
+		rw.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(rw)
+		if err := cw.Write([]string{"address", "type", "label", "createdAt", "updatedAt"}); err != nil {
+			handleError(rw, r, err)
+			return
+		}
+		write = func(a accounts.ExportedAccount) error {
+			return cw.Write([]string{
+				a.Address,
+				string(a.Type),
+				a.Label,
+				a.CreatedAt.UTC().Format(time.RFC3339Nano),
+				a.UpdatedAt.UTC().Format(time.RFC3339Nano),
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		err := &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid format %q, expected %s or %s", format, ExportFormatNDJSON, ExportFormatCSV),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"accounts.%s\"", format))
+
+	flusher, _ := rw.(http.Flusher)
+	n := 0
+
+	err = s.service.Export(filter, func(a accounts.ExportedAccount) error {
+		if err := write(a); err != nil {
+			return err
+		}
+
+		n++
+		if n%exportFlushInterval == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		return nil
+	})
+
+	if err == nil {
+		err = flush()
+	}
+
+	if err != nil {
+		// Headers and possibly a part of the body have been sent already,
+		// the status code can not be changed anymore
+		log.
+			WithFields(log.Fields{"error": err, "exported": n}).
+			Warn("Accounts export aborted")
+	}
+}
+
+// Number of exported accounts between flushes of the response.
+const exportFlushInterval = 100
+
+// parseAccountListFilter reads account list filtering and sorting
+// options from the query parameters.
+func parseAccountListFilter(r *http.Request) (accounts.ListFilter, error) {
+	filter := accounts.ListFilter{
+		Type:     accounts.AccountType(r.FormValue("type")),
+		Label:    r.FormValue("label"),
+		Sort:     r.FormValue("sort"),
+		TenantID: tenants.FromContext(r.Context()),
+	}
+
+	for param, dst := range map[string]**time.Time{
+		"createdAfter":  &filter.CreatedAfter,
+		"createdBefore": &filter.CreatedBefore,
+	} {
+		v := r.FormValue(param)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return accounts.ListFilter{}, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid %s, expected RFC 3339 time: %q", param, v),
+			}
+		}
+		*dst = &t
+	}
+
+	return filter, nil
+}
+
 // Create creates a new account asynchronously.
 // It returns a Job JSON representation.
 func (s *Accounts) CreateFunc(rw http.ResponseWriter, r *http.Request) {
+	var req accounts.CreateRequest
+
+	// Body is optional
+	if r.Body != nil && r.Body != http.NoBody {
+		// Try to decode the request body.
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+			handleError(rw, r, err)
+			return
+		}
+	}
+
 	// Decide whether to serve sync or async, default async
 	sync := r.FormValue(SyncQueryParameter) != ""
 
-	job, acc, err := s.service.Create(r.Context(), sync)
+	var (
+		job *jobs.Job
+		acc *accounts.Account
+		err error
+	)
+
+	// Retried requests with the same key return the original job or account
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		job, acc, err = s.service.CreateIdempotent(r.Context(), sync, key, req)
+	} else {
+		job, acc, err = s.service.CreateWithRequest(r.Context(), sync, req)
+	}
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -56,19 +221,486 @@ func (s *Accounts) CreateFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusCreated, res)
 }
 
+// CreateBatch creates multiple new accounts in a single transaction.
+// It returns a Job JSON representation or the created accounts if sync.
+func (s *Accounts) CreateBatchFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req CreateBatchRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, accs, err := s.service.CreateBatch(r.Context(), sync, req.Count)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = accs
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
 // Details returns details regarding an account.
 // It reads the address for the wanted account from URL.
 // Account service is responsible for validating the address.
 func (s *Accounts) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
+	var res accounts.Account
+	var err error
+
+	switch include := r.FormValue("include"); include {
+	case "":
+		res, err = s.service.Details(vars["address"])
+	case "onchain":
+		res, err = s.service.DetailsWithOnChain(r.Context(), vars["address"])
+	default:
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid include: %q", include)}
+	}
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// BulkDetailsFunc returns the details of multiple accounts at once.
+func (s *Accounts) BulkDetailsFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req BulkDetailsRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := s.service.BulkDetails(r.Context(), req.Addresses)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// UpdateFunc updates the label of an account.
+func (s *Accounts) UpdateFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req UpdateAccountRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+
 	res, err := s.service.Details(vars["address"])
 
+	if err == nil && req.Label != nil {
+		res, err = s.service.UpdateLabel(vars["address"], *req.Label)
+	}
+
+	if err == nil && req.Alias != nil {
+		res, err = s.service.UpdateAlias(vars["address"], *req.Alias)
+	}
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// DisableFunc soft deletes an account, blocking any further transactions.
+func (s *Accounts) DisableFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	err := s.service.Disable(vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// EnableFunc restores a disabled account.
+func (s *Accounts) EnableFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.Enable(vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// FreezeFunc puts an account on hold, blocking any outgoing transactions.
+func (s *Accounts) FreezeFunc(rw http.ResponseWriter, r *http.Request) {
+	var req FreezeAccountRequest
+
+	// Body is optional
+	if r.Body != nil && r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+			handleError(rw, r, err)
+			return
+		}
+	}
+
+	vars := mux.Vars(r)
+
+	res, err := s.service.Freeze(vars["address"], req.Reason)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// UnfreezeFunc lifts the hold of a frozen account.
+func (s *Accounts) UnfreezeFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.Unfreeze(vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// RequestKeyRecoveryFunc starts the recovery of a lost client-held key.
+func (s *Accounts) RequestKeyRecoveryFunc(rw http.ResponseWriter, r *http.Request) {
+	var req accounts.KeyRecoveryRequest
+
+	if r.Body == nil || r.Body == http.NoBody {
+		err := &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("empty body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	res, err := s.service.RequestKeyRecovery(r.Context(), vars["address"], req)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+// ListKeyRecoveriesFunc lists the key recoveries of an account.
+func (s *Accounts) ListKeyRecoveriesFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.ListKeyRecoveries(vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// ApproveKeyRecoveryFunc approves a pending key recovery, starting its delay.
+func (s *Accounts) ApproveKeyRecoveryFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.ApproveKeyRecovery(r.Context(), vars["address"], vars["recoveryId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// CancelKeyRecoveryFunc cancels a key recovery which has not been executed.
+func (s *Accounts) CancelKeyRecoveryFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.CancelKeyRecovery(vars["address"], vars["recoveryId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// ExecuteKeyRecoveryFunc replaces the lost key of an approved key recovery
+// once its delay has passed.
+func (s *Accounts) ExecuteKeyRecoveryFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, recovery, err := s.service.ExecuteKeyRecovery(r.Context(), sync, vars["address"], vars["recoveryId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = recovery
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// SyncFunc reconciles the stored account with its on-chain state and
+// returns a report of the differences.
+func (s *Accounts) SyncFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.Sync(r.Context(), vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// SignAccountProofFunc signs an FCL account-proof message for an account.
+func (s *Accounts) SignAccountProofFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req SignAccountProofRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	res, err := s.service.SignAccountProof(r.Context(), vars["address"], req.AppIdentifier, req.Nonce)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// SignMessageFunc signs an arbitrary user message for an account.
+func (s *Accounts) SignMessageFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req SignMessageRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	res, err := s.service.SignMessage(r.Context(), vars["address"], req.Message)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// OnChainKeysFunc returns the keys of an account as they are stored on chain.
+func (s *Accounts) OnChainKeysFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.OnChainKeys(r.Context(), vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// AddKeyFunc adds a key to an account. Without a public key in the
+// (optional) body a new custodial key is generated.
+func (s *Accounts) AddKeyFunc(rw http.ResponseWriter, r *http.Request) {
+	var key accounts.NewAccountKey
+
+	if r.Body != nil && r.Body != http.NoBody {
+		// Try to decode the request body.
+		if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+			err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+			handleError(rw, r, err)
+			return
+		}
+	}
+
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, acc, err := s.service.AddKey(r.Context(), sync, vars["address"], key)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = acc
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+// RevokeKeyFunc revokes a key of an account.
+func (s *Accounts) RevokeKeyFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	index, err := strconv.Atoi(vars["index"])
+	if err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid key index")}
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, acc, err := s.service.RevokeKey(r.Context(), sync, vars["address"], index)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = acc
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// DeployContractFunc deploys or updates a contract on an account.
+func (s *Accounts) DeployContractFunc(rw http.ResponseWriter, r *http.Request) {
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req DeployContractRequest
+	// Try to decode the request body.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, transaction, err := s.service.DeployContract(r.Context(), sync, vars["address"], vars["name"], req.Code)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+// RemoveContractFunc removes a contract from an account.
+func (s *Accounts) RemoveContractFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	job, transaction, err := s.service.RemoveContract(r.Context(), sync, vars["address"], vars["name"])
 	if err != nil {
 		handleError(rw, r, err)
 		return
 	}
 
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
@@ -92,7 +724,7 @@ func (s *Accounts) AddNonCustodialAccountFunc(rw http.ResponseWriter, r *http.Re
 		return
 	}
 
-	a, err := s.service.AddNonCustodialAccount(b.Address)
+	a, err := s.service.AddNonCustodialAccount(r.Context(), b.Address)
 	if err != nil {
 		handleError(rw, r, err)
 	}
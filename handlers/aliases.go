@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/gorilla/mux"
+)
+
+// Account alias handler middleware
+// ===========================================================================
+
+// ResolveAccountAlias returns a router middleware that replaces an
+// "alias:{name}" {address} path variable with the address of the account
+// with that alias, e.g. /accounts/alias:user-1234/keys. Unknown aliases
+// respond with 404 Not Found.
+func ResolveAccountAlias(svc accounts.Service) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			vars := mux.Vars(r)
+
+			alias, ok := vars["address"]
+			if !ok || !strings.HasPrefix(alias, accounts.AliasPrefix) {
+				h.ServeHTTP(rw, r)
+				return
+			}
+
+			address, err := svc.ResolveAlias(r.Context(), alias)
+			if err != nil {
+				handleError(rw, r, err)
+				return
+			}
+
+			vars["address"] = address
+			h.ServeHTTP(rw, mux.SetURLVars(r, vars))
+		})
+	}
+}
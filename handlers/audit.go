@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/flow-hydraulics/flow-wallet-api/audit"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// Audit is a HTTP server for querying the audit log.
+type Audit struct {
+	service audit.Service
+}
+
+func NewAudit(service audit.Service) *Audit {
+	return &Audit{service}
+}
+
+func (s *Audit) List() http.Handler {
+	return http.HandlerFunc(s.ListFunc)
+}
+
+func (s *Audit) Details() http.Handler {
+	return http.HandlerFunc(s.DetailsFunc)
+}
+
+// Audit log middleware
+// ===========================================================================
+
+// AuditLog returns a router middleware that records mutating requests (POST,
+// PUT, PATCH and DELETE) along with their caller in the audit log: the tenant
+// and API key, the source IP and a fingerprint of the request. The ID of the
+// audit log entry is passed on in the request context.
+func AuditLog(svc audit.Service) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				h.ServeHTTP(rw, r)
+				return
+			}
+
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				b, err := io.ReadAll(r.Body)
+				if err != nil {
+					handleError(rw, r, InvalidBodyError)
+					return
+				}
+				body = b
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			var apiKey string
+			if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+				apiKey = strings.TrimPrefix(auth, "Bearer ")
+			}
+
+			e := &audit.Entry{
+				ID:           uuid.New(),
+				Method:       r.Method,
+				Path:         r.URL.Path,
+				Address:      mux.Vars(r)["address"],
+				TenantID:     tenants.FromContext(r.Context()),
+				APIKeyID:     audit.APIKeyID(apiKey),
+				SourceIP:     sourceIP(r),
+				ForwardedFor: r.Header.Get("X-Forwarded-For"),
+				UserAgent:    r.UserAgent(),
+				Fingerprint:  audit.Fingerprint(r.Method, r.URL.RequestURI(), body),
+			}
+
+			if route := mux.CurrentRoute(r); route != nil {
+				e.Route, _ = route.GetPathTemplate()
+			}
+
+			e.StatusCode = http.StatusOK
+			rw = httpsnoop.Wrap(rw, httpsnoop.Hooks{
+				WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+					return func(code int) {
+						e.StatusCode = code
+						next(code)
+					}
+				},
+			})
+
+			h.ServeHTTP(rw, r.WithContext(audit.NewContext(r.Context(), e.ID)))
+
+			if err := svc.Record(e); err != nil {
+				log.
+					WithFields(log.Fields{"error": err, "method": e.Method, "path": e.Path}).
+					Error("Recording audit log entry failed")
+			}
+		})
+	}
+}
+
+// sourceIP is the IP address the request was received from.
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/flow-hydraulics/flow-wallet-api/audit"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/gorilla/mux"
+)
+
+// ListFunc lists the audit log, newest first, scoped to the tenant of the
+// request. It can be filtered by account (address) and creation time range.
+func (s *Audit) ListFunc(rw http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	filter := audit.ListFilter{
+		TenantID: tenants.FromContext(r.Context()),
+		Address:  r.FormValue("address"),
+	}
+
+	if err := parseCreatedRange(r, &filter.CreatedAfter, &filter.CreatedBefore); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	entries, err := s.service.List(limit, offset, filter)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, entries)
+}
+
+func (s *Audit) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	e, err := s.service.Details(vars["entryId"], tenants.FromContext(r.Context()))
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, e)
+}
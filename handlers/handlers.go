@@ -3,25 +3,33 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	gorilla "github.com/gorilla/handlers"
 	log "github.com/sirupsen/logrus"
 
-	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/handlers/middleware"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
 )
 
 const SyncQueryParameter = "sync"
 
-var EmptyBodyError = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("empty body")}
-var InvalidBodyError = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+// NextCursorHeader holds the cursor to the next page of cursor paginated listings.
+const NextCursorHeader = "X-Next-Cursor"
+
+var EmptyBodyError = &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("empty body")}
+var InvalidBodyError = &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
 
 func UseCors(h http.Handler) http.Handler {
-	return gorilla.CORS(gorilla.AllowedOrigins([]string{"*"}))(h)
+	return gorilla.CORS(gorilla.AllowedOrigins([]string{"*"}), gorilla.ExposedHeaders([]string{NextCursorHeader}))(h)
 }
 
 func UseLogging(h http.Handler) http.Handler {
@@ -37,23 +45,86 @@ func UseJson(h http.Handler) http.Handler {
 	return gorilla.ContentTypeHandler(h, "application/json")
 }
 
+// UseTimeout limits the time for handling requests. Responses are buffered
+// until the handler returns, so streamed responses (ignorePaths) are passed
+// through without a timeout.
+func UseTimeout(h http.Handler, timeout time.Duration, ignorePaths []string) http.Handler {
+	th := http.TimeoutHandler(h, timeout, "request timed out")
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		for _, path := range ignorePaths {
+			if r.URL.Path == path {
+				h.ServeHTTP(rw, r)
+				return
+			}
+		}
+		th.ServeHTTP(rw, r)
+	})
+}
+
 func UseIdempotency(h http.Handler, opts IdempotencyHandlerOptions, store IdempotencyStore) http.Handler {
 	return IdempotencyHandler(h, opts, store)
 }
 
 // handleError is a helper function for unified HTTP error handling.
 func handleError(rw http.ResponseWriter, r *http.Request, err error) {
+	// Sync requests of transactions which were not sealed in time get the
+	// job waiting for the result
+	var pendingErr *transactions.PendingError
+	if errors.As(err, &pendingErr) {
+		handleJsonResponse(rw, http.StatusAccepted, pendingErr.Job.ToJSONResponse())
+		return
+	}
+
+	// Reverted sync transactions respond with the sealed result
+	var revertedErr *transactions.RevertedError
+	if errors.As(err, &revertedErr) {
+		handleJsonResponse(rw, http.StatusBadRequest, revertedErr.Transaction.ToJSONResponse())
+		return
+	}
+
+	// Withdrawals breaking a rule of their token respond with the details
+	var ruleErr *tokens.WithdrawalRuleError
+	if errors.As(err, &ruleErr) {
+		handleJsonResponse(rw, http.StatusBadRequest, ruleErr)
+		return
+	}
+
 	log.
 		WithFields(log.Fields{"error": err}).
 		Warn("Error while handling request")
 
 		// Check if the error was an errors.RequestError
-	reqErr, isReqErr := err.(*errors.RequestError)
+	reqErr, isReqErr := err.(*wallet_errors.RequestError)
 	if isReqErr {
 		http.Error(rw, reqErr.Error(), reqErr.StatusCode)
 		return
 	}
 
+	// Check for signing rate limit, the request can be retried later
+	if errors.Is(err, keys.ErrSigningRateLimited) {
+		rw.Header().Set("Retry-After", "1")
+		http.Error(rw, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	// Check for signing attempts with accounts we hold no keys for
+	if errors.Is(err, keys.ErrNonCustodialAccount) {
+		http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Check for signing attempts with disabled accounts
+	if errors.Is(err, keys.ErrAccountDisabled) {
+		http.Error(rw, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// Check for transactions of frozen accounts
+	if errors.Is(err, transactions.ErrAccountFrozen) {
+		http.Error(rw, err.Error(), http.StatusLocked)
+		return
+	}
+
 	// Check for "record not found" database error
 	if strings.Contains(err.Error(), "record not found") {
 		http.Error(rw, err.Error(), http.StatusNotFound)
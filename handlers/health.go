@@ -17,3 +17,16 @@ func Liveness(getLiveness func() (interface{}, error)) http.Handler {
 		handleJsonResponse(rw, http.StatusOK, liveness)
 	})
 }
+
+// Readiness responds with the status returned by getReadiness and
+// 503 Service Unavailable if the instance is not ready.
+func Readiness(getReadiness func() (bool, interface{})) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ready, status := getReadiness()
+		if !ready {
+			handleJsonResponse(rw, http.StatusServiceUnavailable, status)
+			return
+		}
+		handleJsonResponse(rw, http.StatusOK, status)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/gomodule/redigo/redis"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -28,9 +29,15 @@ func (ist IdempotencyStoreType) String() string {
 	return [...]string{"local", "shared", "redis"}[ist]
 }
 
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 type IdempotencyHandlerOptions struct {
 	IgnorePaths []string
-	Expiry      time.Duration
+	// Paths (matched exactly, {name} segments match any value) whose
+	// handlers deal with repeated idempotency keys themselves, the key is
+	// still required.
+	HandledPaths []string
+	Expiry       time.Duration
 }
 
 type IdempotencyStore interface {
@@ -179,12 +186,24 @@ func IdempotencyHandler(h http.Handler, opts IdempotencyHandlerOptions, store Id
 			return
 		}
 
-		key := r.Header.Get("Idempotency-Key")
+		key := r.Header.Get(IdempotencyKeyHeader)
 		if len(key) == 0 && r.Method == http.MethodPost {
 			http.Error(rw, "Idempotency-Key header not found", http.StatusBadRequest)
 			return
 		}
 
+		// Keys are only unique per tenant
+		if tenantID := tenants.FromContext(r.Context()); tenantID != "" {
+			key = fmt.Sprintf("%s:%s", tenantID, key)
+		}
+
+		for _, path := range opts.HandledPaths {
+			if matchPath(path, r.URL.Path) {
+				h.ServeHTTP(rw, r)
+				return
+			}
+		}
+
 		exists, err := store.Get(key)
 		if err != nil {
 			log.
@@ -213,3 +232,25 @@ func IdempotencyHandler(h http.Handler, opts IdempotencyHandlerOptions, store Id
 		h.ServeHTTP(rw, r)
 	})
 }
+
+// matchPath tells whether path matches pattern, {name} segments of the
+// pattern match any single segment.
+func matchPath(pattern, path string) bool {
+	pp := strings.Split(pattern, "/")
+	ss := strings.Split(path, "/")
+
+	if len(pp) != len(ss) {
+		return false
+	}
+
+	for i := range pp {
+		if strings.HasPrefix(pp[i], "{") && strings.HasSuffix(pp[i], "}") && ss[i] != "" {
+			continue
+		}
+		if pp[i] != ss[i] {
+			return false
+		}
+	}
+
+	return true
+}
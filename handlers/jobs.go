@@ -25,3 +25,7 @@ func (s *Jobs) List() http.Handler {
 func (s *Jobs) Details() http.Handler {
 	return http.HandlerFunc(s.DetailsFunc)
 }
+
+func (s *Jobs) Requeue() http.Handler {
+	return http.HandlerFunc(s.RequeueFunc)
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/gorilla/mux"
 )
 
@@ -20,7 +23,18 @@ func (s *Jobs) ListFunc(rw http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	jobsSlice, err := s.service.List(limit, offset)
+	filter := jobs.ListFilter{TenantID: tenants.FromContext(r.Context())}
+
+	if status := r.FormValue("status"); status != "" {
+		state, err := jobs.ParseState(status)
+		if err != nil {
+			handleError(rw, r, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err})
+			return
+		}
+		filter.State = state
+	}
+
+	jobsSlice, err := s.service.List(limit, offset, filter)
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -43,6 +57,35 @@ func (s *Jobs) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 
 	job, err := s.service.Details(vars["jobId"])
 
+	if err == nil && !tenantAllowed(r, job.TenantID) {
+		err = &errors.RequestError{StatusCode: http.StatusNotFound, Err: fmt.Errorf("job not found")}
+	}
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res := job.ToJSONResponse()
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// RequeueFunc schedules a dead job for execution again.
+// It reads the job id for the wanted job from URL.
+func (s *Jobs) RequeueFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	job, err := s.service.Details(vars["jobId"])
+
+	if err == nil && !tenantAllowed(r, job.TenantID) {
+		err = &errors.RequestError{StatusCode: http.StatusNotFound, Err: fmt.Errorf("job not found")}
+	}
+
+	if err == nil {
+		job, err = s.service.Requeue(vars["jobId"])
+	}
+
 	if err != nil {
 		handleError(rw, r, err)
 		return
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/schedules"
+)
+
+// Schedules is a HTTP server for recurring transaction schedules.
+type Schedules struct {
+	service schedules.Service
+}
+
+func NewSchedules(service schedules.Service) *Schedules {
+	return &Schedules{service}
+}
+
+func (s *Schedules) Add() http.Handler {
+	h := http.HandlerFunc(s.AddFunc)
+	return UseJson(h)
+}
+
+func (s *Schedules) List() http.Handler {
+	return http.HandlerFunc(s.ListFunc)
+}
+
+func (s *Schedules) Details() http.Handler {
+	return http.HandlerFunc(s.DetailsFunc)
+}
+
+func (s *Schedules) Pause() http.Handler {
+	return http.HandlerFunc(s.PauseFunc)
+}
+
+func (s *Schedules) Resume() http.Handler {
+	return http.HandlerFunc(s.ResumeFunc)
+}
+
+func (s *Schedules) Remove() http.Handler {
+	return http.HandlerFunc(s.RemoveFunc)
+}
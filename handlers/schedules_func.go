@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/schedules"
+	"github.com/gorilla/mux"
+)
+
+func (s *Schedules) AddFunc(rw http.ResponseWriter, r *http.Request) {
+	var sc schedules.Schedule
+
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decode JSON
+	if err := json.NewDecoder(r.Body).Decode(&sc); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	if err := s.service.Add(r.Context(), vars["address"], &sc); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, sc)
+}
+
+func (s *Schedules) ListFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	ss, err := s.service.List(vars["address"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, ss)
+}
+
+func (s *Schedules) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	sc, err := s.service.Details(vars["address"], vars["scheduleId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, sc)
+}
+
+func (s *Schedules) PauseFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	sc, err := s.service.Pause(vars["address"], vars["scheduleId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, sc)
+}
+
+func (s *Schedules) ResumeFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	sc, err := s.service.Resume(vars["address"], vars["scheduleId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, sc)
+}
+
+func (s *Schedules) RemoveFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := s.service.Remove(vars["address"], vars["scheduleId"]); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, vars["scheduleId"])
+}
@@ -24,6 +24,11 @@ func (s *Templates) ListTokens(tType templates.TokenType) http.Handler {
 	return s.MakeListTokensFunc(tType)
 }
 
+func (s *Templates) UpdateToken() http.Handler {
+	h := http.HandlerFunc(s.UpdateTokenFunc)
+	return UseJson(h)
+}
+
 func (s *Templates) GetToken() http.Handler {
 	return http.HandlerFunc(s.GetTokenFunc)
 }
@@ -74,6 +74,45 @@ func (s *Templates) GetTokenFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusOK, token)
 }
 
+// UpdateTokenFunc updates a token with the fields given in the body, e.g.
+// {"disabled": true} disables the token.
+func (s *Templates) UpdateTokenFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	id, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	token, err := s.service.GetTokenById(id)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decode JSON over the current values, fields not in the body are kept
+	if err := json.NewDecoder(r.Body).Decode(token); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	token.ID = id
+
+	if err := s.service.UpdateToken(token); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, token)
+}
+
 func (s *Templates) RemoveTokenFunc(rw http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 
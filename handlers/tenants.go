@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/gorilla/mux"
+)
+
+// Tenant handler middleware
+// ===========================================================================
+
+// UseTenants authenticates requests with an "Authorization: Bearer <api key>"
+// header and scopes them to the tenant the API key belongs to.
+// apiKeys maps API keys to tenant IDs, adminKeys maps API keys to admin IDs.
+// Requests with an admin API key are not scoped to a tenant.
+func UseTenants(h http.Handler, apiKeys, adminKeys map[string]string, ignorePaths []string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		for _, path := range ignorePaths {
+			if strings.HasPrefix(r.URL.Path, path) {
+				h.ServeHTTP(rw, r)
+				return
+			}
+		}
+
+		apiKey := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		if adminID, ok := adminKeys[apiKey]; apiKey != "" && ok {
+			h.ServeHTTP(rw, r.WithContext(tenants.NewAdminContext(r.Context(), adminID)))
+			return
+		}
+
+		tenantID, ok := apiKeys[apiKey]
+		if apiKey == "" || !ok {
+			http.Error(rw, "invalid or missing API key", http.StatusUnauthorized)
+			return
+		}
+
+		h.ServeHTTP(rw, r.WithContext(tenants.NewContext(r.Context(), tenantID)))
+	})
+}
+
+// tenantSystemRoutes are the /system routes open to tenants, their results
+// are filtered by the tenant of the request.
+var tenantSystemRoutes = map[string]bool{
+	"/{apiVersion}/system/audit":                                         true,
+	"/{apiVersion}/system/audit/{entryId}":                               true,
+	"/{apiVersion}/system/deposits":                                      true,
+	"/{apiVersion}/system/deposit-notifications":                         true,
+	"/{apiVersion}/system/fungible-tokens/{tokenName}/withdrawals":       true,
+	"/{apiVersion}/system/fungible-tokens/{tokenName}/balance-snapshots": true,
+}
+
+// RequireAdmin is a router middleware that responds with 403 Forbidden to
+// tenant-scoped requests on admin routes: the /system and /ops routes, apart
+// from the listings filtered by tenant, and changes to token and transaction
+// templates, which are shared by all tenants.
+func RequireAdmin(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if tenants.FromContext(r.Context()) != "" && isAdminRoute(r) {
+			handleError(rw, r, errAdminOnly)
+			return
+		}
+
+		h.ServeHTTP(rw, r)
+	})
+}
+
+func isAdminRoute(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(tpl, "/{apiVersion}/system/"):
+		return !(r.Method == http.MethodGet && tenantSystemRoutes[tpl])
+	case strings.HasPrefix(tpl, "/{apiVersion}/ops/"):
+		return true
+	case tpl == "/{apiVersion}/tokens" || strings.HasPrefix(tpl, "/{apiVersion}/tokens/"):
+		return r.Method != http.MethodGet
+	case tpl == "/{apiVersion}/templates" || strings.HasPrefix(tpl, "/{apiVersion}/templates/"):
+		return r.Method != http.MethodGet
+	}
+
+	return false
+}
+
+var errAdminOnly = &errors.RequestError{StatusCode: http.StatusForbidden, Err: fmt.Errorf("admin API key required")}
+
+// TenantAccountScope returns a router middleware that responds with
+// 404 Not Found to requests for accounts of other tenants, e.g.
+// /accounts/{address}/keys.
+func TenantAccountScope(svc accounts.Service) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			address, ok := mux.Vars(r)["address"]
+			if !ok || tenants.FromContext(r.Context()) == "" {
+				h.ServeHTTP(rw, r)
+				return
+			}
+
+			tenantID, err := svc.Tenant(address)
+			if err == nil && !tenantAllowed(r, tenantID) {
+				err = errAccountNotFound
+			}
+
+			if err != nil && !strings.Contains(err.Error(), "record not found") {
+				handleError(rw, r, err)
+				return
+			}
+
+			// Unknown accounts are handled by the route itself, e.g. adding
+			// watch-only accounts
+			h.ServeHTTP(rw, r)
+		})
+	}
+}
+
+var errAccountNotFound = &errors.RequestError{StatusCode: http.StatusNotFound, Err: fmt.Errorf("account not found")}
+
+// tenantAllowed reports whether a resource of the tenant is visible to the request.
+func tenantAllowed(r *http.Request, tenantID string) bool {
+	requestTenantID := tenants.FromContext(r.Context())
+	return requestTenantID == "" || requestTenantID == tenantID
+}
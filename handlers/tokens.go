@@ -7,6 +7,13 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/tokens"
 )
 
+// AllowDestinationRequest represents a JSON payload for adding an address to
+// the withdrawal destination allowlist of a token
+type AllowDestinationRequest struct {
+	Address     string `json:"address"`
+	Description string `json:"description"`
+}
+
 type Tokens struct {
 	service tokens.Service
 }
@@ -29,26 +36,126 @@ func (s *Tokens) Details() http.Handler {
 	return h
 }
 
+func (s *Tokens) NFTMetadata() http.Handler {
+	h := http.HandlerFunc(s.NFTMetadataFunc)
+	return h
+}
+
+func (s *Tokens) Mint() http.Handler {
+	h := http.HandlerFunc(s.MintFunc)
+	return UseJson(h)
+}
+
+func (s *Tokens) CreateListing() http.Handler {
+	h := http.HandlerFunc(s.CreateListingFunc)
+	return UseJson(h)
+}
+
+func (s *Tokens) RemoveListing() http.Handler {
+	h := http.HandlerFunc(s.RemoveListingFunc)
+	return h
+}
+
 func (s *Tokens) CreateWithdrawal() http.Handler {
 	h := http.HandlerFunc(s.CreateWithdrawalFunc)
 	return UseJson(h)
 }
 
+func (s *Tokens) CreateBatchWithdrawal() http.Handler {
+	h := http.HandlerFunc(s.CreateBatchWithdrawalFunc)
+	return UseJson(h)
+}
+
 func (s *Tokens) ListWithdrawals() http.Handler {
 	h := http.HandlerFunc(s.ListWithdrawalsFunc)
 	return h
 }
 
+func (s *Tokens) ListAllWithdrawals() http.Handler {
+	h := http.HandlerFunc(s.ListAllWithdrawalsFunc)
+	return h
+}
+
 func (s *Tokens) GetWithdrawal() http.Handler {
 	h := http.HandlerFunc(s.GetWithdrawalFunc)
 	return h
 }
 
+func (s *Tokens) ApproveWithdrawal() http.Handler {
+	return http.HandlerFunc(s.ApproveWithdrawalFunc)
+}
+
+func (s *Tokens) RejectWithdrawal() http.Handler {
+	return http.HandlerFunc(s.RejectWithdrawalFunc)
+}
+
+func (s *Tokens) ListWithdrawalDestinations() http.Handler {
+	return http.HandlerFunc(s.ListWithdrawalDestinationsFunc)
+}
+
+func (s *Tokens) AllowWithdrawalDestination() http.Handler {
+	h := http.HandlerFunc(s.AllowWithdrawalDestinationFunc)
+	return UseJson(h)
+}
+
+func (s *Tokens) RemoveWithdrawalDestination() http.Handler {
+	return http.HandlerFunc(s.RemoveWithdrawalDestinationFunc)
+}
+
+func (s *Tokens) CreateLedgerTransfer() http.Handler {
+	h := http.HandlerFunc(s.CreateLedgerTransferFunc)
+	return UseJson(h)
+}
+
+func (s *Tokens) ListLedgerTransfers() http.Handler {
+	return http.HandlerFunc(s.ListLedgerTransfersFunc)
+}
+
+func (s *Tokens) LedgerBalance() http.Handler {
+	return http.HandlerFunc(s.LedgerBalanceFunc)
+}
+
+func (s *Tokens) SettleLedger() http.Handler {
+	return http.HandlerFunc(s.SettleLedgerFunc)
+}
+
+func (s *Tokens) TokenInfo() http.Handler {
+	return http.HandlerFunc(s.TokenInfoFunc)
+}
+
+func (s *Tokens) Holdings() http.Handler {
+	return http.HandlerFunc(s.HoldingsFunc)
+}
+
+func (s *Tokens) TransferFeeEstimate() http.Handler {
+	return http.HandlerFunc(s.TransferFeeEstimateFunc)
+}
+
 func (s *Tokens) ListDeposits() http.Handler {
 	h := http.HandlerFunc(s.ListDepositsFunc)
 	return h
 }
 
+func (s *Tokens) ListAllDeposits() http.Handler {
+	h := http.HandlerFunc(s.ListAllDepositsFunc)
+	return h
+}
+
+func (s *Tokens) ListBalanceSnapshots() http.Handler {
+	h := http.HandlerFunc(s.ListBalanceSnapshotsFunc)
+	return h
+}
+
+func (s *Tokens) ListAllBalanceSnapshots() http.Handler {
+	h := http.HandlerFunc(s.ListAllBalanceSnapshotsFunc)
+	return h
+}
+
+func (s *Tokens) ListDepositNotifications() http.Handler {
+	h := http.HandlerFunc(s.ListDepositNotificationsFunc)
+	return h
+}
+
 func (s *Tokens) GetDeposit() http.Handler {
 	h := http.HandlerFunc(s.GetDepositFunc)
 	return h
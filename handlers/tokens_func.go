@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/flow-hydraulics/flow-wallet-api/tokens"
 	"github.com/gorilla/mux"
 )
@@ -25,6 +28,13 @@ func (s *Tokens) SetupFunc(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if job == nil && transaction == nil {
+		// Vault already set up
+		setUp := true
+		handleJsonResponse(rw, http.StatusOK, tokens.Details{TokenName: tokenName, VaultSetUp: &setUp})
+		return
+	}
+
 	var res interface{}
 	if sync {
 		res = transaction.ToJSONResponse()
@@ -56,13 +66,186 @@ func (s *Tokens) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 	address := vars["address"]
 	tokenName := vars["tokenName"]
 
-	res, err := s.service.Details(r.Context(), tokenName, address)
+	// Cached balances can be bypassed with ?refresh=true
+	refresh, _ := strconv.ParseBool(r.FormValue("refresh"))
+
+	res, err := s.service.CachedDetails(r.Context(), tokenName, address, refresh)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// CreateBatchWithdrawalFunc sends a fungible token to each recipient of an
+// array of withdrawal requests in a single transaction.
+func (s *Tokens) MintFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tokenName := vars["tokenName"]
+
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var request tokens.MintRequest
+
+	// Try to decode the request body into the struct.
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	job, transaction, err := s.service.Mint(r.Context(), sync, tokenName, request)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *Tokens) CreateListingFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+	tokenName := vars["tokenName"]
+
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var request tokens.ListingRequest
+
+	// Try to decode the request body into the struct.
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body")}
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	job, transaction, err := s.service.CreateListing(r.Context(), sync, address, tokenName, request)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *Tokens) RemoveListingFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+	tokenName := vars["tokenName"]
+
+	listingId, err := strconv.ParseUint(vars["listingId"], 10, 64)
+	if err != nil {
+		handleError(rw, r, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid listing id: %q", vars["listingId"])})
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	job, transaction, err := s.service.RemoveListing(r.Context(), sync, address, tokenName, listingId)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) CreateBatchWithdrawalFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+	tokenName := vars["tokenName"]
+
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var requests []tokens.WithdrawalRequest
+
+	// Try to decode the request body into the slice.
+	if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+		err = &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid body, expected an array of withdrawals")}
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+
+	var (
+		res []*tokens.Withdrawal
+		err error
+	)
+
+	// Retried requests with the same key return the original withdrawals
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		res, err = s.service.CreateBatchWithdrawalIdempotent(r.Context(), sync, key, address, tokenName, requests)
+	} else {
+		res, err = s.service.CreateBatchWithdrawal(r.Context(), sync, address, tokenName, requests)
+	}
 
 	if err != nil {
 		handleError(rw, r, err)
 		return
 	}
 
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+// NFTMetadataFunc resolves the metadata views of an NFT of an account.
+func (s *Tokens) NFTMetadataFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["address"]
+	tokenName := vars["tokenName"]
+
+	nftId, err := strconv.ParseUint(vars["nftId"], 10, 64)
+	if err != nil {
+		handleError(rw, r, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid NFT id: %q", vars["nftId"])})
+		return
+	}
+
+	res, err := s.service.NFTMetadata(r.Context(), tokenName, address, nftId)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
@@ -90,20 +273,24 @@ func (s *Tokens) CreateWithdrawalFunc(rw http.ResponseWriter, r *http.Request) {
 
 	// Decide whether to serve sync or async, default async
 	sync := r.FormValue(SyncQueryParameter) != ""
-	job, transaction, err := s.service.CreateWithdrawal(r.Context(), sync, address, withdrawal)
+
+	var (
+		res *tokens.Withdrawal
+		err error
+	)
+
+	// Retried requests with the same key return the original withdrawal
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		res, err = s.service.CreateWithdrawalIdempotent(r.Context(), sync, key, address, withdrawal)
+	} else {
+		res, err = s.service.CreateWithdrawal(r.Context(), sync, address, withdrawal)
+	}
 
 	if err != nil {
 		handleError(rw, r, err)
 		return
 	}
 
-	var res interface{}
-	if sync {
-		res = transaction.ToJSONResponse()
-	} else {
-		res = job.ToJSONResponse()
-	}
-
 	handleJsonResponse(rw, http.StatusCreated, res)
 }
 
@@ -112,7 +299,7 @@ func (s *Tokens) ListWithdrawalsFunc(rw http.ResponseWriter, r *http.Request) {
 	address := vars["address"]
 	tokenName := vars["tokenName"]
 
-	res, err := s.service.ListWithdrawals(address, tokenName)
+	res, err := s.service.ListWithdrawals(r.Context(), address, tokenName)
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -122,14 +309,238 @@ func (s *Tokens) ListWithdrawalsFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
+// ListAllWithdrawalsFunc lists the withdrawals of a token from all accounts
+// of the tenant of the request, newest first. It can be filtered by state
+// (repeated or comma separated) and creation time range.
+func (s *Tokens) ListAllWithdrawalsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tokenName := vars["tokenName"]
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	filter := tokens.WithdrawalFilter{TenantID: tenants.FromContext(r.Context())}
+
+	for _, v := range r.Form["state"] {
+		for _, name := range strings.Split(v, ",") {
+			state, err := tokens.ParseWithdrawalState(strings.TrimSpace(name))
+			if err != nil {
+				handleError(rw, r, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err})
+				return
+			}
+			filter.States = append(filter.States, state)
+		}
+	}
+
+	if err := parseCreatedRange(r, &filter.CreatedAfter, &filter.CreatedBefore); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := s.service.ListAllWithdrawals(r.Context(), tokenName, limit, offset, filter)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
 func (s *Tokens) GetWithdrawalFunc(rw http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	address := vars["address"]
 	tokenName := vars["tokenName"]
-	txId := vars["transactionId"]
+	withdrawalId := vars["withdrawalId"]
+
+	res, err := s.service.GetWithdrawal(r.Context(), address, tokenName, withdrawalId)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) ApproveWithdrawalFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
 
-	res, err := s.service.GetWithdrawal(address, tokenName, txId)
+	res, err := s.service.ApproveWithdrawal(r.Context(), vars["withdrawalId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) RejectWithdrawalFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
 
+	res, err := s.service.RejectWithdrawal(r.Context(), vars["withdrawalId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) ListWithdrawalDestinationsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	dd, err := s.service.ListWithdrawalDestinations(r.Context(), vars["tokenName"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, dd)
+}
+
+func (s *Tokens) AllowWithdrawalDestinationFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req AllowDestinationRequest
+
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decode JSON
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	d, err := s.service.AllowWithdrawalDestination(r.Context(), vars["tokenName"], req.Address, req.Description)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, d)
+}
+
+func (s *Tokens) RemoveWithdrawalDestinationFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := s.service.RemoveWithdrawalDestination(r.Context(), vars["tokenName"], vars["address"]); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, vars["address"])
+}
+
+func (s *Tokens) CreateLedgerTransferFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var transfer tokens.WithdrawalRequest
+
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decode JSON
+	if err := json.NewDecoder(r.Body).Decode(&transfer); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	res, err := s.service.CreateLedgerTransfer(r.Context(), vars["address"], vars["tokenName"], transfer)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *Tokens) ListLedgerTransfersFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	res, err := s.service.ListLedgerTransfers(r.Context(), vars["address"], vars["tokenName"], limit, offset)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) LedgerBalanceFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.LedgerBalance(r.Context(), vars["address"], vars["tokenName"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) SettleLedgerFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := s.service.SettleLedger(r.Context(), vars["tokenName"]); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, vars["tokenName"])
+}
+
+func (s *Tokens) TokenInfoFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.TokenInfo(r.Context(), vars["tokenName"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) HoldingsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.Holdings(r.Context(), vars["tokenName"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Tokens) TransferFeeEstimateFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := s.service.TransferFeeEstimate(r.Context(), vars["tokenName"])
 	if err != nil {
 		handleError(rw, r, err)
 		return
@@ -143,8 +554,146 @@ func (s *Tokens) ListDepositsFunc(rw http.ResponseWriter, r *http.Request) {
 	address := vars["address"]
 	tokenName := vars["tokenName"]
 
-	res, err := s.service.ListDeposits(address, tokenName)
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	filter := tokens.DepositFilter{TenantID: tenants.FromContext(r.Context())}
+
+	if err := parseCreatedRange(r, &filter.CreatedAfter, &filter.CreatedBefore); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := s.service.ListDeposits(address, tokenName, limit, offset, filter)
+
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// ListAllDepositsFunc lists the deposits to all accounts of the tenant of the
+// request, newest first. It can be filtered by recipient (address), token
+// and creation time range.
+func (s *Tokens) ListAllDepositsFunc(rw http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	filter := tokens.DepositFilter{
+		TenantID:  tenants.FromContext(r.Context()),
+		Address:   r.FormValue("address"),
+		TokenName: r.FormValue("token"),
+	}
+
+	if err := parseCreatedRange(r, &filter.CreatedAfter, &filter.CreatedBefore); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := s.service.ListAllDeposits(limit, offset, filter)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// ListBalanceSnapshotsFunc lists the daily balance snapshots of a token of an
+// account, newest first, optionally from and to a date.
+func (s *Tokens) ListBalanceSnapshotsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	filter := tokens.BalanceSnapshotFilter{
+		TenantID: tenants.FromContext(r.Context()),
+		From:     r.FormValue("from"),
+		To:       r.FormValue("to"),
+	}
+
+	res, err := s.service.ListBalanceSnapshots(vars["address"], vars["tokenName"], limit, offset, filter)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// ListAllBalanceSnapshotsFunc lists the daily balance snapshots of a token of
+// all accounts of the tenant of the request, newest first. It can be filtered
+// by account (address) and date range.
+func (s *Tokens) ListAllBalanceSnapshotsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	filter := tokens.BalanceSnapshotFilter{
+		TenantID:  tenants.FromContext(r.Context()),
+		Address:   r.FormValue("address"),
+		TokenName: vars["tokenName"],
+		From:      r.FormValue("from"),
+		To:        r.FormValue("to"),
+	}
+
+	res, err := s.service.ListAllBalanceSnapshots(limit, offset, filter)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+// ListDepositNotificationsFunc lists the deposit webhook notifications with
+// their delivery receipts, newest first, only the undelivered ones if asked.
+func (s *Tokens) ListDepositNotificationsFunc(rw http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.FormValue("limit"))
+	if err != nil {
+		limit = 0
+	}
+
+	offset, err := strconv.Atoi(r.FormValue("offset"))
+	if err != nil {
+		offset = 0
+	}
+
+	undelivered := r.FormValue("undelivered") == "true"
 
+	res, err := s.service.ListDepositNotifications(limit, offset, undelivered)
 	if err != nil {
 		handleError(rw, r, err)
 		return
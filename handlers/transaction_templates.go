@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+)
+
+// TransactionTemplates is a HTTP server for named transaction templates.
+type TransactionTemplates struct {
+	templates    templates.Service
+	transactions transactions.Service
+}
+
+// Transaction template invocation JSON HTTP request
+type InvokeTransactionTemplateRequest struct {
+	Arguments map[string]json.RawMessage `json:"arguments"`
+	GasLimit  uint64                     `json:"gasLimit"`
+	Roles     *transactions.Roles        `json:"roles"`
+	// CallbackURL is notified once the transaction is sealed or fails
+	CallbackURL string `json:"callbackUrl"`
+	// Metadata is a free-form JSON object stored with the transaction
+	Metadata json.RawMessage `json:"metadata"`
+	// Priority of the transaction job, "low", "normal" or "high"
+	Priority jobs.Priority `json:"priority"`
+}
+
+func NewTransactionTemplates(tmpls templates.Service, txs transactions.Service) *TransactionTemplates {
+	return &TransactionTemplates{tmpls, txs}
+}
+
+func (s *TransactionTemplates) Add() http.Handler {
+	h := http.HandlerFunc(s.AddFunc)
+	return UseJson(h)
+}
+
+func (s *TransactionTemplates) List() http.Handler {
+	return http.HandlerFunc(s.ListFunc)
+}
+
+func (s *TransactionTemplates) Details() http.Handler {
+	return http.HandlerFunc(s.DetailsFunc)
+}
+
+func (s *TransactionTemplates) Remove() http.Handler {
+	return http.HandlerFunc(s.RemoveFunc)
+}
+
+func (s *TransactionTemplates) Invoke() http.Handler {
+	h := http.HandlerFunc(s.InvokeFunc)
+	return UseJson(h)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/gorilla/mux"
+)
+
+func (s *TransactionTemplates) AddFunc(rw http.ResponseWriter, r *http.Request) {
+	var t templates.TransactionTemplate
+
+	// Check body is not empty
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decode JSON
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	t.ID = 0
+
+	// Templates are invoked without checking the transaction allowlist, they
+	// count as allowlisted only when registered with an admin API key
+	if tenants.AdminFromContext(r.Context()) == "" {
+		if err := s.transactions.CheckTransactionCode(r.Context(), t.Code); err != nil {
+			handleError(rw, r, err)
+			return
+		}
+	}
+
+	if err := s.templates.AddTransactionTemplate(&t); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, t)
+}
+
+func (s *TransactionTemplates) ListFunc(rw http.ResponseWriter, r *http.Request) {
+	tt, err := s.templates.ListTransactionTemplates()
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, tt)
+}
+
+func (s *TransactionTemplates) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	t, err := s.templates.GetTransactionTemplate(vars["name"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, t)
+}
+
+func (s *TransactionTemplates) RemoveFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := s.templates.RemoveTransactionTemplate(vars["name"]); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, vars["name"])
+}
+
+func (s *TransactionTemplates) InvokeFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req InvokeTransactionTemplateRequest
+
+	// Arguments are optional for templates without any
+	if r.Body != nil && r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handleError(rw, r, InvalidBodyError)
+			return
+		}
+	}
+
+	t, err := s.templates.GetTransactionTemplate(vars["name"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	values, err := t.CadenceArguments(req.Arguments)
+	if err != nil {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("template %s: %w", t.Name, err),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	args := make([]transactions.Argument, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+
+	opts := []transactions.TransactionOption{transactions.WithTemplateName(t.Name)}
+	if req.GasLimit > 0 {
+		opts = append(opts, transactions.WithGasLimit(req.GasLimit))
+	}
+	if req.Roles != nil {
+		opts = append(opts, transactions.WithRoles(*req.Roles))
+	}
+	if req.CallbackURL != "" {
+		opts = append(opts, transactions.WithCallbackURL(req.CallbackURL))
+	}
+	if len(req.Metadata) > 0 {
+		opts = append(opts, transactions.WithMetadata(req.Metadata))
+	}
+	if req.Priority != jobs.PriorityNormal {
+		opts = append(opts, transactions.WithPriority(req.Priority))
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	job, transaction, err := s.transactions.Create(r.Context(), sync, vars["address"], t.Code, args, transactions.General, opts...)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
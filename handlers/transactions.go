@@ -6,6 +6,14 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
 )
 
+// AllowCodeRequest represents a JSON payload for adding code to the code
+// allowlist, given either by hash or by code
+type AllowCodeRequest struct {
+	Hash        string `json:"hash"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
 type Transactions struct {
 	service transactions.Service
 }
@@ -24,16 +32,67 @@ func (s *Transactions) Create() http.Handler {
 	return UseJson(h)
 }
 
+func (s *Transactions) CreateBatch() http.Handler {
+	h := http.HandlerFunc(s.CreateBatchFunc)
+	return UseJson(h)
+}
+
+func (s *Transactions) Build() http.Handler {
+	h := http.HandlerFunc(s.BuildFunc)
+	return UseJson(h)
+}
+
+func (s *Transactions) Send() http.Handler {
+	return http.HandlerFunc(s.SendFunc)
+}
+
+func (s *Transactions) SubmitSigned() http.Handler {
+	return http.HandlerFunc(s.SubmitSignedFunc)
+}
+
+func (s *Transactions) Sponsor() http.Handler {
+	return http.HandlerFunc(s.SponsorFunc)
+}
+
 func (s *Transactions) Sign() http.Handler {
 	h := http.HandlerFunc(s.SignFunc)
 	return UseJson(h)
 }
 
+func (s *Transactions) DryRun() http.Handler {
+	h := http.HandlerFunc(s.DryRunFunc)
+	return UseJson(h)
+}
+
 func (s *Transactions) Details() http.Handler {
 	return http.HandlerFunc(s.DetailsFunc)
 }
 
+func (s *Transactions) Cancel() http.Handler {
+	return http.HandlerFunc(s.CancelFunc)
+}
+
+func (s *Transactions) Events() http.Handler {
+	return http.HandlerFunc(s.EventsFunc)
+}
+
+func (s *Transactions) Fees() http.Handler {
+	return http.HandlerFunc(s.FeesFunc)
+}
+
 func (s *Transactions) ExecuteScript() http.Handler {
 	h := http.HandlerFunc(s.ExecuteScriptFunc)
 	return UseJson(h)
 }
+
+func (s *Transactions) ListAllowedCode(kind string) http.Handler {
+	return s.MakeListAllowedCodeFunc(kind)
+}
+
+func (s *Transactions) AllowCode(kind string) http.Handler {
+	return s.MakeAllowCodeFunc(kind)
+}
+
+func (s *Transactions) RemoveAllowedCode(kind string) http.Handler {
+	return s.MakeRemoveAllowedCodeFunc(kind)
+}
@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
 	"github.com/gorilla/mux"
+	"github.com/onflow/flow-go-sdk"
 )
 
 func (s *Transactions) ListFunc(rw http.ResponseWriter, r *http.Request) {
@@ -29,19 +34,39 @@ func (s *Transactions) ListFunc(rw http.ResponseWriter, r *http.Request) {
 
 	vars := mux.Vars(r)
 
+	var next string
+
 	if address, ok := vars["address"]; ok {
-		// Handle account specific transactions
-		// This endpoint is used to handle "raw" transactions for an account
-		// so we use transactions.General type here
-		transactionSlice, err = s.service.ListForAccount(transactions.General, address, limit, offset)
+		// Handle account specific transactions, "raw" transactions by
+		// default
+		filter, err := parseTransactionListFilter(r, transactions.General)
+		if err != nil {
+			handleError(rw, r, err)
+			return
+		}
+
+		transactionSlice, next, err = s.service.ListForAccount(address, limit, offset, filter)
+		if err != nil {
+			handleError(rw, r, err)
+			return
+		}
 	} else {
-		// Handle all transactions
-		transactionSlice, err = s.service.List(limit, offset)
+		// Handle all transactions, of all types by default
+		filter, err := parseTransactionListFilter(r, transactions.Unknown)
+		if err != nil {
+			handleError(rw, r, err)
+			return
+		}
+
+		transactionSlice, next, err = s.service.List(limit, offset, filter, tenants.FromContext(r.Context()))
+		if err != nil {
+			handleError(rw, r, err)
+			return
+		}
 	}
 
-	if err != nil {
-		handleError(rw, r, err)
-		return
+	if next != "" {
+		rw.Header().Set(NextCursorHeader, next)
 	}
 
 	res := make([]transactions.JSONResponse, len(transactionSlice))
@@ -52,6 +77,63 @@ func (s *Transactions) ListFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
+// parseTransactionListFilter reads transaction list filtering options from
+// the query parameters, listing transactions of defaultType unless a type or
+// token is given.
+func parseTransactionListFilter(r *http.Request, defaultType transactions.Type) (transactions.ListFilter, error) {
+	filter := transactions.ListFilter{
+		Status:       strings.ToUpper(r.FormValue("status")),
+		TemplateName: r.FormValue("template"),
+		TokenName:    r.FormValue("token"),
+		Cursor:       r.FormValue("cursor"),
+	}
+
+	// Token transactions are of other types than transactions.General
+	switch t := r.FormValue("type"); {
+	case t == "all":
+	case t != "":
+		if filter.Type = transactions.StatusFromText(t); filter.Type == transactions.Unknown {
+			return transactions.ListFilter{}, &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid type: %q", t),
+			}
+		}
+	case filter.TokenName == "":
+		filter.Type = defaultType
+	}
+
+	if err := parseCreatedRange(r, &filter.CreatedAfter, &filter.CreatedBefore); err != nil {
+		return transactions.ListFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseCreatedRange reads the createdAfter and createdBefore query
+// parameters as RFC 3339 times.
+func parseCreatedRange(r *http.Request, after, before **time.Time) error {
+	for param, dst := range map[string]**time.Time{
+		"createdAfter":  after,
+		"createdBefore": before,
+	} {
+		v := r.FormValue(param)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid %s, expected RFC 3339 time: %q", param, v),
+			}
+		}
+		*dst = &t
+	}
+
+	return nil
+}
+
 func (s *Transactions) CreateFunc(rw http.ResponseWriter, r *http.Request) {
 	var err error
 
@@ -81,7 +163,14 @@ func (s *Transactions) CreateFunc(rw http.ResponseWriter, r *http.Request) {
 
 	// Decide whether to serve sync or async, default async
 	sync := r.FormValue(SyncQueryParameter) != ""
-	job, transaction, err := s.service.Create(r.Context(), sync, vars["address"], txReq.Code, txReq.Arguments, transactions.General)
+	// Create is also used for the service's own transactions, the code of
+	// clients is checked here
+	if err := s.service.CheckTransactionCode(r.Context(), txReq.Code); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	job, transaction, err := s.service.Create(r.Context(), sync, vars["address"], txReq.Code, txReq.Arguments, transactions.General, txReq.Options()...)
 
 	if err != nil {
 		handleError(rw, r, err)
@@ -98,6 +187,93 @@ func (s *Transactions) CreateFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusCreated, res)
 }
 
+// CreateBatchFunc creates a transaction job for each transaction request of
+// an array, the response lists the job or the error of each request.
+func (s *Transactions) CreateBatchFunc(rw http.ResponseWriter, r *http.Request) {
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var txReqs []transactions.JSONRequest
+
+	// Try to decode the request body into the slice.
+	if err := json.NewDecoder(r.Body).Decode(&txReqs); err != nil {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid body, expected an array of transactions"),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	res, err := s.service.CreateBatch(r.Context(), vars["address"], txReqs)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *Transactions) BuildFunc(rw http.ResponseWriter, r *http.Request) {
+	err := checkNonEmptyBody(r)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var txReq transactions.BuildJSONRequest
+
+	// Try to decode the request body into the struct.
+	err = json.NewDecoder(r.Body).Decode(&txReq)
+	if err != nil {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid body"),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	transaction, err := s.service.Build(r.Context(), txReq.Proposer, txReq.Code, txReq.Arguments, transactions.General, txReq.Options()...)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := transaction.ToBuiltJSONResponse()
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
+func (s *Transactions) SendFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	job, transaction, err := s.service.Send(r.Context(), sync, vars["transactionId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var res interface{}
+	if sync {
+		res = transaction.ToJSONResponse()
+	} else {
+		res = job.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, res)
+}
+
 func (s *Transactions) SignFunc(rw http.ResponseWriter, r *http.Request) {
 	err := checkNonEmptyBody(r)
 	if err != nil {
@@ -107,7 +283,7 @@ func (s *Transactions) SignFunc(rw http.ResponseWriter, r *http.Request) {
 
 	vars := mux.Vars(r)
 
-	var txReq transactions.JSONRequest
+	var txReq transactions.SignJSONRequest
 
 	// Try to decode the request body into the struct.
 	err = json.NewDecoder(r.Body).Decode(&txReq)
@@ -120,7 +296,23 @@ func (s *Transactions) SignFunc(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := s.service.Sign(r.Context(), vars["address"], txReq.Code, txReq.Arguments)
+	var tx *transactions.SignedTransaction
+	if len(txReq.Transaction) > 0 {
+		// Client-built transaction
+		flowTx, decodeErr := transactions.DecodeRawTransaction(txReq.Transaction)
+		if decodeErr != nil {
+			err = &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        decodeErr,
+			}
+			handleError(rw, r, err)
+			return
+		}
+
+		tx, err = s.service.SignRaw(r.Context(), vars["address"], *flowTx)
+	} else {
+		tx, err = s.service.Sign(r.Context(), vars["address"], txReq.Code, txReq.Arguments, txReq.Options()...)
+	}
 	if err != nil {
 		handleError(rw, r, err)
 		return
@@ -139,6 +331,87 @@ func (s *Transactions) SignFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusCreated, resp)
 }
 
+func (s *Transactions) SubmitSignedFunc(rw http.ResponseWriter, r *http.Request) {
+	s.submitSigned(rw, r, s.service.SubmitSigned)
+}
+
+func (s *Transactions) SponsorFunc(rw http.ResponseWriter, r *http.Request) {
+	s.submitSigned(rw, r, s.service.Sponsor)
+}
+
+// submitSigned decodes a client-built transaction from the request body and
+// sends it with submit.
+func (s *Transactions) submitSigned(rw http.ResponseWriter, r *http.Request, submit func(context.Context, bool, flow.Transaction) (*transactions.Transaction, error)) {
+	if err := checkNonEmptyBody(r); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	var req transactions.SubmitSignedJSONRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Transaction) == 0 {
+		handleError(rw, r, InvalidBodyError)
+		return
+	}
+
+	flowTx, err := transactions.DecodeRawTransaction(req.Transaction)
+	if err != nil {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        err,
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	// Decide whether to serve sync or async, default async
+	sync := r.FormValue(SyncQueryParameter) != ""
+	transaction, err := submit(r.Context(), sync, *flowTx)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusCreated, transaction.ToJSONResponse())
+}
+
+func (s *Transactions) DryRunFunc(rw http.ResponseWriter, r *http.Request) {
+	err := checkNonEmptyBody(r)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	var txReq transactions.JSONRequest
+
+	// Try to decode the request body into the struct.
+	err = json.NewDecoder(r.Body).Decode(&txReq)
+	if err != nil {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid body"),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	result, err := s.service.DryRun(r.Context(), vars["address"], txReq.Code, txReq.Arguments, txReq.Options()...)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res, err := result.ToJSONResponse()
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
 func (s *Transactions) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 	var (
 		transaction *transactions.Transaction
@@ -166,6 +439,56 @@ func (s *Transactions) DetailsFunc(rw http.ResponseWriter, r *http.Request) {
 	handleJsonResponse(rw, http.StatusOK, res)
 }
 
+// CancelFunc cancels a transaction whose job is still queued.
+func (s *Transactions) CancelFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Address is only set for the raw transactions of an account
+	transaction, err := s.service.Cancel(r.Context(), vars["address"], vars["transactionId"])
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, transaction.ToJSONResponse())
+}
+
+// EventsFunc lists the events emitted by a transaction, the "type" query
+// parameter limits them to a single event type.
+func (s *Transactions) EventsFunc(rw http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Address is only set for the raw transactions of an account
+	events, err := s.service.Events(r.Context(), vars["address"], vars["transactionId"], r.FormValue("type"))
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	res := make([]transactions.EventJSONResponse, len(events))
+	for i, e := range events {
+		res[i] = e.ToJSONResponse()
+	}
+
+	handleJsonResponse(rw, http.StatusOK, res)
+}
+
+func (s *Transactions) FeesFunc(rw http.ResponseWriter, r *http.Request) {
+	filter := transactions.FeeReportFilter{Address: r.FormValue("address")}
+	if err := parseCreatedRange(r, &filter.CreatedAfter, &filter.CreatedBefore); err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	rows, err := s.service.FeeReport(r.Context(), filter, tenants.FromContext(r.Context()))
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
+	handleJsonResponse(rw, http.StatusOK, rows)
+}
+
 func (s *Transactions) ExecuteScriptFunc(rw http.ResponseWriter, r *http.Request) {
 	var err error
 
@@ -191,12 +514,93 @@ func (s *Transactions) ExecuteScriptFunc(rw http.ResponseWriter, r *http.Request
 		return
 	}
 
-	res, err := s.service.ExecuteScript(r.Context(), txReq.Code, txReq.Arguments)
+	format := r.FormValue("format")
+	if format != "" && format != transactions.ScriptResultJSONCadence && format != transactions.ScriptResultPlain {
+		err = &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid format: %q", format),
+		}
+		handleError(rw, r, err)
+		return
+	}
+
+	at := transactions.ScriptBlock{ID: r.FormValue("blockId")}
+	if v := r.FormValue("blockHeight"); v != "" {
+		height, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			err = &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid blockHeight: %q", v),
+			}
+			handleError(rw, r, err)
+			return
+		}
+		at.Height = &height
+	}
+
+	value, err := s.service.ExecuteScriptAt(r.Context(), txReq.Code, txReq.Arguments, at)
 
 	if err != nil {
 		handleError(rw, r, err)
 		return
 	}
 
+	res, err := transactions.EncodeScriptResult(value, format)
+	if err != nil {
+		handleError(rw, r, err)
+		return
+	}
+
 	handleJsonResponse(rw, http.StatusOK, res)
 }
+
+func (s *Transactions) MakeListAllowedCodeFunc(kind string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		cc, err := s.service.ListAllowedCode(r.Context(), kind)
+		if err != nil {
+			handleError(rw, r, err)
+			return
+		}
+
+		handleJsonResponse(rw, http.StatusOK, cc)
+	}
+}
+
+func (s *Transactions) MakeAllowCodeFunc(kind string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		var req AllowCodeRequest
+
+		// Check body is not empty
+		if err := checkNonEmptyBody(r); err != nil {
+			handleError(rw, r, err)
+			return
+		}
+
+		// Decode JSON
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			handleError(rw, r, InvalidBodyError)
+			return
+		}
+
+		c, err := s.service.AllowCode(r.Context(), kind, req.Hash, req.Code, req.Description)
+		if err != nil {
+			handleError(rw, r, err)
+			return
+		}
+
+		handleJsonResponse(rw, http.StatusCreated, c)
+	}
+}
+
+func (s *Transactions) MakeRemoveAllowedCodeFunc(kind string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		if err := s.service.RemoveAllowedCode(r.Context(), kind, vars["hash"]); err != nil {
+			handleError(rw, r, err)
+			return
+		}
+
+		handleJsonResponse(rw, http.StatusOK, vars["hash"])
+	}
+}
@@ -0,0 +1,143 @@
+// Package health provides periodic health checks of the services backends.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Check is a named health check.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Status is the result of the latest run of a Check.
+type Status struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Checker runs the given checks periodically and keeps the latest results.
+type Checker struct {
+	checks   []Check
+	interval time.Duration
+	timeout  time.Duration
+	ticker   *time.Ticker
+	stopChan chan struct{}
+
+	mu       sync.RWMutex
+	statuses map[string]Status
+}
+
+func NewChecker(interval, timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:   checks,
+		interval: interval,
+		timeout:  timeout,
+		stopChan: make(chan struct{}),
+		statuses: make(map[string]Status, len(checks)),
+	}
+}
+
+// Start runs the checks once and then periodically in the background.
+func (c *Checker) Start() *Checker {
+	if c.ticker != nil {
+		// Already started
+		return c
+	}
+
+	c.ticker = time.NewTicker(c.interval)
+
+	go func() {
+		c.RunChecks()
+
+		for {
+			select {
+			case <-c.stopChan:
+				return
+			case <-c.ticker.C:
+				c.RunChecks()
+			}
+		}
+	}()
+
+	return c
+}
+
+func (c *Checker) Stop() {
+	close(c.stopChan)
+
+	if c.ticker != nil {
+		c.ticker.Stop()
+	}
+}
+
+// RunChecks runs all checks concurrently and stores the results.
+func (c *Checker) RunChecks() {
+	var wg sync.WaitGroup
+
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			c.setStatus(c.run(check))
+		}(check)
+	}
+
+	wg.Wait()
+}
+
+func (c *Checker) run(check Check) Status {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	status := Status{Name: check.Name, Healthy: true}
+
+	if err := check.Check(ctx); err != nil {
+		log.
+			WithFields(log.Fields{"check": check.Name, "error": err}).
+			Warn("Health check failed")
+		status.Healthy = false
+		status.Error = err.Error()
+	}
+
+	status.CheckedAt = time.Now()
+
+	return status
+}
+
+func (c *Checker) setStatus(s Status) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.statuses[s.Name] = s
+}
+
+// Ready returns true if every check has been run and the latest run of each
+// check succeeded, along with the latest status of each check.
+func (c *Checker) Ready() (bool, []Status) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ready := true
+	statuses := make([]Status, 0, len(c.checks))
+
+	for _, check := range c.checks {
+		s, ok := c.statuses[check.Name]
+		if !ok {
+			s = Status{Name: check.Name, Error: "not checked yet"}
+		}
+		if !s.Healthy {
+			ready = false
+		}
+		statuses = append(statuses, s)
+	}
+
+	return ready, statuses
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestChecker(t *testing.T) {
+	ok := Check{Name: "ok", Check: func(ctx context.Context) error { return nil }}
+	failing := Check{Name: "failing", Check: func(ctx context.Context) error { return fmt.Errorf("can not sign") }}
+
+	t.Run("not ready before first run", func(t *testing.T) {
+		c := NewChecker(time.Minute, time.Second, ok)
+
+		if ready, _ := c.Ready(); ready {
+			t.Fatal("expected checker not to be ready")
+		}
+	})
+
+	t.Run("ready when all checks pass", func(t *testing.T) {
+		c := NewChecker(time.Minute, time.Second, ok)
+		c.RunChecks()
+
+		ready, statuses := c.Ready()
+		if !ready {
+			t.Fatalf("expected checker to be ready, got %+v", statuses)
+		}
+	})
+
+	t.Run("not ready when a check fails", func(t *testing.T) {
+		c := NewChecker(time.Minute, time.Second, ok, failing)
+		c.RunChecks()
+
+		ready, statuses := c.Ready()
+		if ready {
+			t.Fatal("expected checker not to be ready")
+		}
+
+		if len(statuses) != 2 {
+			t.Fatalf("expected 2 statuses, got %d", len(statuses))
+		}
+
+		if statuses[1].Healthy || statuses[1].Error != "can not sign" {
+			t.Fatalf("unexpected status %+v", statuses[1])
+		}
+	})
+
+	t.Run("check timeout", func(t *testing.T) {
+		slow := Check{Name: "slow", Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}
+
+		c := NewChecker(time.Minute, 10*time.Millisecond, slow)
+		c.RunChecks()
+
+		if ready, _ := c.Ready(); ready {
+			t.Fatal("expected checker not to be ready")
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/keys/basic"
+	"github.com/flow-hydraulics/flow-wallet-api/keys/encryption"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	access "github.com/onflow/flow-go-sdk/access/grpc"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// runImport imports externally created accounts from an AES-GCM encrypted
+// JSON or CSV file (see accounts.ParseImportEntries) and prints the results.
+// The workerpool is not started, imports do not send transactions.
+func runImport(cfg *configs.Config, path, tenantID string) error {
+	configs.ConfigureLogger(cfg.LogLevel)
+
+	if cfg.ImportEncryptionKey == "" {
+		return fmt.Errorf("IMPORT_ENCRYPTION_KEY is required for importing accounts")
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := encryption.NewAESCrypter([]byte(cfg.ImportEncryptionKey)).Decrypt(encrypted)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt import file: %w", err)
+	}
+
+	entries, err := accounts.ParseImportEntries(data)
+	if err != nil {
+		return err
+	}
+
+	fc, err := access.NewClient(
+		cfg.AccessAPIHost,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.GrpcMaxCallRecvMsgSize)),
+	)
+	if err != nil {
+		return err
+	}
+	defer fc.Close()
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer gorm.Close(db)
+
+	keysDB, err := gorm.NewKeys(cfg)
+	if err != nil {
+		return err
+	}
+	if keysDB != nil {
+		defer gorm.Close(keysDB)
+	} else {
+		keysDB = db
+	}
+
+	wp := jobs.NewWorkerPool(jobs.NewGormStore(db), cfg.WorkerQueueCapacity, cfg.WorkerCount)
+
+	km := basic.NewKeyManager(cfg, keys.NewGormStore(keysDB), fc)
+
+	templateService, err := templates.NewService(cfg, templates.NewGormStore(db))
+	if err != nil {
+		return err
+	}
+	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp)
+	accountService := accounts.NewService(cfg, accounts.NewGormStoreWithKeysDB(db, keysDB), km, fc, wp, transactionService, templateService)
+	tokenService := tokens.NewService(cfg, tokens.NewGormStore(db), km, fc, wp, transactionService, templateService, accountService)
+
+	accounts.AccountAdded.Register(&tokens.AccountAddedHandler{
+		TemplateService: templateService,
+		TokenService:    tokenService,
+	})
+
+	results := accountService.Import(tenants.NewContext(context.Background(), tenantID), entries)
+
+	failed := 0
+	for _, r := range results {
+		if !r.Imported {
+			failed++
+		}
+	}
+
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+
+	log.WithFields(log.Fields{"imported": len(results) - failed, "failed": failed}).Info("Account import finished")
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d accounts failed to import", failed, len(results))
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package jobs
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,8 +27,33 @@ const (
 	Error              State = "ERROR"
 	Complete           State = "COMPLETE"
 	Failed             State = "FAILED"
+	// Cancelled jobs were cancelled before being accepted by a worker
+	Cancelled State = "CANCELLED"
+	// Dead jobs ran out of retries, they can be requeued once the cause of
+	// their errors is fixed
+	Dead State = "DEAD"
 )
 
+var states = []State{Init, Accepted, NoAvailableWorkers, Error, Complete, Failed, Cancelled, Dead}
+
+// ParseState parses the name of a job state, case insensitively.
+func ParseState(name string) (State, error) {
+	for _, s := range states {
+		if strings.EqualFold(string(s), name) {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid job state: %q", name)
+}
+
+// ListFilter restricts a job listing.
+type ListFilter struct {
+	// TenantID restricts the listing to jobs of a tenant, if set
+	TenantID string
+	State    State
+}
+
 // Job database model
 type Job struct {
 	ID                     uuid.UUID      `gorm:"column:id;primary_key;type:uuid;"`
@@ -42,6 +69,9 @@ type Job struct {
 	DeletedAt              gorm.DeletedAt `gorm:"column:deleted_at;index"`
 	ShouldSendNotification bool           `gorm:"-"` // Whether or not to notify admin (via webhook for example)
 	Attributes             datatypes.JSON `gorm:"attributes"`
+	TenantID               string         `gorm:"column:tenant_id;index"`
+	Priority               Priority       `gorm:"column:priority;not null;default:0"`
+	RetryAt                *time.Time     `gorm:"column:retry_at"` // When an errored job is retried, see RetryPolicy
 }
 
 func (Job) TableName() string {
@@ -55,19 +85,23 @@ type JobQueueStatus struct {
 	JobsErrored     int `json:"jobsErrored"`
 	JobsFailed      int `json:"jobsFailed"`
 	JobsCompleted   int `json:"jobsCompleted"`
+	JobsCancelled   int `json:"jobsCancelled"`
+	JobsDead        int `json:"jobsDead"`
 }
 
 // Job HTTP response
 type JSONResponse struct {
-	ID            uuid.UUID `json:"jobId"`
-	Type          string    `json:"type"`
-	State         State     `json:"state"`
-	Error         string    `json:"error"`
-	Errors        []string  `json:"errors"`
-	Result        string    `json:"result"`
-	TransactionID string    `json:"transactionId"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID            uuid.UUID  `json:"jobId"`
+	Type          string     `json:"type"`
+	State         State      `json:"state"`
+	Error         string     `json:"error"`
+	Errors        []string   `json:"errors"`
+	Result        string     `json:"result"`
+	TransactionID string     `json:"transactionId"`
+	Priority      Priority   `json:"priority"`
+	RetryAt       *time.Time `json:"retryAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
 
 func (j Job) ToJSONResponse() JSONResponse {
@@ -79,6 +113,8 @@ func (j Job) ToJSONResponse() JSONResponse {
 		Errors:        []string(j.Errors),
 		Result:        j.Result,
 		TransactionID: j.TransactionID,
+		Priority:      j.Priority,
+		RetryAt:       j.RetryAt,
 		CreatedAt:     j.CreatedAt,
 		UpdatedAt:     j.UpdatedAt,
 	}
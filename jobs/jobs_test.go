@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 
@@ -19,14 +20,16 @@ import (
 
 type dummyStore struct{}
 
-func (*dummyStore) Jobs(datastore.ListOptions) ([]Job, error) { return nil, nil }
-func (*dummyStore) Job(id uuid.UUID) (Job, error)             { return Job{}, nil }
-func (*dummyStore) InsertJob(*Job) error                      { return nil }
-func (*dummyStore) UpdateJob(*Job) error                      { return nil }
+func (*dummyStore) Jobs(datastore.ListOptions, ListFilter) ([]Job, error) { return nil, nil }
+func (*dummyStore) Job(id uuid.UUID) (Job, error)                         { return Job{}, nil }
+func (*dummyStore) InsertJob(*Job) error                                  { return nil }
+func (*dummyStore) UpdateJob(*Job) error                                  { return nil }
 func (*dummyStore) AcceptJob(j *Job, acceptedGracePeriod time.Duration) error {
 	j.ExecCount = j.ExecCount + 1
 	return nil
 }
+func (*dummyStore) CancelJob(jobType, txID string) (bool, error) { return false, nil }
+func (*dummyStore) RequeueJob(id uuid.UUID) (bool, error)        { return false, nil }
 func (*dummyStore) SchedulableJobs(acceptedGracePeriod, reSchedulableGracePeriod time.Duration, o datastore.ListOptions) ([]Job, error) {
 	return nil, nil
 }
@@ -378,3 +381,249 @@ func TestJobErrorMessages(t *testing.T) {
 		}
 	})
 }
+
+func TestCancelledJobIsNotAcceptable(t *testing.T) {
+	for _, state := range []State{Init, NoAvailableWorkers, Error} {
+		if !isAcceptable(&Job{State: state}, time.Minute) {
+			t.Fatalf("expected a job in state %s to be acceptable", state)
+		}
+	}
+
+	for _, state := range []State{Complete, Failed, Cancelled, Dead} {
+		if isAcceptable(&Job{State: state}, time.Minute) {
+			t.Fatalf("expected a job in state %s not to be acceptable", state)
+		}
+	}
+}
+
+func TestJobPriority(t *testing.T) {
+	t.Run("higher priority first", func(t *testing.T) {
+		wp := WorkerPoolImpl{
+			jobChan:     make(chan *Job, 2),
+			highJobChan: make(chan *Job, 2),
+			lowJobChan:  make(chan *Job, 2),
+			stopChan:    make(chan struct{}),
+		}
+
+		for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityNormal} {
+			if !wp.tryEnqueue(&Job{Priority: p}, false) {
+				t.Fatalf("could not enqueue a job of priority %s", p)
+			}
+		}
+
+		if wp.QueueSize() != 4 {
+			t.Fatalf("expected 4 queued jobs, got %d", wp.QueueSize())
+		}
+
+		expected := []Priority{PriorityHigh, PriorityNormal, PriorityNormal, PriorityLow}
+		for _, p := range expected {
+			job, ok := wp.nextJob()
+			if !ok || job.Priority != p {
+				t.Fatalf("expected a job of priority %s, got %+v", p, job)
+			}
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		for name, expected := range map[string]Priority{"": PriorityNormal, "low": PriorityLow, "normal": PriorityNormal, "high": PriorityHigh} {
+			p, err := ParsePriority(name)
+			if err != nil || p != expected {
+				t.Errorf("expected %q to parse to %s, got %s (%v)", name, expected, p, err)
+			}
+		}
+
+		if _, err := ParsePriority("urgent"); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		b, err := json.Marshal(Job{Priority: PriorityHigh}.ToJSONResponse())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res struct{ Priority Priority }
+		if err := json.Unmarshal(b, &res); err != nil || res.Priority != PriorityHigh {
+			t.Fatalf("expected priority to round-trip, got %s (%v) from %s", res.Priority, err, b)
+		}
+	})
+}
+
+func TestJobRetryPolicy(t *testing.T) {
+	errTransient := fmt.Errorf("transient error")
+
+	newPool := func() *WorkerPoolImpl {
+		logger, _ := test.NewNullLogger()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wp := &WorkerPoolImpl{
+			context:            ctx,
+			cancelContext:      cancel,
+			executors:          make(map[string]ExecutorFunc),
+			store:              &dummyStore{},
+			maxJobErrorCount:   10,
+			notificationConfig: &NotificationConfig{},
+		}
+
+		WithLogger(logger)(wp)
+		WithRetryBackoff(time.Minute, 4*time.Minute)(wp)
+
+		return wp
+	}
+
+	t.Run("errored job is retried with backoff", func(t *testing.T) {
+		wp := newPool()
+
+		wp.RegisterExecutor("TestJobType", func(ctx context.Context, j *Job) error {
+			return errTransient
+		})
+
+		job, err := wp.CreateJob("TestJobType", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for n := 1; n <= 4; n++ {
+			before := time.Now()
+
+			if err := wp.process(job); err != nil {
+				t.Fatal(err)
+			}
+
+			if job.State != Error {
+				t.Fatalf("expected job to be in state '%s' got '%s'", Error, job.State)
+			}
+
+			if job.RetryAt == nil {
+				t.Fatal("expected job to have a retry time")
+			}
+
+			// Jittered between the min backoff and the doubled backoff, up to the max
+			max := time.Minute << (n - 1)
+			if max > 4*time.Minute {
+				max = 4 * time.Minute
+			}
+
+			if delay := job.RetryAt.Sub(before); delay < time.Minute || delay > max+time.Second {
+				t.Fatalf("expected retry %d to be delayed by 1m to %s, got %s", n, max, delay)
+			}
+		}
+	})
+
+	t.Run("job is dead after max attempts", func(t *testing.T) {
+		wp := newPool()
+
+		wp.RegisterExecutor("TestJobType", func(ctx context.Context, j *Job) error {
+			return errTransient
+		})
+		wp.RegisterRetryPolicy("TestJobType", RetryPolicy{MaxAttempts: 2})
+
+		job, err := wp.CreateJob("TestJobType", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, expected := range []State{Error, Dead} {
+			if err := wp.process(job); err != nil {
+				t.Fatal(err)
+			}
+
+			if job.State != expected {
+				t.Fatalf("expected job to be in state '%s' got '%s'", expected, job.State)
+			}
+		}
+
+		if job.RetryAt != nil {
+			t.Fatalf("expected dead job not to have a retry time, got %s", job.RetryAt)
+		}
+	})
+
+	t.Run("job fails on an error which is not retryable", func(t *testing.T) {
+		wp := newPool()
+
+		wp.RegisterExecutor("TestJobType", func(ctx context.Context, j *Job) error {
+			if j.ExecCount == 1 {
+				return errTransient
+			}
+			return fmt.Errorf("invalid request")
+		})
+		wp.RegisterRetryPolicy("TestJobType", RetryPolicy{
+			Retryable: func(err error) bool { return errors.Is(err, errTransient) },
+		})
+
+		job, err := wp.CreateJob("TestJobType", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, expected := range []State{Error, Failed} {
+			if err := wp.process(job); err != nil {
+				t.Fatal(err)
+			}
+
+			if job.State != expected {
+				t.Fatalf("expected job to be in state '%s' got '%s'", expected, job.State)
+			}
+		}
+	})
+
+	t.Run("no backoff", func(t *testing.T) {
+		wp := newPool()
+		WithRetryBackoff(0, 0)(wp)
+
+		wp.RegisterExecutor("TestJobType", func(ctx context.Context, j *Job) error {
+			return errTransient
+		})
+
+		job, err := wp.CreateJob("TestJobType", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := wp.process(job); err != nil {
+			t.Fatal(err)
+		}
+
+		if job.State != Error || job.RetryAt != nil {
+			t.Fatalf("expected job to be retried after the grace period, got state '%s' and retry time %v", job.State, job.RetryAt)
+		}
+	})
+
+	t.Run("policy without backoff", func(t *testing.T) {
+		wp := newPool()
+
+		wp.RegisterExecutor("TestJobType", func(ctx context.Context, j *Job) error {
+			return errTransient
+		})
+		wp.RegisterRetryPolicy("TestJobType", RetryPolicy{MinBackoff: NoBackoff})
+
+		job, err := wp.CreateJob("TestJobType", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := wp.process(job); err != nil {
+			t.Fatal(err)
+		}
+
+		if job.State != Error || job.RetryAt != nil {
+			t.Fatalf("expected job to be retried after the grace period, got state '%s' and retry time %v", job.State, job.RetryAt)
+		}
+	})
+}
+
+func TestParseState(t *testing.T) {
+	for name, expected := range map[string]State{"dead": Dead, "DEAD": Dead, "no_available_workers": NoAvailableWorkers} {
+		s, err := ParseState(name)
+		if err != nil || s != expected {
+			t.Errorf("expected %q to parse to %s, got %s (%v)", name, expected, s, err)
+		}
+	}
+
+	for _, name := range []string{"", "zombie"} {
+		if _, err := ParseState(name); err == nil {
+			t.Errorf("expected an error for %q", name)
+		}
+	}
+}
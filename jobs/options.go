@@ -68,8 +68,33 @@ func WithReSchedulableGracePeriod(d time.Duration) WorkerPoolOption {
 	}
 }
 
+// WithRetryBackoff sets the default delay before the first retry of an
+// errored job, doubling with each retry up to max. A min of 0 retries errored
+// jobs after the rescheduling grace period instead.
+func WithRetryBackoff(min, max time.Duration) WorkerPoolOption {
+	return func(wp *WorkerPoolImpl) {
+		wp.retryMinBackoff = min
+		wp.retryMaxBackoff = max
+	}
+}
+
 func WithAttributes(attributes datatypes.JSON) JobOption {
 	return func(job *Job) {
 		job.Attributes = attributes
 	}
 }
+
+// WithTenantID scopes the job to a tenant, the job is executed in a context
+// carrying the tenant ID.
+func WithTenantID(tenantID string) JobOption {
+	return func(job *Job) {
+		job.TenantID = tenantID
+	}
+}
+
+// WithPriority sets the priority of the job, normal by default.
+func WithPriority(p Priority) JobOption {
+	return func(job *Job) {
+		job.Priority = p
+	}
+}
@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"fmt"
+)
+
+// Priority of a job, workers take queued jobs of higher priority first so
+// e.g. background maintenance never delays customer-facing transfers.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+var priorityNames = map[Priority]string{
+	PriorityLow:    "low",
+	PriorityNormal: "normal",
+	PriorityHigh:   "high",
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+// ParsePriority parses "low", "normal" or "high", an empty string being
+// normal priority.
+func ParsePriority(name string) (Priority, error) {
+	if name == "" {
+		return PriorityNormal, nil
+	}
+
+	for p, n := range priorityNames {
+		if n == name {
+			return p, nil
+		}
+	}
+
+	return PriorityNormal, fmt.Errorf("invalid priority: %q, expected \"low\", \"normal\" or \"high\"", name)
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Priority) UnmarshalText(b []byte) error {
+	parsed, err := ParsePriority(string(b))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
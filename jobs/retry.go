@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jpillora/backoff"
+)
+
+// NoBackoff as the MinBackoff of a RetryPolicy retries errored jobs after the
+// rescheduling grace period of the pool, regardless of its default backoff.
+const NoBackoff time.Duration = -1
+
+// RetryPolicy decides whether and when a job is retried after its executor
+// returned an error. Fields left unset (zero) take the defaults of the
+// worker pool.
+type RetryPolicy struct {
+	// MaxAttempts is the number of executions after which an erroring job
+	// is dead. Defaults to the max job error count of the pool plus one.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubling with each
+	// retry up to MaxBackoff. With NoBackoff, or when the pool has no
+	// default backoff, errored jobs are retried after the rescheduling
+	// grace period of the pool.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retryable classifies the errors of the executor, the job fails on the
+	// first error which is not retryable. All errors are retryable if nil.
+	// Errors wrapped with PermanentFailure are never retried.
+	Retryable func(error) bool
+}
+
+// nextState returns the state of a job whose execution failed with err:
+// Error if it is retried, Failed if err is not retryable and Dead once the
+// job ran out of attempts.
+func (p RetryPolicy) nextState(j *Job, err error) State {
+	if errors.Is(err, ErrPermanentFailure) {
+		return Failed
+	}
+
+	if p.Retryable != nil && !p.Retryable(err) {
+		return Failed
+	}
+
+	if j.ExecCount >= p.MaxAttempts {
+		return Dead
+	}
+
+	return Error
+}
+
+// retryAt returns the time of the next retry of a job, nil if the policy
+// has no backoff, e.g. NoBackoff.
+func (p RetryPolicy) retryAt(j *Job) *time.Time {
+	if p.MinBackoff <= 0 {
+		return nil
+	}
+
+	b := &backoff.Backoff{
+		Min:    p.MinBackoff,
+		Max:    p.MaxBackoff,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	t := time.Now().Add(b.ForAttempt(float64(j.ExecCount - 1)))
+	return &t
+}
+
+// retryPolicy returns the retry policy registered for a job type, completed
+// with the defaults of the pool.
+func (wp *WorkerPoolImpl) retryPolicy(jobType string) RetryPolicy {
+	p := wp.retryPolicies[jobType]
+
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = wp.maxJobErrorCount + 1
+	}
+
+	if p.MinBackoff == 0 {
+		p.MinBackoff = wp.retryMinBackoff
+	}
+
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = wp.retryMaxBackoff
+	}
+
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+
+	return p
+}
@@ -11,27 +11,31 @@ import (
 )
 
 type Service interface {
-	List(limit, offset int) (*[]Job, error)
+	List(limit, offset int, f ListFilter) (*[]Job, error)
 	Details(jobID string) (*Job, error)
+	Requeue(jobID string) (*Job, error)
 }
 
 // ServiceImpl defines the API for job HTTP handlers.
 type ServiceImpl struct {
 	store Store
+	wp    WorkerPool
 }
 
-// NewService initiates a new job service.
-func NewService(store Store) Service {
-	return &ServiceImpl{store}
+// NewService initiates a new job service, requeued jobs are scheduled with
+// the worker pool.
+func NewService(store Store, wp WorkerPool) Service {
+	return &ServiceImpl{store, wp}
 }
 
-// List returns all jobs in the datastore.
-func (s *ServiceImpl) List(limit, offset int) (*[]Job, error) {
-	log.WithFields(log.Fields{"limit": limit, "offset": offset}).Trace("List jobs")
+// List returns all jobs in the datastore, scoped to the tenant if one is
+// given and to jobs in a state if one is given.
+func (s *ServiceImpl) List(limit, offset int, f ListFilter) (*[]Job, error) {
+	log.WithFields(log.Fields{"limit": limit, "offset": offset, "tenantID": f.TenantID, "state": f.State}).Trace("List jobs")
 
 	o := datastore.ParseListOptions(limit, offset)
 
-	jobs, err := s.store.Jobs(o)
+	jobs, err := s.store.Jobs(o, f)
 	if err != nil {
 		return nil, err
 	}
@@ -66,3 +70,38 @@ func (s *ServiceImpl) Details(jobID string) (*Job, error) {
 
 	return &job, nil
 }
+
+// Requeue schedules a dead job for execution again, with its attempts reset,
+// e.g. once the cause of its errors has been fixed. The errors of the earlier
+// executions are kept.
+func (s *ServiceImpl) Requeue(jobID string) (*Job, error) {
+	log.WithFields(log.Fields{"jobID": jobID}).Trace("Requeue job")
+
+	job, err := s.Details(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	requeued, err := s.store.RequeueJob(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !requeued {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("job is %s, only dead jobs can be requeued", job.State),
+		}
+	}
+
+	requeuedJob, err := s.store.Job(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.wp.Schedule(&requeuedJob); err != nil {
+		return nil, err
+	}
+
+	return &requeuedJob, nil
+}
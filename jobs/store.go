@@ -9,11 +9,17 @@ import (
 
 // Store manages data regarding jobs.
 type Store interface {
-	Jobs(datastore.ListOptions) ([]Job, error)
+	Jobs(o datastore.ListOptions, f ListFilter) ([]Job, error)
 	Job(id uuid.UUID) (Job, error)
 	InsertJob(*Job) error
 	UpdateJob(*Job) error
 	AcceptJob(j *Job, acceptedGracePeriod time.Duration) error
+	// CancelJob cancels the job of a type for a transaction if no worker
+	// has accepted it yet, it tells whether the job was cancelled.
+	CancelJob(jobType, txID string) (bool, error)
+	// RequeueJob resets a dead job to be executed again with its attempts
+	// reset, it tells whether the job was dead.
+	RequeueJob(id uuid.UUID) (bool, error)
 	SchedulableJobs(acceptedGracePeriod, reSchedulableGracePeriod time.Duration, o datastore.ListOptions) ([]Job, error)
 	Status() ([]StatusQuery, error)
 }
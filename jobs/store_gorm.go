@@ -19,8 +19,9 @@ func NewGormStore(db *gorm.DB) Store {
 	return &GormStore{db}
 }
 
-func (s *GormStore) Jobs(o datastore.ListOptions) (jj []Job, err error) {
+func (s *GormStore) Jobs(o datastore.ListOptions, f ListFilter) (jj []Job, err error) {
 	err = s.db.
+		Where(&Job{TenantID: f.TenantID, State: f.State}).
 		Order("created_at desc").
 		Limit(o.Limit).
 		Offset(o.Offset).
@@ -46,7 +47,7 @@ func isAcceptable(j *Job, acceptedGracePeriod time.Duration) bool {
 	if j.State == Accepted && j.UpdatedAt.After(tAccepted) {
 		return false
 	}
-	if j.State == Complete || j.State == Failed {
+	if j.State == Complete || j.State == Failed || j.State == Cancelled || j.State == Dead {
 		return false
 	}
 	return true
@@ -75,6 +76,38 @@ func (s *GormStore) AcceptJob(j *Job, acceptedGracePeriod time.Duration) error {
 	})
 }
 
+// queuedStates are the states of jobs not accepted by a worker yet.
+var queuedStates = []string{string(Init), string(NoAvailableWorkers)}
+
+func (s *GormStore) CancelJob(jobType, txID string) (bool, error) {
+	// A single conditional update, AcceptJob sees either the queued or the
+	// cancelled job
+	res := s.db.Model(&Job{}).
+		Where("type = ? AND transaction_id = ? AND state IN ?", jobType, txID, queuedStates).
+		Update("state", Cancelled)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (s *GormStore) RequeueJob(id uuid.UUID) (bool, error) {
+	// A single conditional update, concurrent requeues only reset the job once
+	res := s.db.Model(&Job{}).
+		Where("id = ? AND state = ?", id, Dead).
+		Updates(map[string]interface{}{
+			"state":      Init,
+			"exec_count": 0,
+			"retry_at":   nil,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
 func (s *GormStore) SchedulableJobs(acceptedGracePeriod, reSchedulableGracePeriod time.Duration, o datastore.ListOptions) (jj []Job, err error) {
 	t0 := time.Now()
 	tAccepted := t0.Add(-1 * acceptedGracePeriod)
@@ -82,8 +115,12 @@ func (s *GormStore) SchedulableJobs(acceptedGracePeriod, reSchedulableGracePerio
 
 	err = s.db.
 		Where("state IN ? AND updated_at < ?", []string{string(Init), string(Accepted)}, tAccepted).
-		Or("state IN ? AND updated_at < ?", []string{string(Error), string(NoAvailableWorkers)}, tReschedulable).
+		Or("state = ? AND updated_at < ?", string(NoAvailableWorkers), tReschedulable).
+		// Errored jobs are retried after their backoff, if any
+		Or("state = ? AND retry_at IS NULL AND updated_at < ?", string(Error), tReschedulable).
+		Or("state = ? AND retry_at <= ?", string(Error), t0).
 		Model(&Job{}).
+		Order("priority desc").
 		Order("created_at desc").
 		Limit(o.Limit).
 		Offset(o.Offset).
@@ -14,6 +14,7 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
 	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/system"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 )
 
 var (
@@ -36,14 +37,21 @@ var (
 	// Grace time period before re-scheduling jobs that are up for immediate
 	// restart (such as NO_AVAILABLE_WORKERS or ERROR).
 	defaultReSchedulableGracePeriod = 1 * time.Minute
+
+	// Delay before the first retry of an errored job, doubling with each
+	// retry up to the max. Retries are picked up by the DB job poll.
+	defaultRetryMinBackoff = 30 * time.Second
+	defaultRetryMaxBackoff = 15 * time.Minute
 )
 
 type ExecutorFunc func(ctx context.Context, j *Job) error
 
 type WorkerPool interface {
 	RegisterExecutor(jobType string, executorF ExecutorFunc)
+	RegisterRetryPolicy(jobType string, p RetryPolicy)
 	CreateJob(jobType, txID string, opts ...JobOption) (*Job, error)
 	Schedule(j *Job) error
+	CancelJob(jobType, txID string) (bool, error)
 	Status() (WorkerPoolStatus, error)
 	Start()
 	Stop(wait bool)
@@ -54,11 +62,14 @@ type WorkerPool interface {
 type WorkerPoolImpl struct {
 	started       bool
 	wg            *sync.WaitGroup
-	jobChan       chan *Job
+	jobChan       chan *Job // Normal priority
+	highJobChan   chan *Job
+	lowJobChan    chan *Job
 	stopChan      chan struct{}
 	context       context.Context
 	cancelContext context.CancelFunc
 	executors     map[string]ExecutorFunc
+	retryPolicies map[string]RetryPolicy
 	logger        *log.Logger
 
 	store       Store
@@ -69,6 +80,8 @@ type WorkerPoolImpl struct {
 	dbJobPollInterval        time.Duration
 	acceptedGracePeriod      time.Duration
 	reSchedulableGracePeriod time.Duration
+	retryMinBackoff          time.Duration
+	retryMaxBackoff          time.Duration
 
 	notificationConfig *NotificationConfig
 	systemService      system.Service
@@ -86,10 +99,13 @@ func NewWorkerPool(db Store, capacity uint, workerCount uint, opts ...WorkerPool
 	pool := &WorkerPoolImpl{
 		wg:            &sync.WaitGroup{},
 		jobChan:       make(chan *Job, capacity),
+		highJobChan:   make(chan *Job, capacity),
+		lowJobChan:    make(chan *Job, capacity),
 		stopChan:      make(chan struct{}),
 		context:       ctx,
 		cancelContext: cancel,
 		executors:     make(map[string]ExecutorFunc),
+		retryPolicies: make(map[string]RetryPolicy),
 		logger:        log.StandardLogger(),
 
 		store:       db,
@@ -100,6 +116,8 @@ func NewWorkerPool(db Store, capacity uint, workerCount uint, opts ...WorkerPool
 		dbJobPollInterval:        defaultDBJobPollInterval,
 		acceptedGracePeriod:      defaultAcceptedGracePeriod,
 		reSchedulableGracePeriod: defaultReSchedulableGracePeriod,
+		retryMinBackoff:          defaultRetryMinBackoff,
+		retryMaxBackoff:          defaultRetryMaxBackoff,
 
 		notificationConfig: &NotificationConfig{},
 	}
@@ -139,6 +157,10 @@ func (wp *WorkerPoolImpl) Status() (WorkerPoolStatus, error) {
 			status.JobsFailed = r.Count
 		case Complete:
 			status.JobsCompleted = r.Count
+		case Cancelled:
+			status.JobsCancelled = r.Count
+		case Dead:
+			status.JobsDead = r.Count
 		default:
 			continue
 		}
@@ -176,6 +198,15 @@ func (wp *WorkerPoolImpl) RegisterExecutor(jobType string, executorF ExecutorFun
 	wp.executors[jobType] = executorF
 }
 
+// RegisterRetryPolicy sets the retry policy of the jobs of a type, jobs of
+// other types are retried with the defaults of the pool.
+func (wp *WorkerPoolImpl) RegisterRetryPolicy(jobType string, p RetryPolicy) {
+	if wp.retryPolicies == nil {
+		wp.retryPolicies = make(map[string]RetryPolicy)
+	}
+	wp.retryPolicies[jobType] = p
+}
+
 // Schedule will try to immediately schedule the run of a job
 func (wp *WorkerPoolImpl) Schedule(j *Job) error {
 	entry := j.logEntry(wp.logger.WithFields(log.Fields{
@@ -206,6 +237,12 @@ func (wp *WorkerPoolImpl) Schedule(j *Job) error {
 	return nil
 }
 
+// CancelJob cancels the job of a type for a transaction unless a worker has
+// accepted it already, it tells whether the job was cancelled.
+func (wp *WorkerPoolImpl) CancelJob(jobType, txID string) (bool, error) {
+	return wp.store.CancelJob(jobType, txID)
+}
+
 func (wp *WorkerPoolImpl) Start() {
 	if !wp.started {
 		wp.started = true
@@ -219,6 +256,8 @@ func (wp *WorkerPoolImpl) Stop(wait bool) {
 	// Give time for the stop channel to signal before closing job channel
 	time.Sleep(time.Millisecond * 100)
 	close(wp.jobChan)
+	close(wp.highJobChan)
+	close(wp.lowJobChan)
 	if wait {
 		wp.cancelContext()
 		wp.wg.Wait()
@@ -229,8 +268,48 @@ func (wp *WorkerPoolImpl) Capacity() uint {
 	return wp.capacity
 }
 
+// QueueSize is the number of queued jobs of all priorities, each priority
+// has a queue of Capacity.
 func (wp *WorkerPoolImpl) QueueSize() uint {
-	return uint(len(wp.jobChan))
+	return uint(len(wp.jobChan) + len(wp.highJobChan) + len(wp.lowJobChan))
+}
+
+// queue returns the queue of jobs of the priority of job.
+func (wp *WorkerPoolImpl) queue(job *Job) chan *Job {
+	switch {
+	case job.Priority > PriorityNormal && wp.highJobChan != nil:
+		return wp.highJobChan
+	case job.Priority < PriorityNormal && wp.lowJobChan != nil:
+		return wp.lowJobChan
+	}
+	return wp.jobChan
+}
+
+// nextJob blocks until a job is queued, taking jobs of higher priority first.
+// It reports false once the pool is stopped.
+func (wp *WorkerPoolImpl) nextJob() (*Job, bool) {
+	select {
+	case job, ok := <-wp.highJobChan:
+		return job, ok
+	default:
+	}
+
+	select {
+	case job, ok := <-wp.highJobChan:
+		return job, ok
+	case job, ok := <-wp.jobChan:
+		return job, ok
+	default:
+	}
+
+	select {
+	case job, ok := <-wp.highJobChan:
+		return job, ok
+	case job, ok := <-wp.jobChan:
+		return job, ok
+	case job, ok := <-wp.lowJobChan:
+		return job, ok
+	}
 }
 
 func (wp *WorkerPoolImpl) accept(job *Job) bool {
@@ -305,8 +384,9 @@ func (wp *WorkerPoolImpl) startWorkers() {
 		wp.wg.Add(1)
 		go func() {
 			defer wp.wg.Done()
-			for job := range wp.jobChan {
-				if job == nil {
+			for {
+				job, ok := wp.nextJob()
+				if !ok || job == nil {
 					break
 				}
 
@@ -342,18 +422,20 @@ func (wp *WorkerPoolImpl) startWorkers() {
 }
 
 func (wp *WorkerPoolImpl) tryEnqueue(job *Job, block bool) bool {
+	queue := wp.queue(job)
+
 	if block {
 		select {
 		case <-wp.stopChan:
 			return false
-		case wp.jobChan <- job:
+		case queue <- job:
 			return true
 		}
 	} else {
 		select {
 		case <-wp.stopChan:
 			return false
-		case wp.jobChan <- job:
+		case queue <- job:
 			return true
 		default:
 			return false
@@ -385,17 +467,19 @@ func (wp *WorkerPoolImpl) process(job *Job) error {
 		return nil
 	}
 
-	if err := executor(wp.context, job); err != nil {
+	if err := executor(tenants.NewContext(wp.context, job.TenantID), job); err != nil {
 		// Check for chain connection errors
 		if wallet_errors.IsChainConnectionError(err) {
 			// Stop processing this job any further, returning it to the pool.
 			return err
 		}
 
-		if job.ExecCount > wp.maxJobErrorCount || errors.Is(err, ErrPermanentFailure) {
-			job.State = Failed
-		} else {
-			job.State = Error
+		policy := wp.retryPolicy(job.Type)
+
+		job.State = policy.nextState(job, err)
+		job.RetryAt = nil
+		if job.State == Error {
+			job.RetryAt = policy.retryAt(job)
 		}
 
 		job.Error = err.Error()
@@ -408,13 +492,14 @@ func (wp *WorkerPoolImpl) process(job *Job) error {
 	} else {
 		job.State = Complete
 		job.Error = "" // Clear the error message for the final & successful execution
+		job.RetryAt = nil
 	}
 
 	if err := wp.store.UpdateJob(job); err != nil {
 		return fmt.Errorf("error while updating database entry: %w", err)
 	}
 
-	if (job.State == Failed || job.State == Complete) && job.ShouldSendNotification && wp.notificationConfig.ShouldSendJobStatus() {
+	if (job.State == Failed || job.State == Dead || job.State == Complete) && job.ShouldSendNotification && wp.notificationConfig.ShouldSendJobStatus() {
 		if err := wp.scheduleJobStatusNotification(job); err != nil {
 			entry.
 				WithFields(log.Fields{"error": err}).
@@ -139,7 +139,9 @@ type AWSSigner struct {
 	ctx    context.Context
 	client *kms.Client
 	keyId  string
-	hasher crypto.Hasher
+	// hashAlgo is used to create a new hasher for each signature,
+	// hashers are stateful and can not be shared between goroutines
+	hashAlgo  crypto.HashAlgorithm
 	publicKey crypto.PublicKey
 }
 
@@ -178,8 +180,7 @@ func SignerForKey(
 		return nil, fmt.Errorf("unknown hash algorithm")
 	}
 
-	hasher, err := crypto.NewHasher(hashAlgo)
-	if err != nil {
+	if _, err := crypto.NewHasher(hashAlgo); err != nil {
 		return nil, fmt.Errorf("keys/aws: failed to instantiate hasher: %w", err)
 	}
 
@@ -189,10 +190,10 @@ func SignerForKey(
 	)
 
 	return &AWSSigner{
-		ctx:    ctx,
-		client: client,
-		keyId:  key.Value,
-		hasher: hasher,
+		ctx:       ctx,
+		client:    client,
+		keyId:     key.Value,
+		hashAlgo:  hashAlgo,
 		publicKey: decodedPublicKey,
 	}, nil
 }
@@ -201,7 +202,12 @@ func SignerForKey(
 //
 // Reference: https://docs.aws.amazon.com/kms/latest/APIReference/API_Sign.html
 func (s *AWSSigner) Sign(message []byte) ([]byte, error) {
-	digest := s.hasher.ComputeHash(message)
+	hasher, err := crypto.NewHasher(s.hashAlgo)
+	if err != nil {
+		return nil, fmt.Errorf("keys/aws: failed to instantiate hasher: %w", err)
+	}
+
+	digest := hasher.ComputeHash(message)
 
 	sigOut, err := s.client.Sign(s.ctx, &kms.SignInput{
 		KeyId:            &s.keyId,
@@ -242,11 +248,12 @@ func createKMSClient(ctx context.Context) *kms.Client {
 // or (x,y) identifying a public key. Component size is needed for encoding couples comprised of variable length
 // numbers to []byte encoding. They are not always the same length, so occasionally padding is required.
 // Here's how one calculates the required length of each component:
-// 		ECDSA_CurveBits = 256
-// 		ecCoupleComponentSize := ECDSA_CurveBits / 8
-// 		if ECDSA_CurveBits % 8 > 0 {
-//			ecCoupleComponentSize++
-// 		}
+//
+//	ECDSA_CurveBits = 256
+//	ecCoupleComponentSize := ECDSA_CurveBits / 8
+//	if ECDSA_CurveBits % 8 > 0 {
+//		ecCoupleComponentSize++
+//	}
 const ecCoupleComponentSize = 32
 
 func parseSignature(signature []byte) ([]byte, error) {
@@ -0,0 +1,102 @@
+package basic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+var healthCheckMessage = []byte("flow-wallet-api key backend health check")
+
+// HealthChecks returns checks for each key backend used by the key manager.
+func (s *KeyManager) HealthChecks() []keys.HealthCheck {
+	return []keys.HealthCheck{
+		{Name: "keys_database", Check: s.checkDatabase},
+		{Name: "keys_encryption", Check: s.checkEncryption},
+		{Name: "admin_signer", Check: s.checkAdminSigner},
+		{Name: "default_key_signer", Check: s.checkDefaultKeySigner},
+	}
+}
+
+func (s *KeyManager) checkDatabase(ctx context.Context) error {
+	_, err := s.store.ProposalKeyCount()
+	return err
+}
+
+func (s *KeyManager) checkEncryption(ctx context.Context) error {
+	encrypted, err := s.crypter.Encrypt(healthCheckMessage)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	decrypted, err := s.crypter.Decrypt(encrypted)
+	if err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	if !bytes.Equal(decrypted, healthCheckMessage) {
+		return fmt.Errorf("decrypted message does not match")
+	}
+
+	return nil
+}
+
+func (s *KeyManager) checkAdminSigner(ctx context.Context) error {
+	k, signer, err := s.adminAccountSigner(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Bypass the rate limiter, health checks should not use up signing capacity
+	return checkSigner(signer, k.HashAlgo)
+}
+
+// checkDefaultKeySigner signs with a key of the default key type, which new
+// accounts get. The key is only generated once, KMS keys are not free.
+func (s *KeyManager) checkDefaultKeySigner(ctx context.Context) error {
+	s.healthKeyMutex.Lock()
+	defer s.healthKeyMutex.Unlock()
+
+	if s.healthKey == nil {
+		_, k, err := s.GenerateWithType(ctx, s.cfg.DefaultKeyType, 0, flow.AccountKeyWeightThreshold)
+		if err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+		s.healthKey = k
+	}
+
+	signer, err := signerForKey(ctx, flow.EmptyAddress, *s.healthKey)
+	if err != nil {
+		return err
+	}
+
+	return checkSigner(signer, s.healthKey.HashAlgo)
+}
+
+// checkSigner signs the health check message and verifies the signature.
+func checkSigner(signer crypto.Signer, hashAlgo crypto.HashAlgorithm) error {
+	sig, err := signer.Sign(healthCheckMessage)
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+
+	hasher, err := crypto.NewHasher(hashAlgo)
+	if err != nil {
+		return err
+	}
+
+	valid, err := signer.PublicKey().Verify(sig, healthCheckMessage, hasher)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	if !valid {
+		return fmt.Errorf("signature could not be verified")
+	}
+
+	return nil
+}
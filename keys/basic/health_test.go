@@ -0,0 +1,37 @@
+package basic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+)
+
+func TestCheckDefaultKeySigner(t *testing.T) {
+	ctx := context.Background()
+
+	km := &KeyManager{cfg: &configs.Config{
+		DefaultKeyType:  keys.AccountKeyTypeLocal,
+		DefaultSignAlgo: "ECDSA_P256",
+		DefaultHashAlgo: "SHA3_256",
+	}}
+
+	if err := km.checkDefaultKeySigner(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The key is generated once
+	k := km.healthKey
+	if err := km.checkDefaultKeySigner(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if km.healthKey != k {
+		t.Fatal("expected the key to be reused")
+	}
+
+	km = &KeyManager{cfg: &configs.Config{DefaultKeyType: "unknown"}}
+	if err := km.checkDefaultKeySigner(ctx); err == nil {
+		t.Fatal("expected an error for an unknown key type")
+	}
+}
@@ -4,6 +4,9 @@ package basic
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -14,16 +17,36 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/keys/encryption"
 	"github.com/flow-hydraulics/flow-wallet-api/keys/google"
 	"github.com/flow-hydraulics/flow-wallet-api/keys/local"
+	"github.com/flow-hydraulics/flow-wallet-api/keys/tpm"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
 )
 
+// KeyManager is safe for concurrent use by multiple goroutines.
+// Signers are created per authorizer, except for a local admin key whose
+// signer is created once and shared as it does not hold any mutable state.
 type KeyManager struct {
-	store           keys.Store
-	fc              flow_helpers.FlowClient
-	crypter         encryption.Crypter
+	store   keys.Store
+	fc      flow_helpers.FlowClient
+	crypter encryption.Crypter
+	cfg     *configs.Config
+	limiter *signingLimiter
+
+	// adminKeyMutex guards the admin key and its cached signer
+	// as the admin key may be rotated at runtime.
+	adminKeyMutex   sync.RWMutex
 	adminAccountKey keys.Private
-	cfg             *configs.Config
+	adminSigner     crypto.Signer
+
+	rotationMutex sync.Mutex
+	// lastRotation is only used by RotateAdminKeyIfDue, which runs in a
+	// single periodic task.
+	lastRotation time.Time
+
+	// healthKeyMutex guards the key of the default key type used by
+	// health checks, generated on the first check.
+	healthKeyMutex sync.Mutex
+	healthKey      *keys.Private
 }
 
 // NewKeyManager initiates a new key manager.
@@ -51,15 +74,25 @@ func NewKeyManager(cfg *configs.Config, store keys.Store, fc flow_helpers.FlowCl
 		crypter = google.NewGoogleKMSCrypter([]byte(cfg.EncryptionKey))
 	case encryption.EncryptionKeyTypeAWSKMS:
 		crypter = aws.NewAWSKMSCrypter([]byte(cfg.EncryptionKey))
+	case encryption.EncryptionKeyTypeTPM:
+		crypter = tpm.NewTPMCrypter([]byte(cfg.EncryptionKey))
 	}
 
-	return &KeyManager{
-		store,
-		fc,
-		crypter,
-		adminAccountKey,
-		cfg,
+	km := &KeyManager{
+		store:           store,
+		fc:              fc,
+		crypter:         crypter,
+		adminAccountKey: adminAccountKey,
+		cfg:             cfg,
+		limiter:         newSigningLimiter(cfg.SigningRateLimitPerSecond, cfg.SigningRateLimitPerMinute),
 	}
+
+	// Use a rotated admin key instead of the configured one, if any
+	if err := km.loadAdminKey(); err != nil {
+		log.WithFields(log.Fields{"error": err}).Error("Unable to load rotated admin key, using configured admin key")
+	}
+
+	return km
 }
 
 func (s *KeyManager) CheckAdminProposalKeyCount(ctx context.Context) error {
@@ -101,9 +134,13 @@ func (s *KeyManager) CheckAdminProposalKeyCount(ctx context.Context) error {
 }
 
 func (s *KeyManager) Generate(ctx context.Context, keyIndex, weight int) (*flow.AccountKey, *keys.Private, error) {
-	switch s.cfg.DefaultKeyType {
+	return s.GenerateWithType(ctx, s.cfg.DefaultKeyType, keyIndex, weight)
+}
+
+func (s *KeyManager) GenerateWithType(ctx context.Context, keyType string, keyIndex, weight int) (*flow.AccountKey, *keys.Private, error) {
+	switch keyType {
 	default:
-		return nil, nil, fmt.Errorf("keyStore.Generate() not implmented for %s", s.cfg.DefaultKeyType)
+		return nil, nil, fmt.Errorf("keyStore.Generate() not implmented for %s", keyType)
 	case keys.AccountKeyTypeLocal:
 		return local.Generate(
 			keyIndex, weight,
@@ -157,20 +194,35 @@ func (s *KeyManager) UserAuthorizer(ctx context.Context, address flow.Address) (
 }
 
 func (s *KeyManager) MakeAuthorizer(ctx context.Context, address flow.Address) (keys.Authorizer, error) {
-	var k keys.Private
+	var (
+		k   keys.Private
+		sig crypto.Signer
+		err error
+	)
 
 	if address == flow.HexToAddress(s.cfg.AdminAddress) {
-		k = s.adminAccountKey
+		k, sig, err = s.adminAccountSigner(ctx)
+		if err != nil {
+			return keys.Authorizer{}, err
+		}
 	} else {
 		// Get the "least recently used" key for this address
 		sk, err := s.store.AccountKey(flow_helpers.FormatAddress(address))
 		if err != nil {
+			// No keys stored for this address, e.g. a watch-only account
+			if strings.Contains(err.Error(), "record not found") {
+				return keys.Authorizer{}, fmt.Errorf("%w %s: no keys held for account", keys.ErrNonCustodialAccount, flow_helpers.FormatAddress(address))
+			}
 			return keys.Authorizer{}, err
 		}
 		k, err = s.Load(sk)
 		if err != nil {
 			return keys.Authorizer{}, err
 		}
+		sig, err = signerForKey(ctx, address, k)
+		if err != nil {
+			return keys.Authorizer{}, err
+		}
 	}
 
 	acc, err := s.fc.GetAccount(ctx, address)
@@ -178,16 +230,65 @@ func (s *KeyManager) MakeAuthorizer(ctx context.Context, address flow.Address) (
 		return keys.Authorizer{}, err
 	}
 
-	sig, err := signerForKey(ctx, address, k)
+	authorizer := keys.Authorizer{
+		Address: address,
+		Key:     acc.Keys[k.Index],
+		Signer:  s.limiter.wrap(sig, address, k.Index),
+	}
+
+	// Multi-signature accounts need more keys to reach the weight threshold
+	if authorizer.Key.Weight < flow.AccountKeyWeightThreshold && address != flow.HexToAddress(s.cfg.AdminAddress) {
+		authorizer.CoSigners, err = s.coSigners(ctx, acc, k.Index)
+		if err != nil {
+			return keys.Authorizer{}, err
+		}
+	}
+
+	return authorizer, nil
+}
+
+// coSigners returns signers for stored keys of the account, other than the
+// key at skipIndex, until the combined weight reaches the threshold.
+func (s *KeyManager) coSigners(ctx context.Context, acc *flow.Account, skipIndex int) ([]keys.CoSigner, error) {
+	stored, err := s.store.AccountKeys(flow_helpers.FormatAddress(acc.Address))
 	if err != nil {
-		return keys.Authorizer{}, err
+		return nil, err
 	}
 
-	return keys.Authorizer{
-		Address: address,
-		Key:     acc.Keys[k.Index],
-		Signer:  sig,
-	}, nil
+	weight := acc.Keys[skipIndex].Weight
+	result := []keys.CoSigner{}
+
+	for _, sk := range stored {
+		if weight >= flow.AccountKeyWeightThreshold {
+			break
+		}
+
+		if sk.Index == skipIndex || sk.Index >= len(acc.Keys) || acc.Keys[sk.Index].Revoked {
+			continue
+		}
+
+		k, err := s.Load(sk)
+		if err != nil {
+			return nil, err
+		}
+
+		sig, err := signerForKey(ctx, acc.Address, k)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, keys.CoSigner{
+			Key:    acc.Keys[k.Index],
+			Signer: s.limiter.wrap(sig, acc.Address, k.Index),
+		})
+		weight += acc.Keys[k.Index].Weight
+	}
+
+	if weight < flow.AccountKeyWeightThreshold {
+		return nil, fmt.Errorf("not enough key weight held for account %s: %d", flow_helpers.FormatAddress(acc.Address), weight)
+	}
+
+	return result, nil
 }
 
 func (s *KeyManager) AdminProposalKey(ctx context.Context) (keys.Authorizer, error) {
@@ -203,7 +304,7 @@ func (s *KeyManager) AdminProposalKey(ctx context.Context) (keys.Authorizer, err
 		return keys.Authorizer{}, err
 	}
 
-	sig, err := signerForKey(ctx, adminAcc, s.adminAccountKey)
+	_, sig, err := s.adminAccountSigner(ctx)
 	if err != nil {
 		return keys.Authorizer{}, err
 	}
@@ -216,10 +317,50 @@ func (s *KeyManager) AdminProposalKey(ctx context.Context) (keys.Authorizer, err
 	return keys.Authorizer{
 		Address: adminAcc,
 		Key:     acc.Keys[index],
-		Signer:  sig,
+		Signer:  s.limiter.wrap(sig, adminAcc, index),
 	}, nil
 }
 
+// adminAccountSigner returns the current admin key and a signer for it.
+// Local admin keys are decoded only once, KMS signers are bound to the
+// given context and are therefore created on each call.
+func (s *KeyManager) adminAccountSigner(ctx context.Context) (keys.Private, crypto.Signer, error) {
+	adminAcc := flow.HexToAddress(s.cfg.AdminAddress)
+
+	s.adminKeyMutex.RLock()
+	k, sig := s.adminAccountKey, s.adminSigner
+	s.adminKeyMutex.RUnlock()
+
+	if sig != nil {
+		return k, sig, nil
+	}
+
+	sig, err := signerForKey(ctx, adminAcc, k)
+	if err != nil {
+		return k, nil, err
+	}
+
+	if k.Type == keys.AccountKeyTypeLocal {
+		s.adminKeyMutex.Lock()
+		// Only cache if the key was not rotated meanwhile
+		if s.adminAccountKey == k {
+			s.adminSigner = sig
+		}
+		s.adminKeyMutex.Unlock()
+	}
+
+	return k, sig, nil
+}
+
+// setAdminKey replaces the admin key used for signing.
+func (s *KeyManager) setAdminKey(k keys.Private) {
+	s.adminKeyMutex.Lock()
+	defer s.adminKeyMutex.Unlock()
+
+	s.adminAccountKey = k
+	s.adminSigner = nil
+}
+
 func signerForKey(ctx context.Context, address flow.Address, k keys.Private) (crypto.Signer, error) {
 	var (
 		sig crypto.Signer
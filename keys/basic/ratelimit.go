@@ -0,0 +1,114 @@
+package basic
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// signingLimiter limits the number of signatures produced per key using
+// fixed per second and per minute windows. Zero limits are not enforced.
+type signingLimiter struct {
+	perSecond uint
+	perMinute uint
+
+	mu      sync.Mutex
+	windows map[string]*signingWindow
+}
+
+type signingWindow struct {
+	second      time.Time
+	secondCount uint
+	minute      time.Time
+	minuteCount uint
+}
+
+func newSigningLimiter(perSecond, perMinute uint) *signingLimiter {
+	return &signingLimiter{
+		perSecond: perSecond,
+		perMinute: perMinute,
+		windows:   make(map[string]*signingWindow),
+	}
+}
+
+func (l *signingLimiter) enabled() bool {
+	return l != nil && (l.perSecond > 0 || l.perMinute > 0)
+}
+
+// allow reports whether a signature for the given key is allowed at the
+// given time and if so, counts it against the keys limits.
+func (l *signingLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	second := now.Truncate(time.Second)
+	minute := now.Truncate(time.Minute)
+
+	w, ok := l.windows[key]
+	if !ok {
+		l.prune(minute)
+		w = &signingWindow{}
+		l.windows[key] = w
+	}
+
+	if !w.second.Equal(second) {
+		w.second, w.secondCount = second, 0
+	}
+
+	if !w.minute.Equal(minute) {
+		w.minute, w.minuteCount = minute, 0
+	}
+
+	if l.perSecond > 0 && w.secondCount >= l.perSecond {
+		return false
+	}
+
+	if l.perMinute > 0 && w.minuteCount >= l.perMinute {
+		return false
+	}
+
+	w.secondCount++
+	w.minuteCount++
+
+	return true
+}
+
+// prune removes windows that have not been used during the current minute.
+func (l *signingLimiter) prune(minute time.Time) {
+	for k, w := range l.windows {
+		if w.minute.Before(minute) {
+			delete(l.windows, k)
+		}
+	}
+}
+
+// wrap returns a signer which checks the limits of the given key before signing.
+func (l *signingLimiter) wrap(signer crypto.Signer, address flow.Address, keyIndex int) crypto.Signer {
+	if !l.enabled() {
+		return signer
+	}
+
+	return &rateLimitedSigner{
+		Signer:  signer,
+		limiter: l,
+		key:     fmt.Sprintf("%s/%d", address.Hex(), keyIndex),
+	}
+}
+
+type rateLimitedSigner struct {
+	crypto.Signer
+	limiter *signingLimiter
+	key     string
+}
+
+func (s *rateLimitedSigner) Sign(message []byte) ([]byte, error) {
+	if !s.limiter.allow(s.key, time.Now()) {
+		return nil, fmt.Errorf("key %s: %w", s.key, keys.ErrSigningRateLimited)
+	}
+
+	return s.Signer.Sign(message)
+}
@@ -0,0 +1,58 @@
+package basic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSigningLimiter(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("per second", func(t *testing.T) {
+		l := newSigningLimiter(2, 0)
+
+		for i := 0; i < 2; i++ {
+			if !l.allow("a", start) {
+				t.Fatalf("expected signature %d to be allowed", i)
+			}
+		}
+
+		if l.allow("a", start) {
+			t.Fatal("expected signature to be limited")
+		}
+
+		if !l.allow("b", start) {
+			t.Fatal("expected limits to be per key")
+		}
+
+		if !l.allow("a", start.Add(time.Second)) {
+			t.Fatal("expected limit to reset on next second")
+		}
+	})
+
+	t.Run("per minute", func(t *testing.T) {
+		l := newSigningLimiter(0, 3)
+
+		for i := 0; i < 3; i++ {
+			if !l.allow("a", start.Add(time.Duration(i)*time.Second)) {
+				t.Fatalf("expected signature %d to be allowed", i)
+			}
+		}
+
+		if l.allow("a", start.Add(30*time.Second)) {
+			t.Fatal("expected signature to be limited")
+		}
+
+		if !l.allow("a", start.Add(time.Minute)) {
+			t.Fatal("expected limit to reset on next minute")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		l := newSigningLimiter(0, 0)
+
+		if l.enabled() {
+			t.Fatal("expected limiter to be disabled")
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package basic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/keys/local"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// RotateAdminKey generates a new admin key, adds it (and the configured
+// number of proposal key clones) to the admin account, revokes all keys
+// matching the current admin key and starts using the new key.
+//
+// The new key is stored (encrypted) before the transaction is sent so it can
+// not be lost. If the outcome of the transaction is unknown the key is left
+// pending and resolved against the chain state on the next rotation.
+func (s *KeyManager) RotateAdminKey(ctx context.Context, dryRun bool) (*keys.AdminKeyRotation, error) {
+	s.rotationMutex.Lock()
+	defer s.rotationMutex.Unlock()
+
+	adminAddress := flow.HexToAddress(s.cfg.AdminAddress)
+
+	if err := s.resolvePendingAdminKey(ctx); err != nil {
+		return nil, fmt.Errorf("error while resolving pending admin key: %w", err)
+	}
+
+	current, _, err := s.adminAccountSigner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Type != keys.AccountKeyTypeLocal {
+		return nil, fmt.Errorf("admin key rotation is only supported for %s keys, got %s", keys.AccountKeyTypeLocal, current.Type)
+	}
+
+	adminAccount, err := s.fc.GetAccount(ctx, adminAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching admin account from chain: %w", err)
+	}
+
+	if current.Index >= len(adminAccount.Keys) {
+		return nil, fmt.Errorf("admin key index %d not found on chain", current.Index)
+	}
+
+	currentPublicKey := adminAccount.Keys[current.Index].PublicKey
+
+	revoke := []int{}
+	for _, k := range adminAccount.Keys {
+		if !k.Revoked && k.PublicKey.Equals(currentPublicKey) {
+			revoke = append(revoke, k.Index)
+		}
+	}
+
+	proposalKeyCount := s.cfg.AdminProposalKeyCount
+	if proposalKeyCount < 1 {
+		proposalKeyCount = 1
+	}
+
+	// Keys are appended to the account, so the new admin key will get the next free index
+	newIndex := len(adminAccount.Keys)
+
+	flowKey, newKey, err := local.Generate(newIndex, flow.AccountKeyWeightThreshold, current.SignAlgo, current.HashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &keys.AdminKeyRotation{
+		DryRun:           dryRun,
+		NewPublicKey:     flowKey.PublicKey.String(),
+		NewKeyIndex:      newIndex,
+		ProposalKeyCount: proposalKeyCount,
+		RevokedKeys:      revoke,
+	}
+
+	logger := log.WithFields(log.Fields{
+		"dryRun":           dryRun,
+		"newPublicKey":     rotation.NewPublicKey,
+		"newKeyIndex":      rotation.NewKeyIndex,
+		"proposalKeyCount": rotation.ProposalKeyCount,
+		"revokedKeys":      rotation.RevokedKeys,
+	})
+
+	if dryRun {
+		logger.Info("Admin key rotation dry-run")
+		return rotation, nil
+	}
+
+	logger.Info("Rotating admin key")
+
+	storable, err := s.Save(*newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	adminKey := keys.AdminKey{
+		Index:     newIndex,
+		Value:     storable.Value,
+		PublicKey: rotation.NewPublicKey,
+		SignAlgo:  storable.SignAlgo,
+		HashAlgo:  storable.HashAlgo,
+		Status:    keys.AdminKeyStatusPending,
+	}
+
+	if err := s.store.InsertAdminKey(&adminKey); err != nil {
+		return nil, err
+	}
+
+	flowTx, err := s.rotationTransaction(ctx, flowKey, proposalKeyCount, revoke)
+	if err != nil {
+		adminKey.Status = keys.AdminKeyStatusFailed
+		if err := s.store.UpdateAdminKey(&adminKey); err != nil {
+			log.WithFields(log.Fields{"error": err}).Warn("Unable to mark admin key failed")
+		}
+		return nil, err
+	}
+
+	rotation.TransactionID = flowTx.ID().Hex()
+	adminKey.TransactionID = rotation.TransactionID
+	if err := s.store.UpdateAdminKey(&adminKey); err != nil {
+		return nil, err
+	}
+
+	if _, err := flow_helpers.SendAndWait(ctx, s.fc, *flowTx, s.cfg.TransactionTimeout); err != nil {
+		return rotation, fmt.Errorf("admin key rotation transaction %s failed, key left pending: %w", rotation.TransactionID, err)
+	}
+
+	if err := s.resolvePendingAdminKey(ctx); err != nil {
+		return rotation, err
+	}
+
+	logger.WithFields(log.Fields{"transactionId": rotation.TransactionID}).Info("Admin key rotated")
+
+	return rotation, nil
+}
+
+func (s *KeyManager) rotationTransaction(ctx context.Context, newKey *flow.AccountKey, proposalKeyCount uint16, revoke []int) (*flow.Transaction, error) {
+	payer, err := s.AdminAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referenceBlockID, err := flow_helpers.LatestBlockId(ctx, s.fc)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := cadence.NewString(strings.TrimPrefix(newKey.PublicKey.String(), "0x"))
+	if err != nil {
+		return nil, err
+	}
+
+	revokeValues := make([]cadence.Value, len(revoke))
+	for i, index := range revoke {
+		revokeValues[i] = cadence.NewInt(index)
+	}
+
+	flowTx := flow.NewTransaction()
+	flowTx.
+		SetReferenceBlockID(*referenceBlockID).
+		SetProposalKey(payer.Address, payer.Key.Index, payer.Key.SequenceNumber).
+		SetPayer(payer.Address).
+		SetGasLimit(s.cfg.TransactionGasLimit).
+		SetScript([]byte(template_strings.RotateAdminKeyTransaction)).
+		AddAuthorizer(payer.Address)
+
+	args := []cadence.Value{
+		publicKey,
+		cadence.NewUInt8(flow_helpers.CadenceSignatureAlgorithm(newKey.SigAlgo)),
+		cadence.NewUInt8(flow_helpers.CadenceHashAlgorithm(newKey.HashAlgo)),
+		cadence.NewUInt16(proposalKeyCount),
+		cadence.NewArray(revokeValues),
+	}
+
+	for _, a := range args {
+		if err := flowTx.AddArgument(a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer); err != nil {
+		return nil, err
+	}
+
+	return flowTx, nil
+}
+
+// resolvePendingAdminKey activates the latest pending admin key if it has
+// been added to the admin account, otherwise it is marked failed.
+func (s *KeyManager) resolvePendingAdminKey(ctx context.Context) error {
+	pending, err := s.store.AdminKey(keys.AdminKeyStatusPending)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil
+		}
+		return err
+	}
+
+	adminAccount, err := s.fc.GetAccount(ctx, flow.HexToAddress(s.cfg.AdminAddress))
+	if err != nil {
+		return err
+	}
+
+	onChain := pending.Index < len(adminAccount.Keys) &&
+		!adminAccount.Keys[pending.Index].Revoked &&
+		adminAccount.Keys[pending.Index].PublicKey.String() == pending.PublicKey
+
+	if !onChain {
+		log.WithFields(log.Fields{"id": pending.ID, "transactionId": pending.TransactionID}).Warn("Pending admin key not found on chain, marking failed")
+		pending.Status = keys.AdminKeyStatusFailed
+		return s.store.UpdateAdminKey(&pending)
+	}
+
+	k, err := s.loadStoredAdminKey(pending)
+	if err != nil {
+		return err
+	}
+
+	pending.Status = keys.AdminKeyStatusActive
+	if err := s.store.UpdateAdminKey(&pending); err != nil {
+		return err
+	}
+
+	if err := s.store.RetireAdminKeys(pending.ID); err != nil {
+		return err
+	}
+
+	s.setAdminKey(k)
+
+	if _, err := s.InitAdminProposalKeys(ctx); err != nil {
+		return fmt.Errorf("error while initializing admin proposal keys: %w", err)
+	}
+
+	return nil
+}
+
+// loadAdminKey loads the latest active rotated admin key, if any.
+func (s *KeyManager) loadAdminKey() error {
+	active, err := s.store.AdminKey(keys.AdminKeyStatusActive)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil
+		}
+		return err
+	}
+
+	k, err := s.loadStoredAdminKey(active)
+	if err != nil {
+		return err
+	}
+
+	s.setAdminKey(k)
+
+	return nil
+}
+
+func (s *KeyManager) loadStoredAdminKey(a keys.AdminKey) (keys.Private, error) {
+	return s.Load(keys.Storable{
+		Index:    a.Index,
+		Type:     keys.AccountKeyTypeLocal,
+		Value:    a.Value,
+		SignAlgo: a.SignAlgo,
+		HashAlgo: a.HashAlgo,
+	})
+}
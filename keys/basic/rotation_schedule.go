@@ -0,0 +1,92 @@
+package basic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	log "github.com/sirupsen/logrus"
+)
+
+// RotateAdminKeyIfDue rotates the admin key if ADMIN_KEY_ROTATION_INTERVAL
+// has passed since the last rotation. A failed rotation counts as a rotation,
+// so it is not retried on every check, and is posted to the alert webhook.
+func (s *KeyManager) RotateAdminKeyIfDue(ctx context.Context) error {
+	if s.lastRotation.IsZero() {
+		s.lastRotation = time.Now()
+		if k, err := s.store.AdminKey(keys.AdminKeyStatusActive); err == nil {
+			s.lastRotation = k.CreatedAt
+		} else if !strings.Contains(err.Error(), "record not found") {
+			log.WithFields(log.Fields{"error": err}).Warn("Unable to get last admin key rotation time")
+		}
+	}
+
+	if time.Since(s.lastRotation) < s.cfg.AdminKeyRotationInterval {
+		return nil
+	}
+
+	s.lastRotation = time.Now()
+
+	rotation, err := s.RotateAdminKey(ctx, s.cfg.AdminKeyRotationDryRun)
+	if err != nil {
+		if alertErr := s.rotationAlert(rotation, err); alertErr != nil {
+			log.WithFields(log.Fields{"error": alertErr}).Warn("Unable to send admin key rotation alert")
+		}
+		return err
+	}
+
+	return nil
+}
+
+type rotationAlert struct {
+	Event    string                 `json:"event"`
+	Error    string                 `json:"error"`
+	Rotation *keys.AdminKeyRotation `json:"rotation,omitempty"`
+}
+
+func (s *KeyManager) rotationAlert(rotation *keys.AdminKeyRotation, rotationErr error) error {
+	if s.cfg.AdminKeyRotationAlertWebhookUrl == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(rotationAlert{
+		Event:    "admin_key_rotation_failed",
+		Error:    rotationErr.Error(),
+		Rotation: rotation,
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout := s.cfg.JobStatusWebhookTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.AdminKeyRotationAlertWebhookUrl, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("error while creating alert request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while sending alert request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alert endpoint responded with an unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
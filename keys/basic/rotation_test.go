@@ -0,0 +1,381 @@
+package basic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/keys/local"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// dummyFlowClient serves the admin account and records the transactions
+// sent, onSend applies a sent transaction to the account.
+type dummyFlowClient struct {
+	flow_helpers.FlowClient
+	account *flow.Account
+	sent    []flow.Transaction
+	sendErr error
+	// Error of the results of sent transactions
+	resultErr error
+	onSend    func(tx flow.Transaction)
+}
+
+func (c *dummyFlowClient) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
+	acc := *c.account
+	acc.Keys = make([]*flow.AccountKey, len(c.account.Keys))
+	for i, k := range c.account.Keys {
+		key := *k
+		acc.Keys[i] = &key
+	}
+	return &acc, nil
+}
+
+func (c *dummyFlowClient) GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.BlockHeader, error) {
+	return &flow.BlockHeader{ID: flow.Identifier{1}}, nil
+}
+
+func (c *dummyFlowClient) SendTransaction(ctx context.Context, tx flow.Transaction) error {
+	if c.sendErr != nil {
+		return c.sendErr
+	}
+	c.sent = append(c.sent, tx)
+	if c.onSend != nil {
+		c.onSend(tx)
+	}
+	return nil
+}
+
+func (c *dummyFlowClient) GetTransactionResult(ctx context.Context, txID flow.Identifier) (*flow.TransactionResult, error) {
+	return &flow.TransactionResult{Status: flow.TransactionStatusSealed, Error: c.resultErr}, nil
+}
+
+// addKeys adds the keys of a rotation to the account and revokes the old
+// ones, like the rotation transaction does.
+func (c *dummyFlowClient) addKeys(r keys.AdminKey, proposalKeyCount int, revoke []int) {
+	publicKey, err := crypto.DecodePublicKeyHex(crypto.StringToSignatureAlgorithm(r.SignAlgo), r.PublicKey[2:])
+	if err != nil {
+		panic(err)
+	}
+
+	for i := 0; i < proposalKeyCount; i++ {
+		weight := 0
+		if i == 0 {
+			weight = flow.AccountKeyWeightThreshold
+		}
+		c.account.Keys = append(c.account.Keys, &flow.AccountKey{
+			Index:     len(c.account.Keys),
+			PublicKey: publicKey,
+			SigAlgo:   publicKey.Algorithm(),
+			HashAlgo:  crypto.StringToHashAlgorithm(r.HashAlgo),
+			Weight:    weight,
+		})
+	}
+
+	for _, i := range revoke {
+		c.account.Keys[i].Revoked = true
+	}
+}
+
+// newRotationTestKeyManager returns a key manager on a fresh sqlite database
+// for an admin account with the admin key at index 0 and a clone of it as a
+// proposal key at index 1.
+func newRotationTestKeyManager(t *testing.T) (*KeyManager, *dummyFlowClient, keys.Store) {
+	t.Helper()
+
+	flowKey, adminKey, err := local.Generate(0, flow.AccountKeyWeightThreshold, crypto.ECDSA_P256, crypto.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone := *flowKey
+	clone.Index = 1
+	clone.Weight = 0
+
+	otherKey, _, err := local.Generate(2, flow.AccountKeyWeightThreshold, crypto.ECDSA_P256, crypto.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := flow.HexToAddress("0xf8d6e0586b0a20c7")
+
+	cfg := &configs.Config{
+		ChainID:               flow.Emulator,
+		DatabaseType:          "sqlite",
+		DatabaseDSN:           path.Join(t.TempDir(), "test.db"),
+		AdminAddress:          address.Hex(),
+		AdminKeyIndex:         0,
+		AdminKeyType:          keys.AccountKeyTypeLocal,
+		AdminPrivateKey:       adminKey.Value,
+		AdminProposalKeyCount: 2,
+		DefaultSignAlgo:       crypto.ECDSA_P256.String(),
+		DefaultHashAlgo:       crypto.SHA3_256.String(),
+		EncryptionKey:         "faae4ed1c30f4e4555ee3a71f1044a8e",
+		EncryptionKeyType:     "local",
+	}
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gorm.Close(db) })
+
+	fc := &dummyFlowClient{
+		account: &flow.Account{Address: address, Keys: []*flow.AccountKey{flowKey, &clone, otherKey}},
+	}
+
+	store := keys.NewGormStore(db)
+
+	return NewKeyManager(cfg, store, fc), fc, store
+}
+
+func assertAdminKeyIndex(t *testing.T, km *KeyManager, index int) {
+	t.Helper()
+
+	k, _, err := km.adminAccountSigner(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if k.Index != index {
+		t.Fatalf("expected admin key %d in use, got %d", index, k.Index)
+	}
+}
+
+func TestRotateAdminKeyDryRun(t *testing.T) {
+	km, fc, store := newRotationTestKeyManager(t)
+
+	rotation, err := km.RotateAdminKey(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rotation.DryRun || rotation.NewKeyIndex != 3 || rotation.ProposalKeyCount != 2 || rotation.TransactionID != "" {
+		t.Fatalf("unexpected dry-run rotation: %+v", rotation)
+	}
+
+	// The admin key and its proposal key clone are revoked, other keys are kept
+	if len(rotation.RevokedKeys) != 2 || rotation.RevokedKeys[0] != 0 || rotation.RevokedKeys[1] != 1 {
+		t.Fatalf("expected keys 0 and 1 to be revoked, got %v", rotation.RevokedKeys)
+	}
+
+	if len(fc.sent) != 0 {
+		t.Fatalf("expected no transactions, got %d", len(fc.sent))
+	}
+
+	if _, err := store.AdminKey(keys.AdminKeyStatusPending); err == nil {
+		t.Fatal("expected no admin key to be stored")
+	}
+
+	assertAdminKeyIndex(t, km, 0)
+}
+
+func TestRotateAdminKey(t *testing.T) {
+	km, fc, store := newRotationTestKeyManager(t)
+
+	fc.onSend = func(tx flow.Transaction) {
+		pending, err := store.AdminKey(keys.AdminKeyStatusPending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fc.addKeys(pending, 2, []int{0, 1})
+	}
+
+	rotation, err := km.RotateAdminKey(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fc.sent) != 1 || rotation.TransactionID != fc.sent[0].ID().Hex() {
+		t.Fatalf("expected the rotation transaction to be sent, got %+v", rotation)
+	}
+
+	active, err := store.AdminKey(keys.AdminKeyStatusActive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if active.Index != 3 || active.PublicKey != rotation.NewPublicKey {
+		t.Fatalf("expected the new key to be active, got %+v", active)
+	}
+
+	assertAdminKeyIndex(t, km, 3)
+
+	for i, k := range fc.account.Keys {
+		if revoked := i < 2; k.Revoked != revoked {
+			t.Fatalf("expected key %d revoked %t, got %t", i, revoked, k.Revoked)
+		}
+	}
+
+	// The proposal keys are reset to the keys which are not revoked
+	count, err := store.ProposalKeyCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 3 {
+		t.Fatalf("expected 3 proposal keys, got %d", count)
+	}
+
+	// The next rotation revokes the keys of the rotated admin key
+	rotation, err = km.RotateAdminKey(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rotation.RevokedKeys) != 2 || rotation.RevokedKeys[0] != 3 || rotation.RevokedKeys[1] != 4 {
+		t.Fatalf("expected keys 3 and 4 to be revoked, got %v", rotation.RevokedKeys)
+	}
+}
+
+func TestRotateAdminKeyFailed(t *testing.T) {
+	km, fc, store := newRotationTestKeyManager(t)
+
+	// The transaction is reverted, the account is unchanged
+	fc.resultErr = errors.New("reverted")
+
+	if _, err := km.RotateAdminKey(context.Background(), false); err == nil {
+		t.Fatal("expected the rotation to fail")
+	}
+
+	pending, err := store.AdminKey(keys.AdminKeyStatusPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertAdminKeyIndex(t, km, 0)
+
+	// The pending key is not on chain and marked failed by the next rotation
+	fc.resultErr = nil
+
+	if _, err := km.RotateAdminKey(context.Background(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.AdminKey(keys.AdminKeyStatusPending); err == nil {
+		t.Fatal("expected no pending admin key")
+	}
+
+	if _, err := store.AdminKey(keys.AdminKeyStatusActive); err == nil {
+		t.Fatalf("expected the pending admin key %d not to be activated", pending.ID)
+	}
+
+	assertAdminKeyIndex(t, km, 0)
+}
+
+func TestRotateAdminKeyInterrupted(t *testing.T) {
+	km, fc, store := newRotationTestKeyManager(t)
+
+	// The transaction is sent but its result is unknown, e.g. the service
+	// was stopped while waiting for it
+	fc.resultErr = errors.New("connection lost")
+	fc.onSend = func(tx flow.Transaction) {
+		pending, err := store.AdminKey(keys.AdminKeyStatusPending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fc.addKeys(pending, 2, []int{0, 1})
+	}
+
+	rotation, err := km.RotateAdminKey(context.Background(), false)
+	if err == nil {
+		t.Fatal("expected the rotation to fail")
+	}
+
+	if rotation == nil || rotation.TransactionID == "" {
+		t.Fatalf("expected the rotation transaction, got %+v", rotation)
+	}
+
+	if _, err := store.AdminKey(keys.AdminKeyStatusPending); err != nil {
+		t.Fatalf("expected the admin key to be left pending: %s", err)
+	}
+
+	// The pending key is on chain and activated by the next rotation
+	fc.resultErr = nil
+	fc.onSend = nil
+
+	if err := km.resolvePendingAdminKey(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := store.AdminKey(keys.AdminKeyStatusActive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if active.Index != 3 || active.TransactionID != rotation.TransactionID {
+		t.Fatalf("expected the pending key to be active, got %+v", active)
+	}
+
+	assertAdminKeyIndex(t, km, 3)
+}
+
+func TestRotateAdminKeyIfDue(t *testing.T) {
+	ctx := context.Background()
+
+	alerts := make(chan rotationAlert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var a rotationAlert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Error(err)
+		}
+		alerts <- a
+	}))
+	defer server.Close()
+
+	km, fc, _ := newRotationTestKeyManager(t)
+	km.cfg.AdminKeyRotationInterval = time.Hour
+	km.cfg.AdminKeyRotationAlertWebhookUrl = server.URL
+
+	// Without rotations the interval starts on the first check
+	if err := km.RotateAdminKeyIfDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fc.sent) != 0 {
+		t.Fatal("expected no rotation before the interval has passed")
+	}
+
+	// Failed rotations are alerted and count as a rotation
+	fc.resultErr = errors.New("reverted")
+	km.lastRotation = time.Now().Add(-2 * time.Hour)
+
+	if err := km.RotateAdminKeyIfDue(ctx); err == nil {
+		t.Fatal("expected the rotation to fail")
+	}
+
+	if len(fc.sent) != 1 {
+		t.Fatalf("expected the rotation transaction to be sent, got %d", len(fc.sent))
+	}
+
+	if time.Since(km.lastRotation) > time.Minute {
+		t.Fatal("expected a failed rotation to be counted as a rotation")
+	}
+
+	select {
+	case a := <-alerts:
+		if a.Event != "admin_key_rotation_failed" || a.Rotation == nil || a.Rotation.NewKeyIndex != 3 {
+			t.Fatalf("unexpected alert: %+v", a)
+		}
+	default:
+		t.Fatal("expected an alert for a failed rotation")
+	}
+
+	if err := km.RotateAdminKeyIfDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fc.sent) != 1 || len(alerts) != 0 {
+		t.Fatal("expected a failed rotation not to be retried right away")
+	}
+}
@@ -9,3 +9,4 @@ type Crypter interface {
 const EncryptionKeyTypeGoogleKMS = "google_kms"
 const EncryptionKeyTypeAWSKMS = "aws_kms"
 const EncryptionKeyTypeLocal = "local"
+const EncryptionKeyTypeTPM = "tpm"
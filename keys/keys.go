@@ -19,10 +19,24 @@ const (
 
 var ErrAdminProposalKeyCountMismatch = errors.New("admin-proposal-key count mismatch")
 
+// ErrSigningRateLimited is returned when a key has exceeded its configured
+// signing rate. The operation can be retried once the limit window has passed.
+var ErrSigningRateLimited = errors.New("signing rate limit exceeded")
+
+// ErrNonCustodialAccount is returned when trying to sign for an account the
+// service does not hold any keys for.
+var ErrNonCustodialAccount = errors.New("non-custodial account")
+
+// ErrAccountDisabled is returned when trying to sign for a disabled account.
+var ErrAccountDisabled = errors.New("account disabled")
+
 // Manager provides the functions needed for key management.
 type Manager interface {
 	// Generate generates a new Key using provided key index and weight.
 	Generate(ctx context.Context, keyIndex, weight int) (*flow.AccountKey, *Private, error)
+	// GenerateWithType generates a new Key of the given type (key backend)
+	// using provided key index and weight.
+	GenerateWithType(ctx context.Context, keyType string, keyIndex, weight int) (*flow.AccountKey, *Private, error)
 	// GenerateDefault generates a new Key using application defaults.
 	GenerateDefault(context.Context) (*flow.AccountKey, *Private, error)
 	// Save is responsible for converting an "in flight" key to a storable key.
@@ -40,6 +54,17 @@ type Manager interface {
 	InitAdminProposalKeys(ctx context.Context) (uint16, error)
 	// AdminProposalKey returns Authorizer to be used as proposer.
 	AdminProposalKey(ctx context.Context) (Authorizer, error)
+	// RotateAdminKey replaces the admin account key (and its proposal key clones)
+	// with a newly generated key. In dry-run mode nothing is sent or stored.
+	RotateAdminKey(ctx context.Context, dryRun bool) (*AdminKeyRotation, error)
+	// HealthChecks returns checks verifying that the key backends are usable.
+	HealthChecks() []HealthCheck
+}
+
+// HealthCheck is a named check of a key backend.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
 }
 
 // Storable struct represents a storable account private key.
@@ -58,6 +83,9 @@ type Storable struct {
 	CreatedAt      time.Time      `json:"createdAt"`
 	UpdatedAt      time.Time      `json:"updatedAt"`
 	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+	// RevokedAt is set when the key was found revoked or missing on chain,
+	// revoked keys stay soft deleted when their account is enabled again
+	RevokedAt *time.Time `json:"-" gorm:"column:revoked_at"`
 }
 
 // Rename the database table to improve database readability
@@ -76,6 +104,45 @@ func (ProposalKey) TableName() string {
 	return "proposal_keys"
 }
 
+type AdminKeyStatus string
+
+const (
+	AdminKeyStatusPending AdminKeyStatus = "PENDING"
+	AdminKeyStatusActive  AdminKeyStatus = "ACTIVE"
+	AdminKeyStatusRetired AdminKeyStatus = "RETIRED"
+	AdminKeyStatusFailed  AdminKeyStatus = "FAILED"
+)
+
+// AdminKey is a rotated admin account key. The latest active AdminKey
+// overrides the admin key set in configuration.
+// AdminKey.Value is encrypted the same way as Storable.Value.
+type AdminKey struct {
+	ID            int            `json:"id" gorm:"primaryKey"`
+	Index         int            `json:"index"`
+	Value         []byte         `json:"-"`
+	PublicKey     string         `json:"publicKey"`
+	SignAlgo      string         `json:"signAlgo"`
+	HashAlgo      string         `json:"hashAlgo"`
+	Status        AdminKeyStatus `json:"status" gorm:"index"`
+	TransactionID string         `json:"transactionId"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
+
+func (AdminKey) TableName() string {
+	return "admin_keys"
+}
+
+// AdminKeyRotation describes a (planned) admin key rotation.
+type AdminKeyRotation struct {
+	DryRun           bool   `json:"dryRun"`
+	NewPublicKey     string `json:"newPublicKey"`
+	NewKeyIndex      int    `json:"newKeyIndex"`
+	ProposalKeyCount uint16 `json:"proposalKeyCount"`
+	RevokedKeys      []int  `json:"revokedKeys"`
+	TransactionID    string `json:"transactionId,omitempty"`
+}
+
 // Private is an "in flight" account private key meaning its Value should be the actual
 // private key or resource id (unencrypted).
 type Private struct {
@@ -91,6 +158,15 @@ type Authorizer struct {
 	Address flow.Address
 	Key     *flow.AccountKey
 	Signer  crypto.Signer
+	// CoSigners are the additional keys needed to reach the signature weight
+	// threshold when Key alone does not have full weight (multi-signature accounts).
+	CoSigners []CoSigner
+}
+
+// CoSigner is an additional account key signing on behalf of an Authorizer.
+type CoSigner struct {
+	Key    *flow.AccountKey
+	Signer crypto.Signer
 }
 
 func (a *Authorizer) Equals(t Authorizer) bool {
@@ -4,6 +4,7 @@ package local
 import (
 	"context"
 	"crypto/rand"
+	"fmt"
 	"strings"
 
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
@@ -46,10 +47,47 @@ func Generate(
 	return f, p, nil
 }
 
+// Signer returns a signer for the given local key.
+// The returned signer is safe for concurrent use.
 func Signer(ctx context.Context, key keys.Private) (crypto.Signer, error) {
 	p, err := crypto.DecodePrivateKeyHex(key.SignAlgo, key.Value)
 	if err != nil {
-		return crypto.InMemorySigner{}, err
+		return nil, err
 	}
-	return crypto.NewInMemorySigner(p, key.HashAlgo)
+	return NewSigner(p, key.HashAlgo)
+}
+
+// NewSigner returns a signer for the given private key and hash algorithm.
+//
+// Unlike crypto.InMemorySigner, which shares a single stateful hasher between
+// all calls to Sign, this signer uses a new hasher for every signature and
+// can therefore be shared between goroutines.
+func NewSigner(privateKey crypto.PrivateKey, hashAlgo crypto.HashAlgorithm) (crypto.Signer, error) {
+	if !crypto.CompatibleAlgorithms(privateKey.Algorithm(), hashAlgo) {
+		return nil, fmt.Errorf("signature algorithm %s and hashing algorithm %s are incompatible", privateKey.Algorithm(), hashAlgo)
+	}
+
+	// PublicKey lazily computes and stores the public key inside the private
+	// key, so it is called once here before the key gets shared.
+	publicKey := privateKey.PublicKey()
+
+	return &signer{privateKey, publicKey, hashAlgo}, nil
+}
+
+type signer struct {
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+	hashAlgo   crypto.HashAlgorithm
+}
+
+func (s *signer) Sign(message []byte) ([]byte, error) {
+	hasher, err := crypto.NewHasher(s.hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+	return s.privateKey.Sign(message, hasher)
+}
+
+func (s *signer) PublicKey() crypto.PublicKey {
+	return s.publicKey
 }
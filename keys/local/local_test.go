@@ -0,0 +1,118 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+func newTestSigner(t testing.TB) (crypto.Signer, crypto.HashAlgorithm) {
+	_, k, err := Generate(0, 1000, crypto.ECDSA_P256, crypto.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Signer(context.Background(), *k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, k.HashAlgo
+}
+
+func TestSignerConcurrency(t *testing.T) {
+	signer, hashAlgo := newTestSigner(t)
+
+	const goroutines = 16
+	const signatures = 50
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			hasher, err := crypto.NewHasher(hashAlgo)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			for i := 0; i < signatures; i++ {
+				message := []byte(fmt.Sprintf("message %d-%d", g, i))
+
+				sig, err := signer.Sign(message)
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				valid, err := signer.PublicKey().Verify(sig, message, hasher)
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				if !valid {
+					errs <- fmt.Errorf("invalid signature for %q", message)
+					return
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestSignerIncompatibleAlgorithms(t *testing.T) {
+	_, k, err := Generate(0, 1000, crypto.ECDSA_P256, crypto.SHA3_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k.HashAlgo = crypto.SHA2_384
+
+	if _, err := Signer(context.Background(), *k); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+// BenchmarkSigner measures signing throughput of a single shared signer.
+// Run with different -cpu values to see how it scales with the number of
+// workers, e.g.:
+//
+//	go test ./keys/local -run none -bench Signer -cpu 1,2,4,8
+//
+// ns/op of the parallel benchmark should drop in proportion to the cpu count.
+func BenchmarkSigner(b *testing.B) {
+	signer, _ := newTestSigner(b)
+	message := []byte("benchmark message")
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := signer.Sign(message); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := signer.Sign(message); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
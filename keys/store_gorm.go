@@ -1,6 +1,8 @@
 package keys
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -36,6 +38,23 @@ func (s *GormStore) AccountKey(address string) (Storable, error) {
 			return err
 		}
 
+		if k.ID == 0 {
+			// Keys of disabled accounts are soft deleted
+			var disabled int64
+			if err := tx.Unscoped().
+				Model(&Storable{}).
+				Where("account_address = ? AND deleted_at IS NOT NULL", address).
+				Count(&disabled).Error; err != nil {
+				return err
+			}
+
+			if disabled > 0 {
+				return fmt.Errorf("%w: %s", ErrAccountDisabled, address)
+			}
+
+			return gorm.ErrRecordNotFound
+		}
+
 		if err := tx.Model(&k).Update("updated_at", time.Now()).Error; err != nil {
 			return err
 		}
@@ -46,6 +65,20 @@ func (s *GormStore) AccountKey(address string) (Storable, error) {
 	return k, err
 }
 
+// AccountKeys returns all stored keys of an account ordered by key index.
+func (s *GormStore) AccountKeys(address string) ([]Storable, error) {
+	kk := []Storable{}
+	if err := s.db.Where(&Storable{AccountAddress: address}).Find(&kk).Error; err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(kk, func(i, j int) bool {
+		return kk[i].Index < kk[j].Index
+	})
+
+	return kk, nil
+}
+
 func (s *GormStore) ProposalKeyIndex(limitKeyCount int) (int, error) {
 	s.proposalKeyMutex.Lock()
 	defer s.proposalKeyMutex.Unlock()
@@ -83,3 +116,24 @@ func (s *GormStore) InsertProposalKey(p ProposalKey) error {
 func (s *GormStore) DeleteAllProposalKeys() error {
 	return s.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&ProposalKey{}).Error
 }
+
+// AdminKey returns the latest admin key with the given status.
+func (s *GormStore) AdminKey(status AdminKeyStatus) (k AdminKey, err error) {
+	err = s.db.Where(&AdminKey{Status: status}).Order("id desc").First(&k).Error
+	return
+}
+
+func (s *GormStore) InsertAdminKey(k *AdminKey) error {
+	return s.db.Create(k).Error
+}
+
+func (s *GormStore) UpdateAdminKey(k *AdminKey) error {
+	return s.db.Save(k).Error
+}
+
+// RetireAdminKeys marks all active admin keys, except the given one, retired.
+func (s *GormStore) RetireAdminKeys(exceptID int) error {
+	return s.db.Model(&AdminKey{}).
+		Where("status = ? AND id <> ?", AdminKeyStatusActive, exceptID).
+		Update("status", AdminKeyStatusRetired).Error
+}
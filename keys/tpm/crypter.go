@@ -0,0 +1,144 @@
+// Package tpm provides encryption and decryption using a host TPM 2.0 device.
+package tpm
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/flow-hydraulics/flow-wallet-api/keys/encryption"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// dataKeySize is the size of the per-message AES key that gets sealed to the TPM.
+const dataKeySize = 32
+
+// srkTemplate is the template used to derive the storage root key under the
+// owner hierarchy. The same template always yields the same key on a given
+// TPM, so nothing needs to be persisted in NV memory.
+var srkTemplate = tpm2.Public{
+	Type:       tpm2.AlgRSA,
+	NameAlg:    tpm2.AlgSHA256,
+	Attributes: tpm2.FlagFixedTPM | tpm2.FlagFixedParent | tpm2.FlagSensitiveDataOrigin | tpm2.FlagUserWithAuth | tpm2.FlagRestricted | tpm2.FlagDecrypt | tpm2.FlagNoDA,
+	RSAParameters: &tpm2.RSAParams{
+		Symmetric: &tpm2.SymScheme{
+			Alg:     tpm2.AlgAES,
+			KeyBits: 128,
+			Mode:    tpm2.AlgCFB,
+		},
+		KeyBits: 2048,
+	},
+}
+
+// sealedTemplate is the template for sealed data objects holding data keys.
+var sealedTemplate = tpm2.Public{
+	Type:       tpm2.AlgKeyedHash,
+	NameAlg:    tpm2.AlgSHA256,
+	Attributes: tpm2.FlagFixedTPM | tpm2.FlagFixedParent | tpm2.FlagUserWithAuth,
+}
+
+// TPMCrypter seals data to the host TPM.
+//
+// A random AES data key is generated for each message, sealed to the TPM
+// storage root key and stored alongside the AES encrypted message. The data
+// key can only be unsealed on the same TPM, which means the encrypted keys
+// are bound to the host they were created on.
+type TPMCrypter struct {
+	devicePath string
+	mu         sync.Mutex
+}
+
+// NewTPMCrypter creates a new TPMCrypter using the TPM device at the
+// given path (e.g. /dev/tpmrm0). An empty path uses the default device.
+func NewTPMCrypter(key []byte) *TPMCrypter {
+	return &TPMCrypter{devicePath: string(key)}
+}
+
+// Encrypt encrypts the given data with a fresh data key sealed to the TPM.
+func (c *TPMCrypter) Encrypt(message []byte) (encrypted []byte, err error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return encrypted, err
+	}
+
+	private, public, err := c.seal(dataKey)
+	if err != nil {
+		return encrypted, fmt.Errorf("failed to seal data key: %w", err)
+	}
+
+	ciphertext, err := encryption.NewAESCrypter(dataKey).Encrypt(message)
+	if err != nil {
+		return encrypted, err
+	}
+
+	return tpmutil.Pack(tpmutil.U16Bytes(public), tpmutil.U16Bytes(private), tpmutil.RawBytes(ciphertext))
+}
+
+// Decrypt unseals the data key from the TPM and decrypts the given data.
+func (c *TPMCrypter) Decrypt(encrypted []byte) (message []byte, err error) {
+	var public, private tpmutil.U16Bytes
+
+	read, err := tpmutil.Unpack(encrypted, &public, &private)
+	if err != nil {
+		return message, fmt.Errorf("failed to parse sealed data key: %w", err)
+	}
+
+	dataKey, err := c.unseal(private, public)
+	if err != nil {
+		return message, fmt.Errorf("failed to unseal data key: %w", err)
+	}
+
+	return encryption.NewAESCrypter(dataKey).Decrypt(encrypted[read:])
+}
+
+func (c *TPMCrypter) seal(data []byte) (private, public []byte, err error) {
+	err = c.withSRK(func(rw io.ReadWriter, srk tpmutil.Handle) error {
+		private, public, _, _, _, err = tpm2.CreateKeyWithSensitive(rw, srk, tpm2.PCRSelection{}, "", "", sealedTemplate, data)
+		return err
+	})
+
+	return private, public, err
+}
+
+func (c *TPMCrypter) unseal(private, public []byte) (data []byte, err error) {
+	err = c.withSRK(func(rw io.ReadWriter, srk tpmutil.Handle) error {
+		handle, _, err := tpm2.Load(rw, srk, "", public, private)
+		if err != nil {
+			return err
+		}
+		defer tpm2.FlushContext(rw, handle) // nolint
+
+		data, err = tpm2.Unseal(rw, handle, "")
+		return err
+	})
+
+	return data, err
+}
+
+// withSRK opens the TPM device, creates the storage root key and
+// calls fn with it. Access to the device is serialized.
+func (c *TPMCrypter) withSRK(fn func(rw io.ReadWriter, srk tpmutil.Handle) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var paths []string
+	if c.devicePath != "" {
+		paths = append(paths, c.devicePath)
+	}
+
+	rw, err := tpm2.OpenTPM(paths...)
+	if err != nil {
+		return fmt.Errorf("failed to open tpm: %w", err)
+	}
+	defer rw.Close()
+
+	srk, _, err := tpm2.CreatePrimary(rw, tpm2.HandleOwner, tpm2.PCRSelection{}, "", "", srkTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to create storage root key: %w", err)
+	}
+	defer tpm2.FlushContext(rw, srk) // nolint
+
+	return fn(rw, srk)
+}
@@ -10,18 +10,25 @@ import (
 	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/audit"
 	"github.com/flow-hydraulics/flow-wallet-api/chain_events"
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
 	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/handlers"
+	"github.com/flow-hydraulics/flow-wallet-api/health"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/keys/basic"
 	"github.com/flow-hydraulics/flow-wallet-api/ops"
+	"github.com/flow-hydraulics/flow-wallet-api/schedules"
 	"github.com/flow-hydraulics/flow-wallet-api/system"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/flow-hydraulics/flow-wallet-api/tokens"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions/results"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
 	"github.com/gomodule/redigo/redis"
 	"github.com/gorilla/mux"
 	access "github.com/onflow/flow-go-sdk/access/grpc"
@@ -40,13 +47,17 @@ var (
 
 func main() {
 	var (
-		printVersion bool
-		envFilePath  string // LEGACY: now used to check if user still is using envFilePath
+		printVersion   bool
+		envFilePath    string // LEGACY: now used to check if user still is using envFilePath
+		importFilePath string
+		importTenantID string
 	)
 
 	// If we should just print the version number and exit
 	flag.BoolVar(&printVersion, "version", false, "if true, print version and exit")
 	flag.StringVar(&envFilePath, "envfile", "", "deprecated")
+	flag.StringVar(&importFilePath, "import-accounts", "", "if set, import accounts from the given encrypted file and exit")
+	flag.StringVar(&importTenantID, "import-tenant", "", "tenant of the imported accounts")
 	flag.Parse()
 
 	if envFilePath != "" {
@@ -63,6 +74,14 @@ func main() {
 		panic(err)
 	}
 
+	if importFilePath != "" {
+		if err := runImport(cfg, importFilePath, importTenantID); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	runServer(cfg)
 
 	os.Exit(0)
@@ -73,6 +92,10 @@ func runServer(cfg *configs.Config) {
 
 	log.Info("Starting server")
 
+	if cfg.TransactionGasLimit > cfg.MaxTransactionGasLimit {
+		log.Fatalf("default transaction gas limit %d exceeds the maximum of %d", cfg.TransactionGasLimit, cfg.MaxTransactionGasLimit)
+	}
+
 	// Flow client
 	// TODO: WithInsecure()?
 	fc, err := access.NewClient(
@@ -90,6 +113,13 @@ func runServer(cfg *configs.Config) {
 		log.Info("Closed Flow Client")
 	}()
 
+	// Brief access node hiccups should not fail transactions
+	flowClient := flow_helpers.NewRetryClient(
+		fc,
+		flow_helpers.WithMaxRetries(cfg.AccessAPIMaxRetries),
+		flow_helpers.WithRetryBackoff(cfg.AccessAPIRetryMinBackoff, cfg.AccessAPIRetryMaxBackoff),
+	)
+
 	// Database
 	db, err := gorm.New(cfg)
 	if err != nil {
@@ -97,6 +127,18 @@ func runServer(cfg *configs.Config) {
 	}
 	defer gorm.Close(db)
 
+	// Optional separate database for key material
+	keysDB, err := gorm.NewKeys(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if keysDB != nil {
+		defer gorm.Close(keysDB)
+		log.Info("Using a separate database for keys")
+	} else {
+		keysDB = db
+	}
+
 	systemService := system.NewService(
 		system.NewGormStore(db),
 		system.WithPauseDuration(cfg.PauseDuration),
@@ -110,6 +152,7 @@ func runServer(cfg *configs.Config) {
 		jobs.WithJobStatusWebhook(cfg.JobStatusWebhookUrl, cfg.JobStatusWebhookTimeout),
 		jobs.WithSystemService(systemService),
 		jobs.WithMaxJobErrorCount(cfg.MaxJobErrorCount),
+		jobs.WithRetryBackoff(cfg.JobRetryMinBackoff, cfg.JobRetryMaxBackoff),
 		jobs.WithDbJobPollInterval(cfg.DBJobPollInterval),
 		jobs.WithAcceptedGracePeriod(cfg.AcceptedGracePeriod),
 		jobs.WithReSchedulableGracePeriod(cfg.ReSchedulableGracePeriod),
@@ -123,18 +166,25 @@ func runServer(cfg *configs.Config) {
 	txRatelimiter := ratelimit.New(cfg.TransactionMaxSendRate, ratelimit.WithoutSlack)
 
 	// Key manager
-	km := basic.NewKeyManager(cfg, keys.NewGormStore(db), fc)
+	km := basic.NewKeyManager(cfg, keys.NewGormStore(keysDB), flowClient)
 
 	// Services
 	templateService, err := templates.NewService(cfg, templates.NewGormStore(db))
 	if err != nil {
 		log.Fatal(err)
 	}
-	jobsService := jobs.NewService(jobs.NewGormStore(db))
-	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp, transactions.WithTxRatelimiter(txRatelimiter))
-	accountService := accounts.NewService(cfg, accounts.NewGormStore(db), km, fc, wp, transactionService, templateService, accounts.WithTxRatelimiter(txRatelimiter))
-	tokenService := tokens.NewService(cfg, tokens.NewGormStore(db), km, fc, wp, transactionService, templateService, accountService)
+	jobsService := jobs.NewService(jobs.NewGormStore(db), wp)
+	auditService := audit.NewService(audit.NewGormStore(db))
+	accountStore := accounts.NewGormStoreWithKeysDB(db, keysDB)
+	webhookService := webhooks.NewService(cfg, wp)
+	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, flowClient, wp, transactions.WithTxRatelimiter(txRatelimiter), transactions.WithFreezeChecker(accountStore), transactions.WithAccountTenants(accountStore), transactions.WithWebhooks(webhookService))
+	accountService := accounts.NewService(cfg, accountStore, km, flowClient, wp, transactionService, templateService, accounts.WithTxRatelimiter(txRatelimiter), accounts.WithWebhooks(webhookService))
+	if err := tokens.ValidateWithdrawalRules(cfg); err != nil {
+		log.Fatal(err)
+	}
+	tokenService := tokens.NewService(cfg, tokens.NewGormStore(db), km, flowClient, wp, transactionService, templateService, accountService, tokens.WithWebhooks(webhookService))
 	opsService := ops.NewService(cfg, ops.NewGormStore(db), templateService, transactionService, tokenService)
+	scheduleService := schedules.NewService(cfg, schedules.NewGormStore(db), transactionService)
 
 	// Register a handler for account added events
 	accounts.AccountAdded.Register(&tokens.AccountAddedHandler{
@@ -150,25 +200,216 @@ func runServer(cfg *configs.Config) {
 	wp.Start()
 	log.Info("Started workerpool")
 
+	// Admin key rotation
+	if cfg.AdminKeyRotationInterval > 0 {
+		// Due rotations are checked at least every minute
+		checkInterval := cfg.AdminKeyRotationInterval
+		if checkInterval > time.Minute {
+			checkInterval = time.Minute
+		}
+
+		task := system.StartPeriodicTask(log.WithFields(log.Fields{"package": "keys"}), "admin key rotation", checkInterval, systemService, km.RotateAdminKeyIfDue)
+
+		log.
+			WithFields(log.Fields{"interval": cfg.AdminKeyRotationInterval, "dryRun": cfg.AdminKeyRotationDryRun}).
+			Info("Started admin key rotation scheduler")
+
+		defer func() {
+			task.Stop()
+			log.Info("Stopped admin key rotation scheduler")
+		}()
+	}
+
+	// Account storage monitoring
+	if cfg.StorageCheckInterval > 0 {
+		task := system.StartPeriodicTask(log.WithFields(log.Fields{"package": "accounts"}), "account storage check", cfg.StorageCheckInterval, systemService, accountService.CheckAllStorage)
+
+		log.
+			WithFields(log.Fields{"interval": cfg.StorageCheckInterval}).
+			Info("Started account storage monitor")
+
+		defer func() {
+			task.Stop()
+			log.Info("Stopped account storage monitor")
+		}()
+	}
+
+	// Transaction result fetching
+	if cfg.TransactionResultFetchInterval > 0 {
+		fetcher := results.NewFetcher(
+			transactionService,
+			cfg.TransactionResultFetchInterval,
+			results.WithSystemService(systemService),
+		)
+
+		defer func() {
+			fetcher.Stop()
+			log.Info("Stopped transaction result fetcher")
+		}()
+
+		fetcher.Start()
+	}
+
+	// Scheduled transactions, created with the raw transaction endpoints
+	if cfg.ScheduleCheckInterval > 0 && !cfg.DisableRawTransactions {
+		runner := schedules.NewRunner(
+			scheduleService,
+			cfg.ScheduleCheckInterval,
+			schedules.WithSystemService(systemService),
+		)
+
+		defer func() {
+			runner.Stop()
+			log.Info("Stopped transaction schedule runner")
+		}()
+
+		runner.Start()
+	}
+
+	// Sweeping of token balances to the treasury account
+	if cfg.SweepInterval > 0 && !cfg.DisableFungibleTokens {
+		thresholds, err := tokens.ParseTokenAmounts(cfg.SweepThresholds)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		treasury := cfg.SweepTreasuryAddress
+		if treasury == "" {
+			treasury = cfg.AdminAddress
+		}
+
+		entry := log.WithFields(log.Fields{"package": "tokens"})
+		task := system.StartPeriodicTask(entry, "sweep", cfg.SweepInterval, systemService, func(ctx context.Context) error {
+			for tokenName, threshold := range thresholds {
+				if err := tokenService.Sweep(ctx, tokenName, treasury, threshold); err != nil {
+					entry.
+						WithFields(log.Fields{"error": err, "token": tokenName}).
+						Warn("Sweep failed")
+				}
+			}
+			return nil
+		})
+
+		log.
+			WithFields(log.Fields{"interval": cfg.SweepInterval, "treasury": treasury}).
+			Info("Started sweeper")
+
+		defer func() {
+			task.Stop()
+			log.Info("Stopped sweeper")
+		}()
+	}
+
+	// On-chain settlement of ledger transfers
+	if cfg.LedgerSettlementInterval > 0 && !cfg.DisableFungibleTokens {
+		task := system.StartPeriodicTask(log.WithFields(log.Fields{"package": "tokens"}), "ledger settlement", cfg.LedgerSettlementInterval, systemService, tokenService.SettleAllLedgers)
+
+		log.
+			WithFields(log.Fields{"interval": cfg.LedgerSettlementInterval}).
+			Info("Started ledger settler")
+
+		defer func() {
+			task.Stop()
+			log.Info("Stopped ledger settler")
+		}()
+	}
+
+	// Rejection of withdrawals which were not approved in time
+	if len(cfg.WithdrawalApprovalThresholds) > 0 && cfg.WithdrawalApprovalTimeout > 0 && !cfg.DisableFungibleTokens {
+		task := system.StartPeriodicTask(log.WithFields(log.Fields{"package": "tokens"}), "approval expiry", cfg.WithdrawalApprovalExpiryInterval, systemService, tokenService.ExpireApprovals)
+
+		log.
+			WithFields(log.Fields{"interval": cfg.WithdrawalApprovalExpiryInterval}).
+			Info("Started approval expirer")
+
+		defer func() {
+			task.Stop()
+			log.Info("Stopped approval expirer")
+		}()
+	}
+
+	// Daily balance snapshots
+	if cfg.BalanceSnapshotInterval > 0 && !cfg.DisableFungibleTokens {
+		task := system.StartPeriodicTask(log.WithFields(log.Fields{"package": "tokens"}), "balance snapshots", cfg.BalanceSnapshotInterval, systemService, tokenService.SnapshotBalances)
+
+		log.
+			WithFields(log.Fields{"interval": cfg.BalanceSnapshotInterval}).
+			Info("Started balance snapshotter")
+
+		defer func() {
+			task.Stop()
+			log.Info("Stopped balance snapshotter")
+		}()
+	}
+
 	// HTTP handling
 	systemHandler := handlers.NewSystem(systemService)
 	templateHandler := handlers.NewTemplates(templateService)
+	transactionTemplateHandler := handlers.NewTransactionTemplates(templateService, transactionService)
 	jobsHandler := handlers.NewJobs(jobsService)
 	accountHandler := handlers.NewAccounts(accountService)
 	transactionHandler := handlers.NewTransactions(transactionService)
 	tokenHandler := handlers.NewTokens(tokenService)
 	opsHandler := handlers.NewOps(opsService)
+	scheduleHandler := handlers.NewSchedules(scheduleService)
+	auditHandler := handlers.NewAudit(auditService)
 
 	r := mux.NewRouter()
 
+	tenantAPIKeys, err := tenants.ParseAPIKeys(cfg.TenantAPIKeys)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	adminAPIKeys, err := tenants.ParseAdminAPIKeys(cfg.AdminAPIKeys)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for apiKey, adminID := range adminAPIKeys {
+		if _, exists := tenantAPIKeys[apiKey]; exists {
+			log.Fatalf("API key of admin %s is also a tenant API key", adminID)
+		}
+	}
+
 	// Catch the api version
 	rv := r.PathPrefix("/{apiVersion}").Subrouter()
 
+	// Aliases are resolved to addresses before anything else is done with them
+	rv.Use(handlers.ResolveAccountAlias(accountService))
+
+	// Accounts of other tenants are not found
+	if len(tenantAPIKeys) > 0 {
+		rv.Use(handlers.TenantAccountScope(accountService))
+	}
+
+	// Mutating requests are recorded with their caller
+	rv.Use(handlers.AuditLog(auditService))
+
+	// Tenants can not manage the service, recorded in the audit log
+	if len(tenantAPIKeys) > 0 {
+		rv.Use(handlers.RequireAdmin)
+	}
+
 	// Debug
 	rv.Handle("/debug", handlers.Debug("https://github.com/flow-hydraulics/flow-wallet-api", sha1ver, buildTime)).Methods(http.MethodGet)
 
 	// Health
-	rv.HandleFunc("/health/ready", handlers.HandleHealthReady).Methods(http.MethodGet)
+	if cfg.HealthCheckInterval > 0 {
+		checks := make([]health.Check, 0)
+		for _, c := range km.HealthChecks() {
+			checks = append(checks, health.Check{Name: c.Name, Check: c.Check})
+		}
+
+		checker := health.NewChecker(cfg.HealthCheckInterval, cfg.HealthCheckTimeout, checks...).Start()
+		defer checker.Stop()
+
+		rv.Handle("/health/ready", handlers.Readiness(func() (bool, interface{}) {
+			return checker.Ready()
+		})).Methods(http.MethodGet)
+	} else {
+		rv.HandleFunc("/health/ready", handlers.HandleHealthReady).Methods(http.MethodGet)
+	}
 	rv.Handle("/health/liveness", handlers.Liveness(func() (interface{}, error) {
 		return wp.Status()
 	})).Methods(http.MethodGet)
@@ -178,36 +419,125 @@ func runServer(cfg *configs.Config) {
 	rv.Handle("/system/settings", systemHandler.SetSettings()).Methods(http.MethodPost)
 
 	rv.Handle("/system/sync-account-key-count", accountHandler.SyncAccountKeyCount()).Methods(http.MethodPost)
+	rv.Handle("/system/accounts/{address}/enable", accountHandler.Enable()).Methods(http.MethodPost)
+	rv.Handle("/system/accounts/{address}/freeze", accountHandler.Freeze()).Methods(http.MethodPost)
+	rv.Handle("/system/accounts/{address}/unfreeze", accountHandler.Unfreeze()).Methods(http.MethodPost)
+	rv.Handle("/system/accounts/{address}/recoveries/{recoveryId}/approve", accountHandler.ApproveKeyRecovery()).Methods(http.MethodPost)
+
+	// Audit log
+	rv.Handle("/system/audit", auditHandler.List()).Methods(http.MethodGet)              // list
+	rv.Handle("/system/audit/{entryId}", auditHandler.Details()).Methods(http.MethodGet) // details
+
+	// Deposits feed
+	rv.Handle("/system/deposits", tokenHandler.ListAllDeposits()).Methods(http.MethodGet)                       // list
+	rv.Handle("/system/deposit-notifications", tokenHandler.ListDepositNotifications()).Methods(http.MethodGet) // list
+
+	// Script allowlist
+	rv.Handle("/system/allowlist/scripts", transactionHandler.ListAllowedCode(transactions.AllowedScript)).Methods(http.MethodGet)             // list
+	rv.Handle("/system/allowlist/scripts", transactionHandler.AllowCode(transactions.AllowedScript)).Methods(http.MethodPost)                  // add
+	rv.Handle("/system/allowlist/scripts/{hash}", transactionHandler.RemoveAllowedCode(transactions.AllowedScript)).Methods(http.MethodDelete) // remove
+
+	// Transaction allowlist
+	rv.Handle("/system/allowlist/transactions", transactionHandler.ListAllowedCode(transactions.AllowedTransaction)).Methods(http.MethodGet)             // list
+	rv.Handle("/system/allowlist/transactions", transactionHandler.AllowCode(transactions.AllowedTransaction)).Methods(http.MethodPost)                  // add
+	rv.Handle("/system/allowlist/transactions/{hash}", transactionHandler.RemoveAllowedCode(transactions.AllowedTransaction)).Methods(http.MethodDelete) // remove
+
+	// Withdrawal destination allowlist
+	rv.Handle("/system/allowlist/withdrawals/{tokenName}", tokenHandler.ListWithdrawalDestinations()).Methods(http.MethodGet)               // list
+	rv.Handle("/system/allowlist/withdrawals/{tokenName}", tokenHandler.AllowWithdrawalDestination()).Methods(http.MethodPost)              // add
+	rv.Handle("/system/allowlist/withdrawals/{tokenName}/{address}", tokenHandler.RemoveWithdrawalDestination()).Methods(http.MethodDelete) // remove
+
+	// Withdrawal approvals
+	rv.Handle("/system/withdrawals/{withdrawalId}/approve", tokenHandler.ApproveWithdrawal()).Methods(http.MethodPost) // approve
+	rv.Handle("/system/withdrawals/{withdrawalId}/reject", tokenHandler.RejectWithdrawal()).Methods(http.MethodPost)   // reject
 
 	// Jobs
-	rv.Handle("/jobs", jobsHandler.List()).Methods(http.MethodGet)            // list
-	rv.Handle("/jobs/{jobId}", jobsHandler.Details()).Methods(http.MethodGet) // details
+	rv.Handle("/jobs", jobsHandler.List()).Methods(http.MethodGet)                     // list
+	rv.Handle("/jobs/{jobId}", jobsHandler.Details()).Methods(http.MethodGet)          // details
+	rv.Handle("/jobs/{jobId}/requeue", jobsHandler.Requeue()).Methods(http.MethodPost) // requeue
 
 	// Token templates
 	rv.Handle("/tokens", templateHandler.ListTokens(templates.NotSpecified)).Methods(http.MethodGet) // list
 	rv.Handle("/tokens", templateHandler.AddToken()).Methods(http.MethodPost)                        // create
 	rv.Handle("/tokens/{id_or_name}", templateHandler.GetToken()).Methods(http.MethodGet)            // details
+	rv.Handle("/tokens/{id}", templateHandler.UpdateToken()).Methods(http.MethodPut)                 // update
 	rv.Handle("/tokens/{id}", templateHandler.RemoveToken()).Methods(http.MethodDelete)              // delete
 
 	// List enabled tokens by type
 	rv.Handle("/fungible-tokens", templateHandler.ListTokens(templates.FT)).Methods(http.MethodGet)      // list
 	rv.Handle("/non-fungible-tokens", templateHandler.ListTokens(templates.NFT)).Methods(http.MethodGet) // list
 
+	// Transaction templates
+	rv.Handle("/templates", transactionTemplateHandler.List()).Methods(http.MethodGet)             // list
+	rv.Handle("/templates", transactionTemplateHandler.Add()).Methods(http.MethodPost)             // create
+	rv.Handle("/templates/{name}", transactionTemplateHandler.Details()).Methods(http.MethodGet)   // details
+	rv.Handle("/templates/{name}", transactionTemplateHandler.Remove()).Methods(http.MethodDelete) // delete
+
 	// Transactions
-	rv.Handle("/transactions", transactionHandler.List()).Methods(http.MethodGet)                    // list
-	rv.Handle("/transactions/{transactionId}", transactionHandler.Details()).Methods(http.MethodGet) // details
+	rv.Handle("/transactions", transactionHandler.List()).Methods(http.MethodGet)                          // list
+	rv.Handle("/transactions/fees", transactionHandler.Fees()).Methods(http.MethodGet)                     // fee report
+	rv.Handle("/transactions/{transactionId}", transactionHandler.Details()).Methods(http.MethodGet)       // details
+	rv.Handle("/transactions/{transactionId}", transactionHandler.Cancel()).Methods(http.MethodDelete)     // cancel
+	rv.Handle("/transactions/{transactionId}/events", transactionHandler.Events()).Methods(http.MethodGet) // events
 
 	// Account
-	rv.Handle("/accounts", accountHandler.List()).Methods(http.MethodGet)              // list
-	rv.Handle("/accounts", accountHandler.Create()).Methods(http.MethodPost)           // create
-	rv.Handle("/accounts/{address}", accountHandler.Details()).Methods(http.MethodGet) // details
+	rv.Handle("/accounts", accountHandler.List()).Methods(http.MethodGet)                          // list
+	rv.Handle("/accounts", accountHandler.Create()).Methods(http.MethodPost)                       // create
+	rv.Handle("/accounts/batch", accountHandler.CreateBatch()).Methods(http.MethodPost)            // create batch
+	rv.Handle("/accounts/watch", accountHandler.AddNonCustodialAccount()).Methods(http.MethodPost) // add watch-only
+	rv.Handle("/accounts/export", accountHandler.Export()).Methods(http.MethodGet)                 // export
+	rv.Handle("/accounts/details", accountHandler.BulkDetails()).Methods(http.MethodPost)          // bulk details
+	rv.Handle("/accounts/{address}", accountHandler.Details()).Methods(http.MethodGet)             // details
+	rv.Handle("/accounts/{address}", accountHandler.Update()).Methods(http.MethodPatch)            // update
+	rv.Handle("/accounts/{address}", accountHandler.Disable()).Methods(http.MethodDelete)          // disable
+	rv.Handle("/accounts/{address}/sync", accountHandler.Sync()).Methods(http.MethodPost)          // sync with chain
+
+	// Account keys
+	rv.Handle("/accounts/{address}/keys/onchain", accountHandler.OnChainKeys()).Methods(http.MethodGet)  // list on-chain
+	rv.Handle("/accounts/{address}/keys", accountHandler.AddKey()).Methods(http.MethodPost)              // add
+	rv.Handle("/accounts/{address}/keys/{index}", accountHandler.RevokeKey()).Methods(http.MethodDelete) // revoke
+
+	// Account key recoveries
+	rv.Handle("/accounts/{address}/recoveries", accountHandler.ListKeyRecoveries()).Methods(http.MethodGet)                        // list
+	rv.Handle("/accounts/{address}/recoveries", accountHandler.RequestKeyRecovery()).Methods(http.MethodPost)                      // request
+	rv.Handle("/accounts/{address}/recoveries/{recoveryId}/execute", accountHandler.ExecuteKeyRecovery()).Methods(http.MethodPost) // execute
+	rv.Handle("/accounts/{address}/recoveries/{recoveryId}/cancel", accountHandler.CancelKeyRecovery()).Methods(http.MethodPost)   // cancel
+
+	// FCL account proofs
+	rv.Handle("/accounts/{address}/sign-account-proof", accountHandler.SignAccountProof()).Methods(http.MethodPost) // sign
+
+	// User message signatures
+	rv.Handle("/accounts/{address}/sign-message", accountHandler.SignMessage()).Methods(http.MethodPost) // sign
+
+	// Account contracts
+	rv.Handle("/accounts/{address}/contracts/{name}", accountHandler.DeployContract()).Methods(http.MethodPut)    // deploy or update
+	rv.Handle("/accounts/{address}/contracts/{name}", accountHandler.RemoveContract()).Methods(http.MethodDelete) // remove
+
+	// Account transactions from templates
+	rv.Handle("/accounts/{address}/templates/{name}", transactionTemplateHandler.Invoke()).Methods(http.MethodPost) // invoke
 
 	// Account raw transactions
 	if !cfg.DisableRawTransactions {
-		rv.Handle("/accounts/{address}/sign", transactionHandler.Sign()).Methods(http.MethodPost)                           // sign
-		rv.Handle("/accounts/{address}/transactions", transactionHandler.List()).Methods(http.MethodGet)                    // list
-		rv.Handle("/accounts/{address}/transactions", transactionHandler.Create()).Methods(http.MethodPost)                 // create
-		rv.Handle("/accounts/{address}/transactions/{transactionId}", transactionHandler.Details()).Methods(http.MethodGet) // details
+		rv.Handle("/accounts/{address}/sign", transactionHandler.Sign()).Methods(http.MethodPost)                                 // sign
+		rv.Handle("/accounts/{address}/transactions", transactionHandler.List()).Methods(http.MethodGet)                          // list
+		rv.Handle("/accounts/{address}/transactions", transactionHandler.Create()).Methods(http.MethodPost)                       // create
+		rv.Handle("/accounts/{address}/transactions/batch", transactionHandler.CreateBatch()).Methods(http.MethodPost)            // create batch
+		rv.Handle("/accounts/{address}/transactions/dry-run", transactionHandler.DryRun()).Methods(http.MethodPost)               // dry run
+		rv.Handle("/accounts/{address}/transactions/{transactionId}", transactionHandler.Details()).Methods(http.MethodGet)       // details
+		rv.Handle("/accounts/{address}/transactions/{transactionId}", transactionHandler.Cancel()).Methods(http.MethodDelete)     // cancel
+		rv.Handle("/accounts/{address}/transactions/{transactionId}/events", transactionHandler.Events()).Methods(http.MethodGet) // events
+		rv.Handle("/transactions/submit-signed", transactionHandler.SubmitSigned()).Methods(http.MethodPost)                      // submit signed
+		rv.Handle("/transactions/sponsor", transactionHandler.Sponsor()).Methods(http.MethodPost)                                 // sponsor
+		rv.Handle("/transactions/build", transactionHandler.Build()).Methods(http.MethodPost)                                     // build
+		rv.Handle("/transactions/{transactionId}/send", transactionHandler.Send()).Methods(http.MethodPost)                       // send
+
+		// Transaction schedules
+		rv.Handle("/accounts/{address}/schedules", scheduleHandler.List()).Methods(http.MethodGet)                        // list
+		rv.Handle("/accounts/{address}/schedules", scheduleHandler.Add()).Methods(http.MethodPost)                        // add
+		rv.Handle("/accounts/{address}/schedules/{scheduleId}", scheduleHandler.Details()).Methods(http.MethodGet)        // details
+		rv.Handle("/accounts/{address}/schedules/{scheduleId}", scheduleHandler.Remove()).Methods(http.MethodDelete)      // delete
+		rv.Handle("/accounts/{address}/schedules/{scheduleId}/pause", scheduleHandler.Pause()).Methods(http.MethodPost)   // pause
+		rv.Handle("/accounts/{address}/schedules/{scheduleId}/resume", scheduleHandler.Resume()).Methods(http.MethodPost) // resume
 	} else {
 		log.Info("raw transactions disabled")
 	}
@@ -226,9 +556,20 @@ func runServer(cfg *configs.Config) {
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}", tokenHandler.Setup()).Methods(http.MethodPost)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals", tokenHandler.ListWithdrawals()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals", tokenHandler.CreateWithdrawal()).Methods(http.MethodPost)
-		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals/{transactionId}", tokenHandler.GetWithdrawal()).Methods(http.MethodGet)
+		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals/batch", tokenHandler.CreateBatchWithdrawal()).Methods(http.MethodPost)
+		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/withdrawals/{withdrawalId}", tokenHandler.GetWithdrawal()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/deposits", tokenHandler.ListDeposits()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/deposits/{transactionId}", tokenHandler.GetDeposit()).Methods(http.MethodGet)
+		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/ledger", tokenHandler.LedgerBalance()).Methods(http.MethodGet)
+		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/ledger/transfers", tokenHandler.ListLedgerTransfers()).Methods(http.MethodGet)
+		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/ledger/transfers", tokenHandler.CreateLedgerTransfer()).Methods(http.MethodPost)
+		rv.Handle("/accounts/{address}/fungible-tokens/{tokenName}/balance-snapshots", tokenHandler.ListBalanceSnapshots()).Methods(http.MethodGet)
+		rv.Handle("/fungible-tokens/{tokenName}", tokenHandler.TokenInfo()).Methods(http.MethodGet)
+		rv.Handle("/fungible-tokens/{tokenName}/holdings", tokenHandler.Holdings()).Methods(http.MethodGet)
+		rv.Handle("/fungible-tokens/{tokenName}/transfer-fee-estimate", tokenHandler.TransferFeeEstimate()).Methods(http.MethodGet)
+		rv.Handle("/system/fungible-tokens/{tokenName}/withdrawals", tokenHandler.ListAllWithdrawals()).Methods(http.MethodGet)
+		rv.Handle("/system/fungible-tokens/{tokenName}/ledger/settle", tokenHandler.SettleLedger()).Methods(http.MethodPost)
+		rv.Handle("/system/fungible-tokens/{tokenName}/balance-snapshots", tokenHandler.ListAllBalanceSnapshots()).Methods(http.MethodGet)
 	} else {
 		log.Info("fungible tokens disabled")
 	}
@@ -240,9 +581,14 @@ func runServer(cfg *configs.Config) {
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}", tokenHandler.Setup()).Methods(http.MethodPost)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals", tokenHandler.ListWithdrawals()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals", tokenHandler.CreateWithdrawal()).Methods(http.MethodPost)
-		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals/{transactionId}", tokenHandler.GetWithdrawal()).Methods(http.MethodGet)
+		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals/batch", tokenHandler.CreateBatchWithdrawal()).Methods(http.MethodPost)
+		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals/{withdrawalId}", tokenHandler.GetWithdrawal()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/deposits", tokenHandler.ListDeposits()).Methods(http.MethodGet)
 		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/deposits/{transactionId}", tokenHandler.GetDeposit()).Methods(http.MethodGet)
+		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/{nftId:[0-9]+}", tokenHandler.NFTMetadata()).Methods(http.MethodGet)
+		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/listings", tokenHandler.CreateListing()).Methods(http.MethodPost)
+		rv.Handle("/accounts/{address}/non-fungible-tokens/{tokenName}/listings/{listingId}", tokenHandler.RemoveListing()).Methods(http.MethodDelete)
+		rv.Handle("/non-fungible-tokens/{tokenName}/mint", tokenHandler.Mint()).Methods(http.MethodPost)
 	} else {
 		log.Info("non-fungible tokens disabled")
 	}
@@ -251,7 +597,7 @@ func runServer(cfg *configs.Config) {
 	rv.Handle("/ops/missing-fungible-token-vaults/start", opsHandler.InitMissingFungibleVaults()).Methods(http.MethodGet) // start retroactive init job
 	rv.Handle("/ops/missing-fungible-token-vaults/stats", opsHandler.GetMissingFungibleVaults()).Methods(http.MethodGet)  // get number of accounts with missing fungible token vaults
 
-	h := http.TimeoutHandler(r, cfg.ServerRequestTimeout, "request timed out")
+	h := handlers.UseTimeout(r, cfg.ServerRequestTimeout, []string{"/v1/accounts/export"}) // Exports are streamed
 	h = handlers.UseCors(h)
 	h = handlers.UseLogging(h)
 	h = handlers.UseCompress(h)
@@ -298,9 +644,23 @@ func runServer(cfg *configs.Config) {
 		h = handlers.UseIdempotency(h, handlers.IdempotencyHandlerOptions{
 			Expiry:      1 * time.Hour,
 			IgnorePaths: []string{"/v1/scripts"}, // Scripts are read-only
+			// Retried account creations and withdrawals return the original result
+			HandledPaths: []string{
+				"/v1/accounts",
+				"/v1/accounts/{address}/fungible-tokens/{tokenName}/withdrawals",
+				"/v1/accounts/{address}/fungible-tokens/{tokenName}/withdrawals/batch",
+				"/v1/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals",
+				"/v1/accounts/{address}/non-fungible-tokens/{tokenName}/withdrawals/batch",
+			},
 		}, is)
 	}
 
+	// Authenticate tenants and admins, needs to wrap the idempotency middleware
+	if len(tenantAPIKeys) > 0 || len(adminAPIKeys) > 0 {
+		h = handlers.UseTenants(h, tenantAPIKeys, adminAPIKeys, []string{"/v1/health"})
+		log.WithFields(log.Fields{"apiKeys": len(tenantAPIKeys), "adminApiKeys": len(adminAPIKeys)}).Info("Tenant scoping enabled")
+	}
+
 	// Server boilerplate
 	srv := &http.Server{
 		Handler:      h,
@@ -332,19 +692,21 @@ func runServer(cfg *configs.Config) {
 				return nil, err
 			}
 
-			token_count := len(tt)
-			event_types := make([]string, token_count)
+			event_types := make([]string, 0, len(tt))
 
 			// Listen for enabled tokens deposit events
-			for i, token := range tt {
-				event_types[i] = templates.DepositEventTypeFromToken(token)
+			for _, token := range tt {
+				if token.Disabled {
+					continue
+				}
+				event_types = append(event_types, templates.DepositEventTypeFromToken(token))
 			}
 
 			return event_types, nil
 		}
 
 		listener := chain_events.NewListener(
-			fc, store, getTypes,
+			flowClient, store, getTypes,
 			cfg.ChainListenerMaxBlocks,
 			cfg.ChainListenerInterval,
 			cfg.ChainListenerStartingHeight,
@@ -367,6 +729,18 @@ func runServer(cfg *configs.Config) {
 		listener.Start()
 
 		log.Info("Started chain events listener")
+
+		// Confirmations of the detected deposits and results of sent withdrawals
+		task := system.StartPeriodicTask(log.WithFields(log.Fields{"package": "tokens"}), "transfer tracking", cfg.ChainListenerInterval, systemService, tokenService.TrackTransfers)
+
+		log.
+			WithFields(log.Fields{"interval": cfg.ChainListenerInterval}).
+			Info("Started transfer tracker")
+
+		defer func() {
+			task.Stop()
+			log.Info("Stopped transfer tracker")
+		}()
 	}
 
 	// Trap interupt or sigterm and gracefully shutdown the server
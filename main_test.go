@@ -803,7 +803,7 @@ func TestTokenServices(t *testing.T) {
 		fatal(t, err)
 
 		// Fund the account from service account
-		_, _, err = svc.CreateWithdrawal(
+		_, err = svc.CreateWithdrawal(
 			context.Background(),
 			true,
 			cfg.AdminAddress,
@@ -816,7 +816,7 @@ func TestTokenServices(t *testing.T) {
 
 		fatal(t, err)
 
-		_, transfer, err := svc.CreateWithdrawal(
+		transfer, err := svc.CreateWithdrawal(
 			context.Background(),
 			true,
 			account.Address,
@@ -839,7 +839,7 @@ func TestTokenServices(t *testing.T) {
 		_, account, err := accountSvc.Create(context.Background(), true)
 		fatal(t, err)
 
-		_, _, err = svc.CreateWithdrawal(
+		_, err = svc.CreateWithdrawal(
 			context.Background(),
 			true,
 			account.Address,
@@ -887,7 +887,7 @@ func TestTokenServices(t *testing.T) {
 		}
 
 		// Create a withdrawal
-		_, _, err = svc.CreateWithdrawal(
+		_, err = svc.CreateWithdrawal(
 			ctx,
 			true,
 			cfg.AdminAddress,
@@ -937,7 +937,7 @@ func TestTokenServices(t *testing.T) {
 		fatal(t, err)
 
 		// Create a withdrawal
-		_, _, err = svc.CreateWithdrawal(
+		_, err = svc.CreateWithdrawal(
 			ctx,
 			true,
 			cfg.AdminAddress,
@@ -967,8 +967,9 @@ func TestTokenHandlers(t *testing.T) {
 	router.Handle("/{address}/fungible-tokens/{tokenName}", handler.Setup()).Methods(http.MethodPost)
 	router.Handle("/{address}/fungible-tokens/{tokenName}", handler.Details()).Methods(http.MethodGet)
 	router.Handle("/{address}/fungible-tokens/{tokenName}/withdrawals", handler.CreateWithdrawal()).Methods(http.MethodPost)
+	router.Handle("/{address}/fungible-tokens/{tokenName}/withdrawals/batch", handler.CreateBatchWithdrawal()).Methods(http.MethodPost)
 	router.Handle("/{address}/fungible-tokens/{tokenName}/withdrawals", handler.ListWithdrawals()).Methods(http.MethodGet)
-	router.Handle("/{address}/fungible-tokens/{tokenName}/withdrawals/{transactionId}", handler.GetWithdrawal()).Methods(http.MethodGet)
+	router.Handle("/{address}/fungible-tokens/{tokenName}/withdrawals/{withdrawalId}", handler.GetWithdrawal()).Methods(http.MethodGet)
 	router.Handle("/{address}/fungible-tokens/{tokenName}/deposits", handler.ListDeposits()).Methods(http.MethodGet)
 	router.Handle("/{address}/fungible-tokens/{tokenName}/deposits/{transactionId}", handler.GetDeposit()).Methods(http.MethodGet)
 
@@ -977,7 +978,7 @@ func TestTokenHandlers(t *testing.T) {
 	router.Handle("/{address}/non-fungible-tokens/{tokenName}", handler.Details()).Methods(http.MethodGet)
 	router.Handle("/{address}/non-fungible-tokens/{tokenName}/withdrawals", handler.CreateWithdrawal()).Methods(http.MethodPost)
 	router.Handle("/{address}/non-fungible-tokens/{tokenName}/withdrawals", handler.ListWithdrawals()).Methods(http.MethodGet)
-	router.Handle("/{address}/non-fungible-tokens/{tokenName}/withdrawals/{transactionId}", handler.GetWithdrawal()).Methods(http.MethodGet)
+	router.Handle("/{address}/non-fungible-tokens/{tokenName}/withdrawals/{withdrawalId}", handler.GetWithdrawal()).Methods(http.MethodGet)
 	router.Handle("/{address}/non-fungible-tokens/{tokenName}/deposits", handler.ListDeposits()).Methods(http.MethodGet)
 	router.Handle("/{address}/non-fungible-tokens/{tokenName}/deposits/{transactionId}", handler.GetDeposit()).Methods(http.MethodGet)
 
@@ -1038,7 +1039,7 @@ func TestTokenHandlers(t *testing.T) {
 	_, testAccount, err := accountSvc.Create(context.Background(), true)
 	fatal(t, err)
 
-	_, testTransferFT, err := svc.CreateWithdrawal(
+	testTransferFT, err := svc.CreateWithdrawal(
 		context.Background(),
 		true,
 		cfg.AdminAddress,
@@ -1066,8 +1067,8 @@ func TestTokenHandlers(t *testing.T) {
 			method:      http.MethodPost,
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/fungible-tokens/%s", testAccounts[1].Address, flowToken.Name),
-			expected:    `vault exists`,
-			status:      http.StatusBadRequest,
+			expected:    `"vaultSetUp":true`,
+			status:      http.StatusOK,
 		},
 		{
 			name:        "Setup FUSD valid async",
@@ -1127,7 +1128,7 @@ func TestTokenHandlers(t *testing.T) {
 
 	nftIDs := aa0NftDetails.Balance.CadenceValue.(cadence.Array).Values
 
-	_, testTransferNFT, err := svc.CreateWithdrawal(
+	testTransferNFT, err := svc.CreateWithdrawal(
 		context.Background(),
 		true,
 		testAccounts[0].Address,
@@ -1195,7 +1196,7 @@ func TestTokenHandlers(t *testing.T) {
 			body:        strings.NewReader(fmt.Sprintf(`{"recipient":"%s","amount":"1.0"}`, testAccount.Address)),
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals", cfg.AdminAddress, flowToken.Name),
-			expected:    `(?m)^{"jobId":".+"}$`,
+			expected:    `(?m)^{.*"state":"requested".*"jobId":".+".*}$`,
 			status:      http.StatusCreated,
 		},
 		{
@@ -1205,9 +1206,39 @@ func TestTokenHandlers(t *testing.T) {
 			body:        strings.NewReader(fmt.Sprintf(`{"recipient":"%s","amount":"1.0"}`, testAccount.Address)),
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals", cfg.AdminAddress, flowToken.Name),
-			expected:    `(?m)^{"transactionId":".+"}$`,
+			expected:    `(?m)^{.*"transactionId":".+".*"state":"sealed".*}$`,
 			status:      http.StatusCreated,
 		},
+		{
+			name:        "create batch withdrawal valid sync",
+			sync:        true,
+			method:      http.MethodPost,
+			body:        strings.NewReader(fmt.Sprintf(`[{"recipient":"%s","amount":"1.0"},{"recipient":"%s","amount":"0.5"}]`, testAccount.Address, testAccounts[0].Address)),
+			contentType: "application/json",
+			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals/batch", cfg.AdminAddress, flowToken.Name),
+			expected:    `(?m)^\[{.*"state":"sealed".*"batchId":".+".*},{.*"state":"sealed".*"batchId":".+".*}\]$`,
+			status:      http.StatusCreated,
+		},
+		{
+			name:        "create batch withdrawal empty",
+			sync:        true,
+			method:      http.MethodPost,
+			body:        strings.NewReader(`[]`),
+			contentType: "application/json",
+			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals/batch", cfg.AdminAddress, flowToken.Name),
+			expected:    "empty batch",
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "create batch withdrawal invalid recipient",
+			sync:        true,
+			method:      http.MethodPost,
+			body:        strings.NewReader(fmt.Sprintf(`[{"recipient":"%s","amount":"1.0"},{"recipient":"","amount":"1.0"}]`, testAccount.Address)),
+			contentType: "application/json",
+			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals/batch", cfg.AdminAddress, flowToken.Name),
+			expected:    "withdrawal 1: not a valid address",
+			status:      http.StatusBadRequest,
+		},
 		{
 			name:        "create withdrawal invalid recipient",
 			sync:        true,
@@ -1225,7 +1256,17 @@ func TestTokenHandlers(t *testing.T) {
 			body:        strings.NewReader(fmt.Sprintf(`{"recipient":"%s","amount":""}`, testAccount.Address)),
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals", cfg.AdminAddress, flowToken.Name),
-			expected:    "missing decimal point",
+			expected:    "expected a decimal number",
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "create withdrawal amount too precise",
+			sync:        true,
+			method:      http.MethodPost,
+			body:        strings.NewReader(fmt.Sprintf(`{"recipient":"%s","amount":"1.000000001"}`, testAccount.Address)),
+			contentType: "application/json",
+			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals", cfg.AdminAddress, flowToken.Name),
+			expected:    "more than 8 decimal places",
 			status:      http.StatusBadRequest,
 		},
 		{
@@ -1235,7 +1276,7 @@ func TestTokenHandlers(t *testing.T) {
 			body:        strings.NewReader(fmt.Sprintf(`{"recipient":"%s","nftId":%d}`, cfg.AdminAddress, nftIDs[1].ToGoValue())),
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/non-fungible-tokens/%s/withdrawals", testAccounts[0].Address, exampleNft.Name),
-			expected:    `(?m)^{"transactionId":".+"}$`,
+			expected:    `(?m)^{.*"transactionId":".+".*"state":"sealed".*}$`,
 			status:      http.StatusCreated,
 		},
 		{
@@ -1257,7 +1298,7 @@ func TestTokenHandlers(t *testing.T) {
 			method:      http.MethodGet,
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals", cfg.AdminAddress, flowToken.Name),
-			expected:    `(?m)^\[{"transactionId":".+".*}\]$`,
+			expected:    `(?m)^\[{.*"transactionId":".+".*}\]$`,
 			status:      http.StatusOK,
 		},
 		{
@@ -1265,7 +1306,7 @@ func TestTokenHandlers(t *testing.T) {
 			method:      http.MethodGet,
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/non-fungible-tokens/%s/withdrawals", testAccounts[0].Address, exampleNft.Name),
-			expected:    `(?m)^\[{"transactionId":".+".*}\]$`,
+			expected:    `(?m)^\[{.*"transactionId":".+".*}\]$`,
 			status:      http.StatusOK,
 		},
 		{
@@ -1281,7 +1322,7 @@ func TestTokenHandlers(t *testing.T) {
 			method:      http.MethodGet,
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals/%s", cfg.AdminAddress, flowToken.Name, testTransferFT.TransactionId),
-			expected:    fmt.Sprintf(`(?m)^{"transactionId":"%s".*}$`, testTransferFT.TransactionId),
+			expected:    fmt.Sprintf(`(?m)^{.*"transactionId":"%s".*}$`, testTransferFT.TransactionId),
 			status:      http.StatusOK,
 		},
 	}
@@ -1293,7 +1334,7 @@ func TestTokenHandlers(t *testing.T) {
 			method:      http.MethodGet,
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals/%s", cfg.AdminAddress, flowToken.Name, testTransferFT.TransactionId),
-			expected:    fmt.Sprintf(`(?m)^{"transactionId":"%s".*}$`, testTransferFT.TransactionId),
+			expected:    fmt.Sprintf(`(?m)^{.*"transactionId":"%s".*}$`, testTransferFT.TransactionId),
 			status:      http.StatusOK,
 		},
 		{
@@ -1301,7 +1342,15 @@ func TestTokenHandlers(t *testing.T) {
 			method:      http.MethodGet,
 			contentType: "application/json",
 			url:         fmt.Sprintf("/%s/non-fungible-tokens/%s/withdrawals/%s", testAccounts[0].Address, exampleNft.Name, testTransferNFT.TransactionId),
-			expected:    fmt.Sprintf(`(?m)^{"transactionId":"%s".*}$`, testTransferNFT.TransactionId),
+			expected:    fmt.Sprintf(`(?m)^{.*"transactionId":"%s".*}$`, testTransferNFT.TransactionId),
+			status:      http.StatusOK,
+		},
+		{
+			name:        "get fungible token withdrawal details by id",
+			method:      http.MethodGet,
+			contentType: "application/json",
+			url:         fmt.Sprintf("/%s/fungible-tokens/%s/withdrawals/%s", cfg.AdminAddress, flowToken.Name, testTransferFT.ID),
+			expected:    fmt.Sprintf(`(?m)^{"id":"%s".*"state":"sealed".*}$`, testTransferFT.ID),
 			status:      http.StatusOK,
 		},
 		{
@@ -1463,6 +1512,7 @@ func TestTemplateHandlers(t *testing.T) {
 	router.Handle("/", handler.AddToken()).Methods(http.MethodPost)
 	router.Handle("/", handler.ListTokens(templates.NotSpecified)).Methods(http.MethodGet)
 	router.Handle("/{id_or_name}", handler.GetToken()).Methods(http.MethodGet)
+	router.Handle("/{id}", handler.UpdateToken()).Methods(http.MethodPut)
 	router.Handle("/{id}", handler.RemoveToken()).Methods(http.MethodDelete)
 
 	addStepts := []httpTestStep{
@@ -1556,6 +1606,44 @@ func TestTemplateHandlers(t *testing.T) {
 		},
 	}
 
+	updateSteps := []httpTestStep{
+		{
+			name:        "Update not found",
+			method:      http.MethodPut,
+			body:        strings.NewReader(`{"disabled":true}`),
+			contentType: "application/json",
+			url:         "/100",
+			expected:    `record not found`,
+			status:      http.StatusNotFound,
+		},
+		{
+			name:        "Disable",
+			method:      http.MethodPut,
+			body:        strings.NewReader(`{"disabled":true}`),
+			contentType: "application/json",
+			url:         "/1",
+			expected:    fmt.Sprintf(`{"id":1,"name":"TestToken","address":"%s","type":"NotSpecified","disabled":true}`, cfg.AdminAddress),
+			status:      http.StatusOK,
+		},
+		{
+			name:        "Get disabled by name",
+			method:      http.MethodGet,
+			contentType: "application/json",
+			url:         "/TestToken",
+			expected:    `token TestToken is disabled`,
+			status:      http.StatusNotFound,
+		},
+		{
+			name:        "Enable",
+			method:      http.MethodPut,
+			body:        strings.NewReader(`{"disabled":false}`),
+			contentType: "application/json",
+			url:         "/1",
+			expected:    fmt.Sprintf(`{"id":1,"name":"TestToken","address":"%s","type":"NotSpecified"}`, cfg.AdminAddress),
+			status:      http.StatusOK,
+		},
+	}
+
 	removeSteps := []httpTestStep{
 		{
 			name:        "Remove invalid id",
@@ -1626,6 +1714,12 @@ func TestTemplateHandlers(t *testing.T) {
 		})
 	}
 
+	for _, s := range updateSteps {
+		t.Run(s.name, func(t *testing.T) {
+			handleStepRequest(s, router, t)
+		})
+	}
+
 	for _, s := range removeSteps {
 		t.Run(s.name, func(t *testing.T) {
 			handleStepRequest(s, router, t)
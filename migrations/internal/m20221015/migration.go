@@ -0,0 +1,44 @@
+// m20221015 handles AdminKey migration
+// NOTE: AdminKeys are used to store rotated admin account keys
+package m20221015
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221015"
+
+type AdminKey struct {
+	ID            int `gorm:"primaryKey"`
+	Index         int
+	Value         []byte
+	PublicKey     string
+	SignAlgo      string
+	HashAlgo      string
+	Status        string `gorm:"index"`
+	TransactionID string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (AdminKey) TableName() string {
+	return "admin_keys"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AdminKey{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&AdminKey{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// m20221016_keys initializes a separate key material database.
+// NOTE: This migration is only run against the keys database (see
+// migrations.KeysList), it snapshots the current state of the key tables
+// without the foreign key to the accounts table, which lives elsewhere.
+package m20221016_keys
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "keys_20221016"
+
+type Storable struct {
+	ID             int    `gorm:"primaryKey"`
+	AccountAddress string `gorm:"index"`
+	Index          int    `gorm:"index"`
+	Type           string
+	Value          []byte
+	PublicKey      string
+	SignAlgo       string
+	HashAlgo       string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+func (Storable) TableName() string {
+	return "storable_keys"
+}
+
+type ProposalKey struct {
+	ID        int `gorm:"primaryKey"`
+	KeyIndex  int `gorm:"unique"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (ProposalKey) TableName() string {
+	return "proposal_keys"
+}
+
+type AdminKey struct {
+	ID            int `gorm:"primaryKey"`
+	Index         int
+	Value         []byte
+	PublicKey     string
+	SignAlgo      string
+	HashAlgo      string
+	Status        string `gorm:"index"`
+	TransactionID string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (AdminKey) TableName() string {
+	return "admin_keys"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Storable{}, &ProposalKey{}, &AdminKey{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&Storable{}, &ProposalKey{}, &AdminKey{}); err != nil {
+		return err
+	}
+
+	return nil
+}
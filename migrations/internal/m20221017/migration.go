@@ -0,0 +1,56 @@
+// m20221017 handles Account.Label migration
+package m20221017
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221017"
+
+type Account struct {
+	Address   string    `gorm:"primaryKey"`
+	Label     string    `gorm:"index"`
+	Type      string    `gorm:"default:custodial"`
+	CreatedAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (Account) TableName() string {
+	return "accounts"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Account{}, "label"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().CreateIndex(&Account{}, "Label"); err != nil {
+		return err
+	}
+
+	// Listings are ordered and filtered by creation time
+	if err := tx.Migrator().CreateIndex(&Account{}, "CreatedAt"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&Account{}, "CreatedAt"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropIndex(&Account{}, "Label"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropColumn(&Account{}, "label"); err != nil {
+		return err
+	}
+
+	return nil
+}
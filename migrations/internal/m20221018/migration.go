@@ -0,0 +1,39 @@
+// m20221018 adds idempotency keys of account creation requests
+package m20221018
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221018"
+
+type IdempotencyKey struct {
+	Key         string `gorm:"primaryKey"`
+	RequestHash string
+	JobID       *uuid.UUID `gorm:"type:uuid"`
+	Address     string
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "account_idempotency_keys"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&IdempotencyKey{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&IdempotencyKey{}); err != nil {
+		return err
+	}
+
+	return nil
+}
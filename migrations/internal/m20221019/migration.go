@@ -0,0 +1,65 @@
+// m20221019 adds tenant scoping of accounts, transactions and jobs
+package m20221019
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221019"
+
+type Account struct {
+	Address  string `gorm:"primaryKey"`
+	TenantID string `gorm:"index"`
+}
+
+func (Account) TableName() string {
+	return "accounts"
+}
+
+type Transaction struct {
+	TransactionId string `gorm:"column:transaction_id;primaryKey"`
+	TenantID      string `gorm:"column:tenant_id;index"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+type Job struct {
+	ID       string `gorm:"column:id;primary_key"`
+	TenantID string `gorm:"column:tenant_id;index"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+var models = []interface{}{&Account{}, &Transaction{}, &Job{}}
+
+func Migrate(tx *gorm.DB) error {
+	for _, m := range models {
+		if err := tx.Migrator().AddColumn(m, "TenantID"); err != nil {
+			return err
+		}
+
+		if err := tx.Migrator().CreateIndex(m, "TenantID"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	for _, m := range models {
+		if err := tx.Migrator().DropIndex(m, "TenantID"); err != nil {
+			return err
+		}
+
+		if err := tx.Migrator().DropColumn(m, "TenantID"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
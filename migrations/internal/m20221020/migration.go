@@ -0,0 +1,42 @@
+// m20221020 handles Account.Frozen migration
+package m20221020
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221020"
+
+type Account struct {
+	Address      string `gorm:"primaryKey"`
+	Frozen       bool   `gorm:"not null;default:false"`
+	FrozenReason string
+}
+
+func (Account) TableName() string {
+	return "accounts"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Account{}, "Frozen"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().AddColumn(&Account{}, "FrozenReason"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&Account{}, "FrozenReason"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropColumn(&Account{}, "Frozen"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+// m20221021 handles Account storage usage tracking migration
+package m20221021
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221021"
+
+type Account struct {
+	Address          string `gorm:"primaryKey"`
+	StorageUsed      uint64
+	StorageCapacity  uint64
+	StorageCheckedAt *time.Time
+}
+
+func (Account) TableName() string {
+	return "accounts"
+}
+
+var columns = []string{"StorageUsed", "StorageCapacity", "StorageCheckedAt"}
+
+func Migrate(tx *gorm.DB) error {
+	for _, c := range columns {
+		if err := tx.Migrator().AddColumn(&Account{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	for _, c := range columns {
+		if err := tx.Migrator().DropColumn(&Account{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// m20221022 handles Account.Alias migration
+package m20221022
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221022"
+
+type Account struct {
+	Address  string  `gorm:"primaryKey"`
+	TenantID string  `gorm:"index;uniqueIndex:idx_accounts_tenant_alias"`
+	Alias    *string `gorm:"size:64;uniqueIndex:idx_accounts_tenant_alias"`
+}
+
+func (Account) TableName() string {
+	return "accounts"
+}
+
+const aliasIndex = "idx_accounts_tenant_alias"
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Account{}, "Alias"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().CreateIndex(&Account{}, aliasIndex); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&Account{}, aliasIndex); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropColumn(&Account{}, "Alias"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+// m20221023 adds account key recoveries
+package m20221023
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221023"
+
+type KeyRecovery struct {
+	ID             uuid.UUID `gorm:"column:id;primary_key;type:uuid;"`
+	AccountAddress string    `gorm:"index"`
+	LostKeyIndex   int
+	PublicKey      string
+	SignAlgo       string
+	HashAlgo       string
+	Weight         int
+	Status         string `gorm:"index"`
+	ApprovedBy     string
+	ApprovedAt     *time.Time
+	ExecutableAt   *time.Time
+	NewKeyIndex    *int
+	TransactionID  string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (KeyRecovery) TableName() string {
+	return "account_key_recoveries"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&KeyRecovery{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&KeyRecovery{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+// m20221024 handles Transaction.PendingSend migration
+package m20221024
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221024"
+
+type Transaction struct {
+	TransactionId string `gorm:"column:transaction_id;primaryKey"`
+	PendingSend   bool   `gorm:"column:pending_send;not null;default:false"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Transaction{}, "PendingSend")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Transaction{}, "PendingSend")
+}
@@ -0,0 +1,41 @@
+// m20221025 adds named transaction templates
+package m20221025
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const ID = "20221025"
+
+type TransactionTemplate struct {
+	ID          uint64 `gorm:"primaryKey"`
+	Name        string `gorm:"uniqueIndex;size:64;not null"`
+	Description string
+	Code        string `gorm:"not null"`
+	Arguments   datatypes.JSON
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (TransactionTemplate) TableName() string {
+	return "transaction_templates"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&TransactionTemplate{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&TransactionTemplate{}); err != nil {
+		return err
+	}
+
+	return nil
+}
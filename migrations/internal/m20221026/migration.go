@@ -0,0 +1,48 @@
+// m20221026 handles the Transaction result migration
+package m20221026
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const ID = "20221026"
+
+type Transaction struct {
+	TransactionId string         `gorm:"column:transaction_id;primaryKey"`
+	Status        string         `gorm:"column:status;index"`
+	ErrorMessage  string         `gorm:"column:error_message"`
+	BlockID       string         `gorm:"column:block_id"`
+	BlockHeight   uint64         `gorm:"column:block_height"`
+	StoredEvents  datatypes.JSON `gorm:"column:events"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+var columns = []string{"Status", "ErrorMessage", "BlockID", "BlockHeight", "StoredEvents"}
+
+func Migrate(tx *gorm.DB) error {
+	for _, c := range columns {
+		if err := tx.Migrator().AddColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	return tx.Migrator().CreateIndex(&Transaction{}, "Status")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&Transaction{}, "Status"); err != nil {
+		return err
+	}
+
+	for _, c := range columns {
+		if err := tx.Migrator().DropColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
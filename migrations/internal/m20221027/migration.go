@@ -0,0 +1,25 @@
+// m20221027 handles Transaction.CallbackURL migration
+package m20221027
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221027"
+
+type Transaction struct {
+	TransactionId string `gorm:"column:transaction_id;primaryKey"`
+	CallbackURL   string `gorm:"column:callback_url"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Transaction{}, "CallbackURL")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Transaction{}, "CallbackURL")
+}
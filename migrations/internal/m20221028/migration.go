@@ -0,0 +1,46 @@
+// m20221028 handles Transaction.TemplateName and Transaction.TokenName migration
+package m20221028
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221028"
+
+type Transaction struct {
+	TransactionId string `gorm:"column:transaction_id;primaryKey"`
+	TemplateName  string `gorm:"column:template_name;index"`
+	TokenName     string `gorm:"column:token_name;index"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	for _, c := range []string{"TemplateName", "TokenName"} {
+		if err := tx.Migrator().AddColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+
+		if err := tx.Migrator().CreateIndex(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	for _, c := range []string{"TemplateName", "TokenName"} {
+		if err := tx.Migrator().DropIndex(&Transaction{}, c); err != nil {
+			return err
+		}
+
+		if err := tx.Migrator().DropColumn(&Transaction{}, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+// m20221029 handles Transaction.SendAttempts migration
+package m20221029
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const ID = "20221029"
+
+type Transaction struct {
+	TransactionId string         `gorm:"column:transaction_id;primaryKey"`
+	SendAttempts  datatypes.JSON `gorm:"column:send_attempts"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Transaction{}, "SendAttempts")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Transaction{}, "SendAttempts")
+}
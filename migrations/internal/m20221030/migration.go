@@ -0,0 +1,50 @@
+// m20221030 adds transaction schedules
+package m20221030
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const ID = "20221030"
+
+type Schedule struct {
+	ID             uuid.UUID `gorm:"column:id;primary_key;type:uuid;"`
+	AccountAddress string    `gorm:"index"`
+	TenantID       string    `gorm:"index"`
+	Name           string
+	Cron           string
+	Code           string
+	Arguments      datatypes.JSON
+	GasLimit       uint64
+	Paused         bool
+	NextRunAt      *time.Time `gorm:"index"`
+	LastRunAt      *time.Time
+	LastJobID      *uuid.UUID `gorm:"type:uuid"`
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (Schedule) TableName() string {
+	return "transaction_schedules"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Schedule{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&Schedule{}); err != nil {
+		return err
+	}
+
+	return nil
+}
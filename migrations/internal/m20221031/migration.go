@@ -0,0 +1,93 @@
+// m20221031 adds the events of sealed transactions
+package m20221031
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const ID = "20221031"
+
+type Event struct {
+	TransactionId    string         `gorm:"column:transaction_id;primaryKey"`
+	EventIndex       int            `gorm:"column:event_index;primaryKey;autoIncrement:false"`
+	TransactionIndex int            `gorm:"column:transaction_index"`
+	Type             string         `gorm:"column:type;index"`
+	BlockID          string         `gorm:"column:block_id"`
+	BlockHeight      uint64         `gorm:"column:block_height;index"`
+	Payload          datatypes.JSON `gorm:"column:payload"`
+	CreatedAt        time.Time      `gorm:"column:created_at"`
+}
+
+func (Event) TableName() string {
+	return "events"
+}
+
+type Transaction struct {
+	TransactionId string         `gorm:"column:transaction_id;primaryKey"`
+	Status        string         `gorm:"column:status"`
+	BlockID       string         `gorm:"column:block_id"`
+	BlockHeight   uint64         `gorm:"column:block_height"`
+	StoredEvents  datatypes.JSON `gorm:"column:events"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+type storedEvent struct {
+	Type             string          `json:"type"`
+	TransactionIndex int             `json:"transactionIndex"`
+	EventIndex       int             `json:"eventIndex"`
+	Payload          json.RawMessage `json:"payload"`
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Event{}); err != nil {
+		return err
+	}
+
+	// Copy the events of already sealed transactions
+	var tt []Transaction
+	return tx.
+		Where("status = ? AND events IS NOT NULL", "SEALED").
+		FindInBatches(&tt, 100, func(_ *gorm.DB, batch int) error {
+			var events []Event
+			for _, t := range tt {
+				var stored []storedEvent
+				if err := json.Unmarshal(t.StoredEvents, &stored); err != nil {
+					return err
+				}
+
+				for _, e := range stored {
+					events = append(events, Event{
+						TransactionId:    t.TransactionId,
+						EventIndex:       e.EventIndex,
+						TransactionIndex: e.TransactionIndex,
+						Type:             e.Type,
+						BlockID:          t.BlockID,
+						BlockHeight:      t.BlockHeight,
+						Payload:          datatypes.JSON(e.Payload),
+						CreatedAt:        time.Now(),
+					})
+				}
+			}
+
+			if len(events) == 0 {
+				return nil
+			}
+
+			return tx.Create(&events).Error
+		}).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&Event{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+// m20221101 handles Transaction.Metadata migration
+package m20221101
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const ID = "20221101"
+
+type Transaction struct {
+	TransactionId string         `gorm:"column:transaction_id;primaryKey"`
+	Metadata      datatypes.JSON `gorm:"column:metadata"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Transaction{}, "Metadata")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Transaction{}, "Metadata")
+}
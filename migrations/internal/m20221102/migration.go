@@ -0,0 +1,34 @@
+// m20221102 handles Transaction.Fees and Transaction.ExecutionEffort migration
+package m20221102
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221102"
+
+type Transaction struct {
+	TransactionId   string `gorm:"column:transaction_id;primaryKey"`
+	Fees            uint64 `gorm:"column:fees"`
+	ExecutionEffort uint64 `gorm:"column:execution_effort"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Transaction{}, "Fees"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().AddColumn(&Transaction{}, "ExecutionEffort")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&Transaction{}, "ExecutionEffort"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropColumn(&Transaction{}, "Fees")
+}
@@ -0,0 +1,37 @@
+// m20221103 adds the code allowlist
+package m20221103
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221103"
+
+type AllowedCode struct {
+	Hash        string    `gorm:"column:hash;primaryKey;size:64"`
+	Kind        string    `gorm:"column:kind;primaryKey;size:16"`
+	Description string    `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (AllowedCode) TableName() string {
+	return "code_allowlist"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AllowedCode{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&AllowedCode{}); err != nil {
+		return err
+	}
+
+	return nil
+}
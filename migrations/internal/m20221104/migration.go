@@ -0,0 +1,64 @@
+// m20221104 adds the audit log and Transaction.AuditID
+package m20221104
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221104"
+
+type Entry struct {
+	ID           uuid.UUID `gorm:"column:id;primary_key;type:uuid;"`
+	Method       string    `gorm:"column:method"`
+	Path         string    `gorm:"column:path"`
+	Route        string    `gorm:"column:route"`
+	Address      string    `gorm:"column:address;index"`
+	StatusCode   int       `gorm:"column:status_code"`
+	TenantID     string    `gorm:"column:tenant_id;index"`
+	APIKeyID     string    `gorm:"column:api_key_id"`
+	SourceIP     string    `gorm:"column:source_ip"`
+	ForwardedFor string    `gorm:"column:forwarded_for"`
+	UserAgent    string    `gorm:"column:user_agent"`
+	Fingerprint  string    `gorm:"column:fingerprint;index"`
+	CreatedAt    time.Time `gorm:"column:created_at;index"`
+}
+
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+type Transaction struct {
+	TransactionId string     `gorm:"column:transaction_id;primaryKey"`
+	AuditID       *uuid.UUID `gorm:"column:audit_id;type:uuid;index"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Entry{}); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().AddColumn(&Transaction{}, "AuditID"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().CreateIndex(&Transaction{}, "AuditID")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&Transaction{}, "AuditID"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropColumn(&Transaction{}, "AuditID"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropTable(&Entry{})
+}
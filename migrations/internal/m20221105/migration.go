@@ -0,0 +1,43 @@
+// m20221105 handles Job.Priority and Transaction.Priority migration
+package m20221105
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221105"
+
+type Job struct {
+	ID       uuid.UUID `gorm:"column:id;primary_key;type:uuid;"`
+	Priority int       `gorm:"column:priority;not null;default:0"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+type Transaction struct {
+	TransactionId string `gorm:"column:transaction_id;primaryKey"`
+	Priority      int    `gorm:"column:priority;not null;default:0"`
+}
+
+func (Transaction) TableName() string {
+	return "transactions"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Job{}, "Priority"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().AddColumn(&Transaction{}, "Priority")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&Transaction{}, "Priority"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropColumn(&Job{}, "Priority")
+}
@@ -0,0 +1,94 @@
+// m20221106 adds withdrawal records
+package m20221106
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221106"
+
+type Withdrawal struct {
+	ID               uuid.UUID  `gorm:"column:id;primary_key;type:uuid;"`
+	TransactionId    string     `gorm:"column:transaction_id;index"`
+	State            string     `gorm:"column:state;index"`
+	SenderAddress    string     `gorm:"column:sender_address;index"`
+	RecipientAddress string     `gorm:"column:recipient_address"`
+	TokenName        string     `gorm:"column:token_name;index"`
+	FtAmount         string     `gorm:"column:ft_amount"`
+	NftID            uint64     `gorm:"column:nft_id"`
+	JobID            *uuid.UUID `gorm:"column:job_id;type:uuid"`
+	Error            string     `gorm:"column:error"`
+	TenantID         string     `gorm:"column:tenant_id;index"`
+	CreatedAt        time.Time  `gorm:"column:created_at;index"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at"`
+}
+
+func (Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+type transfer struct {
+	ID               uint64 `gorm:"column:id;primaryKey"`
+	TransactionId    string
+	RecipientAddress string
+	SenderAddress    string
+	FtAmount         string
+	NftID            uint64
+	TokenName        string
+	TenantID         string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Transaction types of token transfers
+const (
+	ftTransfer  = 3
+	nftTransfer = 5
+)
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Withdrawal{}); err != nil {
+		return err
+	}
+
+	// Record the transfers of managed accounts made so far as sealed
+	// withdrawals
+	var tt []transfer
+	return tx.
+		Table("token_transfers").
+		Select("token_transfers.*, transactions.tenant_id").
+		Joins("join transactions on token_transfers.transaction_id = transactions.transaction_id").
+		Where("transactions.transaction_type IN ?", []int{ftTransfer, nftTransfer}).
+		Where("token_transfers.sender_address IN (?)", tx.Table("accounts").Select("address")).
+		Where("token_transfers.deleted_at IS NULL").
+		FindInBatches(&tt, 100, func(_ *gorm.DB, _ int) error {
+			ww := make([]Withdrawal, len(tt))
+			for i, t := range tt {
+				ww[i] = Withdrawal{
+					ID:               uuid.New(),
+					TransactionId:    t.TransactionId,
+					State:            "sealed",
+					SenderAddress:    t.SenderAddress,
+					RecipientAddress: t.RecipientAddress,
+					TokenName:        t.TokenName,
+					FtAmount:         t.FtAmount,
+					NftID:            t.NftID,
+					TenantID:         t.TenantID,
+					CreatedAt:        t.CreatedAt,
+					UpdatedAt:        t.UpdatedAt,
+				}
+			}
+			if len(ww) == 0 {
+				return nil
+			}
+
+			return tx.Create(&ww).Error
+		}).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&Withdrawal{})
+}
@@ -0,0 +1,37 @@
+// m20221107 handles TokenTransfer.BlockHeight migration
+package m20221107
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221107"
+
+type TokenTransfer struct {
+	ID          uint64 `gorm:"column:id;primaryKey"`
+	BlockHeight uint64 `gorm:"column:block_height"`
+}
+
+func (TokenTransfer) TableName() string {
+	return "token_transfers"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&TokenTransfer{}, "BlockHeight"); err != nil {
+		return err
+	}
+
+	// Transfers are in the block of their transaction, known for sealed
+	// transactions of this service
+	return tx.Exec(`UPDATE token_transfers SET block_height = (
+		SELECT transactions.block_height FROM transactions
+		WHERE transactions.transaction_id = token_transfers.transaction_id
+	) WHERE EXISTS (
+		SELECT 1 FROM transactions
+		WHERE transactions.transaction_id = token_transfers.transaction_id AND transactions.block_height > 0
+	)`).Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&TokenTransfer{}, "BlockHeight")
+}
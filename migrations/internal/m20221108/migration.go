@@ -0,0 +1,25 @@
+// m20221108 handles Token.Disabled migration
+package m20221108
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221108"
+
+type Token struct {
+	ID       uint64 `gorm:"column:id;primaryKey"`
+	Disabled bool   `gorm:"column:disabled;not null;default:false"`
+}
+
+func (Token) TableName() string {
+	return "tokens"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Token{}, "Disabled")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Token{}, "Disabled")
+}
@@ -0,0 +1,32 @@
+// m20221109 handles Withdrawal.BatchID migration
+package m20221109
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221109"
+
+type Withdrawal struct {
+	ID      uuid.UUID  `gorm:"column:id;primary_key;type:uuid;"`
+	BatchID *uuid.UUID `gorm:"column:batch_id;type:uuid;index"`
+}
+
+func (Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Withdrawal{}, "BatchID"); err != nil {
+		return err
+	}
+	return tx.Migrator().CreateIndex(&Withdrawal{}, "BatchID")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&Withdrawal{}, "BatchID"); err != nil {
+		return err
+	}
+	return tx.Migrator().DropColumn(&Withdrawal{}, "BatchID")
+}
@@ -0,0 +1,26 @@
+// m20221110 handles Withdrawal.Sweep migration
+package m20221110
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221110"
+
+type Withdrawal struct {
+	ID    uuid.UUID `gorm:"column:id;primary_key;type:uuid;"`
+	Sweep bool      `gorm:"column:sweep;not null;default:false"`
+}
+
+func (Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Withdrawal{}, "Sweep")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Withdrawal{}, "Sweep")
+}
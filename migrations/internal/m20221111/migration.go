@@ -0,0 +1,37 @@
+// m20221111 adds the withdrawal destination allowlist
+package m20221111
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221111"
+
+type WithdrawalDestination struct {
+	TokenName   string    `gorm:"column:token_name;primaryKey;size:64"`
+	Address     string    `gorm:"column:address;primaryKey;size:18"`
+	Description string    `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+func (WithdrawalDestination) TableName() string {
+	return "withdrawal_destinations"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&WithdrawalDestination{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&WithdrawalDestination{}); err != nil {
+		return err
+	}
+
+	return nil
+}
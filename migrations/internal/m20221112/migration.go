@@ -0,0 +1,44 @@
+// m20221112 adds the internal ledger transfers
+package m20221112
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221112"
+
+type LedgerTransfer struct {
+	ID               uuid.UUID  `gorm:"column:id;primary_key;type:uuid;"`
+	TokenName        string     `gorm:"column:token_name;index"`
+	SenderAddress    string     `gorm:"column:sender_address;index"`
+	RecipientAddress string     `gorm:"column:recipient_address;index"`
+	FtAmount         string     `gorm:"column:ft_amount"`
+	State            string     `gorm:"column:state;index"`
+	WithdrawalID     *uuid.UUID `gorm:"column:withdrawal_id;type:uuid"`
+	TenantID         string     `gorm:"column:tenant_id;index"`
+	CreatedAt        time.Time  `gorm:"column:created_at;index"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at"`
+}
+
+func (LedgerTransfer) TableName() string {
+	return "ledger_transfers"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&LedgerTransfer{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&LedgerTransfer{}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+// m20221113 handles TokenTransfer.DetectedAt and TokenTransfer.ConfirmedAt migration
+package m20221113
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221113"
+
+type TokenTransfer struct {
+	ID          uint64     `gorm:"column:id;primaryKey"`
+	DetectedAt  *time.Time `gorm:"column:detected_at"`
+	ConfirmedAt *time.Time `gorm:"column:confirmed_at;index"`
+}
+
+func (TokenTransfer) TableName() string {
+	return "token_transfers"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&TokenTransfer{}, "DetectedAt"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().AddColumn(&TokenTransfer{}, "ConfirmedAt"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().CreateIndex(&TokenTransfer{}, "ConfirmedAt"); err != nil {
+		return err
+	}
+
+	// Existing transfers are not notified of again
+	return tx.Exec("UPDATE token_transfers SET detected_at = created_at, confirmed_at = created_at").Error
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&TokenTransfer{}, "ConfirmedAt"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropColumn(&TokenTransfer{}, "ConfirmedAt"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropColumn(&TokenTransfer{}, "DetectedAt")
+}
@@ -0,0 +1,52 @@
+// m20221114 adds idempotency keys of withdrawal requests
+package m20221114
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221114"
+
+type WithdrawalIdempotencyKey struct {
+	Key         string `gorm:"primaryKey"`
+	RequestHash string
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+func (WithdrawalIdempotencyKey) TableName() string {
+	return "withdrawal_idempotency_keys"
+}
+
+type Withdrawal struct {
+	IdempotencyKey string `gorm:"column:idempotency_key;index"`
+}
+
+func (Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&WithdrawalIdempotencyKey{}); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().AddColumn(&Withdrawal{}, "IdempotencyKey"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().CreateIndex(&Withdrawal{}, "IdempotencyKey")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropIndex(&Withdrawal{}, "IdempotencyKey"); err != nil {
+		return err
+	}
+
+	if err := tx.Migrator().DropColumn(&Withdrawal{}, "IdempotencyKey"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropTable(&WithdrawalIdempotencyKey{})
+}
@@ -0,0 +1,33 @@
+// m20221115 adds the minter account and the mint code of non-fungible tokens
+package m20221115
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221115"
+
+type Token struct {
+	MinterAddress string
+	Mint          string
+}
+
+func (Token) TableName() string {
+	return "tokens"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Token{}, "MinterAddress"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().AddColumn(&Token{}, "Mint")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&Token{}, "Mint"); err != nil {
+		return err
+	}
+
+	return tx.Migrator().DropColumn(&Token{}, "MinterAddress")
+}
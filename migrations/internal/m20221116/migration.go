@@ -0,0 +1,43 @@
+// m20221116 adds the outbox of deposit webhook notifications
+package m20221116
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221116"
+
+type DepositNotification struct {
+	ID          string `gorm:"primaryKey;size:36"`
+	TransferID  uint64 `gorm:"uniqueIndex:idx_deposit_notifications_transfer_event"`
+	Event       string `gorm:"uniqueIndex:idx_deposit_notifications_transfer_event;size:64"`
+	Payload     []byte
+	Attempts    int
+	LastError   string
+	CreatedAt   time.Time
+	DeliveredAt *time.Time `gorm:"index"`
+}
+
+func (DepositNotification) TableName() string {
+	return "deposit_notifications"
+}
+
+type DepositNotificationReceipt struct {
+	NotificationID string `gorm:"primaryKey;size:36"`
+	Endpoint       string `gorm:"primaryKey;size:255"`
+	DeliveredAt    time.Time
+}
+
+func (DepositNotificationReceipt) TableName() string {
+	return "deposit_notification_receipts"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.AutoMigrate(&DepositNotification{}, &DepositNotificationReceipt{})
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&DepositNotificationReceipt{}, &DepositNotification{})
+}
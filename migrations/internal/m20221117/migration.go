@@ -0,0 +1,32 @@
+// m20221117 adds the daily balance snapshots of accounts
+package m20221117
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221117"
+
+type BalanceSnapshot struct {
+	ID             uint64 `gorm:"column:id;primaryKey"`
+	AccountAddress string `gorm:"column:account_address;uniqueIndex:idx_balance_snapshots_account_token_date;not null"`
+	TokenName      string `gorm:"column:token_name;uniqueIndex:idx_balance_snapshots_account_token_date;index:idx_balance_snapshots_token_date;not null"`
+	Date           string `gorm:"column:date;size:10;uniqueIndex:idx_balance_snapshots_account_token_date;index:idx_balance_snapshots_token_date;not null"`
+	Balance        string `gorm:"column:balance"`
+	TenantID       string `gorm:"column:tenant_id;index"`
+	TakenAt        time.Time
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "balance_snapshots"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.AutoMigrate(&BalanceSnapshot{})
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropTable(&BalanceSnapshot{})
+}
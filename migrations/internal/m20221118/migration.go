@@ -0,0 +1,28 @@
+// m20221118 adds the time of the next retry of errored jobs
+package m20221118
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221118"
+
+type Job struct {
+	ID      uuid.UUID  `gorm:"column:id;primary_key;type:uuid;"`
+	RetryAt *time.Time `gorm:"column:retry_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Job{}, "RetryAt")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Job{}, "RetryAt")
+}
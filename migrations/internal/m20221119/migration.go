@@ -0,0 +1,29 @@
+// m20221119 adds the time an account key was found revoked, so revoked keys
+// can be kept soft deleted when their account is enabled again.
+// NOTE: This migration is run against both the main and the keys database.
+package m20221119
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221119"
+
+type Storable struct {
+	ID        int        `gorm:"primaryKey"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+}
+
+func (Storable) TableName() string {
+	return "storable_keys"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Storable{}, "RevokedAt")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Storable{}, "RevokedAt")
+}
@@ -0,0 +1,33 @@
+// m20221120 adds the locks serializing the ledgers of accounts
+package m20221120
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221120"
+
+type LedgerLock struct {
+	TokenName      string `gorm:"column:token_name;primaryKey"`
+	AccountAddress string `gorm:"column:account_address;primaryKey"`
+}
+
+func (LedgerLock) TableName() string {
+	return "ledger_locks"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&LedgerLock{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&LedgerLock{}); err != nil {
+		return err
+	}
+
+	return nil
+}
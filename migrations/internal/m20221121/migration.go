@@ -0,0 +1,32 @@
+// m20221121 adds the admins who requested and resolved withdrawals pending
+// approval
+package m20221121
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221121"
+
+type Withdrawal struct {
+	RequestedBy string `gorm:"column:requested_by"`
+	ResolvedBy  string `gorm:"column:resolved_by"`
+}
+
+func (Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.Migrator().AddColumn(&Withdrawal{}, "RequestedBy"); err != nil {
+		return err
+	}
+	return tx.Migrator().AddColumn(&Withdrawal{}, "ResolvedBy")
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropColumn(&Withdrawal{}, "ResolvedBy"); err != nil {
+		return err
+	}
+	return tx.Migrator().DropColumn(&Withdrawal{}, "RequestedBy")
+}
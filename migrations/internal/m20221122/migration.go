@@ -0,0 +1,36 @@
+// m20221122 adds the completed runs of daily balance snapshots
+package m20221122
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const ID = "20221122"
+
+type BalanceSnapshotRun struct {
+	TokenName   string    `gorm:"column:token_name;primaryKey"`
+	Date        string    `gorm:"column:date;size:10;primaryKey"`
+	CompletedAt time.Time `gorm:"column:completed_at"`
+}
+
+func (BalanceSnapshotRun) TableName() string {
+	return "balance_snapshot_runs"
+}
+
+func Migrate(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&BalanceSnapshotRun{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Rollback(tx *gorm.DB) error {
+	if err := tx.Migrator().DropTable(&BalanceSnapshotRun{}); err != nil {
+		return err
+	}
+
+	return nil
+}
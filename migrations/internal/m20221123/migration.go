@@ -0,0 +1,26 @@
+// m20221123 adds the creation transaction sent for an account creation
+// request with an idempotency key, whose outcome is unknown.
+package m20221123
+
+import (
+	"gorm.io/gorm"
+)
+
+const ID = "20221123"
+
+type IdempotencyKey struct {
+	Key           string `gorm:"primaryKey"`
+	TransactionID string `gorm:"column:transaction_id"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "account_idempotency_keys"
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&IdempotencyKey{}, "TransactionID")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&IdempotencyKey{}, "TransactionID")
+}
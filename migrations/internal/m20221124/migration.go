@@ -0,0 +1,23 @@
+// m20221124 adds the storage top-up job of an account, which claims the
+// account for the top-up.
+package m20221124
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const ID = "20221124"
+
+type Account struct {
+	Address           string     `gorm:"primaryKey"`
+	StorageTopUpJobID *uuid.UUID `gorm:"column:storage_top_up_job_id;type:uuid"`
+}
+
+func Migrate(tx *gorm.DB) error {
+	return tx.Migrator().AddColumn(&Account{}, "StorageTopUpJobID")
+}
+
+func Rollback(tx *gorm.DB) error {
+	return tx.Migrator().DropColumn(&Account{}, "StorageTopUpJobID")
+}
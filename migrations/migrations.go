@@ -12,6 +12,47 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20211221_2"
 	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20220212"
 	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221001"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221015"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221016_keys"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221017"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221018"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221019"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221020"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221021"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221022"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221023"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221024"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221025"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221026"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221027"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221028"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221029"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221030"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221031"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221101"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221102"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221103"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221104"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221105"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221106"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221107"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221108"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221109"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221110"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221111"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221112"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221113"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221114"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221115"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221116"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221117"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221118"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221119"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221120"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221121"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221122"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221123"
+	"github.com/flow-hydraulics/flow-wallet-api/migrations/internal/m20221124"
 	"github.com/go-gormigrate/gormigrate/v2"
 )
 
@@ -72,6 +113,225 @@ func List() []*gormigrate.Migration {
 			Migrate:  m20221001.Migrate,
 			Rollback: m20221001.Rollback,
 		},
+		{
+			ID:       m20221015.ID,
+			Migrate:  m20221015.Migrate,
+			Rollback: m20221015.Rollback,
+		},
+		{
+			ID:       m20221017.ID,
+			Migrate:  m20221017.Migrate,
+			Rollback: m20221017.Rollback,
+		},
+		{
+			ID:       m20221018.ID,
+			Migrate:  m20221018.Migrate,
+			Rollback: m20221018.Rollback,
+		},
+		{
+			ID:       m20221019.ID,
+			Migrate:  m20221019.Migrate,
+			Rollback: m20221019.Rollback,
+		},
+		{
+			ID:       m20221020.ID,
+			Migrate:  m20221020.Migrate,
+			Rollback: m20221020.Rollback,
+		},
+		{
+			ID:       m20221021.ID,
+			Migrate:  m20221021.Migrate,
+			Rollback: m20221021.Rollback,
+		},
+		{
+			ID:       m20221022.ID,
+			Migrate:  m20221022.Migrate,
+			Rollback: m20221022.Rollback,
+		},
+		{
+			ID:       m20221023.ID,
+			Migrate:  m20221023.Migrate,
+			Rollback: m20221023.Rollback,
+		},
+		{
+			ID:       m20221024.ID,
+			Migrate:  m20221024.Migrate,
+			Rollback: m20221024.Rollback,
+		},
+		{
+			ID:       m20221025.ID,
+			Migrate:  m20221025.Migrate,
+			Rollback: m20221025.Rollback,
+		},
+		{
+			ID:       m20221026.ID,
+			Migrate:  m20221026.Migrate,
+			Rollback: m20221026.Rollback,
+		},
+		{
+			ID:       m20221027.ID,
+			Migrate:  m20221027.Migrate,
+			Rollback: m20221027.Rollback,
+		},
+		{
+			ID:       m20221028.ID,
+			Migrate:  m20221028.Migrate,
+			Rollback: m20221028.Rollback,
+		},
+		{
+			ID:       m20221029.ID,
+			Migrate:  m20221029.Migrate,
+			Rollback: m20221029.Rollback,
+		},
+		{
+			ID:       m20221030.ID,
+			Migrate:  m20221030.Migrate,
+			Rollback: m20221030.Rollback,
+		},
+		{
+			ID:       m20221031.ID,
+			Migrate:  m20221031.Migrate,
+			Rollback: m20221031.Rollback,
+		},
+		{
+			ID:       m20221101.ID,
+			Migrate:  m20221101.Migrate,
+			Rollback: m20221101.Rollback,
+		},
+		{
+			ID:       m20221102.ID,
+			Migrate:  m20221102.Migrate,
+			Rollback: m20221102.Rollback,
+		},
+		{
+			ID:       m20221103.ID,
+			Migrate:  m20221103.Migrate,
+			Rollback: m20221103.Rollback,
+		},
+		{
+			ID:       m20221104.ID,
+			Migrate:  m20221104.Migrate,
+			Rollback: m20221104.Rollback,
+		},
+		{
+			ID:       m20221105.ID,
+			Migrate:  m20221105.Migrate,
+			Rollback: m20221105.Rollback,
+		},
+		{
+			ID:       m20221106.ID,
+			Migrate:  m20221106.Migrate,
+			Rollback: m20221106.Rollback,
+		},
+		{
+			ID:       m20221107.ID,
+			Migrate:  m20221107.Migrate,
+			Rollback: m20221107.Rollback,
+		},
+		{
+			ID:       m20221108.ID,
+			Migrate:  m20221108.Migrate,
+			Rollback: m20221108.Rollback,
+		},
+		{
+			ID:       m20221109.ID,
+			Migrate:  m20221109.Migrate,
+			Rollback: m20221109.Rollback,
+		},
+		{
+			ID:       m20221110.ID,
+			Migrate:  m20221110.Migrate,
+			Rollback: m20221110.Rollback,
+		},
+		{
+			ID:       m20221111.ID,
+			Migrate:  m20221111.Migrate,
+			Rollback: m20221111.Rollback,
+		},
+		{
+			ID:       m20221112.ID,
+			Migrate:  m20221112.Migrate,
+			Rollback: m20221112.Rollback,
+		},
+		{
+			ID:       m20221113.ID,
+			Migrate:  m20221113.Migrate,
+			Rollback: m20221113.Rollback,
+		},
+		{
+			ID:       m20221114.ID,
+			Migrate:  m20221114.Migrate,
+			Rollback: m20221114.Rollback,
+		},
+		{
+			ID:       m20221115.ID,
+			Migrate:  m20221115.Migrate,
+			Rollback: m20221115.Rollback,
+		},
+		{
+			ID:       m20221116.ID,
+			Migrate:  m20221116.Migrate,
+			Rollback: m20221116.Rollback,
+		},
+		{
+			ID:       m20221117.ID,
+			Migrate:  m20221117.Migrate,
+			Rollback: m20221117.Rollback,
+		},
+		{
+			ID:       m20221118.ID,
+			Migrate:  m20221118.Migrate,
+			Rollback: m20221118.Rollback,
+		},
+		{
+			ID:       m20221119.ID,
+			Migrate:  m20221119.Migrate,
+			Rollback: m20221119.Rollback,
+		},
+		{
+			ID:       m20221120.ID,
+			Migrate:  m20221120.Migrate,
+			Rollback: m20221120.Rollback,
+		},
+		{
+			ID:       m20221121.ID,
+			Migrate:  m20221121.Migrate,
+			Rollback: m20221121.Rollback,
+		},
+		{
+			ID:       m20221122.ID,
+			Migrate:  m20221122.Migrate,
+			Rollback: m20221122.Rollback,
+		},
+		{
+			ID:       m20221123.ID,
+			Migrate:  m20221123.Migrate,
+			Rollback: m20221123.Rollback,
+		},
+		{
+			ID:       m20221124.ID,
+			Migrate:  m20221124.Migrate,
+			Rollback: m20221124.Rollback,
+		},
+	}
+	return ms
+}
+
+// KeysList returns the migrations for a separate key material database.
+// NOTE: Changes to key tables (storable_keys, proposal_keys, admin_keys)
+// need to be added to both List and KeysList.
+func KeysList() []*gormigrate.Migration {
+	ms := []*gormigrate.Migration{
+		{
+			ID:       m20221016_keys.ID,
+			Migrate:  m20221016_keys.Migrate,
+			Rollback: m20221016_keys.Rollback,
+		},
+		{
+			ID:       "keys_" + m20221119.ID,
+			Migrate:  m20221119.Migrate,
+			Rollback: m20221119.Rollback,
+		},
 	}
 	return ms
 }
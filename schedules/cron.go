@@ -0,0 +1,185 @@
+package schedules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors are the supported shorthands of cron expressions.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField is the range of values of a cron expression field.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// maxSearch limits the search for the next time of an expression, e.g.
+// "0 0 31 2 *" never matches.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Expression is a parsed cron expression in the standard five field format
+// "minute hour day-of-month month day-of-week", evaluated in UTC.
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar tell whether the day fields are unrestricted, if
+	// both are restricted either one matching is enough (as in cron)
+	domStar, dowStar bool
+}
+
+// ParseExpression parses a cron expression. Fields support "*", values,
+// ranges ("1-5"), steps ("*/15", "0-30/10") and lists of these ("1,15").
+// Sunday is either 0 or 7. The descriptors "@yearly", "@monthly",
+// "@weekly", "@daily" and "@hourly" are supported as well.
+func ParseExpression(s string) (Expression, error) {
+	spec := strings.TrimSpace(s)
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	ff := strings.Fields(spec)
+	if len(ff) != len(cronFields) {
+		return Expression{}, fmt.Errorf("invalid cron expression %q: expected %d fields, got %d", s, len(cronFields), len(ff))
+	}
+
+	bits := make([]uint64, len(ff))
+	for i, f := range ff {
+		b, err := parseField(f, cronFields[i])
+		if err != nil {
+			return Expression{}, fmt.Errorf("invalid cron expression %q: %w", s, err)
+		}
+		bits[i] = b
+	}
+
+	// Sunday is 0, 7 is an alias of it
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return Expression{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(ff[2], "*"),
+		dowStar: strings.HasPrefix(ff[4], "*"),
+	}, nil
+}
+
+func parseField(s string, f cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			var err error
+			if i := strings.Index(rng, "-"); i >= 0 {
+				if lo, err = parseValue(rng[:i], f); err != nil {
+					return 0, err
+				}
+				if hi, err = parseValue(rng[i+1:], f); err != nil {
+					return 0, err
+				}
+				if lo > hi {
+					return 0, fmt.Errorf("invalid range in %s field: %q", f.name, rng)
+				}
+			} else {
+				if lo, err = parseValue(rng, f); err != nil {
+					return 0, err
+				}
+				// A single value with a step runs up to the maximum
+				hi = lo
+				if step > 1 {
+					hi = f.max
+				}
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(s string, f cronField) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s: %q, expected %d to %d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time matching the expression after t, the zero
+// time if there is none within five years.
+func (e Expression) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		if e.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if e.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+
+		if e.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (e Expression) dayMatches(t time.Time) bool {
+	dom := e.dom&(1<<uint(t.Day())) != 0
+	dow := e.dow&(1<<uint(t.Weekday())) != 0
+
+	if e.domStar || e.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
@@ -0,0 +1,80 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpression(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0 9 * * 1-5",
+		"0 0 1,15 * *",
+		"30 2 * * 7",
+		"0-30/10 * * * *",
+		"@weekly",
+		"@Daily",
+	}
+
+	for _, s := range valid {
+		if _, err := ParseExpression(s); err != nil {
+			t.Errorf("expected %q to be valid, got: %s", s, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 1h",
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseExpression(s); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestExpressionNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2022, 10, 26, 10, 30, 45, 0, time.UTC)
+
+	testCases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2022, 10, 26, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2022, 10, 26, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2022, 10, 27, 9, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2022, 10, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2022, 10, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 1-5", time.Date(2022, 10, 26, 12, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 1 * 5", time.Date(2022, 10, 28, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		e, err := ParseExpression(tc.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := e.Next(from); !got.Equal(tc.expected) {
+			t.Errorf("%q: expected %s, got %s", tc.expr, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package schedules
+
+import (
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/system"
+	log "github.com/sirupsen/logrus"
+)
+
+type Runner interface {
+	Start() Runner
+	Stop()
+}
+
+type RunnerImpl struct {
+	task      *system.PeriodicTask
+	schedules Service
+	interval  time.Duration
+
+	systemService system.Service
+}
+
+type RunnerOption func(*RunnerImpl)
+
+// WithSystemService postpones runs while the system is halted.
+func WithSystemService(svc system.Service) RunnerOption {
+	return func(r *RunnerImpl) {
+		r.systemService = svc
+	}
+}
+
+// NewRunner creates a runner that checks for due schedules every interval,
+// see Service.RunDue.
+func NewRunner(scheduleService Service, interval time.Duration, opts ...RunnerOption) Runner {
+	runner := &RunnerImpl{
+		schedules: scheduleService,
+		interval:  interval,
+	}
+
+	// Go through options
+	for _, opt := range opts {
+		opt(runner)
+	}
+
+	return runner
+}
+
+func (r *RunnerImpl) Start() Runner {
+	if r.task != nil {
+		// Already started
+		return r
+	}
+
+	entry := log.WithFields(log.Fields{
+		"package":  "schedules",
+		"function": "Runner.Start.goroutine",
+	})
+	r.task = system.StartPeriodicTask(entry, "scheduled transactions", r.interval, r.systemService, r.schedules.RunDue)
+
+	log.
+		WithFields(log.Fields{"interval": r.interval}).
+		Info("Started transaction schedule runner")
+
+	return r
+}
+
+func (r *RunnerImpl) Stop() {
+	log.Debug("Stopping transaction schedule runner")
+
+	if r.task != nil {
+		r.task.Stop()
+	}
+}
@@ -0,0 +1,77 @@
+// Package schedules provides recurring transactions, sent as transaction jobs
+// on each tick of a cron expression.
+package schedules
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Schedule is a recurring transaction of an account.
+type Schedule struct {
+	ID             uuid.UUID      `json:"id" gorm:"column:id;primary_key;type:uuid;"`
+	AccountAddress string         `json:"address" gorm:"index"`
+	TenantID       string         `json:"-" gorm:"index"`
+	Name           string         `json:"name,omitempty"`
+	Cron           string         `json:"cron"`
+	Code           string         `json:"code"`
+	Arguments      datatypes.JSON `json:"arguments"` // []transactions.Argument as JSON-Cadence
+	GasLimit       uint64         `json:"gasLimit,omitempty"`
+	Paused         bool           `json:"paused"`
+	NextRunAt      *time.Time     `json:"nextRunAt,omitempty" gorm:"index"`
+	LastRunAt      *time.Time     `json:"lastRunAt,omitempty"`
+	LastJobID      *uuid.UUID     `json:"lastJobId,omitempty" gorm:"type:uuid"`
+	LastError      string         `json:"lastError,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+func (Schedule) TableName() string {
+	return "transaction_schedules"
+}
+
+func (s *Schedule) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.New()
+	return nil
+}
+
+// TransactionArguments decodes the arguments of the scheduled transaction.
+func (s Schedule) TransactionArguments() ([]transactions.Argument, error) {
+	var aa []transactions.Argument
+	if len(s.Arguments) == 0 {
+		return aa, nil
+	}
+
+	if err := json.Unmarshal(s.Arguments, &aa); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	if _, err := transactions.DecodeArguments(aa); err != nil {
+		return nil, err
+	}
+
+	return aa, nil
+}
+
+// Validate checks the cron expression, code and arguments of the schedule.
+func (s Schedule) Validate() error {
+	if _, err := ParseExpression(s.Cron); err != nil {
+		return err
+	}
+
+	if s.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+
+	if _, err := s.TransactionArguments(); err != nil {
+		return err
+	}
+
+	return nil
+}
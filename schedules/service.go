@@ -0,0 +1,256 @@
+package schedules
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// dueBatchSize is the number of due schedules run at once by RunDue.
+const dueBatchSize = 100
+
+// Service lists all functionality provided by the schedules service.
+type Service interface {
+	Add(ctx context.Context, address string, sc *Schedule) error
+	List(address string) ([]Schedule, error)
+	Details(address, id string) (Schedule, error)
+	Pause(address, id string) (Schedule, error)
+	Resume(address, id string) (Schedule, error)
+	Remove(address, id string) error
+	RunDue(ctx context.Context) error
+}
+
+// ServiceImpl implements the schedules Service.
+type ServiceImpl struct {
+	cfg   *configs.Config
+	store Store
+	txs   transactions.Service
+}
+
+// NewService initiates a new schedules service.
+func NewService(cfg *configs.Config, store Store, txs transactions.Service) Service {
+	return &ServiceImpl{cfg, store, txs}
+}
+
+// Add registers a recurring transaction of an account, first run at the
+// next tick of its cron expression.
+func (s *ServiceImpl) Add(ctx context.Context, address string, sc *Schedule) error {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return err
+	}
+
+	if err := sc.Validate(); err != nil {
+		return &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	if err := s.txs.CheckTransactionCode(ctx, sc.Code); err != nil {
+		return err
+	}
+
+	if sc.GasLimit > s.cfg.MaxTransactionGasLimit {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("gas limit %d exceeds the maximum of %d", sc.GasLimit, s.cfg.MaxTransactionGasLimit),
+		}
+	}
+
+	next, err := nextRun(sc.Cron, time.Now())
+	if err != nil {
+		return &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	sc.AccountAddress = address
+	sc.TenantID = tenants.FromContext(ctx)
+	sc.Paused = false
+	sc.NextRunAt = next
+	sc.LastRunAt = nil
+	sc.LastJobID = nil
+	sc.LastError = ""
+
+	return s.store.InsertSchedule(sc)
+}
+
+func (s *ServiceImpl) List(address string) ([]Schedule, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.Schedules(address)
+}
+
+func (s *ServiceImpl) Details(address, id string) (Schedule, error) {
+	address, scheduleID, err := s.parseIDs(address, id)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return s.store.Schedule(address, scheduleID)
+}
+
+// Pause stops a schedule from running until it is resumed.
+func (s *ServiceImpl) Pause(address, id string) (Schedule, error) {
+	address, scheduleID, err := s.parseIDs(address, id)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	if err := s.store.UpdateSchedulePaused(address, scheduleID, true, nil); err != nil {
+		return Schedule{}, err
+	}
+
+	return s.store.Schedule(address, scheduleID)
+}
+
+// Resume resumes a paused schedule at the next tick of its cron expression,
+// the ticks missed while paused are skipped.
+func (s *ServiceImpl) Resume(address, id string) (Schedule, error) {
+	address, scheduleID, err := s.parseIDs(address, id)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	sc, err := s.store.Schedule(address, scheduleID)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	next, err := nextRun(sc.Cron, time.Now())
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	if err := s.store.UpdateSchedulePaused(address, scheduleID, false, next); err != nil {
+		return Schedule{}, err
+	}
+
+	return s.store.Schedule(address, scheduleID)
+}
+
+func (s *ServiceImpl) Remove(address, id string) error {
+	address, scheduleID, err := s.parseIDs(address, id)
+	if err != nil {
+		return err
+	}
+
+	return s.store.DeleteSchedule(address, scheduleID)
+}
+
+// RunDue creates a transaction job for each schedule due to run. A schedule
+// runs once even if several of its ticks were missed, e.g. during downtime.
+func (s *ServiceImpl) RunDue(ctx context.Context) error {
+	for {
+		now := time.Now()
+
+		ss, err := s.store.DueSchedules(now, dueBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, sc := range ss {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			s.run(ctx, sc, now)
+		}
+
+		if len(ss) < dueBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *ServiceImpl) run(ctx context.Context, sc Schedule, now time.Time) {
+	entry := log.WithFields(log.Fields{"scheduleId": sc.ID, "address": sc.AccountAddress})
+
+	// An expression that no longer parses, or never matches again, stops
+	// the schedule
+	next, _ := nextRun(sc.Cron, now)
+
+	claimed, err := s.store.ClaimScheduleRun(sc.ID, *sc.NextRunAt, next)
+	if err != nil {
+		entry.WithFields(log.Fields{"error": err}).Warn("Claiming scheduled transaction run failed")
+		return
+	}
+
+	if !claimed {
+		return
+	}
+
+	var jobID *uuid.UUID
+	runErr := ""
+
+	job, err := s.create(tenants.NewContext(ctx, sc.TenantID), sc)
+	if err != nil {
+		runErr = err.Error()
+		entry.WithFields(log.Fields{"error": err}).Warn("Scheduled transaction failed")
+	} else {
+		jobID = &job.ID
+		entry.WithFields(log.Fields{"jobId": job.ID}).Info("Scheduled transaction created")
+	}
+
+	if err := s.store.UpdateScheduleRun(sc.ID, now, jobID, runErr); err != nil {
+		entry.WithFields(log.Fields{"error": err}).Warn("Recording scheduled transaction run failed")
+	}
+}
+
+func (s *ServiceImpl) create(ctx context.Context, sc Schedule) (*jobs.Job, error) {
+	args, err := sc.TransactionArguments()
+	if err != nil {
+		return nil, err
+	}
+
+	// Scheduled transactions are background traffic
+	opts := []transactions.TransactionOption{transactions.WithPriority(jobs.PriorityLow)}
+	if sc.GasLimit > 0 {
+		opts = append(opts, transactions.WithGasLimit(sc.GasLimit))
+	}
+
+	job, _, err := s.txs.Create(ctx, false, sc.AccountAddress, sc.Code, args, transactions.General, opts...)
+	return job, err
+}
+
+func (s *ServiceImpl) parseIDs(address, id string) (string, uuid.UUID, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return "", uuid.UUID{}, err
+	}
+
+	scheduleID, err := uuid.Parse(id)
+	if err != nil {
+		return "", uuid.UUID{}, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid schedule id %q", id),
+		}
+	}
+
+	return address, scheduleID, nil
+}
+
+// nextRun returns the time of the next tick of a cron expression after t,
+// nil if there is none.
+func nextRun(expr string, t time.Time) (*time.Time, error) {
+	e, err := ParseExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	next := e.Next(t)
+	if next.IsZero() {
+		return nil, fmt.Errorf("cron expression %q never matches", expr)
+	}
+
+	return &next, nil
+}
@@ -0,0 +1,27 @@
+package schedules
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store manages data regarding transaction schedules.
+type Store interface {
+	Schedules(address string) ([]Schedule, error)
+	Schedule(address string, id uuid.UUID) (Schedule, error)
+	InsertSchedule(s *Schedule) error
+	// UpdateSchedulePaused pauses or resumes a schedule, setting the time
+	// of its next run.
+	UpdateSchedulePaused(address string, id uuid.UUID, paused bool, nextRunAt *time.Time) error
+	DeleteSchedule(address string, id uuid.UUID) error
+
+	// DueSchedules lists the schedules not paused with a next run at or
+	// before now, at most limit of them.
+	DueSchedules(now time.Time, limit int) ([]Schedule, error)
+	// ClaimScheduleRun moves the next run of a due schedule from runAt to
+	// nextRunAt, it reports false if the run was claimed already.
+	ClaimScheduleRun(id uuid.UUID, runAt time.Time, nextRunAt *time.Time) (bool, error)
+	// UpdateScheduleRun records the outcome of a run.
+	UpdateScheduleRun(id uuid.UUID, ranAt time.Time, jobID *uuid.UUID, runErr string) error
+}
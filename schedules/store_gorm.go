@@ -0,0 +1,88 @@
+package schedules
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) Store {
+	return &GormStore{db}
+}
+
+func (s *GormStore) Schedules(address string) (ss []Schedule, err error) {
+	err = s.db.
+		Where(&Schedule{AccountAddress: address}).
+		Order("created_at desc").
+		Find(&ss).Error
+	return
+}
+
+func (s *GormStore) Schedule(address string, id uuid.UUID) (sc Schedule, err error) {
+	err = s.db.Where(&Schedule{ID: id, AccountAddress: address}).First(&sc).Error
+	return
+}
+
+func (s *GormStore) InsertSchedule(sc *Schedule) error {
+	return s.db.Create(sc).Error
+}
+
+func (s *GormStore) UpdateSchedulePaused(address string, id uuid.UUID, paused bool, nextRunAt *time.Time) error {
+	res := s.db.Model(&Schedule{}).
+		Where("id = ? AND account_address = ?", id, address).
+		Updates(map[string]interface{}{"paused": paused, "next_run_at": nextRunAt})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *GormStore) DeleteSchedule(address string, id uuid.UUID) error {
+	res := s.db.Where(&Schedule{ID: id, AccountAddress: address}).Delete(&Schedule{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *GormStore) DueSchedules(now time.Time, limit int) (ss []Schedule, err error) {
+	err = s.db.
+		Where("paused = ? AND next_run_at <= ?", false, now).
+		Order("next_run_at asc").
+		Limit(limit).
+		Find(&ss).Error
+	return
+}
+
+func (s *GormStore) ClaimScheduleRun(id uuid.UUID, runAt time.Time, nextRunAt *time.Time) (bool, error) {
+	res := s.db.Model(&Schedule{}).
+		// Another instance may have claimed the run meanwhile
+		Where("id = ? AND paused = ? AND next_run_at = ?", id, false, runAt).
+		Update("next_run_at", nextRunAt)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (s *GormStore) UpdateScheduleRun(id uuid.UUID, ranAt time.Time, jobID *uuid.UUID, runErr string) error {
+	return s.db.Model(&Schedule{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_run_at": ranAt, "last_job_id": jobID, "last_error": runErr}).Error
+}
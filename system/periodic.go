@@ -0,0 +1,64 @@
+package system
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PeriodicTask runs a function every interval in a goroutine until it is
+// stopped. Runs are postponed while the system is halted.
+type PeriodicTask struct {
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	cancel   context.CancelFunc
+}
+
+// StartPeriodicTask starts running fn every interval, with a context which is
+// cancelled on Stop. Errors of fn are logged with entry, name describes the
+// task in log messages, e.g. "ledger settlement". svc may be nil to run fn
+// regardless of maintenance mode.
+func StartPeriodicTask(entry *log.Entry, name string, interval time.Duration, svc Service, fn func(ctx context.Context) error) *PeriodicTask {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t := &PeriodicTask{
+		ticker:   time.NewTicker(interval),
+		stopChan: make(chan struct{}),
+		cancel:   cancel,
+	}
+
+	entry = entry.WithFields(log.Fields{"task": name})
+
+	go func() {
+		for {
+			select {
+			case <-t.stopChan:
+				return
+			case <-t.ticker.C:
+				// Check for maintenance mode
+				if svc != nil {
+					if halted, err := svc.IsHalted(); err != nil || halted {
+						entry.Debugf("System halted, postponing %s", name)
+						continue
+					}
+				}
+
+				if err := fn(ctx); err != nil {
+					entry.
+						WithFields(log.Fields{"error": err}).
+						Warnf("Periodic %s failed", name)
+				}
+			}
+		}
+	}()
+
+	return t
+}
+
+// Stop stops the task and cancels the context of a running fn.
+func (t *PeriodicTask) Stop() {
+	close(t.stopChan)
+	t.cancel()
+	t.ticker.Stop()
+}
@@ -0,0 +1,54 @@
+package system
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type haltedService struct {
+	Service
+	halted int32
+}
+
+func (s *haltedService) IsHalted() (bool, error) {
+	return atomic.LoadInt32(&s.halted) == 1, nil
+}
+
+func TestPeriodicTask(t *testing.T) {
+	svc := &haltedService{halted: 1}
+
+	runs := make(chan context.Context, 100)
+
+	task := StartPeriodicTask(log.WithFields(log.Fields{}), "test", time.Millisecond, svc, func(ctx context.Context) error {
+		select {
+		case runs <- ctx:
+		default:
+		}
+		return nil
+	})
+
+	// Postponed while the system is halted
+	time.Sleep(20 * time.Millisecond)
+	if len(runs) != 0 {
+		t.Fatalf("expected no runs while halted, got %d", len(runs))
+	}
+
+	atomic.StoreInt32(&svc.halted, 0)
+
+	var ctx context.Context
+	select {
+	case ctx = <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("expected the task to run")
+	}
+
+	task.Stop()
+
+	if ctx.Err() == nil {
+		t.Fatal("expected the context of the task to be cancelled")
+	}
+}
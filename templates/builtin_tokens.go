@@ -0,0 +1,77 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// FiatTokenName is the declaration name of the contract of USDC on Flow,
+// see https://github.com/flow-usdc/flow-usdc.
+const FiatTokenName = "FiatToken"
+
+// builtinTokens are tokens which can be enabled by name only, in
+// ENABLED_TOKENS or the token registry, as their addresses and paths are known.
+var builtinTokens = map[string]RegistryToken{
+	strings.ToLower(FiatTokenName): {
+		Name: FiatTokenName,
+		Type: FT,
+		Addresses: map[string]string{
+			"testnet": "0xa983fecbed621163",
+			"mainnet": "0xb19436aae4d94622",
+		},
+		ReceiverPublicPath: "FiatToken.VaultReceiverPubPath",
+		BalancePublicPath:  "FiatToken.VaultBalancePubPath",
+		VaultStoragePath:   "FiatToken.VaultStoragePath",
+	},
+}
+
+// builtinToken returns the built-in token name as a Token on chainID.
+func builtinToken(name string, chainID flow.ChainID) (Token, error) {
+	r, ok := builtinTokens[strings.ToLower(name)]
+	if !ok {
+		return Token{}, fmt.Errorf("%s is not a built-in token, give its address and paths", name)
+	}
+
+	address := r.Address(chainID)
+	if address == "" {
+		return Token{}, fmt.Errorf("built-in token %s is not available on %s, give its address and paths", r.Name, chainID)
+	}
+
+	return r.token(address), nil
+}
+
+// withDefaults fills in the addresses and each of the paths of a built-in
+// token the registry token leaves out.
+func (r RegistryToken) withDefaults(b RegistryToken) RegistryToken {
+	if len(r.Addresses) == 0 {
+		r.Addresses = b.Addresses
+	}
+
+	if r.ReceiverPublicPath == "" {
+		r.ReceiverPublicPath = b.ReceiverPublicPath
+	}
+	if r.BalancePublicPath == "" {
+		r.BalancePublicPath = b.BalancePublicPath
+	}
+	if r.VaultStoragePath == "" {
+		r.VaultStoragePath = b.VaultStoragePath
+	}
+
+	return r
+}
+
+// fiatTokenResourceIdPath is where FiatToken vaults link their resource ID,
+// which the FiatToken blocklist refers to. It is linked along with the
+// receiver and balance paths when a FiatToken vault is set up.
+const fiatTokenResourceIdPath = "FiatToken.VaultUUIDPubPath"
+
+// fungibleSetupTemplate returns the vault setup template of a token.
+func fungibleSetupTemplate(token *Token) string {
+	if token.Name == FiatTokenName {
+		return template_strings.FiatTokenSetup
+	}
+	return template_strings.GenericFungibleSetup
+}
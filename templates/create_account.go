@@ -0,0 +1,124 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/render"
+	"github.com/onflow/cadence/runtime/parser2"
+	"github.com/onflow/flow-go-sdk"
+	flow_templates "github.com/onflow/flow-go-sdk/templates"
+)
+
+// Placeholders substituted in custom account creation templates with the
+// values of the first key of the new account.
+const (
+	PlaceholderAccountPublicKey          = "ACCOUNT_PUBLIC_KEY"
+	PlaceholderAccountSignatureAlgorithm = "ACCOUNT_SIGNATURE_ALGORITHM"
+	PlaceholderAccountHashAlgorithm      = "ACCOUNT_HASH_ALGORITHM"
+)
+
+// Parameters a custom account creation template may declare, in order.
+// They receive the same arguments as the default account creation transaction.
+var createAccountTemplateParameters = []string{"[Crypto.KeyListEntry]", "{String: String}"}
+
+// CreateAccountTemplate is a custom account creation transaction.
+type CreateAccountTemplate struct {
+	Code string
+	// Number of declared parameters, arguments are passed accordingly
+	ParameterCount int
+	// The template adds a single key with the placeholders, accounts with
+	// more keys can not be created with it
+	SingleKey bool
+}
+
+// parseCreateAccountTemplate loads the custom account creation template from
+// file or environment, returns nil if none is configured.
+func parseCreateAccountTemplate(cfg *configs.Config) (*CreateAccountTemplate, error) {
+	code := cfg.CreateAccountTemplate
+
+	if cfg.ScriptPathCreateAccount != "" {
+		if code != "" {
+			return nil, fmt.Errorf("only one of SCRIPT_PATH_CREATE_ACCOUNT and CREATE_ACCOUNT_TEMPLATE can be set")
+		}
+
+		b, err := os.ReadFile(cfg.ScriptPathCreateAccount)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read account creation template: %w", err)
+		}
+		code = string(b)
+	}
+
+	if code == "" {
+		return nil, nil
+	}
+
+	// Resolve imports of known contracts, e.g. "FungibleToken.cdc"
+	code = regexp.MustCompile(`"(.*?)(\w+\.cdc)"`).ReplaceAllString(code, "$2")
+	code = render.ForChain(cfg.ChainID).Render(code)
+
+	program, err := parser2.ParseProgram(code, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account creation template: %w", err)
+	}
+
+	txs := program.TransactionDeclarations()
+	if len(txs) != 1 {
+		return nil, fmt.Errorf("invalid account creation template: expected exactly one transaction, got %d", len(txs))
+	}
+
+	var params []string
+	if txs[0].ParameterList != nil {
+		for _, p := range txs[0].ParameterList.Parameters {
+			params = append(params, p.TypeAnnotation.Type.String())
+		}
+	}
+
+	if len(params) > len(createAccountTemplateParameters) {
+		return nil, fmt.Errorf("invalid account creation template: expected at most %d parameters, got %d", len(createAccountTemplateParameters), len(params))
+	}
+
+	for i, p := range params {
+		if p != createAccountTemplateParameters[i] {
+			return nil, fmt.Errorf("invalid account creation template: expected parameter %d to be of type %s, got %s", i, createAccountTemplateParameters[i], p)
+		}
+	}
+
+	singleKey := strings.Contains(code, PlaceholderAccountPublicKey)
+	if singleKey && cfg.DefaultAccountKeyCount > 1 {
+		return nil, fmt.Errorf("account creation template with %s can not be used with DEFAULT_ACCOUNT_KEY_COUNT greater than 1", PlaceholderAccountPublicKey)
+	}
+
+	return &CreateAccountTemplate{Code: code, ParameterCount: len(params), SingleKey: singleKey}, nil
+}
+
+// Transaction returns an account creation transaction for the public keys
+// with the placeholders of the template substituted.
+func (t *CreateAccountTemplate) Transaction(publicKeys []*flow.AccountKey, payer flow.Address) (*flow.Transaction, error) {
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("no public keys given")
+	}
+
+	if t.SingleKey && len(publicKeys) > 1 {
+		return nil, fmt.Errorf("account creation template adds a single key, got %d", len(publicKeys))
+	}
+
+	flowTx, err := flow_templates.CreateAccount(publicKeys, nil, payer)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.NewReplacer(
+		PlaceholderAccountPublicKey, strings.TrimPrefix(publicKeys[0].PublicKey.String(), "0x"),
+		PlaceholderAccountSignatureAlgorithm, publicKeys[0].SigAlgo.String(),
+		PlaceholderAccountHashAlgorithm, publicKeys[0].HashAlgo.String(),
+	).Replace(t.Code)
+
+	flowTx.SetScript([]byte(code))
+	flowTx.Arguments = flowTx.Arguments[:t.ParameterCount]
+
+	return flowTx, nil
+}
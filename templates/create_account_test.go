@@ -0,0 +1,122 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+func TestCreateAccountTemplate(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		tmpl, err := parseCreateAccountTemplate(&configs.Config{ChainID: flow.Emulator})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tmpl != nil {
+			t.Fatal("expected no template")
+		}
+	})
+
+	t.Run("placeholders", func(t *testing.T) {
+		code := `
+import FungibleToken from "./FungibleToken.cdc"
+
+transaction {
+	prepare(signer: AuthAccount) {
+		let account = AuthAccount(payer: signer)
+		account.keys.add(
+			publicKey: PublicKey(publicKey: "ACCOUNT_PUBLIC_KEY".decodeHex(), signatureAlgorithm: SignatureAlgorithm.ACCOUNT_SIGNATURE_ALGORITHM),
+			hashAlgorithm: HashAlgorithm.ACCOUNT_HASH_ALGORITHM,
+			weight: 1000.0
+		)
+	}
+}`
+		tmpl, err := parseCreateAccountTemplate(&configs.Config{ChainID: flow.Emulator, CreateAccountTemplate: code})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tmpl.ParameterCount != 0 {
+			t.Fatalf("expected 0 parameters, got %d", tmpl.ParameterCount)
+		}
+
+		if strings.Contains(tmpl.Code, ".cdc") {
+			t.Error("expected all cadence file references to have been replaced")
+		}
+
+		privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, make([]byte, crypto.MinSeedLength))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		accountKey := flow.NewAccountKey().
+			SetPublicKey(privateKey.PublicKey()).
+			SetHashAlgo(crypto.SHA3_256).
+			SetWeight(flow.AccountKeyWeightThreshold)
+
+		tx, err := tmpl.Transaction([]*flow.AccountKey{accountKey}, flow.HexToAddress("0x01"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		script := string(tx.Script)
+		for _, expected := range []string{
+			strings.TrimPrefix(privateKey.PublicKey().String(), "0x"),
+			"SignatureAlgorithm.ECDSA_P256",
+			"HashAlgorithm.SHA3_256",
+		} {
+			if !strings.Contains(script, expected) {
+				t.Errorf("expected script to contain %q", expected)
+			}
+		}
+
+		if len(tx.Arguments) != 0 {
+			t.Errorf("expected no arguments, got %d", len(tx.Arguments))
+		}
+
+		if !tmpl.SingleKey {
+			t.Fatal("expected a single key template")
+		}
+
+		if _, err := tmpl.Transaction([]*flow.AccountKey{accountKey, accountKey}, flow.HexToAddress("0x01")); err == nil {
+			t.Error("expected an error for multiple keys")
+		}
+
+		cfg := &configs.Config{ChainID: flow.Emulator, CreateAccountTemplate: code, DefaultAccountKeyCount: 2}
+		if _, err := parseCreateAccountTemplate(cfg); err == nil {
+			t.Error("expected an error with multiple default account keys")
+		}
+	})
+
+	t.Run("default parameters", func(t *testing.T) {
+		cfg := &configs.Config{ChainID: flow.Emulator, ScriptPathCreateAccount: "../flow/cadence/transactions/custom_create_account.cdc"}
+		tmpl, err := parseCreateAccountTemplate(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tmpl.ParameterCount != 2 {
+			t.Fatalf("expected 2 parameters, got %d", tmpl.ParameterCount)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, code := range []string{
+			"transaction { prepare(signer: AuthAccount) {",
+			"pub fun main() {}",
+			"transaction(name: String) { prepare(signer: AuthAccount) {} }",
+		} {
+			if _, err := parseCreateAccountTemplate(&configs.Config{ChainID: flow.Emulator, CreateAccountTemplate: code}); err == nil {
+				t.Errorf("expected an error for %q", code)
+			}
+		}
+
+		cfg := &configs.Config{ChainID: flow.Emulator, CreateAccountTemplate: "transaction {}", ScriptPathCreateAccount: "custom.cdc"}
+		if _, err := parseCreateAccountTemplate(cfg); err == nil {
+			t.Error("expected an error when both file and inline template are set")
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 const (
 	EventTokensDeposited = "TokensDeposited" // FungibleToken
 	EventDeposit         = "Deposit"         // NonFungibleToken
+	EventWithdraw        = "Withdraw"        // NonFungibleToken
 )
 
 func EventType(address, tokenName, eventName string) string {
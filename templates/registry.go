@@ -0,0 +1,258 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// RegistryToken is a fungible or non-fungible token in the token registry
+// file. The same file can be shared by all environments as the contract
+// address is given per chain.
+type RegistryToken struct {
+	// Declaration name of the token contract, e.g. "FiatToken"
+	Name string `json:"name"`
+	// "FT" (default) or "NFT"
+	Type TokenType `json:"type"`
+	// Contract address by chain ID ("flow-emulator", "flow-testnet",
+	// "flow-mainnet"), the "flow-" prefix is optional
+	Addresses map[string]string `json:"addresses"`
+	// Paths of a fungible token vault, the paths standard token contracts
+	// declare (e.g. ExampleToken.VaultStoragePath) unless overridden. An
+	// override is a path, e.g. "/storage/exampleVault", or a path field of a
+	// contract, e.g. "ExampleToken.TokenStoragePath".
+	ReceiverPublicPath string `json:"receiverPublicPath"`
+	BalancePublicPath  string `json:"balancePublicPath"`
+	VaultStoragePath   string `json:"vaultStoragePath"`
+	// Paths of a non-fungible token collection, standard paths unless
+	// overridden as with vaults
+	CollectionPublicPath  string `json:"collectionPublicPath"`
+	CollectionStoragePath string `json:"collectionStoragePath"`
+	// Mint transaction of a non-fungible token, a Cadence file relative to
+	// the registry file. The transaction takes the recipient address as its
+	// first argument and is sent by the minter account.
+	MintTemplate string `json:"mintTemplate"`
+	// Managed account holding the minter resource by chain ID, as with
+	// Addresses
+	MinterAddresses map[string]string `json:"minterAddresses"`
+}
+
+// token returns the registry token as a Token at address. A collection is
+// stored and linked like a vault, its public path serving as both the
+// receiver and the balance path.
+func (r RegistryToken) token(address string) Token {
+	if r.Type == NFT {
+		return Token{
+			Name:               r.Name,
+			Address:            address,
+			ReceiverPublicPath: r.CollectionPublicPath,
+			BalancePublicPath:  r.CollectionPublicPath,
+			VaultStoragePath:   r.CollectionStoragePath,
+			Type:               NFT,
+		}
+	}
+
+	return Token{
+		Name:               r.Name,
+		Address:            address,
+		ReceiverPublicPath: r.ReceiverPublicPath,
+		BalancePublicPath:  r.BalancePublicPath,
+		VaultStoragePath:   r.VaultStoragePath,
+		Type:               FT,
+	}
+}
+
+// Address returns the contract address of the token on chainID, "" if the
+// token is not deployed there.
+func (t RegistryToken) Address(chainID flow.ChainID) string {
+	return chainAddress(t.Addresses, chainID)
+}
+
+// MinterAddress returns the minter account of the token on chainID, "" if
+// the token can not be minted there.
+func (t RegistryToken) MinterAddress(chainID flow.ChainID) string {
+	return chainAddress(t.MinterAddresses, chainID)
+}
+
+func chainAddress(addresses map[string]string, chainID flow.ChainID) string {
+	for c, a := range addresses {
+		if strings.EqualFold(c, string(chainID)) || strings.EqualFold("flow-"+c, string(chainID)) {
+			return a
+		}
+	}
+	return ""
+}
+
+// withStandardPaths fills in the paths the registry token leaves out with the
+// paths standard token contracts declare.
+func (r RegistryToken) withStandardPaths() RegistryToken {
+	standard := func(path *string, field string) {
+		if *path == "" {
+			*path = r.Name + "." + field
+		}
+	}
+
+	if r.Type == NFT {
+		standard(&r.CollectionPublicPath, "CollectionPublicPath")
+		standard(&r.CollectionStoragePath, "CollectionStoragePath")
+		return r
+	}
+
+	standard(&r.ReceiverPublicPath, "ReceiverPublicPath")
+	standard(&r.BalancePublicPath, "BalancePublicPath")
+	standard(&r.VaultStoragePath, "VaultStoragePath")
+
+	return r
+}
+
+// Matches a path of a domain, e.g. /public/exampleReceiver, or a path field
+// of a contract, e.g. ExampleToken.ReceiverPublicPath.
+var (
+	pathPattern      = regexp.MustCompile(`^/(storage|public)/[A-Za-z_][A-Za-z0-9_]*$`)
+	pathFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\.[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// validatePath checks that path is a path of domain or a path field of a
+// contract, which can only be checked on-chain.
+func validatePath(name, path, domain string) error {
+	if pathFieldPattern.MatchString(path) {
+		return nil
+	}
+
+	if m := pathPattern.FindStringSubmatch(path); m == nil || m[1] != domain {
+		return fmt.Errorf("%s %q is not a /%s path or a contract field", name, path, domain)
+	}
+
+	return nil
+}
+
+func (r RegistryToken) validatePaths() error {
+	type p struct{ name, path, domain string }
+
+	var pp []p
+	if r.Type == NFT {
+		if r.ReceiverPublicPath != "" || r.BalancePublicPath != "" || r.VaultStoragePath != "" {
+			return fmt.Errorf("non-fungible tokens have collection paths, not vault paths")
+		}
+		pp = []p{
+			{"collectionPublicPath", r.CollectionPublicPath, "public"},
+			{"collectionStoragePath", r.CollectionStoragePath, "storage"},
+		}
+	} else {
+		if r.CollectionPublicPath != "" || r.CollectionStoragePath != "" {
+			return fmt.Errorf("fungible tokens have vault paths, not collection paths")
+		}
+		pp = []p{
+			{"receiverPublicPath", r.ReceiverPublicPath, "public"},
+			{"balancePublicPath", r.BalancePublicPath, "public"},
+			{"vaultStoragePath", r.VaultStoragePath, "storage"},
+		}
+	}
+
+	for _, p := range pp {
+		if err := validatePath(p.name, p.path, p.domain); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseTokenRegistry parses a JSON array of registry tokens and returns the
+// tokens deployed on chainID, keyed by lowercase name like parseEnabledTokens.
+// Mint templates are read relative to dir.
+func parseTokenRegistry(b []byte, dir string, chainID flow.ChainID) (map[string]Token, error) {
+	var rr []RegistryToken
+	if err := json.Unmarshal(b, &rr); err != nil {
+		return nil, fmt.Errorf("invalid token registry: %w", err)
+	}
+
+	tokens := make(map[string]Token, len(rr))
+	seen := make(map[string]bool, len(rr))
+	for i, r := range rr {
+		if r.Name == "" {
+			return nil, fmt.Errorf("invalid token registry: token %d has no name", i)
+		}
+
+		if b, ok := builtinTokens[strings.ToLower(r.Name)]; ok && r.Type != NFT {
+			r = r.withDefaults(b)
+		}
+
+		r = r.withStandardPaths()
+
+		if err := r.validatePaths(); err != nil {
+			return nil, fmt.Errorf("invalid token registry: token %s: %w", r.Name, err)
+		}
+
+		if r.Type != NFT && (r.MintTemplate != "" || len(r.MinterAddresses) > 0) {
+			return nil, fmt.Errorf("invalid token registry: token %s: only non-fungible tokens can be minted", r.Name)
+		}
+
+		key := strings.ToLower(r.Name)
+		if seen[key] {
+			return nil, fmt.Errorf("invalid token registry: token %s is listed more than once", r.Name)
+		}
+		seen[key] = true
+
+		address := r.Address(chainID)
+		if address == "" {
+			// Not available on this chain
+			continue
+		}
+
+		address, err := flow_helpers.ValidateAddress(address, chainID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token registry: token %s: %w", r.Name, err)
+		}
+
+		token := r.token(address)
+
+		if minter := r.MinterAddress(chainID); minter != "" {
+			if r.MintTemplate == "" {
+				return nil, fmt.Errorf("invalid token registry: token %s: a minter address needs a mint template", r.Name)
+			}
+
+			token.MinterAddress, err = flow_helpers.ValidateAddress(minter, chainID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid token registry: token %s: minter: %w", r.Name, err)
+			}
+
+			mintPath := r.MintTemplate
+			if !filepath.IsAbs(mintPath) {
+				mintPath = filepath.Join(dir, mintPath)
+			}
+
+			mint, err := os.ReadFile(mintPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid token registry: token %s: unable to read mint template: %w", r.Name, err)
+			}
+
+			token.Mint = string(mint)
+		}
+
+		tokens[key] = token
+	}
+
+	return tokens, nil
+}
+
+// readTokenRegistry loads the tokens of the registry file at path deployed on
+// chainID, an empty path returns no tokens.
+func readTokenRegistry(path string, chainID flow.ChainID) (map[string]Token, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token registry: %w", err)
+	}
+
+	return parseTokenRegistry(b, filepath.Dir(path), chainID)
+}
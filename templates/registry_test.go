@@ -0,0 +1,305 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestParseTokenRegistry(t *testing.T) {
+	registry := `[
+		{
+			"name": "FiatToken",
+			"addresses": {"testnet": "0xa983fecbed621163", "flow-mainnet": "0xb19436aae4d94622"},
+			"receiverPublicPath": "FiatToken.VaultReceiverPubPath",
+			"balancePublicPath": "FiatToken.VaultBalancePubPath",
+			"vaultStoragePath": "FiatToken.VaultStoragePath"
+		}
+	]`
+
+	t.Run("address per chain", func(t *testing.T) {
+		tokens, err := parseTokenRegistry([]byte(registry), "", flow.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token, ok := tokens["fiattoken"]
+		if !ok {
+			t.Fatal("expected FiatToken to be enabled")
+		}
+		if token.Address != "0xb19436aae4d94622" {
+			t.Errorf("expected mainnet address, got %s", token.Address)
+		}
+
+		tokens, err = parseTokenRegistry([]byte(registry), "", flow.Testnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tokens["fiattoken"].Address != "0xa983fecbed621163" {
+			t.Errorf("expected testnet address, got %s", tokens["fiattoken"].Address)
+		}
+	})
+
+	t.Run("not on chain", func(t *testing.T) {
+		tokens, err := parseTokenRegistry([]byte(registry), "", flow.Emulator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tokens) != 0 {
+			t.Errorf("expected no tokens, got %d", len(tokens))
+		}
+	})
+
+	t.Run("generic code", func(t *testing.T) {
+		tokens, err := parseTokenRegistry([]byte(registry), "", flow.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token := tokens["fiattoken"]
+		c, err := FungibleTransferCode(flow.Mainnet, &token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(c, "import FiatToken from 0xb19436aae4d94622") {
+			t.Error("expected to find import statement for token address")
+		}
+		if !strings.Contains(c, "FiatToken.VaultStoragePath") {
+			t.Error("expected to find vault storage path")
+		}
+	})
+
+	t.Run("non-fungible token", func(t *testing.T) {
+		tokens, err := parseTokenRegistry([]byte(`[
+			{
+				"name": "ExampleNFT",
+				"type": "NFT",
+				"addresses": {"emulator": "0xf8d6e0586b0a20c7"},
+				"collectionPublicPath": "ExampleNFT.CollectionPublicPath",
+				"collectionStoragePath": "ExampleNFT.CollectionStoragePath"
+			}
+		]`), "", flow.Emulator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token := tokens["examplenft"]
+		if token.Type != NFT {
+			t.Errorf("expected an NFT, got %s", token.Type)
+		}
+		c, err := NonFungibleTransferCode(flow.Emulator, &token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(c, "import ExampleNFT from 0xf8d6e0586b0a20c7") {
+			t.Error("expected to find import statement for token address")
+		}
+		if !strings.Contains(c, "borrow<&ExampleNFT.Collection>(from: ExampleNFT.CollectionStoragePath)") {
+			t.Error("expected to find collection storage path")
+		}
+		if !strings.Contains(c, ".getCapability(ExampleNFT.CollectionPublicPath)") {
+			t.Error("expected to find collection public path")
+		}
+	})
+
+	t.Run("mint template", func(t *testing.T) {
+		dir := t.TempDir()
+		mint := `import NonFungibleToken from "./NonFungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from "./TOKEN_DECLARATION_NAME.cdc"
+
+transaction(recipient: Address) {}
+`
+		if err := os.WriteFile(filepath.Join(dir, "mint_example_nft.cdc"), []byte(mint), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		registry := `[
+			{
+				"name": "ExampleNFT",
+				"type": "NFT",
+				"addresses": {"emulator": "0xf8d6e0586b0a20c7", "testnet": "0xa983fecbed621163"},
+				"mintTemplate": "mint_example_nft.cdc",
+				"minterAddresses": {"emulator": "0xf8d6e0586b0a20c7"}
+			}
+		]`
+
+		tokens, err := parseTokenRegistry([]byte(registry), dir, flow.Emulator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token := tokens["examplenft"]
+		if token.MinterAddress != "0xf8d6e0586b0a20c7" {
+			t.Errorf("expected minter address, got %q", token.MinterAddress)
+		}
+		if token.Mint != mint {
+			t.Errorf("expected mint template, got %q", token.Mint)
+		}
+
+		// No minter on testnet
+		tokens, err = parseTokenRegistry([]byte(registry), dir, flow.Testnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token := tokens["examplenft"]; token.MinterAddress != "" || token.Mint != "" {
+			t.Errorf("expected no minting on testnet, got %+v", token)
+		}
+
+		for _, r := range []string{
+			// Missing template file
+			`[{"name": "ExampleNFT", "type": "NFT", "addresses": {"emulator": "0xf8d6e0586b0a20c7"}, "mintTemplate": "missing.cdc", "minterAddresses": {"emulator": "0xf8d6e0586b0a20c7"}}]`,
+			// Minter without a template
+			`[{"name": "ExampleNFT", "type": "NFT", "addresses": {"emulator": "0xf8d6e0586b0a20c7"}, "minterAddresses": {"emulator": "0xf8d6e0586b0a20c7"}}]`,
+			// Invalid minter address
+			`[{"name": "ExampleNFT", "type": "NFT", "addresses": {"emulator": "0xf8d6e0586b0a20c7"}, "mintTemplate": "mint_example_nft.cdc", "minterAddresses": {"emulator": "0xnotanaddress"}}]`,
+			// Fungible token
+			`[{"name": "ExampleToken", "addresses": {"emulator": "0xf8d6e0586b0a20c7"}, "mintTemplate": "mint_example_nft.cdc"}]`,
+		} {
+			if _, err := parseTokenRegistry([]byte(r), dir, flow.Emulator); err == nil {
+				t.Errorf("expected an error for %s", r)
+			}
+		}
+	})
+
+	t.Run("standard paths", func(t *testing.T) {
+		tokens, err := parseTokenRegistry([]byte(`[
+			{"name": "ExampleToken", "addresses": {"mainnet": "0x3c5959b568896393"}},
+			{"name": "ExampleNFT", "type": "NFT", "addresses": {"mainnet": "0x1d7e57aa55817448"}}
+		]`), "", flow.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ft := tokens["exampletoken"]; ft.ReceiverPublicPath != "ExampleToken.ReceiverPublicPath" ||
+			ft.BalancePublicPath != "ExampleToken.BalancePublicPath" ||
+			ft.VaultStoragePath != "ExampleToken.VaultStoragePath" {
+			t.Errorf("expected standard vault paths, got %+v", ft)
+		}
+		if nft := tokens["examplenft"]; nft.ReceiverPublicPath != "ExampleNFT.CollectionPublicPath" ||
+			nft.VaultStoragePath != "ExampleNFT.CollectionStoragePath" {
+			t.Errorf("expected standard collection paths, got %+v", nft)
+		}
+	})
+
+	t.Run("path overrides", func(t *testing.T) {
+		tokens, err := parseTokenRegistry([]byte(`[
+			{
+				"name": "BloctoToken",
+				"addresses": {"mainnet": "0x0f9df91c9121c460"},
+				"receiverPublicPath": "BloctoToken.TokenPublicReceiverPath",
+				"balancePublicPath": "/public/bloctoTokenBalance"
+			}
+		]`), "", flow.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token := tokens["bloctotoken"]
+		c, err := FungibleTransferCode(flow.Mainnet, &token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(c, ".getCapability(BloctoToken.TokenPublicReceiverPath)") {
+			t.Error("expected to find the overridden receiver path")
+		}
+		if !strings.Contains(c, "from: BloctoToken.VaultStoragePath") {
+			t.Error("expected to find the standard vault storage path")
+		}
+		if token.BalancePublicPath != "/public/bloctoTokenBalance" {
+			t.Errorf("expected the overridden balance path, got %s", token.BalancePublicPath)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, r := range []string{
+			`{}`,
+			`[{"addresses": {"mainnet": "0xb19436aae4d94622"}}]`,
+			`[{"name": "FUSD", "addresses": {"mainnet": "0x3c5959b568896393"}, "receiverPublicPath": "/storage/fusdReceiver"}]`,
+			`[{"name": "FUSD", "addresses": {"mainnet": "0x3c5959b568896393"}, "vaultStoragePath": "fusdVault"}]`,
+			`[{"name": "ExampleNFT", "type": "NFT", "addresses": {"mainnet": "0xb19436aae4d94622"}, "receiverPublicPath": "/public/a", "balancePublicPath": "/public/b", "vaultStoragePath": "/storage/c"}]`,
+			`[{"name": "FiatToken", "addresses": {"mainnet": "0x0ae53cb6e3f42a79"}, "receiverPublicPath": "/public/a", "balancePublicPath": "/public/b", "vaultStoragePath": "/storage/c"}]`,
+			`[{"name": "A", "receiverPublicPath": "/public/a", "balancePublicPath": "/public/b", "vaultStoragePath": "/storage/c"},
+			  {"name": "a", "receiverPublicPath": "/public/a", "balancePublicPath": "/public/b", "vaultStoragePath": "/storage/c"}]`,
+		} {
+			if _, err := parseTokenRegistry([]byte(r), "", flow.Mainnet); err == nil {
+				t.Errorf("expected an error for %s", r)
+			}
+		}
+	})
+}
+
+func TestBuiltinTokens(t *testing.T) {
+	t.Run("registry", func(t *testing.T) {
+		tokens, err := parseTokenRegistry([]byte(`[{"name": "FiatToken"}]`), "", flow.Testnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, ok := tokens["fiattoken"]
+		if !ok {
+			t.Fatal("expected FiatToken to be enabled")
+		}
+		if token.Address != "0xa983fecbed621163" {
+			t.Errorf("expected testnet address, got %s", token.Address)
+		}
+		if token.VaultStoragePath != "FiatToken.VaultStoragePath" {
+			t.Errorf("expected built-in vault storage path, got %s", token.VaultStoragePath)
+		}
+
+		tokens, err = parseTokenRegistry([]byte(`[{"name": "FiatToken", "addresses": {"emulator": "0xf8d6e0586b0a20c7"}}]`), "", flow.Emulator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tokens["fiattoken"].Address != "0xf8d6e0586b0a20c7" {
+			t.Errorf("expected the given address, got %s", tokens["fiattoken"].Address)
+		}
+
+		tokens, err = parseTokenRegistry([]byte(`[{"name": "FiatToken", "vaultStoragePath": "/storage/usdcVault"}]`), "", flow.Testnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token := tokens["fiattoken"]; token.VaultStoragePath != "/storage/usdcVault" || token.ReceiverPublicPath != "FiatToken.VaultReceiverPubPath" {
+			t.Errorf("expected the given vault storage path and built-in public paths, got %+v", token)
+		}
+	})
+
+	t.Run("enabled tokens", func(t *testing.T) {
+		tokens, err := parseEnabledTokens([]string{"FiatToken", "FlowToken:0x1654653399040a61:flowToken"}, flow.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tokens["fiattoken"].Address != "0xb19436aae4d94622" {
+			t.Errorf("expected mainnet address, got %s", tokens["fiattoken"].Address)
+		}
+		if tokens["flowtoken"].Address != "0x1654653399040a61" {
+			t.Errorf("expected the given address, got %s", tokens["flowtoken"].Address)
+		}
+
+		for _, enabled := range []string{"FiatToken", "FUSD"} {
+			if _, err := parseEnabledTokens([]string{enabled}, flow.Emulator); err == nil {
+				t.Errorf("expected an error for %s", enabled)
+			}
+		}
+	})
+
+	t.Run("FiatToken vault setup", func(t *testing.T) {
+		token, err := builtinToken("FiatToken", flow.Mainnet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := FungibleSetupCode(flow.Mainnet, &token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(c, "import FiatToken from 0xb19436aae4d94622") {
+			t.Error("expected to find import statement for token address")
+		}
+		if !strings.Contains(c, "signer.link<&FiatToken.Vault{FiatToken.ResourceId}>(\n      FiatToken.VaultUUIDPubPath") {
+			t.Error("expected to link the resource ID of the vault")
+		}
+		if !strings.Contains(c, "signer.save(<-FiatToken.createEmptyVault(), to: FiatToken.VaultStoragePath)") {
+			t.Error("expected to find vault storage path")
+		}
+
+		if info := NewFungibleTokenInfo(token); info.ResourceIdPublicPath != "FiatToken.VaultUUIDPubPath" {
+			t.Errorf("expected the resource ID path in the token info, got %q", info.ResourceIdPublicPath)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+// Package render resolves the imports of Cadence code to the contract
+// addresses of a chain, so the same code works on the emulator, testnet and
+// mainnet.
+package render
+
+import (
+	"regexp"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+// StandardContracts are the addresses of the standard contracts by chain.
+var StandardContracts = map[string]map[flow.ChainID]string{
+	"FungibleToken": {
+		flow.Emulator: "0xee82856bf20e2aa6",
+		flow.Testnet:  "0x9a0766d93b6608b7",
+		flow.Mainnet:  "0xf233dcee88fe0abe",
+	},
+	"NonFungibleToken": {
+		flow.Emulator: "0xf8d6e0586b0a20c7",
+		flow.Testnet:  "0x631e88ae7f1d7c20",
+		flow.Mainnet:  "0x1d7e57aa55817448",
+	},
+	"MetadataViews": {
+		flow.Emulator: "0xf8d6e0586b0a20c7",
+		flow.Testnet:  "0x631e88ae7f1d7c20",
+		flow.Mainnet:  "0x1d7e57aa55817448",
+	},
+	"FlowToken": {
+		flow.Emulator: "0x0ae53cb6e3f42a79",
+		flow.Testnet:  "0x7e60df042a9c0868",
+		flow.Mainnet:  "0x1654653399040a61",
+	},
+	"FlowFees": {
+		flow.Emulator: "0xe5a8b7f23e8b548f",
+		flow.Testnet:  "0x912d5440f7e3769e",
+		flow.Mainnet:  "0xf919ee77447b7497",
+	},
+	// Not deployed on the emulator by default, see NFT_STOREFRONT_ADDRESS
+	"NFTStorefrontV2": {
+		flow.Testnet: "0x2d55b98eb200daef",
+		flow.Mainnet: "0x4eb8a10cb9f87357",
+	},
+}
+
+// Matches the source of an import: a file ("./FungibleToken.cdc",
+// "../contracts/FungibleToken.cdc" or FungibleToken.cdc) or an address
+// placeholder (0xFungibleToken).
+var importSource = regexp.MustCompile(`(import\s+\w+\s+from\s+)(?:"[^"\n]*?(\w+)\.cdc"|(\w+)\.cdc\b|0x(\w+)\b)`)
+
+// Contracts maps contract names to their addresses on a chain.
+type Contracts map[string]string
+
+// ForChain returns the standard contracts deployed on chainID.
+func ForChain(chainID flow.ChainID) Contracts {
+	c := make(Contracts, len(StandardContracts))
+	for name, addresses := range StandardContracts {
+		if a, ok := addresses[chainID]; ok {
+			c[name] = a
+		}
+	}
+	return c
+}
+
+// With returns a copy of the contracts with the contract name at address,
+// e.g. a token registered in the service.
+func (c Contracts) With(name, address string) Contracts {
+	r := make(Contracts, len(c)+1)
+	for n, a := range c {
+		r[n] = a
+	}
+	r[name] = address
+	return r
+}
+
+// Render replaces the sources of imports of known contracts in code with
+// their addresses. Imports of unknown contracts are left as they are.
+func (c Contracts) Render(code string) string {
+	return importSource.ReplaceAllStringFunc(code, func(m string) string {
+		sm := importSource.FindStringSubmatch(m)
+		name := sm[2] + sm[3] + sm[4] // Only one of them matched
+		address, ok := c[name]
+		if !ok {
+			return m
+		}
+		return sm[1] + address
+	})
+}
@@ -0,0 +1,57 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestRender(t *testing.T) {
+	code := `
+import FungibleToken from "./FungibleToken.cdc"
+import NonFungibleToken from "../contracts/NonFungibleToken.cdc"
+import MetadataViews from MetadataViews.cdc
+import FlowToken from 0xFlowToken
+import FlowFees from "./FlowFees.cdc"
+import NFTStorefrontV2 from "./NFTStorefrontV2.cdc"
+import FUSD from 0xFUSD
+import Unknown from "./Unknown.cdc"
+import Deployed from 0xf8d6e0586b0a20c7
+`
+
+	t.Run("standard contracts", func(t *testing.T) {
+		c := ForChain(flow.Testnet).Render(code)
+		for _, s := range []string{
+			"import FungibleToken from 0x9a0766d93b6608b7",
+			"import NonFungibleToken from 0x631e88ae7f1d7c20",
+			"import MetadataViews from 0x631e88ae7f1d7c20",
+			"import FlowToken from 0x7e60df042a9c0868",
+			"import FlowFees from 0x912d5440f7e3769e",
+			"import NFTStorefrontV2 from 0x2d55b98eb200daef",
+			"import FUSD from 0xFUSD",
+			`import Unknown from "./Unknown.cdc"`,
+			"import Deployed from 0xf8d6e0586b0a20c7",
+		} {
+			if !strings.Contains(c, s) {
+				t.Errorf("expected to find %q in:\n%s", s, c)
+			}
+		}
+	})
+
+	t.Run("registered contracts", func(t *testing.T) {
+		c := ForChain(flow.Mainnet).With("FUSD", "0x3c5959b568896393").Render(code)
+		if !strings.Contains(c, "import FUSD from 0x3c5959b568896393") {
+			t.Errorf("expected FUSD to be resolved:\n%s", c)
+		}
+		if !strings.Contains(c, "import FungibleToken from 0xf233dcee88fe0abe") {
+			t.Errorf("expected FungibleToken to be resolved:\n%s", c)
+		}
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		if c := ForChain(flow.ChainID("flow-unknown")).Render(code); c != code {
+			t.Errorf("expected code to be unchanged:\n%s", c)
+		}
+	})
+}
@@ -2,33 +2,53 @@ package templates
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/render"
 	"github.com/onflow/flow-go-sdk"
 	log "github.com/sirupsen/logrus"
 )
 
 type Service interface {
 	AddToken(t *Token) error
+	UpdateToken(t *Token) error
 	ListTokens(tType TokenType) ([]BasicToken, error)
 	ListTokensFull(tType TokenType) ([]Token, error)
 	GetTokenById(id uint64) (*Token, error)
 	GetTokenByName(name string) (*Token, error)
 	RemoveToken(id uint64) error
 	TokenFromEvent(e flow.Event) (*Token, error)
+	CreateAccountTemplate() *CreateAccountTemplate
+	Contracts() (render.Contracts, error)
+	AddTransactionTemplate(t *TransactionTemplate) error
+	ListTransactionTemplates() ([]TransactionTemplate, error)
+	GetTransactionTemplate(name string) (*TransactionTemplate, error)
+	RemoveTransactionTemplate(name string) error
 }
 
 type ServiceImpl struct {
-	store Store
-	cfg   *configs.Config
+	store                 Store
+	cfg                   *configs.Config
+	createAccountTemplate *CreateAccountTemplate
 }
 
-func parseEnabledTokens(envEnabledTokens []string) map[string]Token {
+func parseEnabledTokens(envEnabledTokens []string, chainID flow.ChainID) (map[string]Token, error) {
 	var enabledTokens = make(map[string]Token, len(envEnabledTokens))
 	for _, s := range envEnabledTokens {
 		ss := strings.Split(s, ":")
+		if len(ss) == 1 {
+			// Built-in token enabled by name only, e.g. FiatToken
+			token, err := builtinToken(ss[0], chainID)
+			if err != nil {
+				return nil, err
+			}
+			enabledTokens[strings.ToLower(ss[0])] = token
+			continue
+		}
 		token := Token{Name: ss[0], Address: ss[1]}
 		if len(ss) == 3 {
 			// Deprecated
@@ -48,14 +68,35 @@ func parseEnabledTokens(envEnabledTokens []string) map[string]Token {
 		key := strings.ToLower(ss[0])
 		enabledTokens[key] = token
 	}
-	return enabledTokens
+	return enabledTokens, nil
 }
 
 func NewService(cfg *configs.Config, store Store) (Service, error) {
 	// TODO(latenssi): safeguard against nil config?
 
-	// Add all enabled tokens from config as fungible tokens
-	for _, t := range parseEnabledTokens(cfg.EnabledTokens) {
+	createAccountTemplate, err := parseCreateAccountTemplate(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens of the registry file, ENABLED_TOKENS take precedence
+	enabledTokens, err := readTokenRegistry(cfg.TokenRegistryFile, cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	if enabledTokens == nil {
+		enabledTokens = make(map[string]Token)
+	}
+	envTokens, err := parseEnabledTokens(cfg.EnabledTokens, cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	for key, t := range envTokens {
+		enabledTokens[key] = t
+	}
+
+	// Add all enabled tokens from config
+	for _, t := range enabledTokens {
 		if _, err := store.GetByName(t.Name); err == nil {
 			// Token already in database
 			log.
@@ -71,33 +112,90 @@ func NewService(cfg *configs.Config, store Store) (Service, error) {
 
 		// Copy the value so we get an individual pointer, this is important
 		token := t
-		token.Type = FT // We only allow fungible tokens through env variables config
 
 		var err error
 
-		token.Setup, err = FungibleSetupCode(cfg.ChainID, &token)
-		if err != nil {
-			return nil, err
-		}
+		if token.Type == NFT {
+			// Only through the token registry
+			token.Setup, err = NonFungibleSetupCode(cfg.ChainID, &token)
+			if err != nil {
+				return nil, err
+			}
 
-		token.Transfer, err = FungibleTransferCode(cfg.ChainID, &token)
-		if err != nil {
-			return nil, err
-		}
+			token.Transfer, err = NonFungibleTransferCode(cfg.ChainID, &token)
+			if err != nil {
+				return nil, err
+			}
 
-		token.Balance, err = FungibleBalanceCode(cfg.ChainID, &token)
-		if err != nil {
-			return nil, err
+			token.Balance, err = NonFungibleBalanceCode(cfg.ChainID, &token)
+			if err != nil {
+				return nil, err
+			}
+
+			if token.Mint != "" {
+				token.Mint, err = TokenCode(cfg.ChainID, &token, token.Mint)
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			token.Type = FT // ENABLED_TOKENS are always fungible tokens
+
+			token.Setup, err = FungibleSetupCode(cfg.ChainID, &token)
+			if err != nil {
+				return nil, err
+			}
+
+			token.Transfer, err = FungibleTransferCode(cfg.ChainID, &token)
+			if err != nil {
+				return nil, err
+			}
+
+			token.Balance, err = FungibleBalanceCode(cfg.ChainID, &token)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		// Write to temp storage (memory), instead of database
 		store.InsertTemp(&token)
 	}
 
-	return &ServiceImpl{store, cfg}, nil
+	return &ServiceImpl{store, cfg, createAccountTemplate}, nil
+}
+
+// CreateAccountTemplate returns the custom account creation template,
+// nil if none is configured.
+func (s *ServiceImpl) CreateAccountTemplate() *CreateAccountTemplate {
+	return s.createAccountTemplate
 }
 
 func (s *ServiceImpl) AddToken(t *Token) error {
+	if err := s.prepareToken(t); err != nil {
+		return err
+	}
+
+	return s.store.Insert(t)
+}
+
+// UpdateToken saves the changes to a token added through the API, e.g.
+// disabling it. Tokens configured with environment variables or the token
+// registry are not in the database and can not be updated.
+func (s *ServiceImpl) UpdateToken(t *Token) error {
+	if _, err := s.store.GetById(t.ID); err != nil {
+		return err
+	}
+
+	if err := s.prepareToken(t); err != nil {
+		return err
+	}
+
+	return s.store.Update(t)
+}
+
+// prepareToken validates a token received through the API and renders its
+// code templates.
+func (s *ServiceImpl) prepareToken(t *Token) error {
 	// Check if the input is a valid address
 	address, err := flow_helpers.ValidateAddress(t.Address, s.cfg.ChainID)
 	if err != nil {
@@ -126,23 +224,62 @@ func (s *ServiceImpl) AddToken(t *Token) error {
 		return err
 	}
 
-	return s.store.Insert(t)
+	if t.MinterAddress != "" {
+		t.MinterAddress, err = flow_helpers.ValidateAddress(t.MinterAddress, s.cfg.ChainID)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.Mint, err = TokenCode(s.cfg.ChainID, t, t.Mint)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
+// ListTokens returns all tokens of a type, including disabled ones.
 func (s *ServiceImpl) ListTokens(tType TokenType) ([]BasicToken, error) {
 	return s.store.List(tType)
 }
 
+// ListTokensFull returns the enabled tokens of a type with their code.
 func (s *ServiceImpl) ListTokensFull(tType TokenType) ([]Token, error) {
-	return s.store.ListFull(tType)
+	tt, err := s.store.ListFull(tType)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make([]Token, 0, len(tt))
+	for _, t := range tt {
+		if !t.Disabled {
+			enabled = append(enabled, t)
+		}
+	}
+
+	return enabled, nil
 }
 
 func (s *ServiceImpl) GetTokenById(id uint64) (*Token, error) {
 	return s.store.GetById(id)
 }
 
+// GetTokenByName returns an enabled token, disabled tokens are not found.
 func (s *ServiceImpl) GetTokenByName(name string) (*Token, error) {
-	return s.store.GetByName(name)
+	t, err := s.store.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Disabled {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusNotFound,
+			Err:        fmt.Errorf("token %s is disabled", t.Name),
+		}
+	}
+
+	return t, nil
 }
 
 func (s *ServiceImpl) RemoveToken(id uint64) error {
@@ -178,3 +315,56 @@ func (s *ServiceImpl) TokenFromEvent(e flow.Event) (*Token, error) {
 
 	return token, nil
 }
+
+// Contracts returns the standard contracts and the enabled tokens of the
+// chain of the service for rendering Cadence code.
+func (s *ServiceImpl) Contracts() (render.Contracts, error) {
+	tt, err := s.ListTokensFull(NotSpecified)
+	if err != nil {
+		return nil, err
+	}
+
+	c := render.ForChain(s.cfg.ChainID)
+	for _, t := range tt {
+		c = c.With(t.Name, t.Address)
+	}
+
+	return c, nil
+}
+
+// AddTransactionTemplate registers a named transaction template. Imports of
+// standard contracts and enabled tokens in its code are resolved to their
+// addresses on the chain of the service.
+func (s *ServiceImpl) AddTransactionTemplate(t *TransactionTemplate) error {
+	contracts, err := s.Contracts()
+	if err != nil {
+		return err
+	}
+
+	t.Code = contracts.Render(t.Code)
+
+	if err := t.Validate(); err != nil {
+		return &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	if _, err := s.store.GetTransactionTemplate(t.Name); err == nil {
+		return &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("transaction template %s already exists", t.Name),
+		}
+	}
+
+	return s.store.InsertTransactionTemplate(t)
+}
+
+func (s *ServiceImpl) ListTransactionTemplates() ([]TransactionTemplate, error) {
+	return s.store.ListTransactionTemplates()
+}
+
+func (s *ServiceImpl) GetTransactionTemplate(name string) (*TransactionTemplate, error) {
+	return s.store.GetTransactionTemplate(name)
+}
+
+func (s *ServiceImpl) RemoveTransactionTemplate(name string) error {
+	return s.store.RemoveTransactionTemplate(name)
+}
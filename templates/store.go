@@ -7,8 +7,14 @@ type Store interface {
 	ListFull(TokenType) ([]Token, error)
 	GetById(id uint64) (*Token, error)
 	GetByName(name string) (*Token, error)
+	Update(*Token) error
 	Remove(id uint64) error
 	// Insert a token that is available only for this instances runtime (in-memory)
 	// Used when enabling a token via environment variables
 	InsertTemp(*Token)
+
+	InsertTransactionTemplate(*TransactionTemplate) error
+	ListTransactionTemplates() ([]TransactionTemplate, error)
+	GetTransactionTemplate(name string) (*TransactionTemplate, error)
+	RemoveTransactionTemplate(name string) error
 }
@@ -84,6 +84,10 @@ func (s *GormStore) GetByName(name string) (*Token, error) {
 	return &fromDB, nil
 }
 
+func (s *GormStore) Update(t *Token) error {
+	return s.db.Save(t).Error
+}
+
 func (s *GormStore) Remove(id uint64) error {
 	return s.db.Delete(&Token{}, id).Error
 }
@@ -91,3 +95,33 @@ func (s *GormStore) Remove(id uint64) error {
 func (s *GormStore) InsertTemp(token *Token) {
 	s.tempStore[strings.ToLower(token.Name)] = token
 }
+
+func (s *GormStore) InsertTransactionTemplate(t *TransactionTemplate) error {
+	return s.db.Omit("ID").Create(t).Error
+}
+
+func (s *GormStore) ListTransactionTemplates() (tt []TransactionTemplate, err error) {
+	err = s.db.Order("name asc").Find(&tt).Error
+	return
+}
+
+func (s *GormStore) GetTransactionTemplate(name string) (*TransactionTemplate, error) {
+	var t TransactionTemplate
+	if err := s.db.Where(&TransactionTemplate{Name: name}).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *GormStore) RemoveTransactionTemplate(name string) error {
+	res := s.db.Where(&TransactionTemplate{Name: name}).Delete(&TransactionTemplate{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -16,6 +16,9 @@ type FungibleTokenInfo struct {
 	VaultStoragePath   string
 	ReceiverPublicPath string
 	BalancePublicPath  string
+	// ResourceIdPublicPath is linked to the ResourceId interface of the
+	// token contract if set, e.g. for FiatToken
+	ResourceIdPublicPath string
 }
 
 func AddFungibleTokenVaultBatchTransaction(i BatchedFungibleOpsInfo) (string, error) {
@@ -26,6 +29,12 @@ func CreateAccountAndSetupTransaction(i BatchedFungibleOpsInfo) (string, error)
 	return executeTemplate("CreateAccount", CreateAccountAndSetupTransactionTemplate, i)
 }
 
+// CreateAccountsAndSetupTransaction returns a transaction which creates one
+// account per public key list and initializes the given token vaults for each.
+func CreateAccountsAndSetupTransaction(i BatchedFungibleOpsInfo) (string, error) {
+	return executeTemplate("CreateAccounts", CreateAccountsAndSetupTransactionTemplate, i)
+}
+
 const CreateAccountAndSetupTransactionTemplate = `
 import Crypto
 import FungibleToken from {{ .FungibleTokenContractAddress }}
@@ -53,11 +62,60 @@ transaction(publicKeys: [Crypto.KeyListEntry]) {
 			{{ .BalancePublicPath }},
 			target: {{ .VaultStoragePath }}
 		)
+		{{ if .ResourceIdPublicPath }}
+		account.link<&{{ .ContractName }}.Vault{ {{- .ContractName }}.ResourceId}>(
+			{{ .ResourceIdPublicPath }},
+			target: {{ .VaultStoragePath }}
+		)
+		{{ end }}
 		{{ end }}
 	}
 }
 `
 
+const CreateAccountsAndSetupTransactionTemplate = `
+import Crypto
+{{ if .Tokens }}
+import FungibleToken from {{ .FungibleTokenContractAddress }}
+{{ end }}
+{{ range .Tokens }}
+import {{ .ContractName }} from {{ .Address }}
+{{ end }}
+
+transaction(publicKeys: [[Crypto.KeyListEntry]]) {
+	prepare(signer: AuthAccount) {
+		// create one account per key list, accounts are created in order
+		for accountKeys in publicKeys {
+			let account = AuthAccount(payer: signer)
+
+			// add all the keys to the account
+			for key in accountKeys {
+				account.keys.add(publicKey: key.publicKey, hashAlgorithm: key.hashAlgorithm, weight: key.weight)
+			}
+
+			{{ range .Tokens }}
+			// initializing vault for {{ .ContractName }}
+			account.save(<-{{ .ContractName }}.createEmptyVault(), to: {{ .VaultStoragePath }})
+			account.link<&{{ .ContractName }}.Vault{FungibleToken.Receiver}>(
+				{{ .ReceiverPublicPath }},
+				target: {{ .VaultStoragePath }}
+			)
+			account.link<&{{ .ContractName }}.Vault{FungibleToken.Balance}>(
+				{{ .BalancePublicPath }},
+				target: {{ .VaultStoragePath }}
+			)
+			{{ if .ResourceIdPublicPath }}
+			account.link<&{{ .ContractName }}.Vault{ {{- .ContractName }}.ResourceId}>(
+				{{ .ResourceIdPublicPath }},
+				target: {{ .VaultStoragePath }}
+			)
+			{{ end }}
+			{{ end }}
+		}
+	}
+}
+`
+
 const AddFungibleTokenVaultBatchTransactionTemplate = `
 import FungibleToken from {{ .FungibleTokenContractAddress }}
 {{ range .Tokens }}
@@ -78,6 +136,12 @@ transaction() {
 				{{ .BalancePublicPath }},
 				target: {{ .VaultStoragePath }}
 			)
+			{{ if .ResourceIdPublicPath }}
+			account.link<&{{ .ContractName }}.Vault{ {{- .ContractName }}.ResourceId}>(
+				{{ .ResourceIdPublicPath }},
+				target: {{ .VaultStoragePath }}
+			)
+			{{ end }}
 		}
 		{{ end }}
 	}
@@ -15,6 +15,8 @@ var tokens = BatchedFungibleOpsInfo{
 			VaultStoragePath:   "TokenA.VaultStoragePath",
 			ReceiverPublicPath: "TokenA.VaultReceiverPubPath",
 			BalancePublicPath:  "TokenA.VaultBalancePubPath",
+			// Linked like FiatToken.VaultUUIDPubPath
+			ResourceIdPublicPath: "TokenA.VaultUUIDPubPath",
 		},
 		{
 			ContractName:       "TokenB",
@@ -49,6 +51,38 @@ func TestAccountCreation(t *testing.T) {
 	}
 }
 
+func TestBatchAccountCreation(t *testing.T) {
+	result, err := CreateAccountsAndSetupTransaction(tokens)
+	if err != nil {
+		t.Error(err)
+	}
+
+	checkStrings := []string{
+		"transaction(publicKeys: [[Crypto.KeyListEntry]])",
+		"import FungibleToken from 0xFungibleTokenContractAddress",
+		"import TokenA from 0x1",
+		"import TokenB from 0x2",
+		"account.save(<-TokenA.createEmptyVault(), to: TokenA.VaultStoragePath)",
+		"account.save(<-TokenB.createEmptyVault(), to: /storage/tokenBVault)",
+	}
+
+	ok, failedCheck := containsAll(result, checkStrings)
+	if !ok {
+		fmt.Println(result)
+		t.Errorf("result doesn't contain: %s", failedCheck)
+	}
+
+	result, err = CreateAccountsAndSetupTransaction(BatchedFungibleOpsInfo{})
+	if err != nil {
+		t.Error(err)
+	}
+
+	if strings.Contains(result, "import FungibleToken") {
+		fmt.Println(result)
+		t.Errorf("result should not import FungibleToken without tokens")
+	}
+}
+
 func TestAddFungibleTokens(t *testing.T) {
 	result, err := AddFungibleTokenVaultBatchTransaction(tokens)
 	if err != nil {
@@ -65,6 +99,7 @@ func TestAddFungibleTokens(t *testing.T) {
 		"target: /storage/tokenBVault",
 		"if account.borrow<&TokenA.Vault>(from: TokenA.VaultStoragePath) == nil {",
 		"if account.borrow<&TokenB.Vault>(from: /storage/tokenBVault) == nil {",
+		"account.link<&TokenA.Vault{TokenA.ResourceId}>(\n\t\t\t\tTokenA.VaultUUIDPubPath,",
 	}
 
 	ok, failedCheck := containsAll(result, checkStrings)
@@ -72,6 +107,11 @@ func TestAddFungibleTokens(t *testing.T) {
 		fmt.Println(result)
 		t.Errorf("result doesn't contain: %s", failedCheck)
 	}
+
+	if strings.Contains(result, "TokenB.ResourceId") {
+		fmt.Println(result)
+		t.Errorf("result should not link a resource ID without a path")
+	}
 }
 
 func containsAll(result string, checks []string) (bool, string) {
@@ -14,3 +14,139 @@ pub fun main(account: Address): UFix64 {
     return vaultRef.balance
 }
 `
+
+const GenericFungibleBalances = `
+import FungibleToken from "./FungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+pub fun main(accounts: [Address]): {Address: UFix64} {
+    let balances: {Address: UFix64} = {}
+
+    for account in accounts {
+        if let vaultRef = getAccount(account)
+            .getCapability(TOKEN_BALANCE)
+            .borrow<&TOKEN_DECLARATION_NAME.Vault{FungibleToken.Balance}>() {
+            balances[account] = vaultRef.balance
+        }
+    }
+
+    return balances
+}
+`
+
+const GenericFungibleTotalSupply = `
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+pub fun main(): UFix64 {
+    return TOKEN_DECLARATION_NAME.totalSupply
+}
+`
+
+const ComputeFees = `
+import FlowFees from "./FlowFees.cdc"
+
+pub fun main(inclusionEffort: UFix64, executionEffort: UFix64): UFix64 {
+    return FlowFees.computeFees(inclusionEffort: inclusionEffort, executionEffort: executionEffort)
+}
+`
+
+const GenericNonFungibleBalance = `
+import NonFungibleToken from "./NonFungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+pub fun main(account: Address): [UInt64] {
+
+    let collectionRef = getAccount(account)
+        .getCapability(TOKEN_BALANCE)
+        .borrow<&{NonFungibleToken.CollectionPublic}>()
+        ?? panic("failed to borrow reference to collection")
+
+    return collectionRef.getIDs()
+}
+`
+
+const GenericNonFungibleMetadata = `
+import NonFungibleToken from "./NonFungibleToken.cdc"
+import MetadataViews from "./MetadataViews.cdc"
+
+pub struct Royalty {
+    pub let receiver: Address
+    pub let cut: UFix64
+    pub let description: String
+
+    init(receiver: Address, cut: UFix64, description: String) {
+        self.receiver = receiver
+        self.cut = cut
+        self.description = description
+    }
+}
+
+pub struct NFTMetadata {
+    pub let id: UInt64
+    pub let name: String?
+    pub let description: String?
+    pub let thumbnail: String?
+    pub let serial: UInt64?
+    pub let royalties: [Royalty]
+
+    init(id: UInt64, name: String?, description: String?, thumbnail: String?, serial: UInt64?, royalties: [Royalty]) {
+        self.id = id
+        self.name = name
+        self.description = description
+        self.thumbnail = thumbnail
+        self.serial = serial
+        self.royalties = royalties
+    }
+}
+
+pub fun main(account: Address, id: UInt64): NFTMetadata {
+
+    let collectionRef = getAccount(account)
+        .getCapability(TOKEN_BALANCE)
+        .borrow<&{MetadataViews.ResolverCollection}>()
+        ?? panic("failed to borrow reference to metadata resolver collection")
+
+    let resolver = collectionRef.borrowViewResolver(id: id)
+
+    var name: String? = nil
+    var description: String? = nil
+    var thumbnail: String? = nil
+    if let display = resolver.resolveView(Type<MetadataViews.Display>()) as! MetadataViews.Display? {
+        name = display.name
+        description = display.description
+        thumbnail = display.thumbnail.uri()
+    }
+
+    var serial: UInt64? = nil
+    if let s = resolver.resolveView(Type<MetadataViews.Serial>()) as! MetadataViews.Serial? {
+        serial = s.number
+    }
+
+    let royalties: [Royalty] = []
+    if let rr = resolver.resolveView(Type<MetadataViews.Royalties>()) as! MetadataViews.Royalties? {
+        for r in rr.getRoyalties() {
+            royalties.append(Royalty(receiver: r.receiver.address, cut: r.cut, description: r.description))
+        }
+    }
+
+    return NFTMetadata(id: id, name: name, description: description, thumbnail: thumbnail, serial: serial, royalties: royalties)
+}
+`
+
+const AccountStorageScript = `
+pub fun main(account: Address): [UInt64] {
+    let acct = getAccount(account)
+    return [acct.storageUsed, acct.storageCapacity]
+}
+`
+
+const GenericFungibleVaultCheck = `
+import FungibleToken from "./FungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+pub fun main(account: Address): Bool {
+    return getAccount(account)
+        .getCapability(TOKEN_BALANCE)
+        .borrow<&TOKEN_DECLARATION_NAME.Vault{FungibleToken.Balance}>() != nil
+}
+`
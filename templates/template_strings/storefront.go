@@ -0,0 +1,89 @@
+package template_strings
+
+// NFTStorefrontListing lists an NFT for sale in the NFTStorefrontV2
+// storefront of the signer, setting up the storefront if needed. The price
+// goes to the signer, less the commission paid to the marketplace completing
+// the sale. Only the given marketplaces can claim the commission, any if none
+// are given.
+const NFTStorefrontListing = `
+import FungibleToken from "./FungibleToken.cdc"
+import NonFungibleToken from "./NonFungibleToken.cdc"
+import NFTStorefrontV2 from NFT_STOREFRONT_ADDRESS
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+import PAYMENT_TOKEN_DECLARATION_NAME from PAYMENT_TOKEN_ADDRESS
+
+transaction(saleItemID: UInt64, saleItemPrice: UFix64, commissionAmount: UFix64, expiry: UInt64, marketplacesAddress: [Address]) {
+  let storefront: &NFTStorefrontV2.Storefront
+  let paymentReceiver: Capability<&{FungibleToken.Receiver}>
+  let nftProvider: Capability<&TOKEN_DECLARATION_NAME.Collection{NonFungibleToken.Provider, NonFungibleToken.CollectionPublic}>
+  let marketplacesCapability: [Capability<&{FungibleToken.Receiver}>]
+
+  prepare(signer: AuthAccount) {
+    if signer.borrow<&NFTStorefrontV2.Storefront>(from: NFTStorefrontV2.StorefrontStoragePath) == nil {
+      signer.save(<-NFTStorefrontV2.createStorefront(), to: NFTStorefrontV2.StorefrontStoragePath)
+
+      signer.link<&NFTStorefrontV2.Storefront{NFTStorefrontV2.StorefrontPublic}>(
+        NFTStorefrontV2.StorefrontPublicPath,
+        target: NFTStorefrontV2.StorefrontStoragePath
+      )
+    }
+
+    self.storefront = signer
+      .borrow<&NFTStorefrontV2.Storefront>(from: NFTStorefrontV2.StorefrontStoragePath)
+      ?? panic("failed to borrow reference to storefront")
+
+    self.paymentReceiver = signer.getCapability<&{FungibleToken.Receiver}>(PAYMENT_TOKEN_RECEIVER)
+    assert(self.paymentReceiver.borrow() != nil, message: "failed to borrow reference to payment receiver")
+
+    // The storefront withdraws the NFT once sold through a private capability
+    if !signer.getCapability<&TOKEN_DECLARATION_NAME.Collection{NonFungibleToken.Provider, NonFungibleToken.CollectionPublic}>(PROVIDER_PATH).check() {
+      signer.link<&TOKEN_DECLARATION_NAME.Collection{NonFungibleToken.Provider, NonFungibleToken.CollectionPublic}>(
+        PROVIDER_PATH,
+        target: TOKEN_VAULT
+      )
+    }
+
+    self.nftProvider = signer.getCapability<&TOKEN_DECLARATION_NAME.Collection{NonFungibleToken.Provider, NonFungibleToken.CollectionPublic}>(PROVIDER_PATH)
+    assert(self.nftProvider.borrow() != nil, message: "failed to borrow reference to collection provider")
+
+    self.marketplacesCapability = []
+    for marketplace in marketplacesAddress {
+      self.marketplacesCapability.append(getAccount(marketplace).getCapability<&{FungibleToken.Receiver}>(PAYMENT_TOKEN_RECEIVER))
+    }
+  }
+
+  execute {
+    self.storefront.createListing(
+      nftProviderCapability: self.nftProvider,
+      nftType: Type<@TOKEN_DECLARATION_NAME.NFT>(),
+      nftID: saleItemID,
+      salePaymentVaultType: Type<@PAYMENT_TOKEN_DECLARATION_NAME.Vault>(),
+      saleCuts: [NFTStorefrontV2.SaleCut(receiver: self.paymentReceiver, amount: saleItemPrice - commissionAmount)],
+      marketplacesCapability: self.marketplacesCapability.length == 0 ? nil : self.marketplacesCapability,
+      customID: nil,
+      commissionAmount: commissionAmount,
+      expiry: expiry
+    )
+  }
+}
+`
+
+// NFTStorefrontRemoveListing removes a listing from the NFTStorefrontV2
+// storefront of the signer.
+const NFTStorefrontRemoveListing = `
+import NFTStorefrontV2 from NFT_STOREFRONT_ADDRESS
+
+transaction(listingResourceID: UInt64) {
+  let storefront: &NFTStorefrontV2.Storefront{NFTStorefrontV2.StorefrontManager}
+
+  prepare(signer: AuthAccount) {
+    self.storefront = signer
+      .borrow<&NFTStorefrontV2.Storefront{NFTStorefrontV2.StorefrontManager}>(from: NFTStorefrontV2.StorefrontStoragePath)
+      ?? panic("failed to borrow reference to storefront")
+  }
+
+  execute {
+    self.storefront.removeListing(listingResourceID: listingResourceID)
+  }
+}
+`
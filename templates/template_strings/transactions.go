@@ -46,6 +46,38 @@ transaction(amount: UFix64, recipient: Address) {
 }
 `
 
+const GenericFungibleBatchTransfer = `
+import FungibleToken from "./FungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+transaction(amounts: [UFix64], recipients: [Address]) {
+  let vaultRef: &TOKEN_DECLARATION_NAME.Vault
+
+  prepare(signer: AuthAccount) {
+    pre {
+      amounts.length == recipients.length: "amounts and recipients differ in length"
+    }
+
+    self.vaultRef = signer
+      .borrow<&TOKEN_DECLARATION_NAME.Vault>(from: TOKEN_VAULT)
+      ?? panic("failed to borrow reference to sender vault")
+  }
+
+  execute {
+    var i = 0
+    while i < recipients.length {
+      let receiverRef = getAccount(recipients[i])
+        .getCapability(TOKEN_RECEIVER)
+        .borrow<&{FungibleToken.Receiver}>()
+        ?? panic("failed to borrow reference to recipient vault")
+
+      receiverRef.deposit(from: <-self.vaultRef.withdraw(amount: amounts[i]))
+      i = i + 1
+    }
+  }
+}
+`
+
 const GenericFungibleSetup = `
 import FungibleToken from "./FungibleToken.cdc"
 import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
@@ -74,6 +106,117 @@ transaction {
 }
 `
 
+// FiatTokenSetup sets up a FiatToken (USDC) vault, which also links the
+// resource ID of the vault.
+const FiatTokenSetup = `
+import FungibleToken from "./FungibleToken.cdc"
+import FiatToken from TOKEN_ADDRESS
+
+transaction {
+  prepare(signer: AuthAccount) {
+
+    let existingVault = signer.borrow<&FiatToken.Vault>(from: TOKEN_VAULT)
+
+    if (existingVault != nil) {
+        panic("vault exists")
+    }
+
+    signer.save(<-FiatToken.createEmptyVault(), to: TOKEN_VAULT)
+
+    signer.link<&FiatToken.Vault{FungibleToken.Receiver}>(
+      TOKEN_RECEIVER,
+      target: TOKEN_VAULT
+    )
+
+    signer.link<&FiatToken.Vault{FiatToken.ResourceId}>(
+      FiatToken.VaultUUIDPubPath,
+      target: TOKEN_VAULT
+    )
+
+    signer.link<&FiatToken.Vault{FungibleToken.Balance}>(
+      TOKEN_BALANCE,
+      target: TOKEN_VAULT
+    )
+  }
+}
+`
+
+const GenericNonFungibleTransfer = `
+import NonFungibleToken from "./NonFungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+transaction(recipient: Address, withdrawID: UInt64) {
+  let sentNFT: @NonFungibleToken.NFT
+
+  prepare(signer: AuthAccount) {
+    let collectionRef = signer
+      .borrow<&TOKEN_DECLARATION_NAME.Collection>(from: TOKEN_VAULT)
+      ?? panic("failed to borrow reference to sender collection")
+
+    self.sentNFT <- collectionRef.withdraw(withdrawID: withdrawID)
+  }
+
+  execute {
+    let depositRef = getAccount(recipient)
+      .getCapability(TOKEN_RECEIVER)
+      .borrow<&{NonFungibleToken.CollectionPublic}>()
+      ?? panic("failed to borrow reference to recipient collection")
+
+    depositRef.deposit(token: <-self.sentNFT)
+  }
+}
+`
+
+// GenericNonFungibleBatchTransfer transfers several NFTs of a collection to
+// a single recipient.
+const GenericNonFungibleBatchTransfer = `
+import NonFungibleToken from "./NonFungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+transaction(recipient: Address, withdrawIDs: [UInt64]) {
+  let collectionRef: &TOKEN_DECLARATION_NAME.Collection
+  let depositRef: &{NonFungibleToken.CollectionPublic}
+
+  prepare(signer: AuthAccount) {
+    self.collectionRef = signer
+      .borrow<&TOKEN_DECLARATION_NAME.Collection>(from: TOKEN_VAULT)
+      ?? panic("failed to borrow reference to sender collection")
+
+    self.depositRef = getAccount(recipient)
+      .getCapability(TOKEN_RECEIVER)
+      .borrow<&{NonFungibleToken.CollectionPublic}>()
+      ?? panic("failed to borrow reference to recipient collection")
+  }
+
+  execute {
+    for withdrawID in withdrawIDs {
+      self.depositRef.deposit(token: <-self.collectionRef.withdraw(withdrawID: withdrawID))
+    }
+  }
+}
+`
+
+const GenericNonFungibleSetup = `
+import NonFungibleToken from "./NonFungibleToken.cdc"
+import TOKEN_DECLARATION_NAME from TOKEN_ADDRESS
+
+transaction {
+  prepare(signer: AuthAccount) {
+    // Return early if the account already has a collection
+    if signer.borrow<&TOKEN_DECLARATION_NAME.Collection>(from: TOKEN_VAULT) != nil {
+      return
+    }
+
+    signer.save(<-TOKEN_DECLARATION_NAME.createEmptyCollection(), to: TOKEN_VAULT)
+
+    signer.link<&TOKEN_DECLARATION_NAME.Collection{NonFungibleToken.CollectionPublic}>(
+      TOKEN_RECEIVER,
+      target: TOKEN_VAULT
+    )
+  }
+}
+`
+
 const AddProposalKeyTransaction = `
 transaction(adminKeyIndex: Int, numProposalKeys: UInt16) {
   prepare(account: AuthAccount) {
@@ -110,3 +253,115 @@ transaction(publicKeys: [String]) {
   }
 }
 `
+
+// AddAccountKeyTransaction adds a single key to the signing account.
+const AddAccountKeyTransaction = `
+transaction(publicKey: String, signatureAlgorithm: UInt8, hashAlgorithm: UInt8, weight: UFix64) {
+  prepare(signer: AuthAccount) {
+    let key = PublicKey(
+      publicKey: publicKey.decodeHex(),
+      signatureAlgorithm: SignatureAlgorithm(rawValue: signatureAlgorithm)!
+    )
+
+    signer.keys.add(
+      publicKey: key,
+      hashAlgorithm: HashAlgorithm(rawValue: hashAlgorithm)!,
+      weight: weight
+    )
+  }
+}
+`
+
+// RevokeAccountKeyTransaction revokes a key of the signing account.
+const RevokeAccountKeyTransaction = `
+transaction(keyIndex: Int) {
+  prepare(signer: AuthAccount) {
+    signer.keys.revoke(keyIndex: keyIndex)
+      ?? panic("key not found")
+  }
+}
+`
+
+// ReplaceAccountKeyTransaction revokes a key of the signing account and adds
+// a new key in its place in a single transaction.
+const ReplaceAccountKeyTransaction = `
+transaction(keyIndex: Int, publicKey: String, signatureAlgorithm: UInt8, hashAlgorithm: UInt8, weight: UFix64) {
+  prepare(signer: AuthAccount) {
+    signer.keys.revoke(keyIndex: keyIndex)
+      ?? panic("key not found")
+
+    let key = PublicKey(
+      publicKey: publicKey.decodeHex(),
+      signatureAlgorithm: SignatureAlgorithm(rawValue: signatureAlgorithm)!
+    )
+
+    signer.keys.add(
+      publicKey: key,
+      hashAlgorithm: HashAlgorithm(rawValue: hashAlgorithm)!,
+      weight: weight
+    )
+  }
+}
+`
+
+// DeployContractTransaction deploys a new contract to the signing account.
+const DeployContractTransaction = `
+transaction(name: String, code: String) {
+  prepare(signer: AuthAccount) {
+    signer.contracts.add(name: name, code: code.decodeHex())
+  }
+}
+`
+
+// UpdateContractTransaction updates an existing contract of the signing account.
+const UpdateContractTransaction = `
+transaction(name: String, code: String) {
+  prepare(signer: AuthAccount) {
+    signer.contracts.update__experimental(name: name, code: code.decodeHex())
+  }
+}
+`
+
+// RemoveContractTransaction removes a contract from the signing account.
+const RemoveContractTransaction = `
+transaction(name: String) {
+  prepare(signer: AuthAccount) {
+    signer.contracts.remove(name: name)
+      ?? panic("contract not found")
+  }
+}
+`
+
+// RotateAdminKeyTransaction adds a new full weight admin key followed by
+// zero weight proposal key clones and revokes the given keys.
+const RotateAdminKeyTransaction = `
+transaction(publicKey: String, signatureAlgorithm: UInt8, hashAlgorithm: UInt8, numProposalKeys: UInt16, revokeKeyIndexes: [Int]) {
+  prepare(account: AuthAccount) {
+    let key = PublicKey(
+      publicKey: publicKey.decodeHex(),
+      signatureAlgorithm: SignatureAlgorithm(rawValue: signatureAlgorithm)!
+    )
+    let hashAlgo = HashAlgorithm(rawValue: hashAlgorithm)!
+
+    account.keys.add(
+      publicKey: key,
+      hashAlgorithm: hashAlgo,
+      weight: 1000.0
+    )
+
+    var count: UInt16 = 1
+    while count < numProposalKeys {
+      account.keys.add(
+        publicKey: key,
+        hashAlgorithm: hashAlgo,
+        weight: 0.0
+      )
+      count = count + 1
+    }
+
+    for keyIndex in revokeKeyIndexes {
+      account.keys.revoke(keyIndex: keyIndex)
+    }
+  }
+}
+`
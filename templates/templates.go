@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/flow-hydraulics/flow-wallet-api/templates/render"
 	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
 	"github.com/onflow/flow-go-sdk"
 	log "github.com/sirupsen/logrus"
@@ -18,50 +19,31 @@ type Token struct {
 	BalancePublicPath  string    `json:"balancePublicPath,omitempty"`
 	VaultStoragePath   string    `json:"vaultStoragePath,omitempty"`
 	Address            string    `json:"address" gorm:"not null"`
-	Setup              string    `json:"setup,omitempty"`    // Setup cadence code
-	Transfer           string    `json:"transfer,omitempty"` // Transfer cadence code
-	Balance            string    `json:"balance,omitempty"`  // Balance cadence code
+	Setup              string    `json:"setup,omitempty"`         // Setup cadence code
+	Transfer           string    `json:"transfer,omitempty"`      // Transfer cadence code
+	Balance            string    `json:"balance,omitempty"`       // Balance cadence code
+	Mint               string    `json:"mint,omitempty"`          // Mint cadence code of a non-fungible token
+	MinterAddress      string    `json:"minterAddress,omitempty"` // Managed account holding the minter resource of a non-fungible token
 	Type               TokenType `json:"type"`
+	Disabled           bool      `json:"disabled,omitempty" gorm:"not null;default:false"` // Disabled tokens can not be used and their events are not tracked
 }
 
 // BasicToken is a simplifed representation of a Token used in listings
 type BasicToken struct {
-	ID      uint64    `json:"id,omitempty"`
-	Name    string    `json:"name"`
-	Address string    `json:"address"`
-	Type    TokenType `json:"type"`
-}
-
-type chainReplacers map[flow.ChainID]*strings.Replacer
-type knownAddresses map[flow.ChainID]string
-type templateVariables map[string]knownAddresses
-
-var chains []flow.ChainID = []flow.ChainID{
-	flow.Emulator,
-	flow.Testnet,
-	flow.Mainnet,
-}
-
-var knownAddressesReplacers chainReplacers
-
-func makeReplacers(t templateVariables) chainReplacers {
-	r := make(chainReplacers, len(chains))
-	for _, c := range chains {
-		vv := make([]string, len(t)*2)
-		for varname, addressInChain := range t {
-			vv = append(vv, varname, addressInChain[c])
-		}
-		r[c] = strings.NewReplacer(vv...)
-	}
-	return r
+	ID       uint64    `json:"id,omitempty"`
+	Name     string    `json:"name"`
+	Address  string    `json:"address"`
+	Type     TokenType `json:"type"`
+	Disabled bool      `json:"disabled,omitempty"`
 }
 
 func (token Token) BasicToken() BasicToken {
 	return BasicToken{
-		ID:      token.ID,
-		Name:    token.Name,
-		Address: token.Address,
-		Type:    token.Type,
+		ID:       token.ID,
+		Name:     token.Name,
+		Address:  token.Address,
+		Type:     token.Type,
+		Disabled: token.Disabled,
 	}
 }
 
@@ -98,15 +80,13 @@ func TokenCode(chainId flow.ChainID, token *Token, tmplStr string) (string, erro
 		"TOKEN_BALANCE", tokenBalance,
 	)
 
-	knownAddressesReplacer := knownAddressesReplacers[chainId]
-
 	code := tmplStr
 
 	// Ordering matters here
 	code = matchCadenceFiles.ReplaceAllString(code, replaceCadenceFiles)
 	code = sourceFileReplacer.Replace(code)
 	code = templateReplacer.Replace(code)
-	code = knownAddressesReplacer.Replace(code)
+	code = render.ForChain(chainId).Render(code)
 
 	return code, nil
 }
@@ -161,24 +141,106 @@ func FungibleTransferCode(chainId flow.ChainID, token *Token) (string, error) {
 	return TokenCode(chainId, token, template_strings.GenericFungibleTransfer)
 }
 
+func FungibleBatchTransferCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericFungibleBatchTransfer)
+}
+
 func FungibleSetupCode(chainId flow.ChainID, token *Token) (string, error) {
-	return TokenCode(chainId, token, template_strings.GenericFungibleSetup)
+	return TokenCode(chainId, token, fungibleSetupTemplate(token))
 }
 
 func FungibleBalanceCode(chainId flow.ChainID, token *Token) (string, error) {
 	return TokenCode(chainId, token, template_strings.GenericFungibleBalance)
 }
 
+// FungibleBalancesCode returns a script reading the balances of a list of
+// accounts at once, accounts without a vault are left out.
+func FungibleBalancesCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericFungibleBalances)
+}
+
+// FungibleTotalSupplyCode returns a script reading the total supply of a
+// fungible token from its contract.
+func FungibleTotalSupplyCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericFungibleTotalSupply)
+}
+
+// ComputeFeesCode returns a script computing the fees of a transaction from
+// its inclusion and execution effort with the current fee parameters.
+func ComputeFeesCode(chainId flow.ChainID) string {
+	return render.ForChain(chainId).Render(template_strings.ComputeFees)
+}
+
+func FungibleVaultCheckCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericFungibleVaultCheck)
+}
+
+func NonFungibleTransferCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericNonFungibleTransfer)
+}
+
+// NonFungibleBatchTransferCode returns a transaction transferring several
+// NFTs of a collection to a single recipient.
+func NonFungibleBatchTransferCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericNonFungibleBatchTransfer)
+}
+
+func NonFungibleSetupCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericNonFungibleSetup)
+}
+
+func NonFungibleBalanceCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericNonFungibleBalance)
+}
+
+func NonFungibleMetadataCode(chainId flow.ChainID, token *Token) (string, error) {
+	return TokenCode(chainId, token, template_strings.GenericNonFungibleMetadata)
+}
+
+// NFTStorefrontListingCode returns a transaction listing an NFT of token for
+// sale in the NFTStorefrontV2 contract at storefrontAddress, paid in
+// paymentToken.
+func NFTStorefrontListingCode(chainId flow.ChainID, storefrontAddress string, token, paymentToken *Token) (string, error) {
+	_, paymentReceiver, _, err := GetTokenPaths(paymentToken)
+	if err != nil {
+		return "", err
+	}
+
+	// Replaced before the placeholders of the NFT they contain
+	code := strings.NewReplacer(
+		"NFT_STOREFRONT_ADDRESS", storefrontAddress,
+		"PAYMENT_TOKEN_DECLARATION_NAME", paymentToken.Name,
+		"PAYMENT_TOKEN_ADDRESS", paymentToken.Address,
+		"PAYMENT_TOKEN_RECEIVER", paymentReceiver,
+		"PROVIDER_PATH", fmt.Sprintf("/private/%sProviderForNFTStorefront", token.Name),
+	).Replace(template_strings.NFTStorefrontListing)
+
+	return TokenCode(chainId, token, code)
+}
+
+// NFTStorefrontRemoveListingCode returns a transaction removing a listing
+// from the NFTStorefrontV2 contract at storefrontAddress.
+func NFTStorefrontRemoveListingCode(storefrontAddress string) string {
+	return strings.ReplaceAll(template_strings.NFTStorefrontRemoveListing, "NFT_STOREFRONT_ADDRESS", storefrontAddress)
+}
+
 func InitFungibleTokenVaultsCode(chainId flow.ChainID, tokens []template_strings.FungibleTokenInfo) (string, error) {
 	return template_strings.AddFungibleTokenVaultBatchTransaction(template_strings.BatchedFungibleOpsInfo{
-		FungibleTokenContractAddress: KnownAddresses["FungibleToken.cdc"][chainId],
+		FungibleTokenContractAddress: render.StandardContracts["FungibleToken"][chainId],
 		Tokens:                       tokens,
 	})
 }
 
 func CreateAccountAndInitFungibleTokenVaultsCode(chainId flow.ChainID, tokens []template_strings.FungibleTokenInfo) (string, error) {
 	return template_strings.CreateAccountAndSetupTransaction(template_strings.BatchedFungibleOpsInfo{
-		FungibleTokenContractAddress: KnownAddresses["FungibleToken.cdc"][chainId],
+		FungibleTokenContractAddress: render.StandardContracts["FungibleToken"][chainId],
+		Tokens:                       tokens,
+	})
+}
+
+func CreateAccountsAndInitFungibleTokenVaultsCode(chainId flow.ChainID, tokens []template_strings.FungibleTokenInfo) (string, error) {
+	return template_strings.CreateAccountsAndSetupTransaction(template_strings.BatchedFungibleOpsInfo{
+		FungibleTokenContractAddress: render.StandardContracts["FungibleToken"][chainId],
 		Tokens:                       tokens,
 	})
 }
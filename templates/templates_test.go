@@ -75,4 +75,132 @@ func TestParsing(t *testing.T) {
 			t.Error("expected to find import statement for token address")
 		}
 	})
+
+	t.Run("NFT metadata", func(t *testing.T) {
+		token := &Token{
+			Name:               "ExampleNFT",
+			Address:            "test-address",
+			ReceiverPublicPath: "ExampleNFT.CollectionPublicPath",
+			BalancePublicPath:  "ExampleNFT.CollectionPublicPath",
+			VaultStoragePath:   "ExampleNFT.CollectionStoragePath",
+			Type:               NFT,
+		}
+		c, err := NonFungibleMetadataCode(flow.Testnet, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(c, ".cdc") {
+			t.Error("expected all cadence file references to have been replaced")
+		}
+		if !strings.Contains(c, "import MetadataViews from 0x631e88ae7f1d7c20") {
+			t.Error("expected to find import statement for MetadataViews testnet address")
+		}
+		if !strings.Contains(c, ".getCapability(ExampleNFT.CollectionPublicPath)") {
+			t.Error("expected to find collection public path")
+		}
+	})
+
+	t.Run("FUSD balances", func(t *testing.T) {
+		token := &Token{
+			Name:               "FUSD",
+			Address:            "test-address",
+			ReceiverPublicPath: "/public/fusdReceiver",
+			BalancePublicPath:  "/public/fusdBalance",
+			VaultStoragePath:   "/storage/fusdVault",
+			Type:               FT,
+		}
+		c, err := FungibleBalancesCode(flow.Emulator, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(c, ".cdc") {
+			t.Error("expected all cadence file references to have been replaced")
+		}
+		if !strings.Contains(c, ".getCapability(/public/fusdBalance)") {
+			t.Error("expected to find balance public path")
+		}
+		if !strings.Contains(c, "borrow<&FUSD.Vault{FungibleToken.Balance}>()") {
+			t.Error("expected to borrow the vault of the token")
+		}
+	})
+
+	t.Run("FUSD total supply", func(t *testing.T) {
+		token := &Token{Name: "FUSD", Address: "0x3c5959b568896393", Type: FT}
+		c, err := FungibleTotalSupplyCode(flow.Mainnet, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(c, "import FUSD from 0x3c5959b568896393") {
+			t.Error("expected to import the token contract")
+		}
+		if !strings.Contains(c, "return FUSD.totalSupply") {
+			t.Error("expected to return the total supply of the token")
+		}
+	})
+
+	t.Run("ExampleNFT batch transfer", func(t *testing.T) {
+		token := &Token{
+			Name:               "ExampleNFT",
+			Address:            "0xf8d6e0586b0a20c7",
+			ReceiverPublicPath: "ExampleNFT.CollectionPublicPath",
+			BalancePublicPath:  "ExampleNFT.CollectionPublicPath",
+			VaultStoragePath:   "ExampleNFT.CollectionStoragePath",
+			Type:               NFT,
+		}
+		c, err := NonFungibleBatchTransferCode(flow.Emulator, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(c, "import NonFungibleToken from 0xf8d6e0586b0a20c7") {
+			t.Error("expected NonFungibleToken to be resolved")
+		}
+		if !strings.Contains(c, "borrow<&ExampleNFT.Collection>(from: ExampleNFT.CollectionStoragePath)") {
+			t.Error("expected to find collection storage path")
+		}
+		if !strings.Contains(c, "transaction(recipient: Address, withdrawIDs: [UInt64])") {
+			t.Error("expected to take a list of NFT IDs")
+		}
+	})
+
+	t.Run("ExampleNFT storefront listing", func(t *testing.T) {
+		token := &Token{
+			Name:               "ExampleNFT",
+			Address:            "0x1d7e57aa55817448",
+			ReceiverPublicPath: "ExampleNFT.CollectionPublicPath",
+			BalancePublicPath:  "ExampleNFT.CollectionPublicPath",
+			VaultStoragePath:   "ExampleNFT.CollectionStoragePath",
+			Type:               NFT,
+		}
+		paymentToken := &Token{Name: "FUSD", Address: "0x3c5959b568896393", NameLowerCase: "fusd", Type: FT}
+		c, err := NFTStorefrontListingCode(flow.Mainnet, "0x4eb8a10cb9f87357", token, paymentToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{
+			"import NFTStorefrontV2 from 0x4eb8a10cb9f87357",
+			"import ExampleNFT from 0x1d7e57aa55817448",
+			"import FUSD from 0x3c5959b568896393",
+			"import FungibleToken from 0xf233dcee88fe0abe",
+			"getCapability<&{FungibleToken.Receiver}>(/public/fusdReceiver)",
+			"target: ExampleNFT.CollectionStoragePath",
+			"/private/ExampleNFTProviderForNFTStorefront",
+			"salePaymentVaultType: Type<@FUSD.Vault>()",
+		} {
+			if !strings.Contains(c, s) {
+				t.Errorf("expected to find %q in:\n%s", s, c)
+			}
+		}
+
+		c = NFTStorefrontRemoveListingCode("0x4eb8a10cb9f87357")
+		if !strings.Contains(c, "import NFTStorefrontV2 from 0x4eb8a10cb9f87357") {
+			t.Error("expected to import the storefront contract")
+		}
+	})
+
+	t.Run("compute fees", func(t *testing.T) {
+		c := ComputeFeesCode(flow.Testnet)
+		if !strings.Contains(c, "import FlowFees from 0x912d5440f7e3769e") {
+			t.Errorf("expected FlowFees to be resolved:\n%s", c)
+		}
+	})
 }
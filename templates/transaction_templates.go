@@ -0,0 +1,151 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/onflow/cadence"
+	c_json "github.com/onflow/cadence/encoding/json"
+	"gorm.io/datatypes"
+)
+
+var transactionTemplateNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// argumentTypes are the Cadence types supported in transaction template
+// argument schemas.
+var argumentTypes = map[string]bool{
+	"String": true, "Character": true, "Bool": true, "Address": true,
+	"Int": true, "Int8": true, "Int16": true, "Int32": true, "Int64": true, "Int128": true, "Int256": true,
+	"UInt": true, "UInt8": true, "UInt16": true, "UInt32": true, "UInt64": true, "UInt128": true, "UInt256": true,
+	"Word8": true, "Word16": true, "Word32": true, "Word64": true,
+	"Fix64": true, "UFix64": true,
+}
+
+// TransactionTemplate is a named Cadence transaction registered by an admin,
+// invoked by clients with just the arguments.
+type TransactionTemplate struct {
+	ID          uint64         `json:"id,omitempty"`
+	Name        string         `json:"name" gorm:"uniqueIndex;size:64;not null"`
+	Description string         `json:"description,omitempty"`
+	Code        string         `json:"code" gorm:"not null"`
+	Arguments   datatypes.JSON `json:"arguments"` // []TemplateArgument
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// TemplateArgument describes an argument of a transaction template, in the
+// order of the transaction parameters.
+type TemplateArgument struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func (TransactionTemplate) TableName() string {
+	return "transaction_templates"
+}
+
+// ArgumentSchema returns the argument schema of the template.
+func (t TransactionTemplate) ArgumentSchema() ([]TemplateArgument, error) {
+	var aa []TemplateArgument
+	if len(t.Arguments) == 0 {
+		return aa, nil
+	}
+
+	if err := json.Unmarshal(t.Arguments, &aa); err != nil {
+		return nil, fmt.Errorf("invalid argument schema: %w", err)
+	}
+
+	return aa, nil
+}
+
+// Validate checks the name, code and argument schema of the template.
+func (t TransactionTemplate) Validate() error {
+	if !transactionTemplateNamePattern.MatchString(t.Name) {
+		return fmt.Errorf(`not a valid name: "%s", expected 1 to 64 letters, digits, "_" or "-"`, t.Name)
+	}
+
+	if t.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+
+	aa, err := t.ArgumentSchema()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(aa))
+	for _, a := range aa {
+		if a.Name == "" {
+			return fmt.Errorf("argument name is required")
+		}
+
+		if names[a.Name] {
+			return fmt.Errorf("duplicate argument: %s", a.Name)
+		}
+		names[a.Name] = true
+
+		if !argumentTypes[a.Type] {
+			return fmt.Errorf("unsupported type for argument %s: %q", a.Name, a.Type)
+		}
+	}
+
+	return nil
+}
+
+// CadenceArguments converts argument values given by name to Cadence values
+// in the order of the argument schema. Values are given as in JSON-Cadence,
+// numbers may also be given as JSON numbers.
+func (t TransactionTemplate) CadenceArguments(values map[string]json.RawMessage) ([]cadence.Value, error) {
+	aa, err := t.ArgumentSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) > len(aa) {
+		for name := range values {
+			if !hasArgument(aa, name) {
+				return nil, fmt.Errorf("unknown argument: %s", name)
+			}
+		}
+	}
+
+	result := make([]cadence.Value, len(aa))
+	for i, a := range aa {
+		v, ok := values[a.Name]
+		if !ok {
+			return nil, fmt.Errorf("missing argument: %s", a.Name)
+		}
+
+		// JSON-Cadence expects all but boolean values as strings
+		if a.Type != "Bool" && len(v) > 0 && v[0] != '"' {
+			if v, err = json.Marshal(string(v)); err != nil {
+				return nil, err
+			}
+		}
+
+		j, err := json.Marshal(map[string]interface{}{"type": a.Type, "value": v})
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := c_json.Decode(nil, j)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for argument %s: %w", a.Name, err)
+		}
+
+		result[i] = c
+	}
+
+	return result, nil
+}
+
+func hasArgument(aa []TemplateArgument, name string) bool {
+	for _, a := range aa {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
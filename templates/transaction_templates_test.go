@@ -0,0 +1,88 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"gorm.io/datatypes"
+)
+
+func TestTransactionTemplateValidation(t *testing.T) {
+	valid := TransactionTemplate{
+		Name:      "transfer-flow",
+		Code:      "transaction(amount: UFix64, recipient: Address) {}",
+		Arguments: datatypes.JSON(`[{"name":"amount","type":"UFix64"},{"name":"recipient","type":"Address"}]`),
+	}
+
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("did not expect an error, got: %s", err)
+	}
+
+	invalid := map[string]TransactionTemplate{
+		"name":           {Name: "no spaces", Code: "transaction {}"},
+		"code":           {Name: "empty"},
+		"type":           {Name: "t", Code: "transaction(a: [String]) {}", Arguments: datatypes.JSON(`[{"name":"a","type":"[String]"}]`)},
+		"duplicate name": {Name: "t", Code: "transaction(a: Int, b: Int) {}", Arguments: datatypes.JSON(`[{"name":"a","type":"Int"},{"name":"a","type":"Int"}]`)},
+	}
+
+	for name, tmpl := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := tmpl.Validate(); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestTransactionTemplateArguments(t *testing.T) {
+	tmpl := TransactionTemplate{
+		Name:      "t",
+		Code:      "transaction(amount: UFix64, count: Int, enabled: Bool) {}",
+		Arguments: datatypes.JSON(`[{"name":"amount","type":"UFix64"},{"name":"count","type":"Int"},{"name":"enabled","type":"Bool"}]`),
+	}
+
+	t.Run("in schema order", func(t *testing.T) {
+		values := map[string]json.RawMessage{
+			"enabled": json.RawMessage(`true`),
+			"count":   json.RawMessage(`3`),
+			"amount":  json.RawMessage(`"1.5"`),
+		}
+
+		args, err := tmpl.CadenceArguments(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		amount, err := cadence.NewUFix64("1.5")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []cadence.Value{amount, cadence.NewInt(3), cadence.NewBool(true)}
+		if len(args) != len(expected) {
+			t.Fatalf("expected %d arguments, got %d", len(expected), len(args))
+		}
+
+		for i := range expected {
+			if fmt.Sprintf("%T %s", args[i], args[i]) != fmt.Sprintf("%T %s", expected[i], expected[i]) {
+				t.Errorf("expected argument %d to be %#v, got %#v", i, expected[i], args[i])
+			}
+		}
+	})
+
+	invalid := map[string]map[string]json.RawMessage{
+		"missing": {"amount": json.RawMessage(`"1.0"`), "count": json.RawMessage(`1`)},
+		"unknown": {"amount": json.RawMessage(`"1.0"`), "count": json.RawMessage(`1`), "enabled": json.RawMessage(`false`), "extra": json.RawMessage(`1`)},
+		"type":    {"amount": json.RawMessage(`"abc"`), "count": json.RawMessage(`1`), "enabled": json.RawMessage(`false`)},
+	}
+
+	for name, values := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := tmpl.CadenceArguments(values); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -3,11 +3,24 @@ package templates
 import "github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
 
 func NewFungibleTokenInfo(t Token) template_strings.FungibleTokenInfo {
-	return template_strings.FungibleTokenInfo{
+	info := template_strings.FungibleTokenInfo{
 		ContractName:       t.Name,
 		Address:            t.Address,
 		VaultStoragePath:   t.VaultStoragePath,
 		ReceiverPublicPath: t.ReceiverPublicPath,
 		BalancePublicPath:  t.BalancePublicPath,
 	}
+
+	// Paths derived from the (deprecated) lowercase name of the token
+	if vault, receiver, balance, err := GetTokenPaths(&t); err == nil {
+		info.VaultStoragePath = vault
+		info.ReceiverPublicPath = receiver
+		info.BalancePublicPath = balance
+	}
+
+	if t.Name == FiatTokenName {
+		info.ResourceIdPublicPath = fiatTokenResourceIdPath
+	}
+
+	return info
 }
@@ -0,0 +1,72 @@
+// Package tenants provides scoping of accounts, transactions and jobs to the
+// tenant (organization) of the API credential used for a request.
+package tenants
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type (
+	contextKey      struct{}
+	adminContextKey struct{}
+)
+
+// NewContext returns a copy of ctx carrying the tenant ID.
+func NewContext(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenantID)
+}
+
+// FromContext returns the tenant ID of ctx, an empty string if tenants are
+// not in use.
+func FromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(contextKey{}).(string)
+	return tenantID
+}
+
+// NewAdminContext returns a copy of ctx carrying the ID of the admin making
+// the request. Admin requests are not scoped to a tenant.
+func NewAdminContext(ctx context.Context, adminID string) context.Context {
+	return context.WithValue(ctx, adminContextKey{}, adminID)
+}
+
+// AdminFromContext returns the admin ID of ctx, an empty string if the
+// request was not made with an admin API key.
+func AdminFromContext(ctx context.Context) string {
+	adminID, _ := ctx.Value(adminContextKey{}).(string)
+	return adminID
+}
+
+// ParseAPIKeys parses "tenantID:apiKey" pairs into a map of API keys to
+// tenant IDs. A tenant may have multiple API keys.
+func ParseAPIKeys(pairs []string) (map[string]string, error) {
+	return parseAPIKeys(pairs, "tenant")
+}
+
+// ParseAdminAPIKeys parses "adminID:apiKey" pairs into a map of API keys to
+// admin IDs.
+func ParseAdminAPIKeys(pairs []string) (map[string]string, error) {
+	return parseAPIKeys(pairs, "admin")
+}
+
+func parseAPIKeys(pairs []string, kind string) (map[string]string, error) {
+	apiKeys := make(map[string]string, len(pairs))
+
+	for _, p := range pairs {
+		ss := strings.SplitN(p, ":", 2)
+		if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+			return nil, fmt.Errorf("invalid %s API key, expected format %sID:apiKey", kind, kind)
+		}
+
+		id, apiKey := ss[0], ss[1]
+
+		if _, exists := apiKeys[apiKey]; exists {
+			return nil, fmt.Errorf("duplicate API key for %s %s", kind, id)
+		}
+
+		apiKeys[apiKey] = id
+	}
+
+	return apiKeys, nil
+}
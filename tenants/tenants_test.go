@@ -0,0 +1,45 @@
+package tenants
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	apiKeys, err := ParseAPIKeys([]string{"shop:key-1", "shop:key-2", "games:key:3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"key-1": "shop", "key-2": "shop", "key:3": "games"}
+	for k, v := range expected {
+		if apiKeys[k] != v {
+			t.Errorf("expected API key %q to belong to %q, got %q", k, v, apiKeys[k])
+		}
+	}
+
+	for _, invalid := range [][]string{{"shop"}, {":key"}, {"shop:"}, {"shop:key", "games:key"}} {
+		if _, err := ParseAPIKeys(invalid); err == nil {
+			t.Errorf("expected an error for %v", invalid)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Fatalf("expected no tenant, got %q", id)
+	}
+
+	if id := FromContext(NewContext(context.Background(), "shop")); id != "shop" {
+		t.Fatalf("expected tenant %q, got %q", "shop", id)
+	}
+
+	ctx := NewAdminContext(context.Background(), "alice")
+	if id := AdminFromContext(ctx); id != "alice" {
+		t.Fatalf("expected admin %q, got %q", "alice", id)
+	}
+
+	if id := FromContext(ctx); id != "" {
+		t.Fatalf("expected no tenant for an admin, got %q", id)
+	}
+}
@@ -5,20 +5,28 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/handlers"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/template_strings"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
 	"github.com/gorilla/mux"
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
 )
 
 func TestEmulatorAcceptsSignedTransaction(t *testing.T) {
@@ -89,54 +97,1005 @@ func TestEmulatorAcceptsSignedTransaction(t *testing.T) {
 	}
 }
 
+func TestEmulatorAcceptsCoSignedClientTransaction(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	fc := test.NewFlowClient(t, cfg)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+	txHandler := handlers.NewTransactions(svcs.GetTransactions())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/sign", txHandler.Sign()).Methods(http.MethodPost)
+
+	// Create proposer and payer accounts.
+	var proposer, payer accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &proposer)
+
+	res = send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &payer)
+
+	ctx := context.Background()
+
+	flowProposer, err := fc.GetAccount(ctx, flow.HexToAddress(proposer.Address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	referenceBlock, err := fc.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Build the transaction client side.
+	code := "transaction(greeting: String) { prepare(signer: AuthAccount){} execute { log(greeting.concat(\", World!\")) }}"
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(code)).
+		SetReferenceBlockID(referenceBlock.ID).
+		SetGasLimit(9999).
+		SetProposalKey(flowProposer.Address, flowProposer.Keys[0].Index, flowProposer.Keys[0].SequenceNumber).
+		SetPayer(flow.HexToAddress(payer.Address)).
+		AddAuthorizer(flowProposer.Address)
+
+	if err := tx.AddArgument(cadence.String("Hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A transaction of other accounts can not be signed.
+	var other accounts.Account
+	res = send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &other)
+
+	body := bytes.NewBufferString(fmt.Sprintf("{\"transaction\":%q}", hex.EncodeToString(tx.Encode())))
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", other.Address), body)
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	// Proposer signs the payload, transaction given RLP encoded.
+	body = bytes.NewBufferString(fmt.Sprintf("{\"transaction\":%q}", hex.EncodeToString(tx.Encode())))
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", proposer.Address), body)
+	assertStatusCode(t, res, http.StatusCreated)
+
+	var txResp transactions.SignedTransactionJSONResponse
+	fromJsonBody(t, res, &txResp)
+
+	if len(txResp.PayloadSignatures) != 1 || len(txResp.EnvelopeSignatures) != 0 {
+		t.Fatalf("expected a single payload signature, got %d payload and %d envelope signatures", len(txResp.PayloadSignatures), len(txResp.EnvelopeSignatures))
+	}
+
+	// Payer signs the envelope, transaction given in JSON form.
+	body = bytes.NewBuffer(asJson(map[string]interface{}{"transaction": txResp}))
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", payer.Address), body)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &txResp)
+
+	signedTx, err := txResp.ToFlowTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signedTx.ID() != tx.ID() {
+		t.Fatalf("expected signed transaction ID %s, got %s", tx.ID(), signedTx.ID())
+	}
+
+	// The client submits the final transaction.
+	if _, err := flow_helpers.SendAndWait(ctx, fc, *signedTx, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransactionTemplates(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+	tmplHandler := handlers.NewTransactionTemplates(svcs.GetTemplates(), svcs.GetTransactions())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/templates", tmplHandler.Add()).Methods(http.MethodPost)
+	router.Handle("/templates/{name}", tmplHandler.Details()).Methods(http.MethodGet)
+	router.Handle("/templates/{name}", tmplHandler.Remove()).Methods(http.MethodDelete)
+	router.Handle("/{address}/templates/{name}", tmplHandler.Invoke()).Methods(http.MethodPost)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	// Register a template.
+	tmpl := `{
+		"name": "greet",
+		"code": "transaction(greeting: String, times: Int) { prepare(signer: AuthAccount){} execute { log(greeting) }}",
+		"arguments": [{"name": "greeting", "type": "String"}, {"name": "times", "type": "Int"}]
+	}`
+	res = send(router, http.MethodPost, "/templates", bytes.NewBufferString(tmpl))
+	assertStatusCode(t, res, http.StatusCreated)
+
+	// Names are unique.
+	res = send(router, http.MethodPost, "/templates", bytes.NewBufferString(tmpl))
+	assertStatusCode(t, res, http.StatusConflict)
+
+	res = send(router, http.MethodGet, "/templates/greet", nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	// Arguments are checked against the schema.
+	body := bytes.NewBufferString(`{"arguments": {"greeting": "Hello"}}`)
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/templates/greet?sync=true", account.Address), body)
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	body = bytes.NewBufferString(`{"arguments": {"greeting": "Hello", "times": 2}}`)
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/templates/greet?sync=true", account.Address), body)
+	assertStatusCode(t, res, http.StatusCreated)
+
+	res = send(router, http.MethodDelete, "/templates/greet", nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/templates/greet?sync=true", account.Address), nil)
+	assertStatusCode(t, res, http.StatusNotFound)
+}
+
+func TestTransactionTemplatesAllowlist(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	cfg.EnforceTransactionAllowlist = true
+	svcs := test.GetServices(t, cfg)
+
+	tmplHandler := handlers.NewTransactionTemplates(svcs.GetTemplates(), svcs.GetTransactions())
+
+	// Dummy middleware making requests as an admin
+	asAdmin := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(rw, r.WithContext(tenants.NewAdminContext(r.Context(), "alice")))
+		})
+	}
+
+	router := mux.NewRouter()
+	router.Handle("/templates", tmplHandler.Add()).Methods(http.MethodPost)
+	router.Handle("/admin/templates", asAdmin(tmplHandler.Add())).Methods(http.MethodPost)
+
+	tmpl := `{"name": "noop", "code": "transaction() { prepare(signer: AuthAccount){} execute {}}"}`
+
+	// Templates invoked without an allowlist check can not bring in code
+	// which is not in the allowlist
+	res := send(router, http.MethodPost, "/templates", bytes.NewBufferString(tmpl))
+	assertStatusCode(t, res, http.StatusForbidden)
+
+	res = send(router, http.MethodPost, "/admin/templates", bytes.NewBufferString(tmpl))
+	assertStatusCode(t, res, http.StatusCreated)
+}
+
 func TestWatchlistAccountManagement(t *testing.T) {
 	cfg := test.LoadConfig(t)
-	fc := test.NewFlowClient(t, cfg)
+	fc := test.NewFlowClient(t, cfg)
+	svcs := test.GetServices(t, cfg)
+	km := svcs.GetKeyManager()
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.AddNonCustodialAccount()).Methods(http.MethodPost)
+	router.Handle("/{address}", accHandler.Details()).Methods(http.MethodGet)
+	router.Handle("/{address}", accHandler.DeleteNonCustodialAccount()).Methods(http.MethodDelete)
+
+	// Create a non-custodial account.
+	adminAuthorizer, err := km.AdminAuthorizer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonCustodialAccount := test.NewFlowAccount(t, fc, adminAuthorizer.Address, adminAuthorizer.Key, adminAuthorizer.Signer)
+
+	// Add created non-custodial account to watchlist.
+	account := accounts.Account{Address: nonCustodialAccount.Address.Hex()}
+	buf := bytes.NewBuffer(asJson(&account))
+	res := send(router, http.MethodPost, "/", buf)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	// Ensure that account can be found.
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &account)
+
+	if account.Address != flow_helpers.FormatAddress(nonCustodialAccount.Address) {
+		t.Fatalf("read account address doesn't match - expected %q, got %q", flow_helpers.FormatAddress(nonCustodialAccount.Address), account.Address)
+	}
+
+	if account.Type != accounts.AccountTypeNonCustodial {
+		t.Fatalf("read account type doesn't match - expected %q, got %q", accounts.AccountTypeNonCustodial, account.Type)
+	}
+
+	// Remove the non-custodial account from watchlist.
+	res = send(router, http.MethodDelete, fmt.Sprintf("/%s", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	// Ensure that it's not found anymore.
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s", account.Address), nil)
+	assertStatusCode(t, res, http.StatusNotFound)
+}
+
+func TestWatchOnlyAccountSigningFails(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	fc := test.NewFlowClient(t, cfg)
+	svcs := test.GetServices(t, cfg)
+	km := svcs.GetKeyManager()
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+	txHandler := handlers.NewTransactions(svcs.GetTransactions())
+
+	router := mux.NewRouter()
+	router.Handle("/watch", accHandler.AddNonCustodialAccount()).Methods(http.MethodPost)
+	router.Handle("/{address}/sign", txHandler.Sign()).Methods(http.MethodPost)
+
+	adminAuthorizer, err := km.AdminAuthorizer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonCustodialAccount := test.NewFlowAccount(t, fc, adminAuthorizer.Address, adminAuthorizer.Key, adminAuthorizer.Signer)
+
+	// Register the account as watch-only.
+	account := accounts.Account{Address: nonCustodialAccount.Address.Hex()}
+	res := send(router, http.MethodPost, "/watch", bytes.NewBuffer(asJson(&account)))
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	// Signing must fail as the service holds no keys for the account.
+	code := "transaction() { prepare(signer: AuthAccount){} }"
+	body := bytes.NewBufferString(fmt.Sprintf("{\"code\":%q,\"arguments\":[]}", code))
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", account.Address), body)
+	assertStatusCode(t, res, http.StatusUnprocessableEntity)
+}
+
+func TestCreateAccountWithClientKey(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+	txHandler := handlers.NewTransactions(svcs.GetTransactions())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/sign", txHandler.Sign()).Methods(http.MethodPost)
+
+	pk, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, bytes.Repeat([]byte{3}, crypto.MinSeedLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := pk.PublicKey().String()
+
+	// A public key is required.
+	res := send(router, http.MethodPost, "/?sync=true", bytes.NewBufferString(`{"key":{"signAlgo":"ECDSA_P256"}}`))
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	res = send(router, http.MethodPost, "/?sync=true", bytes.NewBufferString(fmt.Sprintf(`{"key":{"publicKey":%q},"multiSig":{"threshold":2,"keyTypes":["local","local"]}}`, publicKey)))
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	var account accounts.Account
+	res = send(router, http.MethodPost, "/?sync=true", bytes.NewBufferString(fmt.Sprintf(`{"key":{"publicKey":%q}}`, publicKey)))
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	if account.Type != accounts.AccountTypeNonCustodial || len(account.Keys) != 0 {
+		t.Fatalf("expected a non-custodial account without stored keys, got %+v", account)
+	}
+
+	flowAccount, err := svcs.GetFlowClient().GetAccount(context.Background(), flow.HexToAddress(account.Address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(flowAccount.Keys) != 1 || flowAccount.Keys[0].PublicKey.String() != publicKey || flowAccount.Keys[0].Weight != flow.AccountKeyWeightThreshold {
+		t.Fatalf("expected the account to be created with the client key, got %+v", flowAccount.Keys)
+	}
+
+	// The service can not sign for the account.
+	code := "transaction() { prepare(signer: AuthAccount){} }"
+	body := bytes.NewBufferString(fmt.Sprintf("{\"code\":%q,\"arguments\":[]}", code))
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", account.Address), body)
+	assertStatusCode(t, res, http.StatusUnprocessableEntity)
+}
+
+func TestDisabledAccountSigningFails(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+	txHandler := handlers.NewTransactions(svcs.GetTransactions())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}", accHandler.Details()).Methods(http.MethodGet)
+	router.Handle("/{address}", accHandler.Disable()).Methods(http.MethodDelete)
+	router.Handle("/{address}/enable", accHandler.Enable()).Methods(http.MethodPost)
+	router.Handle("/{address}/sign", txHandler.Sign()).Methods(http.MethodPost)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	code := "transaction() { prepare(signer: AuthAccount){} }"
+	signBody := func() io.Reader {
+		return bytes.NewBufferString(fmt.Sprintf("{\"code\":%q,\"arguments\":[]}", code))
+	}
+
+	// Disable the account.
+	res = send(router, http.MethodDelete, fmt.Sprintf("/%s", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s", account.Address), nil)
+	assertStatusCode(t, res, http.StatusNotFound)
+
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", account.Address), signBody())
+	assertStatusCode(t, res, http.StatusForbidden)
+
+	// Re-enable the account, keys should be restored.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/enable", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", account.Address), signBody())
+	assertStatusCode(t, res, http.StatusCreated)
+}
+
+func TestFrozenAccountSigningFails(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+	txHandler := handlers.NewTransactions(svcs.GetTransactions())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/freeze", accHandler.Freeze()).Methods(http.MethodPost)
+	router.Handle("/{address}/unfreeze", accHandler.Unfreeze()).Methods(http.MethodPost)
+	router.Handle("/{address}/sign", txHandler.Sign()).Methods(http.MethodPost)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	code := "transaction() { prepare(signer: AuthAccount){} }"
+	signBody := func() io.Reader {
+		return bytes.NewBufferString(fmt.Sprintf("{\"code\":%q,\"arguments\":[]}", code))
+	}
+
+	// Freeze the account.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/freeze", account.Address), bytes.NewBufferString(`{"reason":"incident"}`))
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &account)
+
+	if !account.Frozen || account.FrozenReason != "incident" {
+		t.Fatalf("expected account to be frozen for an incident, got %+v", account)
+	}
+
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", account.Address), signBody())
+	assertStatusCode(t, res, http.StatusLocked)
+
+	// Unfreeze the account.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/unfreeze", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign", account.Address), signBody())
+	assertStatusCode(t, res, http.StatusCreated)
+}
+
+func TestAccountAliases(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Use(handlers.ResolveAccountAlias(svcs.GetAccounts()))
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}", accHandler.Details()).Methods(http.MethodGet)
+	router.Handle("/{address}", accHandler.Update()).Methods(http.MethodPatch)
+
+	var account, other accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	res = send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &other)
+
+	res = send(router, http.MethodPatch, fmt.Sprintf("/%s", account.Address), bytes.NewBufferString(`{"alias":"user-1234"}`))
+	assertStatusCode(t, res, http.StatusOK)
+
+	// Resolve the alias.
+	var resolved accounts.Account
+	res = send(router, http.MethodGet, "/alias:user-1234", nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &resolved)
+
+	if resolved.Address != account.Address || resolved.Alias == nil || *resolved.Alias != "user-1234" {
+		t.Fatalf("expected alias to resolve to %s, got %+v", account.Address, resolved)
+	}
+
+	// Aliases are unique.
+	res = send(router, http.MethodPatch, fmt.Sprintf("/%s", other.Address), bytes.NewBufferString(`{"alias":"user-1234"}`))
+	assertStatusCode(t, res, http.StatusConflict)
+
+	res = send(router, http.MethodPatch, fmt.Sprintf("/%s", other.Address), bytes.NewBufferString(`{"alias":"not valid"}`))
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	// Remove the alias, the label is left as is.
+	res = send(router, http.MethodPatch, "/alias:user-1234", bytes.NewBufferString(`{"alias":""}`))
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodGet, "/alias:user-1234", nil)
+	assertStatusCode(t, res, http.StatusNotFound)
+}
+
+func TestAccountKeyManagement(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/keys", accHandler.AddKey()).Methods(http.MethodPost)
+	router.Handle("/{address}/keys/{index}", accHandler.RevokeKey()).Methods(http.MethodDelete)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	keyCount := len(account.Keys)
+
+	// Add a generated custodial key.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/keys?sync=true", account.Address), nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	if len(account.Keys) != keyCount+1 {
+		t.Fatalf("expected %d keys, got %d", keyCount+1, len(account.Keys))
+	}
+
+	// Invalid weight.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/keys?sync=true", account.Address), bytes.NewBufferString(`{"weight":1001}`))
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	// Revoke all but the newly added key.
+	for _, k := range account.Keys[:keyCount] {
+		res = send(router, http.MethodDelete, fmt.Sprintf("/%s/keys/%d?sync=true", account.Address, k.Index), nil)
+		assertStatusCode(t, res, http.StatusOK)
+	}
+
+	fromJsonBody(t, res, &account)
+
+	if len(account.Keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(account.Keys))
+	}
+
+	// The last custodial key can not be revoked.
+	res = send(router, http.MethodDelete, fmt.Sprintf("/%s/keys/%d?sync=true", account.Address, account.Keys[0].Index), nil)
+	assertStatusCode(t, res, http.StatusBadRequest)
+}
+
+func TestAccountKeyRecovery(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	cfg.KeyRecoveryDelay = time.Hour
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/keys", accHandler.AddKey()).Methods(http.MethodPost)
+	router.Handle("/{address}/recoveries", accHandler.ListKeyRecoveries()).Methods(http.MethodGet)
+	router.Handle("/{address}/recoveries", accHandler.RequestKeyRecovery()).Methods(http.MethodPost)
+	router.Handle("/{address}/recoveries/{recoveryId}/approve", handlers.UseTenants(accHandler.ApproveKeyRecovery(), nil, map[string]string{"admin-key": "support"}, nil)).Methods(http.MethodPost)
+	router.Handle("/{address}/recoveries/{recoveryId}/execute", accHandler.ExecuteKeyRecovery()).Methods(http.MethodPost)
+	router.Handle("/{address}/recoveries/{recoveryId}/cancel", accHandler.CancelKeyRecovery()).Methods(http.MethodPost)
+
+	publicKey := func(seed byte) string {
+		pk, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, bytes.Repeat([]byte{seed}, crypto.MinSeedLength))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pk.PublicKey().String()
+	}
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	serviceKey := account.Keys[0].Index
+
+	// Add a client-held key.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/keys?sync=true", account.Address), bytes.NewBufferString(fmt.Sprintf(`{"publicKey":%q}`, publicKey(1))))
+	assertStatusCode(t, res, http.StatusCreated)
+
+	clientKey := int(cfg.DefaultAccountKeyCount)
+
+	// Keys held by the service can not be recovered.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/recoveries", account.Address), bytes.NewBufferString(fmt.Sprintf(`{"lostKeyIndex":%d,"newKey":{"publicKey":%q}}`, serviceKey, publicKey(2))))
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	var recovery accounts.KeyRecovery
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/recoveries", account.Address), bytes.NewBufferString(fmt.Sprintf(`{"lostKeyIndex":%d,"newKey":{"publicKey":%q}}`, clientKey, publicKey(2))))
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &recovery)
+
+	if recovery.Status != accounts.KeyRecoveryPending || recovery.Weight != flow.AccountKeyWeightThreshold {
+		t.Fatalf("expected a pending recovery with the weight of the lost key, got %+v", recovery)
+	}
+
+	// Only one recovery at a time.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/recoveries", account.Address), bytes.NewBufferString(fmt.Sprintf(`{"lostKeyIndex":%d,"newKey":{"publicKey":%q}}`, clientKey, publicKey(2))))
+	assertStatusCode(t, res, http.StatusConflict)
+
+	recoveryURL := fmt.Sprintf("/%s/recoveries/%s", account.Address, recovery.ID)
+
+	// Not approved yet.
+	res = send(router, http.MethodPost, recoveryURL+"/execute?sync=true", nil)
+	assertStatusCode(t, res, http.StatusConflict)
+
+	// Approvals need an admin.
+	_, err := svcs.GetAccounts().ApproveKeyRecovery(context.Background(), account.Address, recovery.ID.String())
+	var reqErr *wallet_errors.RequestError
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected approval without an admin to be forbidden, got %v", err)
+	}
+
+	res = sendWithHeaders(router, http.MethodPost, recoveryURL+"/approve", nil, map[string]string{"Authorization": "Bearer admin-key"})
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &recovery)
+
+	if recovery.ApprovedBy != "support" {
+		t.Fatalf("expected the recovery to be approved by %q, got %q", "support", recovery.ApprovedBy)
+	}
+
+	if recovery.ExecutableAt == nil || recovery.ExecutableAt.Sub(*recovery.ApprovedAt) != cfg.KeyRecoveryDelay {
+		t.Fatalf("expected the recovery to be executable after the delay, got %+v", recovery)
+	}
+
+	// Delay has not passed yet.
+	res = send(router, http.MethodPost, recoveryURL+"/execute?sync=true", nil)
+	assertStatusCode(t, res, http.StatusConflict)
+
+	res = send(router, http.MethodPost, recoveryURL+"/cancel", nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	// Request again with no delay.
+	cfg.KeyRecoveryDelay = 0
+
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/recoveries", account.Address), bytes.NewBufferString(fmt.Sprintf(`{"lostKeyIndex":%d,"newKey":{"publicKey":%q}}`, clientKey, publicKey(2))))
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &recovery)
+
+	recoveryURL = fmt.Sprintf("/%s/recoveries/%s", account.Address, recovery.ID)
+
+	res = sendWithHeaders(router, http.MethodPost, recoveryURL+"/approve", nil, map[string]string{"Authorization": "Bearer admin-key"})
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodPost, recoveryURL+"/execute?sync=true", nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &recovery)
+
+	if recovery.Status != accounts.KeyRecoveryCompleted || recovery.NewKeyIndex == nil || recovery.TransactionID == "" {
+		t.Fatalf("expected a completed recovery, got %+v", recovery)
+	}
+
+	flowAccount, err := svcs.GetFlowClient().GetAccount(context.Background(), flow.HexToAddress(account.Address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !flowAccount.Keys[clientKey].Revoked || flowAccount.Keys[*recovery.NewKeyIndex].PublicKey.String() != publicKey(2) {
+		t.Fatalf("expected key %d to be replaced, got %+v", clientKey, flowAccount.Keys)
+	}
+
+	// Completed recoveries can not be cancelled.
+	res = send(router, http.MethodPost, recoveryURL+"/cancel", nil)
+	assertStatusCode(t, res, http.StatusConflict)
+
+	var recoveries []accounts.KeyRecovery
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s/recoveries", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &recoveries)
+
+	if len(recoveries) != 2 || recoveries[0].Status != accounts.KeyRecoveryCompleted || recoveries[1].Status != accounts.KeyRecoveryCancelled {
+		t.Fatalf("expected a completed and a cancelled recovery, got %+v", recoveries)
+	}
+}
+
+func TestAccountContractDeployment(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/contracts/{name}", accHandler.DeployContract()).Methods(http.MethodPut)
+	router.Handle("/{address}/contracts/{name}", accHandler.RemoveContract()).Methods(http.MethodDelete)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	contract := func(greeting string) io.Reader {
+		code := fmt.Sprintf("pub contract Greeter { pub fun greet(): String { return %q } }", greeting)
+		return bytes.NewBuffer(asJson(&handlers.DeployContractRequest{Code: code}))
+	}
+
+	url := fmt.Sprintf("/%s/contracts/Greeter?sync=true", account.Address)
+
+	// Deploy.
+	res = send(router, http.MethodPut, url, contract("Hello"))
+	assertStatusCode(t, res, http.StatusCreated)
+
+	// Update.
+	res = send(router, http.MethodPut, url, contract("Hi"))
+	assertStatusCode(t, res, http.StatusCreated)
+
+	// Invalid name.
+	res = send(router, http.MethodPut, fmt.Sprintf("/%s/contracts/1Greeter?sync=true", account.Address), contract("Hello"))
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	// Remove.
+	res = send(router, http.MethodDelete, url, nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodDelete, url, nil)
+	assertStatusCode(t, res, http.StatusNotFound)
+}
+
+func TestAccountDetailsOnChain(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}", accHandler.Details()).Methods(http.MethodGet)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &account)
+
+	if account.OnChain != nil {
+		t.Fatal("expected no on-chain details by default")
+	}
+
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s?include=onchain", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &account)
+
+	if account.OnChain == nil {
+		t.Fatal("expected on-chain details")
+	}
+
+	if len(account.OnChain.Keys) != int(cfg.DefaultAccountKeyCount) {
+		t.Fatalf("expected %d on-chain keys, got %d", cfg.DefaultAccountKeyCount, len(account.OnChain.Keys))
+	}
+
+	if account.OnChain.StorageCapacity == 0 || account.OnChain.StorageUsed == 0 {
+		t.Fatalf("expected storage usage and capacity, got %+v", account.OnChain)
+	}
+
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s?include=everything", account.Address), nil)
+	assertStatusCode(t, res, http.StatusBadRequest)
+}
+
+func TestAccountOnChainKeys(t *testing.T) {
+	cfg := test.LoadConfig(t)
 	svcs := test.GetServices(t, cfg)
-	km := svcs.GetKeyManager()
 
 	accHandler := handlers.NewAccounts(svcs.GetAccounts())
 
 	router := mux.NewRouter()
-	router.Handle("/", accHandler.AddNonCustodialAccount()).Methods(http.MethodPost)
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/keys/onchain", accHandler.OnChainKeys()).Methods(http.MethodGet)
+	router.Handle("/{address}/keys/{index}", accHandler.RevokeKey()).Methods(http.MethodDelete)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	var keys []accounts.OnChainKey
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s/keys/onchain", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &keys)
+
+	if len(keys) != len(account.Keys) {
+		t.Fatalf("expected %d on-chain keys, got %d", len(account.Keys), len(keys))
+	}
+
+	for _, k := range keys {
+		if k.Revoked || k.Weight == 0 || k.PublicKey == "" {
+			t.Fatalf("expected a valid key, got %+v", k)
+		}
+	}
+
+	if len(keys) < 2 {
+		return
+	}
+
+	// Revoked keys are still listed.
+	res = send(router, http.MethodDelete, fmt.Sprintf("/%s/keys/%d?sync=true", account.Address, keys[0].Index), nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s/keys/onchain", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &keys)
+
+	if !keys[0].Revoked {
+		t.Fatalf("expected key %d to be revoked, got %+v", keys[0].Index, keys[0])
+	}
+}
+
+func TestAccountSync(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
 	router.Handle("/{address}", accHandler.Details()).Methods(http.MethodGet)
-	router.Handle("/{address}", accHandler.DeleteNonCustodialAccount()).Methods(http.MethodDelete)
+	router.Handle("/{address}/keys", accHandler.AddKey()).Methods(http.MethodPost)
+	router.Handle("/{address}/sync", accHandler.Sync()).Methods(http.MethodPost)
 
-	// Create a non-custodial account.
-	adminAuthorizer, err := km.AdminAuthorizer(context.Background())
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/keys?sync=true", account.Address), nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	keyCount := len(account.Keys)
+	revoked := account.Keys[keyCount-1].Index
+
+	// Revoke a key outside of the account service.
+	_, _, err := svcs.GetTransactions().Create(
+		context.Background(),
+		true,
+		account.Address,
+		template_strings.RevokeAccountKeyTransaction,
+		[]transactions.Argument{cadence.NewInt(revoked)},
+		transactions.General,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	nonCustodialAccount := test.NewFlowAccount(t, fc, adminAuthorizer.Address, adminAuthorizer.Key, adminAuthorizer.Signer)
+	var report accounts.SyncReport
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sync", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &report)
 
-	// Add created non-custodial account to watchlist.
-	account := accounts.Account{Address: nonCustodialAccount.Address.Hex()}
-	buf := bytes.NewBuffer(asJson(&account))
-	res := send(router, http.MethodPost, "/", buf)
+	for _, k := range report.Keys {
+		if k.Index == revoked && (k.Status != accounts.KeySyncStatusRevoked || !k.Removed) {
+			t.Fatalf("expected key %d to be reported as revoked and removed, got %+v", revoked, k)
+		}
+		if k.Index != revoked && k.Status != accounts.KeySyncStatusOK {
+			t.Fatalf("expected key %d to be in sync, got %+v", k.Index, k)
+		}
+	}
+
+	res = send(router, http.MethodGet, fmt.Sprintf("/%s", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &account)
+
+	if len(account.Keys) != keyCount-1 {
+		t.Fatalf("expected %d keys, got %d", keyCount-1, len(account.Keys))
+	}
+
+	// The revoked key is kept for auditing.
+	var removed keys.Storable
+	err = test.GetDatabase(t, cfg).
+		Unscoped().
+		Where(map[string]interface{}{"account_address": account.Address, "index": revoked}).
+		First(&removed).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !removed.DeletedAt.Valid || removed.RevokedAt == nil {
+		t.Fatalf("expected key %d to be soft deleted as revoked, got %+v", revoked, removed)
+	}
+}
+
+func TestAccountSyncKeepsMismatchedKeys(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}", accHandler.Details()).Methods(http.MethodGet)
+	router.Handle("/{address}/keys", accHandler.AddKey()).Methods(http.MethodPost)
+	router.Handle("/{address}/sync", accHandler.Sync()).Methods(http.MethodPost)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
 	assertStatusCode(t, res, http.StatusCreated)
 	fromJsonBody(t, res, &account)
 
-	// Ensure that account can be found.
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/keys?sync=true", account.Address), nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	keyCount := len(account.Keys)
+	if keyCount < 2 {
+		t.Fatalf("expected at least 2 keys, got %d", keyCount)
+	}
+
+	// Keys stored before public keys were have an empty public key, others
+	// may have one which can not be decoded.
+	db := test.GetDatabase(t, cfg)
+	publicKeys := map[int]string{
+		account.Keys[0].Index: "",
+		account.Keys[1].Index: "0xnot-a-public-key",
+	}
+	for index, publicKey := range publicKeys {
+		err := db.Model(&keys.Storable{}).
+			Where(map[string]interface{}{"account_address": account.Address, "index": index}).
+			Update("public_key", publicKey).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var report accounts.SyncReport
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sync", account.Address), nil)
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &report)
+
+	for _, k := range report.Keys {
+		if _, ok := publicKeys[k.Index]; !ok {
+			continue
+		}
+		if k.Status != accounts.KeySyncStatusMismatch || k.Removed {
+			t.Fatalf("expected key %d to be reported as mismatch and kept, got %+v", k.Index, k)
+		}
+	}
+
 	res = send(router, http.MethodGet, fmt.Sprintf("/%s", account.Address), nil)
 	assertStatusCode(t, res, http.StatusOK)
 	fromJsonBody(t, res, &account)
 
-	if account.Address != flow_helpers.FormatAddress(nonCustodialAccount.Address) {
-		t.Fatalf("read account address doesn't match - expected %q, got %q", flow_helpers.FormatAddress(nonCustodialAccount.Address), account.Address)
+	if len(account.Keys) != keyCount {
+		t.Fatalf("expected %d keys, got %d", keyCount, len(account.Keys))
 	}
+}
 
-	if account.Type != accounts.AccountTypeNonCustodial {
-		t.Fatalf("read account type doesn't match - expected %q, got %q", accounts.AccountTypeNonCustodial, account.Type)
+func TestSignAccountProof(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/sign-account-proof", accHandler.SignAccountProof()).Methods(http.MethodPost)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	req := handlers.SignAccountProofRequest{
+		AppIdentifier: "Test App",
+		Nonce:         "75f8587e5bd5f9dcc9909d0dae1f0ac5814458b2ae129620502cb936fde7120a",
 	}
 
-	// Remove the non-custodial account from watchlist.
-	res = send(router, http.MethodDelete, fmt.Sprintf("/%s", account.Address), nil)
+	var proof accounts.AccountProof
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign-account-proof", account.Address), bytes.NewBuffer(asJson(&req)))
 	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &proof)
 
-	// Ensure that it's not found anymore.
-	res = send(router, http.MethodGet, fmt.Sprintf("/%s", account.Address), nil)
-	assertStatusCode(t, res, http.StatusNotFound)
+	if len(proof.Signatures) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(proof.Signatures))
+	}
+
+	message, err := flow.EncodeAccountProofMessage(flow.HexToAddress(account.Address), req.AppIdentifier, req.Nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := account.Keys[0]
+	pbk, err := crypto.DecodePublicKeyHex(crypto.StringToSignatureAlgorithm(key.SignAlgo), strings.TrimPrefix(key.PublicKey, "0x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasher, err := crypto.NewHasher(crypto.StringToHashAlgorithm(key.HashAlgo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := hex.DecodeString(proof.Signatures[0].Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := pbk.Verify(signature, append(flow.UserDomainTag[:], message...), hasher); err != nil || !ok {
+		t.Fatalf("expected a valid signature, got %v, %v", ok, err)
+	}
+
+	// Too short nonce.
+	req.Nonce = "75f8587e"
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign-account-proof", account.Address), bytes.NewBuffer(asJson(&req)))
+	assertStatusCode(t, res, http.StatusBadRequest)
+}
+
+func TestSignMessage(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/{address}/sign-message", accHandler.SignMessage()).Methods(http.MethodPost)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	message := []byte("I own this account")
+	req := handlers.SignMessageRequest{Message: hex.EncodeToString(message)}
+
+	var signed accounts.SignedMessage
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign-message", account.Address), bytes.NewBuffer(asJson(&req)))
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &signed)
+
+	if len(signed.Signatures) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(signed.Signatures))
+	}
+
+	key := account.Keys[0]
+	pbk, err := crypto.DecodePublicKeyHex(crypto.StringToSignatureAlgorithm(key.SignAlgo), strings.TrimPrefix(key.PublicKey, "0x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasher, err := crypto.NewHasher(crypto.StringToHashAlgorithm(key.HashAlgo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := hex.DecodeString(signed.Signatures[0].Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := pbk.Verify(signature, append(flow.UserDomainTag[:], message...), hasher); err != nil || !ok {
+		t.Fatalf("expected a valid signature, got %v, %v", ok, err)
+	}
+
+	// Not hex.
+	req.Message = "I own this account"
+	res = send(router, http.MethodPost, fmt.Sprintf("/%s/sign-message", account.Address), bytes.NewBuffer(asJson(&req)))
+	assertStatusCode(t, res, http.StatusBadRequest)
 }
 
 func assertStatusCode(t *testing.T, res *http.Response, expected int) {
@@ -182,3 +1141,109 @@ func send(router *mux.Router, method, path string, body io.Reader) *http.Respons
 	router.ServeHTTP(rr, req)
 	return rr.Result()
 }
+
+func TestAccountExport(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/export", accHandler.Export()).Methods(http.MethodGet)
+
+	var account accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &account)
+
+	res = send(router, http.MethodGet, "/export", nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	var exported []accounts.ExportedAccount
+	dec := json.NewDecoder(res.Body)
+	for dec.More() {
+		var a accounts.ExportedAccount
+		if err := dec.Decode(&a); err != nil {
+			t.Fatal(err)
+		}
+		exported = append(exported, a)
+	}
+
+	if len(exported) == 0 || exported[len(exported)-1].Address != account.Address {
+		t.Fatalf("expected %q to be exported last, got %+v", account.Address, exported)
+	}
+
+	res = send(router, http.MethodGet, "/export?format=csv&type=custodial", nil)
+	assertStatusCode(t, res, http.StatusOK)
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if lines[0] != "address,type,label,createdAt,updatedAt" {
+		t.Fatalf("unexpected CSV header %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], account.Address+",custodial,") {
+		t.Fatalf("expected %q to be exported last, got %q", account.Address, lines[len(lines)-1])
+	}
+
+	res = send(router, http.MethodGet, "/export?format=xml", nil)
+	assertStatusCode(t, res, http.StatusBadRequest)
+}
+
+func TestAccountBulkDetails(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+
+	accHandler := handlers.NewAccounts(svcs.GetAccounts())
+
+	router := mux.NewRouter()
+	router.Handle("/", accHandler.Create()).Methods(http.MethodPost)
+	router.Handle("/details", accHandler.BulkDetails()).Methods(http.MethodPost)
+
+	var a1, a2 accounts.Account
+	res := send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &a1)
+
+	res = send(router, http.MethodPost, "/?sync=true", nil)
+	assertStatusCode(t, res, http.StatusCreated)
+	fromJsonBody(t, res, &a2)
+
+	// Unknown addresses are left out, the order of the request is kept.
+	unknown := flow.NewAddressGenerator(cfg.ChainID).SetIndex(1 << 20).Address().Hex()
+	body := asJson(&handlers.BulkDetailsRequest{Addresses: []string{a2.Address, unknown, a1.Address, a2.Address}})
+
+	var found []accounts.Account
+	res = send(router, http.MethodPost, "/details", bytes.NewBuffer(body))
+	assertStatusCode(t, res, http.StatusOK)
+	fromJsonBody(t, res, &found)
+
+	if len(found) != 2 || found[0].Address != a2.Address || found[1].Address != a1.Address {
+		t.Fatalf("expected %s and %s, got %+v", a2.Address, a1.Address, found)
+	}
+
+	for _, a := range found {
+		if len(a.Keys) == 0 {
+			t.Fatalf("expected keys for %s", a.Address)
+		}
+		for _, k := range a.Keys {
+			if len(k.Value) != 0 {
+				t.Fatal("expected private keys to be stripped")
+			}
+		}
+	}
+
+	res = send(router, http.MethodPost, "/details", bytes.NewBufferString(`{"addresses":["not-an-address"]}`))
+	assertStatusCode(t, res, http.StatusBadRequest)
+
+	tooMany := make([]string, cfg.MaxBulkAccountDetails+1)
+	for i := range tooMany {
+		tooMany[i] = a1.Address
+	}
+	res = send(router, http.MethodPost, "/details", bytes.NewBuffer(asJson(&handlers.BulkDetailsRequest{Addresses: tooMany})))
+	assertStatusCode(t, res, http.StatusBadRequest)
+}
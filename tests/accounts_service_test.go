@@ -2,10 +2,18 @@ package tests
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
 )
 
 func Test_Add_New_Non_Custodial_Account(t *testing.T) {
@@ -14,7 +22,7 @@ func Test_Add_New_Non_Custodial_Account(t *testing.T) {
 
 	addr := "0x0123456789"
 
-	a, err := svc.AddNonCustodialAccount(addr)
+	a, err := svc.AddNonCustodialAccount(context.Background(), addr)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -30,12 +38,12 @@ func Test_Add_Existing_Non_Custodial_Account_fails(t *testing.T) {
 
 	addr := "0x0123456789"
 
-	_, err := svc.AddNonCustodialAccount(addr)
+	_, err := svc.AddNonCustodialAccount(context.Background(), addr)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = svc.AddNonCustodialAccount(addr)
+	_, err = svc.AddNonCustodialAccount(context.Background(), addr)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -47,7 +55,7 @@ func Test_Add_Non_Custodial_Account_After_Delete(t *testing.T) {
 
 	addr := "0x0123456789"
 
-	_, err := svc.AddNonCustodialAccount(addr)
+	_, err := svc.AddNonCustodialAccount(context.Background(), addr)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -58,7 +66,7 @@ func Test_Add_Non_Custodial_Account_After_Delete(t *testing.T) {
 	}
 
 	// One must be able to add the same account again after it was deleted.
-	_, err = svc.AddNonCustodialAccount(addr)
+	_, err = svc.AddNonCustodialAccount(context.Background(), addr)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -97,7 +105,7 @@ func Test_Delete_Non_Custodial_Account_Is_Idempotent(t *testing.T) {
 
 	addr := "0x0123456789"
 
-	_, err := svc.AddNonCustodialAccount(addr)
+	_, err := svc.AddNonCustodialAccount(context.Background(), addr)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -134,7 +142,7 @@ func Test_Add_Multiple_New_Custodial_Accounts(t *testing.T) {
 		t.Skip("skipped as \"cfg.AdminProposalKeyCount\" is less than or equal to 1")
 	}
 
-	if accounts, err := svcs[0].GetAccounts().List(0, 0); err != nil {
+	if accounts, err := svcs[0].GetAccounts().List(0, 0, accounts.ListFilter{}); err != nil {
 		t.Fatal(err)
 	} else if len(accounts) > 1 {
 		t.Fatal("expected there to be only 1 account")
@@ -171,9 +179,323 @@ func Test_Add_Multiple_New_Custodial_Accounts(t *testing.T) {
 	default:
 	}
 
-	if accounts, err := svcs[0].GetAccounts().List(0, 0); err != nil {
+	if accounts, err := svcs[0].GetAccounts().List(0, 0, accounts.ListFilter{}); err != nil {
 		t.Fatal(err)
 	} else if len(accounts) < 1+accountsToCreate {
 		t.Fatalf("expected there to be %d accounts", 1+accountsToCreate)
 	}
 }
+
+func Test_Create_Accounts_Coalesced_Into_Batch(t *testing.T) {
+	cfg := test.LoadConfig(t)
+
+	accountsToCreate := 3
+	cfg.WorkerCount = uint(accountsToCreate)
+	cfg.AccountCreationBatchInterval = 2 * time.Second
+	cfg.MaxInFlightAccountCreations = 1
+
+	svcs := test.GetServices(t, cfg)
+	svc := svcs.GetAccounts()
+
+	jobIDs := make([]string, accountsToCreate)
+	for i := range jobIDs {
+		job, _, err := svc.Create(context.Background(), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobIDs[i] = job.ID.String()
+	}
+
+	txIDs := map[string]bool{}
+	addresses := map[string]bool{}
+	for _, id := range jobIDs {
+		job, err := test.WaitForJob(svcs.GetJobs(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txIDs[job.TransactionID] = true
+		addresses[job.Result] = true
+	}
+
+	if len(txIDs) != 1 {
+		t.Fatalf("expected accounts to be created in a single transaction, got %d", len(txIDs))
+	}
+
+	if len(addresses) != accountsToCreate {
+		t.Fatalf("expected %d distinct accounts, got %d", accountsToCreate, len(addresses))
+	}
+}
+
+func Test_List_Accounts_Filter_And_Sort(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svc := test.GetServices(t, cfg).GetAccounts()
+
+	addrs := []string{"0x01cf0e2f2f715450", "0x179b6b1cb6755e31"}
+
+	for _, addr := range addrs {
+		if _, err := svc.AddNonCustodialAccount(context.Background(), addr); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := svc.UpdateLabel(addr, "watched"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := svc.List(0, 0, accounts.ListFilter{Label: "watched", Sort: "address"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != len(addrs) {
+		t.Fatalf("expected %d accounts, got %d", len(addrs), len(list))
+	}
+
+	for i, a := range list {
+		if a.Address != addrs[i] {
+			t.Fatalf("expected account %d to be %q, got %q", i, addrs[i], a.Address)
+		}
+	}
+
+	list, err = svc.List(0, 0, accounts.ListFilter{Type: accounts.AccountTypeNonCustodial, Sort: "-address"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != len(addrs) || list[0].Address != addrs[1] {
+		t.Fatalf("expected non-custodial accounts in descending order, got %v", list)
+	}
+
+	if _, err := svc.List(0, 0, accounts.ListFilter{Sort: "keys"}); err == nil {
+		t.Fatal("expected error for unknown sort field, got nil")
+	}
+}
+
+func Test_Create_Account_With_Initial_Funding(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	svc := svcs.GetAccounts()
+
+	amount, err := cadence.NewUFix64("10.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, a, err := svc.CreateWithRequest(context.Background(), true, accounts.CreateRequest{InitialFundingAmount: "10.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := svcs.GetTokens().Details(context.Background(), "FlowToken", a.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance, ok := details.Balance.CadenceValue.(cadence.UFix64); !ok || balance < amount {
+		t.Fatalf("expected balance of at least %s, got %v", amount, details.Balance.CadenceValue)
+	}
+
+	if _, _, err := svc.CreateWithRequest(context.Background(), true, accounts.CreateRequest{InitialFundingAmount: "-1"}); err == nil {
+		t.Fatal("expected error for invalid funding amount, got nil")
+	}
+}
+
+func Test_Check_Account_Storage_Tops_Up(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	// Any account is considered to be running low on storage
+	cfg.StorageTopUpThreshold = 1 << 40
+	cfg.StorageTopUpAmount = "0.5"
+	svcs := test.GetServices(t, cfg)
+	svc := svcs.GetAccounts()
+
+	_, a, err := svc.Create(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checked, err := svc.CheckStorage(context.Background(), a.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checked.StorageCheckedAt == nil || checked.StorageCapacity == 0 || checked.StorageUsed == 0 {
+		t.Fatalf("expected storage usage to be tracked, got %+v", checked)
+	}
+
+	jj, err := svcs.GetJobs().List(-1, 0, jobs.ListFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var topUpJobID string
+	for _, j := range *jj {
+		if j.Type == accounts.AccountStorageTopUpJobType {
+			topUpJobID = j.ID.String()
+		}
+	}
+
+	if topUpJobID == "" {
+		t.Fatal("expected a storage top-up job to be scheduled")
+	}
+
+	job, err := test.WaitForJob(svcs.GetJobs(), topUpJobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.TransactionID == "" {
+		t.Fatalf("expected the account to be topped up, got result %q", job.Result)
+	}
+}
+
+func Test_Create_Account_With_Token_Vaults(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	svc := svcs.GetAccounts()
+
+	_, a, err := svc.CreateWithRequest(context.Background(), true, accounts.CreateRequest{Tokens: []string{"FUSD"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading the balance fails if the vault has not been set up
+	if _, err := svcs.GetTokens().Details(context.Background(), "FUSD", a.Address); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := svc.CreateWithRequest(context.Background(), true, accounts.CreateRequest{Tokens: []string{"NotAToken"}}); err == nil {
+		t.Fatal("expected error for unknown token, got nil")
+	}
+}
+
+func Test_Create_Multi_Signature_Account(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	svc := svcs.GetAccounts()
+
+	multiSig := &accounts.MultiSig{
+		Threshold: 2,
+		KeyTypes:  []string{keys.AccountKeyTypeLocal, keys.AccountKeyTypeLocal, keys.AccountKeyTypeLocal},
+	}
+
+	_, a, err := svc.CreateWithRequest(context.Background(), true, accounts.CreateRequest{MultiSig: multiSig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(a.Keys) != len(multiSig.KeyTypes) {
+		t.Fatalf("expected %d keys, got %d", len(multiSig.KeyTypes), len(a.Keys))
+	}
+
+	flowAccount, err := svcs.GetFlowClient().GetAccount(context.Background(), flow.HexToAddress(a.Address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range flowAccount.Keys {
+		if k.Weight != multiSig.KeyWeight() {
+			t.Fatalf("expected key weight %d, got %d", multiSig.KeyWeight(), k.Weight)
+		}
+	}
+
+	// A single key is not enough, the transactions service has to collect more signatures
+	code := "transaction { prepare(signer: AuthAccount) {} }"
+	if _, _, err := svcs.GetTransactions().Create(context.Background(), true, a.Address, code, nil, transactions.General); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := &accounts.MultiSig{Threshold: 3, KeyTypes: []string{keys.AccountKeyTypeLocal, keys.AccountKeyTypeLocal}}
+	if _, _, err := svc.CreateWithRequest(context.Background(), true, accounts.CreateRequest{MultiSig: invalid}); err == nil {
+		t.Fatal("expected error for threshold larger than key count, got nil")
+	}
+}
+
+func Test_Import_Accounts(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	svc := svcs.GetAccounts()
+
+	adminAuthorizer, err := svcs.GetKeyManager().AdminAuthorizer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flowAccount, privateKey := test.NewFlowAccountWithKey(t, svcs.GetFlowClient(), adminAuthorizer.Address, adminAuthorizer.Key, adminAuthorizer.Signer)
+	_, otherKey := test.NewFlowAccountWithKey(t, svcs.GetFlowClient(), adminAuthorizer.Address, adminAuthorizer.Key, adminAuthorizer.Signer)
+
+	csv := fmt.Sprintf("address,privateKey\n%s,%s\n%s,%s\n",
+		flowAccount.Address.Hex(), privateKey.String(),
+		flowAccount.Address.Hex(), otherKey.String(),
+	)
+
+	entries, err := accounts.ParseImportEntries([]byte(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := svc.Import(context.Background(), entries)
+
+	if !results[0].Imported {
+		t.Fatalf("expected account to be imported, got error: %s", results[0].Error)
+	}
+
+	// Key does not belong to the account
+	if results[1].Imported {
+		t.Fatal("expected import with a non-matching key to fail")
+	}
+
+	a, err := svc.Details(results[0].Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Type != accounts.AccountTypeCustodial {
+		t.Fatalf("expected a custodial account, got %s", a.Type)
+	}
+
+	// The imported key is usable for signing
+	code := "transaction { prepare(signer: AuthAccount) {} }"
+	if _, _, err := svcs.GetTransactions().Create(context.Background(), true, a.Address, code, nil, transactions.General); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Create_Account_Idempotent(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	svc := test.GetServices(t, cfg).GetAccounts()
+
+	key := "create-account-idempotent"
+
+	job1, _, err := svc.CreateIdempotent(context.Background(), false, key, accounts.CreateRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job2, _, err := svc.CreateIdempotent(context.Background(), false, key, accounts.CreateRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job1.ID != job2.ID {
+		t.Fatalf("expected the original job %s, got %s", job1.ID, job2.ID)
+	}
+
+	// Same key, different request
+	if _, _, err := svc.CreateIdempotent(context.Background(), false, key, accounts.CreateRequest{InitialFundingAmount: "1.0"}); err == nil {
+		t.Fatal("expected error when reusing an idempotency key for a different request, got nil")
+	}
+
+	_, a1, err := svc.CreateIdempotent(context.Background(), true, key+"-sync", accounts.CreateRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, a2, err := svc.CreateIdempotent(context.Background(), true, key+"-sync", accounts.CreateRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a1.Address != a2.Address {
+		t.Fatalf("expected the original account %s, got %s", a1.Address, a2.Address)
+	}
+}
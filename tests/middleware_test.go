@@ -8,7 +8,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/audit"
 	"github.com/flow-hydraulics/flow-wallet-api/handlers"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
 	"github.com/gorilla/mux"
 )
 
@@ -20,11 +23,17 @@ func Test_IdempotencyMiddleware(t *testing.T) {
 		rw.WriteHeader(http.StatusOK)
 	})
 
+	opts := handlers.IdempotencyHandlerOptions{
+		Expiry:       5000 * time.Millisecond,
+		IgnorePaths:  []string{"/ignored"},
+		HandledPaths: []string{"/handled", "/handled/{id}/items"},
+	}
+
 	router := mux.NewRouter()
-	router.Handle("/test", handlers.UseIdempotency(testHandler, handlers.IdempotencyHandlerOptions{
-		Expiry:      5000 * time.Millisecond,
-		IgnorePaths: []string{"/ignored"},
-	}, is)).Methods(http.MethodPost)
+	router.Handle("/test", handlers.UseIdempotency(testHandler, opts, is)).Methods(http.MethodPost)
+	router.Handle("/handled", handlers.UseIdempotency(testHandler, opts, is)).Methods(http.MethodPost)
+	router.Handle("/handled/{id}/items", handlers.UseIdempotency(testHandler, opts, is)).Methods(http.MethodPost)
+	router.Handle("/handled/{id}/other", handlers.UseIdempotency(testHandler, opts, is)).Methods(http.MethodPost)
 
 	ik := "idempotency-key-test"
 	body := bytes.NewBufferString("")
@@ -44,6 +53,22 @@ func Test_IdempotencyMiddleware(t *testing.T) {
 		assertStatusCode(t, res, http.StatusBadRequest)
 	})
 
+	t.Run("passes a used key to handled paths", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			res := sendWithHeaders(router, http.MethodPost, "/handled", body, map[string]string{"Idempotency-Key": ik})
+			assertStatusCode(t, res, http.StatusOK)
+		}
+	})
+
+	t.Run("matches segments of handled paths", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			res := sendWithHeaders(router, http.MethodPost, "/handled/1/items", body, map[string]string{"Idempotency-Key": ik})
+			assertStatusCode(t, res, http.StatusOK)
+		}
+
+		res := sendWithHeaders(router, http.MethodPost, "/handled/1/other", body, map[string]string{"Idempotency-Key": ik})
+		assertStatusCode(t, res, http.StatusConflict)
+	})
 }
 
 // TODO: Move to test utils
@@ -59,3 +84,182 @@ func sendWithHeaders(router *mux.Router, method, path string, body io.Reader, he
 	router.ServeHTTP(rr, req)
 	return rr.Result()
 }
+
+func Test_TenantsMiddleware(t *testing.T) {
+	apiKeys, err := tenants.ParseAPIKeys([]string{"shop:shop-key", "games:games-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adminKeys, err := tenants.ParseAdminAPIKeys([]string{"alice:admin-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Dummy endpoint responding with the tenant or admin of the request
+	testHandler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(tenants.FromContext(r.Context()) + "|" + tenants.AdminFromContext(r.Context()))) // nolint
+	})
+
+	rv := mux.NewRouter().PathPrefix("/{apiVersion}").Subrouter()
+	rv.Use(handlers.RequireAdmin)
+	rv.Handle("/test", testHandler).Methods(http.MethodGet)
+	rv.Handle("/health/ready", testHandler).Methods(http.MethodGet)
+	rv.Handle("/system/settings", testHandler).Methods(http.MethodGet, http.MethodPost)
+	rv.Handle("/system/deposits", testHandler).Methods(http.MethodGet)
+	rv.Handle("/ops/missing-fungible-token-vaults/start", testHandler).Methods(http.MethodGet)
+	rv.Handle("/tokens", testHandler).Methods(http.MethodGet, http.MethodPost)
+	rv.Handle("/tokens/{id}", testHandler).Methods(http.MethodPut, http.MethodDelete)
+	rv.Handle("/templates", testHandler).Methods(http.MethodGet, http.MethodPost)
+	rv.Handle("/templates/{name}", testHandler).Methods(http.MethodGet, http.MethodDelete)
+	rv.Handle("/accounts/{address}/templates/{name}", testHandler).Methods(http.MethodPost)
+
+	router := mux.NewRouter()
+	router.PathPrefix("/").Handler(handlers.UseTenants(rv, apiKeys, adminKeys, []string{"/v1/health"}))
+
+	t.Run("scopes the request to the tenant of the API key", func(t *testing.T) {
+		res := sendWithHeaders(router, http.MethodGet, "/v1/test", nil, map[string]string{"Authorization": "Bearer games-key"})
+		assertStatusCode(t, res, http.StatusOK)
+
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != "games|" {
+			t.Fatalf("expected tenant %q, got %q", "games", string(body))
+		}
+	})
+
+	t.Run("does not scope admin requests to a tenant", func(t *testing.T) {
+		res := sendWithHeaders(router, http.MethodGet, "/v1/test", nil, map[string]string{"Authorization": "Bearer admin-key"})
+		assertStatusCode(t, res, http.StatusOK)
+
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != "|alice" {
+			t.Fatalf("expected admin %q, got %q", "alice", string(body))
+		}
+	})
+
+	t.Run("returns 403 to tenants on admin routes", func(t *testing.T) {
+		testCases := []struct {
+			method, path string
+			status       int
+		}{
+			{http.MethodGet, "/v1/system/settings", http.StatusForbidden},
+			{http.MethodPost, "/v1/system/settings", http.StatusForbidden},
+			{http.MethodGet, "/v1/ops/missing-fungible-token-vaults/start", http.StatusForbidden},
+			{http.MethodPost, "/v1/tokens", http.StatusForbidden},
+			{http.MethodPut, "/v1/tokens/1", http.StatusForbidden},
+			{http.MethodDelete, "/v1/tokens/1", http.StatusForbidden},
+			{http.MethodGet, "/v1/tokens", http.StatusOK},
+			{http.MethodGet, "/v1/system/deposits", http.StatusOK},
+			{http.MethodPost, "/v1/templates", http.StatusForbidden},
+			{http.MethodDelete, "/v1/templates/mint", http.StatusForbidden},
+			{http.MethodGet, "/v1/templates", http.StatusOK},
+			{http.MethodGet, "/v1/templates/mint", http.StatusOK},
+			{http.MethodPost, "/v1/accounts/0x01cf0e2f2f715450/templates/mint", http.StatusOK},
+		}
+
+		for _, tc := range testCases {
+			res := sendWithHeaders(router, tc.method, tc.path, nil, map[string]string{"Authorization": "Bearer shop-key"})
+			assertStatusCode(t, res, tc.status)
+
+			res = sendWithHeaders(router, tc.method, tc.path, nil, map[string]string{"Authorization": "Bearer admin-key"})
+			assertStatusCode(t, res, http.StatusOK)
+		}
+	})
+
+	t.Run("returns 401 with an unknown API key", func(t *testing.T) {
+		res := sendWithHeaders(router, http.MethodGet, "/v1/test", nil, map[string]string{"Authorization": "Bearer other-key"})
+		assertStatusCode(t, res, http.StatusUnauthorized)
+	})
+
+	t.Run("returns 401 with missing header", func(t *testing.T) {
+		res := send(router, http.MethodGet, "/v1/test", nil)
+		assertStatusCode(t, res, http.StatusUnauthorized)
+	})
+
+	t.Run("ignores paths", func(t *testing.T) {
+		res := send(router, http.MethodGet, "/v1/health/ready", nil)
+		assertStatusCode(t, res, http.StatusOK)
+	})
+}
+
+func Test_AuditLogMiddleware(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	db := test.GetDatabase(t, cfg)
+	svc := audit.NewService(audit.NewGormStore(db))
+
+	// Dummy endpoint responding with the audit log entry of the request
+	testHandler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var id string
+		if auditID := audit.FromContext(r.Context()); auditID != nil {
+			id = auditID.String()
+		}
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte(id)) // nolint
+	})
+
+	router := mux.NewRouter()
+	router.Use(handlers.AuditLog(svc))
+	router.Handle("/accounts/{address}/transactions", testHandler).Methods(http.MethodGet, http.MethodPost)
+
+	t.Run("records mutating requests", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"code":"transaction {}"}`)
+		res := sendWithHeaders(router, http.MethodPost, "/accounts/0x01cf0e2f2f715450/transactions", body, map[string]string{"Authorization": "Bearer shop-key"})
+		assertStatusCode(t, res, http.StatusCreated)
+
+		id, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e, err := svc.Details(string(id), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if e.Route != "/accounts/{address}/transactions" || e.Address != "0x01cf0e2f2f715450" || e.StatusCode != http.StatusCreated {
+			t.Fatalf("unexpected entry: %+v", e)
+		}
+
+		if e.APIKeyID != audit.APIKeyID("shop-key") || e.SourceIP == "" {
+			t.Fatalf("expected the caller to be recorded, got: %+v", e)
+		}
+
+		if e.Fingerprint != audit.Fingerprint(http.MethodPost, "/accounts/0x01cf0e2f2f715450/transactions", []byte(`{"code":"transaction {}"}`)) {
+			t.Fatalf("unexpected fingerprint: %s", e.Fingerprint)
+		}
+	})
+
+	t.Run("ignores other requests", func(t *testing.T) {
+		res := send(router, http.MethodGet, "/accounts/0x01cf0e2f2f715450/transactions", nil)
+		assertStatusCode(t, res, http.StatusCreated)
+
+		entries, err := svc.List(0, 0, audit.ListFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+	})
+
+	t.Run("hides entries of other tenants", func(t *testing.T) {
+		entries, err := svc.List(0, 0, audit.ListFilter{TenantID: "games"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 0 {
+			t.Fatalf("expected no entries, got %d", len(entries))
+		}
+	})
+}
@@ -47,6 +47,12 @@ func NewFlowClient(t *testing.T, cfg *configs.Config) flow_helpers.FlowClient {
 }
 
 func NewFlowAccount(t *testing.T, fc flow_helpers.FlowClient, creatorAddress flow.Address, creatorKey *flow.AccountKey, creatorSigner crypto.Signer) *flow.Account {
+	a, _ := NewFlowAccountWithKey(t, fc, creatorAddress, creatorKey, creatorSigner)
+	return a
+}
+
+// NewFlowAccountWithKey creates a new account and also returns its private key.
+func NewFlowAccountWithKey(t *testing.T, fc flow_helpers.FlowClient, creatorAddress flow.Address, creatorKey *flow.AccountKey, creatorSigner crypto.Signer) (*flow.Account, crypto.PrivateKey) {
 	seed := make([]byte, seed_length)
 	readRandom(t, seed)
 
@@ -113,7 +119,7 @@ func NewFlowAccount(t *testing.T, fc flow_helpers.FlowClient, creatorAddress flo
 		t.Fatal(err)
 	}
 
-	return a
+	return a, privateKey
 }
 
 func readRandom(t *testing.T, buf []byte) {
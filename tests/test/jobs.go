@@ -11,7 +11,7 @@ func WaitForJob(jobSvc jobs.Service, jobId string) (*jobs.Job, error) {
 	for {
 		if job, err := jobSvc.Details(jobId); err != nil {
 			return nil, err
-		} else if job.State == jobs.Failed {
+		} else if job.State == jobs.Failed || job.State == jobs.Dead {
 			return nil, fmt.Errorf(job.Error)
 		} else if job.State == jobs.Complete {
 			return job, nil
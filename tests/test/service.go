@@ -21,6 +21,7 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
 	"github.com/flow-hydraulics/flow-wallet-api/tokens"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
 )
 
 type Services interface {
@@ -114,9 +115,11 @@ func GetServices(t *testing.T, cfg *configs.Config) Services {
 	if err != nil {
 		t.Fatal(err)
 	}
-	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp)
-	accountService := accounts.NewService(cfg, accounts.NewGormStore(db), km, fc, wp, transactionService, templateService)
-	jobService := jobs.NewService(jobs.NewGormStore(db))
+	accountStore := accounts.NewGormStore(db)
+	webhookService := webhooks.NewService(cfg, wp)
+	transactionService := transactions.NewService(cfg, transactions.NewGormStore(db), km, fc, wp, transactions.WithFreezeChecker(accountStore), transactions.WithWebhooks(webhookService))
+	accountService := accounts.NewService(cfg, accountStore, km, fc, wp, transactionService, templateService, accounts.WithWebhooks(webhookService))
+	jobService := jobs.NewService(jobs.NewGormStore(db), wp)
 	tokenService := tokens.NewService(cfg, tokens.NewGormStore(db), km, fc, wp, transactionService, templateService, accountService)
 	opsService := ops.NewService(cfg, ops.NewGormStore(db), templateService, transactionService, tokenService)
 
@@ -127,12 +130,14 @@ func GetServices(t *testing.T, cfg *configs.Config) Services {
 			return nil, err
 		}
 
-		token_count := len(tt)
-		event_types := make([]string, token_count)
+		event_types := make([]string, 0, len(tt))
 
 		// Listen for enabled tokens deposit events
-		for i, token := range tt {
-			event_types[i] = templates.DepositEventTypeFromToken(token)
+		for _, token := range tt {
+			if token.Disabled {
+				continue
+			}
+			event_types = append(event_types, templates.DepositEventTypeFromToken(token))
 		}
 
 		return event_types, nil
@@ -80,7 +80,7 @@ func Test_TokensSetup(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			job, tx, err := svc.Setup(context.Background(), tc.input.sync, tc.input.tokenName, tc.input.address)
 			// Wait for job to execute
-			for job != nil && job.State != jobs.Complete && job.State != jobs.Failed {
+			for job != nil && job.State != jobs.Complete && job.State != jobs.Failed && job.State != jobs.Dead {
 				time.Sleep(10 * time.Millisecond)
 			}
 			jobOpts := []cmp.Option{
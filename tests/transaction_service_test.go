@@ -2,12 +2,14 @@ package tests
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 )
 
@@ -172,3 +174,427 @@ func Test_TransactionProposalKeySequenceNumber(t *testing.T) {
 	})
 
 }
+
+func Test_TransactionBuildAndSend(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	txSvc := svcs.GetTransactions()
+
+	_, acc, err := svcs.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := txSvc.Build(ctx, acc.Address, "transaction() { prepare(signer: AuthAccount){} execute {}}", nil, transactions.General)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tx.PendingSend {
+		t.Fatal("expected a built transaction to be pending send")
+	}
+
+	// Built transactions are not on chain yet
+	details, err := txSvc.Details(ctx, tx.TransactionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !details.PendingSend {
+		t.Fatal("expected transaction details to be pending send")
+	}
+
+	if _, sent, err := txSvc.Send(ctx, true, tx.TransactionId); err != nil {
+		t.Fatal(err)
+	} else if sent.PendingSend {
+		t.Fatal("expected a sent transaction not to be pending send")
+	}
+
+	// A transaction can only be sent once
+	if _, _, err := txSvc.Send(ctx, true, tx.TransactionId); err == nil {
+		t.Fatal("expected an error when sending a transaction twice")
+	}
+
+	if _, err := txSvc.Details(ctx, tx.TransactionId); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_TransactionGasLimit(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	cfg.TransactionGasLimit = 1000
+	cfg.MaxTransactionGasLimit = 5000
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	tx, err := txSvc.Sign(ctx, cfg.AdminAddress, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.GasLimit != cfg.TransactionGasLimit {
+		t.Fatalf("expected default gas limit %d, got %d", cfg.TransactionGasLimit, tx.GasLimit)
+	}
+
+	tx, err = txSvc.Sign(ctx, cfg.AdminAddress, "", nil, transactions.WithGasLimit(cfg.MaxTransactionGasLimit))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.GasLimit != cfg.MaxTransactionGasLimit {
+		t.Fatalf("expected gas limit %d, got %d", cfg.MaxTransactionGasLimit, tx.GasLimit)
+	}
+
+	if _, err := txSvc.Sign(ctx, cfg.AdminAddress, "", nil, transactions.WithGasLimit(cfg.MaxTransactionGasLimit+1)); err == nil {
+		t.Fatal("expected an error when exceeding the maximum gas limit")
+	}
+}
+
+func Test_TransactionSigningRoles(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	txSvc := svcs.GetTransactions()
+
+	_, acc, err := svcs.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// User authorizes, admin proposes and pays
+	roles := transactions.Roles{Proposer: cfg.AdminAddress, Authorizers: []string{acc.Address}}
+	tx, err := txSvc.Sign(ctx, acc.Address, "", nil, transactions.WithRoles(roles))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.ProposalKey.Address.Hex() != strings.TrimPrefix(cfg.AdminAddress, "0x") {
+		t.Fatalf("expected admin to propose, got %s", tx.ProposalKey.Address)
+	}
+
+	if tx.Payer.Hex() != strings.TrimPrefix(cfg.AdminAddress, "0x") {
+		t.Fatalf("expected admin to pay, got %s", tx.Payer)
+	}
+
+	if len(tx.Authorizers) != 1 || tx.Authorizers[0].Hex() != strings.TrimPrefix(acc.Address, "0x") {
+		t.Fatalf("expected %s as the sole authorizer, got %v", acc.Address, tx.Authorizers)
+	}
+
+	if !addressExists(acc.Address, tx.PayloadSignatures) {
+		t.Fatal("expected the authorizer to sign the payload")
+	}
+
+	if !addressExists(cfg.AdminAddress, tx.EnvelopeSignatures) {
+		t.Fatal("expected the payer to sign the envelope")
+	}
+
+	// Keys for each role have to be held by the service
+	roles = transactions.Roles{Payer: "0x0ae53cb6e3f42a79"}
+	if _, err := txSvc.Sign(ctx, acc.Address, "", nil, transactions.WithRoles(roles)); err == nil {
+		t.Fatal("expected an error for a payer not managed by the service")
+	}
+}
+
+func Test_TransactionDryRun(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	code := "transaction(greeting: String) { prepare(signer: AuthAccount) { log(greeting) } }"
+
+	result, err := txSvc.DryRun(ctx, cfg.AdminAddress, code, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected a missing argument error, got %v", result.Errors)
+	}
+
+	args := []transactions.Argument{cadence.String("hello")}
+	result, err = txSvc.DryRun(ctx, cfg.AdminAddress, code, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Errors) != 0 {
+		t.Fatalf("expected no errors, got %v", result.Errors)
+	}
+
+	if len(result.Transaction.PayloadSignatures) != 0 || len(result.Transaction.EnvelopeSignatures) != 0 {
+		t.Fatal("expected a dry run transaction to be left unsigned")
+	}
+
+	// Nothing is stored
+	tt, _, err := txSvc.List(100, 0, transactions.ListFilter{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tt) != 0 {
+		t.Fatalf("expected no stored transactions, got %d", len(tt))
+	}
+}
+
+func Test_TransactionResultIsRecorded(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	_, tx, err := txSvc.Create(ctx, true, cfg.AdminAddress, "transaction() { prepare(signer: AuthAccount){} execute {}}", nil, transactions.General)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing left to fetch for a transaction sent synchronously
+	if err := txSvc.FetchResults(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := txSvc.Details(ctx, tx.TransactionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if details.Status != flow.TransactionStatusSealed.String() {
+		t.Fatalf("expected status %s, got %q", flow.TransactionStatusSealed, details.Status)
+	}
+
+	if details.BlockID == "" || details.BlockHeight == 0 {
+		t.Fatalf("expected block ID and height to be recorded, got %q and %d", details.BlockID, details.BlockHeight)
+	}
+
+	if details.ErrorMessage != "" {
+		t.Fatalf("expected no error, got %q", details.ErrorMessage)
+	}
+}
+
+func Test_TransactionListPagination(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := txSvc.Create(ctx, true, cfg.AdminAddress, "transaction() { prepare(signer: AuthAccount){} execute {}}", nil, transactions.General); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filter := transactions.ListFilter{Type: transactions.General}
+
+	page, next, err := txSvc.ListForAccount(cfg.AdminAddress, 2, 0, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page) != 2 || next == "" {
+		t.Fatalf("expected a full page with a cursor, got %d transactions and cursor %q", len(page), next)
+	}
+
+	filter.Cursor = next
+	rest, next, err := txSvc.ListForAccount(cfg.AdminAddress, 2, 0, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rest) != 1 || next != "" {
+		t.Fatalf("expected the last transaction without a cursor, got %d transactions and cursor %q", len(rest), next)
+	}
+
+	for _, tx := range page {
+		if tx.TransactionId == rest[0].TransactionId {
+			t.Fatal("expected pages not to overlap")
+		}
+	}
+
+	sealed, _, err := txSvc.ListForAccount(cfg.AdminAddress, 0, 0, transactions.ListFilter{Status: flow.TransactionStatusSealed.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sealed) != 3 {
+		t.Fatalf("expected 3 sealed transactions, got %d", len(sealed))
+	}
+
+	if _, _, err := txSvc.ListForAccount(cfg.AdminAddress, 0, 0, transactions.ListFilter{Status: "DONE"}); err == nil {
+		t.Fatal("expected an error for an invalid status")
+	}
+}
+
+func Test_TransactionCreateBatch(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	cfg.MaxTransactionBatchSize = 3
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	code := "transaction(n: Int) { prepare(signer: AuthAccount){} execute {}}"
+	requests := []transactions.JSONRequest{
+		{Code: code, Arguments: []transactions.Argument{cadence.NewInt(1)}},
+		{Code: code, Arguments: []transactions.Argument{cadence.String("not an Int")}},
+		{Code: code, Arguments: []transactions.Argument{cadence.NewInt(3)}},
+	}
+
+	items, err := txSvc.CreateBatch(ctx, cfg.AdminAddress, requests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != len(requests) {
+		t.Fatalf("expected %d items, got %d", len(requests), len(items))
+	}
+
+	for i, item := range items {
+		if item.Index != i {
+			t.Fatalf("expected index %d, got %d", i, item.Index)
+		}
+
+		failed := i == 1
+		if failed != (item.Error != "") || failed != (item.JobID == nil) {
+			t.Fatalf("unexpected item %d: %+v", i, item)
+		}
+	}
+
+	if _, err := txSvc.CreateBatch(ctx, cfg.AdminAddress, append(requests, requests[0])); err == nil {
+		t.Fatal("expected an error when exceeding the maximum batch size")
+	}
+}
+
+func Test_ExecuteScriptAtBlock(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	txSvc := svcs.GetTransactions()
+
+	header, err := svcs.GetFlowClient().GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := "pub fun main(): UInt64 { return getCurrentBlock().height }"
+
+	for name, at := range map[string]transactions.ScriptBlock{
+		"height": {Height: &header.Height},
+		"id":     {ID: header.ID.Hex()},
+	} {
+		res, err := txSvc.ExecuteScriptAt(ctx, code, nil, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.(cadence.UInt64) != cadence.UInt64(header.Height) {
+			t.Errorf("%s: expected block height %d, got %s", name, header.Height, res)
+		}
+	}
+
+	if _, err := txSvc.ExecuteScriptAt(ctx, code, nil, transactions.ScriptBlock{Height: &header.Height, ID: header.ID.Hex()}); err == nil {
+		t.Error("expected an error when both block height and ID are given")
+	}
+}
+
+func Test_ExecuteScriptAllowlist(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	cfg.EnforceScriptAllowlist = true
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	code := "pub fun main(): Int { return 1 }"
+
+	if _, err := txSvc.ExecuteScriptAt(ctx, code, nil, transactions.ScriptBlock{}); !strings.Contains(fmt.Sprint(err), "not in the allowlist") {
+		t.Fatalf("expected script not in the allowlist to be rejected, got: %v", err)
+	}
+
+	if _, err := txSvc.AllowCode(ctx, transactions.AllowedScript, transactions.CodeHash(code), "", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := txSvc.ExecuteScriptAt(ctx, code, nil, transactions.ScriptBlock{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scripts of the service itself are not checked
+	if _, err := txSvc.ExecuteScript(ctx, "pub fun main(): Int { return 2 }", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_TransactionAllowlist(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	cfg.EnforceTransactionAllowlist = true
+	txSvc := test.GetServices(t, cfg).GetTransactions()
+
+	code := "transaction() { prepare(signer: AuthAccount){} execute {}}"
+
+	if _, err := txSvc.Sign(ctx, cfg.AdminAddress, code, nil); !strings.Contains(fmt.Sprint(err), "not in the allowlist") {
+		t.Fatalf("expected transaction not in the allowlist to be rejected, got: %v", err)
+	}
+
+	if _, err := txSvc.AllowCode(ctx, transactions.AllowedTransaction, "", code, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := txSvc.Sign(ctx, cfg.AdminAddress, code, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scripts have an allowlist of their own
+	if _, err := txSvc.AllowCode(ctx, transactions.AllowedScript, "", code, "test"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_TransactionSponsor(t *testing.T) {
+	ctx := context.Background()
+	cfg := test.LoadConfig(t)
+	svcs := test.GetServices(t, cfg)
+	txSvc := svcs.GetTransactions()
+
+	_, acc, err := svcs.GetAccounts().Create(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := "transaction() { prepare(signer: AuthAccount){} execute {}}"
+	if _, err := txSvc.AllowCode(ctx, transactions.AllowedTransaction, "", code, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := svcs.GetKeyManager().UserAuthorizer(ctx, flow.HexToAddress(acc.Address))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := svcs.GetFlowClient().GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Built and signed by the user, as a non-custodial client would
+	flowTx := flow.NewTransaction().
+		SetScript([]byte(code)).
+		SetReferenceBlockID(block.ID).
+		SetGasLimit(100).
+		SetProposalKey(user.Address, user.Key.Index, user.Key.SequenceNumber).
+		SetPayer(flow.HexToAddress(cfg.AdminAddress)).
+		AddAuthorizer(user.Address)
+
+	if err := flowTx.SignPayload(user.Address, user.Key.Index, user.Signer); err != nil {
+		t.Fatal(err)
+	}
+
+	tooMuchGas := *flowTx
+	tooMuchGas.GasLimit = cfg.MaxSponsoredGasLimit + 1
+	if _, err := txSvc.Sponsor(ctx, true, tooMuchGas); err == nil {
+		t.Fatal("expected a transaction over the gas limit to be rejected")
+	}
+
+	tx, err := txSvc.Sponsor(ctx, true, *flowTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.Status != flow.TransactionStatusSealed.String() || tx.ErrorMessage != "" {
+		t.Fatalf("expected the transaction to be sealed, got %q: %s", tx.Status, tx.ErrorMessage)
+	}
+}
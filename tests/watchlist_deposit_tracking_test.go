@@ -9,6 +9,7 @@ import (
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/tests/test"
+	"github.com/flow-hydraulics/flow-wallet-api/tokens"
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 )
@@ -37,12 +38,12 @@ func Test_NonCustodialAccountDepositTracking(t *testing.T) {
 	t.Logf("non-custodial account: %q", nonCustodialAccount.Address.Hex())
 	t.Logf("    custodial account: %q", custodialAccount.Address)
 
-	_, err = accountSvc.AddNonCustodialAccount(nonCustodialAccount.Address.Hex())
+	_, err = accountSvc.AddNonCustodialAccount(context.Background(), nonCustodialAccount.Address.Hex())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	deposits, err := svcs.GetTokens().ListDeposits("0x"+nonCustodialAccount.Address.Hex(), "FlowToken")
+	deposits, err := svcs.GetTokens().ListDeposits("0x"+nonCustodialAccount.Address.Hex(), "FlowToken", 0, 0, tokens.DepositFilter{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -66,7 +67,7 @@ func Test_NonCustodialAccountDepositTracking(t *testing.T) {
 	// tracking to see & process the token deposit.
 	time.Sleep(time.Second)
 
-	deposits, err = svcs.GetTokens().ListDeposits("0x"+nonCustodialAccount.Address.Hex(), "FlowToken")
+	deposits, err = svcs.GetTokens().ListDeposits("0x"+nonCustodialAccount.Address.Hex(), "FlowToken", 0, 0, tokens.DepositFilter{})
 	if err != nil {
 		t.Fatal(err)
 	}
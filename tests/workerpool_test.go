@@ -361,6 +361,93 @@ func Test_WorkerPoolDoesntPickupFailedJob(t *testing.T) {
 	}
 }
 
+func Test_WorkerPoolRequeuesDeadJob(t *testing.T) {
+	cfg := test.LoadConfig(t)
+	db := test.GetDatabase(t, cfg)
+	jobStore := jobs.NewGormStore(db)
+
+	executedWG := &sync.WaitGroup{}
+	jobType := "job"
+	jobFunc := func(ctx context.Context, j *jobs.Job) error {
+		defer executedWG.Done()
+		return nil
+	}
+
+	t0 := time.Now()
+	j := &jobs.Job{
+		ID:            uuid.New(),
+		State:         jobs.Dead,
+		Type:          jobType,
+		TransactionID: "0xf00d",
+		Errors:        []string{"test job executor error"},
+		ExecCount:     11,
+		CreatedAt:     t0.Add(-10 * time.Minute),
+		UpdatedAt:     t0.Add(-10 * time.Minute),
+	}
+
+	// Directly insert "old" job into DB.
+	err := db.Create(j).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wp := jobs.NewWorkerPool(jobStore, 10, 10)
+	wp.RegisterExecutor(jobType, jobFunc)
+	jobService := jobs.NewService(jobStore, wp)
+
+	t.Cleanup(func() {
+		wp.Stop(false)
+	})
+	wp.Start()
+
+	dead, err := jobService.List(0, 0, jobs.ListFilter{State: jobs.Dead})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*dead) != 1 || (*dead)[0].ID != j.ID {
+		t.Fatalf("expected the dead job to be listed, got %+v", *dead)
+	}
+
+	executedWG.Add(1)
+	if _, err := jobService.Requeue(j.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	executedWG.Wait()
+
+	var job jobs.Job
+	for {
+		job, err = jobStore.Job(j.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if time.Since(job.UpdatedAt) < 250*time.Millisecond {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		break
+	}
+
+	if job.State != jobs.Complete {
+		t.Fatalf("expected job.State = %q, got %q", jobs.Complete, job.State)
+	}
+
+	if job.ExecCount != 1 {
+		t.Fatalf("expected job.ExecCount = 1 after requeueing, got %d", job.ExecCount)
+	}
+
+	if len(job.Errors) != 1 {
+		t.Fatalf("expected the errors of the dead job to be kept, got %v", job.Errors)
+	}
+
+	if _, err := jobService.Requeue(j.ID.String()); err == nil {
+		t.Fatal("expected requeueing a completed job to fail")
+	}
+}
+
 func Test_ExceedingWorkerpoolCapacity(t *testing.T) {
 	cfg := test.LoadConfig(t)
 	db := test.GetDatabase(t, cfg)
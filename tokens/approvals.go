@@ -0,0 +1,302 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// AllowWithdrawalDestination adds an address to the destination allowlist of a token.
+func (s *ServiceImpl) AllowWithdrawalDestination(ctx context.Context, tokenName, address, description string) (*WithdrawalDestination, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	dd, err := s.store.WithdrawalDestinations(token.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range dd {
+		if d.Address == address {
+			return nil, &wallet_errors.RequestError{
+				StatusCode: http.StatusConflict,
+				Err:        fmt.Errorf("%s is already an allowed destination of %s", address, token.Name),
+			}
+		}
+	}
+
+	d := &WithdrawalDestination{TokenName: token.Name, Address: address, Description: description}
+	if err := s.store.InsertWithdrawalDestination(d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ListWithdrawalDestinations lists the destination allowlist of a token.
+func (s *ServiceImpl) ListWithdrawalDestinations(ctx context.Context, tokenName string) ([]WithdrawalDestination, error) {
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.WithdrawalDestinations(token.Name)
+}
+
+// RemoveWithdrawalDestination removes an address from the destination allowlist of a token.
+func (s *ServiceImpl) RemoveWithdrawalDestination(ctx context.Context, tokenName, address string) error {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return err
+	}
+
+	return s.store.RemoveWithdrawalDestination(token.Name, address)
+}
+
+// checkDestination checks the recipient of a withdrawal against the
+// destination allowlist of its token, if the token has one.
+func (s *ServiceImpl) checkDestination(w *Withdrawal) error {
+	dd, err := s.store.WithdrawalDestinations(w.TokenName)
+	if err != nil {
+		return err
+	}
+
+	if len(dd) == 0 {
+		return nil
+	}
+
+	for _, d := range dd {
+		if d.Address == w.RecipientAddress {
+			return nil
+		}
+	}
+
+	return &wallet_errors.RequestError{
+		StatusCode: http.StatusForbidden,
+		Err:        fmt.Errorf("%s is not an allowed destination of %s", w.RecipientAddress, w.TokenName),
+	}
+}
+
+// needsApproval tells whether the amount of a withdrawal, or the total of a
+// batch of withdrawals of the same sender and token, is above the approval
+// threshold of its token.
+func (s *ServiceImpl) needsApproval(ww ...*Withdrawal) (bool, error) {
+	if len(ww) == 0 || ww[0].FtAmount == "" {
+		// Non-fungible tokens
+		return false, nil
+	}
+
+	threshold, ok := tokenAmount(s.rules.approvalThresholds, ww[0].TokenName)
+	if !ok {
+		return false, nil
+	}
+
+	total := new(big.Int)
+	for _, w := range ww {
+		amount, err := decimal.ParseUFix64(w.FtAmount)
+		if err != nil {
+			return false, err
+		}
+		total.Add(total, new(big.Int).SetUint64(uint64(amount)))
+	}
+
+	return total.Cmp(new(big.Int).SetUint64(uint64(threshold))) > 0, nil
+}
+
+// requestApproval records a new withdrawal pending the approval of an admin.
+func (s *ServiceImpl) requestApproval(ctx context.Context, w *Withdrawal) (*Withdrawal, error) {
+	// Rejected up front, as with withdrawals which are sent right away
+	if err := transactions.CheckNotFrozen(s.accounts, w.SenderAddress); err != nil {
+		return nil, err
+	}
+
+	w.State = WithdrawalPendingApproval
+	// Empty for tenants, any admin may approve their withdrawals
+	w.RequestedBy = tenants.AdminFromContext(ctx)
+
+	// The amount is reserved while the withdrawal is pending
+	if err := s.insertWithdrawals(ctx, []*Withdrawal{w}); err != nil {
+		return nil, err
+	}
+
+	log.
+		WithFields(log.Fields{"withdrawalId": w.ID, "token": w.TokenName, "amount": w.FtAmount}).
+		Info("Withdrawal pending approval")
+
+	return w, nil
+}
+
+// ApproveWithdrawal approves a withdrawal pending approval and sends its
+// transfer in a job. Withdrawals are approved by an admin other than the
+// one who requested them.
+func (s *ServiceImpl) ApproveWithdrawal(ctx context.Context, withdrawalId string) (*Withdrawal, error) {
+	adminID := tenants.AdminFromContext(ctx)
+	if adminID == "" {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("withdrawals can only be approved with an admin API key"),
+		}
+	}
+
+	w, err := s.pendingApproval(ctx, withdrawalId)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.RequestedBy == adminID {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("withdrawals can not be approved by the admin who requested them"),
+		}
+	}
+
+	w.State = WithdrawalRequested
+	w.ResolvedBy = adminID
+
+	if err := s.resolveApproval(w); err != nil {
+		return nil, err
+	}
+
+	// The job belongs to the tenant of the withdrawal rather than the approver
+	ctx = tenants.NewContext(ctx, w.TenantID)
+
+	if err := s.scheduleWithdrawal(ctx, w, jobs.PriorityHigh); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// RejectWithdrawal rejects a withdrawal pending approval, it is not sent.
+func (s *ServiceImpl) RejectWithdrawal(ctx context.Context, withdrawalId string) (*Withdrawal, error) {
+	w, err := s.pendingApproval(ctx, withdrawalId)
+	if err != nil {
+		return nil, err
+	}
+
+	w.State = WithdrawalRejected
+	w.Error = "rejected"
+	w.ResolvedBy = tenants.AdminFromContext(ctx)
+
+	if err := s.resolveApproval(w); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// ExpireApprovals rejects the withdrawals which have been pending approval
+// for longer than the approval timeout.
+func (s *ServiceImpl) ExpireApprovals(ctx context.Context) error {
+	timeout := s.cfg.WithdrawalApprovalTimeout
+	if timeout <= 0 {
+		return nil
+	}
+
+	ww, err := s.store.ExpiredApprovals(time.Now().Add(-timeout))
+	if err != nil {
+		return err
+	}
+
+	for _, w := range ww {
+		w.State = WithdrawalRejected
+		w.Error = "approval timed out"
+
+		ok, err := s.store.ResolvePendingWithdrawal(w)
+		if err != nil {
+			return err
+		}
+
+		// Approved or rejected in the meantime
+		if !ok {
+			continue
+		}
+
+		log.
+			WithFields(log.Fields{"withdrawalId": w.ID, "token": w.TokenName, "amount": w.FtAmount}).
+			Info("Withdrawal approval timed out")
+	}
+
+	return nil
+}
+
+// pendingApproval returns a withdrawal which is pending approval.
+func (s *ServiceImpl) pendingApproval(ctx context.Context, withdrawalId string) (*Withdrawal, error) {
+	id, err := uuid.Parse(withdrawalId)
+	if err != nil {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("invalid withdrawal id: %q", withdrawalId),
+		}
+	}
+
+	w, err := s.store.Withdrawal(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Withdrawals of other tenants are not found
+	if tenantID := tenants.FromContext(ctx); tenantID != "" && tenantID != w.TenantID {
+		return nil, fmt.Errorf("record not found")
+	}
+
+	if w.State != WithdrawalPendingApproval {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("withdrawal is %s, not pending approval", w.State),
+		}
+	}
+
+	// Timed out but not rejected by ExpireApprovals yet
+	if timeout := s.cfg.WithdrawalApprovalTimeout; timeout > 0 && time.Since(w.CreatedAt) >= timeout {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("withdrawal approval timed out"),
+		}
+	}
+
+	return w, nil
+}
+
+// resolveApproval records the approval or rejection of a withdrawal, failing
+// if it was resolved in the meantime.
+func (s *ServiceImpl) resolveApproval(w *Withdrawal) error {
+	ok, err := s.store.ResolvePendingWithdrawal(w)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return &wallet_errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("withdrawal is not pending approval"),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+)
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var reqErr *wallet_errors.RequestError
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != status {
+		t.Fatalf("expected an error with status %d, got %v", status, err)
+	}
+}
+
+func newApprovalTestService(t *testing.T, timeout time.Duration) (*ServiceImpl, *dummyChain, *dummyWorkerPool) {
+	t.Helper()
+
+	svc, chain, wp := newTestService(t, &configs.Config{
+		WithdrawalApprovalThresholds: []string{"FlowToken:100.0"},
+		WithdrawalApprovalTimeout:    timeout,
+	}, 2)
+
+	chain.balances[chain.accounts[0].Address] = mustUFix64(t, "1000.0")
+
+	return svc, chain, wp
+}
+
+func TestNeedsApproval(t *testing.T) {
+	svc, _, _ := newApprovalTestService(t, 0)
+
+	testCases := []struct {
+		name     string
+		w        Withdrawal
+		approval bool
+	}{
+		{name: "below threshold", w: Withdrawal{TokenName: "FlowToken", FtAmount: "99.99999999"}},
+		{name: "at threshold", w: Withdrawal{TokenName: "FlowToken", FtAmount: "100.00000000"}},
+		{name: "above threshold", w: Withdrawal{TokenName: "FlowToken", FtAmount: "100.00000001"}, approval: true},
+		{name: "token name case", w: Withdrawal{TokenName: "flowtoken", FtAmount: "500.0"}, approval: true},
+		{name: "token without threshold", w: Withdrawal{TokenName: "FUSD", FtAmount: "500.0"}},
+		{name: "non-fungible token", w: Withdrawal{TokenName: "FlowToken", NftID: 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			approval, err := svc.needsApproval(&tc.w)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if approval != tc.approval {
+				t.Fatalf("expected approval %t, got %t", tc.approval, approval)
+			}
+		})
+	}
+}
+
+func TestBatchWithdrawalApproval(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, _ := newApprovalTestService(t, 0)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+	svc.cfg.MaxWithdrawalBatchSize = 10
+	chain.tokens[0].NameLowerCase = "flowtoken"
+
+	batch := func(amounts ...string) []*Withdrawal {
+		ww := make([]*Withdrawal, len(amounts))
+		for i, amount := range amounts {
+			ww[i] = &Withdrawal{TokenName: "FlowToken", FtAmount: amount}
+		}
+		return ww
+	}
+
+	testCases := []struct {
+		name     string
+		ww       []*Withdrawal
+		approval bool
+	}{
+		{name: "total at threshold", ww: batch("60.0", "40.0")},
+		{name: "total above threshold", ww: batch("60.0", "40.00000001"), approval: true},
+		{name: "many small amounts", ww: batch("30.0", "30.0", "30.0", "30.0"), approval: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			approval, err := svc.needsApproval(tc.ww...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if approval != tc.approval {
+				t.Fatalf("expected approval %t, got %t", tc.approval, approval)
+			}
+		})
+	}
+
+	// Each withdrawal is below the threshold, the total is not
+	_, err := svc.CreateBatchWithdrawal(ctx, false, a, "FlowToken", []WithdrawalRequest{
+		{Recipient: b, FtAmount: "60.0"},
+		{Recipient: b, FtAmount: "40.00000001"},
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+	if !strings.Contains(err.Error(), "needs approval") {
+		t.Fatalf("expected the batch to need approval, got %v", err)
+	}
+}
+
+func TestWithdrawalDestinationAllowlist(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, _ := newApprovalTestService(t, 0)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+	other := "0x01cf0e2f2f715450"
+
+	withdraw := func(recipient string) error {
+		_, err := svc.CreateWithdrawal(ctx, false, a, WithdrawalRequest{TokenName: "FlowToken", Recipient: recipient, FtAmount: "1.0"})
+		return err
+	}
+
+	// Without an allowlist any recipient is allowed
+	if err := withdraw(other); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.AllowWithdrawalDestination(ctx, "FlowToken", b, "exchange"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := svc.AllowWithdrawalDestination(ctx, "FlowToken", b, "exchange")
+	assertStatus(t, err, http.StatusConflict)
+
+	assertStatus(t, withdraw(other), http.StatusForbidden)
+
+	if err := withdraw(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.RemoveWithdrawalDestination(ctx, "FlowToken", b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := withdraw(other); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApproveWithdrawal(t *testing.T) {
+	alice := tenants.NewAdminContext(context.Background(), "alice")
+	bob := tenants.NewAdminContext(context.Background(), "bob")
+
+	svc, chain, wp := newApprovalTestService(t, 0)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	w, err := svc.CreateWithdrawal(alice, true, a, WithdrawalRequest{TokenName: "FlowToken", Recipient: b, FtAmount: "500.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.State != WithdrawalPendingApproval || w.RequestedBy != "alice" || len(wp.scheduled) != 0 {
+		t.Fatalf("expected a withdrawal pending approval requested by alice, got %+v", w)
+	}
+
+	// Tenants and the requester can not approve the withdrawal
+	_, err = svc.ApproveWithdrawal(tenants.NewContext(context.Background(), "shop"), w.ID.String())
+	assertStatus(t, err, http.StatusForbidden)
+
+	_, err = svc.ApproveWithdrawal(context.Background(), w.ID.String())
+	assertStatus(t, err, http.StatusForbidden)
+
+	_, err = svc.ApproveWithdrawal(alice, w.ID.String())
+	assertStatus(t, err, http.StatusForbidden)
+
+	approved, err := svc.ApproveWithdrawal(bob, w.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if approved.State != WithdrawalRequested || approved.ResolvedBy != "bob" || len(wp.scheduled) != 1 {
+		t.Fatalf("expected a requested withdrawal approved by bob, got %+v", approved)
+	}
+
+	stored, err := svc.store.Withdrawal(w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.State != WithdrawalRequested || stored.ResolvedBy != "bob" {
+		t.Fatalf("expected the approval to be stored, got %+v", stored)
+	}
+
+	_, err = svc.ApproveWithdrawal(bob, w.ID.String())
+	assertStatus(t, err, http.StatusConflict)
+
+	_, err = svc.RejectWithdrawal(bob, w.ID.String())
+	assertStatus(t, err, http.StatusConflict)
+
+	// Withdrawals of tenants can be approved by any admin
+	w, err = svc.CreateWithdrawal(context.Background(), false, a, WithdrawalRequest{TokenName: "FlowToken", Recipient: b, FtAmount: "200.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ApproveWithdrawal(alice, w.ID.String()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveApprovalRace(t *testing.T) {
+	ctx := tenants.NewAdminContext(context.Background(), "alice")
+	svc, chain, _ := newApprovalTestService(t, 0)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	w, err := svc.CreateWithdrawal(context.Background(), false, a, WithdrawalRequest{TokenName: "FlowToken", Recipient: b, FtAmount: "500.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both read the withdrawal while it is pending approval
+	approval, err := svc.pendingApproval(ctx, w.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rejection, err := svc.pendingApproval(ctx, w.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rejection.State = WithdrawalRejected
+	if err := svc.resolveApproval(rejection); err != nil {
+		t.Fatal(err)
+	}
+
+	approval.State = WithdrawalRequested
+	assertStatus(t, svc.resolveApproval(approval), http.StatusConflict)
+
+	stored, err := svc.store.Withdrawal(w.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.State != WithdrawalRejected {
+		t.Fatalf("expected the withdrawal to stay rejected, got %s", stored.State)
+	}
+}
+
+func TestExpireApprovals(t *testing.T) {
+	ctx := tenants.NewAdminContext(context.Background(), "alice")
+	svc, chain, _ := newApprovalTestService(t, time.Hour)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	request := WithdrawalRequest{TokenName: "FlowToken", Recipient: b, FtAmount: "200.0"}
+
+	expired, err := svc.CreateWithdrawal(context.Background(), false, a, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired.CreatedAt = time.Now().Add(-2 * time.Hour)
+	if err := svc.store.UpdateWithdrawal(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := svc.CreateWithdrawal(context.Background(), false, a, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Timed out withdrawals can not be approved before they are rejected
+	_, err = svc.ApproveWithdrawal(ctx, expired.ID.String())
+	assertStatus(t, err, http.StatusConflict)
+
+	if err := svc.ExpireApprovals(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := svc.store.Withdrawal(expired.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.State != WithdrawalRejected || w.Error != "approval timed out" {
+		t.Fatalf("expected the withdrawal to be rejected, got %+v", w)
+	}
+
+	w, err = svc.store.Withdrawal(pending.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.State != WithdrawalPendingApproval {
+		t.Fatalf("expected the withdrawal to be pending approval, got %+v", w)
+	}
+
+	// Expired withdrawals no longer reserve their amount
+	balance, err := svc.LedgerBalance(ctx, a, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.Withdrawing != "200.00000000" {
+		t.Fatalf("expected 200.0 withdrawing, got %+v", balance)
+	}
+}
@@ -0,0 +1,99 @@
+package tokens
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+)
+
+type balanceCacheKey struct {
+	address   string
+	tokenName string
+}
+
+type balanceCacheEntry struct {
+	details   Details
+	checkedAt time.Time
+}
+
+// balanceCache holds token details (balances) read from the chain per
+// account and token, in memory of a single instance.
+type balanceCache struct {
+	mu      sync.Mutex
+	entries map[balanceCacheKey]balanceCacheEntry
+}
+
+func newBalanceCache() *balanceCache {
+	return &balanceCache{entries: make(map[balanceCacheKey]balanceCacheEntry)}
+}
+
+func (c *balanceCache) get(address, tokenName string, ttl time.Duration) (Details, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := balanceCacheKey{address, strings.ToLower(tokenName)}
+
+	e, ok := c.entries[k]
+	if !ok {
+		return Details{}, time.Time{}, false
+	}
+
+	if time.Since(e.checkedAt) >= ttl {
+		delete(c.entries, k)
+		return Details{}, time.Time{}, false
+	}
+
+	return e.details, e.checkedAt, true
+}
+
+func (c *balanceCache) set(address, tokenName string, details Details, checkedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[balanceCacheKey{address, strings.ToLower(tokenName)}] = balanceCacheEntry{details, checkedAt}
+}
+
+// invalidate drops the cached balance of a token of an account, e.g. once a
+// transfer from or to the account is recorded.
+func (c *balanceCache) invalidate(address, tokenName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, balanceCacheKey{address, strings.ToLower(tokenName)})
+}
+
+// CachedDetails is Details with the result cached per account and token for
+// BalanceCacheTTL, unless refresh is given. The details tell when the
+// balance was read from the chain.
+func (s *ServiceImpl) CachedDetails(ctx context.Context, tokenName, address string, refresh bool) (*Details, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cfg.BalanceCacheTTL > 0 && !refresh {
+		if details, checkedAt, ok := s.balances.get(address, tokenName, s.cfg.BalanceCacheTTL); ok {
+			details.CheckedAt = &checkedAt
+			return &details, nil
+		}
+	}
+
+	checkedAt := time.Now()
+
+	details, err := s.Details(ctx, tokenName, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cfg.BalanceCacheTTL > 0 {
+		s.balances.set(address, tokenName, *details, checkedAt)
+	}
+
+	details.CheckedAt = &checkedAt
+
+	return details, nil
+}
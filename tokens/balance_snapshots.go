@@ -0,0 +1,224 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// Layout of the dates of balance snapshots.
+const SnapshotDateLayout = "2006-01-02"
+
+// BalanceSnapshot is the balance of a fungible token held by a managed
+// account as read from the chain once per day (UTC). Accounts without a vault
+// of the token are not snapshotted.
+type BalanceSnapshot struct {
+	ID             uint64    `json:"-" gorm:"column:id;primaryKey"`
+	AccountAddress string    `json:"address" gorm:"column:account_address;uniqueIndex:idx_balance_snapshots_account_token_date;not null"`
+	TokenName      string    `json:"token" gorm:"column:token_name;uniqueIndex:idx_balance_snapshots_account_token_date;index:idx_balance_snapshots_token_date;not null"`
+	Date           string    `json:"date" gorm:"column:date;size:10;uniqueIndex:idx_balance_snapshots_account_token_date;index:idx_balance_snapshots_token_date;not null"`
+	Balance        string    `json:"balance" gorm:"column:balance"`
+	TenantID       string    `json:"-" gorm:"column:tenant_id;index"`
+	TakenAt        time.Time `json:"takenAt"`
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "balance_snapshots"
+}
+
+// BalanceSnapshotRun records that the balance snapshots of a token have been
+// taken of all accounts on a date.
+type BalanceSnapshotRun struct {
+	TokenName   string    `gorm:"column:token_name;primaryKey"`
+	Date        string    `gorm:"column:date;size:10;primaryKey"`
+	CompletedAt time.Time `gorm:"column:completed_at"`
+}
+
+func (BalanceSnapshotRun) TableName() string {
+	return "balance_snapshot_runs"
+}
+
+// BalanceSnapshotFilter restricts a balance snapshot listing. From and To
+// are inclusive dates in SnapshotDateLayout.
+type BalanceSnapshotFilter struct {
+	Address   string
+	TokenName string
+	TenantID  string
+	From      string
+	To        string
+}
+
+// SnapshotBalances takes the balance snapshots of the current day of all
+// enabled fungible tokens which have not been taken of all accounts yet. A
+// token counts as snapshotted for the day once a run over all accounts
+// completed, a failed run is repeated by the next call keeping the snapshots
+// already taken. It is safe to call repeatedly and from several instances.
+func (s *ServiceImpl) SnapshotBalances(ctx context.Context) error {
+	tt, err := s.templates.ListTokensFull(templates.FT)
+	if err != nil {
+		return err
+	}
+
+	date := time.Now().UTC().Format(SnapshotDateLayout)
+
+	for i := range tt {
+		token := &tt[i]
+
+		completed, err := s.store.BalanceSnapshotRunCompleted(token.Name, date)
+		if err != nil {
+			return err
+		}
+
+		if completed {
+			continue
+		}
+
+		if err := s.snapshotBalances(ctx, token, date); err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "token": token.Name, "date": date}).
+				Warn("Balance snapshot failed")
+			continue
+		}
+
+		run := &BalanceSnapshotRun{TokenName: token.Name, Date: date, CompletedAt: time.Now()}
+		if err := s.store.InsertBalanceSnapshotRun(run); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) snapshotBalances(ctx context.Context, token *templates.Token, date string) error {
+	code, err := templates.FungibleBalancesCode(s.cfg.ChainID, token)
+	if err != nil {
+		return fmt.Errorf("balances of %s can not be read: %w", token.Name, err)
+	}
+
+	filter := accounts.ListFilter{Type: accounts.AccountTypeCustodial, Sort: "address"}
+
+	for offset := 0; ; offset += holdingsBatchSize {
+		aa, err := s.accounts.List(holdingsBatchSize, offset, filter)
+		if err != nil {
+			return err
+		}
+
+		if len(aa) > 0 {
+			addresses := make([]cadence.Value, len(aa))
+			tenantIDs := make(map[string]string, len(aa))
+			for i := range aa {
+				addresses[i] = cadence.NewAddress(flow.HexToAddress(aa[i].Address))
+				tenantIDs[aa[i].Address] = aa[i].TenantID
+			}
+
+			takenAt := time.Now()
+
+			res, err := s.transactions.ExecuteScript(ctx, code, []transactions.Argument{cadence.NewArray(addresses)})
+			if err != nil {
+				return err
+			}
+
+			balances, ok := res.(cadence.Dictionary)
+			if !ok {
+				return fmt.Errorf("unexpected balances of %s: %s", token.Name, res)
+			}
+
+			snapshots := make([]*BalanceSnapshot, 0, len(balances.Pairs))
+			for _, pair := range balances.Pairs {
+				address, ok := pair.Key.(cadence.Address)
+				if !ok {
+					return fmt.Errorf("unexpected address: %s", pair.Key)
+				}
+
+				balance, ok := pair.Value.(cadence.UFix64)
+				if !ok {
+					return fmt.Errorf("unexpected balance of %s: %s", token.Name, pair.Value)
+				}
+
+				a := flow_helpers.FormatAddress(flow.Address(address))
+
+				snapshots = append(snapshots, &BalanceSnapshot{
+					AccountAddress: a,
+					TokenName:      token.Name,
+					Date:           date,
+					Balance:        balance.String(),
+					TenantID:       tenantIDs[a],
+					TakenAt:        takenAt,
+				})
+			}
+
+			if err := s.store.InsertBalanceSnapshots(snapshots); err != nil {
+				return err
+			}
+		}
+
+		if len(aa) < holdingsBatchSize {
+			break
+		}
+	}
+
+	return nil
+}
+
+// ListBalanceSnapshots returns the daily balance snapshots of a fungible
+// token of an account, newest first.
+func (s *ServiceImpl) ListBalanceSnapshots(address, tokenName string, limit, offset int, f BalanceSnapshotFilter) ([]*BalanceSnapshot, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Address = address
+	f.TokenName = tokenName
+
+	return s.ListAllBalanceSnapshots(limit, offset, f)
+}
+
+// ListAllBalanceSnapshots returns the daily balance snapshots of all
+// accounts, newest first. They can be filtered by account, token, tenant and
+// date range.
+func (s *ServiceImpl) ListAllBalanceSnapshots(limit, offset int, f BalanceSnapshotFilter) ([]*BalanceSnapshot, error) {
+	if f.Address != "" {
+		// Check if the input is a valid address
+		address, err := flow_helpers.ValidateAddress(f.Address, s.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		f.Address = address
+	}
+
+	if f.TokenName != "" {
+		token, err := s.templates.GetTokenByName(f.TokenName)
+		if err != nil {
+			return nil, err
+		}
+		f.TokenName = token.Name
+	}
+
+	for param, date := range map[string]string{"from": f.From, "to": f.To} {
+		if date == "" {
+			continue
+		}
+
+		if _, err := time.Parse(SnapshotDateLayout, date); err != nil {
+			return nil, &wallet_errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("invalid %s, expected a date as YYYY-MM-DD: %q", param, date),
+			}
+		}
+	}
+
+	return s.store.BalanceSnapshots(datastore.ParseListOptions(limit, offset), f)
+}
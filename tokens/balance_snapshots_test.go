@@ -0,0 +1,101 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+)
+
+func TestSnapshotBalances(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, _ := newTestService(t, nil, 3)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	// Paths of the balances script
+	chain.tokens[0].Address = "0x0ae53cb6e3f42a79"
+	chain.tokens[0].BalancePublicPath = "/public/flowTokenBalance"
+	chain.tokens[0].ReceiverPublicPath = "/public/flowTokenReceiver"
+	chain.tokens[0].VaultStoragePath = "/storage/flowTokenVault"
+
+	// The third account has no vault
+	chain.balances[a] = mustUFix64(t, "1.0")
+	chain.balances[b] = mustUFix64(t, "2.0")
+
+	date := time.Now().UTC().Format(SnapshotDateLayout)
+
+	snapshots := func() map[string]string {
+		t.Helper()
+
+		ss, err := svc.store.BalanceSnapshots(datastore.ListOptions{Limit: 100}, BalanceSnapshotFilter{TokenName: "FlowToken"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		balances := make(map[string]string, len(ss))
+		for _, s := range ss {
+			if s.Date != date {
+				t.Fatalf("expected a snapshot of %s, got %+v", date, s)
+			}
+			balances[s.AccountAddress] = s.Balance
+		}
+		return balances
+	}
+
+	completed := func() bool {
+		t.Helper()
+
+		ok, err := svc.store.BalanceSnapshotRunCompleted("FlowToken", date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	// A failed run is not completed
+	chain.balancesErr = errors.New("access node unavailable")
+
+	if err := svc.SnapshotBalances(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if completed() || len(snapshots()) != 0 {
+		t.Fatal("expected no snapshots of a failed run")
+	}
+
+	// A snapshot left over by a run which did not complete is kept
+	leftover := []*BalanceSnapshot{{AccountAddress: a, TokenName: "FlowToken", Date: date, Balance: "0.50000000", TakenAt: time.Now()}}
+	if err := svc.store.InsertBalanceSnapshots(leftover); err != nil {
+		t.Fatal(err)
+	}
+
+	chain.balancesErr = nil
+
+	if err := svc.SnapshotBalances(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{a: "0.50000000", b: "2.00000000"}
+
+	balances := snapshots()
+	if len(balances) != len(expected) || balances[a] != expected[a] || balances[b] != expected[b] {
+		t.Fatalf("expected snapshots %v, got %v", expected, balances)
+	}
+
+	if !completed() {
+		t.Fatal("expected the run to be completed")
+	}
+
+	// Completed runs are not repeated on the same day
+	chain.balances[b] = mustUFix64(t, "3.0")
+
+	if err := svc.SnapshotBalances(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if balances := snapshots(); balances[b] != expected[b] {
+		t.Fatalf("expected the snapshot of %s to be kept, got %v", b, balances)
+	}
+}
@@ -0,0 +1,267 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/google/uuid"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateBatchWithdrawal sends a fungible token to several recipients, or
+// several NFTs of a collection to a single recipient, in a single
+// transaction. Each recipient or NFT gets a withdrawal of its own, the
+// withdrawals share the batch ID, the transaction and its state.
+func (s *ServiceImpl) CreateBatchWithdrawal(ctx context.Context, sync bool, sender, tokenName string, requests []WithdrawalRequest) ([]*Withdrawal, error) {
+	log.WithFields(log.Fields{"sync": sync, "count": len(requests)}).Trace("Create batch withdrawal")
+
+	if err := s.checkIdempotencyKey(ctx); err != nil {
+		return nil, err
+	}
+
+	if len(requests) == 0 {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("empty batch"),
+		}
+	}
+
+	if uint(len(requests)) > s.cfg.MaxWithdrawalBatchSize {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("batch of %d withdrawals exceeds the maximum of %d", len(requests), s.cfg.MaxWithdrawalBatchSize),
+		}
+	}
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.batchTransferCode(token); err != nil {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("batch withdrawals of %s are not supported: %w", token.Name, err),
+		}
+	}
+
+	batchID := uuid.New()
+
+	nftIDs := make(map[uint64]bool, len(requests))
+
+	ww := make([]*Withdrawal, len(requests))
+	for i, r := range requests {
+		r.TokenName = token.Name
+
+		w, err := s.newWithdrawal(ctx, sender, r)
+		if err != nil {
+			return nil, &wallet_errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("withdrawal %d: %w", i, err),
+			}
+		}
+
+		if err := s.checkDestination(w); err != nil {
+			return nil, err
+		}
+
+		if token.Type == templates.NFT {
+			// A single transaction moves the NFTs into one collection
+			if i > 0 && w.RecipientAddress != ww[0].RecipientAddress {
+				return nil, &wallet_errors.RequestError{
+					StatusCode: http.StatusBadRequest,
+					Err:        fmt.Errorf("withdrawal %d: all NFTs of a batch must go to the same recipient", i),
+				}
+			}
+			if nftIDs[w.NftID] {
+				return nil, &wallet_errors.RequestError{
+					StatusCode: http.StatusBadRequest,
+					Err:        fmt.Errorf("withdrawal %d: NFT %d is listed more than once", i, w.NftID),
+				}
+			}
+			nftIDs[w.NftID] = true
+		}
+
+		w.BatchID = &batchID
+		ww[i] = w
+	}
+
+	// A batch must not get around the threshold by splitting the amount
+	if approval, err := s.needsApproval(ww...); err != nil {
+		return nil, err
+	} else if approval {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("total amount of the batch needs approval, withdrawals needing approval are not supported in batches"),
+		}
+	}
+
+	if err := s.checkWithdrawalRules(ctx, token, ww); err != nil {
+		return nil, err
+	}
+
+	// Rejected up front so async withdrawals of frozen accounts fail immediately
+	if err := transactions.CheckNotFrozen(s.accounts, ww[0].SenderAddress); err != nil {
+		return nil, err
+	}
+
+	if err := s.insertWithdrawals(ctx, ww); err != nil {
+		return nil, err
+	}
+
+	if !sync {
+		// Async
+		attrBytes, err := json.Marshal(withdrawalBatchJobAttributes{BatchID: batchID})
+		if err != nil {
+			return nil, s.failBatchWithdrawal(ww, err)
+		}
+
+		// Withdrawals are customer-facing, ahead of background traffic
+		job, err := s.wp.CreateJob(
+			WithdrawalBatchJobType,
+			"",
+			jobs.WithAttributes(attrBytes),
+			jobs.WithTenantID(tenants.FromContext(ctx)),
+			jobs.WithPriority(jobs.PriorityHigh),
+		)
+		if err != nil {
+			return nil, s.failBatchWithdrawal(ww, err)
+		}
+
+		for _, w := range ww {
+			w.JobID = &job.ID
+			if err := s.store.UpdateWithdrawal(w); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := s.wp.Schedule(job); err != nil {
+			return nil, s.failBatchWithdrawal(ww, err)
+		}
+
+		return ww, nil
+
+	} else {
+		// Sync
+		if err := s.sendBatchWithdrawal(ctx, ww); err != nil {
+			if ww[0].State == WithdrawalRequested {
+				// Not retried
+				return nil, s.failBatchWithdrawal(ww, err)
+			}
+			return nil, err
+		}
+
+		return ww, nil
+	}
+}
+
+// sendBatchWithdrawal sends the transfer of requested withdrawals of a batch
+// and records the resulting state.
+func (s *ServiceImpl) sendBatchWithdrawal(ctx context.Context, ww []*Withdrawal) error {
+	transaction, err := s.createBatchWithdrawal(ctx, ww)
+	return s.recordWithdrawalResult(transaction, err, ww...)
+}
+
+// failBatchWithdrawal marks the withdrawals of a batch that will not be sent
+// failed and returns err.
+func (s *ServiceImpl) failBatchWithdrawal(ww []*Withdrawal, err error) error {
+	for _, w := range ww {
+		err = s.failWithdrawal(w, err)
+	}
+	return err
+}
+
+// batchTransferCode returns the batch transfer code of a token.
+func (s *ServiceImpl) batchTransferCode(token *templates.Token) (string, error) {
+	if token.Type == templates.NFT {
+		return templates.NonFungibleBatchTransferCode(s.cfg.ChainID, token)
+	}
+	return templates.FungibleBatchTransferCode(s.cfg.ChainID, token)
+}
+
+// createBatchWithdrawal synchronously sends a single transaction transferring
+// the amounts or NFTs of the withdrawals of a batch and stores a transfer for
+// each.
+func (s *ServiceImpl) createBatchWithdrawal(ctx context.Context, ww []*Withdrawal) (*transactions.Transaction, error) {
+	token, err := s.templates.GetTokenByName(ww[0].TokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := s.batchTransferCode(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		arguments []transactions.Argument
+		txType    transactions.Type
+	)
+
+	switch token.Type {
+	case templates.NFT:
+		txType = transactions.NftTransfer
+
+		nftIDs := make([]cadence.Value, len(ww))
+		for i, w := range ww {
+			nftIDs[i] = cadence.NewUInt64(w.NftID)
+		}
+
+		arguments = []transactions.Argument{cadence.NewAddress(flow.HexToAddress(ww[0].RecipientAddress)), cadence.NewArray(nftIDs)}
+	default:
+		txType = transactions.FtTransfer
+
+		amounts := make([]cadence.Value, len(ww))
+		recipients := make([]cadence.Value, len(ww))
+		for i, w := range ww {
+			amount, err := decimal.ParseAmount(w.FtAmount)
+			if err != nil {
+				return nil, err
+			}
+			amounts[i] = amount
+			recipients[i] = cadence.NewAddress(flow.HexToAddress(w.RecipientAddress))
+		}
+
+		for _, w := range ww {
+			if err := s.setUpRecipientVault(ctx, token, w.RecipientAddress); err != nil {
+				return nil, err
+			}
+		}
+
+		arguments = []transactions.Argument{cadence.NewArray(amounts), cadence.NewArray(recipients)}
+	}
+
+	// Create the transaction, must be sync here
+	_, transaction, err := s.transactions.Create(ctx, true, ww[0].SenderAddress, code, arguments, txType, transactions.WithTokenName(token.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range ww {
+		transfer := &TokenTransfer{
+			TransactionId:    transaction.TransactionId,
+			RecipientAddress: w.RecipientAddress,
+			SenderAddress:    w.SenderAddress,
+			FtAmount:         w.FtAmount,
+			NftID:            w.NftID,
+			TokenName:        token.Name,
+			BlockHeight:      transaction.BlockHeight,
+		}
+
+		if err := s.store.InsertTokenTransfer(transfer); err != nil {
+			return nil, err
+		}
+	}
+
+	return transaction, nil
+}
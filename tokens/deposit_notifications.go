@@ -0,0 +1,127 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// DepositNotification is the outbox entry of a deposit webhook event. It is
+// stored in the same database transaction as the change of the deposit it
+// notifies of, once per deposit and event, and delivered until all webhook
+// endpoints have acknowledged it. Every delivery carries the ID of the
+// notification as the ID of the payload, for receivers to deduplicate.
+type DepositNotification struct {
+	ID          string                       `json:"id" gorm:"primaryKey;size:36"`
+	TransferID  uint64                       `json:"-" gorm:"uniqueIndex:idx_deposit_notifications_transfer_event"`
+	Event       webhooks.Event               `json:"event" gorm:"uniqueIndex:idx_deposit_notifications_transfer_event;size:64"`
+	Payload     []byte                       `json:"-"`
+	Attempts    int                          `json:"attempts"`
+	LastError   string                       `json:"lastError,omitempty"`
+	CreatedAt   time.Time                    `json:"createdAt"`
+	DeliveredAt *time.Time                   `json:"deliveredAt" gorm:"index"`
+	Receipts    []DepositNotificationReceipt `json:"receipts" gorm:"foreignKey:NotificationID"`
+}
+
+func (DepositNotification) TableName() string {
+	return "deposit_notifications"
+}
+
+// DepositNotificationReceipt records the acknowledgement of a deposit
+// notification by an endpoint, the notification is not delivered to it again.
+type DepositNotificationReceipt struct {
+	NotificationID string    `json:"-" gorm:"primaryKey;size:36"`
+	Endpoint       string    `json:"endpoint" gorm:"primaryKey;size:255"`
+	DeliveredAt    time.Time `json:"deliveredAt"`
+}
+
+func (DepositNotificationReceipt) TableName() string {
+	return "deposit_notification_receipts"
+}
+
+// newDepositNotification returns the notification of an event of a deposit
+// to store with it, nil if no webhook endpoints are configured.
+func (s *ServiceImpl) newDepositNotification(event webhooks.Event, t *TokenTransfer) (*DepositNotification, error) {
+	if s.webhooks == nil || len(s.webhooks.Endpoints()) == 0 {
+		return nil, nil
+	}
+
+	id := uuid.New().String()
+
+	payload, err := webhooks.Encode(id, event, t.Deposit())
+	if err != nil {
+		return nil, err
+	}
+
+	return &DepositNotification{ID: id, Event: event, Payload: payload}, nil
+}
+
+// deliverDepositNotifications delivers the stored deposit notifications to
+// the webhook endpoints which have not acknowledged them yet, oldest first.
+// Notifications are retried on every call until delivered.
+func (s *ServiceImpl) deliverDepositNotifications(ctx context.Context) error {
+	if s.webhooks == nil || len(s.webhooks.Endpoints()) == 0 {
+		return nil
+	}
+
+	nn, err := s.store.UndeliveredDepositNotifications(datastore.DefaultLimit)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range nn {
+		acknowledged := make(map[string]bool, len(n.Receipts))
+		for _, r := range n.Receipts {
+			acknowledged[r.Endpoint] = true
+		}
+
+		var deliveryErr error
+		for _, endpoint := range s.webhooks.Endpoints() {
+			if acknowledged[endpoint] {
+				continue
+			}
+
+			if err := s.webhooks.Deliver(ctx, endpoint, n.Payload); err != nil {
+				log.
+					WithFields(log.Fields{"error": err, "notification": n.ID, "endpoint": endpoint}).
+					Warn("Unable to deliver deposit notification")
+				deliveryErr = err
+				continue
+			}
+
+			if err := s.store.InsertDepositNotificationReceipt(&DepositNotificationReceipt{
+				NotificationID: n.ID,
+				Endpoint:       endpoint,
+				DeliveredAt:    time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+
+		n.Attempts++
+		n.LastError = ""
+		if deliveryErr != nil {
+			n.LastError = deliveryErr.Error()
+		} else {
+			now := time.Now()
+			n.DeliveredAt = &now
+		}
+
+		if err := s.store.UpdateDepositNotification(n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListDepositNotifications lists the deposit notifications with their
+// delivery receipts, newest first, only the ones not delivered to all
+// endpoints yet if undelivered is set.
+func (s *ServiceImpl) ListDepositNotifications(limit, offset int, undelivered bool) ([]*DepositNotification, error) {
+	return s.store.DepositNotifications(datastore.ParseListOptions(limit, offset), undelivered)
+}
@@ -0,0 +1,91 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+)
+
+// Inclusion effort of a transaction with a single authorizer and key.
+const transferInclusionEffort = cadence.UFix64(100_000_000) // 1.0
+
+// Number of sealed withdrawals the execution effort of a transfer is
+// averaged from.
+const feeEstimateSamples = 20
+
+// FeeEstimate is the expected fee of a transfer of a token, in FLOW.
+type FeeEstimate struct {
+	TokenName       string `json:"token"`
+	InclusionEffort string `json:"inclusionEffort"`
+	ExecutionEffort string `json:"executionEffort"`
+	// Samples is the number of sealed withdrawals the execution effort is
+	// averaged from, 0 if the configured default was used
+	Samples     int       `json:"samples"`
+	Fee         string    `json:"fee"`
+	EstimatedAt time.Time `json:"estimatedAt"`
+}
+
+// TransferFeeEstimate computes the expected fee of a withdrawal of a fungible
+// token with the current fee parameters of the chain. The execution effort
+// is averaged from the latest sealed withdrawals of the token.
+func (s *ServiceImpl) TransferFeeEstimate(ctx context.Context, tokenName string) (*FeeEstimate, error) {
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != templates.FT {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("%s is not a fungible token", token.Name),
+		}
+	}
+
+	efforts, err := s.store.WithdrawalExecutionEfforts(token.Name, feeEstimateSamples)
+	if err != nil {
+		return nil, err
+	}
+
+	var executionEffort cadence.UFix64
+	if len(efforts) > 0 {
+		var sum uint64
+		for _, e := range efforts {
+			sum += e
+		}
+		executionEffort = cadence.UFix64(sum / uint64(len(efforts)))
+	} else {
+		executionEffort, err = decimal.ParseUFix64(s.cfg.FeeEstimateExecutionEffort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee estimate execution effort: %w", err)
+		}
+	}
+
+	res, err := s.transactions.ExecuteScript(ctx, templates.ComputeFeesCode(s.cfg.ChainID), []transactions.Argument{
+		transferInclusionEffort,
+		executionEffort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fee, ok := res.(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("unexpected fee: %s", res)
+	}
+
+	return &FeeEstimate{
+		TokenName:       token.Name,
+		InclusionEffort: transferInclusionEffort.String(),
+		ExecutionEffort: executionEffort.String(),
+		Samples:         len(efforts),
+		Fee:             fee.String(),
+		EstimatedAt:     time.Now(),
+	}, nil
+}
@@ -0,0 +1,148 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// Number of accounts of which the balances are read in a single script.
+const holdingsBatchSize = 100
+
+// Holdings reports the balance of a fungible token held by all accounts of
+// the service on-chain against the balance recorded by the service.
+type Holdings struct {
+	TokenName string `json:"token"`
+	// Accounts is the number of accounts checked, Vaults the number of them
+	// with a vault of the token set up
+	Accounts int `json:"accounts"`
+	Vaults   int `json:"vaults"`
+	// OnChain is the sum of the on-chain balances of the accounts
+	OnChain string `json:"onChain"`
+	// Deposited and Withdrawn are the sums of the recorded transfers to and
+	// from the accounts, Recorded is the balance they add up to
+	Deposited string `json:"deposited"`
+	Withdrawn string `json:"withdrawn"`
+	Recorded  string `json:"recorded"`
+	// Difference is OnChain minus Recorded, the accounts hold less than
+	// recorded and are not solvent when it is negative
+	Difference string    `json:"difference"`
+	Solvent    bool      `json:"solvent"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// Holdings sums up the on-chain balances of a fungible token of all accounts
+// of the service, or of the tenant in context, and reconciles the sum with
+// the recorded deposits and withdrawals of the accounts. Balances from before
+// the accounts were tracked, e.g. initial funding, and fees paid show up as a
+// difference.
+func (s *ServiceImpl) Holdings(ctx context.Context, tokenName string) (*Holdings, error) {
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != templates.FT {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("%s is not a fungible token", token.Name),
+		}
+	}
+
+	code, err := templates.FungibleBalancesCode(s.cfg.ChainID, token)
+	if err != nil {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("balances of %s can not be read: %w", token.Name, err),
+		}
+	}
+
+	tenantID := tenants.FromContext(ctx)
+
+	h := &Holdings{TokenName: token.Name, CheckedAt: time.Now()}
+	onChain := new(big.Int)
+
+	filter := accounts.ListFilter{TenantID: tenantID, Sort: "address"}
+
+	for offset := 0; ; offset += holdingsBatchSize {
+		aa, err := s.accounts.List(holdingsBatchSize, offset, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(aa) > 0 {
+			addresses := make([]cadence.Value, len(aa))
+			for i := range aa {
+				addresses[i] = cadence.NewAddress(flow.HexToAddress(aa[i].Address))
+			}
+
+			res, err := s.transactions.ExecuteScript(ctx, code, []transactions.Argument{cadence.NewArray(addresses)})
+			if err != nil {
+				return nil, err
+			}
+
+			balances, ok := res.(cadence.Dictionary)
+			if !ok {
+				return nil, fmt.Errorf("unexpected balances of %s: %s", token.Name, res)
+			}
+
+			for _, pair := range balances.Pairs {
+				balance, ok := pair.Value.(cadence.UFix64)
+				if !ok {
+					return nil, fmt.Errorf("unexpected balance of %s: %s", token.Name, pair.Value)
+				}
+				onChain.Add(onChain, new(big.Int).SetUint64(uint64(balance)))
+			}
+
+			h.Accounts += len(aa)
+			h.Vaults += len(balances.Pairs)
+		}
+
+		if len(aa) < holdingsBatchSize {
+			break
+		}
+	}
+
+	depositedAmounts, err := s.store.DepositedAmounts(token.Name, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	deposited, err := decimal.Sum(depositedAmounts)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawnAmounts, err := s.store.WithdrawnAmounts(token.Name, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawn, err := decimal.Sum(withdrawnAmounts)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := new(big.Int).Sub(deposited, withdrawn)
+	difference := new(big.Int).Sub(onChain, recorded)
+
+	h.OnChain = decimal.Format(onChain)
+	h.Deposited = decimal.Format(deposited)
+	h.Withdrawn = decimal.Format(withdrawn)
+	h.Recorded = decimal.Format(recorded)
+	h.Difference = decimal.Format(difference)
+	h.Solvent = difference.Sign() >= 0
+
+	return h, nil
+}
@@ -0,0 +1,163 @@
+package tokens
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	log "github.com/sirupsen/logrus"
+)
+
+// WithdrawalIdempotencyKey records a withdrawal request made with an
+// Idempotency-Key header. The withdrawals created for the request carry the
+// key.
+type WithdrawalIdempotencyKey struct {
+	Key         string `gorm:"primaryKey"`
+	RequestHash string
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+func (WithdrawalIdempotencyKey) TableName() string {
+	return "withdrawal_idempotency_keys"
+}
+
+type idempotencyKeyContextKey struct{}
+
+// withIdempotencyKey returns a copy of ctx with the idempotency key of the
+// withdrawal request, given to the withdrawals created in it.
+func withIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// checkIdempotencyKey fails withdrawal requests without an idempotency key if
+// keys are required.
+func (s *ServiceImpl) checkIdempotencyKey(ctx context.Context) error {
+	if s.cfg.RequireWithdrawalIdempotencyKeys && idempotencyKeyFromContext(ctx) == "" {
+		return &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("an idempotency key is required for withdrawals"),
+		}
+	}
+	return nil
+}
+
+// CreateWithdrawalIdempotent creates a withdrawal like CreateWithdrawal,
+// unless a request with the same idempotency key has already been made. In
+// that case the original withdrawal is returned, in its current state,
+// instead of sending the tokens again. Reusing a key for a different request
+// fails.
+func (s *ServiceImpl) CreateWithdrawalIdempotent(ctx context.Context, sync bool, key, sender string, request WithdrawalRequest) (*Withdrawal, error) {
+	ww, err := s.createIdempotent(ctx, sync, key, []interface{}{sender, request}, func(ctx context.Context) ([]*Withdrawal, error) {
+		w, err := s.CreateWithdrawal(ctx, sync, sender, request)
+		if err != nil {
+			return nil, err
+		}
+		return []*Withdrawal{w}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ww[0], nil
+}
+
+// CreateBatchWithdrawalIdempotent creates a batch of withdrawals like
+// CreateBatchWithdrawal, unless a request with the same idempotency key has
+// already been made. In that case the original withdrawals are returned.
+func (s *ServiceImpl) CreateBatchWithdrawalIdempotent(ctx context.Context, sync bool, key, sender, tokenName string, requests []WithdrawalRequest) ([]*Withdrawal, error) {
+	return s.createIdempotent(ctx, sync, key, []interface{}{sender, tokenName, requests}, func(ctx context.Context) ([]*Withdrawal, error) {
+		return s.CreateBatchWithdrawal(ctx, sync, sender, tokenName, requests)
+	})
+}
+
+func (s *ServiceImpl) createIdempotent(ctx context.Context, sync bool, key string, request interface{}, create func(context.Context) ([]*Withdrawal, error)) ([]*Withdrawal, error) {
+	log.WithFields(log.Fields{"sync": sync, "idempotencyKey": key}).Trace("Create withdrawal idempotently")
+
+	hash, err := withdrawalRequestHash(sync, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are only unique per tenant
+	if tenantID := tenants.FromContext(ctx); tenantID != "" {
+		key = fmt.Sprintf("%s:%s", tenantID, key)
+	}
+
+	if existing, err := s.store.WithdrawalIdempotencyKey(key); err == nil {
+		return s.idempotentWithdrawals(existing, hash)
+	} else if !strings.Contains(err.Error(), "record not found") {
+		return nil, err
+	}
+
+	k := &WithdrawalIdempotencyKey{Key: key, RequestHash: hash}
+	if err := s.store.InsertWithdrawalIdempotencyKey(k); err != nil {
+		// A concurrent request may have inserted the key first
+		if existing, getErr := s.store.WithdrawalIdempotencyKey(key); getErr == nil {
+			return s.idempotentWithdrawals(existing, hash)
+		}
+		return nil, err
+	}
+
+	ww, err := create(withIdempotencyKey(ctx, key))
+	if err != nil {
+		// Once a withdrawal is recorded its transfer may have been sent, the
+		// key is only released if nothing was recorded
+		if recorded, getErr := s.store.IdempotentWithdrawals(key); getErr != nil || len(recorded) > 0 {
+			return nil, err
+		}
+		if deleteErr := s.store.DeleteWithdrawalIdempotencyKey(key); deleteErr != nil {
+			log.WithFields(log.Fields{"error": deleteErr, "idempotencyKey": key}).Warn("Unable to delete idempotency key")
+		}
+		return nil, err
+	}
+
+	return ww, nil
+}
+
+func (s *ServiceImpl) idempotentWithdrawals(k WithdrawalIdempotencyKey, hash string) ([]*Withdrawal, error) {
+	if k.RequestHash != hash {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusUnprocessableEntity,
+			Err:        fmt.Errorf("idempotency key %s was already used for a different request", k.Key),
+		}
+	}
+
+	ww, err := s.store.IdempotentWithdrawals(k.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ww) == 0 {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("a request with idempotency key %s is still in progress", k.Key),
+		}
+	}
+
+	return ww, nil
+}
+
+func withdrawalRequestHash(sync bool, request interface{}) (string, error) {
+	b, err := json.Marshal(struct {
+		Sync    bool        `json:"sync"`
+		Request interface{} `json:"request"`
+	}{sync, request})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,74 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// TokenInfo is the registered configuration of a fungible token along with
+// live data of its contract on the chain of the service.
+type TokenInfo struct {
+	templates.Token
+	ChainID string `json:"chainId"`
+	// ContractDeployed tells whether the contract of the token is deployed to
+	// its address, the total supply is null if not
+	ContractDeployed bool      `json:"contractDeployed"`
+	TotalSupply      *string   `json:"totalSupply"`
+	CheckedAt        time.Time `json:"checkedAt"`
+}
+
+// TokenInfo returns the configuration of a fungible token with the total
+// supply read from its contract.
+func (s *ServiceImpl) TokenInfo(ctx context.Context, tokenName string) (*TokenInfo, error) {
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != templates.FT {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("%s is not a fungible token", token.Name),
+		}
+	}
+
+	info := &TokenInfo{Token: *token, ChainID: s.cfg.ChainID.String(), CheckedAt: time.Now()}
+
+	account, err := s.fc.GetAccount(ctx, flow.HexToAddress(token.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := account.Contracts[token.Name]; !ok {
+		return info, nil
+	}
+
+	info.ContractDeployed = true
+
+	code, err := templates.FungibleTotalSupplyCode(s.cfg.ChainID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.transactions.ExecuteScript(ctx, code, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	totalSupply, ok := res.(cadence.UFix64)
+	if !ok {
+		return nil, fmt.Errorf("unexpected total supply of %s: %s", token.Name, res)
+	}
+
+	supply := totalSupply.String()
+	info.TotalSupply = &supply
+
+	return info, nil
+}
@@ -3,17 +3,73 @@ package tokens
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/google/uuid"
 )
 
-const WithdrawalCreateJobType = "withdrawal_create"
+const (
+	WithdrawalCreateJobType = "withdrawal_create"
+	WithdrawalBatchJobType  = "withdrawal_batch"
+)
 
 type withdrawalCreateJobAttributes struct {
+	WithdrawalID uuid.UUID
+
+	// Jobs created before withdrawals were recorded
 	Sender  string
 	Request WithdrawalRequest
 }
 
+// isRetryableWithdrawalError tells whether a withdrawal job which failed with
+// err may succeed when retried. Withdrawals from frozen accounts and requests
+// refused by the service are not retried, nor are reverted transactions (see
+// the executors).
+func isRetryableWithdrawalError(err error) bool {
+	if errors.Is(err, transactions.ErrAccountFrozen) {
+		return false
+	}
+
+	var reqErr *wallet_errors.RequestError
+	return !errors.As(err, &reqErr) || reqErr.StatusCode >= http.StatusInternalServerError
+}
+
+// withdrawalRetryPolicy is the retry policy of withdrawal jobs. The number
+// of attempts is that of the worker pool, given explicitly for the executors
+// to tell the last attempt.
+func (s *ServiceImpl) withdrawalRetryPolicy() jobs.RetryPolicy {
+	return jobs.RetryPolicy{
+		MaxAttempts: s.cfg.MaxJobErrorCount + 1,
+		Retryable:   isRetryableWithdrawalError,
+	}
+}
+
+// failJobWithdrawals fails the withdrawals of a job which were not sent once
+// the job fails for good, with an error which is not retryable or on its
+// last attempt. Requested withdrawals would otherwise hold back their amount
+// as withdrawing and keep the sender from being swept.
+func (s *ServiceImpl) failJobWithdrawals(j *jobs.Job, err error, ww ...*Withdrawal) {
+	// Chain connection errors return the job to the pool without failing it
+	if err == nil || wallet_errors.IsChainConnectionError(err) {
+		return
+	}
+
+	if isRetryableWithdrawalError(err) && j.ExecCount < s.withdrawalRetryPolicy().MaxAttempts {
+		return
+	}
+
+	for _, w := range ww {
+		if w.State == WithdrawalRequested {
+			_ = s.failWithdrawal(w, err)
+		}
+	}
+}
+
 func (s *ServiceImpl) executeCreateWithdrawalJob(ctx context.Context, j *jobs.Job) error {
 	if j.Type != WithdrawalCreateJobType {
 		return jobs.ErrInvalidJobType
@@ -26,13 +82,103 @@ func (s *ServiceImpl) executeCreateWithdrawalJob(ctx context.Context, j *jobs.Jo
 		return err
 	}
 
-	transaction, err := s.createWithdrawal(ctx, attrs.Sender, attrs.Request)
+	w, err := s.jobWithdrawal(ctx, j, attrs)
+	if err != nil {
+		return err
+	}
+
+	// Sent on an earlier execution already
+	if w.State != WithdrawalRequested {
+		j.TransactionID = w.TransactionId
+		j.Result = w.TransactionId
+		return nil
+	}
+
+	err = s.sendWithdrawal(ctx, w)
+
+	j.TransactionID = w.TransactionId
+	j.Result = w.TransactionId
+
+	if w.State == WithdrawalFailed {
+		// Reverted, resending would not help
+		return jobs.PermanentFailure(err)
+	}
+
+	s.failJobWithdrawals(j, err, w)
+
+	return err
+}
+
+// jobWithdrawal returns the withdrawal of a job, recording it for jobs
+// created before withdrawals were recorded.
+func (s *ServiceImpl) jobWithdrawal(ctx context.Context, j *jobs.Job, attrs withdrawalCreateJobAttributes) (*Withdrawal, error) {
+	if attrs.WithdrawalID != uuid.Nil {
+		return s.store.Withdrawal(attrs.WithdrawalID)
+	}
+
+	w, err := s.newWithdrawal(ctx, attrs.Sender, attrs.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	w.JobID = &j.ID
+
+	if err := s.store.InsertWithdrawal(w); err != nil {
+		return nil, err
+	}
+
+	// Retries use the same withdrawal
+	attrs.WithdrawalID = w.ID
+	if j.Attributes, err = json.Marshal(attrs); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+type withdrawalBatchJobAttributes struct {
+	BatchID uuid.UUID
+}
+
+func (s *ServiceImpl) executeBatchWithdrawalJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != WithdrawalBatchJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = true
+
+	attrs := withdrawalBatchJobAttributes{}
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	ww, err := s.store.BatchWithdrawals(attrs.BatchID)
 	if err != nil {
 		return err
 	}
 
-	j.TransactionID = transaction.TransactionId
-	j.Result = transaction.TransactionId
+	if len(ww) == 0 {
+		return jobs.PermanentFailure(fmt.Errorf("batch %s has no withdrawals", attrs.BatchID))
+	}
+
+	// Sent on an earlier execution already
+	if ww[0].State != WithdrawalRequested {
+		j.TransactionID = ww[0].TransactionId
+		j.Result = ww[0].TransactionId
+		return nil
+	}
+
+	err = s.sendBatchWithdrawal(ctx, ww)
+
+	j.TransactionID = ww[0].TransactionId
+	j.Result = ww[0].TransactionId
+
+	if ww[0].State == WithdrawalFailed {
+		// Reverted, resending would not help
+		return jobs.PermanentFailure(err)
+	}
+
+	s.failJobWithdrawals(j, err, ww...)
 
-	return nil
+	return err
 }
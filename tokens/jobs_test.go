@@ -0,0 +1,84 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+)
+
+func TestIsRetryableWithdrawalError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "connection error", err: fmt.Errorf("connection refused"), retryable: true},
+		{name: "frozen sender", err: fmt.Errorf("sending: %w", transactions.ErrAccountFrozen)},
+		{name: "refused request", err: &wallet_errors.RequestError{StatusCode: http.StatusForbidden, Err: fmt.Errorf("not allowed")}},
+		{name: "server error", err: &wallet_errors.RequestError{StatusCode: http.StatusServiceUnavailable, Err: fmt.Errorf("unavailable")}, retryable: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if retryable := isRetryableWithdrawalError(tc.err); retryable != tc.retryable {
+				t.Fatalf("expected retryable %t, got %t", tc.retryable, retryable)
+			}
+		})
+	}
+}
+
+func TestWithdrawalJobFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, wp := newTestService(t, &configs.Config{MaxJobErrorCount: 2}, 2)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+	chain.balances[a] = mustUFix64(t, "100.0")
+
+	connErr := &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}
+
+	testCases := []struct {
+		name      string
+		err       error
+		execCount int
+		state     WithdrawalState
+	}{
+		{name: "retryable error", err: fmt.Errorf("unexpected"), execCount: 1, state: WithdrawalRequested},
+		{name: "retryable error on the last attempt", err: fmt.Errorf("unexpected"), execCount: 3, state: WithdrawalFailed},
+		{name: "error which is not retryable", err: &wallet_errors.RequestError{StatusCode: http.StatusForbidden, Err: fmt.Errorf("not allowed")}, execCount: 1, state: WithdrawalFailed},
+		{name: "frozen sender", err: fmt.Errorf("sending: %w", transactions.ErrAccountFrozen), execCount: 1, state: WithdrawalFailed},
+		{name: "connection error on the last attempt", err: connErr, execCount: 3, state: WithdrawalRequested},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain.createErr = nil
+
+			w, err := svc.CreateWithdrawal(ctx, false, a, WithdrawalRequest{TokenName: "FlowToken", Recipient: b, FtAmount: "1.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			job := wp.scheduled[len(wp.scheduled)-1]
+			job.ExecCount = tc.execCount
+			chain.createErr = tc.err
+
+			if err := svc.executeCreateWithdrawalJob(ctx, job); err == nil {
+				t.Fatal("expected the job to fail")
+			}
+
+			stored, err := svc.store.Withdrawal(w.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if stored.State != tc.state {
+				t.Fatalf("expected withdrawal %s, got %s", tc.state, stored.State)
+			}
+		})
+	}
+}
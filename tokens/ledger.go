@@ -0,0 +1,646 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/google/uuid"
+	"github.com/onflow/cadence"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// LedgerTransferState is the state of a ledger transfer. A ledger transfer is
+// pending until a settlement sends a withdrawal settling it, settling until
+// the withdrawal is sealed and then settled. Transfers whose withdrawal fails
+// are pending again and settled by a later settlement.
+type LedgerTransferState string
+
+const (
+	LedgerTransferPending  LedgerTransferState = "pending"
+	LedgerTransferSettling LedgerTransferState = "settling"
+	LedgerTransferSettled  LedgerTransferState = "settled"
+)
+
+// LedgerTransfer is a transfer of a fungible token between two managed
+// accounts, recorded off-chain in the internal ledger. Ledger transfers are
+// settled on-chain later, netted per pair of accounts.
+type LedgerTransfer struct {
+	ID               uuid.UUID           `json:"id" gorm:"column:id;primary_key;type:uuid;"`
+	TokenName        string              `json:"token" gorm:"column:token_name;index"`
+	SenderAddress    string              `json:"sender" gorm:"column:sender_address;index"`
+	RecipientAddress string              `json:"recipient" gorm:"column:recipient_address;index"`
+	FtAmount         string              `json:"amount" gorm:"column:ft_amount"`
+	State            LedgerTransferState `json:"state" gorm:"column:state;index"`
+	// Withdrawal settling the transfer, nil for transfers netted out entirely
+	WithdrawalID *uuid.UUID `json:"withdrawalId,omitempty" gorm:"column:withdrawal_id;type:uuid"`
+	TenantID     string     `json:"-" gorm:"column:tenant_id;index"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"column:created_at;index"`
+	UpdatedAt    time.Time  `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (LedgerTransfer) TableName() string {
+	return "ledger_transfers"
+}
+
+func (t *LedgerTransfer) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// LedgerLock serializes the ledger of a token of an account across
+// instances, see Store.LockLedger.
+type LedgerLock struct {
+	TokenName      string `gorm:"column:token_name;primaryKey"`
+	AccountAddress string `gorm:"column:account_address;primaryKey"`
+}
+
+func (LedgerLock) TableName() string {
+	return "ledger_locks"
+}
+
+// LedgerBalance is the balance of an account in the internal ledger, its
+// on-chain balance with the unsettled ledger transfers from and to it and the
+// on-chain withdrawals from it which are not final yet.
+type LedgerBalance struct {
+	TokenName   string `json:"token"`
+	OnChain     string `json:"onChain"`
+	Incoming    string `json:"incoming"`
+	Outgoing    string `json:"outgoing"`
+	Withdrawing string `json:"withdrawing"`
+	Available   string `json:"available"`
+}
+
+// CreateLedgerTransfer records a transfer of a fungible token from a
+// custodial account to another managed account in the internal ledger. The
+// transfer is instant and free, it is settled on-chain by a later
+// settlement. The amount can not exceed the available ledger balance of the
+// sender.
+func (s *ServiceImpl) CreateLedgerTransfer(ctx context.Context, sender, tokenName string, request WithdrawalRequest) (*LedgerTransfer, error) {
+	// Check if the sender is a valid address
+	sender, err := flow_helpers.ValidateAddress(sender, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if the recipient is a valid address
+	recipient, err := flow_helpers.ValidateAddress(request.Recipient, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sender == recipient {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("sender and recipient are the same account"),
+		}
+	}
+
+	token, err := s.ledgerToken(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := decimal.ParseAmount(request.FtAmount)
+	if err != nil {
+		return nil, &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	senderAccount, err := s.accounts.Details(sender)
+	if err != nil {
+		return nil, err
+	}
+
+	if senderAccount.Type != accounts.AccountTypeCustodial {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("ledger transfers can only be sent from custodial accounts"),
+		}
+	}
+
+	if err := transactions.CheckNotFrozen(s.accounts, sender); err != nil {
+		return nil, err
+	}
+
+	// Recipients have to be managed accounts of the same tenant
+	recipientAccount, err := s.accounts.Details(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	if recipientAccount.TenantID != senderAccount.TenantID {
+		return nil, fmt.Errorf("record not found")
+	}
+
+	t := &LedgerTransfer{
+		TokenName:        token.Name,
+		SenderAddress:    sender,
+		RecipientAddress: recipient,
+		FtAmount:         amount.String(),
+		State:            LedgerTransferPending,
+		TenantID:         senderAccount.TenantID,
+	}
+
+	err = s.store.LockLedger(token.Name, []string{sender}, func(store Store) error {
+		balance, err := s.ledgerBalance(ctx, store, token, sender)
+		if err != nil {
+			return err
+		}
+
+		if amount > balance.available {
+			return &wallet_errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("insufficient balance, %s of %s available", balance.available, token.Name),
+			}
+		}
+
+		return store.InsertLedgerTransfer(t)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// ListLedgerTransfers returns the ledger transfers from and to an account,
+// newest first.
+func (s *ServiceImpl) ListLedgerTransfers(ctx context.Context, address, tokenName string, limit, offset int) ([]*LedgerTransfer, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.ledgerToken(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.LedgerTransfers(address, token.Name, datastore.ParseListOptions(limit, offset))
+}
+
+// LedgerBalance returns the balance of an account in the internal ledger.
+func (s *ServiceImpl) LedgerBalance(ctx context.Context, address, tokenName string) (*LedgerBalance, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.ledgerToken(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := s.ledgerBalance(ctx, s.store, token, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LedgerBalance{
+		TokenName:   token.Name,
+		OnChain:     b.onChain.String(),
+		Incoming:    b.incoming.String(),
+		Outgoing:    b.outgoing.String(),
+		Withdrawing: b.withdrawing.String(),
+		Available:   b.available.String(),
+	}, nil
+}
+
+// SettleAllLedgers settles the ledger transfers of all tokens, see SettleLedger.
+func (s *ServiceImpl) SettleAllLedgers(ctx context.Context) error {
+	tokenNames, err := s.store.UnsettledLedgerTokens()
+	if err != nil {
+		return err
+	}
+
+	for _, tokenName := range tokenNames {
+		if err := s.SettleLedger(ctx, tokenName); err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "token": tokenName}).
+				Warn("Ledger settlement failed")
+		}
+	}
+
+	return nil
+}
+
+// SettleLedger settles the pending ledger transfers of a token on-chain. The
+// transfers between each pair of accounts are netted into a single
+// withdrawal, sent in a low priority job, from the account owing the other
+// one. Transfers which net out entirely are settled without a withdrawal.
+func (s *ServiceImpl) SettleLedger(ctx context.Context, tokenName string) error {
+	token, err := s.ledgerToken(tokenName)
+	if err != nil {
+		return err
+	}
+
+	tt, err := s.store.UnsettledLedgerTransfers(token.Name, "")
+	if err != nil {
+		return err
+	}
+
+	s.syncLedgerTransfers(ctx, s.store, tt)
+
+	// Pairs of accounts with pending transfers, in the order of their first transfer
+	var pairs []ledgerPair
+	seen := make(map[ledgerPair]bool)
+
+	for _, t := range tt {
+		if t.State != LedgerTransferPending {
+			continue
+		}
+
+		p := newLedgerPair(t.SenderAddress, t.RecipientAddress)
+		if !seen[p] {
+			seen[p] = true
+			pairs = append(pairs, p)
+		}
+	}
+
+	settled, failed := 0, 0
+
+	for _, p := range pairs {
+		if err := s.settleLedgerPair(ctx, token, p); err != nil {
+			failed++
+			log.
+				WithFields(log.Fields{"error": err, "token": token.Name, "accounts": []string{p.a, p.b}}).
+				Warn("Settling ledger transfers failed")
+			continue
+		}
+		settled++
+	}
+
+	log.WithFields(log.Fields{"token": token.Name, "settled": settled, "failed": failed}).Info("Settled ledger transfers")
+
+	return nil
+}
+
+// ledgerPair is a pair of accounts with ledger transfers between them, a < b.
+type ledgerPair struct{ a, b string }
+
+func newLedgerPair(x, y string) ledgerPair {
+	if x > y {
+		return ledgerPair{y, x}
+	}
+	return ledgerPair{x, y}
+}
+
+// netLedgerTransfers nets the amounts of ledger transfers between the
+// accounts of p. It returns the account owing the other one and the amount
+// owed, no sender if the transfers net out entirely.
+func netLedgerTransfers(p ledgerPair, tt []*LedgerTransfer) (sender, recipient string, net cadence.UFix64, err error) {
+	var ab, ba cadence.UFix64
+
+	for _, t := range tt {
+		amount, err := decimal.ParseUFix64(t.FtAmount)
+		if err != nil {
+			return "", "", 0, err
+		}
+
+		if t.SenderAddress == p.a {
+			ab += amount
+		} else {
+			ba += amount
+		}
+	}
+
+	switch {
+	case ab > ba:
+		return p.a, p.b, ab - ba, nil
+	case ba > ab:
+		return p.b, p.a, ba - ab, nil
+	default:
+		return "", "", 0, nil
+	}
+}
+
+// settleLedgerPair settles the pending ledger transfers between the accounts
+// of p with a withdrawal of the net amount. The transfers are read again and
+// linked to the withdrawal holding the ledger locks of both accounts, so a
+// transfer is settled once even with settlements running on several
+// instances.
+func (s *ServiceImpl) settleLedgerPair(ctx context.Context, token *templates.Token, p ledgerPair) error {
+	var w *Withdrawal
+
+	err := s.store.LockLedger(token.Name, []string{p.a, p.b}, func(store Store) error {
+		unsettled, err := store.UnsettledLedgerTransfers(token.Name, p.a)
+		if err != nil {
+			return err
+		}
+
+		var tt []*LedgerTransfer
+		for _, t := range unsettled {
+			if t.State == LedgerTransferPending && newLedgerPair(t.SenderAddress, t.RecipientAddress) == p {
+				tt = append(tt, t)
+			}
+		}
+
+		if len(tt) == 0 {
+			// Settled by another instance
+			return nil
+		}
+
+		sender, recipient, net, err := netLedgerTransfers(p, tt)
+		if err != nil {
+			return err
+		}
+
+		if sender == "" {
+			// Netted out
+			for _, t := range tt {
+				t.State = LedgerTransferSettled
+				if err := store.UpdateLedgerTransfer(t); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if err := transactions.CheckNotFrozen(s.accounts, sender); err != nil {
+			return err
+		}
+
+		// The withdrawal belongs to the tenant of the accounts
+		w, err = s.newWithdrawal(tenants.NewContext(ctx, tt[0].TenantID), sender, WithdrawalRequest{
+			TokenName: token.Name,
+			Recipient: recipient,
+			FtAmount:  net.String(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := store.InsertWithdrawal(w); err != nil {
+			return err
+		}
+
+		for _, t := range tt {
+			t.State = LedgerTransferSettling
+			t.WithdrawalID = &w.ID
+			if err := store.UpdateLedgerTransfer(t); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil || w == nil {
+		return err
+	}
+
+	// A withdrawal which can not be scheduled fails, its transfers are
+	// pending again once synced
+	return s.scheduleWithdrawal(tenants.NewContext(ctx, w.TenantID), w, jobs.PriorityLow)
+}
+
+// syncLedgerTransfers updates settling ledger transfers with the state of
+// their withdrawal, settled once it is sealed and pending again if it failed.
+func (s *ServiceImpl) syncLedgerTransfers(ctx context.Context, store Store, tt []*LedgerTransfer) {
+	withdrawals := make(map[uuid.UUID]*Withdrawal)
+
+	for _, t := range tt {
+		if t.State != LedgerTransferSettling {
+			continue
+		}
+
+		w, ok := withdrawals[*t.WithdrawalID]
+		if !ok {
+			var err error
+			w, err = store.Withdrawal(*t.WithdrawalID)
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				w = nil
+			case err != nil:
+				log.
+					WithFields(log.Fields{"error": err, "ledgerTransferId": t.ID}).
+					Warn("Could not get the withdrawal of a ledger transfer")
+				continue
+			default:
+				s.syncWithdrawal(ctx, w)
+			}
+			withdrawals[*t.WithdrawalID] = w
+		}
+
+		var state LedgerTransferState
+		switch {
+		case w == nil, w.State == WithdrawalFailed, w.State == WithdrawalRejected:
+			// Not recorded or not sent, settled again
+			state = LedgerTransferPending
+		case w.State == WithdrawalSealed:
+			state = LedgerTransferSettled
+		default:
+			continue
+		}
+
+		if err := store.ResolveLedgerTransfers(*t.WithdrawalID, state); err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "ledgerTransferId": t.ID}).
+				Error("Error while updating ledger transfer")
+			continue
+		}
+
+		t.State = state
+		if state == LedgerTransferPending {
+			t.WithdrawalID = nil
+		}
+	}
+}
+
+type ledgerBalance struct {
+	onChain, incoming, outgoing, withdrawing, available cadence.UFix64
+	// Amount the account owes to others once its transfers are netted,
+	// the part of the on-chain balance settlements are going to withdraw
+	owed cadence.UFix64
+}
+
+// withdrawable is the part of the on-chain balance which can be withdrawn
+// without leaving ledger transfers from the account uncovered.
+func (b *ledgerBalance) withdrawable() cadence.UFix64 {
+	if b.onChain > b.owed+b.withdrawing {
+		return b.onChain - b.owed - b.withdrawing
+	}
+	return 0
+}
+
+// ledgerBalance returns the balance of an account in the ledger, read with
+// store. Callers changing the ledger hold the ledger lock of the account.
+func (s *ServiceImpl) ledgerBalance(ctx context.Context, store Store, token *templates.Token, address string) (*ledgerBalance, error) {
+	b := &ledgerBalance{}
+
+	// Read before the transfers and withdrawals are, a withdrawal sealed in
+	// between is counted twice rather than not at all
+	details, err := s.Details(ctx, token.Name, address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Not a UFix64 if the vault is not set up
+	if onChain, ok := details.Balance.CadenceValue.(cadence.UFix64); ok {
+		b.onChain = onChain
+	}
+
+	tt, err := store.UnsettledLedgerTransfers(token.Name, address)
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncLedgerTransfers(ctx, store, tt)
+
+	// Withdrawals settling ledger transfers are counted as the transfers
+	settlements := make(map[uuid.UUID]bool)
+
+	// Amounts owed to each counterparty, netted as they are settled: the
+	// transfers of a settlement on their own and the pending ones together
+	type counterparty struct {
+		address    string
+		withdrawal uuid.UUID
+	}
+	owed := make(map[counterparty]int64)
+
+	for _, t := range tt {
+		// Settled transfers are part of the on-chain balance
+		if t.State == LedgerTransferSettled {
+			continue
+		}
+
+		var withdrawal uuid.UUID
+		if t.WithdrawalID != nil {
+			withdrawal = *t.WithdrawalID
+			settlements[withdrawal] = true
+		}
+
+		amount, err := decimal.ParseUFix64(t.FtAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.SenderAddress == address {
+			b.outgoing += amount
+			owed[counterparty{t.RecipientAddress, withdrawal}] += int64(amount)
+		} else {
+			b.incoming += amount
+			owed[counterparty{t.SenderAddress, withdrawal}] -= int64(amount)
+		}
+	}
+
+	for _, amount := range owed {
+		if amount > 0 {
+			b.owed += cadence.UFix64(amount)
+		}
+	}
+
+	ww, err := store.PendingWithdrawals(address, token.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range ww {
+		if settlements[w.ID] || w.FtAmount == "" {
+			continue
+		}
+
+		amount, err := decimal.ParseUFix64(w.FtAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		b.withdrawing += amount
+	}
+
+	if b.onChain+b.incoming > b.outgoing+b.withdrawing {
+		b.available = b.onChain + b.incoming - b.outgoing - b.withdrawing
+	}
+
+	return b, nil
+}
+
+// insertWithdrawals records new withdrawals of a sender. Withdrawals of a
+// fungible token are recorded holding the ledger lock of the sender and are
+// refused if they would withdraw funds owed to the recipients of its ledger
+// transfers, or funds of other withdrawals which are not final yet.
+func (s *ServiceImpl) insertWithdrawals(ctx context.Context, ww []*Withdrawal) error {
+	var total cadence.UFix64
+	for _, w := range ww {
+		if w.FtAmount == "" {
+			continue
+		}
+
+		amount, err := decimal.ParseUFix64(w.FtAmount)
+		if err != nil {
+			return err
+		}
+		total += amount
+	}
+
+	if total == 0 {
+		// Non-fungible tokens
+		for _, w := range ww {
+			if err := s.store.InsertWithdrawal(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	token, err := s.templates.GetTokenByName(ww[0].TokenName)
+	if err != nil {
+		return err
+	}
+
+	sender := ww[0].SenderAddress
+
+	return s.store.LockLedger(token.Name, []string{sender}, func(store Store) error {
+		balance, err := s.ledgerBalance(ctx, store, token, sender)
+		if err != nil {
+			return err
+		}
+
+		if total > balance.withdrawable() {
+			return &wallet_errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("insufficient balance, %s of %s can be withdrawn", balance.withdrawable(), token.Name),
+			}
+		}
+
+		for _, w := range ww {
+			if err := store.InsertWithdrawal(w); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ledgerToken returns a fungible token by name.
+func (s *ServiceImpl) ledgerToken(tokenName string) (*templates.Token, error) {
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != templates.FT {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("ledger transfers are only supported for fungible tokens"),
+		}
+	}
+
+	return token, nil
+}
@@ -0,0 +1,321 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestNetLedgerTransfers(t *testing.T) {
+	p := newLedgerPair("0x02", "0x01")
+	if p.a != "0x01" || p.b != "0x02" {
+		t.Fatalf("expected an ordered pair, got %+v", p)
+	}
+
+	transfer := func(sender, recipient, amount string) *LedgerTransfer {
+		return &LedgerTransfer{SenderAddress: sender, RecipientAddress: recipient, FtAmount: amount}
+	}
+
+	testCases := []struct {
+		name      string
+		transfers []*LedgerTransfer
+		sender    string
+		recipient string
+		net       string
+	}{
+		{
+			name:      "single transfer",
+			transfers: []*LedgerTransfer{transfer("0x02", "0x01", "1.5")},
+			sender:    "0x02",
+			recipient: "0x01",
+			net:       "1.50000000",
+		},
+		{
+			name: "both ways",
+			transfers: []*LedgerTransfer{
+				transfer("0x01", "0x02", "6.0"),
+				transfer("0x02", "0x01", "2.25"),
+				transfer("0x01", "0x02", "0.25"),
+			},
+			sender:    "0x01",
+			recipient: "0x02",
+			net:       "4.00000000",
+		},
+		{
+			name: "netted out",
+			transfers: []*LedgerTransfer{
+				transfer("0x01", "0x02", "3.0"),
+				transfer("0x02", "0x01", "1.0"),
+				transfer("0x02", "0x01", "2.0"),
+			},
+			net: "0.00000000",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sender, recipient, net, err := netLedgerTransfers(p, tc.transfers)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if sender != tc.sender || recipient != tc.recipient || net.String() != tc.net {
+				t.Fatalf("expected %q to %q of %s, got %q to %q of %s", tc.sender, tc.recipient, tc.net, sender, recipient, net)
+			}
+		})
+	}
+}
+
+func TestLedgerInsufficientBalance(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, _ := newTestService(t, nil, 2)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	chain.balances[a] = mustUFix64(t, "10.0")
+
+	assertInsufficient := func(err error) {
+		t.Helper()
+		var reqErr *wallet_errors.RequestError
+		if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected an insufficient balance error, got %v", err)
+		}
+	}
+
+	if _, err := svc.CreateLedgerTransfer(ctx, a, "FlowToken", WithdrawalRequest{Recipient: b, FtAmount: "6.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := svc.CreateLedgerTransfer(ctx, a, "FlowToken", WithdrawalRequest{Recipient: b, FtAmount: "4.5"})
+	assertInsufficient(err)
+
+	// The funds owed to b can not be withdrawn on-chain
+	_, err = svc.CreateWithdrawal(ctx, false, a, WithdrawalRequest{TokenName: "FlowToken", Recipient: b, FtAmount: "4.5"})
+	assertInsufficient(err)
+
+	_, err = svc.CreateBatchWithdrawal(ctx, false, a, "FlowToken", []WithdrawalRequest{
+		{Recipient: b, FtAmount: "2.0"},
+		{Recipient: b, FtAmount: "2.5"},
+	})
+	assertInsufficient(err)
+
+	if _, err := svc.CreateWithdrawal(ctx, false, a, WithdrawalRequest{TokenName: "FlowToken", Recipient: b, FtAmount: "3.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The withdrawal is not sent yet, its amount is spent already
+	_, err = svc.CreateLedgerTransfer(ctx, a, "FlowToken", WithdrawalRequest{Recipient: b, FtAmount: "1.5"})
+	assertInsufficient(err)
+
+	balance, err := svc.LedgerBalance(ctx, a, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := LedgerBalance{
+		TokenName:   "FlowToken",
+		OnChain:     "10.00000000",
+		Incoming:    "0.00000000",
+		Outgoing:    "6.00000000",
+		Withdrawing: "3.00000000",
+		Available:   "1.00000000",
+	}
+	if *balance != expected {
+		t.Fatalf("expected %+v, got %+v", expected, *balance)
+	}
+
+	// Funds received in the ledger are not on-chain before they are settled
+	balance, err = svc.LedgerBalance(ctx, b, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.Available != "6.00000000" {
+		t.Fatalf("expected 6.0 available, got %+v", balance)
+	}
+
+	_, err = svc.CreateWithdrawal(ctx, false, b, WithdrawalRequest{TokenName: "FlowToken", Recipient: a, FtAmount: "1.0"})
+	assertInsufficient(err)
+
+	if _, err := svc.CreateLedgerTransfer(ctx, b, "FlowToken", WithdrawalRequest{Recipient: a, FtAmount: "6.0"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLedgerSettlementNetting(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, wp := newTestService(t, nil, 3)
+	a, b, c := chain.accounts[0].Address, chain.accounts[1].Address, chain.accounts[2].Address
+
+	chain.balances[a] = mustUFix64(t, "10.0")
+	chain.balances[b] = mustUFix64(t, "10.0")
+	chain.balances[c] = mustUFix64(t, "10.0")
+
+	transfers := []struct {
+		sender, recipient, amount string
+	}{
+		{a, b, "6.0"},
+		{b, a, "2.0"},
+		{a, c, "3.0"},
+		{c, a, "3.0"},
+	}
+
+	for _, tr := range transfers {
+		if _, err := svc.CreateLedgerTransfer(ctx, tr.sender, "FlowToken", WithdrawalRequest{Recipient: tr.recipient, FtAmount: tr.amount}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := svc.SettleLedger(ctx, "FlowToken"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a and b settle with a single withdrawal, a and c net out
+	ww, err := svc.store.PendingWithdrawals(a, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ww) != 1 || ww[0].RecipientAddress != b || ww[0].FtAmount != "4.00000000" {
+		t.Fatalf("expected a withdrawal of 4.0 to %s, got %+v", b, ww)
+	}
+
+	if len(wp.scheduled) != 1 {
+		t.Fatalf("expected 1 scheduled job, got %d", len(wp.scheduled))
+	}
+
+	for _, address := range []string{b, c} {
+		ww, err := svc.store.PendingWithdrawals(address, "FlowToken")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ww) != 0 {
+			t.Fatalf("expected no withdrawals from %s, got %+v", address, ww)
+		}
+	}
+
+	tt, err := svc.store.LedgerTransfers(a, "FlowToken", datastore.ListOptions{Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tr := range tt {
+		settledWithC := tr.SenderAddress == c || tr.RecipientAddress == c
+		switch {
+		case settledWithC && tr.State != LedgerTransferSettled:
+			t.Fatalf("expected transfer between a and c to be settled, got %+v", tr)
+		case !settledWithC && (tr.State != LedgerTransferSettling || *tr.WithdrawalID != ww[0].ID):
+			t.Fatalf("expected transfer between a and b to be settling with %s, got %+v", ww[0].ID, tr)
+		}
+	}
+
+	// A second settlement does not settle the transfers again
+	if err := svc.SettleLedger(ctx, "FlowToken"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wp.scheduled) != 1 {
+		t.Fatalf("expected 1 scheduled job, got %d", len(wp.scheduled))
+	}
+
+	// Transfers are settled once the withdrawal is sealed
+	ww[0].State = WithdrawalSent
+	ww[0].TransactionId = "settlement"
+	if err := svc.store.UpdateWithdrawal(ww[0]); err != nil {
+		t.Fatal(err)
+	}
+	chain.results["settlement"] = [2]string{flow.TransactionStatusSealed.String(), ""}
+
+	if err := svc.SettleAllLedgers(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tt, err = svc.store.UnsettledLedgerTransfers("FlowToken", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tt) != 0 {
+		t.Fatalf("expected all transfers to be settled, got %+v", tt)
+	}
+}
+
+func TestLedgerFailedSettlement(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, wp := newTestService(t, nil, 2)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	chain.balances[a] = mustUFix64(t, "10.0")
+
+	if _, err := svc.CreateLedgerTransfer(ctx, a, "FlowToken", WithdrawalRequest{Recipient: b, FtAmount: "5.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The withdrawal settling the transfer can not be scheduled
+	wp.scheduleErr = errors.New("queue full")
+
+	if err := svc.SettleLedger(ctx, "FlowToken"); err != nil {
+		t.Fatal(err)
+	}
+
+	ww, err := svc.store.Withdrawals(a, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ww) != 1 || ww[0].State != WithdrawalFailed {
+		t.Fatalf("expected a failed withdrawal, got %+v", ww)
+	}
+	failed := ww[0].ID
+
+	// The transfer is pending again and settled by the next settlement
+	wp.scheduleErr = nil
+
+	if err := svc.SettleLedger(ctx, "FlowToken"); err != nil {
+		t.Fatal(err)
+	}
+
+	tt, err := svc.store.UnsettledLedgerTransfers("FlowToken", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tt) != 1 || tt[0].State != LedgerTransferSettling || *tt[0].WithdrawalID == failed {
+		t.Fatalf("expected the transfer to be settling with a new withdrawal, got %+v", tt)
+	}
+
+	// A reverted settlement withdrawal leaves the transfer pending
+	w, err := svc.store.Withdrawal(*tt[0].WithdrawalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.State = WithdrawalSent
+	w.TransactionId = "reverted"
+	if err := svc.store.UpdateWithdrawal(w); err != nil {
+		t.Fatal(err)
+	}
+	chain.results["reverted"] = [2]string{flow.TransactionStatusSealed.String(), "insufficient balance"}
+
+	balance, err := svc.LedgerBalance(ctx, a, "FlowToken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.Outgoing != "5.00000000" || balance.Withdrawing != "0.00000000" || balance.Available != "5.00000000" {
+		t.Fatalf("expected 5.0 outgoing and available, got %+v", balance)
+	}
+
+	tt, err = svc.store.UnsettledLedgerTransfers("FlowToken", a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tt) != 1 || tt[0].State != LedgerTransferPending || tt[0].WithdrawalID != nil {
+		t.Fatalf("expected the transfer to be pending, got %+v", tt)
+	}
+}
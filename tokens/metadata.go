@@ -0,0 +1,99 @@
+package tokens
+
+import (
+	"fmt"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// NFTMetadata is the metadata of an NFT resolved from its Display, Serial and
+// Royalties metadata views. Views the NFT does not resolve are omitted.
+type NFTMetadata struct {
+	ID          uint64       `json:"id"`
+	Name        string       `json:"name,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Thumbnail   string       `json:"thumbnail,omitempty"`
+	Serial      *uint64      `json:"serial,omitempty"`
+	Royalties   []NFTRoyalty `json:"royalties"`
+}
+
+// NFTRoyalty is a royalty cut of an NFT and the address receiving it.
+type NFTRoyalty struct {
+	Receiver    string `json:"receiver"`
+	Cut         string `json:"cut"`
+	Description string `json:"description,omitempty"`
+}
+
+// nftMetadataFromCadence decodes the NFTMetadata struct returned by the
+// generic non-fungible token metadata script.
+func nftMetadataFromCadence(v cadence.Value) (*NFTMetadata, error) {
+	fields, err := structFields(v)
+	if err != nil {
+		return nil, err
+	}
+
+	id, _ := fields["id"].(cadence.UInt64)
+
+	m := &NFTMetadata{
+		ID:          uint64(id),
+		Name:        optionalString(fields["name"]),
+		Description: optionalString(fields["description"]),
+		Thumbnail:   optionalString(fields["thumbnail"]),
+		Royalties:   []NFTRoyalty{},
+	}
+
+	if o, ok := fields["serial"].(cadence.Optional); ok && o.Value != nil {
+		if serial, ok := o.Value.(cadence.UInt64); ok {
+			n := uint64(serial)
+			m.Serial = &n
+		}
+	}
+
+	royalties, _ := fields["royalties"].(cadence.Array)
+	for _, r := range royalties.Values {
+		rf, err := structFields(r)
+		if err != nil {
+			return nil, err
+		}
+
+		receiver, _ := rf["receiver"].(cadence.Address)
+
+		m.Royalties = append(m.Royalties, NFTRoyalty{
+			Receiver:    flow_helpers.FormatAddress(flow.Address(receiver)),
+			Cut:         rf["cut"].String(),
+			Description: optionalString(rf["description"]),
+		})
+	}
+
+	return m, nil
+}
+
+func structFields(v cadence.Value) (map[string]cadence.Value, error) {
+	s, ok := v.(cadence.Struct)
+	if !ok || s.StructType == nil {
+		return nil, fmt.Errorf("unexpected metadata value: %v", v)
+	}
+
+	fields := make(map[string]cadence.Value, len(s.Fields))
+	for i, f := range s.StructType.Fields {
+		if i < len(s.Fields) {
+			fields[f.Identifier] = s.Fields[i]
+		}
+	}
+	return fields, nil
+}
+
+// optionalString returns the Go string of a cadence String or String?, "" for
+// nil.
+func optionalString(v cadence.Value) string {
+	if o, ok := v.(cadence.Optional); ok {
+		v = o.Value
+	}
+	s, ok := v.(cadence.String)
+	if !ok {
+		return ""
+	}
+	return string(s)
+}
@@ -0,0 +1,79 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// MintRequest is a request to mint a non-fungible token into the collection
+// of the recipient.
+type MintRequest struct {
+	Recipient string `json:"recipient"`
+	// Arguments of the mint transaction in JSON-Cadence, following the
+	// recipient address
+	Arguments []transactions.Argument `json:"arguments"`
+}
+
+// Mint sends the mint transaction of a non-fungible token from its minter
+// account, a managed account holding the minter resource of the token. The
+// recipient address is given to the transaction as its first argument. With
+// tenants the minter has to be an account of the tenant of the request.
+func (s *ServiceImpl) Mint(ctx context.Context, sync bool, tokenName string, request MintRequest) (*jobs.Job, *transactions.Transaction, error) {
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token.Type != templates.NFT {
+		return nil, nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("%s is not a non-fungible token", token.Name),
+		}
+	}
+
+	if token.Mint == "" || token.MinterAddress == "" {
+		return nil, nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("%s has no mint template or minter account", token.Name),
+		}
+	}
+
+	// Check if the input is a valid address
+	recipient, err := flow_helpers.ValidateAddress(request.Recipient, s.cfg.ChainID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The minter has to be a managed account for the service to sign
+	minter, err := s.accounts.Details(token.MinterAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The route is not scoped to an account, tenants only mint with minters
+	// of their own
+	if tenantID := tenants.FromContext(ctx); tenantID != "" && tenantID != minter.TenantID {
+		return nil, nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("the minter account of %s belongs to another tenant", token.Name),
+		}
+	}
+
+	if err := transactions.CheckNotFrozen(s.accounts, token.MinterAddress); err != nil {
+		return nil, nil, err
+	}
+
+	args := append([]transactions.Argument{cadence.NewAddress(flow.HexToAddress(recipient))}, request.Arguments...)
+
+	return s.transactions.Create(ctx, sync, token.MinterAddress, token.Mint, args, transactions.General, transactions.WithTokenName(token.Name))
+}
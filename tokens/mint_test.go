@@ -0,0 +1,53 @@
+package tokens
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+)
+
+func TestMint(t *testing.T) {
+	svc, chain, _ := newTestService(t, nil, 2)
+	minter, recipient := chain.accounts[0].Address, chain.accounts[1].Address
+	chain.accounts[0].TenantID = "shop"
+
+	chain.tokens = append(chain.tokens, templates.Token{Name: "ExampleNFT", Type: templates.NFT, Mint: "mint", MinterAddress: minter})
+
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		status int
+	}{
+		{name: "tenant of the minter", ctx: tenants.NewContext(context.Background(), "shop")},
+		{name: "admin", ctx: tenants.NewAdminContext(context.Background(), "alice")},
+		{name: "without tenants", ctx: context.Background()},
+		{name: "another tenant", ctx: tenants.NewContext(context.Background(), "games"), status: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			created := len(chain.created)
+
+			_, _, err := svc.Mint(tc.ctx, true, "ExampleNFT", MintRequest{Recipient: recipient})
+
+			if tc.status != 0 {
+				assertStatus(t, err, tc.status)
+				if len(chain.created) != created {
+					t.Fatal("expected no mint transaction")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(chain.created) != created+1 || chain.created[created].ProposerAddress != minter {
+				t.Fatalf("expected a mint transaction from the minter")
+			}
+		})
+	}
+}
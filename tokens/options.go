@@ -0,0 +1,14 @@
+package tokens
+
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+)
+
+type ServiceOption func(*ServiceImpl)
+
+// WithWebhooks enables webhook notifications of deposits and withdrawals.
+func WithWebhooks(webhookService webhooks.Service) ServiceOption {
+	return func(svc *ServiceImpl) {
+		svc.webhooks = webhookService
+	}
+}
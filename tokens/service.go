@@ -3,17 +3,27 @@ package tokens
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/chain_events"
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"github.com/google/uuid"
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	log "github.com/sirupsen/logrus"
@@ -25,16 +35,52 @@ const (
 )
 
 type Service interface {
+	// Setup sets up the token for an account, returning neither a job nor a
+	// transaction if the fungible token vault of the account already exists.
 	Setup(ctx context.Context, sync bool, tokenName, address string) (*jobs.Job, *transactions.Transaction, error)
 	AddAccountToken(tokenName, address string) error
 	AccountTokens(address string, tType templates.TokenType) ([]AccountToken, error)
 	Details(ctx context.Context, tokenName, address string) (*Details, error)
-	CreateWithdrawal(ctx context.Context, sync bool, sender string, request WithdrawalRequest) (*jobs.Job, *transactions.Transaction, error)
-	ListWithdrawals(address, tokenName string) ([]*TokenWithdrawal, error)
-	ListDeposits(address, tokenName string) ([]*TokenDeposit, error)
-	GetWithdrawal(address, tokenName, transactionId string) (*TokenWithdrawal, error)
+	CachedDetails(ctx context.Context, tokenName, address string, refresh bool) (*Details, error)
+	NFTMetadata(ctx context.Context, tokenName, address string, nftId uint64) (*NFTMetadata, error)
+	Mint(ctx context.Context, sync bool, tokenName string, request MintRequest) (*jobs.Job, *transactions.Transaction, error)
+	CreateListing(ctx context.Context, sync bool, address, tokenName string, request ListingRequest) (*jobs.Job, *transactions.Transaction, error)
+	RemoveListing(ctx context.Context, sync bool, address, tokenName string, listingID uint64) (*jobs.Job, *transactions.Transaction, error)
+	CreateWithdrawal(ctx context.Context, sync bool, sender string, request WithdrawalRequest) (*Withdrawal, error)
+	CreateBatchWithdrawal(ctx context.Context, sync bool, sender, tokenName string, requests []WithdrawalRequest) ([]*Withdrawal, error)
+	CreateWithdrawalIdempotent(ctx context.Context, sync bool, key, sender string, request WithdrawalRequest) (*Withdrawal, error)
+	CreateBatchWithdrawalIdempotent(ctx context.Context, sync bool, key, sender, tokenName string, requests []WithdrawalRequest) ([]*Withdrawal, error)
+	ListWithdrawals(ctx context.Context, address, tokenName string) ([]*Withdrawal, error)
+	ListAllWithdrawals(ctx context.Context, tokenName string, limit, offset int, f WithdrawalFilter) ([]*Withdrawal, error)
+	ListDeposits(address, tokenName string, limit, offset int, f DepositFilter) ([]*TokenDeposit, error)
+	ListAllDeposits(limit, offset int, f DepositFilter) ([]*TokenDeposit, error)
+	ListDepositNotifications(limit, offset int, undelivered bool) ([]*DepositNotification, error)
+	ListBalanceSnapshots(address, tokenName string, limit, offset int, f BalanceSnapshotFilter) ([]*BalanceSnapshot, error)
+	ListAllBalanceSnapshots(limit, offset int, f BalanceSnapshotFilter) ([]*BalanceSnapshot, error)
+	GetWithdrawal(ctx context.Context, address, tokenName, withdrawalId string) (*Withdrawal, error)
 	GetDeposit(address, tokenName, transactionId string) (*TokenDeposit, error)
 	RegisterDeposit(ctx context.Context, token *templates.Token, transactionId flow.Identifier, recipient accounts.Account, amountOrNftID string) error
+	ApproveWithdrawal(ctx context.Context, withdrawalId string) (*Withdrawal, error)
+	RejectWithdrawal(ctx context.Context, withdrawalId string) (*Withdrawal, error)
+	AllowWithdrawalDestination(ctx context.Context, tokenName, address, description string) (*WithdrawalDestination, error)
+	ListWithdrawalDestinations(ctx context.Context, tokenName string) ([]WithdrawalDestination, error)
+	RemoveWithdrawalDestination(ctx context.Context, tokenName, address string) error
+	CreateLedgerTransfer(ctx context.Context, sender, tokenName string, request WithdrawalRequest) (*LedgerTransfer, error)
+	ListLedgerTransfers(ctx context.Context, address, tokenName string, limit, offset int) ([]*LedgerTransfer, error)
+	LedgerBalance(ctx context.Context, address, tokenName string) (*LedgerBalance, error)
+	SettleLedger(ctx context.Context, tokenName string) error
+	SettleAllLedgers(ctx context.Context) error
+	TokenInfo(ctx context.Context, tokenName string) (*TokenInfo, error)
+	Holdings(ctx context.Context, tokenName string) (*Holdings, error)
+	TransferFeeEstimate(ctx context.Context, tokenName string) (*FeeEstimate, error)
+	// TrackTransfers updates sent withdrawals and confirms deposits, notifying webhooks of both.
+	TrackTransfers(ctx context.Context) error
+	// Sweep moves balances above threshold from custodial accounts to the treasury account.
+	Sweep(ctx context.Context, tokenName, treasury string, threshold cadence.UFix64) error
+	// SnapshotBalances records the daily balances of fungible tokens of managed accounts.
+	SnapshotBalances(ctx context.Context) error
+	// ExpireApprovals rejects withdrawals which were not approved in time.
+	ExpireApprovals(ctx context.Context) error
 
 	// DeployTokenContractForAccount is only used in tests
 	DeployTokenContractForAccount(ctx context.Context, runSync bool, tokenName, address string) error
@@ -49,6 +95,9 @@ type ServiceImpl struct {
 	templates    templates.Service
 	accounts     accounts.Service
 	cfg          *configs.Config
+	webhooks     webhooks.Service
+	balances     *balanceCache
+	rules        withdrawalRules
 }
 
 func NewService(
@@ -60,10 +109,33 @@ func NewService(
 	txs transactions.Service,
 	tes templates.Service,
 	acs accounts.Service,
+	opts ...ServiceOption,
 ) Service {
 	// TODO(latenssi): safeguard against nil config?
 
-	svc := &ServiceImpl{store, km, fc, wp, txs, tes, acs, cfg}
+	rules, err := parseWithdrawalRules(cfg)
+	if err != nil {
+		// Checked on startup with ValidateWithdrawalRules
+		panic(err)
+	}
+
+	svc := &ServiceImpl{
+		store:        store,
+		km:           km,
+		fc:           fc,
+		wp:           wp,
+		transactions: txs,
+		templates:    tes,
+		accounts:     acs,
+		cfg:          cfg,
+		balances:     newBalanceCache(),
+		rules:        rules,
+	}
+
+	// Go through options
+	for _, opt := range opts {
+		opt(svc)
+	}
 
 	if wp == nil {
 		panic("workerpool nil")
@@ -71,6 +143,9 @@ func NewService(
 
 	// Register asynchronous job executor.
 	wp.RegisterExecutor(WithdrawalCreateJobType, svc.executeCreateWithdrawalJob)
+	wp.RegisterExecutor(WithdrawalBatchJobType, svc.executeBatchWithdrawalJob)
+	wp.RegisterRetryPolicy(WithdrawalCreateJobType, svc.withdrawalRetryPolicy())
+	wp.RegisterRetryPolicy(WithdrawalBatchJobType, svc.withdrawalRetryPolicy())
 
 	return svc
 }
@@ -88,6 +163,10 @@ func (s *ServiceImpl) Setup(ctx context.Context, sync bool, tokenName, address s
 		return nil, nil, err
 	}
 
+	if err := transactions.CheckNotFrozen(s.accounts, address); err != nil {
+		return nil, nil, err
+	}
+
 	token, err := s.templates.GetTokenByName(tokenName)
 	if err != nil {
 		return nil, nil, err
@@ -98,29 +177,43 @@ func (s *ServiceImpl) Setup(ctx context.Context, sync bool, tokenName, address s
 	switch token.Type {
 	case templates.FT:
 		txType = transactions.FtSetup
+
+		// Setting up an existing vault is a no-op, no job or transaction is created
+		setUp, err := s.vaultSetUp(ctx, token, address)
+		if err != nil {
+			return nil, nil, err
+		}
+		if setUp != nil && *setUp {
+			s.addAccountToken(address, token)
+			return nil, nil, nil
+		}
 	case templates.NFT:
 		txType = transactions.NftSetup
 	}
 
-	job, tx, err := s.transactions.Create(ctx, sync, address, token.Setup, nil, txType)
+	job, tx, err := s.transactions.Create(ctx, sync, address, token.Setup, nil, txType, transactions.WithTokenName(token.Name))
 
 	if err == nil || strings.Contains(err.Error(), "vault exists") {
-		// Handle adding token to account in database
-		if err := s.store.InsertAccountToken(&AccountToken{
-			AccountAddress: address,
-			TokenAddress:   token.Address,
-			TokenName:      token.Name,
-			TokenType:      token.Type,
-		}); err != nil {
-			log.
-				WithFields(log.Fields{"error": err}).
-				Warn("Error while adding account token")
-		}
+		s.addAccountToken(address, token)
 	}
 
 	return job, tx, err
 }
 
+// addAccountToken handles adding the token to the account in database.
+func (s *ServiceImpl) addAccountToken(address string, token *templates.Token) {
+	if err := s.store.InsertAccountToken(&AccountToken{
+		AccountAddress: address,
+		TokenAddress:   token.Address,
+		TokenName:      token.Name,
+		TokenType:      token.Type,
+	}); err != nil {
+		log.
+			WithFields(log.Fields{"error": err}).
+			Warn("Error while adding account token")
+	}
+}
+
 func (s *ServiceImpl) AddAccountToken(tokenName, address string) error {
 	// Check if the input is a valid address
 	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
@@ -178,96 +271,440 @@ func (s *ServiceImpl) Details(ctx context.Context, tokenName, address string) (*
 		return nil, fmt.Errorf("unsupported token type: %s", token.Type)
 	}
 
+	details := &Details{TokenName: token.Name}
+
+	if token.Type == templates.FT {
+		details.VaultSetUp, err = s.vaultSetUp(ctx, token, address)
+		if err != nil {
+			return nil, err
+		}
+		if details.VaultSetUp != nil && !*details.VaultSetUp {
+			// Balance of null rather than zero
+			details.Balance = &Balance{}
+			return details, nil
+		}
+	}
+
 	res, err := s.transactions.ExecuteScript(ctx, token.Balance, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(address))})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Details{TokenName: token.Name, Balance: &Balance{CadenceValue: res}}, nil
+	details.Balance = &Balance{CadenceValue: res}
+
+	return details, nil
 }
 
-func (s *ServiceImpl) CreateWithdrawal(ctx context.Context, sync bool, sender string, request WithdrawalRequest) (*jobs.Job, *transactions.Transaction, error) {
-	log.WithFields(log.Fields{"sync": sync}).Trace("Create withdrawal")
+// NFTMetadata resolves the metadata views of an NFT in the collection of an
+// account. The collection has to expose MetadataViews.ResolverCollection
+// through the public path of the token.
+func (s *ServiceImpl) NFTMetadata(ctx context.Context, tokenName, address string, nftId uint64) (*NFTMetadata, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
 
-	if !sync {
-		// Async
-		attrs := withdrawalCreateJobAttributes{sender, request}
-		attrBytes, err := json.Marshal(attrs)
-		if err != nil {
-			return nil, nil, err
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != templates.NFT {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("%s is not a non-fungible token", token.Name),
 		}
+	}
 
-		job, err := s.wp.CreateJob(WithdrawalCreateJobType, "", jobs.WithAttributes(attrBytes))
-		if err != nil {
-			return nil, nil, err
+	code, err := templates.NonFungibleMetadataCode(s.cfg.ChainID, token)
+	if err != nil {
+		return nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("metadata of %s can not be resolved: %w", token.Name, err),
 		}
+	}
 
-		err = s.wp.Schedule(job)
-		if err != nil {
-			return nil, nil, err
+	res, err := s.transactions.ExecuteScript(ctx, code, []transactions.Argument{
+		cadence.NewAddress(flow.HexToAddress(address)),
+		cadence.NewUInt64(nftId),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nftMetadataFromCadence(res)
+}
+
+// vaultSetUp checks whether the fungible token vault of the account is set up,
+// nil if it can not be checked as the paths of the token are not known.
+func (s *ServiceImpl) vaultSetUp(ctx context.Context, token *templates.Token, address string) (*bool, error) {
+	code, err := templates.FungibleVaultCheckCode(s.cfg.ChainID, token)
+	if err != nil {
+		return nil, nil
+	}
+
+	res, err := s.transactions.ExecuteScript(ctx, code, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(address))})
+	if err != nil {
+		return nil, err
+	}
+
+	ok, _ := res.(cadence.Bool)
+	setUp := bool(ok)
+
+	return &setUp, nil
+}
+
+// CreateWithdrawal records a withdrawal and sends its transfer, in a job
+// unless sync.
+func (s *ServiceImpl) CreateWithdrawal(ctx context.Context, sync bool, sender string, request WithdrawalRequest) (*Withdrawal, error) {
+	log.WithFields(log.Fields{"sync": sync}).Trace("Create withdrawal")
+
+	if err := s.checkIdempotencyKey(ctx); err != nil {
+		return nil, err
+	}
+
+	w, err := s.newWithdrawal(ctx, sender, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkDestination(w); err != nil {
+		return nil, err
+	}
+
+	token, err := s.templates.GetTokenByName(w.TokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkWithdrawalRules(ctx, token, []*Withdrawal{w}); err != nil {
+		return nil, err
+	}
+
+	approval, err := s.needsApproval(w)
+	if err != nil {
+		return nil, err
+	}
+
+	if approval {
+		return s.requestApproval(ctx, w)
+	}
+
+	// Withdrawals are customer-facing, ahead of background traffic
+	return s.requestWithdrawal(ctx, sync, w, jobs.PriorityHigh)
+}
+
+// requestWithdrawal records a new withdrawal and sends its transfer, in a job
+// of the given priority unless sync.
+func (s *ServiceImpl) requestWithdrawal(ctx context.Context, sync bool, w *Withdrawal, priority jobs.Priority) (*Withdrawal, error) {
+	// Rejected up front so async withdrawals of frozen accounts fail immediately
+	if err := transactions.CheckNotFrozen(s.accounts, w.SenderAddress); err != nil {
+		return nil, err
+	}
+
+	if err := s.insertWithdrawals(ctx, []*Withdrawal{w}); err != nil {
+		return nil, err
+	}
+
+	if !sync {
+		// Async
+		if err := s.scheduleWithdrawal(ctx, w, priority); err != nil {
+			return nil, err
 		}
 
-		return job, nil, err
+		return w, nil
 
 	} else {
 		// Sync
-		transaction, err := s.createWithdrawal(ctx, sender, request)
-		if err != nil {
-			return nil, nil, err
+		if err := s.sendWithdrawal(ctx, w); err != nil {
+			if w.State == WithdrawalRequested {
+				// Not retried
+				return nil, s.failWithdrawal(w, err)
+			}
+			return nil, err
 		}
 
-		return nil, transaction, nil
+		return w, nil
 	}
 }
 
-func (s *ServiceImpl) listTransfers(queryType, address, tokenName string) ([]*TokenTransfer, error) {
-	// Check if the input is a valid address
-	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+// scheduleWithdrawal creates and schedules the job sending a recorded withdrawal.
+func (s *ServiceImpl) scheduleWithdrawal(ctx context.Context, w *Withdrawal, priority jobs.Priority) error {
+	attrs := withdrawalCreateJobAttributes{WithdrawalID: w.ID}
+	attrBytes, err := json.Marshal(attrs)
+	if err != nil {
+		return s.failWithdrawal(w, err)
+	}
+
+	job, err := s.wp.CreateJob(
+		WithdrawalCreateJobType,
+		"",
+		jobs.WithAttributes(attrBytes),
+		jobs.WithTenantID(tenants.FromContext(ctx)),
+		jobs.WithPriority(priority),
+	)
+	if err != nil {
+		return s.failWithdrawal(w, err)
+	}
+
+	w.JobID = &job.ID
+	if err := s.store.UpdateWithdrawal(w); err != nil {
+		return err
+	}
+
+	if err := s.wp.Schedule(job); err != nil {
+		return s.failWithdrawal(w, err)
+	}
+
+	return nil
+}
+
+// newWithdrawal validates a withdrawal request and returns a withdrawal for it.
+func (s *ServiceImpl) newWithdrawal(ctx context.Context, sender string, request WithdrawalRequest) (*Withdrawal, error) {
+	// Check if the sender is a valid address
+	sender, err := flow_helpers.ValidateAddress(sender, s.cfg.ChainID)
 	if err != nil {
 		return nil, err
 	}
 
-	token, err := s.templates.GetTokenByName(tokenName)
+	// Check if the recipient is a valid address
+	recipient, err := flow_helpers.ValidateAddress(request.Recipient, s.cfg.ChainID)
 	if err != nil {
 		return nil, err
 	}
 
+	token, err := s.templates.GetTokenByName(request.TokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Withdrawal{
+		State:            WithdrawalRequested,
+		SenderAddress:    sender,
+		RecipientAddress: recipient,
+		TokenName:        token.Name,
+		IdempotencyKey:   idempotencyKeyFromContext(ctx),
+		TenantID:         tenants.FromContext(ctx),
+	}
+
 	switch token.Type {
 	case templates.FT:
+		amount, err := decimal.ParseAmount(request.FtAmount)
+		if err != nil {
+			return nil, &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+		}
+		// Stored with all decimal places
+		w.FtAmount = amount.String()
 	case templates.NFT:
-		// Continue normal flow
+		w.NftID = request.NftID
 	default:
 		return nil, fmt.Errorf("unsupported token type: %s", token.Type)
 	}
 
-	switch queryType {
+	return w, nil
+}
+
+// sendWithdrawal sends the transfer of a requested withdrawal and records
+// the resulting state. Withdrawals whose transaction is not sealed in time
+// stay sent, the withdrawal is updated when it is next queried.
+func (s *ServiceImpl) sendWithdrawal(ctx context.Context, w *Withdrawal) error {
+	transaction, err := s.createWithdrawal(ctx, w.SenderAddress, w.request())
+	return s.recordWithdrawalResult(transaction, err, w)
+}
+
+// recordWithdrawalResult records the state of withdrawals after sending their
+// transaction and returns the error of sending, nil if the transaction was
+// sent but is not sealed yet.
+func (s *ServiceImpl) recordWithdrawalResult(transaction *transactions.Transaction, err error, ww ...*Withdrawal) error {
+	var (
+		pending  *transactions.PendingError
+		reverted *transactions.RevertedError
+	)
+
+	for _, w := range ww {
+		switch {
+		case err == nil:
+			w.TransactionId = transaction.TransactionId
+			w.State = WithdrawalSealed
+			w.Error = ""
+		case errors.As(err, &pending):
+			w.TransactionId = pending.Job.TransactionID
+			w.State = WithdrawalSent
+			w.Error = ""
+		case errors.As(err, &reverted):
+			w.TransactionId = reverted.Transaction.TransactionId
+			w.State = WithdrawalFailed
+			w.Error = err.Error()
+		default:
+			// Not sent, async withdrawals are retried
+			w.Error = err.Error()
+		}
+
+		s.balances.invalidate(w.SenderAddress, w.TokenName)
+		s.balances.invalidate(w.RecipientAddress, w.TokenName)
+
+		if updateErr := s.store.UpdateWithdrawal(w); updateErr != nil {
+			log.
+				WithFields(log.Fields{"error": updateErr, "withdrawalId": w.ID}).
+				Error("Error while updating withdrawal")
+			continue
+		}
+
+		if w.State != WithdrawalRequested {
+			s.notifyWithdrawal(w)
+		}
+	}
+
+	if errors.As(err, &pending) {
+		return nil
+	}
+
+	return err
+}
+
+// failWithdrawal marks a withdrawal that will not be sent failed and returns err.
+func (s *ServiceImpl) failWithdrawal(w *Withdrawal, err error) error {
+	w.State = WithdrawalFailed
+	w.Error = err.Error()
+
+	if updateErr := s.store.UpdateWithdrawal(w); updateErr != nil {
+		log.
+			WithFields(log.Fields{"error": updateErr, "withdrawalId": w.ID}).
+			Error("Error while updating withdrawal")
+	} else {
+		s.notifyWithdrawal(w)
+	}
+
+	return err
+}
+
+// syncWithdrawal updates a sent withdrawal with the result of its transaction.
+func (s *ServiceImpl) syncWithdrawal(ctx context.Context, w *Withdrawal) {
+	if w.State != WithdrawalSent {
+		return
+	}
+
+	tx, err := s.transactions.Details(ctx, w.TransactionId)
+	if err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "withdrawalId": w.ID}).
+			Warn("Could not get the result of a withdrawal")
+		return
+	}
+
+	switch tx.Status {
+	case flow.TransactionStatusSealed.String():
+		if tx.ErrorMessage == "" {
+			w.State = WithdrawalSealed
+		} else {
+			w.State = WithdrawalFailed
+			w.Error = tx.ErrorMessage
+		}
+	case flow.TransactionStatusExpired.String(), transactions.StatusCancelled:
+		w.State = WithdrawalFailed
+		w.Error = fmt.Sprintf("transaction %s", strings.ToLower(tx.Status))
 	default:
-		return nil, fmt.Errorf("unknown transfer type %s", queryType)
-	case queryTypeWithdrawal:
-		return s.store.TokenWithdrawals(address, token)
-	case queryTypeDeposit:
-		return s.store.TokenDeposits(address, token)
+		// Not final yet
+		return
 	}
+
+	s.balances.invalidate(w.SenderAddress, w.TokenName)
+	s.balances.invalidate(w.RecipientAddress, w.TokenName)
+
+	if err := s.store.UpdateWithdrawal(w); err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "withdrawalId": w.ID}).
+			Error("Error while updating withdrawal")
+		return
+	}
+
+	s.notifyWithdrawal(w)
 }
 
-func (s *ServiceImpl) ListWithdrawals(address, tokenName string) ([]*TokenWithdrawal, error) {
-	tt, err := s.listTransfers(queryTypeWithdrawal, address, tokenName)
+func (s *ServiceImpl) ListWithdrawals(ctx context.Context, address, tokenName string) ([]*Withdrawal, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
 	if err != nil {
 		return nil, err
 	}
-	res := make([]*TokenWithdrawal, len(tt))
-	for i, t := range tt {
-		w := t.Withdrawal()
-		res[i] = &w
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
 	}
-	return res, nil
+
+	ww, err := s.store.Withdrawals(address, token.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range ww {
+		s.syncWithdrawal(ctx, w)
+	}
+
+	return ww, nil
 }
 
-func (s *ServiceImpl) ListDeposits(address, tokenName string) ([]*TokenDeposit, error) {
-	tt, err := s.listTransfers(queryTypeDeposit, address, tokenName)
+// ListAllWithdrawals returns the withdrawals of a token from all accounts,
+// newest first. They can be filtered by tenant, state and creation time range.
+func (s *ServiceImpl) ListAllWithdrawals(ctx context.Context, tokenName string, limit, offset int, f WithdrawalFilter) ([]*Withdrawal, error) {
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return nil, err
+	}
+
+	ww, err := s.store.AllWithdrawals(token.Name, datastore.ParseListOptions(limit, offset), f)
 	if err != nil {
 		return nil, err
 	}
+
+	for _, w := range ww {
+		s.syncWithdrawal(ctx, w)
+	}
+
+	return ww, nil
+}
+
+// ListDeposits returns the deposits to an account, newest first.
+func (s *ServiceImpl) ListDeposits(address, tokenName string, limit, offset int, f DepositFilter) ([]*TokenDeposit, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Address = address
+	f.TokenName = tokenName
+
+	return s.ListAllDeposits(limit, offset, f)
+}
+
+// ListAllDeposits returns the deposits to all accounts, newest first. They can
+// be filtered by recipient, token, tenant and creation time range.
+func (s *ServiceImpl) ListAllDeposits(limit, offset int, f DepositFilter) ([]*TokenDeposit, error) {
+	if f.Address != "" {
+		// Check if the input is a valid address
+		address, err := flow_helpers.ValidateAddress(f.Address, s.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		f.Address = address
+	}
+
+	if f.TokenName != "" {
+		token, err := s.templates.GetTokenByName(f.TokenName)
+		if err != nil {
+			return nil, err
+		}
+		f.TokenName = token.Name
+	}
+
+	tt, err := s.store.Deposits(datastore.ParseListOptions(limit, offset), f)
+	if err != nil {
+		return nil, err
+	}
+
 	res := make([]*TokenDeposit, len(tt))
 	for i, t := range tt {
 		d := t.Deposit()
@@ -312,13 +749,45 @@ func (s *ServiceImpl) getTransfer(queryType, address, tokenName, transactionId s
 	}
 }
 
-func (s *ServiceImpl) GetWithdrawal(address, tokenName, transactionId string) (*TokenWithdrawal, error) {
-	t, err := s.getTransfer(queryTypeWithdrawal, address, tokenName, transactionId)
+// GetWithdrawal returns a withdrawal of an account by its ID or the ID of its
+// transaction.
+func (s *ServiceImpl) GetWithdrawal(ctx context.Context, address, tokenName, withdrawalId string) (*Withdrawal, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
 	if err != nil {
 		return nil, err
 	}
-	w := t.Withdrawal()
-	return &w, nil
+
+	var w *Withdrawal
+	if id, err := uuid.Parse(withdrawalId); err == nil {
+		if w, err = s.store.Withdrawal(id); err != nil {
+			return nil, err
+		}
+	} else {
+		// Check if the input is a valid transaction id
+		if err := flow_helpers.ValidateTransactionId(withdrawalId); err != nil {
+			return nil, err
+		}
+
+		token, err := s.templates.GetTokenByName(tokenName)
+		if err != nil {
+			return nil, err
+		}
+
+		if w, err = s.store.WithdrawalByTransaction(address, withdrawalId, token.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	// Withdrawals of other accounts, tokens and tenants are not found
+	tenantID := tenants.FromContext(ctx)
+	if w.SenderAddress != address || !strings.EqualFold(w.TokenName, tokenName) || (tenantID != "" && tenantID != w.TenantID) {
+		return nil, fmt.Errorf("record not found")
+	}
+
+	s.syncWithdrawal(ctx, w)
+
+	return w, nil
 }
 
 func (s *ServiceImpl) GetDeposit(address, tokenName, transactionId string) (*TokenDeposit, error) {
@@ -350,6 +819,8 @@ func (s *ServiceImpl) RegisterDeposit(ctx context.Context, token *templates.Toke
 		return fmt.Errorf("unsupported token type: %s", token.Type)
 	}
 
+	s.balances.invalidate(recipient.Address, token.Name)
+
 	// TODO (latenssi): db lock for transaction; could it also allow "syncing" when running multiple instances?
 
 	// Get existing transaction or create one
@@ -363,6 +834,9 @@ func (s *ServiceImpl) RegisterDeposit(ctx context.Context, token *templates.Toke
 		// Transaction was just created
 		// Transfer most likely did not originate in this wallet service
 		transaction.TransactionType = transactions.FtTransfer
+		if token.Type == templates.NFT {
+			transaction.TransactionType = transactions.NftTransfer
+		}
 		transaction.ProposerAddress = flow_helpers.FormatAddress(flowTx.ProposalKey.Address)
 		if err := s.transactions.UpdateTransaction(transaction); err != nil {
 			return err
@@ -381,32 +855,142 @@ func (s *ServiceImpl) RegisterDeposit(ctx context.Context, token *templates.Toke
 		return err
 	}
 
+	blockHeight := chain_events.BlockHeight(ctx)
+
 	// Check for existing deposit
-	if _, err := s.store.TokenDeposit(recipient.Address, transaction.TransactionId, token); err != nil {
+	if existing, err := s.store.TokenDeposit(recipient.Address, transaction.TransactionId, token); err != nil {
 		if !strings.Contains(err.Error(), "record not found") {
 			// Error did not contain "record not found"
 			return err
 		}
 		// Error contains "record not found", proceed
 	} else {
-		// err == nil, existing deposit found, we are done
-		return nil
+		// err == nil, existing deposit found, e.g. a withdrawal from another
+		// account of this service, we are done once it has a block height
+		// and its deposit event has been seen
+		if existing.DetectedAt != nil && (existing.BlockHeight > 0 || blockHeight == 0) {
+			return nil
+		}
+
+		detected := existing.DetectedAt == nil
+		if detected {
+			now := time.Now()
+			existing.DetectedAt = &now
+		}
+
+		if existing.BlockHeight == 0 {
+			existing.BlockHeight = blockHeight
+		}
+
+		var n *DepositNotification
+		if detected {
+			if n, err = s.newDepositNotification(webhooks.EventDepositDetected, existing); err != nil {
+				return err
+			}
+		}
+
+		return s.store.SaveDeposit(existing, n)
+	}
+
+	// Default to the authorizer of the transaction as the sender
+	sender := flow_helpers.FormatAddress(flowTx.Authorizers[0])
+
+	if token.Type == templates.NFT {
+		// The owner the NFT was withdrawn from, if any, is the actual sender
+		owner, err := s.nftOwner(ctx, token, transactionId, nftId)
+		if err != nil {
+			return err
+		}
+		if owner != "" {
+			sender = owner
+		}
 	}
 
 	// Create and store a new token transfer
+	detectedAt := time.Now()
 	transfer := &TokenTransfer{
 		TransactionId:    transaction.TransactionId,
 		RecipientAddress: recipient.Address,
-		SenderAddress:    flow_helpers.FormatAddress(flowTx.Authorizers[0]),
+		SenderAddress:    sender,
 		FtAmount:         ftAmount,
 		NftID:            nftId,
 		TokenName:        token.Name,
+		BlockHeight:      blockHeight,
+		DetectedAt:       &detectedAt,
 	}
 
-	if err := s.store.InsertTokenTransfer(transfer); err != nil {
+	n, err := s.newDepositNotification(webhooks.EventDepositDetected, transfer)
+	if err != nil {
+		return err
+	}
+
+	return s.store.SaveDeposit(transfer, n)
+}
+
+// nftOwner returns the address the NFT was withdrawn from in the transaction,
+// "" if it was not withdrawn from an account, e.g. when it was minted.
+func (s *ServiceImpl) nftOwner(ctx context.Context, token *templates.Token, transactionId flow.Identifier, nftId uint64) (string, error) {
+	result, err := s.fc.GetTransactionResult(ctx, transactionId)
+	if err != nil {
+		return "", err
+	}
+
+	withdrawType := templates.EventType(strings.TrimPrefix(token.Address, "0x"), token.Name, templates.EventWithdraw)
+	id := strconv.FormatUint(nftId, 10)
+
+	for _, e := range result.Events {
+		if e.Type != withdrawType || len(e.Value.Fields) < 2 || e.Value.Fields[0].String() != id {
+			continue
+		}
+
+		// Withdraw(id: UInt64, from: Address?)
+		if from, ok := e.Value.Fields[1].(cadence.Optional); ok && from.Value != nil {
+			return flow_helpers.FormatAddress(flow.HexToAddress(from.Value.String())), nil
+		}
+	}
+
+	return "", nil
+}
+
+// setUpRecipientVault synchronously sets up the missing vault of a custodial
+// recipient of a transfer if SetupRecipientVaults is configured. Recipients
+// which are not accounts of the service, or are frozen, are left alone.
+func (s *ServiceImpl) setUpRecipientVault(ctx context.Context, token *templates.Token, recipient string) error {
+	if !s.cfg.SetupRecipientVaults {
+		return nil
+	}
+
+	account, err := s.accounts.Details(recipient)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil
+		}
 		return err
 	}
 
+	if account.Type != accounts.AccountTypeCustodial || account.Frozen {
+		return nil
+	}
+
+	// Nil for tokens without paths, which can not be checked
+	setUp, err := s.vaultSetUp(ctx, token, recipient)
+	if err != nil {
+		return err
+	}
+	if setUp == nil || *setUp {
+		return nil
+	}
+
+	log.
+		WithFields(log.Fields{"token": token.Name, "recipient": recipient}).
+		Info("Setting up the vault of a recipient")
+
+	// Set up concurrently if the vault exists by now. Otherwise the error is
+	// not wrapped, a pending or reverted setup must not be taken for the transfer.
+	if _, _, err := s.Setup(ctx, true, token.Name, recipient); err != nil && !strings.Contains(err.Error(), "vault exists") {
+		return fmt.Errorf("could not set up the %s vault of recipient %s: %s", token.Name, recipient, err)
+	}
+
 	return nil
 }
 
@@ -436,10 +1020,13 @@ func (s *ServiceImpl) createWithdrawal(ctx context.Context, sender string, reque
 	switch token.Type {
 	case templates.FT:
 		txType = transactions.FtTransfer
-		amount, err := cadence.NewUFix64(request.FtAmount)
+		amount, err := decimal.ParseAmount(request.FtAmount)
 		if err != nil {
 			return nil, err
 		}
+		if err := s.setUpRecipientVault(ctx, token, recipient); err != nil {
+			return nil, err
+		}
 		arguments[0] = amount
 		arguments[1] = cadence.NewAddress(flow.HexToAddress(recipient))
 	case templates.NFT:
@@ -451,7 +1038,7 @@ func (s *ServiceImpl) createWithdrawal(ctx context.Context, sender string, reque
 	}
 
 	// Create the transaction, must be sync here
-	_, transaction, err := s.transactions.Create(ctx, true, sender, token.Transfer, arguments, txType)
+	_, transaction, err := s.transactions.Create(ctx, true, sender, token.Transfer, arguments, txType, transactions.WithTokenName(token.Name))
 	if err != nil {
 		return nil, err
 	}
@@ -464,6 +1051,7 @@ func (s *ServiceImpl) createWithdrawal(ctx context.Context, sender string, reque
 		FtAmount:         request.FtAmount,
 		NftID:            request.NftID,
 		TokenName:        token.Name,
+		BlockHeight:      transaction.BlockHeight,
 	}
 
 	if err := s.store.InsertTokenTransfer(transfer); err != nil {
@@ -0,0 +1,232 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+const testBalanceCode = "balance"
+
+// dummyWorkerPool records the jobs scheduled, they are not executed. The
+// methods which are not implemented panic.
+type dummyWorkerPool struct {
+	jobs.WorkerPool
+	scheduled   []*jobs.Job
+	scheduleErr error
+}
+
+func (wp *dummyWorkerPool) CreateJob(jobType, txID string, opts ...jobs.JobOption) (*jobs.Job, error) {
+	job := &jobs.Job{Type: jobType, TransactionID: txID}
+	for _, opt := range opts {
+		opt(job)
+	}
+	return job, nil
+}
+
+func (wp *dummyWorkerPool) Schedule(j *jobs.Job) error {
+	if wp.scheduleErr != nil {
+		return wp.scheduleErr
+	}
+	wp.scheduled = append(wp.scheduled, j)
+	return nil
+}
+
+// dummyChain holds the state served by the dummy services: the tokens, the
+// managed accounts, their on-chain balances and the results of transactions.
+type dummyChain struct {
+	tokens   []templates.Token
+	accounts []accounts.Account
+	balances map[string]cadence.UFix64
+	// Status and error message of transactions by ID
+	results map[string][2]string
+	frozen  map[string]bool
+	// Error of scripts reading the balances of several accounts
+	balancesErr error
+	// Transactions created, not sent
+	created []*transactions.Transaction
+	// Error of creating transactions
+	createErr error
+}
+
+// Methods of the dummy services which are not implemented panic.
+type (
+	dummyTransactions struct {
+		transactions.Service
+		*dummyChain
+	}
+	dummyTemplates struct {
+		templates.Service
+		*dummyChain
+	}
+	dummyAccounts struct {
+		accounts.Service
+		*dummyChain
+	}
+)
+
+// ExecuteScript serves the balance script of the test token and the scripts
+// reading the balances of several accounts, accounts without a balance have
+// no vault.
+func (c dummyTransactions) ExecuteScript(ctx context.Context, code string, args []transactions.Argument) (cadence.Value, error) {
+	if addresses, ok := args[0].(cadence.Array); ok {
+		if c.balancesErr != nil {
+			return nil, c.balancesErr
+		}
+
+		pairs := []cadence.KeyValuePair{}
+		for _, v := range addresses.Values {
+			address := flow_helpers.FormatAddress(flow.Address(v.(cadence.Address)))
+			if balance, ok := c.balances[address]; ok {
+				pairs = append(pairs, cadence.KeyValuePair{Key: v, Value: balance})
+			}
+		}
+		return cadence.NewDictionary(pairs), nil
+	}
+
+	if code != testBalanceCode {
+		return nil, fmt.Errorf("unexpected script: %s", code)
+	}
+
+	address := flow_helpers.FormatAddress(flow.Address(args[0].(cadence.Address)))
+	return c.balances[address], nil
+}
+
+func (c dummyTransactions) Details(ctx context.Context, transactionId string) (*transactions.Transaction, error) {
+	r, ok := c.results[transactionId]
+	if !ok {
+		return nil, fmt.Errorf("record not found")
+	}
+	return &transactions.Transaction{TransactionId: transactionId, Status: r[0], ErrorMessage: r[1]}, nil
+}
+
+// Create records the transactions created, they are not sent.
+func (c dummyTransactions) Create(ctx context.Context, sync bool, proposerAddress string, code string, args []transactions.Argument, tType transactions.Type, opts ...transactions.TransactionOption) (*jobs.Job, *transactions.Transaction, error) {
+	if c.createErr != nil {
+		return nil, nil, c.createErr
+	}
+	tx := &transactions.Transaction{ProposerAddress: proposerAddress, TransactionType: tType}
+	c.created = append(c.created, tx)
+	return nil, tx, nil
+}
+
+func (c dummyTemplates) GetTokenByName(name string) (*templates.Token, error) {
+	for i := range c.tokens {
+		if strings.EqualFold(c.tokens[i].Name, name) {
+			t := c.tokens[i]
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("record not found")
+}
+
+func (c dummyTemplates) ListTokensFull(tType templates.TokenType) ([]templates.Token, error) {
+	tt := []templates.Token{}
+	for _, t := range c.tokens {
+		if t.Type == tType {
+			tt = append(tt, t)
+		}
+	}
+	return tt, nil
+}
+
+func (c dummyAccounts) List(limit, offset int, filter accounts.ListFilter) ([]accounts.Account, error) {
+	if offset >= len(c.accounts) {
+		return []accounts.Account{}, nil
+	}
+	end := offset + limit
+	if end > len(c.accounts) {
+		end = len(c.accounts)
+	}
+	return c.accounts[offset:end], nil
+}
+
+func (c dummyAccounts) Details(address string) (accounts.Account, error) {
+	for _, a := range c.accounts {
+		if a.Address == address {
+			return a, nil
+		}
+	}
+	return accounts.Account{}, fmt.Errorf("record not found")
+}
+
+func (c dummyAccounts) IsFrozen(address string) (bool, error) {
+	return c.frozen[address], nil
+}
+
+// newTestService returns a token service on a fresh sqlite database, with
+// n custodial accounts of the emulator and a fungible token named
+// "FlowToken".
+func newTestService(t *testing.T, cfg *configs.Config, n int) (*ServiceImpl, *dummyChain, *dummyWorkerPool) {
+	t.Helper()
+
+	if cfg == nil {
+		cfg = &configs.Config{}
+	}
+	cfg.ChainID = flow.Emulator
+	cfg.DatabaseType = "sqlite"
+	cfg.DatabaseDSN = path.Join(t.TempDir(), "test.db")
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gorm.Close(db) })
+
+	chain := &dummyChain{
+		tokens:   []templates.Token{{Name: "FlowToken", Type: templates.FT, Balance: testBalanceCode}},
+		balances: make(map[string]cadence.UFix64),
+		results:  make(map[string][2]string),
+		frozen:   make(map[string]bool),
+	}
+
+	gen := flow.NewAddressGenerator(flow.Emulator)
+	for i := 0; i < n; i++ {
+		chain.accounts = append(chain.accounts, accounts.Account{
+			Address: flow_helpers.FormatAddress(gen.NextAddress()),
+			Type:    accounts.AccountTypeCustodial,
+		})
+	}
+
+	rules, err := parseWithdrawalRules(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wp := &dummyWorkerPool{}
+
+	svc := &ServiceImpl{
+		store:        NewGormStore(db),
+		wp:           wp,
+		transactions: dummyTransactions{dummyChain: chain},
+		templates:    dummyTemplates{dummyChain: chain},
+		accounts:     dummyAccounts{dummyChain: chain},
+		cfg:          cfg,
+		balances:     newBalanceCache(),
+		rules:        rules,
+	}
+
+	return svc, chain, wp
+}
+
+func mustUFix64(t *testing.T, s string) cadence.UFix64 {
+	t.Helper()
+
+	v, err := cadence.NewUFix64(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
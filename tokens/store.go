@@ -1,6 +1,12 @@
 package tokens
 
-import "github.com/flow-hydraulics/flow-wallet-api/templates"
+import (
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/google/uuid"
+)
 
 // Store manages data regarding tokens.
 type Store interface {
@@ -11,8 +17,83 @@ type Store interface {
 	InsertAccountToken(at *AccountToken) error
 
 	InsertTokenTransfer(*TokenTransfer) error
+	UpdateTokenTransfer(*TokenTransfer) error
 	TokenWithdrawals(address string, token *templates.Token) ([]*TokenTransfer, error)
 	TokenWithdrawal(address, transactionId string, token *templates.Token) (*TokenTransfer, error)
 	TokenDeposits(address string, token *templates.Token) ([]*TokenTransfer, error)
 	TokenDeposit(address, transactionId string, token *templates.Token) (*TokenTransfer, error)
+	Deposits(o datastore.ListOptions, f DepositFilter) ([]*TokenTransfer, error)
+	// Amounts of the transfers of a token to accounts of the service, of a tenant unless tenantID is empty
+	DepositedAmounts(tokenName, tenantID string) ([]string, error)
+	// Amounts of the transfers of a token from accounts of the service which did not fail, of a tenant unless tenantID is empty
+	WithdrawnAmounts(tokenName, tenantID string) ([]string, error)
+	// Detected deposits to accounts of the service up to a block height which are not confirmed yet, at most datastore.DefaultLimit of them
+	UnconfirmedDeposits(height uint64) ([]*TokenTransfer, error)
+	// Save the confirmation of a deposit, false if it was confirmed already,
+	// with its notification if any in the same database transaction
+	ConfirmDeposit(*TokenTransfer, *DepositNotification) (bool, error)
+	// Insert or update a deposit with its notification if any in a single
+	// database transaction, a notification of an event of a deposit is only
+	// stored once
+	SaveDeposit(*TokenTransfer, *DepositNotification) error
+
+	// Deposit notifications not delivered to all endpoints, oldest first, with their receipts
+	UndeliveredDepositNotifications(limit int) ([]*DepositNotification, error)
+	// Deposit notifications with their receipts, newest first
+	DepositNotifications(o datastore.ListOptions, undelivered bool) ([]*DepositNotification, error)
+	UpdateDepositNotification(*DepositNotification) error
+	InsertDepositNotificationReceipt(*DepositNotificationReceipt) error
+
+	InsertWithdrawal(*Withdrawal) error
+	UpdateWithdrawal(*Withdrawal) error
+	Withdrawals(address, tokenName string) ([]*Withdrawal, error)
+	AllWithdrawals(tokenName string, o datastore.ListOptions, f WithdrawalFilter) ([]*Withdrawal, error)
+	Withdrawal(id uuid.UUID) (*Withdrawal, error)
+	BatchWithdrawals(batchID uuid.UUID) ([]*Withdrawal, error)
+	// Withdrawals of a token from an account which are not final yet
+	PendingWithdrawals(address, tokenName string) ([]*Withdrawal, error)
+	// Execution efforts of the transactions of the latest sealed withdrawals of a token, batches left out
+	WithdrawalExecutionEfforts(tokenName string, limit int) ([]uint64, error)
+	// Save the state of a withdrawal pending approval, false if it was not pending anymore
+	ResolvePendingWithdrawal(*Withdrawal) (bool, error)
+	// Withdrawals pending approval created before the given time
+	ExpiredApprovals(createdBefore time.Time) ([]*Withdrawal, error)
+	WithdrawalByTransaction(address, transactionId, tokenName string) (*Withdrawal, error)
+	// Withdrawals created with an idempotency key, oldest first
+	IdempotentWithdrawals(key string) ([]*Withdrawal, error)
+
+	// Get a withdrawal idempotency key
+	WithdrawalIdempotencyKey(key string) (WithdrawalIdempotencyKey, error)
+	// Insert a new withdrawal idempotency key, fails if it exists
+	InsertWithdrawalIdempotencyKey(k *WithdrawalIdempotencyKey) error
+	// Delete a withdrawal idempotency key
+	DeleteWithdrawalIdempotencyKey(key string) error
+
+	InsertWithdrawalDestination(*WithdrawalDestination) error
+	WithdrawalDestinations(tokenName string) ([]WithdrawalDestination, error)
+	RemoveWithdrawalDestination(tokenName, address string) error
+
+	InsertLedgerTransfer(*LedgerTransfer) error
+	UpdateLedgerTransfer(*LedgerTransfer) error
+	// Ledger transfers of a token from or to an account, newest first
+	LedgerTransfers(address, tokenName string, o datastore.ListOptions) ([]*LedgerTransfer, error)
+	// Ledger transfers of a token which are not settled, from or to an account unless address is empty
+	UnsettledLedgerTransfers(tokenName, address string) ([]*LedgerTransfer, error)
+	// Names of the tokens with ledger transfers which are not settled
+	UnsettledLedgerTokens() ([]string, error)
+	// Move the ledger transfers settling with a withdrawal to state, pending
+	// transfers are unlinked from the withdrawal
+	ResolveLedgerTransfers(withdrawalID uuid.UUID, state LedgerTransferState) error
+	// Run fn holding the ledger locks of a token of the accounts, with a
+	// store bound to the database transaction of the locks
+	LockLedger(tokenName string, addresses []string, fn func(Store) error) error
+
+	// Insert balance snapshots, a snapshot of an account and token already taken on the same date is kept
+	InsertBalanceSnapshots([]*BalanceSnapshot) error
+	// Record that the balance snapshots of a token have been taken of all accounts on a date
+	InsertBalanceSnapshotRun(*BalanceSnapshotRun) error
+	// Whether the balance snapshots of a token have been taken of all accounts on a date
+	BalanceSnapshotRunCompleted(tokenName, date string) (bool, error)
+	// Balance snapshots, newest first
+	BalanceSnapshots(o datastore.ListOptions, f BalanceSnapshotFilter) ([]*BalanceSnapshot, error)
 }
@@ -2,19 +2,26 @@ package tokens
 
 import (
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/lib"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type GormStore struct {
-	db *gorm.DB
+	db          *gorm.DB
+	ledgerMutex *sync.Mutex
 }
 
 func NewGormStore(db *gorm.DB) Store {
-	return &GormStore{db}
+	return &GormStore{db: db, ledgerMutex: &sync.Mutex{}}
 }
 
 func (s *GormStore) AccountTokens(address string, tokenType templates.TokenType) (att []AccountToken, err error) {
@@ -40,6 +47,10 @@ func (s *GormStore) InsertTokenTransfer(t *TokenTransfer) error {
 	return s.db.Create(t).Error
 }
 
+func (s *GormStore) UpdateTokenTransfer(t *TokenTransfer) error {
+	return s.db.Omit(clause.Associations).Save(t).Error
+}
+
 func tokenToTransferType(token *templates.Token) (*transactions.Type, error) {
 	var txType transactions.Type
 	switch token.Type {
@@ -110,6 +121,168 @@ func (s *GormStore) TokenDeposits(address string, token *templates.Token) (tt []
 	return
 }
 
+// Deposits lists transfers to accounts of the service.
+func (s *GormStore) Deposits(o datastore.ListOptions, f DepositFilter) (tt []*TokenTransfer, err error) {
+	q := s.db.
+		Preload(clause.Associations).
+		Select("token_transfers.*").
+		Joins("join accounts on token_transfers.recipient_address = accounts.address")
+
+	if f.Address != "" {
+		q = q.Where("token_transfers.recipient_address = ?", f.Address)
+	}
+
+	if f.TokenName != "" {
+		q = q.Where("token_transfers.token_name = ?", f.TokenName)
+	}
+
+	if f.TenantID != "" {
+		q = q.Where("accounts.tenant_id = ?", f.TenantID)
+	}
+
+	if f.CreatedAfter != nil {
+		q = q.Where("token_transfers.created_at >= ?", *f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != nil {
+		q = q.Where("token_transfers.created_at < ?", *f.CreatedBefore)
+	}
+
+	err = q.
+		Order("token_transfers.created_at desc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&tt).Error
+	return
+}
+
+func (s *GormStore) DepositedAmounts(tokenName, tenantID string) (amounts []string, err error) {
+	q := s.db.
+		Model(&TokenTransfer{}).
+		Joins("join accounts on token_transfers.recipient_address = accounts.address").
+		Where("token_transfers.token_name = ?", tokenName)
+
+	if tenantID != "" {
+		q = q.Where("accounts.tenant_id = ?", tenantID)
+	}
+
+	err = q.Pluck("token_transfers.ft_amount", &amounts).Error
+	return
+}
+
+func (s *GormStore) WithdrawnAmounts(tokenName, tenantID string) (amounts []string, err error) {
+	q := s.db.
+		Model(&TokenTransfer{}).
+		Joins("join accounts on token_transfers.sender_address = accounts.address").
+		Joins("left join transactions on token_transfers.transaction_id = transactions.transaction_id").
+		Where("token_transfers.token_name = ?", tokenName).
+		Where("(transactions.error_message IS NULL OR transactions.error_message = '')")
+
+	if tenantID != "" {
+		q = q.Where("accounts.tenant_id = ?", tenantID)
+	}
+
+	err = q.Pluck("token_transfers.ft_amount", &amounts).Error
+	return
+}
+
+func (s *GormStore) UnconfirmedDeposits(height uint64) (tt []*TokenTransfer, err error) {
+	err = s.db.
+		Select("token_transfers.*").
+		Joins("join accounts on token_transfers.recipient_address = accounts.address").
+		Where("token_transfers.detected_at IS NOT NULL").
+		Where("token_transfers.confirmed_at IS NULL").
+		Where("token_transfers.block_height > 0 AND token_transfers.block_height <= ?", height).
+		Order("token_transfers.block_height").
+		Limit(datastore.DefaultLimit).
+		Find(&tt).Error
+	return
+}
+
+func (s *GormStore) ConfirmDeposit(t *TokenTransfer, n *DepositNotification) (confirmed bool, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Model(&TokenTransfer{}).
+			Where("id = ? AND confirmed_at IS NULL", t.ID).
+			Update("confirmed_at", t.ConfirmedAt)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		confirmed = res.RowsAffected > 0
+		if !confirmed {
+			return nil
+		}
+
+		return insertDepositNotification(tx, t, n)
+	})
+	return
+}
+
+func (s *GormStore) SaveDeposit(t *TokenTransfer, n *DepositNotification) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if t.ID == 0 {
+			err = tx.Create(t).Error
+		} else {
+			err = tx.Omit(clause.Associations).Save(t).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return insertDepositNotification(tx, t, n)
+	})
+}
+
+func insertDepositNotification(tx *gorm.DB, t *TokenTransfer, n *DepositNotification) error {
+	if n == nil {
+		return nil
+	}
+
+	n.TransferID = t.ID
+
+	// Notified once per deposit and event
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(n).Error
+}
+
+func (s *GormStore) UndeliveredDepositNotifications(limit int) (nn []*DepositNotification, err error) {
+	err = s.db.
+		Preload("Receipts").
+		Where("delivered_at IS NULL").
+		Order("created_at asc").
+		Limit(limit).
+		Find(&nn).Error
+	return
+}
+
+func (s *GormStore) DepositNotifications(o datastore.ListOptions, undelivered bool) (nn []*DepositNotification, err error) {
+	q := s.db.Preload("Receipts")
+
+	if undelivered {
+		q = q.Where("delivered_at IS NULL")
+	}
+
+	err = q.
+		Order("created_at desc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&nn).Error
+	return
+}
+
+func (s *GormStore) UpdateDepositNotification(n *DepositNotification) error {
+	return s.db.
+		Model(n).
+		Select("attempts", "last_error", "delivered_at").
+		Updates(n).Error
+}
+
+func (s *GormStore) InsertDepositNotificationReceipt(r *DepositNotificationReceipt) error {
+	// A concurrent delivery may have been acknowledged first
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(r).Error
+}
+
 func (s *GormStore) TokenDeposit(address, transactionId string, token *templates.Token) (t *TokenTransfer, err error) {
 	txType, err := tokenToTransferType(token)
 	if err != nil {
@@ -128,3 +301,274 @@ func (s *GormStore) TokenDeposit(address, transactionId string, token *templates
 		First(&t).Error
 	return
 }
+
+func (s *GormStore) InsertWithdrawal(w *Withdrawal) error {
+	return s.db.Create(w).Error
+}
+
+func (s *GormStore) UpdateWithdrawal(w *Withdrawal) error {
+	return s.db.Save(w).Error
+}
+
+func (s *GormStore) Withdrawals(address, tokenName string) (ww []*Withdrawal, err error) {
+	err = s.db.
+		Where(&Withdrawal{SenderAddress: address, TokenName: tokenName}).
+		Order("created_at desc").
+		Find(&ww).Error
+	return
+}
+
+// AllWithdrawals lists the withdrawals of a token from all accounts.
+func (s *GormStore) AllWithdrawals(tokenName string, o datastore.ListOptions, f WithdrawalFilter) (ww []*Withdrawal, err error) {
+	q := s.db.Where(&Withdrawal{TokenName: tokenName, TenantID: f.TenantID})
+
+	if len(f.States) > 0 {
+		q = q.Where("state IN ?", f.States)
+	}
+
+	if f.CreatedAfter != nil {
+		q = q.Where("created_at >= ?", *f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != nil {
+		q = q.Where("created_at < ?", *f.CreatedBefore)
+	}
+
+	err = q.
+		Order("created_at desc").
+		// ID as a tiebreaker keeps pagination stable
+		Order("id asc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&ww).Error
+	return
+}
+
+func (s *GormStore) Withdrawal(id uuid.UUID) (w *Withdrawal, err error) {
+	err = s.db.Where(&Withdrawal{ID: id}).First(&w).Error
+	return
+}
+
+func (s *GormStore) BatchWithdrawals(batchID uuid.UUID) (ww []*Withdrawal, err error) {
+	err = s.db.
+		Where(&Withdrawal{BatchID: &batchID}).
+		Order("created_at asc").
+		Find(&ww).Error
+	return
+}
+
+func (s *GormStore) PendingWithdrawals(address, tokenName string) (ww []*Withdrawal, err error) {
+	err = s.db.
+		Where(&Withdrawal{SenderAddress: address, TokenName: tokenName}).
+		Where("state IN ?", []WithdrawalState{WithdrawalPendingApproval, WithdrawalRequested, WithdrawalSent}).
+		Find(&ww).Error
+	return
+}
+
+func (s *GormStore) WithdrawalExecutionEfforts(tokenName string, limit int) (efforts []uint64, err error) {
+	err = s.db.
+		Model(&Withdrawal{}).
+		Joins("join transactions on withdrawals.transaction_id = transactions.transaction_id").
+		Where("withdrawals.token_name = ?", tokenName).
+		Where("withdrawals.state = ?", WithdrawalSealed).
+		Where("withdrawals.batch_id IS NULL").
+		Where("transactions.execution_effort > 0").
+		Order("withdrawals.created_at desc").
+		Limit(limit).
+		Pluck("transactions.execution_effort", &efforts).Error
+	return
+}
+
+func (s *GormStore) IdempotentWithdrawals(key string) (ww []*Withdrawal, err error) {
+	err = s.db.
+		Where("idempotency_key = ?", key).
+		Order("created_at asc").
+		Find(&ww).Error
+	return
+}
+
+func (s *GormStore) WithdrawalIdempotencyKey(key string) (k WithdrawalIdempotencyKey, err error) {
+	err = s.db.Where(&WithdrawalIdempotencyKey{Key: key}).First(&k).Error
+	return
+}
+
+func (s *GormStore) InsertWithdrawalIdempotencyKey(k *WithdrawalIdempotencyKey) error {
+	return s.db.Create(k).Error
+}
+
+func (s *GormStore) DeleteWithdrawalIdempotencyKey(key string) error {
+	return s.db.Where(&WithdrawalIdempotencyKey{Key: key}).Delete(&WithdrawalIdempotencyKey{}).Error
+}
+
+func (s *GormStore) ResolvePendingWithdrawal(w *Withdrawal) (bool, error) {
+	res := s.db.
+		Model(&Withdrawal{}).
+		Where(&Withdrawal{ID: w.ID, State: WithdrawalPendingApproval}).
+		Updates(map[string]interface{}{"state": w.State, "error": w.Error, "resolved_by": w.ResolvedBy})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (s *GormStore) ExpiredApprovals(createdBefore time.Time) (ww []*Withdrawal, err error) {
+	err = s.db.
+		Where(&Withdrawal{State: WithdrawalPendingApproval}).
+		Where("created_at < ?", createdBefore).
+		Order("created_at asc").
+		Find(&ww).Error
+	return
+}
+
+func (s *GormStore) WithdrawalByTransaction(address, transactionId, tokenName string) (w *Withdrawal, err error) {
+	err = s.db.
+		Where(&Withdrawal{SenderAddress: address, TransactionId: transactionId, TokenName: tokenName}).
+		Order("created_at desc").
+		First(&w).Error
+	return
+}
+
+func (s *GormStore) InsertWithdrawalDestination(d *WithdrawalDestination) error {
+	return s.db.Create(d).Error
+}
+
+func (s *GormStore) WithdrawalDestinations(tokenName string) (dd []WithdrawalDestination, err error) {
+	err = s.db.Where(&WithdrawalDestination{TokenName: tokenName}).Order("created_at asc").Find(&dd).Error
+	return
+}
+
+func (s *GormStore) RemoveWithdrawalDestination(tokenName, address string) error {
+	res := s.db.Where(&WithdrawalDestination{TokenName: tokenName, Address: address}).Delete(&WithdrawalDestination{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (s *GormStore) InsertLedgerTransfer(t *LedgerTransfer) error {
+	return s.db.Create(t).Error
+}
+
+func (s *GormStore) UpdateLedgerTransfer(t *LedgerTransfer) error {
+	return s.db.Save(t).Error
+}
+
+func (s *GormStore) LedgerTransfers(address, tokenName string, o datastore.ListOptions) (tt []*LedgerTransfer, err error) {
+	err = s.db.
+		Where(&LedgerTransfer{TokenName: tokenName}).
+		Where("sender_address = ? OR recipient_address = ?", address, address).
+		Order("created_at desc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&tt).Error
+	return
+}
+
+func (s *GormStore) UnsettledLedgerTransfers(tokenName, address string) (tt []*LedgerTransfer, err error) {
+	q := s.db.
+		Where(&LedgerTransfer{TokenName: tokenName}).
+		Where("state IN ?", []LedgerTransferState{LedgerTransferPending, LedgerTransferSettling})
+
+	if address != "" {
+		q = q.Where("sender_address = ? OR recipient_address = ?", address, address)
+	}
+
+	err = q.Order("created_at asc").Find(&tt).Error
+	return
+}
+
+func (s *GormStore) UnsettledLedgerTokens() (names []string, err error) {
+	err = s.db.
+		Model(&LedgerTransfer{}).
+		Where("state IN ?", []LedgerTransferState{LedgerTransferPending, LedgerTransferSettling}).
+		Distinct().
+		Pluck("token_name", &names).Error
+	return
+}
+
+func (s *GormStore) ResolveLedgerTransfers(withdrawalID uuid.UUID, state LedgerTransferState) error {
+	updates := map[string]interface{}{"state": state, "updated_at": time.Now()}
+	if state == LedgerTransferPending {
+		updates["withdrawal_id"] = nil
+	}
+
+	// Conditional, so transfers settled again in the meantime are left alone
+	return s.db.
+		Model(&LedgerTransfer{}).
+		Where("withdrawal_id = ? AND state = ?", withdrawalID, LedgerTransferSettling).
+		Updates(updates).Error
+}
+
+func (s *GormStore) LockLedger(tokenName string, addresses []string, fn func(Store) error) error {
+	// Serializes the ledger within this instance, sqlite has no row locks
+	s.ledgerMutex.Lock()
+	defer s.ledgerMutex.Unlock()
+
+	// Locked in a fixed order so locking the same accounts can not deadlock
+	addresses = append([]string(nil), addresses...)
+	sort.Strings(addresses)
+
+	return lib.GormTransaction(s.db, func(tx *gorm.DB) error {
+		for _, address := range addresses {
+			l := LedgerLock{TokenName: tokenName, AccountAddress: address}
+
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&l).Error; err != nil {
+				return err
+			}
+
+			if err := tx.
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where(&l).
+				First(&LedgerLock{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// The mutex is held already
+		return fn(&GormStore{db: tx, ledgerMutex: &sync.Mutex{}})
+	})
+}
+
+func (s *GormStore) InsertBalanceSnapshots(ss []*BalanceSnapshot) error {
+	if len(ss) == 0 {
+		return nil
+	}
+	// The first snapshot of a day is kept
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&ss).Error
+}
+
+func (s *GormStore) InsertBalanceSnapshotRun(r *BalanceSnapshotRun) error {
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(r).Error
+}
+
+func (s *GormStore) BalanceSnapshotRunCompleted(tokenName, date string) (bool, error) {
+	var count int64
+	err := s.db.
+		Model(&BalanceSnapshotRun{}).
+		Where(&BalanceSnapshotRun{TokenName: tokenName, Date: date}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s *GormStore) BalanceSnapshots(o datastore.ListOptions, f BalanceSnapshotFilter) (ss []*BalanceSnapshot, err error) {
+	q := s.db.Where(&BalanceSnapshot{AccountAddress: f.Address, TokenName: f.TokenName, TenantID: f.TenantID})
+
+	if f.From != "" {
+		q = q.Where("date >= ?", f.From)
+	}
+
+	if f.To != "" {
+		q = q.Where("date <= ?", f.To)
+	}
+
+	err = q.
+		Order("date desc").
+		Order("account_address asc").
+		Order("token_name asc").
+		Limit(o.Limit).
+		Offset(o.Offset).
+		Find(&ss).Error
+	return
+}
@@ -0,0 +1,176 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/templates/render"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// ListingRequest is a request to list an NFT for sale in the NFTStorefrontV2
+// storefront of an account.
+type ListingRequest struct {
+	NftID uint64 `json:"nftId"`
+	// Fungible token the NFT is sold for
+	PaymentToken string `json:"paymentToken"`
+	Price        string `json:"price"`
+	// Commission is the part of the price paid to the marketplace completing
+	// the sale, none if empty
+	Commission string `json:"commission,omitempty"`
+	// CommissionReceivers are the marketplaces which can complete the sale,
+	// any if empty
+	CommissionReceivers []string  `json:"commissionReceivers,omitempty"`
+	Expiry              time.Time `json:"expiry"`
+}
+
+// CreateListing lists an NFT of a managed account for sale in the
+// NFTStorefrontV2 storefront of the account, setting up the storefront if
+// needed. The ID of the listing is in the ListingAvailable event of the
+// transaction.
+func (s *ServiceImpl) CreateListing(ctx context.Context, sync bool, address, tokenName string, request ListingRequest) (*jobs.Job, *transactions.Transaction, error) {
+	address, token, storefront, err := s.storefrontAccount(address, tokenName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paymentToken, err := s.templates.GetTokenByName(request.PaymentToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if paymentToken.Type != templates.FT {
+		return nil, nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("payment token %s is not a fungible token", paymentToken.Name),
+		}
+	}
+
+	price, err := decimal.ParseAmount(request.Price)
+	if err != nil {
+		return nil, nil, &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("price: %w", err)}
+	}
+
+	var commission cadence.UFix64
+	if request.Commission != "" {
+		commission, err = decimal.ParseUFix64(request.Commission)
+		if err != nil {
+			return nil, nil, &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("commission: %w", err)}
+		}
+	}
+
+	if commission >= price {
+		return nil, nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("commission %s has to be less than the price %s", commission, price),
+		}
+	}
+
+	receivers := make([]cadence.Value, len(request.CommissionReceivers))
+	for i, r := range request.CommissionReceivers {
+		r, err := flow_helpers.ValidateAddress(r, s.cfg.ChainID)
+		if err != nil {
+			return nil, nil, &wallet_errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("commission receiver %d: %w", i, err)}
+		}
+		receivers[i] = cadence.NewAddress(flow.HexToAddress(r))
+	}
+
+	if !request.Expiry.After(time.Now()) {
+		return nil, nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("expiry has to be in the future"),
+		}
+	}
+
+	code, err := templates.NFTStorefrontListingCode(s.cfg.ChainID, storefront, token, paymentToken)
+	if err != nil {
+		return nil, nil, &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("listings of %s for %s are not supported: %w", token.Name, paymentToken.Name, err),
+		}
+	}
+
+	args := []transactions.Argument{
+		cadence.NewUInt64(request.NftID),
+		price,
+		commission,
+		cadence.NewUInt64(uint64(request.Expiry.Unix())),
+		cadence.NewArray(receivers),
+	}
+
+	return s.transactions.Create(ctx, sync, address, code, args, transactions.General, transactions.WithTokenName(token.Name))
+}
+
+// RemoveListing removes a listing from the NFTStorefrontV2 storefront of a
+// managed account.
+func (s *ServiceImpl) RemoveListing(ctx context.Context, sync bool, address, tokenName string, listingID uint64) (*jobs.Job, *transactions.Transaction, error) {
+	address, token, storefront, err := s.storefrontAccount(address, tokenName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	code := templates.NFTStorefrontRemoveListingCode(storefront)
+	args := []transactions.Argument{cadence.NewUInt64(listingID)}
+
+	return s.transactions.Create(ctx, sync, address, code, args, transactions.General, transactions.WithTokenName(token.Name))
+}
+
+// storefrontAccount checks that address is a managed account which can send
+// transactions and tokenName a non-fungible token, and returns the address
+// of the NFTStorefrontV2 contract.
+func (s *ServiceImpl) storefrontAccount(address, tokenName string) (string, *templates.Token, string, error) {
+	// Check if the input is a valid address
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	// Check if the account with given address exists
+	if _, err := s.accounts.Details(address); err != nil {
+		return "", nil, "", err
+	}
+
+	if err := transactions.CheckNotFrozen(s.accounts, address); err != nil {
+		return "", nil, "", err
+	}
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	if token.Type != templates.NFT {
+		return "", nil, "", &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("%s is not a non-fungible token", token.Name),
+		}
+	}
+
+	storefront := s.cfg.NFTStorefrontAddress
+	if storefront == "" {
+		storefront = render.ForChain(s.cfg.ChainID)["NFTStorefrontV2"]
+	}
+
+	if storefront == "" {
+		return "", nil, "", &wallet_errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("no NFTStorefrontV2 contract on %s, set FLOW_WALLET_NFT_STOREFRONT_ADDRESS", s.cfg.ChainID),
+		}
+	}
+
+	storefront, err = flow_helpers.ValidateAddress(storefront, s.cfg.ChainID)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return address, token, storefront, nil
+}
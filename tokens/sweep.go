@@ -0,0 +1,138 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/onflow/cadence"
+	log "github.com/sirupsen/logrus"
+)
+
+// Number of accounts read from the datastore at a time when sweeping.
+const sweepPageSize = 100
+
+// Sweep moves the balance of a fungible token above threshold from all
+// custodial accounts to the treasury account. Every sweep is a withdrawal
+// marked as a sweep, sent in a low priority job. Accounts with withdrawals or
+// ledger transfers of the token which are not final yet are left for the next
+// sweep as their balance is about to change. Failed sweeps are logged and do not stop
+// sweeping the rest of the accounts. The treasury is global: the accounts of
+// all tenants are swept to it, each sweep is recorded for the tenant of its
+// account.
+func (s *ServiceImpl) Sweep(ctx context.Context, tokenName, treasury string, threshold cadence.UFix64) error {
+	treasury, err := flow_helpers.ValidateAddress(treasury, s.cfg.ChainID)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.templates.GetTokenByName(tokenName)
+	if err != nil {
+		return err
+	}
+
+	if token.Type != templates.FT {
+		return fmt.Errorf("%s is not a fungible token", token.Name)
+	}
+
+	swept, failed := 0, 0
+
+	filter := accounts.ListFilter{Type: accounts.AccountTypeCustodial, Sort: "address"}
+
+	for offset := 0; ; offset += sweepPageSize {
+		aa, err := s.accounts.List(sweepPageSize, offset, filter)
+		if err != nil {
+			return err
+		}
+
+		for i := range aa {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if aa[i].Address == treasury || aa[i].Frozen {
+				continue
+			}
+
+			ok, err := s.sweepAccount(ctx, token, &aa[i], treasury, threshold)
+			if err != nil {
+				failed++
+				log.
+					WithFields(log.Fields{"error": err, "address": aa[i].Address, "token": token.Name}).
+					Warn("Sweeping account failed")
+				continue
+			}
+			if ok {
+				swept++
+			}
+		}
+
+		if len(aa) < sweepPageSize {
+			break
+		}
+	}
+
+	log.WithFields(log.Fields{"token": token.Name, "swept": swept, "failed": failed}).Info("Swept account balances")
+
+	return nil
+}
+
+// sweepAccount requests a sweep of the balance of an account above threshold,
+// returning whether a sweep was requested.
+func (s *ServiceImpl) sweepAccount(ctx context.Context, token *templates.Token, account *accounts.Account, treasury string, threshold cadence.UFix64) (bool, error) {
+	pending, err := s.store.PendingWithdrawals(account.Address, token.Name)
+	if err != nil {
+		return false, err
+	}
+
+	for _, w := range pending {
+		s.syncWithdrawal(ctx, w)
+		if w.State == WithdrawalPendingApproval || w.State == WithdrawalRequested || w.State == WithdrawalSent {
+			return false, nil
+		}
+	}
+
+	// Ledger transfers are settled from the on-chain balance
+	unsettled, err := s.store.UnsettledLedgerTransfers(token.Name, account.Address)
+	if err != nil {
+		return false, err
+	}
+
+	if len(unsettled) > 0 {
+		return false, nil
+	}
+
+	details, err := s.Details(ctx, token.Name, account.Address)
+	if err != nil {
+		return false, err
+	}
+
+	// Not a UFix64 if the vault is not set up
+	balance, ok := details.Balance.CadenceValue.(cadence.UFix64)
+	if !ok || balance <= threshold {
+		return false, nil
+	}
+
+	ctx = tenants.NewContext(ctx, account.TenantID)
+
+	w, err := s.newWithdrawal(ctx, account.Address, WithdrawalRequest{
+		TokenName: token.Name,
+		Recipient: treasury,
+		FtAmount:  (balance - threshold).String(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	w.Sweep = true
+
+	if _, err := s.requestWithdrawal(ctx, false, w, jobs.PriorityLow); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
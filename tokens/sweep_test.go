@@ -0,0 +1,109 @@
+package tokens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, wp := newTestService(t, nil, 8)
+
+	var (
+		above     = chain.accounts[0].Address
+		at        = chain.accounts[1].Address
+		pending   = chain.accounts[2].Address
+		ledger    = chain.accounts[3].Address
+		frozen    = chain.accounts[4].Address
+		sealed    = chain.accounts[5].Address
+		noVault   = chain.accounts[6].Address
+		treasury  = chain.accounts[7].Address
+		threshold = mustUFix64(t, "0.5")
+	)
+
+	for _, a := range []string{above, pending, ledger, frozen, sealed, treasury} {
+		chain.balances[a] = mustUFix64(t, "10.25")
+	}
+	chain.balances[at] = threshold
+	chain.accounts[4].Frozen = true
+
+	// Accounts whose balance is about to change are left for the next sweep
+	if _, err := svc.CreateWithdrawal(ctx, false, pending, WithdrawalRequest{TokenName: "FlowToken", Recipient: above, FtAmount: "1.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.CreateLedgerTransfer(ctx, ledger, "FlowToken", WithdrawalRequest{Recipient: treasury, FtAmount: "1.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Final withdrawals do not hold back a sweep
+	w, err := svc.CreateWithdrawal(ctx, false, sealed, WithdrawalRequest{TokenName: "FlowToken", Recipient: above, FtAmount: "1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.State = WithdrawalSent
+	w.TransactionId = "sealed"
+	if err := svc.store.UpdateWithdrawal(w); err != nil {
+		t.Fatal(err)
+	}
+	chain.results["sealed"] = [2]string{flow.TransactionStatusSealed.String(), ""}
+
+	scheduled := len(wp.scheduled)
+
+	if err := svc.Sweep(ctx, "FlowToken", treasury, threshold); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(wp.scheduled) - scheduled; n != 2 {
+		t.Fatalf("expected 2 sweeps to be scheduled, got %d", n)
+	}
+
+	testCases := []struct {
+		name    string
+		address string
+		amount  string
+	}{
+		{name: "above threshold", address: above, amount: "9.75000000"},
+		{name: "at threshold", address: at},
+		{name: "pending withdrawal", address: pending},
+		{name: "unsettled ledger transfer", address: ledger},
+		{name: "frozen", address: frozen},
+		{name: "sealed withdrawal", address: sealed, amount: "9.75000000"},
+		{name: "no vault", address: noVault},
+		{name: "treasury", address: treasury},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ww, err := svc.store.Withdrawals(tc.address, "FlowToken")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var sweeps []*Withdrawal
+			for _, w := range ww {
+				if w.Sweep {
+					sweeps = append(sweeps, w)
+				}
+			}
+
+			if tc.amount == "" {
+				if len(sweeps) != 0 {
+					t.Fatalf("expected no sweep, got %+v", sweeps[0])
+				}
+				return
+			}
+
+			if len(sweeps) != 1 {
+				t.Fatalf("expected a sweep, got %d", len(sweeps))
+			}
+
+			if s := sweeps[0]; s.FtAmount != tc.amount || s.RecipientAddress != treasury || s.State != WithdrawalRequested {
+				t.Fatalf("expected a sweep of %s to the treasury, got %+v", tc.amount, s)
+			}
+		})
+	}
+}
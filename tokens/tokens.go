@@ -3,11 +3,15 @@
 package tokens
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
 	"github.com/flow-hydraulics/flow-wallet-api/templates"
 	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +19,12 @@ type Details struct {
 	TokenName string   `json:"name"`
 	Address   string   `json:"address,omitempty"`
 	Balance   *Balance `json:"balance,omitempty"`
+	// VaultSetUp tells fungible tokens without a vault apart from a zero
+	// balance, the balance is null if the vault is not set up
+	VaultSetUp *bool `json:"vaultSetUp,omitempty"`
+	// CheckedAt is when the balance was read from the chain, it may be
+	// cached, see BalanceCacheTTL
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
 }
 
 type WithdrawalRequest struct {
@@ -24,6 +34,16 @@ type WithdrawalRequest struct {
 	NftID     uint64 `json:"nftId,omitempty"`
 }
 
+// DepositFilter limits a listing of deposits to a recipient, a token, the
+// accounts of a tenant and to a creation time range.
+type DepositFilter struct {
+	Address       string
+	TokenName     string
+	TenantID      string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
 // AccountToken represents a token that is enabled on an account.
 type AccountToken struct {
 	ID             uint64              `json:"-" gorm:"column:id;primaryKey"`
@@ -51,9 +71,14 @@ type TokenTransfer struct {
 	FtAmount         string                   `gorm:"column:ft_amount"`
 	NftID            uint64                   `gorm:"column:nft_id"`
 	TokenName        string                   `gorm:"column:token_name"`
-	CreatedAt        time.Time                `gorm:"column:created_at"`
-	UpdatedAt        time.Time                `gorm:"column:updated_at"`
-	DeletedAt        gorm.DeletedAt           `gorm:"column:deleted_at;index"`
+	BlockHeight      uint64                   `gorm:"column:block_height"`
+	// DetectedAt is set once the deposit event of the transfer is seen,
+	// ConfirmedAt once its block is deep enough, see TrackTransfers
+	DetectedAt  *time.Time     `gorm:"column:detected_at"`
+	ConfirmedAt *time.Time     `gorm:"column:confirmed_at;index"`
+	CreatedAt   time.Time      `gorm:"column:created_at"`
+	UpdatedAt   time.Time      `gorm:"column:updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"column:deleted_at;index"`
 }
 
 func (TokenTransfer) TableName() string {
@@ -66,6 +91,7 @@ type TokenTransferBase struct {
 	FtAmount      string    `json:"amount"`
 	NftID         uint64    `json:"nftId"`
 	TokenName     string    `json:"token"`
+	BlockHeight   uint64    `json:"blockHeight,omitempty"`
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
 }
@@ -79,7 +105,9 @@ type TokenWithdrawal struct {
 // TokenDeposit is used for JSON interfacing
 type TokenDeposit struct {
 	TokenTransferBase
-	SenderAddress string `json:"sender"`
+	SenderAddress    string     `json:"sender"`
+	RecipientAddress string     `json:"recipient"`
+	ConfirmedAt      *time.Time `json:"confirmedAt,omitempty"`
 }
 
 func baseFromTransfer(t *TokenTransfer) TokenTransferBase {
@@ -88,6 +116,7 @@ func baseFromTransfer(t *TokenTransfer) TokenTransferBase {
 		FtAmount:      t.FtAmount,
 		NftID:         t.NftID,
 		TokenName:     t.TokenName,
+		BlockHeight:   t.BlockHeight,
 		CreatedAt:     t.CreatedAt,
 		UpdatedAt:     t.UpdatedAt,
 	}
@@ -104,5 +133,31 @@ func (t *TokenTransfer) Deposit() TokenDeposit {
 	return TokenDeposit{
 		baseFromTransfer(t),
 		t.SenderAddress,
+		t.RecipientAddress,
+		t.ConfirmedAt,
 	}
 }
+
+// ParseTokenAmounts parses "tokenName:amount" pairs, e.g. the sweep
+// thresholds of the config, into a map of token names to amounts.
+func ParseTokenAmounts(pairs []string) (map[string]cadence.UFix64, error) {
+	amounts := make(map[string]cadence.UFix64, len(pairs))
+
+	for _, p := range pairs {
+		ss := strings.SplitN(p, ":", 2)
+		if len(ss) != 2 || ss[0] == "" {
+			return nil, fmt.Errorf("invalid token amount, expected format tokenName:amount")
+		}
+
+		tokenName, value := ss[0], ss[1]
+
+		amount, err := decimal.ParseUFix64(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", tokenName, err)
+		}
+
+		amounts[tokenName] = amount
+	}
+
+	return amounts, nil
+}
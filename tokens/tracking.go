@@ -0,0 +1,128 @@
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	log "github.com/sirupsen/logrus"
+)
+
+// Number of sent withdrawals read from the datastore at a time when tracking.
+const trackingPageSize = 100
+
+// withdrawalEvents are the webhook events of withdrawal states.
+var withdrawalEvents = map[WithdrawalState]webhooks.Event{
+	WithdrawalSent:   webhooks.EventWithdrawalSent,
+	WithdrawalSealed: webhooks.EventWithdrawalSealed,
+	WithdrawalFailed: webhooks.EventWithdrawalFailed,
+}
+
+// TrackTransfers updates sent withdrawals with the result of their
+// transaction, so their webhook events are sent without the withdrawals being
+// queried, confirms the detected deposits whose block has at least
+// DepositConfirmations blocks on top of it and delivers the pending deposit
+// notifications.
+func (s *ServiceImpl) TrackTransfers(ctx context.Context) error {
+	if err := s.trackWithdrawals(ctx); err != nil {
+		return err
+	}
+
+	latestBlock, err := s.fc.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	if latestBlock.Height >= s.cfg.DepositConfirmations {
+		if err := s.confirmDeposits(latestBlock.Height); err != nil {
+			return err
+		}
+	}
+
+	return s.deliverDepositNotifications(ctx)
+}
+
+// trackWithdrawals updates all sent withdrawals with the result of their
+// transaction.
+func (s *ServiceImpl) trackWithdrawals(ctx context.Context) error {
+	filter := WithdrawalFilter{States: []WithdrawalState{WithdrawalSent}}
+
+	for offset := 0; ; {
+		ww, err := s.store.AllWithdrawals("", datastore.ListOptions{Limit: trackingPageSize, Offset: offset}, filter)
+		if err != nil {
+			return err
+		}
+
+		for _, w := range ww {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			s.syncWithdrawal(ctx, w)
+
+			// Final withdrawals drop out of the listing, only the ones
+			// still sent are skipped on the next page
+			if w.State == WithdrawalSent {
+				offset++
+			}
+		}
+
+		if len(ww) < trackingPageSize {
+			return nil
+		}
+	}
+}
+
+// confirmDeposits confirms the detected deposits up to DepositConfirmations
+// blocks below height.
+func (s *ServiceImpl) confirmDeposits(height uint64) error {
+	confirmed := 0
+
+	for {
+		// Confirmed deposits drop out of the listing
+		tt, err := s.store.UnconfirmedDeposits(height - s.cfg.DepositConfirmations)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range tt {
+			now := time.Now()
+			t.ConfirmedAt = &now
+
+			n, err := s.newDepositNotification(webhooks.EventDepositConfirmed, t)
+			if err != nil {
+				return err
+			}
+
+			// Only stored by the first to confirm the deposit
+			if _, err := s.store.ConfirmDeposit(t, n); err != nil {
+				return err
+			}
+		}
+
+		confirmed += len(tt)
+
+		if len(tt) < datastore.DefaultLimit {
+			break
+		}
+	}
+
+	if confirmed > 0 {
+		log.WithFields(log.Fields{"confirmed": confirmed, "height": height}).Debug("Confirmed deposits")
+	}
+
+	return nil
+}
+
+// notifyWithdrawal sends a webhook notification of the state of a withdrawal,
+// if it has an event.
+func (s *ServiceImpl) notifyWithdrawal(w *Withdrawal) {
+	if s.webhooks == nil {
+		return
+	}
+
+	if event, ok := withdrawalEvents[w.State]; ok {
+		s.webhooks.Notify(event, w)
+	}
+}
@@ -0,0 +1,284 @@
+package tokens
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/accounts"
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"github.com/google/uuid"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// dummyWebhooks records the events notified and the payloads delivered to
+// a single endpoint.
+type dummyWebhooks struct {
+	notified  []webhooks.Event
+	delivered []webhooks.Event
+}
+
+func (wh *dummyWebhooks) Notify(event webhooks.Event, data interface{}) {
+	wh.notified = append(wh.notified, event)
+}
+
+func (wh *dummyWebhooks) NotifyEndpoint(endpoint string, event webhooks.Event, data interface{}) {}
+
+func (wh *dummyWebhooks) Deliver(ctx context.Context, endpoint string, payload []byte) error {
+	var p webhooks.Payload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+	wh.delivered = append(wh.delivered, p.Event)
+	return nil
+}
+
+func (wh *dummyWebhooks) Endpoints() []string {
+	return []string{"http://localhost/webhook"}
+}
+
+type dummyFlowClient struct {
+	flow_helpers.FlowClient
+	height uint64
+}
+
+func (fc *dummyFlowClient) GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.BlockHeader, error) {
+	return &flow.BlockHeader{Height: fc.height}, nil
+}
+
+func newTrackingTestService(t *testing.T, cfg *configs.Config) (*ServiceImpl, *dummyChain, *dummyWebhooks, *dummyFlowClient) {
+	t.Helper()
+
+	svc, chain, _ := newTestService(t, cfg, 2)
+
+	wh := &dummyWebhooks{}
+	fc := &dummyFlowClient{}
+	svc.webhooks = wh
+	svc.fc = fc
+
+	return svc, chain, wh, fc
+}
+
+func countEvents(ee []webhooks.Event) map[webhooks.Event]int {
+	counts := make(map[webhooks.Event]int)
+	for _, e := range ee {
+		counts[e]++
+	}
+	return counts
+}
+
+func TestTrackWithdrawals(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, wh, _ := newTrackingTestService(t, nil)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	// More sent withdrawals than fit on a page, finished ones interleaved
+	// with ones still waiting for their transaction
+	n := trackingPageSize*2 + 10
+	ww := make([]*Withdrawal, n)
+	now := time.Now()
+
+	for i := range ww {
+		ww[i] = &Withdrawal{
+			ID:               uuid.New(),
+			TransactionId:    fmt.Sprintf("tx-%d", i),
+			State:            WithdrawalSent,
+			SenderAddress:    a,
+			RecipientAddress: b,
+			TokenName:        "FlowToken",
+			FtAmount:         "1.00000000",
+			CreatedAt:        now.Add(-time.Duration(i) * time.Second),
+		}
+
+		if err := svc.store.InsertWithdrawal(ww[i]); err != nil {
+			t.Fatal(err)
+		}
+
+		switch i % 4 {
+		case 0:
+			chain.results[ww[i].TransactionId] = [2]string{flow.TransactionStatusSealed.String(), ""}
+		case 1:
+			chain.results[ww[i].TransactionId] = [2]string{flow.TransactionStatusSealed.String(), "reverted"}
+		case 2:
+			chain.results[ww[i].TransactionId] = [2]string{flow.TransactionStatusExpired.String(), ""}
+		case 3:
+			chain.results[ww[i].TransactionId] = [2]string{flow.TransactionStatusPending.String(), ""}
+		}
+	}
+
+	if err := svc.TrackTransfers(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, w := range ww {
+		stored, err := svc.store.Withdrawal(w.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[int]WithdrawalState{0: WithdrawalSealed, 1: WithdrawalFailed, 2: WithdrawalFailed, 3: WithdrawalSent}[i%4]
+		if stored.State != expected {
+			t.Fatalf("expected withdrawal %d to be %s, got %s", i, expected, stored.State)
+		}
+	}
+
+	// One event per state change, none for withdrawals still sent
+	events := countEvents(wh.notified)
+	sealed, failed := (n+3)/4, (n+2)/4+(n+1)/4
+
+	if len(wh.notified) != sealed+failed || events[webhooks.EventWithdrawalSealed] != sealed || events[webhooks.EventWithdrawalFailed] != failed {
+		t.Fatalf("expected %d sealed and %d failed events, got %v", sealed, failed, events)
+	}
+
+	// Withdrawals are notified once
+	if err := svc.TrackTransfers(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wh.notified) != sealed+failed {
+		t.Fatalf("expected no new events, got %d", len(wh.notified)-sealed-failed)
+	}
+}
+
+func TestRecordWithdrawalResultEvents(t *testing.T) {
+	svc, chain, wh, _ := newTrackingTestService(t, nil)
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	testCases := []struct {
+		name  string
+		err   error
+		state WithdrawalState
+		event webhooks.Event
+	}{
+		{name: "sealed", state: WithdrawalSealed, event: webhooks.EventWithdrawalSealed},
+		{name: "pending", err: &transactions.PendingError{Job: &jobs.Job{TransactionID: "tx"}}, state: WithdrawalSent, event: webhooks.EventWithdrawalSent},
+		{name: "reverted", err: &transactions.RevertedError{Transaction: &transactions.Transaction{TransactionId: "tx"}, Err: fmt.Errorf("reverted")}, state: WithdrawalFailed, event: webhooks.EventWithdrawalFailed},
+		{name: "not sent", err: fmt.Errorf("no connection"), state: WithdrawalRequested},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wh.notified = nil
+
+			w := &Withdrawal{ID: uuid.New(), State: WithdrawalRequested, SenderAddress: a, RecipientAddress: b, TokenName: "FlowToken", FtAmount: "1.0"}
+			if err := svc.store.InsertWithdrawal(w); err != nil {
+				t.Fatal(err)
+			}
+
+			_ = svc.recordWithdrawalResult(&transactions.Transaction{TransactionId: "tx"}, tc.err, w)
+
+			if w.State != tc.state {
+				t.Fatalf("expected state %s, got %s", tc.state, w.State)
+			}
+
+			if tc.event == "" {
+				if len(wh.notified) != 0 {
+					t.Fatalf("expected no events, got %v", wh.notified)
+				}
+				return
+			}
+
+			if len(wh.notified) != 1 || wh.notified[0] != tc.event {
+				t.Fatalf("expected a %s event, got %v", tc.event, wh.notified)
+			}
+		})
+	}
+}
+
+func TestConfirmDeposits(t *testing.T) {
+	ctx := context.Background()
+	svc, chain, wh, fc := newTrackingTestService(t, &configs.Config{DepositConfirmations: 5})
+	a, b := chain.accounts[0].Address, chain.accounts[1].Address
+
+	// Deposits are only confirmed to accounts of the service
+	if err := svc.store.(*GormStore).db.Create(&accounts.Account{Address: b}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	heights := []uint64{10, 15, 16}
+	deposits := make([]*TokenTransfer, len(heights))
+
+	for i, height := range heights {
+		detectedAt := time.Now()
+		deposits[i] = &TokenTransfer{
+			TransactionId:    fmt.Sprintf("deposit-%d", i),
+			SenderAddress:    a,
+			RecipientAddress: b,
+			FtAmount:         "1.00000000",
+			TokenName:        "FlowToken",
+			BlockHeight:      height,
+			DetectedAt:       &detectedAt,
+		}
+
+		n, err := svc.newDepositNotification(webhooks.EventDepositDetected, deposits[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := svc.store.SaveDeposit(deposits[i], n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	confirmed := func() (cc []bool) {
+		for _, d := range deposits {
+			var stored TokenTransfer
+			if err := svc.store.(*GormStore).db.First(&stored, d.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			cc = append(cc, stored.ConfirmedAt != nil)
+		}
+		return
+	}
+
+	testCases := []struct {
+		name      string
+		height    uint64
+		confirmed []bool
+		delivered map[webhooks.Event]int
+	}{
+		{
+			name:      "below confirmation depth",
+			height:    4,
+			confirmed: []bool{false, false, false},
+			delivered: map[webhooks.Event]int{webhooks.EventDepositDetected: 3},
+		},
+		{
+			name:      "at confirmation depth",
+			height:    20,
+			confirmed: []bool{true, true, false},
+			delivered: map[webhooks.Event]int{webhooks.EventDepositDetected: 3, webhooks.EventDepositConfirmed: 2},
+		},
+		{
+			name:      "above confirmation depth",
+			height:    30,
+			confirmed: []bool{true, true, true},
+			delivered: map[webhooks.Event]int{webhooks.EventDepositDetected: 3, webhooks.EventDepositConfirmed: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc.height = tc.height
+
+			if err := svc.TrackTransfers(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if cc := confirmed(); fmt.Sprint(cc) != fmt.Sprint(tc.confirmed) {
+				t.Fatalf("expected confirmed %v, got %v", tc.confirmed, cc)
+			}
+
+			// Every event is delivered once
+			if events := countEvents(wh.delivered); fmt.Sprint(events) != fmt.Sprint(tc.delivered) {
+				t.Fatalf("expected delivered events %v, got %v", tc.delivered, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/decimal"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// Codes of the withdrawal rules, see WithdrawalRuleError.
+const (
+	WithdrawalBelowMinimum = "below_minimum"
+	WithdrawalLeavesDust   = "leaves_dust"
+)
+
+// WithdrawalRuleError is returned for withdrawal requests breaking a rule
+// configured for their token. It is responded to as JSON so clients can tell
+// the rules apart by code.
+type WithdrawalRuleError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	TokenName string `json:"token"`
+	// Amount is the amount requested, of all withdrawals of a batch for dust
+	Amount string `json:"amount"`
+	// Limit is the minimum amount or the dust threshold of the token
+	Limit string `json:"limit"`
+	// Balance is the balance of the sender, set for dust only
+	Balance string `json:"balance,omitempty"`
+}
+
+func (e *WithdrawalRuleError) Error() string {
+	return e.Message
+}
+
+// withdrawalRules are the amounts of the withdrawal rules of the config by
+// token name, parsed once when the service is created.
+type withdrawalRules struct {
+	approvalThresholds map[string]cadence.UFix64
+	minimums           map[string]cadence.UFix64
+	dustThresholds     map[string]cadence.UFix64
+}
+
+func parseWithdrawalRules(cfg *configs.Config) (r withdrawalRules, err error) {
+	if r.approvalThresholds, err = ParseTokenAmounts(cfg.WithdrawalApprovalThresholds); err != nil {
+		return r, fmt.Errorf("invalid withdrawal approval threshold: %w", err)
+	}
+
+	if r.minimums, err = ParseTokenAmounts(cfg.WithdrawalMinimums); err != nil {
+		return r, fmt.Errorf("invalid withdrawal minimum: %w", err)
+	}
+
+	if r.dustThresholds, err = ParseTokenAmounts(cfg.WithdrawalDustThresholds); err != nil {
+		return r, fmt.Errorf("invalid withdrawal dust threshold: %w", err)
+	}
+
+	return r, nil
+}
+
+// ValidateWithdrawalRules checks the withdrawal approval thresholds, minimums
+// and dust thresholds of cfg, NewService panics on invalid rules.
+func ValidateWithdrawalRules(cfg *configs.Config) error {
+	_, err := parseWithdrawalRules(cfg)
+	return err
+}
+
+// checkWithdrawalRules checks requested withdrawals of a fungible token from
+// a single sender against the minimum amount and the dust threshold of the
+// token. Each withdrawal has to reach the minimum, all of them together must
+// not leave the sender with a balance below the dust threshold, other than
+// zero.
+func (s *ServiceImpl) checkWithdrawalRules(ctx context.Context, token *templates.Token, ww []*Withdrawal) error {
+	if token.Type != templates.FT || len(ww) == 0 {
+		return nil
+	}
+
+	total := new(big.Int)
+
+	for _, w := range ww {
+		amount, err := decimal.ParseUFix64(w.FtAmount)
+		if err != nil {
+			return err
+		}
+
+		if minimum, ok := tokenAmount(s.rules.minimums, token.Name); ok && amount < minimum {
+			return &WithdrawalRuleError{
+				Code:      WithdrawalBelowMinimum,
+				Message:   fmt.Sprintf("amount %s is below the minimum withdrawal of %s of %s", amount, minimum, token.Name),
+				TokenName: token.Name,
+				Amount:    amount.String(),
+				Limit:     minimum.String(),
+			}
+		}
+
+		total.Add(total, new(big.Int).SetUint64(uint64(amount)))
+	}
+
+	threshold, ok := tokenAmount(s.rules.dustThresholds, token.Name)
+	if !ok {
+		return nil
+	}
+
+	res, err := s.transactions.ExecuteScript(ctx, token.Balance, []transactions.Argument{cadence.NewAddress(flow.HexToAddress(ww[0].SenderAddress))})
+	if err != nil {
+		return err
+	}
+
+	balance, ok := res.(cadence.UFix64)
+	if !ok {
+		return fmt.Errorf("unexpected balance of %s: %s", token.Name, res)
+	}
+
+	// Overdrafts are left to fail on-chain
+	remaining := new(big.Int).Sub(new(big.Int).SetUint64(uint64(balance)), total)
+	if remaining.Sign() > 0 && remaining.Cmp(new(big.Int).SetUint64(uint64(threshold))) < 0 {
+		return &WithdrawalRuleError{
+			Code:      WithdrawalLeavesDust,
+			Message:   fmt.Sprintf("withdrawal would leave %s of %s, below the dust threshold of %s, withdraw the whole balance of %s instead", decimal.Format(remaining), token.Name, threshold, balance),
+			TokenName: token.Name,
+			Amount:    decimal.Format(total),
+			Limit:     threshold.String(),
+			Balance:   balance.String(),
+		}
+	}
+
+	return nil
+}
+
+// tokenAmount looks up the amount of a token parsed by ParseTokenAmounts,
+// token names are matched case insensitively.
+func tokenAmount(amounts map[string]cadence.UFix64, tokenName string) (cadence.UFix64, bool) {
+	for name, amount := range amounts {
+		if strings.EqualFold(name, tokenName) {
+			return amount, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,87 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/templates"
+)
+
+func TestCheckWithdrawalRules(t *testing.T) {
+	ctx := context.Background()
+	cfg := &configs.Config{
+		WithdrawalMinimums:       []string{"flowtoken:1.0"},
+		WithdrawalDustThresholds: []string{"FlowToken:0.5"},
+	}
+	svc, chain, _ := newTestService(t, cfg, 1)
+	sender := chain.accounts[0].Address
+	chain.balances[sender] = mustUFix64(t, "10.0")
+
+	flowToken := &templates.Token{Name: "FlowToken", Type: templates.FT, Balance: testBalanceCode}
+	otherToken := &templates.Token{Name: "FUSD", Type: templates.FT, Balance: testBalanceCode}
+
+	testCases := []struct {
+		name    string
+		token   *templates.Token
+		amounts []string
+		code    string
+	}{
+		{name: "below minimum", token: flowToken, amounts: []string{"0.99999999"}, code: WithdrawalBelowMinimum},
+		{name: "at minimum", token: flowToken, amounts: []string{"1.0"}},
+		{name: "above minimum", token: flowToken, amounts: []string{"2.0"}},
+		{name: "one of many below minimum", token: flowToken, amounts: []string{"2.0", "0.5", "2.0"}, code: WithdrawalBelowMinimum},
+		{name: "token without rules", token: otherToken, amounts: []string{"9.9"}},
+		{name: "leaves dust", token: flowToken, amounts: []string{"9.6"}, code: WithdrawalLeavesDust},
+		{name: "many leave dust", token: flowToken, amounts: []string{"5.0", "4.6"}, code: WithdrawalLeavesDust},
+		{name: "leaves dust threshold", token: flowToken, amounts: []string{"9.5"}},
+		{name: "whole balance", token: flowToken, amounts: []string{"10.0"}},
+		{name: "overdraft", token: flowToken, amounts: []string{"10.1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ww := make([]*Withdrawal, len(tc.amounts))
+			for i, amount := range tc.amounts {
+				ww[i] = &Withdrawal{SenderAddress: sender, TokenName: tc.token.Name, FtAmount: amount}
+			}
+
+			err := svc.checkWithdrawalRules(ctx, tc.token, ww)
+
+			if tc.code == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+
+			var ruleErr *WithdrawalRuleError
+			if !errors.As(err, &ruleErr) || ruleErr.Code != tc.code {
+				t.Fatalf("expected a %s error, got %v", tc.code, err)
+			}
+		})
+	}
+}
+
+func TestParseWithdrawalRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cfg   *configs.Config
+		valid bool
+	}{
+		{name: "no rules", cfg: &configs.Config{}, valid: true},
+		{name: "valid rules", cfg: &configs.Config{WithdrawalMinimums: []string{"FlowToken:1.0"}, WithdrawalDustThresholds: []string{"FlowToken:0.001"}}, valid: true},
+		{name: "invalid minimum", cfg: &configs.Config{WithdrawalMinimums: []string{"FlowToken:-1"}}},
+		{name: "invalid dust threshold", cfg: &configs.Config{WithdrawalDustThresholds: []string{"FlowToken"}}},
+		{name: "invalid approval threshold", cfg: &configs.Config{WithdrawalApprovalThresholds: []string{"FlowToken:abc"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := ValidateWithdrawalRules(tc.cfg); (err == nil) != tc.valid {
+				t.Fatalf("expected valid %t, got %v", tc.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tokens
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WithdrawalState is the state of a withdrawal. A withdrawal is requested,
+// sent once its transaction is on its way to the chain and finally sealed or
+// failed. Withdrawals which need an approval are pending approval until they
+// are approved, and requested, or rejected.
+type WithdrawalState string
+
+const (
+	WithdrawalPendingApproval WithdrawalState = "pending_approval"
+	WithdrawalRejected        WithdrawalState = "rejected"
+	WithdrawalRequested       WithdrawalState = "requested"
+	WithdrawalSent            WithdrawalState = "sent"
+	WithdrawalSealed          WithdrawalState = "sealed"
+	WithdrawalFailed          WithdrawalState = "failed"
+)
+
+// ParseWithdrawalState returns the withdrawal state named s.
+func ParseWithdrawalState(s string) (WithdrawalState, error) {
+	switch state := WithdrawalState(strings.ToLower(s)); state {
+	case WithdrawalPendingApproval, WithdrawalRejected, WithdrawalRequested, WithdrawalSent, WithdrawalSealed, WithdrawalFailed:
+		return state, nil
+	}
+	return "", fmt.Errorf("invalid withdrawal state: %q", s)
+}
+
+// WithdrawalFilter limits a listing of withdrawals to senders of a tenant, to
+// a set of states and to a creation time range.
+type WithdrawalFilter struct {
+	TenantID      string
+	States        []WithdrawalState
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// Withdrawal is a persisted record of an outgoing token transfer from a
+// managed account, created when the withdrawal is requested. Every outgoing
+// payment can be reconciled against its withdrawal.
+type Withdrawal struct {
+	ID               uuid.UUID       `json:"id" gorm:"column:id;primary_key;type:uuid;"`
+	TransactionId    string          `json:"transactionId" gorm:"column:transaction_id;index"`
+	State            WithdrawalState `json:"state" gorm:"column:state;index"`
+	SenderAddress    string          `json:"sender" gorm:"column:sender_address;index"`
+	RecipientAddress string          `json:"recipient" gorm:"column:recipient_address"`
+	TokenName        string          `json:"token" gorm:"column:token_name;index"`
+	FtAmount         string          `json:"amount,omitempty" gorm:"column:ft_amount"`
+	NftID            uint64          `json:"nftId,omitempty" gorm:"column:nft_id"`
+	JobID            *uuid.UUID      `json:"jobId,omitempty" gorm:"column:job_id;type:uuid"`
+	BatchID          *uuid.UUID      `json:"batchId,omitempty" gorm:"column:batch_id;type:uuid;index"`
+	Sweep            bool            `json:"sweep,omitempty" gorm:"column:sweep;not null;default:false"`
+	Error            string          `json:"error,omitempty" gorm:"column:error"`
+	// Admins who requested and approved or rejected a withdrawal pending approval
+	RequestedBy    string    `json:"requestedBy,omitempty" gorm:"column:requested_by"`
+	ResolvedBy     string    `json:"resolvedBy,omitempty" gorm:"column:resolved_by"`
+	IdempotencyKey string    `json:"-" gorm:"column:idempotency_key;index"`
+	TenantID       string    `json:"-" gorm:"column:tenant_id;index"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"column:created_at;index"`
+	UpdatedAt      time.Time `json:"updatedAt" gorm:"column:updated_at"`
+}
+
+func (Withdrawal) TableName() string {
+	return "withdrawals"
+}
+
+func (w *Withdrawal) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// request returns the withdrawal request the withdrawal was created from.
+func (w *Withdrawal) request() WithdrawalRequest {
+	return WithdrawalRequest{
+		TokenName: w.TokenName,
+		Recipient: w.RecipientAddress,
+		FtAmount:  w.FtAmount,
+		NftID:     w.NftID,
+	}
+}
+
+// WithdrawalDestination is an entry of the destination allowlist of a token.
+// Once a token has any, its withdrawals can only be sent to them.
+type WithdrawalDestination struct {
+	TokenName   string    `json:"token" gorm:"column:token_name;primaryKey;size:64"`
+	Address     string    `json:"address" gorm:"column:address;primaryKey;size:18"`
+	Description string    `json:"description,omitempty" gorm:"column:description"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (WithdrawalDestination) TableName() string {
+	return "withdrawal_destinations"
+}
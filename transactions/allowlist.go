@@ -0,0 +1,115 @@
+package transactions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+)
+
+// Kinds of allowed code.
+const (
+	AllowedScript      = "script"
+	AllowedTransaction = "transaction"
+)
+
+var codeHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// AllowedCode is an entry of the code allowlist, the SHA-256 of Cadence code
+// which clients may execute when the allowlist is enforced.
+type AllowedCode struct {
+	Hash        string    `json:"hash" gorm:"column:hash;primaryKey;size:64"`
+	Kind        string    `json:"kind" gorm:"column:kind;primaryKey;size:16"`
+	Description string    `json:"description,omitempty" gorm:"column:description"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:created_at"`
+}
+
+func (AllowedCode) TableName() string {
+	return "code_allowlist"
+}
+
+// CodeHash returns the hex encoded SHA-256 of code, as in the allowlist.
+func CodeHash(code string) string {
+	h := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(h[:])
+}
+
+// AllowCode adds an entry to the allowlist, given by hash or by code.
+func (s *ServiceImpl) AllowCode(ctx context.Context, kind, hash, code, description string) (*AllowedCode, error) {
+	if kind != AllowedScript && kind != AllowedTransaction {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid kind: %q", kind)}
+	}
+
+	switch {
+	case hash != "" && code != "":
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("only one of hash and code can be given")}
+	case code != "":
+		hash = CodeHash(code)
+	}
+
+	hash = strings.ToLower(strings.TrimPrefix(hash, "0x"))
+	if !codeHashPattern.MatchString(hash) {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid hash, expected a hex encoded SHA-256: %q", hash)}
+	}
+
+	if allowed, err := s.store.IsCodeAllowed(kind, hash); err != nil {
+		return nil, err
+	} else if allowed {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("%s %s is already in the allowlist", kind, hash),
+		}
+	}
+
+	c := &AllowedCode{Hash: hash, Kind: kind, Description: description}
+	if err := s.store.InsertAllowedCode(c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ListAllowedCode lists the allowlist entries of a kind.
+func (s *ServiceImpl) ListAllowedCode(ctx context.Context, kind string) ([]AllowedCode, error) {
+	return s.store.AllowedCode(kind)
+}
+
+// RemoveAllowedCode removes an entry from the allowlist.
+func (s *ServiceImpl) RemoveAllowedCode(ctx context.Context, kind, hash string) error {
+	return s.store.RemoveAllowedCode(kind, strings.ToLower(strings.TrimPrefix(hash, "0x")))
+}
+
+// CheckTransactionCode checks the code of a transaction from a client against
+// the transaction allowlist, if the allowlist is enforced.
+func (s *ServiceImpl) CheckTransactionCode(ctx context.Context, code string) error {
+	if !s.cfg.EnforceTransactionAllowlist {
+		return nil
+	}
+
+	return s.checkCodeAllowed(AllowedTransaction, code)
+}
+
+// checkCodeAllowed checks code against the allowlist entries of a kind.
+func (s *ServiceImpl) checkCodeAllowed(kind, code string) error {
+	hash := CodeHash(code)
+
+	allowed, err := s.store.IsCodeAllowed(kind, hash)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return &errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("%s is not in the allowlist, SHA-256: %s", kind, hash),
+		}
+	}
+
+	return nil
+}
@@ -1,7 +1,11 @@
 package transactions
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
 
 	"github.com/onflow/cadence"
 	c_json "github.com/onflow/cadence/encoding/json"
@@ -9,6 +13,9 @@ import (
 
 type Argument interface{}
 
+// ArgAsCadence converts an argument given in JSON-Cadence to a Cadence value.
+// All JSON-Cadence values are supported, invalid values are reported with the
+// path of the offending part, e.g. "[1].key: invalid UInt8 ...".
 func ArgAsCadence(a Argument) (cadence.Value, error) {
 	c, ok := a.(cadence.Value)
 	if ok {
@@ -21,6 +28,22 @@ func ArgAsCadence(a Argument) (cadence.Value, error) {
 		return cadence.Void{}, err
 	}
 
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return cadence.Void{}, err
+	}
+
+	v, err = checkJSONCadence(v, "")
+	if err != nil {
+		return cadence.Void{}, err
+	}
+
+	if j, err = json.Marshal(v); err != nil {
+		return cadence.Void{}, err
+	}
+
 	// Use cadence's own encoding library
 	c, err = c_json.Decode(nil, j)
 	if err != nil {
@@ -30,6 +53,22 @@ func ArgAsCadence(a Argument) (cadence.Value, error) {
 	return c, nil
 }
 
+// DecodeArguments converts arguments given in JSON-Cadence to Cadence values,
+// errors point at the index of the offending argument.
+func DecodeArguments(aa []Argument) ([]cadence.Value, error) {
+	var cc []cadence.Value
+
+	for i, a := range aa {
+		c, err := ArgAsCadence(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d: %w", i, err)
+		}
+		cc = append(cc, c)
+	}
+
+	return cc, nil
+}
+
 func MustDecodeArgs(aa []Argument) []cadence.Value {
 	var cc []cadence.Value
 
@@ -43,3 +82,300 @@ func MustDecodeArgs(aa []Argument) []cadence.Value {
 
 	return cc
 }
+
+// integerBits are the sizes of the fixed size integer types, 0 for the
+// arbitrary precision Int and UInt.
+var integerBits = map[string]uint{
+	"Int": 0, "Int8": 8, "Int16": 16, "Int32": 32, "Int64": 64, "Int128": 128, "Int256": 256,
+	"UInt": 0, "UInt8": 8, "UInt16": 16, "UInt32": 32, "UInt64": 64, "UInt128": 128, "UInt256": 256,
+	"Word8": 8, "Word16": 16, "Word32": 32, "Word64": 64,
+}
+
+var compositeTypes = map[string]bool{
+	"Struct": true, "Resource": true, "Event": true, "Contract": true, "Enum": true,
+}
+
+var pathDomains = map[string]bool{
+	"storage": true, "private": true, "public": true,
+}
+
+// checkJSONCadence validates a decoded JSON-Cadence value, path being its
+// location within the argument. Numbers may be given as JSON numbers and
+// fixed-point numbers without a fractional part, the returned value has them
+// in the canonical form.
+func checkJSONCadence(v interface{}, path string) (interface{}, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, pathError(path, `expected a JSON-Cadence value, e.g. {"type": "String", "value": "..."}`)
+	}
+
+	t, ok := obj["type"].(string)
+	if !ok {
+		return nil, pathError(path, `missing "type"`)
+	}
+
+	for k := range obj {
+		if k != "type" && k != "value" {
+			return nil, pathError(path, "unexpected field %q", k)
+		}
+	}
+
+	if t == "Void" {
+		return obj, nil
+	}
+
+	value, ok := obj["value"]
+	if !ok {
+		return nil, pathError(path, `missing "value" of %s`, t)
+	}
+
+	var err error
+
+	switch {
+	case t == "Optional":
+		if value != nil {
+			// The inner value is at the same position
+			value, err = checkJSONCadence(value, path)
+		}
+
+	case t == "Bool":
+		if _, ok := value.(bool); !ok {
+			err = pathError(path, "invalid Bool %s, expected true or false", describe(value))
+		}
+
+	case t == "String" || t == "Character":
+		if _, ok := value.(string); !ok {
+			err = pathError(path, "invalid %s %s, expected a string", t, describe(value))
+		}
+
+	case t == "Address":
+		s, ok := value.(string)
+		if !ok || !isAddress(s) {
+			err = pathError(path, `invalid Address %s, expected a hex string such as "0xf8d6e0586b0a20c7"`, describe(value))
+		}
+
+	case integerBits[t] > 0 || t == "Int" || t == "UInt":
+		value, err = checkInteger(t, value)
+		if err != nil {
+			err = pathError(path, "%s", err)
+		}
+
+	case t == "Fix64" || t == "UFix64":
+		value, err = checkFixedPoint(t, value)
+		if err != nil {
+			err = pathError(path, "%s", err)
+		}
+
+	case t == "Array":
+		elements, ok := value.([]interface{})
+		if !ok {
+			return nil, pathError(path, "invalid Array %s, expected a list of values", describe(value))
+		}
+		for i := range elements {
+			if elements[i], err = checkJSONCadence(elements[i], fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return nil, err
+			}
+		}
+
+	case t == "Dictionary":
+		entries, ok := value.([]interface{})
+		if !ok {
+			return nil, pathError(path, `invalid Dictionary %s, expected a list of {"key": ..., "value": ...} entries`, describe(value))
+		}
+		for i := range entries {
+			entry, ok := entries[i].(map[string]interface{})
+			if !ok || len(entry) != 2 || entry["key"] == nil || entry["value"] == nil {
+				return nil, pathError(path, `invalid Dictionary entry %d, expected {"key": ..., "value": ...}`, i)
+			}
+			p := fmt.Sprintf("%s[%d]", path, i)
+			if entry["key"], err = checkJSONCadence(entry["key"], p+".key"); err != nil {
+				return nil, err
+			}
+			if entry["value"], err = checkJSONCadence(entry["value"], p+".value"); err != nil {
+				return nil, err
+			}
+		}
+
+	case compositeTypes[t]:
+		value, err = checkComposite(t, value, path)
+
+	case t == "Path":
+		p, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, pathError(path, `invalid Path %s, expected {"domain": ..., "identifier": ...}`, describe(value))
+		}
+		if d, _ := p["domain"].(string); !pathDomains[d] {
+			return nil, pathError(path, `invalid Path domain %s, expected "storage", "private" or "public"`, describe(p["domain"]))
+		}
+		if id, _ := p["identifier"].(string); id == "" {
+			return nil, pathError(path, "invalid Path identifier %s", describe(p["identifier"]))
+		}
+
+	case t == "Type" || t == "Capability" || t == "Link":
+		// Validated by the decoder as a whole
+		if _, ok := value.(map[string]interface{}); !ok {
+			return nil, pathError(path, "invalid %s %s, expected an object", t, describe(value))
+		}
+		if _, err := c_json.Decode(nil, mustMarshal(obj)); err != nil {
+			return nil, pathError(path, "invalid %s: %s", t, err)
+		}
+
+	default:
+		return nil, pathError(path, "unsupported type %q", t)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	obj["value"] = value
+
+	return obj, nil
+}
+
+func checkInteger(t string, value interface{}) (interface{}, error) {
+	s, ok := numberString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %s, expected a number as a string", t, describe(value))
+	}
+
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %q, expected an integer", t, s)
+	}
+
+	signed := strings.HasPrefix(t, "Int")
+	bits := integerBits[t]
+
+	if !signed && i.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s %q, expected a non-negative integer", t, s)
+	}
+
+	if bits > 0 {
+		var min, max *big.Int
+		if signed {
+			max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits-1), big.NewInt(1))
+			min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), bits-1))
+		} else {
+			max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+			min = big.NewInt(0)
+		}
+
+		if i.Cmp(min) < 0 || i.Cmp(max) > 0 {
+			return nil, fmt.Errorf("invalid %s %q, out of range %s to %s", t, s, min, max)
+		}
+	}
+
+	return s, nil
+}
+
+func checkFixedPoint(t string, value interface{}) (interface{}, error) {
+	s, ok := numberString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %s, expected a decimal number as a string", t, describe(value))
+	}
+
+	canonical := s
+	if !strings.Contains(s, ".") {
+		canonical += ".0"
+	}
+
+	var err error
+	if t == "UFix64" {
+		_, err = cadence.NewUFix64(canonical)
+	} else {
+		_, err = cadence.NewFix64(canonical)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %s", t, s, err)
+	}
+
+	return canonical, nil
+}
+
+func checkComposite(t string, value interface{}, path string) (interface{}, error) {
+	c, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, pathError(path, `invalid %s %s, expected {"id": ..., "fields": [...]}`, t, describe(value))
+	}
+
+	if id, _ := c["id"].(string); id == "" {
+		return nil, pathError(path, `invalid %s: missing type "id", e.g. "A.0ae53cb6e3f42a79.Contract.Type"`, t)
+	}
+
+	fields, ok := c["fields"].([]interface{})
+	if !ok {
+		return nil, pathError(path, `invalid %s: "fields" must be a list of {"name": ..., "value": ...}`, t)
+	}
+
+	for i := range fields {
+		f, ok := fields[i].(map[string]interface{})
+		name, _ := f["name"].(string)
+		if !ok || name == "" {
+			return nil, pathError(path, `invalid %s field %d, expected {"name": ..., "value": ...}`, t, i)
+		}
+
+		var err error
+		if f["value"], err = checkJSONCadence(f["value"], joinPath(path, name)); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// pathError formats an error at path within an argument.
+func pathError(path, format string, a ...interface{}) error {
+	if path == "" {
+		return fmt.Errorf(format, a...)
+	}
+	return fmt.Errorf("%s: %s", path, fmt.Sprintf(format, a...))
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// numberString returns the string form of a number given either as a
+// string or as a JSON number.
+func numberString(value interface{}) (string, bool) {
+	switch n := value.(type) {
+	case string:
+		return n, true
+	case json.Number:
+		return n.String(), true
+	}
+	return "", false
+}
+
+func isAddress(s string) bool {
+	h := strings.TrimPrefix(s, "0x")
+	if h == "" || len(h) > 16 {
+		return false
+	}
+	for _, r := range h {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// describe formats a JSON value for error messages.
+func describe(value interface{}) string {
+	if value == nil {
+		return "null"
+	}
+	return string(mustMarshal(value))
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprintf("%v", v))
+	}
+	return b
+}
@@ -45,6 +45,33 @@ func Test_AsCadence(t *testing.T) {
 			inputJson: `[{"type":"Void"}]`,
 			expected:  []cadence.Value{cadence.NewVoid()},
 		},
+		{
+			name:      "decode array argument",
+			inputJson: `[{"type":"Array","value":[{"type":"String","value":"a"},{"type":"String","value":"b"}]}]`,
+			expected:  []cadence.Value{cadence.NewArray([]cadence.Value{cadence.String("a"), cadence.String("b")})},
+		},
+		{
+			name:      "decode dictionary argument",
+			inputJson: `[{"type":"Dictionary","value":[{"key":{"type":"String","value":"a"},"value":{"type":"UInt8","value":"1"}}]}]`,
+			expected: []cadence.Value{cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("a"), Value: cadence.NewUInt8(1)},
+			})},
+		},
+		{
+			name:      "decode optional arguments",
+			inputJson: `[{"type":"Optional","value":null},{"type":"Optional","value":{"type":"Bool","value":true}}]`,
+			expected:  []cadence.Value{cadence.NewOptional(nil), cadence.NewOptional(cadence.NewBool(true))},
+		},
+		{
+			name:      "decode path argument",
+			inputJson: `[{"type":"Path","value":{"domain":"public","identifier":"flowTokenReceiver"}}]`,
+			expected:  []cadence.Value{cadence.Path{Domain: "public", Identifier: "flowTokenReceiver"}},
+		},
+		{
+			name:      "decode fixed-point numbers without a fractional part",
+			inputJson: `[{"type":"UFix64","value":"1"},{"type":"UFix64","value":2.5}]`,
+			expected:  []cadence.Value{cadence.UFix64(100000000), cadence.UFix64(250000000)},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -72,3 +99,60 @@ func Test_AsCadence(t *testing.T) {
 		})
 	}
 }
+
+func Test_DecodeArgumentsErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		inputJson string
+		expected  string
+	}{
+		{
+			name:      "not a value",
+			inputJson: `["abc"]`,
+			expected:  `invalid argument 0: expected a JSON-Cadence value, e.g. {"type": "String", "value": "..."}`,
+		},
+		{
+			name:      "unsupported type",
+			inputJson: `[{"type":"String","value":"a"},{"type":"Foo","value":"1"}]`,
+			expected:  `invalid argument 1: unsupported type "Foo"`,
+		},
+		{
+			name:      "integer out of range",
+			inputJson: `[{"type":"Array","value":[{"type":"UInt8","value":"1"},{"type":"UInt8","value":"300"}]}]`,
+			expected:  `invalid argument 0: [1]: invalid UInt8 "300", out of range 0 to 255`,
+		},
+		{
+			name:      "negative struct field",
+			inputJson: `[{"type":"Struct","value":{"id":"A.0ae53cb6e3f42a79.C.S","fields":[{"name":"amount","value":{"type":"UFix64","value":"-1.0"}}]}}]`,
+			expected:  `invalid argument 0: amount: invalid UFix64 "-1.0": invalid negative integer part`,
+		},
+		{
+			name:      "invalid dictionary key",
+			inputJson: `[{"type":"Dictionary","value":[{"key":{"type":"Address","value":"xyz"},"value":{"type":"Bool","value":true}}]}]`,
+			expected:  `invalid argument 0: [0].key: invalid Address "xyz", expected a hex string such as "0xf8d6e0586b0a20c7"`,
+		},
+		{
+			name:      "invalid path domain",
+			inputJson: `[{"type":"Path","value":{"domain":"home","identifier":"vault"}}]`,
+			expected:  `invalid argument 0: invalid Path domain "home", expected "storage", "private" or "public"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var arguments []Argument
+			if err := json.Unmarshal([]byte(tc.inputJson), &arguments); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := DecodeArguments(arguments)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if err.Error() != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package transactions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser2"
+)
+
+// numberSupertypes are the abstract number types and the prefixes of the
+// concrete types they accept.
+var numberSupertypes = map[string][]string{
+	"Number":           {"Int", "UInt", "Word", "Fix64", "UFix64"},
+	"SignedNumber":     {"Int", "Fix64"},
+	"Integer":          {"Int", "UInt", "Word"},
+	"SignedInteger":    {"Int"},
+	"FixedPoint":       {"Fix64", "UFix64"},
+	"SignedFixedPoint": {"Fix64"},
+}
+
+// pathDomainsOf are the path types and the domains of the paths they accept.
+var pathDomainsOf = map[string][]string{
+	"Path":           {"storage", "private", "public"},
+	"StoragePath":    {"storage"},
+	"CapabilityPath": {"private", "public"},
+	"PrivatePath":    {"private"},
+	"PublicPath":     {"public"},
+}
+
+// checkArgumentTypes checks the arguments against the parameters declared by
+// the transaction code, in count and type. Code which does not parse or does
+// not declare exactly one transaction is left for the chain to reject.
+func checkArgumentTypes(code string, args []cadence.Value) []string {
+	program, err := parser2.ParseProgram(code, nil)
+	if err != nil {
+		return nil
+	}
+
+	declarations := program.TransactionDeclarations()
+	if len(declarations) != 1 {
+		return nil
+	}
+
+	return argumentTypeErrors(declarations[0], args)
+}
+
+// argumentTypeErrors checks the arguments against the parameters of a
+// transaction declaration.
+func argumentTypeErrors(tx *ast.TransactionDeclaration, args []cadence.Value) []string {
+	var params []*ast.Parameter
+	if tx.ParameterList != nil {
+		params = tx.ParameterList.Parameters
+	}
+
+	if len(params) != len(args) {
+		return []string{fmt.Sprintf("transaction expects %d arguments, got %d", len(params), len(args))}
+	}
+
+	var errs []string
+	for i, p := range params {
+		if p.TypeAnnotation == nil {
+			continue
+		}
+
+		if err := matchType(p.TypeAnnotation.Type, args[i], ""); err != nil {
+			errs = append(errs, fmt.Sprintf("argument %d (%s: %s): %s", i, p.Identifier.Identifier, p.TypeAnnotation.Type, err))
+		}
+	}
+
+	return errs
+}
+
+// matchType checks a value against a declared type, path being the location
+// of the value within the argument. Types which can not be checked without
+// type checking the program, e.g. references and restricted types, match any
+// value.
+func matchType(t ast.Type, v cadence.Value, path string) error {
+	switch t := t.(type) {
+	case *ast.OptionalType:
+		o, ok := v.(cadence.Optional)
+		if !ok {
+			// A value is a subtype of its optional type
+			return matchType(t.Type, v, path)
+		}
+		if o.Value == nil {
+			return nil
+		}
+		return matchType(t.Type, o.Value, path)
+
+	case *ast.VariableSizedType:
+		a, ok := v.(cadence.Array)
+		if !ok {
+			return mismatch(t, v, path)
+		}
+		for i, e := range a.Values {
+			if err := matchType(t.Type, e, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.ConstantSizedType:
+		a, ok := v.(cadence.Array)
+		if !ok {
+			return mismatch(t, v, path)
+		}
+		if t.Size != nil && t.Size.Value != nil && t.Size.Value.IsInt64() && int64(len(a.Values)) != t.Size.Value.Int64() {
+			return pathError(path, "expected %s elements, got %d", t.Size.Value, len(a.Values))
+		}
+		for i, e := range a.Values {
+			if err := matchType(t.Type, e, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.DictionaryType:
+		d, ok := v.(cadence.Dictionary)
+		if !ok {
+			return mismatch(t, v, path)
+		}
+		for i, p := range d.Pairs {
+			if err := matchType(t.KeyType, p.Key, fmt.Sprintf("%s[%d].key", path, i)); err != nil {
+				return err
+			}
+			if err := matchType(t.ValueType, p.Value, fmt.Sprintf("%s[%d].value", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *ast.InstantiationType:
+		if n, ok := t.Type.(*ast.NominalType); ok && n.Identifier.Identifier == "Capability" {
+			if _, ok := v.(cadence.Capability); !ok {
+				return mismatch(t, v, path)
+			}
+		}
+		return nil
+
+	case *ast.NominalType:
+		if matchNominalType(t, v) {
+			return nil
+		}
+		return mismatch(t, v, path)
+	}
+
+	return nil
+}
+
+func matchNominalType(t *ast.NominalType, v cadence.Value) bool {
+	name := t.String()
+
+	switch name {
+	case "AnyStruct", "AnyResource":
+		return true
+	case "Type":
+		_, ok := v.(cadence.TypeValue)
+		return ok
+	case "Capability":
+		_, ok := v.(cadence.Capability)
+		return ok
+	}
+
+	if domains, ok := pathDomainsOf[name]; ok {
+		p, ok := v.(cadence.Path)
+		return ok && contains(domains, p.Domain)
+	}
+
+	valueType := typeID(v)
+
+	if prefixes, ok := numberSupertypes[name]; ok {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(valueType, prefix) && isNumberType(valueType) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch v.(type) {
+	case cadence.Struct, cadence.Resource, cadence.Event, cadence.Contract, cadence.Enum:
+		// Composite types are given by qualified identifier, e.g.
+		// "FungibleToken.Vault" for "A.f233dcee88fe0abe.FungibleToken.Vault"
+		return valueType == name || strings.HasSuffix(valueType, "."+name)
+	}
+
+	return valueType == name
+}
+
+// typeID returns the ID of the type of a value, e.g. "UFix64".
+func typeID(v cadence.Value) string {
+	if v == nil {
+		return "nil"
+	}
+
+	switch v := v.(type) {
+	case cadence.Optional:
+		return "Optional"
+	case cadence.Array:
+		return "Array"
+	case cadence.Dictionary:
+		return "Dictionary"
+	case cadence.Path:
+		return "Path"
+	case cadence.Struct:
+		if v.StructType != nil {
+			return v.StructType.ID()
+		}
+	case cadence.Resource:
+		if v.ResourceType != nil {
+			return v.ResourceType.ID()
+		}
+	case cadence.Event:
+		if v.EventType != nil {
+			return v.EventType.ID()
+		}
+	case cadence.Contract:
+		if v.ContractType != nil {
+			return v.ContractType.ID()
+		}
+	case cadence.Enum:
+		if v.EnumType != nil {
+			return v.EnumType.ID()
+		}
+	}
+
+	if t := v.Type(); t != nil {
+		return t.ID()
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+func isNumberType(id string) bool {
+	_, isInteger := integerBits[id]
+	return isInteger || id == "Fix64" || id == "UFix64"
+}
+
+func mismatch(t ast.Type, v cadence.Value, path string) error {
+	return pathError(path, "expected %s, got %s", t, typeID(v))
+}
+
+func contains(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
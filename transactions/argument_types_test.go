@@ -0,0 +1,75 @@
+package transactions
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_CheckArgumentTypes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		code      string
+		inputJson string
+		expected  []string
+	}{
+		{
+			name:      "matching simple types",
+			code:      `transaction(amount: UFix64, recipient: Address, memo: String?) {}`,
+			inputJson: `[{"type":"UFix64","value":"1.0"},{"type":"Address","value":"0x01"},{"type":"Optional","value":null}]`,
+		},
+		{
+			name:      "matching composite types",
+			code:      `import FungibleToken from 0x01 transaction(ids: [UInt64], amounts: {String: UFix64}, target: PublicPath, entry: FungibleToken.Entry, n: Integer) {}`,
+			inputJson: `[{"type":"Array","value":[{"type":"UInt64","value":"1"}]},{"type":"Dictionary","value":[{"key":{"type":"String","value":"a"},"value":{"type":"UFix64","value":"1.0"}}]},{"type":"Path","value":{"domain":"public","identifier":"receiver"}},{"type":"Struct","value":{"id":"A.0000000000000001.FungibleToken.Entry","fields":[]}},{"type":"Int8","value":"1"}]`,
+		},
+		{
+			name:      "argument count mismatch",
+			code:      `transaction(amount: UFix64) {}`,
+			inputJson: `[]`,
+			expected:  []string{"transaction expects 1 arguments, got 0"},
+		},
+		{
+			name:      "simple type mismatch",
+			code:      `transaction(amount: UFix64, recipient: Address) {}`,
+			inputJson: `[{"type":"UFix64","value":"1.0"},{"type":"String","value":"0x01"}]`,
+			expected:  []string{"argument 1 (recipient: Address): expected Address, got String"},
+		},
+		{
+			name:      "nested type mismatch",
+			code:      `transaction(amounts: {String: [UFix64]}) {}`,
+			inputJson: `[{"type":"Dictionary","value":[{"key":{"type":"String","value":"a"},"value":{"type":"Array","value":[{"type":"UFix64","value":"1.0"},{"type":"Int","value":"2"}]}}]}]`,
+			expected:  []string{"argument 0 (amounts: {String: [UFix64]}): [0].value[1]: expected UFix64, got Int"},
+		},
+		{
+			name:      "path domain mismatch",
+			code:      `transaction(path: StoragePath) {}`,
+			inputJson: `[{"type":"Path","value":{"domain":"public","identifier":"receiver"}}]`,
+			expected:  []string{"argument 0 (path: StoragePath): expected StoragePath, got Path"},
+		},
+		{
+			name:      "code not parsing is left to the chain",
+			code:      `transaction(`,
+			inputJson: `[{"type":"String","value":"a"}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var arguments []Argument
+			if err := json.Unmarshal([]byte(tc.inputJson), &arguments); err != nil {
+				t.Fatal(err)
+			}
+
+			args, err := DecodeArguments(arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			errs := checkArgumentTypes(tc.code, args)
+			if !reflect.DeepEqual(errs, tc.expected) {
+				t.Fatalf("expected %q, got %q", tc.expected, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// BatchItem is the outcome of a single transaction of a batch, either the job
+// sending it or the error creating it.
+type BatchItem struct {
+	Index         int        `json:"index"`
+	JobID         *uuid.UUID `json:"jobId,omitempty"`
+	TransactionID string     `json:"transactionId,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// CreateBatch creates a transaction and a job sending it for each request.
+// The requests are independent of each other, a failing request does not
+// stop the rest of the batch.
+func (s *ServiceImpl) CreateBatch(ctx context.Context, proposerAddress string, requests []JSONRequest) ([]BatchItem, error) {
+	if len(requests) == 0 {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("empty batch"),
+		}
+	}
+
+	if uint(len(requests)) > s.cfg.MaxTransactionBatchSize {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("batch of %d transactions exceeds the maximum of %d", len(requests), s.cfg.MaxTransactionBatchSize),
+		}
+	}
+
+	proposerAddress, err := flow_helpers.ValidateAddress(proposerAddress, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]BatchItem, len(requests))
+	failed := 0
+
+	for i, r := range requests {
+		items[i].Index = i
+
+		err := s.CheckTransactionCode(ctx, r.Code)
+		if err != nil {
+			items[i].Error = err.Error()
+			failed++
+			continue
+		}
+
+		job, tx, err := s.Create(ctx, false, proposerAddress, r.Code, r.Arguments, General, r.Options()...)
+		if err != nil {
+			items[i].Error = err.Error()
+			failed++
+			continue
+		}
+
+		items[i].JobID = &job.ID
+		items[i].TransactionID = tx.TransactionId
+	}
+
+	log.
+		WithFields(log.Fields{"address": proposerAddress, "count": len(requests), "failed": failed}).
+		Info("Created transaction batch")
+
+	return items, nil
+}
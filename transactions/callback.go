@@ -0,0 +1,74 @@
+package transactions
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+)
+
+// checkCallbackURL checks a callback URL given in a request. The service
+// posts transaction results signed with the webhook secret to it, so it is
+// limited to the configured callback hosts, or the hosts of the webhook
+// endpoints, and may not resolve to private or loopback addresses.
+func (s *ServiceImpl) checkCallbackURL(callbackURL string) error {
+	u, err := url.ParseRequestURI(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("not a valid callback URL: %q", callbackURL),
+		}
+	}
+
+	host := u.Hostname()
+
+	if !s.callbackHostAllowed(host) {
+		return &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("callback URLs to %s are not allowed", host),
+		}
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if ips, err = net.LookupIP(host); err != nil {
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("could not resolve the host of the callback URL: %w", err),
+			}
+		}
+	}
+
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+			return &errors.RequestError{
+				StatusCode: http.StatusBadRequest,
+				Err:        fmt.Errorf("callback URLs to private or loopback addresses are not allowed: %s", host),
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) callbackHostAllowed(host string) bool {
+	hosts := s.cfg.WebhookCallbackHosts
+	if len(hosts) == 0 {
+		for _, e := range s.cfg.WebhookEndpoints {
+			if u, err := url.Parse(e); err == nil {
+				hosts = append(hosts, u.Hostname())
+			}
+		}
+	}
+
+	for _, h := range hosts {
+		if strings.EqualFold(strings.TrimSpace(h), host) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+package transactions
+
+import (
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+)
+
+func Test_CheckCallbackURL(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cfg   *configs.Config
+		url   string
+		valid bool
+	}{
+		{
+			name:  "allowed host",
+			cfg:   &configs.Config{WebhookCallbackHosts: []string{"93.184.216.34"}},
+			url:   "https://93.184.216.34/callback",
+			valid: true,
+		},
+		{
+			name:  "host of a webhook endpoint",
+			cfg:   &configs.Config{WebhookEndpoints: []string{"https://93.184.216.34/webhook"}},
+			url:   "http://93.184.216.34:8080/callback",
+			valid: true,
+		},
+		{
+			name: "other host",
+			cfg:  &configs.Config{WebhookCallbackHosts: []string{"93.184.216.34"}},
+			url:  "https://93.184.216.35/callback",
+		},
+		{
+			name: "no allowed hosts",
+			cfg:  &configs.Config{},
+			url:  "https://93.184.216.34/callback",
+		},
+		{
+			name: "loopback address",
+			cfg:  &configs.Config{WebhookCallbackHosts: []string{"127.0.0.1"}},
+			url:  "http://127.0.0.1/callback",
+		},
+		{
+			name: "host resolving to a loopback address",
+			cfg:  &configs.Config{WebhookCallbackHosts: []string{"localhost"}},
+			url:  "http://localhost/callback",
+		},
+		{
+			name: "private address",
+			cfg:  &configs.Config{WebhookEndpoints: []string{"http://10.0.0.1/webhook"}},
+			url:  "http://10.0.0.1/callback",
+		},
+		{
+			name: "link-local address",
+			cfg:  &configs.Config{WebhookCallbackHosts: []string{"169.254.169.254"}},
+			url:  "http://169.254.169.254/latest/meta-data",
+		},
+		{
+			name: "not http",
+			cfg:  &configs.Config{WebhookCallbackHosts: []string{"93.184.216.34"}},
+			url:  "ftp://93.184.216.34/callback",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &ServiceImpl{cfg: tc.cfg}
+
+			if err := svc.checkCallbackURL(tc.url); (err == nil) != tc.valid {
+				t.Fatalf("expected valid %t, got %v", tc.valid, err)
+			}
+		})
+	}
+}
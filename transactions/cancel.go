@@ -0,0 +1,73 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	log "github.com/sirupsen/logrus"
+)
+
+// StatusCancelled is the status of transactions cancelled before being sent.
+const StatusCancelled = "CANCELLED"
+
+// Cancel cancels a transaction whose job is still queued, i.e. not accepted
+// by a worker yet and so never sent to the chain. Address limits the lookup
+// to the raw transactions of an account.
+func (s *ServiceImpl) Cancel(ctx context.Context, address, transactionId string) (*Transaction, error) {
+	if err := flow_helpers.ValidateTransactionId(transactionId); err != nil {
+		return nil, err
+	}
+
+	var (
+		transaction Transaction
+		err         error
+	)
+
+	if address != "" {
+		address, err = flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		transaction, err = s.store.TransactionForAccount(General, address, transactionId)
+	} else {
+		transaction, err = s.store.Transaction(transactionId)
+	}
+
+	// Transactions of other tenants are not visible
+	tenantID := tenants.FromContext(ctx)
+	if (err != nil && err.Error() == "record not found") || (tenantID != "" && tenantID != transaction.TenantID) {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusNotFound,
+			Err:        fmt.Errorf("transaction not found"),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Workers can not accept the job anymore once it is cancelled
+	cancelled, err := s.wp.CancelJob(TransactionJobType, transaction.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cancelled {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("transaction is not queued, it may have been sent already"),
+		}
+	}
+
+	transaction.Status = StatusCancelled
+	if _, err := s.store.UpdateTransactionResult(&transaction); err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{"transactionId": transaction.TransactionId}).Info("Cancelled transaction")
+
+	return &transaction, nil
+}
@@ -0,0 +1,121 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser2"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// DryRunResult is the outcome of a transaction dry run.
+type DryRunResult struct {
+	Transaction flow.Transaction
+	// Errors the transaction is expected to fail with
+	Errors []string
+}
+
+// Transaction dry run HTTP response
+type DryRunJSONResponse struct {
+	Transaction SignedTransactionJSONResponse `json:"transaction"`
+	Valid       bool                          `json:"valid"`
+	Errors      []string                      `json:"errors"`
+}
+
+func (r DryRunResult) ToJSONResponse() (DryRunJSONResponse, error) {
+	signed := SignedTransaction{Transaction: r.Transaction}
+	tx, err := signed.ToJSONResponse()
+	if err != nil {
+		return DryRunJSONResponse{}, err
+	}
+
+	errs := r.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+
+	return DryRunJSONResponse{Transaction: tx, Valid: len(errs) == 0, Errors: errs}, nil
+}
+
+// DryRun builds a transaction like Create would, without signing, storing or
+// sending it, and checks it against the latest state of its signers. The
+// Access API does not execute transactions without sending them, so the
+// checks are limited to what can be verified before execution.
+func (s *ServiceImpl) DryRun(ctx context.Context, proposerAddress string, code string, args []Argument, opts ...TransactionOption) (*DryRunResult, error) {
+	o, err := s.transactionOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	signers, err := s.getSigners(ctx, proposerAddress, o.roles)
+	if err != nil {
+		return nil, err
+	}
+
+	flowTx, err := s.unsignedFlowTransaction(ctx, signers, code, args, o.gasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decoded by unsignedFlowTransaction already
+	cc, err := DecodeArguments(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DryRunResult{
+		Transaction: *flowTx,
+		Errors:      checkTransactionCode(code, cc, len(flowTx.Authorizers)),
+	}
+
+	if signers.proposer.Key.Revoked {
+		result.Errors = append(result.Errors, fmt.Sprintf("proposal key %d of %s is revoked", signers.proposer.Key.Index, signers.proposer.Address))
+	}
+
+	return result, nil
+}
+
+// checkTransactionCode parses the transaction code and checks its parameters
+// against the arguments and the number of authorizers.
+func checkTransactionCode(code string, args []cadence.Value, authorizerCount int) []string {
+	program, err := parser2.ParseProgram(code, nil)
+	if err != nil {
+		var parseErr parser2.Error
+		if !errors.As(err, &parseErr) {
+			return []string{err.Error()}
+		}
+
+		errs := make([]string, len(parseErr.Errors))
+		for i, e := range parseErr.Errors {
+			if p, ok := e.(ast.HasPosition); ok {
+				pos := p.StartPosition()
+				errs[i] = fmt.Sprintf("%d:%d: %s", pos.Line, pos.Column, e)
+			} else {
+				errs[i] = e.Error()
+			}
+		}
+		return errs
+	}
+
+	declarations := program.TransactionDeclarations()
+	if len(declarations) != 1 {
+		return []string{fmt.Sprintf("expected exactly one transaction declaration, got %d", len(declarations))}
+	}
+
+	tx := declarations[0]
+
+	errs := argumentTypeErrors(tx, args)
+
+	signers := 0
+	if tx.Prepare != nil && tx.Prepare.FunctionDeclaration.ParameterList != nil {
+		signers = len(tx.Prepare.FunctionDeclaration.ParameterList.Parameters)
+	}
+	if signers != authorizerCount {
+		errs = append(errs, fmt.Sprintf("transaction expects %d authorizers, got %d", signers, authorizerCount))
+	}
+
+	return errs
+}
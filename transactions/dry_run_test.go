@@ -0,0 +1,68 @@
+package transactions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+)
+
+func Test_CheckTransactionCode(t *testing.T) {
+	testCases := []struct {
+		name            string
+		code            string
+		args            []cadence.Value
+		authorizerCount int
+		expected        []string
+	}{
+		{
+			name:            "valid transaction",
+			code:            `transaction(amount: UFix64) { prepare(signer: AuthAccount) {} }`,
+			args:            []cadence.Value{cadence.UFix64(100000000)},
+			authorizerCount: 1,
+		},
+		{
+			name: "valid transaction without prepare",
+			code: `transaction { execute {} }`,
+		},
+		{
+			name:            "argument and authorizer count mismatch",
+			code:            `transaction(amount: UFix64) { prepare(a: AuthAccount, b: AuthAccount) {} }`,
+			authorizerCount: 1,
+			expected:        []string{"transaction expects 1 arguments, got 0", "transaction expects 2 authorizers, got 1"},
+		},
+		{
+			name:            "argument type mismatch",
+			code:            `transaction(amount: UFix64, recipient: Address) { prepare(signer: AuthAccount) {} }`,
+			args:            []cadence.Value{cadence.String("1.0"), cadence.NewAddress([8]byte{1})},
+			authorizerCount: 1,
+			expected:        []string{"argument 0 (amount: UFix64): expected UFix64, got String"},
+		},
+		{
+			name:     "no transaction",
+			code:     `pub fun main() {}`,
+			expected: []string{"expected exactly one transaction declaration, got 0"},
+		},
+		{
+			name:     "syntax error",
+			code:     `transaction {`,
+			expected: []string{"1:"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := checkTransactionCode(tc.code, tc.args, tc.authorizerCount)
+
+			if len(errs) != len(tc.expected) {
+				t.Fatalf("expected %d errors, got %v", len(tc.expected), errs)
+			}
+
+			for i, e := range tc.expected {
+				if !strings.HasPrefix(errs[i], e) {
+					t.Errorf("expected error %q to start with %q", errs[i], e)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package transactions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	c_json "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"gorm.io/datatypes"
+)
+
+// Event is the database model for the events emitted by sealed
+// transactions, linked to the transaction by TransactionId.
+type Event struct {
+	TransactionId    string         `gorm:"column:transaction_id;primaryKey"`
+	EventIndex       int            `gorm:"column:event_index;primaryKey;autoIncrement:false"`
+	TransactionIndex int            `gorm:"column:transaction_index"`
+	Type             string         `gorm:"column:type;index"`
+	BlockID          string         `gorm:"column:block_id"`
+	BlockHeight      uint64         `gorm:"column:block_height;index"`
+	Payload          datatypes.JSON `gorm:"column:payload"` // JSON-Cadence
+	CreatedAt        time.Time      `gorm:"column:created_at"`
+}
+
+func (Event) TableName() string {
+	return "events"
+}
+
+// Event JSON HTTP response
+type EventJSONResponse struct {
+	TransactionId    string          `json:"transactionId"`
+	Type             string          `json:"type"`
+	TransactionIndex int             `json:"transactionIndex"`
+	EventIndex       int             `json:"eventIndex"`
+	BlockID          string          `json:"blockId,omitempty"`
+	BlockHeight      uint64          `json:"blockHeight,omitempty"`
+	Payload          json.RawMessage `json:"payload"`
+}
+
+func (e Event) ToJSONResponse() EventJSONResponse {
+	return EventJSONResponse{
+		TransactionId:    e.TransactionId,
+		Type:             e.Type,
+		TransactionIndex: e.TransactionIndex,
+		EventIndex:       e.EventIndex,
+		BlockID:          e.BlockID,
+		BlockHeight:      e.BlockHeight,
+		Payload:          json.RawMessage(e.Payload),
+	}
+}
+
+// DecodedEventJSONResponse is an event emitted by a transaction with its
+// fields decoded to plain JSON values, see plainValue.
+type DecodedEventJSONResponse struct {
+	Type             string          `json:"type"`
+	TransactionIndex int             `json:"transactionIndex"`
+	EventIndex       int             `json:"eventIndex"`
+	Fields           json.RawMessage `json:"fields"`
+}
+
+// decodedEvents decodes the fields of events to plain JSON values.
+func decodedEvents(events []flow.Event) []DecodedEventJSONResponse {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ee := make([]DecodedEventJSONResponse, len(events))
+	for i, e := range events {
+		fields, err := EncodeScriptResult(e.Value, ScriptResultPlain)
+		if err != nil {
+			fields = nil
+		}
+
+		ee[i] = DecodedEventJSONResponse{
+			Type:             e.Type,
+			TransactionIndex: e.TransactionIndex,
+			EventIndex:       e.EventIndex,
+			Fields:           fields,
+		}
+	}
+
+	return ee
+}
+
+// storedEventRecords returns the stored events of the transaction as Event
+// records.
+func (t Transaction) storedEventRecords() ([]Event, error) {
+	if len(t.StoredEvents) == 0 {
+		return nil, nil
+	}
+
+	var events []storedEvent
+	if err := json.Unmarshal(t.StoredEvents, &events); err != nil {
+		return nil, err
+	}
+
+	ee := make([]Event, len(events))
+	for i, e := range events {
+		ee[i] = Event{
+			TransactionId:    t.TransactionId,
+			EventIndex:       e.EventIndex,
+			TransactionIndex: e.TransactionIndex,
+			Type:             e.Type,
+			BlockID:          t.BlockID,
+			BlockHeight:      t.BlockHeight,
+			Payload:          datatypes.JSON(e.Payload),
+		}
+	}
+
+	return ee, nil
+}
+
+// eventRecords returns the events of the transaction as Event records.
+func (t Transaction) eventRecords() ([]Event, error) {
+	ee := make([]Event, 0, len(t.Events))
+	for _, e := range t.Events {
+		payload, err := c_json.Encode(e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("error while encoding event %s: %w", e.Type, err)
+		}
+
+		ee = append(ee, Event{
+			TransactionId:    t.TransactionId,
+			EventIndex:       e.EventIndex,
+			TransactionIndex: e.TransactionIndex,
+			Type:             e.Type,
+			BlockID:          t.BlockID,
+			BlockHeight:      t.BlockHeight,
+			Payload:          datatypes.JSON(bytes.TrimSpace(payload)),
+		})
+	}
+
+	return ee, nil
+}
+
+// Events returns the events emitted by a transaction, optionally only those
+// of eventType. Events of sealed transactions are read from the database,
+// others from the chain. Address limits the lookup to the raw transactions
+// of an account.
+func (s *ServiceImpl) Events(ctx context.Context, address, transactionId, eventType string) ([]Event, error) {
+	var (
+		transaction *Transaction
+		err         error
+	)
+
+	if address != "" {
+		transaction, err = s.DetailsForAccount(ctx, General, address, transactionId)
+	} else {
+		transaction, err = s.Details(ctx, transactionId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if isFinalStatus(transaction.Status) {
+		return s.store.TransactionEvents(transaction.TransactionId, eventType)
+	}
+
+	ee, err := transaction.eventRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	return filterEvents(ee, eventType), nil
+}
+
+func filterEvents(ee []Event, eventType string) []Event {
+	if eventType == "" {
+		return ee
+	}
+
+	filtered := make([]Event, 0, len(ee))
+	for _, e := range ee {
+		if e.Type == eventType {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
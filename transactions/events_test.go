@@ -0,0 +1,102 @@
+package transactions
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func Test_EventRecords(t *testing.T) {
+	eventType := &cadence.EventType{
+		QualifiedIdentifier: "A.0ae53cb6e3f42a79.FlowToken.TokensDeposited",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+		},
+	}
+
+	amount, err := cadence.NewUFix64("1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := cadence.NewEvent([]cadence.Value{amount}).WithType(eventType)
+
+	result := &flow.TransactionResult{
+		Status: flow.TransactionStatusSealed,
+		Events: []flow.Event{
+			{Type: eventType.QualifiedIdentifier, EventIndex: 0, Value: value},
+			{Type: "A.0ae53cb6e3f42a79.FlowToken.TokensWithdrawn", EventIndex: 1, Value: value},
+		},
+		BlockID: flow.HexToID("01"),
+	}
+
+	tx := Transaction{TransactionId: flow.HexToID("02").Hex()}
+	if err := tx.setResult(result, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	fromChain, err := tx.eventRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := tx.storedEventRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fromChain) != 2 || len(stored) != 2 {
+		t.Fatalf("expected 2 events, got %d and %d", len(fromChain), len(stored))
+	}
+
+	for i := range stored {
+		a, b := fromChain[i].ToJSONResponse(), stored[i].ToJSONResponse()
+		if a.TransactionId != tx.TransactionId || a.BlockID != tx.BlockID || a.BlockHeight != 10 {
+			t.Fatalf("unexpected event: %+v", a)
+		}
+		if a.Type != b.Type || a.EventIndex != b.EventIndex || string(a.Payload) != string(b.Payload) {
+			t.Fatalf("events differ: %+v, %+v", a, b)
+		}
+	}
+
+	filtered := filterEvents(stored, "A.0ae53cb6e3f42a79.FlowToken.TokensWithdrawn")
+	if len(filtered) != 1 || filtered[0].EventIndex != 1 {
+		t.Fatalf("unexpected filtered events: %+v", filtered)
+	}
+}
+
+func Test_DecodedEvents(t *testing.T) {
+	eventType := &cadence.EventType{
+		QualifiedIdentifier: "A.0ae53cb6e3f42a79.FlowToken.TokensDeposited",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "to", Type: cadence.OptionalType{Type: cadence.AddressType{}}},
+		},
+	}
+
+	amount, err := cadence.NewUFix64("1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := cadence.NewOptional(cadence.NewAddress(flow.HexToAddress("0ae53cb6e3f42a79")))
+	value := cadence.NewEvent([]cadence.Value{amount, to}).WithType(eventType)
+
+	events := decodedEvents([]flow.Event{{Type: eventType.QualifiedIdentifier, EventIndex: 2, Value: value}})
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	if events[0].Type != eventType.QualifiedIdentifier || events[0].EventIndex != 2 {
+		t.Fatalf("unexpected event: %+v", events[0])
+	}
+
+	if expected := `{"amount":"1.50000000","to":"0x0ae53cb6e3f42a79"}`; string(events[0].Fields) != expected {
+		t.Fatalf("expected fields %s, got %s", expected, events[0].Fields)
+	}
+
+	if decodedEvents(nil) != nil {
+		t.Fatal("expected no events")
+	}
+}
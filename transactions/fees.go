@@ -0,0 +1,156 @@
+package transactions
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// flowFeesAddresses are the addresses of the FlowFees contract on the
+// emulator, testnet and mainnet.
+var flowFeesAddresses = map[string]bool{
+	"e5a8b7f23e8b548f": true,
+	"912d5440f7e3769e": true,
+	"f919ee77447b7497": true,
+}
+
+// FeeReportFilter limits a fee report to the sealed transactions of an
+// account and to a creation time range.
+type FeeReportFilter struct {
+	Address       string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// FeeReportRow sums up the fees of the sealed transactions of an account
+// created on a day (UTC). Fees and execution effort are UFix64 values.
+type FeeReportRow struct {
+	Address         string `json:"address"`
+	Day             string `json:"day"`
+	Transactions    int    `json:"transactions"`
+	Fees            string `json:"fees"`
+	ExecutionEffort string `json:"executionEffort"`
+}
+
+// flowFeesEvent returns the name of a FlowFees contract event, e.g.
+// "FeesDeducted", ok being false for events of other contracts.
+func flowFeesEvent(eventType string) (name string, ok bool) {
+	// A.<address>.FlowFees.<name>
+	parts := strings.Split(eventType, ".")
+	if len(parts) != 4 || parts[0] != "A" || parts[2] != "FlowFees" || !flowFeesAddresses[parts[1]] {
+		return "", false
+	}
+	return parts[3], true
+}
+
+// transactionFees returns the fees paid for a transaction and its execution
+// effort, as raw UFix64 values, from its FlowFees events. Networks which do
+// not emit FeesDeducted only report the fees, as FlowFees deposits.
+func transactionFees(events []flow.Event) (fees, executionEffort uint64) {
+	var deposited uint64
+	for _, e := range events {
+		name, ok := flowFeesEvent(e.Type)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "FeesDeducted":
+			return ufix64Field(e.Value, "amount"), ufix64Field(e.Value, "executionEffort")
+		case "TokensDeposited":
+			deposited += ufix64Field(e.Value, "amount")
+		}
+	}
+
+	return deposited, 0
+}
+
+func ufix64Field(e cadence.Event, name string) uint64 {
+	if e.EventType == nil {
+		return 0
+	}
+
+	for i, f := range e.EventType.Fields {
+		if f.Identifier == name && i < len(e.Fields) {
+			if v, ok := e.Fields[i].(cadence.UFix64); ok {
+				return uint64(v)
+			}
+		}
+	}
+
+	return 0
+}
+
+func formatUFix64(v uint64) string {
+	return cadence.UFix64(v).String()
+}
+
+type feeReportKey struct {
+	address string
+	day     string
+}
+
+type feeTotals struct {
+	transactions    int
+	fees            uint64
+	executionEffort uint64
+}
+
+// feeReport sums up transaction fees per account and day.
+type feeReport map[feeReportKey]*feeTotals
+
+func (r feeReport) add(t Transaction) {
+	k := feeReportKey{t.ProposerAddress, t.CreatedAt.UTC().Format("2006-01-02")}
+
+	totals, ok := r[k]
+	if !ok {
+		totals = &feeTotals{}
+		r[k] = totals
+	}
+
+	totals.transactions++
+	totals.fees += t.Fees
+	totals.executionEffort += t.ExecutionEffort
+}
+
+// rows returns the report ordered by day and address.
+func (r feeReport) rows() []FeeReportRow {
+	rr := make([]FeeReportRow, 0, len(r))
+	for k, totals := range r {
+		rr = append(rr, FeeReportRow{
+			Address:         k.address,
+			Day:             k.day,
+			Transactions:    totals.transactions,
+			Fees:            formatUFix64(totals.fees),
+			ExecutionEffort: formatUFix64(totals.executionEffort),
+		})
+	}
+
+	sort.Slice(rr, func(i, j int) bool {
+		if rr[i].Day != rr[j].Day {
+			return rr[i].Day < rr[j].Day
+		}
+		return rr[i].Address < rr[j].Address
+	})
+
+	return rr
+}
+
+// FeeReport returns the fees of sealed transactions per account and day,
+// scoped to the tenant if one is given.
+func (s *ServiceImpl) FeeReport(ctx context.Context, f FeeReportFilter, tenantID string) ([]FeeReportRow, error) {
+	if f.Address != "" {
+		address, err := flow_helpers.ValidateAddress(f.Address, s.cfg.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		f.Address = address
+	}
+
+	return s.store.TransactionFees(f, tenantID)
+}
@@ -0,0 +1,92 @@
+package transactions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func feesEvent(t *testing.T, eventType string, fields map[string]string) flow.Event {
+	t.Helper()
+
+	typ := &cadence.EventType{QualifiedIdentifier: eventType}
+	values := make([]cadence.Value, 0, len(fields))
+	for _, name := range []string{"amount", "inclusionEffort", "executionEffort"} {
+		s, ok := fields[name]
+		if !ok {
+			continue
+		}
+
+		v, err := cadence.NewUFix64(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		typ.Fields = append(typ.Fields, cadence.Field{Identifier: name, Type: cadence.UFix64Type{}})
+		values = append(values, v)
+	}
+
+	return flow.Event{Type: eventType, Value: cadence.NewEvent(values).WithType(typ)}
+}
+
+func Test_TransactionFees(t *testing.T) {
+	deposited := feesEvent(t, "A.912d5440f7e3769e.FlowFees.TokensDeposited", map[string]string{"amount": "0.00001"})
+	deducted := feesEvent(t, "A.912d5440f7e3769e.FlowFees.FeesDeducted", map[string]string{
+		"amount":          "0.00001",
+		"inclusionEffort": "1.0",
+		"executionEffort": "0.00000153",
+	})
+	other := feesEvent(t, "A.7e60df042a9c0868.FlowToken.TokensDeposited", map[string]string{"amount": "5.0"})
+	unknown := feesEvent(t, "A.0000000000000001.FlowFees.FeesDeducted", map[string]string{"amount": "5.0"})
+
+	cases := []struct {
+		name            string
+		events          []flow.Event
+		fees            string
+		executionEffort string
+	}{
+		{"no events", nil, "0.00000000", "0.00000000"},
+		{"fees deducted", []flow.Event{other, deposited, deducted}, "0.00001000", "0.00000153"},
+		{"fees deposited", []flow.Event{other, deposited}, "0.00001000", "0.00000000"},
+		{"unknown contract", []flow.Event{unknown}, "0.00000000", "0.00000000"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fees, executionEffort := transactionFees(c.events)
+			if got := formatUFix64(fees); got != c.fees {
+				t.Errorf("expected fees %s, got %s", c.fees, got)
+			}
+			if got := formatUFix64(executionEffort); got != c.executionEffort {
+				t.Errorf("expected execution effort %s, got %s", c.executionEffort, got)
+			}
+		})
+	}
+}
+
+func Test_FeeReport(t *testing.T) {
+	day := time.Date(2022, 11, 2, 23, 0, 0, 0, time.UTC)
+
+	report := feeReport{}
+	report.add(Transaction{ProposerAddress: "0x02", CreatedAt: day, Fees: 100, ExecutionEffort: 10})
+	report.add(Transaction{ProposerAddress: "0x01", CreatedAt: day.Add(2 * time.Hour), Fees: 300})
+	report.add(Transaction{ProposerAddress: "0x02", CreatedAt: day.Add(30 * time.Minute), Fees: 200, ExecutionEffort: 20})
+
+	rows := report.rows()
+	expected := []FeeReportRow{
+		{Address: "0x02", Day: "2022-11-02", Transactions: 2, Fees: "0.00000300", ExecutionEffort: "0.00000030"},
+		{Address: "0x01", Day: "2022-11-03", Transactions: 1, Fees: "0.00000300", ExecutionEffort: "0.00000000"},
+	}
+
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("expected row %d to be %+v, got %+v", i, expected[i], rows[i])
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAccountFrozen is returned when trying to send a transaction for a frozen account.
+var ErrAccountFrozen = errors.New("account frozen")
+
+// FreezeChecker reports whether an account is frozen.
+type FreezeChecker interface {
+	IsFrozen(address string) (bool, error)
+}
+
+// CheckNotFrozen returns ErrAccountFrozen if the account is frozen.
+func CheckNotFrozen(c FreezeChecker, address string) error {
+	if c == nil {
+		return nil
+	}
+
+	frozen, err := c.IsFrozen(address)
+	if err != nil {
+		return err
+	}
+
+	if frozen {
+		return fmt.Errorf("%w: %s", ErrAccountFrozen, address)
+	}
+
+	return nil
+}
@@ -2,8 +2,10 @@ package transactions
 
 import (
 	"context"
+	"errors"
 
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/onflow/flow-go-sdk"
 )
 
 const TransactionJobType = "transaction"
@@ -20,10 +22,36 @@ func (s *ServiceImpl) executeTransactionJob(ctx context.Context, j *jobs.Job) er
 		return err
 	}
 
-	err = s.sendTransaction(ctx, &tx)
-	if err != nil {
-		return err
+	// The account may have been frozen after the transaction was signed
+	if tx.ProposerAddress != s.cfg.AdminAddress {
+		if err := CheckNotFrozen(s.freezeChecker, tx.ProposerAddress); err != nil {
+			return jobs.PermanentFailure(err)
+		}
 	}
 
-	return nil
+	err = s.submitTransaction(ctx, &tx)
+	if isExpiredError(err) {
+		// The transaction sat in the queue for too long
+		if rebuildErr := s.rebuildTransaction(ctx, &tx, err); rebuildErr != nil {
+			err = rebuildErr
+		} else {
+			err = s.submitTransaction(ctx, &tx)
+		}
+	}
+
+	// The transaction gets a new ID whenever it is rebuilt
+	j.TransactionID = tx.TransactionId
+
+	if err != nil && tx.Status == flow.TransactionStatusSealed.String() && tx.ErrorMessage != "" {
+		return &RevertedError{Transaction: &tx, Err: err}
+	}
+
+	return err
+}
+
+// isRetryableTransactionError tells whether a transaction job which failed
+// with err may succeed when retried. A reverted transaction is final.
+func isRetryableTransactionError(err error) bool {
+	var reverted *RevertedError
+	return !errors.As(err, &reverted)
 }
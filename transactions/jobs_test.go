@@ -0,0 +1,18 @@
+package transactions
+
+import (
+	"fmt"
+	"testing"
+)
+
+func Test_IsRetryableTransactionError(t *testing.T) {
+	reverted := &RevertedError{Transaction: &Transaction{}, Err: fmt.Errorf("panic")}
+
+	if isRetryableTransactionError(reverted) || isRetryableTransactionError(fmt.Errorf("job: %w", reverted)) {
+		t.Fatal("expected a reverted transaction not to be retried")
+	}
+
+	if !isRetryableTransactionError(fmt.Errorf("connection refused")) {
+		t.Fatal("expected other errors to be retried")
+	}
+}
@@ -0,0 +1,85 @@
+package transactions
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+// StatusFailed filters for transactions which failed on chain.
+const StatusFailed = "FAILED"
+
+var listStatuses = map[string]bool{
+	flow.TransactionStatusPending.String():   true,
+	flow.TransactionStatusFinalized.String(): true,
+	flow.TransactionStatusExecuted.String():  true,
+	flow.TransactionStatusSealed.String():    true,
+	flow.TransactionStatusExpired.String():   true,
+	StatusFailed:                             true,
+	StatusCancelled:                          true,
+}
+
+// ListFilter filters transaction listings.
+type ListFilter struct {
+	// Type restricts the listing to one transaction type, Unknown lists all
+	Type Type
+	// Status is an on-chain status, StatusFailed or StatusCancelled
+	Status        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	TemplateName  string
+	TokenName     string
+	// Cursor continues a listing after the last transaction of the previous
+	// page, as returned by the listing. The offset is ignored with a cursor.
+	Cursor string
+}
+
+// listCursor is the position of a transaction in listings, newest first.
+type listCursor struct {
+	CreatedAt     time.Time
+	TransactionId string
+}
+
+func (f ListFilter) validate() error {
+	if f.Status != "" && !listStatuses[f.Status] {
+		return fmt.Errorf("invalid status: %q", f.Status)
+	}
+
+	if f.Cursor != "" {
+		if _, err := decodeCursor(f.Cursor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeCursor(t Transaction) string {
+	s := fmt.Sprintf("%d:%s", t.CreatedAt.UnixNano(), t.TransactionId)
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func decodeCursor(cursor string) (listCursor, error) {
+	invalid := fmt.Errorf("invalid cursor: %q", cursor)
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listCursor{}, invalid
+	}
+
+	parts := strings.SplitN(string(b), ":", 2)
+	if len(parts) != 2 {
+		return listCursor{}, invalid
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return listCursor{}, invalid
+	}
+
+	return listCursor{time.Unix(0, nanos), parts[1]}, nil
+}
@@ -1,11 +1,108 @@
 package transactions
 
-import "go.uber.org/ratelimit"
+import (
+	"encoding/json"
+
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"go.uber.org/ratelimit"
+)
 
 type ServiceOption func(*ServiceImpl)
 
+// TransactionOption customises a transaction built by the service.
+type TransactionOption func(*transactionOptions)
+
+type transactionOptions struct {
+	gasLimit    uint64
+	roles       Roles
+	callbackURL string
+	metadata    json.RawMessage
+	priority    jobs.Priority
+
+	// Recorded on the transaction for filtering listings
+	templateName string
+	tokenName    string
+}
+
 func WithTxRatelimiter(limiter ratelimit.Limiter) ServiceOption {
 	return func(svc *ServiceImpl) {
 		svc.txRateLimiter = limiter
 	}
 }
+
+// WithFreezeChecker rejects transactions proposed by frozen accounts.
+func WithFreezeChecker(c FreezeChecker) ServiceOption {
+	return func(svc *ServiceImpl) {
+		svc.freezeChecker = c
+	}
+}
+
+// WithAccountTenants limits the accounts tenant-scoped requests can select
+// to sign transactions to the accounts of the tenant.
+func WithAccountTenants(t AccountTenants) ServiceOption {
+	return func(svc *ServiceImpl) {
+		svc.accountTenants = t
+	}
+}
+
+// WithWebhooks enables webhook notifications of transaction results.
+func WithWebhooks(webhookService webhooks.Service) ServiceOption {
+	return func(svc *ServiceImpl) {
+		svc.webhooks = webhookService
+	}
+}
+
+// WithGasLimit sets the gas (computation) limit of a transaction instead of
+// the configured default. The limit can not exceed the configured maximum.
+func WithGasLimit(limit uint64) TransactionOption {
+	return func(o *transactionOptions) {
+		o.gasLimit = limit
+	}
+}
+
+// WithRoles selects the accounts signing a transaction in each role.
+func WithRoles(roles Roles) TransactionOption {
+	return func(o *transactionOptions) {
+		o.roles = roles
+	}
+}
+
+// WithCallbackURL has the result of a transaction posted to the given URL
+// once the transaction is sealed or fails.
+func WithCallbackURL(u string) TransactionOption {
+	return func(o *transactionOptions) {
+		o.callbackURL = u
+	}
+}
+
+// WithMetadata attaches free-form metadata, a JSON object, to a transaction.
+func WithMetadata(metadata json.RawMessage) TransactionOption {
+	return func(o *transactionOptions) {
+		o.metadata = metadata
+	}
+}
+
+// WithPriority sets the priority of the job sending a transaction, workers
+// take jobs of higher priority first.
+func WithPriority(p jobs.Priority) TransactionOption {
+	return func(o *transactionOptions) {
+		o.priority = p
+	}
+}
+
+// WithTemplateName records the name of the transaction template a
+// transaction was created from.
+func WithTemplateName(name string) TransactionOption {
+	return func(o *transactionOptions) {
+		o.templateName = name
+	}
+}
+
+// WithTokenName records the name of the token a transaction sets up or
+// transfers.
+func WithTokenName(name string) TransactionOption {
+	return func(o *transactionOptions) {
+		o.tokenName = name
+	}
+}
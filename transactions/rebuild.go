@@ -0,0 +1,123 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+// isExpiredError tells whether sending a transaction failed because its
+// reference block expired, either on submission or while waiting for it
+// to be sealed. An expired transaction was never executed.
+func isExpiredError(err error) bool {
+	if errors.Is(err, flow_helpers.ErrTransactionExpired) {
+		return true
+	}
+
+	var rpcErr grpc.RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.GRPCStatus().Code() == codes.InvalidArgument && strings.Contains(rpcErr.Error(), "expired")
+	}
+
+	return false
+}
+
+// isSequenceNumberError tells whether the access node rejected a transaction
+// because its proposal key sequence number was out of sync with the chain.
+// A rejected transaction was never executed.
+func isSequenceNumberError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "proposal key") && strings.Contains(msg, "sequence number")
+}
+
+// submitTransaction sends a transaction and, as long as it gets rejected for
+// an invalid proposal key sequence number, rebuilds it with the key state
+// fetched from chain and sends it again, at most
+// cfg.SequenceNumberMaxRetries times.
+func (s *ServiceImpl) submitTransaction(ctx context.Context, tx *Transaction) error {
+	err := s.sendTransaction(ctx, tx)
+
+	for retry := uint(1); isSequenceNumberError(err) && retry <= s.cfg.SequenceNumberMaxRetries; retry++ {
+		log.
+			WithFields(log.Fields{"error": err, "transactionId": tx.TransactionId, "attempt": retry}).
+			Warn("Proposal key sequence number out of sync, retrying")
+
+		if err := s.rebuildTransaction(ctx, tx, err); err != nil {
+			return err
+		}
+
+		err = s.sendTransaction(ctx, tx)
+	}
+
+	return err
+}
+
+// rebuildTransaction rebuilds a transaction which was never executed, due to
+// reason, with the latest block as the reference block and the current
+// proposal key sequence number, and signs it again. The transaction keeps its
+// code, arguments, gas limit and signing accounts but gets a new ID, the
+// stored transaction is updated accordingly and the failed attempt recorded.
+func (s *ServiceImpl) rebuildTransaction(ctx context.Context, tx *Transaction, reason error) error {
+	previous, err := flow.DecodeTransaction(tx.FlowTransaction)
+	if err != nil {
+		return err
+	}
+
+	roles := Roles{
+		Proposer: flow_helpers.FormatAddress(previous.ProposalKey.Address),
+		Payer:    flow_helpers.FormatAddress(previous.Payer),
+	}
+	for _, a := range previous.Authorizers {
+		roles.Authorizers = append(roles.Authorizers, flow_helpers.FormatAddress(a))
+	}
+
+	signers, err := s.getSigners(ctx, tx.ProposerAddress, roles)
+	if err != nil {
+		return err
+	}
+
+	flowTx := flow.NewTransaction().
+		SetScript(previous.Script).
+		SetGasLimit(previous.GasLimit)
+	flowTx.Arguments = previous.Arguments
+
+	if err := s.setSigners(ctx, flowTx, signers); err != nil {
+		return err
+	}
+
+	if err := signFlowTransaction(flowTx, signers); err != nil {
+		return err
+	}
+
+	// The transaction is only updated once the stored one is replaced
+	rebuilt := *tx
+	if err := rebuilt.addAttempt(reason); err != nil {
+		return err
+	}
+
+	rebuilt.TransactionId = flowTx.ID().Hex()
+	rebuilt.FlowTransaction = flowTx.Encode()
+
+	if err := s.store.ReplaceTransaction(tx.TransactionId, &rebuilt); err != nil {
+		return err
+	}
+
+	previousID := tx.TransactionId
+	*tx = rebuilt
+
+	log.
+		WithFields(log.Fields{"previousTransactionId": previousID, "transactionId": tx.TransactionId, "reason": reason}).
+		Info("Rebuilt transaction")
+
+	return nil
+}
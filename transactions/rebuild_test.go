@@ -0,0 +1,116 @@
+package transactions
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_IsExpiredError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "expired while waiting for seal",
+			err:      flow_helpers.ErrTransactionExpired,
+			expected: true,
+		},
+		{
+			name:     "expired on submission",
+			err:      grpc.RPCError{GRPCErr: status.Error(codes.InvalidArgument, "transaction is expired: ref_height=1 final_height=700")},
+			expected: true,
+		},
+		{
+			name:     "wrapped expiry",
+			err:      fmt.Errorf("error while sending: %w", flow_helpers.ErrTransactionExpired),
+			expected: true,
+		},
+		{
+			name: "other invalid argument",
+			err:  grpc.RPCError{GRPCErr: status.Error(codes.InvalidArgument, "invalid signature")},
+		},
+		{
+			name: "unavailable",
+			err:  grpc.RPCError{GRPCErr: status.Error(codes.Unavailable, "connection refused")},
+		},
+		{
+			name: "no error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isExpiredError(tc.err); got != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_IsSequenceNumberError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "invalid sequence number",
+			err:      grpc.RPCError{GRPCErr: status.Error(codes.InvalidArgument, "[Error Code: 1007] invalid proposal key: public key 0 on account f8d6e0586b0a20c7 does not have a valid signature: the sequence number is invalid, expected 4, got 3")},
+			expected: true,
+		},
+		{
+			name:     "wrapped",
+			err:      fmt.Errorf("error while sending: %w", errors.New("Invalid Proposal Key Sequence Number")),
+			expected: true,
+		},
+		{
+			name: "expired",
+			err:  grpc.RPCError{GRPCErr: status.Error(codes.InvalidArgument, "transaction is expired: ref_height=1 final_height=700")},
+		},
+		{
+			name: "no error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isSequenceNumberError(tc.err); got != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_TransactionAttempts(t *testing.T) {
+	tx := Transaction{TransactionId: "a"}
+
+	if aa := tx.ToJSONResponse().Attempts; len(aa) != 0 {
+		t.Fatalf("expected no attempts, got %v", aa)
+	}
+
+	if err := tx.addAttempt(errors.New("first")); err != nil {
+		t.Fatal(err)
+	}
+
+	tx.TransactionId = "b"
+
+	if err := tx.addAttempt(errors.New("second")); err != nil {
+		t.Fatal(err)
+	}
+
+	aa := tx.ToJSONResponse().Attempts
+	if len(aa) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(aa))
+	}
+
+	if aa[0].TransactionId != "a" || aa[0].Error != "first" || aa[1].TransactionId != "b" || aa[1].Error != "second" {
+		t.Fatalf("unexpected attempts: %+v", aa)
+	}
+}
@@ -0,0 +1,233 @@
+package transactions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"github.com/onflow/cadence"
+	c_json "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/access/grpc"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	resultFetchPageSize = 100
+	// Transactions are expected to be final well within this window, older
+	// ones (e.g. never sent because their job failed) are not polled anymore
+	resultFetchWindow = 24 * time.Hour
+)
+
+// storedEvent is the database form of an event, Payload is JSON-Cadence.
+type storedEvent struct {
+	Type             string          `json:"type"`
+	TransactionIndex int             `json:"transactionIndex"`
+	EventIndex       int             `json:"eventIndex"`
+	Payload          json.RawMessage `json:"payload"`
+}
+
+// isFinalStatus tells whether a transaction status can not change anymore.
+func isFinalStatus(status string) bool {
+	return status == flow.TransactionStatusSealed.String() || status == flow.TransactionStatusExpired.String() || status == StatusCancelled
+}
+
+// setResult records the on-chain result of the transaction.
+func (t *Transaction) setResult(result *flow.TransactionResult, blockHeight uint64) error {
+	t.Status = result.Status.String()
+	t.Events = result.Events
+
+	t.ErrorMessage = ""
+	if result.Error != nil {
+		t.ErrorMessage = result.Error.Error()
+	}
+
+	t.BlockID, t.BlockHeight = "", blockHeight
+	if result.BlockID != flow.EmptyID {
+		t.BlockID = result.BlockID.Hex()
+	}
+
+	events := make([]storedEvent, len(result.Events))
+	for i, e := range result.Events {
+		payload, err := c_json.Encode(e.Value)
+		if err != nil {
+			return fmt.Errorf("error while encoding event %s: %w", e.Type, err)
+		}
+
+		events[i] = storedEvent{e.Type, e.TransactionIndex, e.EventIndex, payload}
+	}
+
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	t.StoredEvents = b
+
+	t.Fees, t.ExecutionEffort = 0, 0
+	if result.Status == flow.TransactionStatusSealed {
+		t.Fees, t.ExecutionEffort = transactionFees(result.Events)
+	}
+
+	return nil
+}
+
+// decodeEvents decodes the stored events of the transaction into Events.
+func (t *Transaction) decodeEvents() error {
+	if len(t.StoredEvents) == 0 {
+		return nil
+	}
+
+	var events []storedEvent
+	if err := json.Unmarshal(t.StoredEvents, &events); err != nil {
+		return err
+	}
+
+	t.Events = make([]flow.Event, len(events))
+	for i, e := range events {
+		v, err := c_json.Decode(nil, e.Payload)
+		if err != nil {
+			return fmt.Errorf("error while decoding event %s: %w", e.Type, err)
+		}
+
+		value, ok := v.(cadence.Event)
+		if !ok {
+			return fmt.Errorf("not an event: %s", e.Type)
+		}
+
+		t.Events[i] = flow.Event{
+			Type:             e.Type,
+			TransactionID:    flow.HexToID(t.TransactionId),
+			TransactionIndex: e.TransactionIndex,
+			EventIndex:       e.EventIndex,
+			Value:            value,
+			Payload:          e.Payload,
+		}
+	}
+
+	return nil
+}
+
+// FetchResults fetches the on-chain results of sent transactions which are
+// not final yet and records them. Run periodically by a results.Fetcher.
+func (s *ServiceImpl) FetchResults(ctx context.Context) error {
+	fetched, failed := 0, 0
+
+	since := time.Now().Add(-resultFetchWindow)
+
+	// Transactions turning final drop out of the query, the offset only
+	// skips the ones still in progress
+	for offset := 0; ; {
+		tt, err := s.store.UnfinishedTransactions(since, resultFetchPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for i := range tt {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			final, err := s.fetchResult(ctx, &tt[i])
+			if err != nil {
+				failed++
+				log.
+					WithFields(log.Fields{"error": err, "transactionId": tt[i].TransactionId}).
+					Warn("Fetching transaction result failed")
+			}
+
+			if err != nil || !final {
+				offset++
+				continue
+			}
+			fetched++
+		}
+
+		if len(tt) < resultFetchPageSize {
+			break
+		}
+	}
+
+	if fetched > 0 || failed > 0 {
+		log.WithFields(log.Fields{"fetched": fetched, "failed": failed}).Info("Fetched transaction results")
+	}
+
+	return nil
+}
+
+// fetchResult fetches and records the result of a transaction, it tells
+// whether the result is final.
+func (s *ServiceImpl) fetchResult(ctx context.Context, tx *Transaction) (bool, error) {
+	result, err := s.fc.GetTransactionResult(ctx, flow.HexToID(tx.TransactionId))
+	if err != nil {
+		if rpcErr, ok := err.(grpc.RPCError); ok && rpcErr.GRPCStatus().Code() == codes.NotFound {
+			// Not sent yet
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := s.recordResult(ctx, tx, result); err != nil {
+		return false, err
+	}
+
+	return isFinalStatus(tx.Status), nil
+}
+
+// recordResult stores the result of a transaction once the status changes.
+func (s *ServiceImpl) recordResult(ctx context.Context, tx *Transaction, result *flow.TransactionResult) error {
+	if result.Status.String() == tx.Status {
+		return nil
+	}
+
+	var blockHeight uint64
+	if result.BlockID != flow.EmptyID {
+		header, err := s.fc.GetBlockHeaderByID(ctx, result.BlockID)
+		if err != nil {
+			return err
+		}
+		blockHeight = header.Height
+	}
+
+	if err := tx.setResult(result, blockHeight); err != nil {
+		return err
+	}
+
+	updated, err := s.store.UpdateTransactionResult(tx)
+	if err != nil {
+		return err
+	}
+
+	// Only the first to record the final result notifies
+	if updated && isFinalStatus(tx.Status) {
+		s.notifyResult(tx)
+	}
+
+	return nil
+}
+
+// notifyResult sends a webhook notification of a final transaction result to
+// the callback URL of the transaction and, if configured, to all endpoints.
+func (s *ServiceImpl) notifyResult(tx *Transaction) {
+	if s.webhooks == nil {
+		return
+	}
+
+	event := webhooks.EventTransactionSealed
+	if tx.ErrorMessage != "" || tx.Status != flow.TransactionStatusSealed.String() {
+		event = webhooks.EventTransactionFailed
+	}
+
+	data := tx.ToJSONResponse()
+
+	if tx.CallbackURL != "" {
+		s.webhooks.NotifyEndpoint(tx.CallbackURL, event, data)
+	}
+
+	if s.cfg.WebhookTransactionEvents {
+		s.webhooks.Notify(event, data)
+	}
+}
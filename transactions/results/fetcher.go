@@ -0,0 +1,66 @@
+// Package results provides periodic fetching of transaction results.
+package results
+
+import (
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/system"
+	"github.com/flow-hydraulics/flow-wallet-api/transactions"
+	log "github.com/sirupsen/logrus"
+)
+
+type Fetcher interface {
+	Start() Fetcher
+	Stop()
+}
+
+type FetcherImpl struct {
+	task         *system.PeriodicTask
+	transactions transactions.Service
+	interval     time.Duration
+
+	systemService system.Service
+}
+
+// NewFetcher creates a fetcher that records the on-chain results of sent
+// transactions every interval, see transactions.Service.FetchResults.
+func NewFetcher(transactionService transactions.Service, interval time.Duration, opts ...FetcherOption) Fetcher {
+	fetcher := &FetcherImpl{
+		transactions: transactionService,
+		interval:     interval,
+	}
+
+	// Go through options
+	for _, opt := range opts {
+		opt(fetcher)
+	}
+
+	return fetcher
+}
+
+func (f *FetcherImpl) Start() Fetcher {
+	if f.task != nil {
+		// Already started
+		return f
+	}
+
+	entry := log.WithFields(log.Fields{
+		"package":  "results",
+		"function": "Fetcher.Start.goroutine",
+	})
+	f.task = system.StartPeriodicTask(entry, "transaction result fetching", f.interval, f.systemService, f.transactions.FetchResults)
+
+	log.
+		WithFields(log.Fields{"interval": f.interval}).
+		Info("Started transaction result fetcher")
+
+	return f
+}
+
+func (f *FetcherImpl) Stop() {
+	log.Debug("Stopping transaction result fetcher")
+
+	if f.task != nil {
+		f.task.Stop()
+	}
+}
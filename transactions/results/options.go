@@ -0,0 +1,14 @@
+package results
+
+import (
+	"github.com/flow-hydraulics/flow-wallet-api/system"
+)
+
+type FetcherOption func(*FetcherImpl)
+
+// WithSystemService postpones fetching while the system is halted.
+func WithSystemService(svc system.Service) FetcherOption {
+	return func(f *FetcherImpl) {
+		f.systemService = svc
+	}
+}
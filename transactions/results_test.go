@@ -0,0 +1,146 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+func Test_TransactionResultRoundTrip(t *testing.T) {
+	eventType := &cadence.EventType{
+		QualifiedIdentifier: "A.0ae53cb6e3f42a79.FlowToken.TokensDeposited",
+		Fields: []cadence.Field{
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+		},
+	}
+
+	amount, err := cadence.NewUFix64("1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := flow.Event{
+		Type:       eventType.QualifiedIdentifier,
+		EventIndex: 1,
+		Value:      cadence.NewEvent([]cadence.Value{amount}).WithType(eventType),
+	}
+
+	result := &flow.TransactionResult{
+		Status:  flow.TransactionStatusSealed,
+		Error:   errors.New("execution failed"),
+		Events:  []flow.Event{event},
+		BlockID: flow.HexToID("01"),
+	}
+
+	tx := Transaction{TransactionId: flow.HexToID("02").Hex()}
+	if err := tx.setResult(result, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.Status != "SEALED" || tx.ErrorMessage != "execution failed" || tx.BlockID != result.BlockID.Hex() || tx.BlockHeight != 10 {
+		t.Fatalf("unexpected result: %+v", tx)
+	}
+
+	// As loaded from the database
+	stored := Transaction{TransactionId: tx.TransactionId, StoredEvents: tx.StoredEvents}
+	if err := stored.decodeEvents(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored.Events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(stored.Events))
+	}
+
+	e := stored.Events[0]
+	if e.Type != event.Type || e.EventIndex != event.EventIndex || e.TransactionID.Hex() != tx.TransactionId {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	if e.Value.String() != event.Value.String() {
+		t.Fatalf("expected event value %s, got %s", event.Value, e.Value)
+	}
+
+	if !isFinalStatus(tx.Status) {
+		t.Fatal("expected a sealed transaction to be final")
+	}
+}
+
+type notification struct {
+	endpoint string
+	event    webhooks.Event
+}
+
+// recordingWebhooks records notifications instead of sending them.
+type recordingWebhooks struct {
+	notifications []notification
+}
+
+func (w *recordingWebhooks) Notify(event webhooks.Event, data interface{}) {
+	w.notifications = append(w.notifications, notification{"", event})
+}
+
+func (w *recordingWebhooks) NotifyEndpoint(endpoint string, event webhooks.Event, data interface{}) {
+	w.notifications = append(w.notifications, notification{endpoint, event})
+}
+
+func (w *recordingWebhooks) Deliver(ctx context.Context, endpoint string, payload []byte) error {
+	return nil
+}
+
+func (w *recordingWebhooks) Endpoints() []string {
+	return nil
+}
+
+func Test_NotifyResult(t *testing.T) {
+	testCases := []struct {
+		name          string
+		tx            Transaction
+		globalEvents  bool
+		notifications []notification
+	}{
+		{
+			name:          "sealed with callback",
+			tx:            Transaction{Status: "SEALED", CallbackURL: "https://example.com/cb"},
+			notifications: []notification{{"https://example.com/cb", webhooks.EventTransactionSealed}},
+		},
+		{
+			name:          "failed with callback",
+			tx:            Transaction{Status: "SEALED", ErrorMessage: "panic", CallbackURL: "https://example.com/cb"},
+			notifications: []notification{{"https://example.com/cb", webhooks.EventTransactionFailed}},
+		},
+		{
+			name: "expired without callback",
+			tx:   Transaction{Status: "EXPIRED"},
+		},
+		{
+			name:          "expired with global events",
+			tx:            Transaction{Status: "EXPIRED"},
+			globalEvents:  true,
+			notifications: []notification{{"", webhooks.EventTransactionFailed}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &recordingWebhooks{}
+			svc := &ServiceImpl{cfg: &configs.Config{WebhookTransactionEvents: tc.globalEvents}, webhooks: w}
+
+			svc.notifyResult(&tc.tx)
+
+			if len(w.notifications) != len(tc.notifications) {
+				t.Fatalf("expected notifications %v, got %v", tc.notifications, w.notifications)
+			}
+
+			for i, n := range tc.notifications {
+				if w.notifications[i] != n {
+					t.Fatalf("expected notification %v, got %v", n, w.notifications[i])
+				}
+			}
+		})
+	}
+}
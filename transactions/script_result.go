@@ -0,0 +1,150 @@
+package transactions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/onflow/cadence"
+	c_json "github.com/onflow/cadence/encoding/json"
+)
+
+// Formats of script results.
+const (
+	// ScriptResultJSONCadence is the JSON-Cadence encoding of the result,
+	// with the type of each value.
+	ScriptResultJSONCadence = "json-cadence"
+	// ScriptResultPlain is the result decoded to plain JSON values, without
+	// types, see plainValue.
+	ScriptResultPlain = "plain"
+)
+
+// EncodeScriptResult encodes the result of a script in the given format,
+// JSON-Cadence by default.
+func EncodeScriptResult(v cadence.Value, format string) (json.RawMessage, error) {
+	switch format {
+	case "", ScriptResultJSONCadence:
+		b, err := c_json.Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.TrimSpace(b), nil
+	case ScriptResultPlain:
+		return json.Marshal(plainValue(v))
+	}
+
+	return nil, fmt.Errorf("invalid format: %q, expected %q or %q", format, ScriptResultJSONCadence, ScriptResultPlain)
+}
+
+// plainValue converts a Cadence value to a value which marshals to plain
+// JSON. Integers are JSON numbers of any size, fixed-point numbers strings,
+// e.g. "1.50000000", addresses 0x prefixed hex strings, paths strings and
+// composites objects of their fields. Dictionaries with keys other than
+// strings, addresses, characters or numbers are lists of key-value pairs.
+func plainValue(v cadence.Value) interface{} {
+	switch v := v.(type) {
+	case nil, cadence.Void:
+		return nil
+	case cadence.Optional:
+		return plainValue(v.Value)
+	case cadence.Bool:
+		return bool(v)
+	case cadence.String:
+		return string(v)
+	case cadence.Character:
+		return string(v)
+	case cadence.Address:
+		return v.String()
+	case cadence.Fix64, cadence.UFix64:
+		return v.String()
+	case cadence.Path:
+		return v.String()
+	case cadence.TypeValue:
+		if v.StaticType == nil {
+			return ""
+		}
+		return v.StaticType.ID()
+	case cadence.Capability:
+		c := map[string]interface{}{
+			"path":    v.Path.String(),
+			"address": v.Address.String(),
+		}
+		if v.BorrowType != nil {
+			c["borrowType"] = v.BorrowType.ID()
+		}
+		return c
+	case cadence.Array:
+		a := make([]interface{}, len(v.Values))
+		for i, e := range v.Values {
+			a[i] = plainValue(e)
+		}
+		return a
+	case cadence.Dictionary:
+		return plainDictionary(v)
+	}
+
+	if isNumberType(typeID(v)) {
+		return json.Number(v.String())
+	}
+
+	if t, fields, ok := compositeFields(v); ok {
+		m := make(map[string]interface{}, len(fields))
+		for i, f := range t.CompositeFields() {
+			if i < len(fields) {
+				m[f.Identifier] = plainValue(fields[i])
+			}
+		}
+		return m
+	}
+
+	return v.String()
+}
+
+// plainDictionary converts a dictionary to an object if all its keys
+// convert to strings or numbers, to a list of key-value pairs otherwise.
+func plainDictionary(d cadence.Dictionary) interface{} {
+	m := make(map[string]interface{}, len(d.Pairs))
+	for _, p := range d.Pairs {
+		var key string
+		switch k := plainValue(p.Key).(type) {
+		case string:
+			key = k
+		case json.Number:
+			key = k.String()
+		default:
+			return plainPairs(d)
+		}
+		m[key] = plainValue(p.Value)
+	}
+
+	return m
+}
+
+func plainPairs(d cadence.Dictionary) []map[string]interface{} {
+	pairs := make([]map[string]interface{}, len(d.Pairs))
+	for i, p := range d.Pairs {
+		pairs[i] = map[string]interface{}{
+			"key":   plainValue(p.Key),
+			"value": plainValue(p.Value),
+		}
+	}
+
+	return pairs
+}
+
+func compositeFields(v cadence.Value) (cadence.CompositeType, []cadence.Value, bool) {
+	switch v := v.(type) {
+	case cadence.Struct:
+		return v.StructType, v.Fields, v.StructType != nil
+	case cadence.Resource:
+		return v.ResourceType, v.Fields, v.ResourceType != nil
+	case cadence.Event:
+		return v.EventType, v.Fields, v.EventType != nil
+	case cadence.Contract:
+		return v.ContractType, v.Fields, v.ContractType != nil
+	case cadence.Enum:
+		return v.EnumType, v.Fields, v.EnumType != nil
+	}
+
+	return nil, nil, false
+}
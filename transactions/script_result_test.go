@@ -0,0 +1,57 @@
+package transactions
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+)
+
+func Test_EncodeScriptResult(t *testing.T) {
+	amount, err := cadence.NewUFix64("1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	structType := &cadence.StructType{
+		QualifiedIdentifier: "S.test.Balance",
+		Fields: []cadence.Field{
+			{Identifier: "owner", Type: cadence.AddressType{}},
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+		},
+	}
+	balance := cadence.NewStruct([]cadence.Value{cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1}), amount}).WithType(structType)
+
+	cases := []struct {
+		name     string
+		value    cadence.Value
+		format   string
+		expected string
+	}{
+		{"json-cadence", cadence.NewInt(1), "", `{"type":"Int","value":"1"}`},
+		{"json-cadence explicit", cadence.NewOptional(nil), ScriptResultJSONCadence, `{"type":"Optional","value":null}`},
+		{"plain int", cadence.NewUInt64(18446744073709551615), ScriptResultPlain, `18446744073709551615`},
+		{"plain fixed point", amount, ScriptResultPlain, `"1.50000000"`},
+		{"plain optional", cadence.NewOptional(cadence.String("a")), ScriptResultPlain, `"a"`},
+		{"plain nil", cadence.NewOptional(nil), ScriptResultPlain, `null`},
+		{"plain array", cadence.NewArray([]cadence.Value{cadence.NewBool(true), cadence.Path{Domain: "public", Identifier: "vault"}}), ScriptResultPlain, `[true,"/public/vault"]`},
+		{"plain struct", balance, ScriptResultPlain, `{"amount":"1.50000000","owner":"0x0000000000000001"}`},
+		{"plain dictionary", cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadence.String("b"), Value: cadence.NewInt(2)}, {Key: cadence.String("a"), Value: cadence.NewInt(1)}}), ScriptResultPlain, `{"a":1,"b":2}`},
+		{"plain dictionary of pairs", cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadence.NewBool(true), Value: cadence.NewInt(1)}}), ScriptResultPlain, `[{"key":true,"value":1}]`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := EncodeScriptResult(c.value, c.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, b)
+			}
+		})
+	}
+
+	if _, err := EncodeScriptResult(cadence.NewInt(1), "xml"); err == nil {
+		t.Error("expected an error for an invalid format")
+	}
+}
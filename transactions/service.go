@@ -2,42 +2,68 @@ package transactions
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/flow-hydraulics/flow-wallet-api/audit"
 	"github.com/flow-hydraulics/flow-wallet-api/configs"
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
 	"github.com/flow-hydraulics/flow-wallet-api/errors"
 	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
 	"github.com/flow-hydraulics/flow-wallet-api/jobs"
 	"github.com/flow-hydraulics/flow-wallet-api/keys"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/flow-hydraulics/flow-wallet-api/webhooks"
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/grpc"
+	log "github.com/sirupsen/logrus"
 	"go.uber.org/ratelimit"
 	"google.golang.org/grpc/codes"
+	"gorm.io/datatypes"
 )
 
 type Service interface {
-	Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type) (*jobs.Job, *Transaction, error)
-	Sign(ctx context.Context, proposerAddress string, code string, args []Argument) (*SignedTransaction, error)
-	List(limit, offset int) ([]Transaction, error)
-	ListForAccount(tType Type, address string, limit, offset int) ([]Transaction, error)
+	Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*jobs.Job, *Transaction, error)
+	CreateBatch(ctx context.Context, proposerAddress string, requests []JSONRequest) ([]BatchItem, error)
+	Build(ctx context.Context, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*Transaction, error)
+	Send(ctx context.Context, sync bool, transactionId string) (*jobs.Job, *Transaction, error)
+	Sign(ctx context.Context, proposerAddress string, code string, args []Argument, opts ...TransactionOption) (*SignedTransaction, error)
+	SignRaw(ctx context.Context, address string, flowTx flow.Transaction) (*SignedTransaction, error)
+	SubmitSigned(ctx context.Context, sync bool, flowTx flow.Transaction) (*Transaction, error)
+	Sponsor(ctx context.Context, sync bool, flowTx flow.Transaction) (*Transaction, error)
+	DryRun(ctx context.Context, proposerAddress string, code string, args []Argument, opts ...TransactionOption) (*DryRunResult, error)
+	List(limit, offset int, f ListFilter, tenantID string) ([]Transaction, string, error)
+	ListForAccount(address string, limit, offset int, f ListFilter) ([]Transaction, string, error)
 	Details(ctx context.Context, transactionId string) (*Transaction, error)
 	DetailsForAccount(ctx context.Context, tType Type, address, transactionId string) (*Transaction, error)
+	Events(ctx context.Context, address, transactionId, eventType string) ([]Event, error)
+	Cancel(ctx context.Context, address, transactionId string) (*Transaction, error)
+	FeeReport(ctx context.Context, f FeeReportFilter, tenantID string) ([]FeeReportRow, error)
 	ExecuteScript(ctx context.Context, code string, args []Argument) (cadence.Value, error)
+	ExecuteScriptAt(ctx context.Context, code string, args []Argument, at ScriptBlock) (cadence.Value, error)
+	AllowCode(ctx context.Context, kind, hash, code, description string) (*AllowedCode, error)
+	ListAllowedCode(ctx context.Context, kind string) ([]AllowedCode, error)
+	RemoveAllowedCode(ctx context.Context, kind, hash string) error
+	CheckTransactionCode(ctx context.Context, code string) error
+	FetchResults(ctx context.Context) error
 	UpdateTransaction(t *Transaction) error
 	GetOrCreateTransaction(transactionId string) *Transaction
 }
 
 // ServiceImpl defines the API for transaction HTTP handlers.
 type ServiceImpl struct {
-	store         Store
-	km            keys.Manager
-	fc            flow_helpers.FlowClient
-	wp            jobs.WorkerPool
-	cfg           *configs.Config
-	txRateLimiter ratelimit.Limiter
+	store          Store
+	km             keys.Manager
+	fc             flow_helpers.FlowClient
+	wp             jobs.WorkerPool
+	cfg            *configs.Config
+	txRateLimiter  ratelimit.Limiter
+	freezeChecker  FreezeChecker
+	webhooks       webhooks.Service
+	accountTenants AccountTenants
 }
 
 // NewService initiates a new transaction service.
@@ -52,7 +78,7 @@ func NewService(
 	var defaultTxRatelimiter = ratelimit.NewUnlimited()
 
 	// TODO(latenssi): safeguard against nil config?
-	svc := &ServiceImpl{store, km, fc, wp, cfg, defaultTxRatelimiter}
+	svc := &ServiceImpl{store, km, fc, wp, cfg, defaultTxRatelimiter, nil, nil, nil}
 
 	for _, opt := range opts {
 		opt(svc)
@@ -64,12 +90,14 @@ func NewService(
 
 	// Register asynchronous job executor.
 	wp.RegisterExecutor(TransactionJobType, svc.executeTransactionJob)
+	wp.RegisterRetryPolicy(TransactionJobType, jobs.RetryPolicy{Retryable: isRetryableTransactionError})
 
 	return svc
 }
 
-func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type) (*jobs.Job, *Transaction, error) {
-	transaction, err := s.newTransaction(ctx, proposerAddress, code, args, tType)
+// Create builds, signs and sends a transaction.
+func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*jobs.Job, *Transaction, error) {
+	transaction, err := s.newTransaction(ctx, proposerAddress, code, args, tType, opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error while getting new transaction: %w", err)
 	}
@@ -78,9 +106,109 @@ func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress str
 		return nil, nil, fmt.Errorf("error while inserting transaction in db: %w", err)
 	}
 
+	return s.send(ctx, sync, transaction)
+}
+
+// Build builds and signs a transaction and stores it without sending it.
+// The transaction can be sent later on with Send, as long as it has not
+// expired and the proposal key sequence number has not been used meanwhile.
+func (s *ServiceImpl) Build(ctx context.Context, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*Transaction, error) {
+	if err := s.CheckTransactionCode(ctx, code); err != nil {
+		return nil, err
+	}
+
+	proposerAddress, err := flow_helpers.ValidateAddress(proposerAddress, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The proposer comes from the request body rather than the path, which
+	// the tenant scope of the routes does not cover
+	if err := s.checkAccountTenant(ctx, proposerAddress); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.newTransaction(ctx, proposerAddress, code, args, tType, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting new transaction: %w", err)
+	}
+
+	// Stays pending until sent with Send
+	transaction.PendingSend = true
+
+	if err := s.store.InsertTransaction(transaction); err != nil {
+		return nil, fmt.Errorf("error while inserting transaction in db: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// Send sends a built transaction.
+func (s *ServiceImpl) Send(ctx context.Context, sync bool, transactionId string) (*jobs.Job, *Transaction, error) {
+	// Check if the input is a valid transaction id
+	if err := flow_helpers.ValidateTransactionId(transactionId); err != nil {
+		return nil, nil, err
+	}
+
+	transaction, err := s.store.Transaction(transactionId)
+
+	// Transactions of other tenants are not visible
+	tenantID := tenants.FromContext(ctx)
+	if (err != nil && err.Error() == "record not found") || (tenantID != "" && tenantID != transaction.TenantID) {
+		// Convert error to a 404 RequestError
+		err = &errors.RequestError{
+			StatusCode: http.StatusNotFound,
+			Err:        fmt.Errorf("transaction not found"),
+		}
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The account may have been frozen after the transaction was built
+	if transaction.ProposerAddress != s.cfg.AdminAddress {
+		if err := CheckNotFrozen(s.freezeChecker, transaction.ProposerAddress); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Claim the transaction so it only gets sent once
+	claimed, err := s.store.SetTransactionPendingSend(transactionId, true, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !claimed {
+		return nil, nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("transaction already sent"),
+		}
+	}
+
+	transaction.PendingSend = false
+
+	job, tx, err := s.send(ctx, sync, &transaction)
+	if err != nil && !isSentError(err) {
+		// Let the transaction be sent again, resending a transaction
+		// that did reach the chain has no effect
+		if _, releaseErr := s.store.SetTransactionPendingSend(transactionId, false, true); releaseErr != nil {
+			log.WithFields(log.Fields{"error": releaseErr, "transactionId": transactionId}).Warn("Could not release transaction claim")
+		}
+	}
+
+	return job, tx, err
+}
+
+func (s *ServiceImpl) send(ctx context.Context, sync bool, transaction *Transaction) (*jobs.Job, *Transaction, error) {
 	if !sync {
 		// Async
-		job, err := s.wp.CreateJob(TransactionJobType, transaction.TransactionId)
+		job, err := s.wp.CreateJob(
+			TransactionJobType,
+			transaction.TransactionId,
+			jobs.WithTenantID(tenants.FromContext(ctx)),
+			jobs.WithPriority(transaction.Priority),
+		)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error while creating job: %w", err)
 		}
@@ -93,7 +221,7 @@ func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress str
 
 	} else {
 		// Sync
-		if err := s.sendTransaction(ctx, transaction); err != nil {
+		if err := s.submitSync(ctx, transaction, s.submitTransaction); err != nil {
 			return nil, nil, err
 		}
 
@@ -101,8 +229,17 @@ func (s *ServiceImpl) Create(ctx context.Context, sync bool, proposerAddress str
 	}
 }
 
-func (s *ServiceImpl) Sign(ctx context.Context, proposerAddress string, code string, args []Argument) (*SignedTransaction, error) {
-	flowTx, err := s.buildFlowTransaction(ctx, proposerAddress, code, args)
+func (s *ServiceImpl) Sign(ctx context.Context, proposerAddress string, code string, args []Argument, opts ...TransactionOption) (*SignedTransaction, error) {
+	if err := s.CheckTransactionCode(ctx, code); err != nil {
+		return nil, err
+	}
+
+	o, err := s.transactionOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	flowTx, err := s.buildFlowTransaction(ctx, proposerAddress, code, args, o)
 	if err != nil {
 		return nil, err
 	}
@@ -110,23 +247,122 @@ func (s *ServiceImpl) Sign(ctx context.Context, proposerAddress string, code str
 	return &SignedTransaction{Transaction: *flowTx}, nil
 }
 
-// List returns all transactions in the datastore.
-func (s *ServiceImpl) List(limit, offset int) ([]Transaction, error) {
+// SignRaw signs a client-built transaction with the keys of the given account
+// without sending it. The account signs the envelope if it is the payer of the
+// transaction and the payload if it is the proposer or an authorizer.
+func (s *ServiceImpl) SignRaw(ctx context.Context, address string, flowTx flow.Transaction) (*SignedTransaction, error) {
+	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Client-built transactions could otherwise spend the admin account's funds
+	if address == s.cfg.AdminAddress {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("client-built transactions can not be signed by the admin account"),
+		}
+	}
+
+	if err := s.CheckTransactionCode(ctx, string(flowTx.Script)); err != nil {
+		return nil, err
+	}
+
+	if err := CheckNotFrozen(s.freezeChecker, address); err != nil {
+		return nil, err
+	}
+
+	flowAddress := flow.HexToAddress(address)
+
+	isPayer := flowTx.Payer == flowAddress
+	isPayloadSigner := flowTx.ProposalKey.Address == flowAddress
+	for _, a := range flowTx.Authorizers {
+		if a == flowAddress {
+			isPayloadSigner = true
+		}
+	}
+
+	if !isPayer && !isPayloadSigner {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("account %s is not the proposer, an authorizer or the payer of the transaction", address),
+		}
+	}
+
+	authorizer, err := s.km.UserAuthorizer(ctx, flowAddress)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting user authorizer: %w", err)
+	}
+
+	// A payer signs the envelope only, regardless of its other roles
+	sign := flowTx.SignPayload
+	if isPayer {
+		sign = flowTx.SignEnvelope
+	}
+
+	if err := sign(authorizer.Address, authorizer.Key.Index, authorizer.Signer); err != nil {
+		return nil, err
+	}
+
+	// Multi-signature accounts need the rest of the signatures as well
+	for _, c := range authorizer.CoSigners {
+		if err := sign(authorizer.Address, c.Key.Index, c.Signer); err != nil {
+			return nil, err
+		}
+	}
+
+	return &SignedTransaction{Transaction: flowTx}, nil
+}
+
+// List returns the transactions of all accounts matching the filter, scoped
+// to the tenant if one is given, newest first along with a cursor to the next
+// page if the page is full.
+func (s *ServiceImpl) List(limit, offset int, f ListFilter, tenantID string) ([]Transaction, string, error) {
+	if err := f.validate(); err != nil {
+		return nil, "", &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
 	o := datastore.ParseListOptions(limit, offset)
-	return s.store.Transactions(o)
+
+	tt, err := s.store.Transactions(o, f, tenantID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if o.Limit > 0 && len(tt) == o.Limit {
+		next = encodeCursor(tt[len(tt)-1])
+	}
+
+	return tt, next, nil
 }
 
-// ListForAccount returns all transactions in the datastore for a given account.
-func (s *ServiceImpl) ListForAccount(tType Type, address string, limit, offset int) ([]Transaction, error) {
+// ListForAccount returns the transactions of an account matching the filter,
+// newest first, along with a cursor to the next page if the page is full.
+func (s *ServiceImpl) ListForAccount(address string, limit, offset int, f ListFilter) ([]Transaction, string, error) {
 	// Check if the input is a valid address
 	address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
 	if err != nil {
-		return []Transaction{}, err
+		return []Transaction{}, "", err
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, "", &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
 	}
 
 	o := datastore.ParseListOptions(limit, offset)
 
-	return s.store.TransactionsForAccount(tType, address, o)
+	tt, err := s.store.TransactionsForAccount(address, o, f)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if o.Limit > 0 && len(tt) == o.Limit {
+		next = encodeCursor(tt[len(tt)-1])
+	}
+
+	return tt, next, nil
 }
 
 // Details returns a specific transaction.
@@ -138,7 +374,10 @@ func (s *ServiceImpl) Details(ctx context.Context, transactionId string) (*Trans
 
 	// Get from datastore
 	transaction, err := s.store.Transaction(transactionId)
-	if err != nil && err.Error() == "record not found" {
+
+	// Transactions of other tenants are not visible
+	tenantID := tenants.FromContext(ctx)
+	if (err != nil && err.Error() == "record not found") || (tenantID != "" && tenantID != transaction.TenantID) {
 		// Convert error to a 404 RequestError
 		err = &errors.RequestError{
 			StatusCode: http.StatusNotFound,
@@ -147,13 +386,10 @@ func (s *ServiceImpl) Details(ctx context.Context, transactionId string) (*Trans
 		return nil, err
 	}
 
-	result, err := s.fc.GetTransactionResult(ctx, flow.HexToID(transactionId))
-	if err != nil {
+	if err := s.transactionResult(ctx, &transaction); err != nil {
 		return nil, err
 	}
 
-	transaction.Events = result.Events
-
 	return &transaction, nil
 }
 
@@ -181,22 +417,90 @@ func (s *ServiceImpl) DetailsForAccount(ctx context.Context, tType Type, address
 		return nil, err
 	}
 
-	result, err := s.fc.GetTransactionResult(ctx, flow.HexToID(transactionId))
-	if err != nil {
+	if err := s.transactionResult(ctx, &transaction); err != nil {
 		return nil, err
 	}
 
+	return &transaction, nil
+}
+
+// transactionResult sets the on-chain result of a transaction, from the
+// database once final or else from the chain.
+func (s *ServiceImpl) transactionResult(ctx context.Context, transaction *Transaction) error {
+	// Not on chain yet
+	if transaction.PendingSend {
+		return nil
+	}
+
+	if isFinalStatus(transaction.Status) {
+		return transaction.decodeEvents()
+	}
+
+	result, err := s.fc.GetTransactionResult(ctx, flow.HexToID(transaction.TransactionId))
+	if err != nil {
+		return err
+	}
+
+	if err := s.recordResult(ctx, transaction, result); err != nil {
+		log.
+			WithFields(log.Fields{"error": err, "transactionId": transaction.TransactionId}).
+			Warn("Recording transaction result failed")
+	}
+
 	transaction.Events = result.Events
 
-	return &transaction, nil
+	return nil
 }
 
-// Execute a script
+// Execute a script of the service itself, not checked against the script
+// allowlist.
 func (s *ServiceImpl) ExecuteScript(ctx context.Context, code string, args []Argument) (cadence.Value, error) {
+	return s.executeScript(ctx, code, args, ScriptBlock{})
+}
+
+// ScriptBlock is the block a script is executed at, the latest sealed block
+// unless a height or a block ID is given.
+type ScriptBlock struct {
+	Height *uint64
+	ID     string
+}
+
+// ExecuteScriptAt executes a script of a client against the state at a
+// block, e.g. to query balances at the end of a day. Only scripts in the
+// script allowlist are executed if the allowlist is enforced.
+func (s *ServiceImpl) ExecuteScriptAt(ctx context.Context, code string, args []Argument, at ScriptBlock) (cadence.Value, error) {
+	if s.cfg.EnforceScriptAllowlist {
+		if err := s.checkCodeAllowed(AllowedScript, code); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.executeScript(ctx, code, args, at)
+}
+
+func (s *ServiceImpl) executeScript(ctx context.Context, code string, args []Argument, at ScriptBlock) (cadence.Value, error) {
+	cc, err := DecodeArguments(args)
+	if err != nil {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	switch {
+	case at.Height != nil && at.ID != "":
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("only one of block height and block ID can be given")}
+	case at.Height != nil:
+		return s.fc.ExecuteScriptAtBlockHeight(ctx, *at.Height, []byte(code), cc)
+	case at.ID != "":
+		b, err := hex.DecodeString(strings.TrimPrefix(at.ID, "0x"))
+		if err != nil || len(b) != len(flow.EmptyID) {
+			return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: fmt.Errorf("invalid block ID: %q", at.ID)}
+		}
+		return s.fc.ExecuteScriptAtBlockID(ctx, flow.BytesToID(b), []byte(code), cc)
+	}
+
 	return s.fc.ExecuteScriptAtLatestBlock(
 		ctx,
 		[]byte(code),
-		MustDecodeArgs(args),
+		cc,
 	)
 }
 
@@ -208,69 +512,169 @@ func (s *ServiceImpl) GetOrCreateTransaction(transactionId string) *Transaction
 	return s.store.GetOrCreateTransaction(transactionId)
 }
 
-func (s *ServiceImpl) buildFlowTransaction(ctx context.Context, proposerAddress, code string, arguments []Argument) (*flow.Transaction, error) {
-	latestBlockID, err := flow_helpers.LatestBlockId(ctx, s.fc)
+func (s *ServiceImpl) buildFlowTransaction(ctx context.Context, proposerAddress, code string, arguments []Argument, o transactionOptions) (*flow.Transaction, error) {
+	// Mismatching arguments would fail on chain, after paying the fees
+	cc, err := DecodeArguments(arguments)
 	if err != nil {
-		return nil, err
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	if errs := checkArgumentTypes(code, cc); len(errs) > 0 {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("arguments do not match the transaction parameters: %s", strings.Join(errs, "; ")),
+		}
 	}
 
-	// Admin should always be the payer of the transaction fees.
-	payer, err := s.km.AdminAuthorizer(ctx)
+	signers, err := s.getSigners(ctx, proposerAddress, o.roles)
 	if err != nil {
-		return nil, fmt.Errorf("error while getting admin authorizer for payer: %w", err)
+		return nil, err
 	}
 
-	proposer, err := s.getProposalAuthorizer(ctx, proposerAddress)
+	flowTx, err := s.unsignedFlowTransaction(ctx, signers, code, arguments, o.gasLimit)
 	if err != nil {
 		return nil, err
 	}
 
-	flowTx := flow.NewTransaction()
-	flowTx.
-		SetReferenceBlockID(*latestBlockID).
-		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
-		SetPayer(payer.Address).
-		SetGasLimit(maxGasLimit).
-		SetScript([]byte(code))
+	if err := signFlowTransaction(flowTx, signers); err != nil {
+		return nil, err
+	}
 
-	for _, arg := range arguments {
-		cv, err := ArgAsCadence(arg)
-		if err != nil {
-			return nil, err
+	return flowTx, nil
+}
+
+// transactionOptions applies opts over the configured defaults.
+func (s *ServiceImpl) transactionOptions(ctx context.Context, opts []TransactionOption) (transactionOptions, error) {
+	o := transactionOptions{gasLimit: s.cfg.TransactionGasLimit}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.gasLimit > s.cfg.MaxTransactionGasLimit {
+		return o, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("gas limit %d exceeds the maximum of %d", o.gasLimit, s.cfg.MaxTransactionGasLimit),
 		}
+	}
+
+	if err := s.checkRoles(ctx, o.roles); err != nil {
+		return o, err
+	}
 
-		err = flowTx.AddArgument(cv)
+	if len(o.metadata) > 0 {
+		m, err := normalizeMetadata(o.metadata)
 		if err != nil {
-			return nil, err
+			return o, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
 		}
+		o.metadata = m
 	}
 
-	// Add authorizers. We assume proposer is always the sole authorizer
-	// https://github.com/flow-hydraulics/flow-wallet-api/issues/79
-	flowTx.AddAuthorizer(proposer.Address)
+	if o.callbackURL != "" {
+		if err := s.checkCallbackURL(o.callbackURL); err != nil {
+			return o, err
+		}
+	}
 
-	// Proposer signs the payload (unless proposer == payer).
-	if !proposer.Equals(payer) {
-		if err := flowTx.SignPayload(proposer.Address, proposer.Key.Index, proposer.Signer); err != nil {
+	return o, nil
+}
+
+// unsignedFlowTransaction builds a transaction with the given signers.
+func (s *ServiceImpl) unsignedFlowTransaction(ctx context.Context, signers *transactionSigners, code string, arguments []Argument, gasLimit uint64) (*flow.Transaction, error) {
+	flowTx := flow.NewTransaction()
+	flowTx.
+		SetGasLimit(gasLimit).
+		SetScript([]byte(code))
+
+	cc, err := DecodeArguments(arguments)
+	if err != nil {
+		return nil, &errors.RequestError{StatusCode: http.StatusBadRequest, Err: err}
+	}
+
+	for _, cv := range cc {
+		if err := flowTx.AddArgument(cv); err != nil {
 			return nil, err
 		}
 	}
 
-	// Payer signs the envelope
-	if err := flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer); err != nil {
+	if err := s.setSigners(ctx, flowTx, signers); err != nil {
 		return nil, err
 	}
 
 	return flowTx, nil
 }
 
-func (s *ServiceImpl) newTransaction(ctx context.Context, proposerAddress string, code string, args []Argument, tType Type) (*Transaction, error) {
+// setSigners sets the latest block as the reference block and the signers of
+// a transaction.
+func (s *ServiceImpl) setSigners(ctx context.Context, flowTx *flow.Transaction, signers *transactionSigners) error {
+	latestBlockID, err := flow_helpers.LatestBlockId(ctx, s.fc)
+	if err != nil {
+		return err
+	}
+
+	proposer, payer := signers.proposer, signers.payer
+
+	flowTx.
+		SetReferenceBlockID(*latestBlockID).
+		SetProposalKey(proposer.Address, proposer.Key.Index, proposer.Key.SequenceNumber).
+		SetPayer(payer.Address)
+
+	for _, a := range signers.authorizers {
+		flowTx.AddAuthorizer(a.Address)
+	}
+
+	return nil
+}
+
+// signFlowTransaction adds the payload signatures of the proposer and the
+// authorizers and the payer's envelope signature to a transaction.
+func signFlowTransaction(flowTx *flow.Transaction, signers *transactionSigners) error {
+	payer := signers.payer
+
+	// Each key signs once, keys of the payer only sign the envelope
+	signed := map[string]bool{fmt.Sprintf("%s-%d", payer.Address, payer.Key.Index): true}
+
+	for _, a := range append([]keys.Authorizer{signers.proposer}, signers.authorizers...) {
+		id := fmt.Sprintf("%s-%d", a.Address, a.Key.Index)
+		if signed[id] {
+			continue
+		}
+		signed[id] = true
+
+		if err := flowTx.SignPayload(a.Address, a.Key.Index, a.Signer); err != nil {
+			return err
+		}
+
+		// Multi-signature accounts need the rest of the signatures as well
+		for _, c := range a.CoSigners {
+			if err := flowTx.SignPayload(a.Address, c.Key.Index, c.Signer); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Payer signs the envelope
+	return flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer)
+}
+
+func (s *ServiceImpl) newTransaction(ctx context.Context, proposerAddress string, code string, args []Argument, tType Type, opts ...TransactionOption) (*Transaction, error) {
+	o, err := s.transactionOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	tx := &Transaction{
 		ProposerAddress: proposerAddress,
 		TransactionType: tType,
+		TenantID:        tenants.FromContext(ctx),
+		AuditID:         audit.FromContext(ctx),
+		Priority:        o.priority,
+		CallbackURL:     o.callbackURL,
+		TemplateName:    o.templateName,
+		TokenName:       o.tokenName,
+		Metadata:        datatypes.JSON(o.metadata),
 	}
 
-	flowTx, err := s.buildFlowTransaction(ctx, proposerAddress, code, args)
+	flowTx, err := s.buildFlowTransaction(ctx, proposerAddress, code, args, o)
 	if err != nil {
 		return nil, fmt.Errorf("error while building transaction: %w", err)
 	}
@@ -281,33 +685,100 @@ func (s *ServiceImpl) newTransaction(ctx context.Context, proposerAddress string
 	return tx, nil
 }
 
-func (s *ServiceImpl) getProposalAuthorizer(ctx context.Context, proposerAddress string) (keys.Authorizer, error) {
-	// Validate the input address.
-	proposerAddress, err := flow_helpers.ValidateAddress(proposerAddress, s.cfg.ChainID)
-	if err != nil {
-		return keys.Authorizer{}, err
+// transactionSigners are the keys signing a transaction in each role.
+type transactionSigners struct {
+	proposer    keys.Authorizer
+	payer       keys.Authorizer
+	authorizers []keys.Authorizer
+}
+
+// getSigners resolves the signers of a transaction. By default the account at
+// address proposes and authorizes the transaction and the admin account pays
+// for it. Every account in a role has to be one the service holds keys for.
+func (s *ServiceImpl) getSigners(ctx context.Context, address string, roles Roles) (*transactionSigners, error) {
+	proposerAddress := address
+	if roles.Proposer != "" {
+		proposerAddress = roles.Proposer
 	}
 
-	var proposer keys.Authorizer
-	if proposerAddress == s.cfg.AdminAddress {
-		proposer, err = s.km.AdminProposalKey(ctx)
+	payerAddress := s.cfg.AdminAddress
+	if roles.Payer != "" {
+		payerAddress = roles.Payer
+	}
+
+	authorizerAddresses := []string{address}
+	if len(roles.Authorizers) > 0 {
+		authorizerAddresses = roles.Authorizers
+	}
+
+	// An account signs with the same key in each of its roles
+	authorizers := make(map[string]keys.Authorizer)
+	getAuthorizer := func(address string) (keys.Authorizer, error) {
+		address, err := flow_helpers.ValidateAddress(address, s.cfg.ChainID)
 		if err != nil {
-			return keys.Authorizer{}, fmt.Errorf("error while getting admin authorizer: %w", err)
+			return keys.Authorizer{}, err
 		}
-	} else {
-		proposer, err = s.km.UserAuthorizer(ctx, flow.HexToAddress(proposerAddress))
+
+		if a, ok := authorizers[address]; ok {
+			return a, nil
+		}
+
+		var a keys.Authorizer
+		if address == s.cfg.AdminAddress {
+			a, err = s.km.AdminAuthorizer(ctx)
+			if err != nil {
+				return keys.Authorizer{}, fmt.Errorf("error while getting admin authorizer: %w", err)
+			}
+		} else {
+			if err := CheckNotFrozen(s.freezeChecker, address); err != nil {
+				return keys.Authorizer{}, err
+			}
+
+			a, err = s.km.UserAuthorizer(ctx, flow.HexToAddress(address))
+			if err != nil {
+				return keys.Authorizer{}, fmt.Errorf("error while getting user authorizer: %w", err)
+			}
+		}
+
+		authorizers[address] = a
+		return a, nil
+	}
+
+	var (
+		result transactionSigners
+		err    error
+	)
+
+	if result.payer, err = getAuthorizer(payerAddress); err != nil {
+		return nil, err
+	}
+
+	for _, a := range authorizerAddresses {
+		authorizer, err := getAuthorizer(a)
 		if err != nil {
-			return keys.Authorizer{}, fmt.Errorf("error while getting user authorizer: %w", err)
+			return nil, err
 		}
+		result.authorizers = append(result.authorizers, authorizer)
+	}
+
+	// The admin account proposes with its dedicated proposal keys
+	proposerAddress, err = flow_helpers.ValidateAddress(proposerAddress, s.cfg.ChainID)
+	if err != nil {
+		return nil, err
 	}
 
-	return proposer, nil
+	if proposerAddress == s.cfg.AdminAddress {
+		if result.proposer, err = s.km.AdminProposalKey(ctx); err != nil {
+			return nil, fmt.Errorf("error while getting admin authorizer: %w", err)
+		}
+	} else if result.proposer, err = getAuthorizer(proposerAddress); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
 
 func (s *ServiceImpl) sendTransaction(ctx context.Context, tx *Transaction) error {
-	// TODO: we should "recreate" the transaction as proposal key sequence numbering
-	// might have gotten out of sync by now (in async situations)
-
 	flowTx, err := flow.DecodeTransaction(tx.FlowTransaction)
 	if err != nil {
 		return err
@@ -315,6 +786,7 @@ func (s *ServiceImpl) sendTransaction(ctx context.Context, tx *Transaction) erro
 
 	// Check if transaction has been sent already.
 	_, err = s.fc.GetTransaction(ctx, flowTx.ID())
+	sent := err == nil
 	if err != nil {
 		rpcErr, ok := err.(grpc.RPCError)
 		if !ok {
@@ -330,10 +802,25 @@ func (s *ServiceImpl) sendTransaction(ctx context.Context, tx *Transaction) erro
 		// The Flow transaction was not found. All good. Continue.
 	}
 
-	// Ratelimit
-	s.txRateLimiter.Take()
+	var resp *flow.TransactionResult
+	if sent {
+		// E.g. by a sync request which timed out, only wait for the seal
+		resp, err = flow_helpers.WaitForSeal(ctx, s.fc, flowTx.ID(), s.cfg.TransactionTimeout, s.waitOptions()...)
+	} else {
+		// Ratelimit
+		s.txRateLimiter.Take()
 
-	resp, err := flow_helpers.SendAndWait(ctx, s.fc, *flowTx, s.cfg.TransactionTimeout)
+		resp, err = flow_helpers.SendAndWait(ctx, s.fc, *flowTx, s.cfg.TransactionTimeout, s.waitOptions()...)
+	}
+	// Expired transactions sent by jobs get rebuilt, the fetcher records
+	// the expiry otherwise
+	if resp != nil && err != flow_helpers.ErrTransactionExpired && !isSequenceNumberError(err) {
+		if err := s.recordResult(ctx, tx, resp); err != nil {
+			log.
+				WithFields(log.Fields{"error": err, "transactionId": tx.TransactionId}).
+				Warn("Recording transaction result failed")
+		}
+	}
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,73 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// Sponsor pays for a client-built transaction signed by its user: the admin
+// account signs the envelope as the payer and the transaction is sent as with
+// SubmitSigned. The admin account only pays, it can not propose or authorize
+// sponsored transactions. Their code has to be in the transaction allowlist,
+// whether the allowlist is enforced otherwise or not, and their gas limit is
+// capped by cfg.MaxSponsoredGasLimit.
+func (s *ServiceImpl) Sponsor(ctx context.Context, sync bool, flowTx flow.Transaction) (*Transaction, error) {
+	admin := flow.HexToAddress(s.cfg.AdminAddress)
+
+	if flowTx.Payer != admin {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("the payer of a sponsored transaction has to be %s", s.cfg.AdminAddress),
+		}
+	}
+
+	if flowTx.ProposalKey.Address == admin {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusForbidden,
+			Err:        fmt.Errorf("the admin account can not propose sponsored transactions"),
+		}
+	}
+
+	for _, a := range flowTx.Authorizers {
+		if a == admin {
+			return nil, &errors.RequestError{
+				StatusCode: http.StatusForbidden,
+				Err:        fmt.Errorf("the admin account can not authorize sponsored transactions"),
+			}
+		}
+	}
+
+	if flowTx.GasLimit > s.cfg.MaxSponsoredGasLimit {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("gas limit %d exceeds the maximum of %d for sponsored transactions", flowTx.GasLimit, s.cfg.MaxSponsoredGasLimit),
+		}
+	}
+
+	if err := s.checkCodeAllowed(AllowedTransaction, string(flowTx.Script)); err != nil {
+		return nil, err
+	}
+
+	// Everything but the payer's envelope signature is up to the user
+	if missing := MissingSigners(flowTx); len(missing) != 1 || missing[0] != admin {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("sponsored transactions have to be signed by their proposer and authorizers, and not by the payer"),
+		}
+	}
+
+	payer, err := s.km.AdminAuthorizer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting admin authorizer: %w", err)
+	}
+
+	if err := flowTx.SignEnvelope(payer.Address, payer.Key.Index, payer.Signer); err != nil {
+		return nil, err
+	}
+
+	return s.sendSigned(ctx, sync, flowTx)
+}
@@ -1,16 +1,46 @@
 package transactions
 
 import (
+	"time"
+
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
 )
 
 // Store manages data regarding transactions.
 type Store interface {
-	Transactions(opt datastore.ListOptions) ([]Transaction, error)
+	Transactions(opt datastore.ListOptions, f ListFilter, tenantID string) ([]Transaction, error)
 	Transaction(txId string) (Transaction, error)
-	TransactionsForAccount(tType Type, address string, opt datastore.ListOptions) ([]Transaction, error)
+	TransactionsForAccount(address string, opt datastore.ListOptions, f ListFilter) ([]Transaction, error)
 	TransactionForAccount(tType Type, address, txId string) (Transaction, error)
 	GetOrCreateTransaction(txId string) *Transaction
 	InsertTransaction(*Transaction) error
 	UpdateTransaction(*Transaction) error
+	// SetTransactionPendingSend updates the pending send flag of a transaction
+	// if it equals from, it tells whether the flag was updated.
+	SetTransactionPendingSend(txId string, from, to bool) (bool, error)
+	// UnfinishedTransactions returns sent transactions created after since
+	// which do not have a final result yet, oldest first.
+	UnfinishedTransactions(since time.Time, limit, offset int) ([]Transaction, error)
+	// UpdateTransactionResult updates the result columns of a transaction
+	// unless a final result was recorded already, it tells whether the
+	// transaction was updated.
+	UpdateTransactionResult(*Transaction) (bool, error)
+	// TransactionEvents returns the stored events of a sealed transaction,
+	// only those of eventType if given.
+	TransactionEvents(txId, eventType string) ([]Event, error)
+	// TransactionFees sums up the fees of sealed transactions matching the
+	// filter per account and day, scoped to the tenant if one is given.
+	TransactionFees(f FeeReportFilter, tenantID string) ([]FeeReportRow, error)
+	// InsertAllowedCode adds an entry to the code allowlist.
+	InsertAllowedCode(*AllowedCode) error
+	// AllowedCode lists the code allowlist entries of a kind, oldest first.
+	AllowedCode(kind string) ([]AllowedCode, error)
+	// IsCodeAllowed tells whether the hash is in the code allowlist.
+	IsCodeAllowed(kind, hash string) (bool, error)
+	// RemoveAllowedCode removes an entry from the code allowlist.
+	RemoveAllowedCode(kind, hash string) error
+	// ReplaceTransaction replaces the Flow transaction of a stored transaction,
+	// and with it the transaction ID of the transaction and its jobs, and
+	// clears its result.
+	ReplaceTransaction(txId string, t *Transaction) error
 }
@@ -1,7 +1,11 @@
 package transactions
 
 import (
+	"time"
+
 	"github.com/flow-hydraulics/flow-wallet-api/datastore"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/onflow/flow-go-sdk"
 	"gorm.io/gorm"
 )
 
@@ -15,14 +19,9 @@ func NewGormStore(db *gorm.DB) Store {
 
 // -- All transactions
 
-func (s *GormStore) Transactions(o datastore.ListOptions) (tt []Transaction, err error) {
-	q := &Transaction{}
-	err = s.db.
-		Where(q).
-		Order("created_at desc").
-		Limit(o.Limit).
-		Offset(o.Offset).
-		Find(&tt).Error
+func (s *GormStore) Transactions(o datastore.ListOptions, f ListFilter, tenantID string) (tt []Transaction, err error) {
+	q := s.db.Where(&Transaction{TenantID: tenantID})
+	err = listTransactions(q, o, f, &tt)
 	return
 }
 
@@ -34,15 +33,54 @@ func (s *GormStore) Transaction(txId string) (t Transaction, err error) {
 
 // -- Transactions for an account
 
-func (s *GormStore) TransactionsForAccount(tType Type, address string, o datastore.ListOptions) (tt []Transaction, err error) {
-	q := &Transaction{ProposerAddress: address, TransactionType: tType}
-	err = s.db.
-		Where(q).
+func (s *GormStore) TransactionsForAccount(address string, o datastore.ListOptions, f ListFilter) (tt []Transaction, err error) {
+	q := s.db.Where(&Transaction{ProposerAddress: address})
+	err = listTransactions(q, o, f, &tt)
+	return
+}
+
+// listTransactions finds the transactions of q matching the filter, newest
+// first.
+func listTransactions(q *gorm.DB, o datastore.ListOptions, f ListFilter, tt *[]Transaction) error {
+	q = q.Where(&Transaction{
+		TransactionType: f.Type,
+		TemplateName:    f.TemplateName,
+		TokenName:       f.TokenName,
+	})
+
+	switch f.Status {
+	case "":
+	case StatusFailed:
+		q = q.Where("(error_message <> '' OR status = ?)", flow.TransactionStatusExpired.String())
+	default:
+		q = q.Where("status = ?", f.Status)
+	}
+
+	if f.CreatedAfter != nil {
+		q = q.Where("created_at >= ?", *f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != nil {
+		q = q.Where("created_at < ?", *f.CreatedBefore)
+	}
+
+	if f.Cursor != "" {
+		c, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return err
+		}
+
+		q = q.Where("(created_at < ? OR (created_at = ? AND transaction_id < ?))", c.CreatedAt, c.CreatedAt, c.TransactionId)
+		o.Offset = 0
+	}
+
+	return q.
+		// Transaction ID as a tiebreaker keeps pagination stable
 		Order("created_at desc").
+		Order("transaction_id desc").
 		Limit(o.Limit).
 		Offset(o.Offset).
-		Find(&tt).Error
-	return
+		Find(tt).Error
 }
 
 func (s *GormStore) TransactionForAccount(tType Type, address, txId string) (t Transaction, err error) {
@@ -68,3 +106,153 @@ func (s *GormStore) InsertTransaction(t *Transaction) error {
 func (s *GormStore) UpdateTransaction(t *Transaction) error {
 	return s.db.Save(t).Error
 }
+
+func (s *GormStore) SetTransactionPendingSend(txId string, from, to bool) (bool, error) {
+	res := s.db.Model(&Transaction{}).
+		Where("transaction_id = ? AND pending_send = ?", txId, from).
+		Update("pending_send", to)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+var finalStatuses = []string{flow.TransactionStatusSealed.String(), flow.TransactionStatusExpired.String(), StatusCancelled}
+
+func (s *GormStore) UnfinishedTransactions(since time.Time, limit, offset int) (tt []Transaction, err error) {
+	err = s.db.
+		Where("pending_send = ? AND created_at >= ?", false, since).
+		Where("(status IS NULL OR status NOT IN ?)", finalStatuses).
+		Order("created_at asc").
+		Limit(limit).
+		Offset(offset).
+		Find(&tt).Error
+	return
+}
+
+func (s *GormStore) UpdateTransactionResult(t *Transaction) (updated bool, err error) {
+	events, err := t.storedEventRecords()
+	if err != nil {
+		return false, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(t).
+			Where("(status IS NULL OR status NOT IN ?)", finalStatuses).
+			Select("status", "error_message", "block_id", "block_height", "events", "fees", "execution_effort").
+			Updates(t)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		updated = res.RowsAffected > 0
+
+		// Events are final once the transaction is sealed
+		if !updated || t.Status != flow.TransactionStatusSealed.String() || len(events) == 0 {
+			return nil
+		}
+
+		return tx.Create(&events).Error
+	})
+
+	return updated, err
+}
+
+func (s *GormStore) TransactionEvents(txId, eventType string) (ee []Event, err error) {
+	err = s.db.
+		Where(&Event{TransactionId: txId, Type: eventType}).
+		Order("event_index asc").
+		Find(&ee).Error
+	return
+}
+
+func (s *GormStore) TransactionFees(f FeeReportFilter, tenantID string) ([]FeeReportRow, error) {
+	q := s.db.
+		Select("transaction_id", "proposer_address", "created_at", "fees", "execution_effort").
+		Where(&Transaction{TenantID: tenantID, ProposerAddress: f.Address}).
+		Where("status = ?", flow.TransactionStatusSealed.String())
+
+	if f.CreatedAfter != nil {
+		q = q.Where("created_at >= ?", *f.CreatedAfter)
+	}
+
+	if f.CreatedBefore != nil {
+		q = q.Where("created_at < ?", *f.CreatedBefore)
+	}
+
+	report := feeReport{}
+	var batch []Transaction
+	err := q.FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+		for _, t := range batch {
+			report.add(t)
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return report.rows(), nil
+}
+
+func (s *GormStore) ReplaceTransaction(txId string, t *Transaction) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Transaction{}).
+			Where("transaction_id = ?", txId).
+			Updates(map[string]interface{}{
+				"transaction_id":   t.TransactionId,
+				"flow_transaction": t.FlowTransaction,
+				"send_attempts":    t.SendAttempts,
+				"status":           "",
+				"error_message":    "",
+				"block_id":         "",
+				"block_height":     0,
+				"events":           nil,
+				"fees":             0,
+				"execution_effort": 0,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// Jobs must not lose their transaction if the job is not updated
+		return tx.Model(&jobs.Job{}).
+			Where("transaction_id = ?", txId).
+			Update("transaction_id", t.TransactionId).Error
+	})
+}
+
+// -- Code allowlist
+
+func (s *GormStore) InsertAllowedCode(c *AllowedCode) error {
+	return s.db.Create(c).Error
+}
+
+func (s *GormStore) AllowedCode(kind string) (cc []AllowedCode, err error) {
+	err = s.db.Where(&AllowedCode{Kind: kind}).Order("created_at asc").Find(&cc).Error
+	return
+}
+
+func (s *GormStore) IsCodeAllowed(kind, hash string) (bool, error) {
+	var count int64
+	err := s.db.Model(&AllowedCode{}).Where(&AllowedCode{Kind: kind, Hash: hash}).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *GormStore) RemoveAllowedCode(kind, hash string) error {
+	res := s.db.Where(&AllowedCode{Kind: kind, Hash: hash}).Delete(&AllowedCode{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package transactions
+
+import (
+	"path"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/datastore/gorm"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+)
+
+func TestReplaceTransaction(t *testing.T) {
+	cfg := &configs.Config{DatabaseType: "sqlite", DatabaseDSN: path.Join(t.TempDir(), "test.db")}
+
+	db, err := gorm.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gorm.Close(db) })
+
+	store := NewGormStore(db)
+	jobStore := jobs.NewGormStore(db)
+
+	if err := store.InsertTransaction(&Transaction{TransactionId: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &jobs.Job{Type: TransactionJobType, State: jobs.Accepted, TransactionID: "old"}
+	if err := jobStore.InsertJob(job); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.ReplaceTransaction("old", &Transaction{TransactionId: "new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Transaction("new"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The job follows the transaction without being updated itself
+	stored, err := jobStore.Job(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.TransactionID != "new" {
+		t.Fatalf("expected the job to have the new transaction ID, got %q", stored.TransactionID)
+	}
+
+	if err := store.ReplaceTransaction("unknown", &Transaction{TransactionId: "other"}); err == nil {
+		t.Fatal("expected an error for an unknown transaction")
+	}
+}
@@ -0,0 +1,80 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/flow-hydraulics/flow-wallet-api/audit"
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// SubmitSigned sends a fully signed client-built transaction, e.g. one
+// co-signed with SignRaw and completed by the client, and stores it so its
+// result is recorded like that of other transactions. Asynchronously the
+// transaction is sent and left to the result fetcher, synchronously the
+// result is waited for. Expired transactions are not rebuilt, the service
+// can not sign for the client.
+func (s *ServiceImpl) SubmitSigned(ctx context.Context, sync bool, flowTx flow.Transaction) (*Transaction, error) {
+	if err := s.CheckTransactionCode(ctx, string(flowTx.Script)); err != nil {
+		return nil, err
+	}
+
+	if missing := MissingSigners(flowTx); len(missing) > 0 {
+		addresses := make([]string, len(missing))
+		for i, a := range missing {
+			addresses[i] = flow_helpers.FormatAddress(a)
+		}
+
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusBadRequest,
+			Err:        fmt.Errorf("transaction is missing signatures of: %s", strings.Join(addresses, ", ")),
+		}
+	}
+
+	return s.sendSigned(ctx, sync, flowTx)
+}
+
+// sendSigned stores and sends a fully signed transaction.
+func (s *ServiceImpl) sendSigned(ctx context.Context, sync bool, flowTx flow.Transaction) (*Transaction, error) {
+	transactionId := flowTx.ID().Hex()
+
+	if _, err := s.store.Transaction(transactionId); err == nil {
+		return nil, &errors.RequestError{
+			StatusCode: http.StatusConflict,
+			Err:        fmt.Errorf("transaction already submitted"),
+		}
+	}
+
+	transaction := &Transaction{
+		TransactionId:   transactionId,
+		TransactionType: General,
+		ProposerAddress: flow_helpers.FormatAddress(flowTx.ProposalKey.Address),
+		FlowTransaction: flowTx.Encode(),
+		TenantID:        tenants.FromContext(ctx),
+		AuditID:         audit.FromContext(ctx),
+	}
+
+	if err := s.store.InsertTransaction(transaction); err != nil {
+		return nil, fmt.Errorf("error while inserting transaction in db: %w", err)
+	}
+
+	if sync {
+		if err := s.submitSync(ctx, transaction, s.sendTransaction); err != nil {
+			return nil, err
+		}
+		return transaction, nil
+	}
+
+	s.txRateLimiter.Take()
+
+	if err := s.fc.SendTransaction(ctx, flowTx); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
@@ -0,0 +1,85 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+)
+
+// AccountTenants resolves the tenant an account belongs to.
+type AccountTenants interface {
+	AccountTenant(address string) (string, error)
+}
+
+var errAccountNotFound = &errors.RequestError{StatusCode: http.StatusNotFound, Err: fmt.Errorf("account not found")}
+
+// checkAccountTenant returns a 404 RequestError if the account does not
+// belong to the tenant of a tenant-scoped request. The admin account belongs
+// to no tenant.
+func (s *ServiceImpl) checkAccountTenant(ctx context.Context, address string) error {
+	tenantID := tenants.FromContext(ctx)
+	if tenantID == "" || s.accountTenants == nil {
+		return nil
+	}
+
+	accountTenantID, err := s.accountTenants.AccountTenant(address)
+	if err != nil {
+		if err.Error() == "record not found" {
+			return errAccountNotFound
+		}
+		return err
+	}
+
+	if address == s.cfg.AdminAddress || accountTenantID != tenantID {
+		return errAccountNotFound
+	}
+
+	return nil
+}
+
+// checkRoles checks the accounts a request selected for the roles of a
+// transaction. A tenant can only select its own accounts, apart from the
+// admin account proposing or paying, and the admin account never authorizes
+// transactions of the client, like with SignRaw.
+func (s *ServiceImpl) checkRoles(ctx context.Context, roles Roles) error {
+	for _, a := range []string{roles.Proposer, roles.Payer} {
+		if a == "" {
+			continue
+		}
+
+		address, err := flow_helpers.ValidateAddress(a, s.cfg.ChainID)
+		if err != nil {
+			return err
+		}
+
+		if address != s.cfg.AdminAddress {
+			if err := s.checkAccountTenant(ctx, address); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, a := range roles.Authorizers {
+		address, err := flow_helpers.ValidateAddress(a, s.cfg.ChainID)
+		if err != nil {
+			return err
+		}
+
+		if address == s.cfg.AdminAddress {
+			return &errors.RequestError{
+				StatusCode: http.StatusForbidden,
+				Err:        fmt.Errorf("transactions of the client can not be authorized by the admin account"),
+			}
+		}
+
+		if err := s.checkAccountTenant(ctx, address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
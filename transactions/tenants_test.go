@@ -0,0 +1,112 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	wallet_errors "github.com/flow-hydraulics/flow-wallet-api/errors"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/onflow/flow-go-sdk"
+)
+
+type dummyAccountTenants map[string]string
+
+func (t dummyAccountTenants) AccountTenant(address string) (string, error) {
+	tenantID, ok := t[address]
+	if !ok {
+		return "", fmt.Errorf("record not found")
+	}
+	return tenantID, nil
+}
+
+func Test_CheckRoles(t *testing.T) {
+	const (
+		admin   = "0xf8d6e0586b0a20c7"
+		own     = "0x01cf0e2f2f715450"
+		other   = "0x179b6b1cb6755e31"
+		unknown = "0xf3fcd2c1a78f5eee"
+	)
+
+	svc := &ServiceImpl{
+		cfg:            &configs.Config{ChainID: flow.Emulator, AdminAddress: admin, MaxTransactionGasLimit: 9999},
+		accountTenants: dummyAccountTenants{admin: "", own: "tenant-a", other: "tenant-b"},
+	}
+
+	tenantCtx := tenants.NewContext(context.Background(), "tenant-a")
+
+	testCases := []struct {
+		name   string
+		ctx    context.Context
+		roles  Roles
+		status int
+	}{
+		{name: "own accounts", ctx: tenantCtx, roles: Roles{Proposer: own, Payer: own, Authorizers: []string{own}}},
+		{name: "admin payer", ctx: tenantCtx, roles: Roles{Payer: admin, Authorizers: []string{own}}},
+		{name: "authorizer of another tenant", ctx: tenantCtx, roles: Roles{Authorizers: []string{own, other}}, status: http.StatusNotFound},
+		{name: "proposer of another tenant", ctx: tenantCtx, roles: Roles{Proposer: other}, status: http.StatusNotFound},
+		{name: "payer of another tenant", ctx: tenantCtx, roles: Roles{Payer: other}, status: http.StatusNotFound},
+		{name: "unknown authorizer", ctx: tenantCtx, roles: Roles{Authorizers: []string{unknown}}, status: http.StatusNotFound},
+		{name: "admin proposer", ctx: tenantCtx, roles: Roles{Proposer: admin, Authorizers: []string{own}}},
+		{name: "admin authorizer", ctx: tenantCtx, roles: Roles{Authorizers: []string{admin}}, status: http.StatusForbidden},
+		{name: "admin authorizer without tenants", ctx: context.Background(), roles: Roles{Authorizers: []string{own, admin}}, status: http.StatusForbidden},
+		{name: "any account without tenants", ctx: context.Background(), roles: Roles{Proposer: other, Payer: own, Authorizers: []string{other}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.transactionOptions(tc.ctx, []TransactionOption{WithRoles(tc.roles)})
+
+			if tc.status == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %s", err)
+				}
+				return
+			}
+
+			var reqErr *wallet_errors.RequestError
+			if !errors.As(err, &reqErr) || reqErr.StatusCode != tc.status {
+				t.Fatalf("expected status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
+
+func Test_BuildTenantProposer(t *testing.T) {
+	const (
+		admin = "0xf8d6e0586b0a20c7"
+		other = "0x179b6b1cb6755e31"
+		own   = "0x01cf0e2f2f715450"
+	)
+
+	svc := &ServiceImpl{
+		cfg:            &configs.Config{ChainID: flow.Emulator, AdminAddress: admin, MaxTransactionGasLimit: 9999},
+		accountTenants: dummyAccountTenants{admin: "", own: "tenant-a", other: "tenant-b"},
+	}
+
+	ctx := tenants.NewContext(context.Background(), "tenant-a")
+
+	testCases := []struct {
+		name     string
+		proposer string
+		roles    Roles
+	}{
+		{name: "proposer of another tenant", proposer: other},
+		{name: "admin proposer", proposer: admin},
+		{name: "authorizer of another tenant", proposer: own, roles: Roles{Authorizers: []string{other}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.Build(ctx, tc.proposer, "transaction {}", nil, General, WithRoles(tc.roles))
+
+			var reqErr *wallet_errors.RequestError
+			if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusNotFound {
+				t.Fatalf("expected status %d, got %v", http.StatusNotFound, err)
+			}
+		})
+	}
+}
@@ -1,15 +1,20 @@
 package transactions
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/google/uuid"
 	"github.com/onflow/flow-go-sdk"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
-const maxGasLimit = 9999
-
 type SignedTransaction struct {
 	flow.Transaction
 }
@@ -25,6 +30,11 @@ type SignedTransactionJSONResponse struct {
 	Authorizers        []string                   `json:"authorizers"`
 	PayloadSignatures  []TransactionSignatureJSON `json:"payloadSignatures"`
 	EnvelopeSignatures []TransactionSignatureJSON `json:"envelopeSignatures"`
+	// Encoded is the hex encoded RLP of the transaction, signatures included
+	Encoded string `json:"encoded,omitempty"`
+	// MissingSigners are the accounts which still have to sign the
+	// transaction before it can be sent
+	MissingSigners []string `json:"missingSigners,omitempty"`
 }
 
 type CadenceArgument interface{}
@@ -77,9 +87,110 @@ func (st *SignedTransaction) ToJSONResponse() (SignedTransactionJSONResponse, er
 		res.EnvelopeSignatures = append(res.EnvelopeSignatures, sig)
 	}
 
+	res.Encoded = hex.EncodeToString(st.Encode())
+
+	for _, a := range MissingSigners(st.Transaction) {
+		res.MissingSigners = append(res.MissingSigners, a.Hex())
+	}
+
 	return res, nil
 }
 
+// MissingSigners returns the accounts which have not signed the transaction
+// yet: the proposer and the authorizers sign the payload, unless they are the
+// payer, and the payer signs the envelope.
+func MissingSigners(tx flow.Transaction) []flow.Address {
+	var missing []flow.Address
+
+	seen := map[flow.Address]bool{tx.Payer: true}
+	for _, a := range append([]flow.Address{tx.ProposalKey.Address}, tx.Authorizers...) {
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+
+		if !hasSignature(tx.PayloadSignatures, a) {
+			missing = append(missing, a)
+		}
+	}
+
+	if !hasSignature(tx.EnvelopeSignatures, tx.Payer) {
+		missing = append(missing, tx.Payer)
+	}
+
+	return missing
+}
+
+func hasSignature(sigs []flow.TransactionSignature, a flow.Address) bool {
+	for _, s := range sigs {
+		if s.Address == a {
+			return true
+		}
+	}
+	return false
+}
+
+// ToFlowTransaction converts the JSON form of a transaction back to a
+// flow.Transaction, signatures included.
+func (r SignedTransactionJSONResponse) ToFlowTransaction() (*flow.Transaction, error) {
+	referenceBlockID, err := hex.DecodeString(strings.TrimPrefix(r.ReferenceBlockID, "0x"))
+	if err != nil || len(referenceBlockID) != len(flow.EmptyID) {
+		return nil, fmt.Errorf("invalid reference block ID: %q", r.ReferenceBlockID)
+	}
+
+	flowTx := flow.NewTransaction().
+		SetScript([]byte(r.Code)).
+		SetReferenceBlockID(flow.BytesToID(referenceBlockID)).
+		SetGasLimit(r.GasLimit).
+		SetProposalKey(flow.HexToAddress(r.ProposalKey.Address), r.ProposalKey.KeyIndex, r.ProposalKey.SequenceNumber).
+		SetPayer(flow.HexToAddress(r.Payer))
+
+	flowTx.Arguments = r.Arguments
+
+	for _, a := range r.Authorizers {
+		flowTx.AddAuthorizer(flow.HexToAddress(a))
+	}
+
+	// Signatures are added last, the signer indexes depend on the roles above
+	for _, sig := range r.PayloadSignatures {
+		b, err := hex.DecodeString(strings.TrimPrefix(sig.Signature, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid payload signature: %w", err)
+		}
+		flowTx.AddPayloadSignature(flow.HexToAddress(sig.Address), sig.KeyIndex, b)
+	}
+
+	for _, sig := range r.EnvelopeSignatures {
+		b, err := hex.DecodeString(strings.TrimPrefix(sig.Signature, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid envelope signature: %w", err)
+		}
+		flowTx.AddEnvelopeSignature(flow.HexToAddress(sig.Address), sig.KeyIndex, b)
+	}
+
+	return flowTx, nil
+}
+
+// DecodeRawTransaction decodes a client-built transaction given either as a
+// hex encoded RLP string or in the JSON form of SignedTransactionJSONResponse.
+func DecodeRawTransaction(raw json.RawMessage) (*flow.Transaction, error) {
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		b, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction encoding: %w", err)
+		}
+		return flow.DecodeTransaction(b)
+	}
+
+	var r SignedTransactionJSONResponse
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	return r.ToFlowTransaction()
+}
+
 // Transaction is the database model for all transactions.
 type Transaction struct {
 	TransactionId   string         `gorm:"column:transaction_id;primaryKey"`
@@ -89,34 +200,253 @@ type Transaction struct {
 	CreatedAt       time.Time      `gorm:"column:created_at"`
 	UpdatedAt       time.Time      `gorm:"column:updated_at"`
 	DeletedAt       gorm.DeletedAt `gorm:"column:deleted_at;index"`
-	Events          []flow.Event   `gorm:"-"`
+	TenantID        string         `gorm:"column:tenant_id;index"`
+	// AuditID is the audit log entry of the request which created the
+	// transaction, see audit.Entry
+	AuditID *uuid.UUID `gorm:"column:audit_id;type:uuid;index"`
+	// Priority of the jobs sending the transaction
+	Priority jobs.Priority `gorm:"column:priority;not null;default:0"`
+	// PendingSend is set for built transactions not yet sent to the chain
+	PendingSend bool `gorm:"column:pending_send;not null;default:false"`
+	// Result of the transaction on chain, see FetchResults
+	Status       string         `gorm:"column:status;index"`
+	ErrorMessage string         `gorm:"column:error_message"`
+	BlockID      string         `gorm:"column:block_id"`
+	BlockHeight  uint64         `gorm:"column:block_height"`
+	StoredEvents datatypes.JSON `gorm:"column:events"`
+	Events       []flow.Event   `gorm:"-"`
+	// Fees and ExecutionEffort are raw UFix64 values recorded from the
+	// FlowFees events once the transaction is sealed
+	Fees            uint64 `gorm:"column:fees"`
+	ExecutionEffort uint64 `gorm:"column:execution_effort"`
+	// CallbackURL is notified once the result is final
+	CallbackURL  string `gorm:"column:callback_url"`
+	TemplateName string `gorm:"column:template_name;index"`
+	TokenName    string `gorm:"column:token_name;index"`
+	// Metadata is a free-form JSON object given on creation
+	Metadata datatypes.JSON `gorm:"column:metadata"`
+	// SendAttempts records the earlier attempts of sending the transaction
+	// which were rebuilt and sent again, see SendAttempt
+	SendAttempts datatypes.JSON `gorm:"column:send_attempts"`
+}
+
+// SendAttempt is an attempt of sending a transaction which failed and was
+// retried with a rebuilt transaction.
+type SendAttempt struct {
+	TransactionId string    `json:"transactionId"`
+	Error         string    `json:"error"`
+	At            time.Time `json:"at"`
 }
 
 func (Transaction) TableName() string {
 	return "transactions"
 }
 
+// maxMetadataSize is the maximum size of the metadata of a transaction in
+// bytes.
+const maxMetadataSize = 4096
+
+// normalizeMetadata checks that transaction metadata is a JSON object and
+// returns it compacted, null is no metadata.
+func normalizeMetadata(m json.RawMessage) (json.RawMessage, error) {
+	if len(m) > maxMetadataSize {
+		return nil, fmt.Errorf("metadata exceeds the maximum size of %d bytes", maxMetadataSize)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(m, &obj); err != nil {
+		return nil, fmt.Errorf("metadata must be a JSON object")
+	}
+
+	if obj == nil {
+		return nil, nil
+	}
+
+	var b bytes.Buffer
+	if err := json.Compact(&b, m); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
 // Transaction JSON HTTP request
 type JSONRequest struct {
 	Code      string     `json:"code"`
 	Arguments []Argument `json:"arguments"`
+	// GasLimit overrides the configured default gas limit if set
+	GasLimit uint64 `json:"gasLimit"`
+	Roles    *Roles `json:"roles"`
+	// CallbackURL is notified once the transaction is sealed or fails
+	CallbackURL string `json:"callbackUrl"`
+	// Metadata is a free-form JSON object stored with the transaction
+	Metadata json.RawMessage `json:"metadata"`
+	// Priority of the job sending the transaction, "low", "normal" or "high"
+	Priority jobs.Priority `json:"priority"`
+}
+
+// Roles selects the accounts signing a transaction in each role. By default
+// the account sending the transaction proposes and authorizes it and the
+// admin account pays for it.
+type Roles struct {
+	Proposer    string   `json:"proposer,omitempty"`
+	Payer       string   `json:"payer,omitempty"`
+	Authorizers []string `json:"authorizers,omitempty"`
+}
+
+// Options returns the transaction options of the request.
+func (r JSONRequest) Options() []TransactionOption {
+	var opts []TransactionOption
+	if r.GasLimit > 0 {
+		opts = append(opts, WithGasLimit(r.GasLimit))
+	}
+	if r.Roles != nil {
+		opts = append(opts, WithRoles(*r.Roles))
+	}
+	if r.CallbackURL != "" {
+		opts = append(opts, WithCallbackURL(r.CallbackURL))
+	}
+	if len(r.Metadata) > 0 {
+		opts = append(opts, WithMetadata(r.Metadata))
+	}
+	if r.Priority != jobs.PriorityNormal {
+		opts = append(opts, WithPriority(r.Priority))
+	}
+	return opts
+}
+
+// Transaction build JSON HTTP request
+type BuildJSONRequest struct {
+	JSONRequest
+	Proposer string `json:"proposer"`
+}
+
+// Transaction signing JSON HTTP request, either code and arguments for a new
+// transaction or a client-built transaction (hex encoded RLP or JSON form)
+type SignJSONRequest struct {
+	JSONRequest
+	Transaction json.RawMessage `json:"transaction"`
+}
+
+// Signed transaction submission JSON HTTP request, a client-built
+// transaction signed by the client (hex encoded RLP or JSON form)
+type SubmitSignedJSONRequest struct {
+	Transaction json.RawMessage `json:"transaction"`
 }
 
 // Transaction JSON HTTP response
 type JSONResponse struct {
-	TransactionId   string       `json:"transactionId"`
-	TransactionType Type         `json:"transactionType"`
-	Events          []flow.Event `json:"events,omitempty"`
-	CreatedAt       time.Time    `json:"createdAt"`
-	UpdatedAt       time.Time    `json:"updatedAt"`
+	TransactionId   string                     `json:"transactionId"`
+	TransactionType Type                       `json:"transactionType"`
+	PendingSend     bool                       `json:"pendingSend,omitempty"`
+	Status          string                     `json:"status,omitempty"`
+	Error           string                     `json:"error,omitempty"`
+	BlockID         string                     `json:"blockId,omitempty"`
+	BlockHeight     uint64                     `json:"blockHeight,omitempty"`
+	Events          []flow.Event               `json:"events,omitempty"`
+	DecodedEvents   []DecodedEventJSONResponse `json:"decodedEvents,omitempty"`
+	Fees            string                     `json:"fees,omitempty"`
+	ExecutionEffort string                     `json:"executionEffort,omitempty"`
+	CallbackURL     string                     `json:"callbackUrl,omitempty"`
+	TemplateName    string                     `json:"templateName,omitempty"`
+	TokenName       string                     `json:"tokenName,omitempty"`
+	Metadata        json.RawMessage            `json:"metadata,omitempty"`
+	Attempts        []SendAttempt              `json:"attempts,omitempty"`
+	AuditID         *uuid.UUID                 `json:"auditId,omitempty"`
+	CreatedAt       time.Time                  `json:"createdAt"`
+	UpdatedAt       time.Time                  `json:"updatedAt"`
 }
 
 func (t Transaction) ToJSONResponse() JSONResponse {
 	return JSONResponse{
 		TransactionId:   t.TransactionId,
 		TransactionType: t.TransactionType,
+		PendingSend:     t.PendingSend,
+		Status:          t.Status,
+		Error:           t.ErrorMessage,
+		BlockID:         t.BlockID,
+		BlockHeight:     t.BlockHeight,
 		Events:          t.Events,
+		DecodedEvents:   decodedEvents(t.Events),
+		Fees:            t.feesString(),
+		ExecutionEffort: t.executionEffortString(),
+		CallbackURL:     t.CallbackURL,
+		TemplateName:    t.TemplateName,
+		TokenName:       t.TokenName,
+		Metadata:        json.RawMessage(t.Metadata),
+		Attempts:        t.attempts(),
+		AuditID:         t.AuditID,
 		CreatedAt:       t.CreatedAt,
 		UpdatedAt:       t.UpdatedAt,
 	}
 }
+
+// feesString formats the fees of a sealed transaction as UFix64.
+func (t Transaction) feesString() string {
+	if t.Status != flow.TransactionStatusSealed.String() {
+		return ""
+	}
+	return formatUFix64(t.Fees)
+}
+
+// executionEffortString formats the execution effort of a sealed transaction
+// as UFix64, if reported.
+func (t Transaction) executionEffortString() string {
+	if t.ExecutionEffort == 0 {
+		return ""
+	}
+	return formatUFix64(t.ExecutionEffort)
+}
+
+// attempts decodes the stored send attempts of the transaction.
+func (t Transaction) attempts() []SendAttempt {
+	var aa []SendAttempt
+	if len(t.SendAttempts) == 0 {
+		return aa
+	}
+
+	if err := json.Unmarshal(t.SendAttempts, &aa); err != nil {
+		return nil
+	}
+
+	return aa
+}
+
+// addAttempt records a failed attempt of sending the transaction.
+func (t *Transaction) addAttempt(err error) error {
+	aa := append(t.attempts(), SendAttempt{
+		TransactionId: t.TransactionId,
+		Error:         err.Error(),
+		At:            time.Now(),
+	})
+
+	b, err := json.Marshal(aa)
+	if err != nil {
+		return err
+	}
+
+	t.SendAttempts = b
+
+	return nil
+}
+
+// Built transaction JSON HTTP response, includes the signed transaction for inspection
+type BuiltJSONResponse struct {
+	JSONResponse
+	Transaction SignedTransactionJSONResponse `json:"transaction"`
+}
+
+func (t Transaction) ToBuiltJSONResponse() (BuiltJSONResponse, error) {
+	flowTx, err := flow.DecodeTransaction(t.FlowTransaction)
+	if err != nil {
+		return BuiltJSONResponse{}, err
+	}
+
+	signed := SignedTransaction{Transaction: *flowTx}
+	tx, err := signed.ToJSONResponse()
+	if err != nil {
+		return BuiltJSONResponse{}, err
+	}
+
+	return BuiltJSONResponse{t.ToJSONResponse(), tx}, nil
+}
@@ -0,0 +1,88 @@
+package transactions
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+func Test_NormalizeMetadata(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{name: "object", input: `{ "invoiceId": "INV-1",  "lines": [1, 2] }`, expected: `{"invoiceId":"INV-1","lines":[1,2]}`},
+		{name: "empty object", input: `{}`, expected: `{}`},
+		{name: "null", input: `null`, expected: ``},
+		{name: "array", input: `["INV-1"]`, err: true},
+		{name: "string", input: `"INV-1"`, err: true},
+		{name: "too large", input: `{"a":"` + strings.Repeat("x", maxMetadataSize) + `"}`, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := normalizeMetadata(json.RawMessage(tc.input))
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(m) != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, string(m))
+			}
+		})
+	}
+}
+
+func Test_MissingSigners(t *testing.T) {
+	proposer, authorizer, payer := flow.HexToAddress("01"), flow.HexToAddress("02"), flow.HexToAddress("03")
+
+	tx := flow.NewTransaction().
+		SetScript([]byte("transaction {}")).
+		SetProposalKey(proposer, 0, 0).
+		SetPayer(payer).
+		AddAuthorizer(proposer).
+		AddAuthorizer(authorizer)
+
+	expect := func(expected ...flow.Address) {
+		t.Helper()
+
+		missing := MissingSigners(*tx)
+		if len(missing) != len(expected) {
+			t.Fatalf("expected %v to be missing, got %v", expected, missing)
+		}
+		for i := range expected {
+			if missing[i] != expected[i] {
+				t.Fatalf("expected %v to be missing, got %v", expected, missing)
+			}
+		}
+	}
+
+	expect(proposer, authorizer, payer)
+
+	tx.AddPayloadSignature(authorizer, 0, []byte{1})
+	expect(proposer, payer)
+
+	tx.AddPayloadSignature(proposer, 0, []byte{1})
+	expect(payer)
+
+	tx.AddEnvelopeSignature(payer, 0, []byte{1})
+	expect()
+
+	// A payer which also proposes only signs the envelope
+	tx = flow.NewTransaction().SetProposalKey(payer, 0, 0).SetPayer(payer)
+	expect(payer)
+
+	tx.AddEnvelopeSignature(payer, 0, []byte{1})
+	expect()
+}
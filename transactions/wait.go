@@ -0,0 +1,93 @@
+package transactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flow-hydraulics/flow-wallet-api/flow_helpers"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/flow-hydraulics/flow-wallet-api/tenants"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// PendingError is returned for sync requests of transactions which are sent
+// but not sealed within cfg.SyncTransactionTimeout. Job waits for the result
+// of the transaction instead.
+type PendingError struct {
+	Job *jobs.Job
+}
+
+func (e *PendingError) Error() string {
+	return fmt.Sprintf("transaction not sealed in time, job %s waits for the result", e.Job.ID)
+}
+
+// RevertedError is returned for sync requests and jobs of transactions which
+// were sealed with an error. Transaction holds the sealed result.
+type RevertedError struct {
+	Transaction *Transaction
+	Err         error
+}
+
+func (e *RevertedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RevertedError) Unwrap() error {
+	return e.Err
+}
+
+// isSentError reports whether err was returned for a sync request of a
+// transaction which did reach the chain.
+func isSentError(err error) bool {
+	switch err.(type) {
+	case *PendingError, *RevertedError:
+		return true
+	}
+	return false
+}
+
+// waitOptions configures waiting for transaction seals.
+func (s *ServiceImpl) waitOptions() []flow_helpers.WaitOption {
+	return []flow_helpers.WaitOption{
+		flow_helpers.WithInitialDelay(s.cfg.TransactionWaitInitialDelay),
+		flow_helpers.WithBackoff(s.cfg.TransactionWaitMinInterval, s.cfg.TransactionWaitMaxInterval, s.cfg.TransactionWaitBackoffFactor),
+	}
+}
+
+// submitSync submits a transaction for a sync request. If the transaction is
+// not sealed within cfg.SyncTransactionTimeout a job is scheduled to wait for
+// it and returned in a PendingError, rather than holding up the request. A
+// reverted transaction is returned in a RevertedError.
+func (s *ServiceImpl) submitSync(ctx context.Context, tx *Transaction, submit func(context.Context, *Transaction) error) error {
+	submitCtx := ctx
+	if s.cfg.SyncTransactionTimeout > 0 {
+		var cancel context.CancelFunc
+		submitCtx, cancel = context.WithTimeout(ctx, s.cfg.SyncTransactionTimeout)
+		defer cancel()
+	}
+
+	err := submit(submitCtx, tx)
+	if err != nil && tx.Status == flow.TransactionStatusSealed.String() && tx.ErrorMessage != "" {
+		return &RevertedError{Transaction: tx, Err: err}
+	}
+	if !errors.Is(err, flow_helpers.ErrSealTimeout) {
+		return err
+	}
+
+	job, err := s.wp.CreateJob(
+		TransactionJobType,
+		tx.TransactionId,
+		jobs.WithTenantID(tenants.FromContext(ctx)),
+		jobs.WithPriority(tx.Priority),
+	)
+	if err != nil {
+		return fmt.Errorf("error while creating job: %w", err)
+	}
+
+	if err := s.wp.Schedule(job); err != nil {
+		return fmt.Errorf("error while scheduling job: %w", err)
+	}
+
+	return &PendingError{Job: job}
+}
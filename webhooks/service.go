@@ -0,0 +1,186 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const SendWebhookJobType = "send_webhook"
+
+// Service sends webhook notifications.
+type Service interface {
+	// Notify schedules delivery of the event to all configured endpoints.
+	// Delivery is retried by the workerpool, errors are only logged.
+	Notify(event Event, data interface{})
+	// NotifyEndpoint schedules delivery of the event to the given endpoint
+	// only, e.g. a callback URL given in a request.
+	NotifyEndpoint(endpoint string, event Event, data interface{})
+	// Deliver sends a payload encoded with Encode to the endpoint right
+	// away, e.g. from an outbox keeping track of the deliveries itself.
+	Deliver(ctx context.Context, endpoint string, payload []byte) error
+	// Endpoints returns the configured endpoints.
+	Endpoints() []string
+}
+
+// ServiceImpl implements Service.
+type ServiceImpl struct {
+	endpoints []string
+	secret    []byte
+	timeout   time.Duration
+	wp        jobs.WorkerPool
+}
+
+type sendWebhookJobAttributes struct {
+	Endpoint string          `json:"endpoint"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// NewService initiates a new webhook service. Without configured endpoints
+// all notifications are dropped.
+func NewService(cfg *configs.Config, wp jobs.WorkerPool) Service {
+	for _, e := range cfg.WebhookEndpoints {
+		if _, err := url.ParseRequestURI(e); err != nil {
+			panic(fmt.Sprintf("invalid webhook endpoint: %s", e))
+		}
+	}
+
+	// Signatures keyed with an empty secret could be forged by anyone
+	if len(cfg.WebhookEndpoints) > 0 && cfg.WebhookSecret == "" {
+		panic("webhook secret required with webhook endpoints")
+	}
+
+	svc := &ServiceImpl{
+		endpoints: cfg.WebhookEndpoints,
+		secret:    []byte(cfg.WebhookSecret),
+		timeout:   cfg.WebhookTimeout,
+		wp:        wp,
+	}
+
+	wp.RegisterExecutor(SendWebhookJobType, svc.executeSendWebhookJob)
+
+	return svc
+}
+
+func (s *ServiceImpl) Notify(event Event, data interface{}) {
+	if len(s.endpoints) == 0 {
+		return
+	}
+
+	s.notify(s.endpoints, event, data)
+}
+
+func (s *ServiceImpl) NotifyEndpoint(endpoint string, event Event, data interface{}) {
+	s.notify([]string{endpoint}, event, data)
+}
+
+func (s *ServiceImpl) Deliver(ctx context.Context, endpoint string, payload []byte) error {
+	return s.send(ctx, endpoint, payload)
+}
+
+func (s *ServiceImpl) Endpoints() []string {
+	return s.endpoints
+}
+
+// Encode returns the payload of an event with the given ID, which receivers
+// can use to tell deliveries of the same event apart from new events.
+func Encode(id string, event Event, data interface{}) ([]byte, error) {
+	return json.Marshal(Payload{
+		ID:        id,
+		Event:     event,
+		CreatedAt: time.Now(),
+		Data:      data,
+	})
+}
+
+func (s *ServiceImpl) notify(endpoints []string, event Event, data interface{}) {
+	entry := log.WithFields(log.Fields{"event": event, "function": "webhooks.Notify"})
+
+	b, err := Encode(uuid.New().String(), event, data)
+	if err != nil {
+		entry.WithFields(log.Fields{"error": err}).Error("Unable to encode webhook payload")
+		return
+	}
+
+	// One job per endpoint so that retries are independent
+	for _, endpoint := range endpoints {
+		if err := s.schedule(endpoint, b); err != nil {
+			entry.WithFields(log.Fields{"error": err, "endpoint": endpoint}).Error("Unable to schedule webhook")
+		}
+	}
+}
+
+func (s *ServiceImpl) schedule(endpoint string, payload []byte) error {
+	attrBytes, err := json.Marshal(sendWebhookJobAttributes{Endpoint: endpoint, Payload: payload})
+	if err != nil {
+		return err
+	}
+
+	job, err := s.wp.CreateJob(SendWebhookJobType, "", jobs.WithAttributes(attrBytes))
+	if err != nil {
+		return err
+	}
+
+	return s.wp.Schedule(job)
+}
+
+func (s *ServiceImpl) executeSendWebhookJob(ctx context.Context, j *jobs.Job) error {
+	if j.Type != SendWebhookJobType {
+		return jobs.ErrInvalidJobType
+	}
+
+	j.ShouldSendNotification = false
+
+	var attrs sendWebhookJobAttributes
+	if err := json.Unmarshal(j.Attributes, &attrs); err != nil {
+		return err
+	}
+
+	return s.send(ctx, attrs.Endpoint, attrs.Payload)
+}
+
+func (s *ServiceImpl) send(ctx context.Context, endpoint string, payload []byte) error {
+	client := http.Client{
+		Timeout: s.timeout,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("error while creating webhook request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(SignatureHeader, Sign(s.secret, payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while sending webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint responded with an unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload. Receivers should
+// compare it against the SignatureHeader of the request in constant time.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,204 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/flow-hydraulics/flow-wallet-api/configs"
+	"github.com/flow-hydraulics/flow-wallet-api/jobs"
+)
+
+// dummyWorkerPool runs scheduled jobs right away, the methods which are not
+// implemented panic.
+type dummyWorkerPool struct {
+	jobs.WorkerPool
+	executors map[string]jobs.ExecutorFunc
+	errors    []error
+}
+
+func (wp *dummyWorkerPool) RegisterExecutor(jobType string, executorF jobs.ExecutorFunc) {
+	wp.executors[jobType] = executorF
+}
+
+func (wp *dummyWorkerPool) CreateJob(jobType, txID string, opts ...jobs.JobOption) (*jobs.Job, error) {
+	job := &jobs.Job{Type: jobType, TransactionID: txID}
+	for _, opt := range opts {
+		opt(job)
+	}
+	return job, nil
+}
+
+func (wp *dummyWorkerPool) Schedule(j *jobs.Job) error {
+	wp.errors = append(wp.errors, wp.executors[j.Type](context.Background(), j))
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	secret := "secret"
+
+	var (
+		received  []Payload
+		signature string
+	)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if signature = r.Header.Get(SignatureHeader); signature != Sign([]byte(secret), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var p Payload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, p)
+	}))
+	defer svr.Close()
+
+	cfg := &configs.Config{
+		WebhookEndpoints: []string{svr.URL, svr.URL + "/other"},
+		WebhookSecret:    secret,
+		WebhookTimeout:   time.Second,
+	}
+
+	wp := &dummyWorkerPool{executors: make(map[string]jobs.ExecutorFunc)}
+	svc := NewService(cfg, wp)
+
+	svc.Notify(EventAccountCreated, AccountData{Address: "0x01cf0e2f2f715450"})
+
+	for _, err := range wp.errors {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(received) != len(cfg.WebhookEndpoints) {
+		t.Fatalf("expected %d notifications, got %d", len(cfg.WebhookEndpoints), len(received))
+	}
+
+	if received[0].Event != EventAccountCreated || received[0].ID == "" {
+		t.Fatalf("unexpected payload: %+v", received[0])
+	}
+
+	if signature == "" {
+		t.Fatal("expected a signature header")
+	}
+}
+
+func TestNotifyFailingEndpoint(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer svr.Close()
+
+	cfg := &configs.Config{WebhookEndpoints: []string{svr.URL}, WebhookSecret: "secret", WebhookTimeout: time.Second}
+
+	wp := &dummyWorkerPool{executors: make(map[string]jobs.ExecutorFunc)}
+	svc := NewService(cfg, wp)
+
+	svc.Notify(EventAccountDisabled, AccountData{Address: "0x01cf0e2f2f715450"})
+
+	// The error is returned to the workerpool so that delivery is retried
+	if len(wp.errors) != 1 || wp.errors[0] == nil {
+		t.Fatalf("expected a delivery error, got %v", wp.errors)
+	}
+}
+
+func TestNotifyEndpoint(t *testing.T) {
+	var paths []string
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer svr.Close()
+
+	// Configured endpoints are not notified of events for a single endpoint
+	cfg := &configs.Config{WebhookEndpoints: []string{svr.URL + "/global"}, WebhookSecret: "secret", WebhookTimeout: time.Second}
+
+	wp := &dummyWorkerPool{executors: make(map[string]jobs.ExecutorFunc)}
+	svc := NewService(cfg, wp)
+
+	svc.NotifyEndpoint(svr.URL+"/callback", EventTransactionSealed, map[string]string{"transactionId": "..."})
+
+	for _, err := range wp.errors {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(paths) != 1 || paths[0] != "/callback" {
+		t.Fatalf("expected a single notification to /callback, got %v", paths)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	var received []Payload
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, p)
+	}))
+	defer svr.Close()
+
+	cfg := &configs.Config{WebhookEndpoints: []string{svr.URL}, WebhookSecret: "secret", WebhookTimeout: time.Second}
+
+	wp := &dummyWorkerPool{executors: make(map[string]jobs.ExecutorFunc)}
+	svc := NewService(cfg, wp)
+
+	payload, err := Encode("deposit-1", EventDepositDetected, map[string]string{"transactionId": "..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Redelivered payloads keep their ID
+	for i := 0; i < 2; i++ {
+		if err := svc.Deliver(context.Background(), svc.Endpoints()[0], payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(wp.errors) != 0 {
+		t.Fatalf("expected no jobs, got %d", len(wp.errors))
+	}
+
+	if len(received) != 2 || received[0].ID != "deposit-1" || received[1].ID != "deposit-1" {
+		t.Fatalf("expected two deliveries of deposit-1, got %+v", received)
+	}
+}
+
+func TestNewServiceWithoutSecret(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cfg    *configs.Config
+		panics bool
+	}{
+		{name: "endpoints without a secret", cfg: &configs.Config{WebhookEndpoints: []string{"http://localhost/webhook"}}, panics: true},
+		{name: "endpoints with a secret", cfg: &configs.Config{WebhookEndpoints: []string{"http://localhost/webhook"}, WebhookSecret: "secret"}},
+		{name: "no endpoints", cfg: &configs.Config{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.panics {
+					t.Fatalf("expected panic %t, got %v", tc.panics, r)
+				}
+			}()
+
+			NewService(tc.cfg, &dummyWorkerPool{executors: make(map[string]jobs.ExecutorFunc)})
+		})
+	}
+}
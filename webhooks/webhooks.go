@@ -0,0 +1,50 @@
+// Package webhooks provides signed webhook notifications of account lifecycle,
+// transaction result and token transfer events.
+package webhooks
+
+import (
+	"time"
+)
+
+type Event string
+
+const (
+	EventAccountCreated    Event = "account.created"
+	EventAccountFunded     Event = "account.funded"
+	EventAccountKeyRotated Event = "account.key_rotated"
+	EventAccountDisabled   Event = "account.disabled"
+	EventAccountEnabled    Event = "account.enabled"
+	EventAccountFrozen     Event = "account.frozen"
+	EventAccountUnfrozen   Event = "account.unfrozen"
+
+	EventTransactionSealed Event = "transaction.sealed"
+	EventTransactionFailed Event = "transaction.failed"
+	EventDepositDetected   Event = "deposit.detected"
+	EventDepositConfirmed  Event = "deposit.confirmed"
+	EventWithdrawalSent    Event = "withdrawal.sent"
+	EventWithdrawalSealed  Event = "withdrawal.sealed"
+	EventWithdrawalFailed  Event = "withdrawal.failed"
+)
+
+// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body,
+// keyed with the configured webhook secret.
+const SignatureHeader = "X-Flow-Wallet-Signature"
+
+// Payload is the JSON body posted to webhook endpoints.
+type Payload struct {
+	ID        string      `json:"id"`
+	Event     Event       `json:"event"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// AccountData is the payload data of account lifecycle events.
+type AccountData struct {
+	Address       string `json:"address"`
+	TransactionID string `json:"transactionId,omitempty"`
+	// Amount of FLOW for funding events
+	Amount string `json:"amount,omitempty"`
+	// Key index and change ("added", "revoked" or "recovered") for key rotation events
+	KeyIndex  *int   `json:"keyIndex,omitempty"`
+	KeyChange string `json:"keyChange,omitempty"`
+}